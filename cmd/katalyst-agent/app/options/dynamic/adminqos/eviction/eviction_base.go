@@ -26,7 +26,8 @@ import (
 )
 
 type EvictionOptions struct {
-	DryRun []string
+	DryRun    []string
+	ShadowRun []string
 
 	*CPUPressureEvictionOptions
 	*MemoryPressureEvictionOptions
@@ -51,6 +52,8 @@ func (o *EvictionOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	fs := fss.FlagSet("eviction")
 	fs.StringSliceVar(&o.DryRun, "eviction-dry-run-plugins", o.DryRun, fmt.Sprintf(" A list of "+
 		"eviction plugins to dry run. If a plugin in this list, it will enter dry run mode"))
+	fs.StringSliceVar(&o.ShadowRun, "eviction-shadow-run-plugins", o.ShadowRun, " A list of "+
+		"eviction plugins to shadow run. If a plugin in this list, pods chosen by it are recorded instead of being evicted")
 
 	o.CPUPressureEvictionOptions.AddFlags(fss)
 	o.MemoryPressureEvictionOptions.AddFlags(fss)
@@ -63,6 +66,7 @@ func (o *EvictionOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 func (o *EvictionOptions) ApplyTo(c *eviction.EvictionConfiguration) error {
 	var errList []error
 	c.DryRun = o.DryRun
+	c.ShadowRun = o.ShadowRun
 	errList = append(errList, o.CPUPressureEvictionOptions.ApplyTo(c.CPUPressureEvictionConfiguration))
 	errList = append(errList, o.MemoryPressureEvictionOptions.ApplyTo(c.MemoryPressureEvictionConfiguration))
 	errList = append(errList, o.ReclaimedResourcesEvictionOptions.ApplyTo(c.ReclaimedResourcesEvictionConfiguration))
@@ -17,11 +17,14 @@ limitations under the License.
 package tmodefault
 
 import (
+	"fmt"
+	"strconv"
 	"time"
 
 	cliflag "k8s.io/component-base/cli/flag"
 
 	"github.com/kubewharf/katalyst-api/pkg/apis/config/v1alpha1"
+	"github.com/kubewharf/katalyst-api/pkg/consts"
 	tmodynamicconf "github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/tmo"
 )
 
@@ -34,6 +37,10 @@ type DefaultOptions struct {
 	DefaultTMOPSIPolicyPSIAvg60Threshold               float64
 	DefaultTMORefaultPolicyReclaimAccuracyTarget       float64
 	DefaultTMORefaultPolicyReclaimScanEfficiencyTarget float64
+	DefaultTMOCompressedSwapPolicyPoolUtilizationCap   float64
+	DefaultTMOCompressedSwapPolicyMinCompressionRatio  float64
+	DefaultTMOCompressedSwapPolicyMaxWritebackRate     float64
+	TMOCompressedSwapPolicyQoSLevelPoolUtilizationCaps map[string]string
 }
 
 func NewDefaultOptions() *DefaultOptions {
@@ -46,6 +53,10 @@ func NewDefaultOptions() *DefaultOptions {
 		DefaultTMOPSIPolicyPSIAvg60Threshold:               tmodynamicconf.DefaultTMOPSIPolicyPSIAvg60Threshold,
 		DefaultTMORefaultPolicyReclaimAccuracyTarget:       tmodynamicconf.DefaultTMORefaultPolicyReclaimAccuracyTarget,
 		DefaultTMORefaultPolicyReclaimScanEfficiencyTarget: tmodynamicconf.DefaultTMORefaultPolicyReclaimScanEfficiencyTarget,
+		DefaultTMOCompressedSwapPolicyPoolUtilizationCap:   tmodynamicconf.DefaultTMOCompressedSwapPolicyPoolUtilizationCap,
+		DefaultTMOCompressedSwapPolicyMinCompressionRatio:  tmodynamicconf.DefaultTMOCompressedSwapPolicyMinCompressionRatio,
+		DefaultTMOCompressedSwapPolicyMaxWritebackRate:     tmodynamicconf.DefaultTMOCompressedSwapPolicyMaxWritebackRate,
+		TMOCompressedSwapPolicyQoSLevelPoolUtilizationCaps: map[string]string{},
 	}
 }
 
@@ -68,6 +79,14 @@ func (o *DefaultOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 		"indicates the default desired level of precision or accuracy in offloaded pages")
 	fs.Float64Var(&o.DefaultTMORefaultPolicyReclaimScanEfficiencyTarget, "default-refault-policy-reclaim-scan-efficiency-target", o.DefaultTMORefaultPolicyReclaimScanEfficiencyTarget,
 		"indicates the default desired level of efficiency in scanning and identifying memory pages that can be offloaded.")
+	fs.Float64Var(&o.DefaultTMOCompressedSwapPolicyPoolUtilizationCap, "default-compressed-swap-policy-pool-utilization-cap", o.DefaultTMOCompressedSwapPolicyPoolUtilizationCap,
+		"indicates the default max utilization of compressed swap pools (zswap and zram), and memory offloading will be stopped if it's exceeded.")
+	fs.Float64Var(&o.DefaultTMOCompressedSwapPolicyMinCompressionRatio, "default-compressed-swap-policy-min-compression-ratio", o.DefaultTMOCompressedSwapPolicyMinCompressionRatio,
+		"indicates the default compression ratio of compressed swap pools, below which memory offloading size will be scaled down.")
+	fs.Float64Var(&o.DefaultTMOCompressedSwapPolicyMaxWritebackRate, "default-compressed-swap-policy-max-writeback-rate", o.DefaultTMOCompressedSwapPolicyMaxWritebackRate,
+		"indicates the default max rate (bytes per second) of writing back from compressed swap pools to backing devices, and memory offloading will be stopped if it's exceeded.")
+	fs.StringToStringVar(&o.TMOCompressedSwapPolicyQoSLevelPoolUtilizationCaps, "compressed-swap-policy-qos-level-pool-utilization-caps", o.TMOCompressedSwapPolicyQoSLevelPoolUtilizationCaps,
+		"max utilization of compressed swap pools for each qos level, which overrides the default one")
 }
 
 func (o *DefaultOptions) ApplyTo(c *tmodynamicconf.TMODefaultConfigurations) error {
@@ -79,5 +98,19 @@ func (o *DefaultOptions) ApplyTo(c *tmodynamicconf.TMODefaultConfigurations) err
 	c.DefaultTMOPSIPolicyPSIAvg60Threshold = o.DefaultTMOPSIPolicyPSIAvg60Threshold
 	c.DefaultTMORefaultPolicyReclaimAccuracyTarget = o.DefaultTMORefaultPolicyReclaimAccuracyTarget
 	c.DefaultTMORefaultPolicyReclaimScanEfficiencyTarget = o.DefaultTMORefaultPolicyReclaimScanEfficiencyTarget
+	c.DefaultTMOCompressedSwapPolicyPoolUtilizationCap = o.DefaultTMOCompressedSwapPolicyPoolUtilizationCap
+	c.DefaultTMOCompressedSwapPolicyMinCompressionRatio = o.DefaultTMOCompressedSwapPolicyMinCompressionRatio
+	c.DefaultTMOCompressedSwapPolicyMaxWritebackRate = o.DefaultTMOCompressedSwapPolicyMaxWritebackRate
+
+	c.TMOCompressedSwapPolicyQoSLevelPoolUtilizationCaps = make(map[consts.QoSLevel]float64)
+	for qosLevel, capStr := range o.TMOCompressedSwapPolicyQoSLevelPoolUtilizationCaps {
+		poolUtilizationCap, err := strconv.ParseFloat(capStr, 64)
+		if err != nil {
+			return err
+		} else if poolUtilizationCap < 0 || poolUtilizationCap > 1 {
+			return fmt.Errorf("qos level %v compressed swap pool utilization cap must be in [0, 1]", qosLevel)
+		}
+		c.TMOCompressedSwapPolicyQoSLevelPoolUtilizationCaps[consts.QoSLevel(qosLevel)] = poolUtilizationCap
+	}
 	return nil
 }
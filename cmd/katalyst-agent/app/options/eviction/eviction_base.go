@@ -50,6 +50,16 @@ type GenericEvictionOptions struct {
 	// QoSPodKillers specify the pod killer implementation for different QoS levels
 	QoSPodKillers map[string]string
 
+	// ContainerRestartKillerPlugins are the eviction plugins whose results are handled by restarting containers
+	ContainerRestartKillerPlugins []string
+	// ContainerRestartKillerMaxRestarts is the max restart times of a container in window before falling back to pod eviction
+	ContainerRestartKillerMaxRestarts int
+	ContainerRestartKillerWindow      time.Duration
+	// ContainerRestartKillerFallback is the killer used to evict the whole pod
+	ContainerRestartKillerFallback string
+	// ContainerRestartKillerCheckpointDir is the directory where restart times of containers are checkpointed
+	ContainerRestartKillerCheckpointDir string
+
 	// StrictAuthentication means whether to authenticate plugins strictly
 	StrictAuthentication bool
 
@@ -59,6 +69,22 @@ type GenericEvictionOptions struct {
 	// RecordManager specifies the eviction record manager to use
 	RecordManager string
 
+	// EvictionRecordDir is the directory where the local eviction record manager persists records
+	EvictionRecordDir string
+	// EvictionRecordBucketDuration is the time span aggregated into one eviction bucket of a workload
+	EvictionRecordBucketDuration time.Duration
+	// EvictionRecordRetention is how long eviction buckets of a workload are kept
+	EvictionRecordRetention time.Duration
+
+	// NodeEvictionQPS and NodeEvictionQPSBurst limit the eviction rate of the whole node
+	NodeEvictionQPS      float64
+	NodeEvictionQPSBurst int
+	// WorkloadEvictionQPS and WorkloadEvictionQPSBurst limit the eviction rate of each workload
+	WorkloadEvictionQPS      float64
+	WorkloadEvictionQPSBurst int
+	// EvictionRespectPDB means whether to consult PodDisruptionBudget before evicting pods
+	EvictionRespectPDB bool
+
 	// HostPathNotifierPathRoot is the root path for host-path notifier
 	HostPathNotifierRootPath string
 }
@@ -75,6 +101,20 @@ func NewGenericEvictionOptions() *GenericEvictionOptions {
 		HostPathNotifierRootPath:      "/opt/katalyst",
 		PodKiller:                     consts.KillerNameEvictionKiller,
 		StrictAuthentication:          false,
+		EvictionRecordDir:             "/var/lib/katalyst/eviction",
+		EvictionRecordBucketDuration:  10 * time.Minute,
+		EvictionRecordRetention:       24 * time.Hour,
+		NodeEvictionQPSBurst:          1,
+		WorkloadEvictionQPSBurst:      1,
+
+		ContainerRestartKillerPlugins: []string{
+			"rss-overuse-eviction-plugin",
+			"rootfs-overuse-eviction-plugin",
+		},
+		ContainerRestartKillerMaxRestarts:   3,
+		ContainerRestartKillerWindow:        time.Hour,
+		ContainerRestartKillerFallback:      consts.KillerNameEvictionKiller,
+		ContainerRestartKillerCheckpointDir: "/var/lib/katalyst/eviction",
 	}
 }
 
@@ -106,6 +146,17 @@ func (o *GenericEvictionOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	fs.StringToStringVar(&o.QoSPodKillers, "qos-pod-killers", o.QoSPodKillers,
 		"the pod killer used to evict pod for different QoS levels")
 
+	fs.StringSliceVar(&o.ContainerRestartKillerPlugins, "container-restart-killer-plugins", o.ContainerRestartKillerPlugins,
+		"the eviction plugins whose results are handled by restarting the target container if container restart killer is used")
+	fs.IntVar(&o.ContainerRestartKillerMaxRestarts, "container-restart-killer-max-restarts", o.ContainerRestartKillerMaxRestarts,
+		"the max restart times of a container in window before container restart killer falls back to pod eviction")
+	fs.DurationVar(&o.ContainerRestartKillerWindow, "container-restart-killer-window", o.ContainerRestartKillerWindow,
+		"the window to count container restarts by container restart killer")
+	fs.StringVar(&o.ContainerRestartKillerFallback, "container-restart-killer-fallback", o.ContainerRestartKillerFallback,
+		"the killer used by container restart killer to evict the whole pod")
+	fs.StringVar(&o.ContainerRestartKillerCheckpointDir, "container-restart-killer-checkpoint-dir", o.ContainerRestartKillerCheckpointDir,
+		"the directory where container restart killer checkpoints restart times of containers")
+
 	fs.BoolVar(&o.StrictAuthentication, "strict-authentication", o.StrictAuthentication,
 		"whether to authenticate plugins strictly, the out-of-tree plugins must use valid and authorized token "+
 			"to register if it set to true")
@@ -116,6 +167,25 @@ func (o *GenericEvictionOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	fs.StringVar(&o.RecordManager, "eviction-record-manager", o.RecordManager,
 		"the eviction record manager to use")
 
+	fs.StringVar(&o.EvictionRecordDir, "eviction-record-dir", o.EvictionRecordDir,
+		"the directory where the local eviction record manager persists eviction records")
+	fs.DurationVar(&o.EvictionRecordBucketDuration, "eviction-record-bucket-duration", o.EvictionRecordBucketDuration,
+		"the time span aggregated into one eviction bucket of a workload")
+	fs.DurationVar(&o.EvictionRecordRetention, "eviction-record-retention", o.EvictionRecordRetention,
+		"how long eviction buckets of a workload are kept")
+
+	fs.Float64Var(&o.NodeEvictionQPS, "eviction-node-qps", o.NodeEvictionQPS,
+		"the token bucket qps of pod evictions on this node, non-positive value disables the limit; "+
+			"the bucket is kept in memory and starts full after agent restarts")
+	fs.IntVar(&o.NodeEvictionQPSBurst, "eviction-node-qps-burst", o.NodeEvictionQPSBurst,
+		"the token bucket burst of pod evictions on this node")
+	fs.Float64Var(&o.WorkloadEvictionQPS, "eviction-workload-qps", o.WorkloadEvictionQPS,
+		"the token bucket qps of pod evictions for each workload, non-positive value disables the limit")
+	fs.IntVar(&o.WorkloadEvictionQPSBurst, "eviction-workload-qps-burst", o.WorkloadEvictionQPSBurst,
+		"the token bucket burst of pod evictions for each workload")
+	fs.BoolVar(&o.EvictionRespectPDB, "eviction-respect-pdb", o.EvictionRespectPDB,
+		"whether to keep pods in eviction queue if the disruption budget of their PodDisruptionBudget is exhausted")
+
 	fs.StringVar(&o.HostPathNotifierRootPath, "pod-notifier-root-path", o.HostPathNotifierRootPath,
 		"root path of host-path notifier")
 }
@@ -130,9 +200,22 @@ func (o *GenericEvictionOptions) ApplyTo(c *evictionconfig.GenericEvictionConfig
 	c.EvictionBurst = o.EvictionBurst
 	c.PodKiller = o.PodKiller
 	c.QoSPodKillers = o.QoSPodKillers
+	c.ContainerRestartKillerPlugins = o.ContainerRestartKillerPlugins
+	c.ContainerRestartKillerMaxRestarts = o.ContainerRestartKillerMaxRestarts
+	c.ContainerRestartKillerWindow = o.ContainerRestartKillerWindow
+	c.ContainerRestartKillerFallback = o.ContainerRestartKillerFallback
+	c.ContainerRestartKillerCheckpointDir = o.ContainerRestartKillerCheckpointDir
 	c.StrictAuthentication = o.StrictAuthentication
 	c.PodMetricLabels.Insert(o.PodMetricLabels...)
 	c.RecordManager = o.RecordManager
+	c.EvictionRecordDir = o.EvictionRecordDir
+	c.EvictionRecordBucketDuration = o.EvictionRecordBucketDuration
+	c.EvictionRecordRetention = o.EvictionRecordRetention
+	c.NodeEvictionQPS = o.NodeEvictionQPS
+	c.NodeEvictionQPSBurst = o.NodeEvictionQPSBurst
+	c.WorkloadEvictionQPS = o.WorkloadEvictionQPS
+	c.WorkloadEvictionQPSBurst = o.WorkloadEvictionQPSBurst
+	c.EvictionRespectPDB = o.EvictionRespectPDB
 	c.HostPathNotifierRootPath = o.HostPathNotifierRootPath
 	return nil
 }
@@ -149,6 +232,7 @@ type EvictionOptions struct {
 	*ReclaimedResourcesEvictionOptions
 	*MemoryPressureEvictionOptions
 	*CPUPressureEvictionOptions
+	*PSIPressureEvictionOptions
 }
 
 func NewEvictionOptions() *EvictionOptions {
@@ -156,6 +240,7 @@ func NewEvictionOptions() *EvictionOptions {
 		ReclaimedResourcesEvictionOptions: NewReclaimedResourcesEvictionOptions(),
 		MemoryPressureEvictionOptions:     NewMemoryPressureEvictionOptions(),
 		CPUPressureEvictionOptions:        NewCPUPressureEvictionOptions(),
+		PSIPressureEvictionOptions:        NewPSIPressureEvictionOptions(),
 	}
 }
 
@@ -163,6 +248,7 @@ func (o *EvictionOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	o.ReclaimedResourcesEvictionOptions.AddFlags(fss)
 	o.MemoryPressureEvictionOptions.AddFlags(fss)
 	o.CPUPressureEvictionOptions.AddFlags(fss)
+	o.PSIPressureEvictionOptions.AddFlags(fss)
 }
 
 // ApplyTo fills up config with options
@@ -172,6 +258,7 @@ func (o *EvictionOptions) ApplyTo(c *evictionconfig.EvictionConfiguration) error
 		o.ReclaimedResourcesEvictionOptions.ApplyTo(c.ReclaimedResourcesEvictionConfiguration),
 		o.MemoryPressureEvictionOptions.ApplyTo(c.MemoryPressureEvictionConfiguration),
 		o.CPUPressureEvictionOptions.ApplyTo(c.CPUPressureEvictionConfiguration),
+		o.PSIPressureEvictionOptions.ApplyTo(c.PSIPressureEvictionConfiguration),
 	)
 	return errors.NewAggregate(errList)
 }
@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eviction
+
+import (
+	"time"
+
+	cliflag "k8s.io/component-base/cli/flag"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	evictionconfig "github.com/kubewharf/katalyst-core/pkg/config/agent/eviction"
+)
+
+const (
+	defaultPSIPressureSyncPeriod          = 10 * time.Second
+	defaultPSIPressureGraceWindow         = 60 * time.Second
+	defaultPSIPressureEvictionGracePeriod = 30
+)
+
+// PSIPressureEvictionOptions is the options of PSI based io and cpu pressure eviction
+type PSIPressureEvictionOptions struct {
+	EnableIOPressureEviction  bool
+	IOPressureThresholds      evictionconfig.PSIThresholds
+	EnableCPUPressureEviction bool
+	CPUPressureThresholds     evictionconfig.PSIThresholds
+
+	PSIPressureSyncPeriod          time.Duration
+	PSIPressureGraceWindow         time.Duration
+	PSIPressureCandidateQoSLevels  []string
+	PSIPressureEvictionGracePeriod int64
+}
+
+// NewPSIPressureEvictionOptions returns a new PSIPressureEvictionOptions
+func NewPSIPressureEvictionOptions() *PSIPressureEvictionOptions {
+	return &PSIPressureEvictionOptions{
+		IOPressureThresholds: evictionconfig.PSIThresholds{
+			SomeAvg10: 60,
+			FullAvg10: 40,
+		},
+		CPUPressureThresholds: evictionconfig.PSIThresholds{
+			SomeAvg10: 80,
+			SomeAvg60: 60,
+		},
+		PSIPressureSyncPeriod:  defaultPSIPressureSyncPeriod,
+		PSIPressureGraceWindow: defaultPSIPressureGraceWindow,
+		PSIPressureCandidateQoSLevels: []string{
+			apiconsts.PodAnnotationQoSLevelReclaimedCores,
+			apiconsts.PodAnnotationQoSLevelSharedCores,
+		},
+		PSIPressureEvictionGracePeriod: defaultPSIPressureEvictionGracePeriod,
+	}
+}
+
+// AddFlags parses the flags to PSIPressureEvictionOptions
+func (o *PSIPressureEvictionOptions) AddFlags(fss *cliflag.NamedFlagSets) {
+	fs := fss.FlagSet("eviction-psi-pressure")
+
+	fs.BoolVar(&o.EnableIOPressureEviction, "eviction-io-pressure-enable", o.EnableIOPressureEviction,
+		"whether to evict pods when the node is under io pressure reported by /proc/pressure/io")
+	fs.Float64Var(&o.IOPressureThresholds.SomeAvg10, "eviction-io-pressure-some-avg10-threshold",
+		o.IOPressureThresholds.SomeAvg10, "the threshold of io some avg10 pressure in percentage, 0 means disabled")
+	fs.Float64Var(&o.IOPressureThresholds.SomeAvg60, "eviction-io-pressure-some-avg60-threshold",
+		o.IOPressureThresholds.SomeAvg60, "the threshold of io some avg60 pressure in percentage, 0 means disabled")
+	fs.Float64Var(&o.IOPressureThresholds.FullAvg10, "eviction-io-pressure-full-avg10-threshold",
+		o.IOPressureThresholds.FullAvg10, "the threshold of io full avg10 pressure in percentage, 0 means disabled")
+	fs.Float64Var(&o.IOPressureThresholds.FullAvg60, "eviction-io-pressure-full-avg60-threshold",
+		o.IOPressureThresholds.FullAvg60, "the threshold of io full avg60 pressure in percentage, 0 means disabled")
+	fs.BoolVar(&o.EnableCPUPressureEviction, "eviction-cpu-psi-pressure-enable", o.EnableCPUPressureEviction,
+		"whether to evict pods when the node is under cpu pressure reported by /proc/pressure/cpu")
+	fs.Float64Var(&o.CPUPressureThresholds.SomeAvg10, "eviction-cpu-pressure-some-avg10-threshold",
+		o.CPUPressureThresholds.SomeAvg10, "the threshold of cpu some avg10 pressure in percentage, 0 means disabled")
+	fs.Float64Var(&o.CPUPressureThresholds.SomeAvg60, "eviction-cpu-pressure-some-avg60-threshold",
+		o.CPUPressureThresholds.SomeAvg60, "the threshold of cpu some avg60 pressure in percentage, 0 means disabled")
+	fs.Float64Var(&o.CPUPressureThresholds.FullAvg10, "eviction-cpu-pressure-full-avg10-threshold",
+		o.CPUPressureThresholds.FullAvg10, "the threshold of cpu full avg10 pressure in percentage, 0 means disabled")
+	fs.Float64Var(&o.CPUPressureThresholds.FullAvg60, "eviction-cpu-pressure-full-avg60-threshold",
+		o.CPUPressureThresholds.FullAvg60, "the threshold of cpu full avg60 pressure in percentage, 0 means disabled")
+	fs.DurationVar(&o.PSIPressureSyncPeriod, "eviction-psi-pressure-sync-period", o.PSIPressureSyncPeriod,
+		"the interval to collect node and pod level pressure stall information")
+	fs.DurationVar(&o.PSIPressureGraceWindow, "eviction-psi-pressure-grace-window", o.PSIPressureGraceWindow,
+		"the duration that pressure thresholds must keep being met before evicting pods")
+	fs.StringSliceVar(&o.PSIPressureCandidateQoSLevels, "eviction-psi-pressure-candidate-qos-levels",
+		o.PSIPressureCandidateQoSLevels, "the qos levels of pods that can be evicted by io and cpu pressure")
+	fs.Int64Var(&o.PSIPressureEvictionGracePeriod, "eviction-psi-pressure-grace-period", o.PSIPressureEvictionGracePeriod,
+		"the grace period in seconds of pods deleted by io and cpu pressure eviction")
+}
+
+// ApplyTo applies PSIPressureEvictionOptions to PSIPressureEvictionConfiguration
+func (o *PSIPressureEvictionOptions) ApplyTo(c *evictionconfig.PSIPressureEvictionConfiguration) error {
+	c.EnableIOPressureEviction = o.EnableIOPressureEviction
+	c.IOPressureThresholds = o.IOPressureThresholds
+	c.EnableCPUPressureEviction = o.EnableCPUPressureEviction
+	c.CPUPressureThresholds = o.CPUPressureThresholds
+	c.PSIPressureSyncPeriod = o.PSIPressureSyncPeriod
+	c.PSIPressureGraceWindow = o.PSIPressureGraceWindow
+	c.PSIPressureCandidateQoSLevels = o.PSIPressureCandidateQoSLevels
+	c.PSIPressureEvictionGracePeriod = o.PSIPressureEvictionGracePeriod
+	return nil
+}
@@ -17,23 +17,46 @@ limitations under the License.
 package global
 
 import (
+	"time"
+
 	cliflag "k8s.io/component-base/cli/flag"
 
 	"github.com/kubewharf/katalyst-core/pkg/agent/audit/sink"
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/global"
 )
 
-const DefaultBufferSize = 1000
+const (
+	DefaultBufferSize = 1000
+
+	defaultFileSinkDir             = "/var/log/katalyst/audit"
+	defaultFileSinkMaxFileSizeMB   = 100
+	defaultFileSinkMaxFileAge      = 24 * time.Hour
+	defaultFileSinkMaxBackups      = 10
+	defaultFileSinkRetentionPeriod = 7 * 24 * time.Hour
+)
 
 type AuditOptions struct {
 	Sinks      []string
 	BufferSize int
+
+	FileSinkDir             string
+	FileSinkMaxFileSizeMB   int
+	FileSinkMaxFileAge      time.Duration
+	FileSinkMaxBackups      int
+	FileSinkRetentionPeriod time.Duration
+
+	QueryAddress string
 }
 
 func NewAuditOptions() *AuditOptions {
 	return &AuditOptions{
-		Sinks:      []string{sink.SinkNameLogBased},
-		BufferSize: DefaultBufferSize,
+		Sinks:                   []string{sink.SinkNameLogBased},
+		BufferSize:              DefaultBufferSize,
+		FileSinkDir:             defaultFileSinkDir,
+		FileSinkMaxFileSizeMB:   defaultFileSinkMaxFileSizeMB,
+		FileSinkMaxFileAge:      defaultFileSinkMaxFileAge,
+		FileSinkMaxBackups:      defaultFileSinkMaxBackups,
+		FileSinkRetentionPeriod: defaultFileSinkRetentionPeriod,
 	}
 }
 
@@ -42,11 +65,29 @@ func (o *AuditOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	fs := fss.FlagSet("audit")
 	fs.StringSliceVar(&o.Sinks, "sinks", o.Sinks, "the sinks to send audit data")
 	fs.IntVar(&o.BufferSize, "buffer-size", o.BufferSize, "buffer size for write audit data")
+	fs.StringVar(&o.FileSinkDir, "audit-file-sink-dir", o.FileSinkDir,
+		"the directory to store audit records for file-based sink")
+	fs.IntVar(&o.FileSinkMaxFileSizeMB, "audit-file-sink-max-file-size-mb", o.FileSinkMaxFileSizeMB,
+		"the max size in megabytes of one audit file before it gets rotated")
+	fs.DurationVar(&o.FileSinkMaxFileAge, "audit-file-sink-max-file-age", o.FileSinkMaxFileAge,
+		"the max duration of one audit file before it gets rotated")
+	fs.IntVar(&o.FileSinkMaxBackups, "audit-file-sink-max-backups", o.FileSinkMaxBackups,
+		"the max number of rotated audit files to retain")
+	fs.DurationVar(&o.FileSinkRetentionPeriod, "audit-file-sink-retention-period", o.FileSinkRetentionPeriod,
+		"the max duration to retain rotated audit files")
+	fs.StringVar(&o.QueryAddress, "audit-query-address", o.QueryAddress,
+		"the local address to serve audit history queries, e.g. 127.0.0.1:9318; disabled if empty")
 }
 
 // ApplyTo fills up config with options
 func (o *AuditOptions) ApplyTo(conf *global.AuditConfiguration) error {
 	conf.Sinks = o.Sinks
 	conf.BufferSize = o.BufferSize
+	conf.FileAuditSinkConfiguration.Dir = o.FileSinkDir
+	conf.FileAuditSinkConfiguration.MaxFileSizeMB = o.FileSinkMaxFileSizeMB
+	conf.FileAuditSinkConfiguration.MaxFileAge = o.FileSinkMaxFileAge
+	conf.FileAuditSinkConfiguration.MaxBackups = o.FileSinkMaxBackups
+	conf.FileAuditSinkConfiguration.RetentionPeriod = o.FileSinkRetentionPeriod
+	conf.QueryAddress = o.QueryAddress
 	return nil
 }
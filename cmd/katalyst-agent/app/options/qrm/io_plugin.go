@@ -30,6 +30,7 @@ type IOOptions struct {
 	// DirtyThrottlingOption // option for dirty throttling, it determin the global watermark of dirty memory.
 	IOCostOption
 	IOWeightOption
+	IOThrottleOption
 }
 
 type WritebackThrottlingOption struct {
@@ -53,6 +54,13 @@ type IOWeightOption struct {
 	IOWeightCgroupLevelConfigFile string
 }
 
+type IOThrottleOption struct {
+	EnableIOThrottle           bool
+	IOThrottleDeviceConfigFile string
+	IOThrottleDefaultDevice    string
+	SkipIOStateCorruption      bool
+}
+
 func NewIOOptions() *IOOptions {
 	return &IOOptions{
 		PolicyName: "static",
@@ -74,6 +82,12 @@ func NewIOOptions() *IOOptions {
 			IOWeightQoSLevelConfigFile:    "",
 			IOWeightCgroupLevelConfigFile: "",
 		},
+		IOThrottleOption: IOThrottleOption{
+			EnableIOThrottle:           false,
+			IOThrottleDeviceConfigFile: "",
+			IOThrottleDefaultDevice:    "",
+			SkipIOStateCorruption:      false,
+		},
 	}
 }
 
@@ -106,6 +120,14 @@ func (o *IOOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 		o.IOWeightQoSLevelConfigFile, "the absolute path of io.weight qos config file")
 	fs.StringVar(&o.IOWeightCgroupLevelConfigFile, "io-weight-cgroup-config-file",
 		o.IOWeightCgroupLevelConfigFile, "the absolute path of io.weight cgroup config file")
+	fs.BoolVar(&o.EnableIOThrottle, "enable-io-throttle",
+		o.EnableIOThrottle, "if set it to true, io plugin will allocate disk bandwidth and iops for containers and apply them as io.max")
+	fs.StringVar(&o.IOThrottleDeviceConfigFile, "io-throttle-device-config-file",
+		o.IOThrottleDeviceConfigFile, "the absolute path of io throttle device config file, which maps device names to their capacities")
+	fs.StringVar(&o.IOThrottleDefaultDevice, "io-throttle-default-device",
+		o.IOThrottleDefaultDevice, "the device to throttle if pod doesn't specify one, the first device in config file will be used if it's empty")
+	fs.BoolVar(&o.SkipIOStateCorruption, "skip-io-state-corruption",
+		o.SkipIOStateCorruption, "if set true, we will skip io state corruption")
 }
 
 func (o *IOOptions) ApplyTo(conf *qrmconfig.IOQRMPluginConfig) error {
@@ -122,5 +144,9 @@ func (o *IOOptions) ApplyTo(conf *qrmconfig.IOQRMPluginConfig) error {
 	conf.EnableSettingIOWeight = o.EnableSettingIOWeight
 	conf.IOWeightQoSLevelConfigFile = o.IOWeightQoSLevelConfigFile
 	conf.IOWeightCgroupLevelConfigFile = o.IOWeightCgroupLevelConfigFile
+	conf.EnableIOThrottle = o.EnableIOThrottle
+	conf.IOThrottleDeviceConfigFile = o.IOThrottleDeviceConfigFile
+	conf.IOThrottleDefaultDevice = o.IOThrottleDefaultDevice
+	conf.SkipIOStateCorruption = o.SkipIOStateCorruption
 	return nil
 }
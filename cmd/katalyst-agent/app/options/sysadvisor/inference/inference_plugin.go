@@ -31,6 +31,10 @@ const (
 // InferencePluginOptions holds the configurations for inference plugin.
 type InferencePluginOptions struct {
 	SyncPeriod time.Duration
+	ResultTTL  time.Duration
+
+	EnableLocalModelResultFetcher bool
+	LocalModelDirectory           string
 }
 
 // NewInferencePluginOptions creates a new Options with a default config.
@@ -45,10 +49,20 @@ func (o *InferencePluginOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	fs := fss.FlagSet("inference_plugin")
 
 	fs.DurationVar(&o.SyncPeriod, "inference-sync-period", o.SyncPeriod, "Period for inference plugin to sync")
+	fs.DurationVar(&o.ResultTTL, "inference-result-ttl", o.ResultTTL,
+		"TTL of inference results persisted to be reused after restart, 0 means not persisted")
+	fs.BoolVar(&o.EnableLocalModelResultFetcher, "enable-local-model-result-fetcher", o.EnableLocalModelResultFetcher,
+		"if set as true, evaluate local model artifacts in-process to get inference results")
+	fs.StringVar(&o.LocalModelDirectory, "local-model-directory", o.LocalModelDirectory,
+		"directory of local model artifacts, each json file in it is loaded as a model named by its file name, "+
+			"and its results replace those of the borwein model with the same name")
 }
 
 // ApplyTo fills up config with options
 func (o *InferencePluginOptions) ApplyTo(c *inference.InferencePluginConfiguration) error {
 	c.SyncPeriod = o.SyncPeriod
+	c.ResultTTL = o.ResultTTL
+	c.EnableLocalModelResultFetcher = o.EnableLocalModelResultFetcher
+	c.LocalModelDirectory = o.LocalModelDirectory
 	return nil
 }
@@ -29,6 +29,12 @@ type PowerAwarePluginOptions struct {
 	PowerCappingAdvisorSocketAbsPath string
 	AnnotationKeyPrefix              string
 	DVFSIndication                   string
+
+	PowerReader       string
+	PowerCapper       string
+	LocalCapperMethod string
+	PowercapRoot      string
+	CPUFreqRoot       string
 }
 
 func (p *PowerAwarePluginOptions) AddFlags(fss *cliflag.NamedFlagSets) {
@@ -39,6 +45,11 @@ func (p *PowerAwarePluginOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	fs.StringVar(&p.PowerCappingAdvisorSocketAbsPath, "power-capping-advisor-sock-abs-path", p.PowerCappingAdvisorSocketAbsPath, "absolute path of unix socket file for power capping advisor served in sys-advisor")
 	fs.StringVar(&p.AnnotationKeyPrefix, "power-aware-annotation-key-prefix", p.AnnotationKeyPrefix, "prefix of node annotation keys used by power aware plugin")
 	fs.StringVar(&p.DVFSIndication, "power-aware-dvfs-indication", p.DVFSIndication, "indication metric name of dvfs effect")
+	fs.StringVar(&p.PowerReader, "power-aware-power-reader", p.PowerReader, "source of node power usage, one of metric-store or rapl")
+	fs.StringVar(&p.PowerCapper, "power-aware-power-capper", p.PowerCapper, "power capper to use, server to delegate to external capper, or local to cap by sys-advisor itself")
+	fs.StringVar(&p.LocalCapperMethod, "power-aware-local-capper-method", p.LocalCapperMethod, "how local power capper throttles, one of powercap or cpufreq")
+	fs.StringVar(&p.PowercapRoot, "power-aware-powercap-root", p.PowercapRoot, "root dir of powercap sysfs")
+	fs.StringVar(&p.CPUFreqRoot, "power-aware-cpufreq-root", p.CPUFreqRoot, "root dir of cpu sysfs holding cpufreq settings")
 }
 
 func (p *PowerAwarePluginOptions) ApplyTo(o *poweraware.PowerAwarePluginConfiguration) error {
@@ -48,6 +59,11 @@ func (p *PowerAwarePluginOptions) ApplyTo(o *poweraware.PowerAwarePluginConfigur
 	o.PowerCappingAdvisorSocketAbsPath = p.PowerCappingAdvisorSocketAbsPath
 	o.AnnotationKeyPrefix = p.AnnotationKeyPrefix
 	o.DVFSIndication = p.DVFSIndication
+	o.PowerReader = p.PowerReader
+	o.PowerCapper = p.PowerCapper
+	o.LocalCapperMethod = p.LocalCapperMethod
+	o.PowercapRoot = p.PowercapRoot
+	o.CPUFreqRoot = p.CPUFreqRoot
 
 	return nil
 }
@@ -55,6 +71,11 @@ func (p *PowerAwarePluginOptions) ApplyTo(o *poweraware.PowerAwarePluginConfigur
 // NewPowerAwarePluginOptions creates a new Options with a default config.
 func NewPowerAwarePluginOptions() *PowerAwarePluginOptions {
 	return &PowerAwarePluginOptions{
-		DVFSIndication: poweraware.DVFSIndicationPower,
+		DVFSIndication:    poweraware.DVFSIndicationPower,
+		PowerReader:       poweraware.PowerReaderMetricStore,
+		PowerCapper:       poweraware.PowerCapperServer,
+		LocalCapperMethod: poweraware.LocalCapperMethodCPUFreq,
+		PowercapRoot:      "/sys/class/powercap",
+		CPUFreqRoot:       "/sys/devices/system/cpu",
 	}
 }
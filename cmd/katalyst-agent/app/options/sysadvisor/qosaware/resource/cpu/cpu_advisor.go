@@ -51,11 +51,14 @@ func NewCPUAdvisorOptions() *CPUAdvisorOptions {
 			string(v1alpha1.QoSRegionTypeShare):     string(types.CPUProvisionPolicyCanonical),
 			string(v1alpha1.QoSRegionTypeIsolation): string(types.CPUProvisionPolicyCanonical),
 			string(v1alpha1.QoSRegionTypeDedicated): string(types.CPUProvisionPolicyCanonical),
+			string(types.QoSRegionTypeBatch):        string(types.CPUProvisionPolicyThroughput),
 		},
 		CPUHeadroomPolicyPriority: map[string]string{
 			string(v1alpha1.QoSRegionTypeShare):     string(types.CPUHeadroomPolicyCanonical),
 			string(v1alpha1.QoSRegionTypeIsolation): string(types.CPUHeadroomPolicyCanonical),
 			string(v1alpha1.QoSRegionTypeDedicated): string(types.CPUHeadroomPolicyCanonical),
+			// batch pool is counted into reclaimed headroom by headroom assembler
+			string(types.QoSRegionTypeBatch): string(types.CPUHeadroomPolicyNone),
 		},
 		CPUProvisionAssembler:     string(types.CPUProvisionAssemblerCommon),
 		CPUHeadroomAssembler:      string(types.CPUHeadroomAssemblerCommon),
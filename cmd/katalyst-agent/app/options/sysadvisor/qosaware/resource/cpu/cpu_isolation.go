@@ -19,6 +19,7 @@ package cpu
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/spf13/pflag"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -59,6 +60,19 @@ type CPUIsolationOptions struct {
 	IsolationNonExclusivePools []string
 
 	IsolationIncludeSidecarRequirement bool
+
+	// InterferenceIsolation* are the options of isolator by interference signals
+	InterferenceIsolationEnabled              bool
+	InterferenceIsolationScoreThreshold       float64
+	InterferenceIsolationMemBandwidthWeight   float64
+	InterferenceIsolationLLCMissWeight        float64
+	InterferenceIsolationCPIDegradationWeight float64
+	InterferenceIsolationThrottleWeight       float64
+	InterferenceIsolationNeighbourPressure    float64
+	InterferenceIsolationLockInDuration       time.Duration
+	InterferenceIsolationLockOutDuration      time.Duration
+	InterferenceIsolationMaxIsolatedPods      int
+	InterferenceIsolationMaxPoolIsolatedPods  map[string]string
 }
 
 // NewCPUIsolationOptions creates a new Options with a default config
@@ -80,6 +94,17 @@ func NewCPUIsolationOptions() *CPUIsolationOptions {
 		IsolationDisabledPools:     []string{},
 		IsolationForceEnablePools:  []string{},
 		IsolationNonExclusivePools: []string{},
+
+		InterferenceIsolationScoreThreshold:       0.5,
+		InterferenceIsolationMemBandwidthWeight:   0.4,
+		InterferenceIsolationLLCMissWeight:        0.4,
+		InterferenceIsolationCPIDegradationWeight: 0.5,
+		InterferenceIsolationThrottleWeight:       0.5,
+		InterferenceIsolationNeighbourPressure:    0.1,
+		InterferenceIsolationLockInDuration:       time.Minute,
+		InterferenceIsolationLockOutDuration:      5 * time.Minute,
+		InterferenceIsolationMaxIsolatedPods:      1,
+		InterferenceIsolationMaxPoolIsolatedPods:  map[string]string{},
 	}
 }
 
@@ -119,6 +144,29 @@ func (o *CPUIsolationOptions) AddFlags(fs *pflag.FlagSet) {
 		"isolation is non-exclusive for get given pool")
 	fs.BoolVar(&o.IsolationIncludeSidecarRequirement, "isolation-include-sidecar-requirement", o.IsolationIncludeSidecarRequirement,
 		"isolation include sidecar requirement")
+
+	fs.BoolVar(&o.InterferenceIsolationEnabled, "isolation-interference-enable", o.InterferenceIsolationEnabled,
+		"if set as true, also isolate pods by interference signals, e.g. memory bandwidth, llc misses and neighbours' cpi degradation")
+	fs.Float64Var(&o.InterferenceIsolationScoreThreshold, "isolation-interference-score-threshold", o.InterferenceIsolationScoreThreshold,
+		"interference score for a pod to be regarded as an offender")
+	fs.Float64Var(&o.InterferenceIsolationMemBandwidthWeight, "isolation-interference-mem-bandwidth-weight", o.InterferenceIsolationMemBandwidthWeight,
+		"weight of memory bandwidth share in interference score")
+	fs.Float64Var(&o.InterferenceIsolationLLCMissWeight, "isolation-interference-llc-miss-weight", o.InterferenceIsolationLLCMissWeight,
+		"weight of llc miss share in interference score")
+	fs.Float64Var(&o.InterferenceIsolationCPIDegradationWeight, "isolation-interference-cpi-degradation-weight", o.InterferenceIsolationCPIDegradationWeight,
+		"weight of neighbours' cpi degradation in interference score")
+	fs.Float64Var(&o.InterferenceIsolationThrottleWeight, "isolation-interference-throttle-weight", o.InterferenceIsolationThrottleWeight,
+		"weight of neighbours' cpu throttling in interference score")
+	fs.Float64Var(&o.InterferenceIsolationNeighbourPressure, "isolation-interference-neighbour-pressure-threshold", o.InterferenceIsolationNeighbourPressure,
+		"min weighted cpi degradation and cpu throttling of neighbours for a pod to be regarded as an offender")
+	fs.DurationVar(&o.InterferenceIsolationLockInDuration, "isolation-interference-lockin-duration", o.InterferenceIsolationLockInDuration,
+		"mark pod as isolated iff it keeps as an offender for the duration")
+	fs.DurationVar(&o.InterferenceIsolationLockOutDuration, "isolation-interference-lockout-duration", o.InterferenceIsolationLockOutDuration,
+		"mark isolated pod as back to un-isolated iff it keeps quiet for the duration")
+	fs.IntVar(&o.InterferenceIsolationMaxIsolatedPods, "isolation-interference-max-pods", o.InterferenceIsolationMaxIsolatedPods,
+		"max pods isolated by interference in each pool")
+	fs.StringToStringVar(&o.InterferenceIsolationMaxPoolIsolatedPods, "isolation-interference-max-pool-pods", o.InterferenceIsolationMaxPoolIsolatedPods,
+		"max pods isolated by interference for the given pools")
 }
 
 // ApplyTo fills up config with options
@@ -164,5 +212,30 @@ func (o *CPUIsolationOptions) ApplyTo(c *cpu.CPUIsolationConfiguration) error {
 
 	c.IsolationIncludeSidecarRequirement = o.IsolationIncludeSidecarRequirement
 
+	maxPoolIsolatedPods := make(map[string]int, len(o.InterferenceIsolationMaxPoolIsolatedPods))
+	for pool, maxStr := range o.InterferenceIsolationMaxPoolIsolatedPods {
+		maxPods, err := strconv.Atoi(maxStr)
+		if err != nil {
+			return err
+		} else if maxPods < 0 {
+			return fmt.Errorf("pool %v interference isolation max pods must not be negative", pool)
+		}
+		maxPoolIsolatedPods[pool] = maxPods
+	}
+	c.InterferenceIsolation = cpu.InterferenceIsolationConfiguration{
+		Enabled:              o.InterferenceIsolationEnabled,
+		ScoreThreshold:       o.InterferenceIsolationScoreThreshold,
+		MemBandwidthWeight:   o.InterferenceIsolationMemBandwidthWeight,
+		LLCMissWeight:        o.InterferenceIsolationLLCMissWeight,
+		CPIDegradationWeight: o.InterferenceIsolationCPIDegradationWeight,
+		ThrottleWeight:       o.InterferenceIsolationThrottleWeight,
+		LockInDuration:       o.InterferenceIsolationLockInDuration,
+		LockOutDuration:      o.InterferenceIsolationLockOutDuration,
+		MaxIsolatedPods:      o.InterferenceIsolationMaxIsolatedPods,
+		MaxPoolIsolatedPods:  maxPoolIsolatedPods,
+
+		NeighbourPressureThreshold: o.InterferenceIsolationNeighbourPressure,
+	}
+
 	return nil
 }
@@ -17,11 +17,13 @@ limitations under the License.
 package provision
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/spf13/pflag"
 	"k8s.io/apimachinery/pkg/util/errors"
 
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
 	provisionconfig "github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu/provision"
 )
 
@@ -29,6 +31,14 @@ type CPURegulatorOptions struct {
 	MaxRampUpStep     int
 	MaxRampDownStep   int
 	MinRampDownPeriod time.Duration
+
+	RegulatorNames                        map[string]string
+	AdaptiveRegulatorHysteresisUpBand     float64
+	AdaptiveRegulatorHysteresisDownBand   float64
+	AdaptiveRegulatorSmoothingFactor      float64
+	AdaptiveRegulatorOscillationWindow    int
+	AdaptiveRegulatorOscillationThreshold int
+	AdaptiveRegulatorDampingFactor        float64
 }
 
 type CPUProvisionPolicyOptions struct {
@@ -38,6 +48,9 @@ type CPUProvisionPolicyOptions struct {
 	// PolicyRamaOptions is the options for policy rama
 	PolicyRama *PolicyRamaOptions
 
+	// PolicyMPCOptions is the options for policy mpc
+	PolicyMPC *PolicyMPCOptions
+
 	// enable to use control knob cpu quota when cgroup2 available
 	EnableControlKnobCPUQuota bool
 }
@@ -48,21 +61,48 @@ func NewCPUProvisionPolicyOptions() *CPUProvisionPolicyOptions {
 			MaxRampUpStep:     10,
 			MaxRampDownStep:   2,
 			MinRampDownPeriod: 30 * time.Second,
+
+			RegulatorNames:                        map[string]string{},
+			AdaptiveRegulatorHysteresisUpBand:     0.5,
+			AdaptiveRegulatorHysteresisDownBand:   2,
+			AdaptiveRegulatorSmoothingFactor:      0.5,
+			AdaptiveRegulatorOscillationWindow:    6,
+			AdaptiveRegulatorOscillationThreshold: 3,
+			AdaptiveRegulatorDampingFactor:        0.5,
 		},
 		PolicyRama: NewPolicyRamaOptions(),
+		PolicyMPC:  NewPolicyMPCOptions(),
 	}
 }
 
 // ApplyTo fills up config with options
 func (o *CPUProvisionPolicyOptions) ApplyTo(c *provisionconfig.CPUProvisionPolicyConfiguration) error {
+	for regionType, name := range o.RegulatorNames {
+		switch types.CPURegulatorName(name) {
+		case "", types.CPURegulatorDefault, types.CPURegulatorAdaptive:
+		default:
+			return fmt.Errorf("unknown cpu regulator %q for region type %s", name, regionType)
+		}
+	}
+
 	c.MaxRampUpStep = o.MaxRampUpStep
 	c.MaxRampDownStep = o.MaxRampDownStep
 	c.MinRampDownPeriod = o.MinRampDownPeriod
+	c.RegulatorNames = o.RegulatorNames
+	c.AdaptiveRegulator = provisionconfig.AdaptiveRegulatorConfiguration{
+		HysteresisUpBand:     o.AdaptiveRegulatorHysteresisUpBand,
+		HysteresisDownBand:   o.AdaptiveRegulatorHysteresisDownBand,
+		SmoothingFactor:      o.AdaptiveRegulatorSmoothingFactor,
+		OscillationWindow:    o.AdaptiveRegulatorOscillationWindow,
+		OscillationThreshold: o.AdaptiveRegulatorOscillationThreshold,
+		DampingFactor:        o.AdaptiveRegulatorDampingFactor,
+	}
 
 	c.EnableControlKnobCPUQuota = o.EnableControlKnobCPUQuota
 
 	var errList []error
 	errList = append(errList, o.PolicyRama.ApplyTo(c.PolicyRama))
+	errList = append(errList, o.PolicyMPC.ApplyTo(c.PolicyMPC))
 
 	return errors.NewAggregate(errList)
 }
@@ -72,6 +112,14 @@ func (o *CPUProvisionPolicyOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&o.MaxRampUpStep, "cpu-regulator-max-ramp-up-step", o.MaxRampUpStep, "max ramp up step for cpu provision policy")
 	fs.IntVar(&o.MaxRampDownStep, "cpu-regulator-max-ramp-down-step", o.MaxRampDownStep, "max ramp down step for cpu provision policy")
 	fs.DurationVar(&o.MinRampDownPeriod, "cpu-regulator-min-ramp-down-period", o.MinRampDownPeriod, "min ramp down period for cpu provision policy")
+	fs.StringToStringVar(&o.RegulatorNames, "cpu-regulator-names", o.RegulatorNames, "cpu regulator by region type, e.g. share=adaptive; available regulators: default, adaptive, and default regulator is used if not set")
+	fs.Float64Var(&o.AdaptiveRegulatorHysteresisUpBand, "cpu-regulator-adaptive-hysteresis-up-band", o.AdaptiveRegulatorHysteresisUpBand, "cpu cores a requirement must exceed above current value before adaptive regulator scales up")
+	fs.Float64Var(&o.AdaptiveRegulatorHysteresisDownBand, "cpu-regulator-adaptive-hysteresis-down-band", o.AdaptiveRegulatorHysteresisDownBand, "cpu cores a requirement must fall below current value before adaptive regulator scales down")
+	fs.Float64Var(&o.AdaptiveRegulatorSmoothingFactor, "cpu-regulator-adaptive-smoothing-factor", o.AdaptiveRegulatorSmoothingFactor, "weight of latest raw requirement in exponential smoothing of adaptive regulator")
+	fs.IntVar(&o.AdaptiveRegulatorOscillationWindow, "cpu-regulator-adaptive-oscillation-window", o.AdaptiveRegulatorOscillationWindow, "number of latest regulations checked for oscillation by adaptive regulator")
+	fs.IntVar(&o.AdaptiveRegulatorOscillationThreshold, "cpu-regulator-adaptive-oscillation-threshold", o.AdaptiveRegulatorOscillationThreshold, "direction flips within oscillation window to regard requirement as oscillating")
+	fs.Float64Var(&o.AdaptiveRegulatorDampingFactor, "cpu-regulator-adaptive-damping-factor", o.AdaptiveRegulatorDampingFactor, "factor to scale down smoothing and widen hysteresis while requirement oscillates")
 	o.PolicyRama.AddFlags(fs)
+	o.PolicyMPC.AddFlags(fs)
 	fs.BoolVar(&o.EnableControlKnobCPUQuota, "cpu-provision-enable-control-knob-cpu-quota", o.EnableControlKnobCPUQuota, "enable control knob cpu quota for cpu provision policy")
 }
@@ -0,0 +1,155 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provision
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	provisionconfig "github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu/provision"
+)
+
+type PolicyMPCOptions struct {
+	Horizon         int
+	CPUCostWeight   float64
+	MoveCostWeight  float64
+	Resolution      float64
+	MaxRampUpStep   float64
+	MaxRampDownStep float64
+	// IndicatorParams is keyed by spd business indicator name, with value in format of weight:elasticity:smoothing
+	IndicatorParams map[string]string
+	// RegionConstraints is keyed by region owner pool name, with value in format of min:max
+	RegionConstraints map[string]string
+}
+
+func NewPolicyMPCOptions() *PolicyMPCOptions {
+	c := provisionconfig.NewPolicyMPCConfiguration()
+	indicatorParams := make(map[string]string, len(c.IndicatorParams))
+	for name, params := range c.IndicatorParams {
+		indicatorParams[name] = fmt.Sprintf("%v:%v:%v", params.Weight, params.Elasticity, params.Smoothing)
+	}
+	return &PolicyMPCOptions{
+		Horizon:           c.Horizon,
+		CPUCostWeight:     c.CPUCostWeight,
+		MoveCostWeight:    c.MoveCostWeight,
+		Resolution:        c.Resolution,
+		MaxRampUpStep:     c.MaxRampUpStep,
+		MaxRampDownStep:   c.MaxRampDownStep,
+		IndicatorParams:   indicatorParams,
+		RegionConstraints: map[string]string{},
+	}
+}
+
+// AddFlags adds flags to the specified FlagSet.
+func (o *PolicyMPCOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&o.Horizon, "mpc-horizon", o.Horizon,
+		"number of periods predicted by mpc policy")
+	fs.Float64Var(&o.CPUCostWeight, "mpc-cpu-cost-weight", o.CPUCostWeight,
+		"cost weight of cpu in mpc policy, relative to service indicator violations")
+	fs.Float64Var(&o.MoveCostWeight, "mpc-move-cost-weight", o.MoveCostWeight,
+		"cost weight of cpu change between periods in mpc policy")
+	fs.Float64Var(&o.Resolution, "mpc-resolution", o.Resolution,
+		"granularity of candidate cpu requirements in mpc policy")
+	fs.Float64Var(&o.MaxRampUpStep, "mpc-max-ramp-up-step", o.MaxRampUpStep,
+		"max cpu increased in one period by mpc policy")
+	fs.Float64Var(&o.MaxRampDownStep, "mpc-max-ramp-down-step", o.MaxRampDownStep,
+		"max cpu decreased in one period by mpc policy")
+	fs.StringToStringVar(&o.IndicatorParams, "mpc-indicator-params", o.IndicatorParams,
+		"models of spd business indicators in mpc policy keyed by indicator name, in format of name=weight:elasticity:smoothing; "+
+			"elasticity is negative for indicators improved by more cpu, and indicators not listed are ignored")
+	fs.StringToStringVar(&o.RegionConstraints, "mpc-region-constraints", o.RegionConstraints,
+		"cpu bounds of regions in mpc policy keyed by owner pool name, in format of pool=min:max")
+}
+
+// ApplyTo fills up config with options
+func (o *PolicyMPCOptions) ApplyTo(c *provisionconfig.PolicyMPCConfiguration) error {
+	c.Horizon = o.Horizon
+	c.CPUCostWeight = o.CPUCostWeight
+	c.MoveCostWeight = o.MoveCostWeight
+	c.Resolution = o.Resolution
+	c.MaxRampUpStep = o.MaxRampUpStep
+	c.MaxRampDownStep = o.MaxRampDownStep
+
+	indicatorParams := make(map[string]types.MPCIndicatorParams, len(o.IndicatorParams))
+	for name, value := range o.IndicatorParams {
+		params, err := parseMPCIndicatorParams(value)
+		if err != nil {
+			return fmt.Errorf("invalid mpc indicator params of %s: %v", name, err)
+		}
+		indicatorParams[name] = params
+	}
+	c.IndicatorParams = indicatorParams
+
+	constraints := make(map[string]types.MPCRegionConstraint, len(o.RegionConstraints))
+	for pool, value := range o.RegionConstraints {
+		constraint, err := parseMPCRegionConstraint(value)
+		if err != nil {
+			return fmt.Errorf("invalid mpc region constraint of %s: %v", pool, err)
+		}
+		constraints[pool] = constraint
+	}
+	c.RegionConstraints = constraints
+
+	return nil
+}
+
+func parseMPCRegionConstraint(value string) (types.MPCRegionConstraint, error) {
+	bounds := strings.Split(value, ":")
+	if len(bounds) != 2 {
+		return types.MPCRegionConstraint{}, fmt.Errorf("%q is not in format of min:max", value)
+	}
+
+	minCPU, err := strconv.ParseFloat(strings.TrimSpace(bounds[0]), 64)
+	if err != nil {
+		return types.MPCRegionConstraint{}, err
+	}
+	maxCPU, err := strconv.ParseFloat(strings.TrimSpace(bounds[1]), 64)
+	if err != nil {
+		return types.MPCRegionConstraint{}, err
+	}
+	if minCPU < 0 || maxCPU < minCPU {
+		return types.MPCRegionConstraint{}, fmt.Errorf("invalid bounds [%v, %v]", minCPU, maxCPU)
+	}
+
+	return types.MPCRegionConstraint{MinCPU: minCPU, MaxCPU: maxCPU}, nil
+}
+
+func parseMPCIndicatorParams(value string) (types.MPCIndicatorParams, error) {
+	fields := strings.Split(value, ":")
+	if len(fields) != 3 {
+		return types.MPCIndicatorParams{}, fmt.Errorf("%q is not in format of weight:elasticity:smoothing", value)
+	}
+
+	parsed := make([]float64, 0, len(fields))
+	for _, field := range fields {
+		v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return types.MPCIndicatorParams{}, err
+		}
+		parsed = append(parsed, v)
+	}
+
+	params := types.MPCIndicatorParams{Weight: parsed[0], Elasticity: parsed[1], Smoothing: parsed[2]}
+	if params.Weight < 0 || params.Elasticity == 0 || params.Smoothing <= 0 || params.Smoothing > 1 {
+		return types.MPCIndicatorParams{}, fmt.Errorf("invalid params %+v", params)
+	}
+	return params, nil
+}
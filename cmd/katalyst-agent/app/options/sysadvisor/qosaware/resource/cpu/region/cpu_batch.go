@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package region
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu/region"
+)
+
+type CPUBatchOptions struct {
+	ThroughputIndicators    []string
+	MaxThroughputScaleRatio float64
+}
+
+// NewCPUBatchOptions creates a new Options with a default config
+func NewCPUBatchOptions() *CPUBatchOptions {
+	c := region.NewCPUBatchConfiguration()
+	return &CPUBatchOptions{
+		ThroughputIndicators:    c.ThroughputIndicators,
+		MaxThroughputScaleRatio: c.MaxThroughputScaleRatio,
+	}
+}
+
+// AddFlags adds flags to the specified FlagSet.
+func (o *CPUBatchOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVar(&o.ThroughputIndicators, "cpu-batch-throughput-indicators", o.ThroughputIndicators,
+		"spd business indicators regarded as throughput slo, and reclaimed_cores pods with any of them are "+
+			"assigned to batch region; batch region is disabled if it's empty")
+	fs.Float64Var(&o.MaxThroughputScaleRatio, "cpu-batch-max-throughput-scale-ratio", o.MaxThroughputScaleRatio,
+		"max ratio between cpu requirement and cpu usage of batch region when throughput is below its target")
+}
+
+// ApplyTo fills up config with options
+func (o *CPUBatchOptions) ApplyTo(c *region.CPUBatchConfiguration) error {
+	if o.MaxThroughputScaleRatio < 1 {
+		return fmt.Errorf("cpu batch max throughput scale ratio %v is less than 1", o.MaxThroughputScaleRatio)
+	}
+
+	c.ThroughputIndicators = o.ThroughputIndicators
+	c.MaxThroughputScaleRatio = o.MaxThroughputScaleRatio
+	return nil
+}
@@ -26,12 +26,14 @@ import (
 
 type CPURegionOptions struct {
 	CPUShare          *CPUShareOptions
+	CPUBatch          *CPUBatchOptions
 	RestrictRefPolicy map[string]string
 }
 
 func NewCPURegionOptions() *CPURegionOptions {
 	return &CPURegionOptions{
 		CPUShare:          NewCPUShareOptions(),
+		CPUBatch:          NewCPUBatchOptions(),
 		RestrictRefPolicy: map[string]string{string(types.CPUProvisionPolicyRama): string(types.CPUProvisionPolicyDynamicQuota)},
 	}
 }
@@ -40,6 +42,7 @@ func NewCPURegionOptions() *CPURegionOptions {
 func (o *CPURegionOptions) ApplyTo(c *region.CPURegionConfiguration) error {
 	var errList []error
 	errList = append(errList, o.CPUShare.ApplyTo(c.CPUShareConfiguration))
+	errList = append(errList, o.CPUBatch.ApplyTo(c.CPUBatchConfiguration))
 
 	restrictRefPolicy := make(map[types.CPUProvisionPolicyName]types.CPUProvisionPolicyName)
 	for k, v := range o.RestrictRefPolicy {
@@ -52,6 +55,7 @@ func (o *CPURegionOptions) ApplyTo(c *region.CPURegionConfiguration) error {
 // AddFlags adds flags to the specified FlagSet.
 func (o *CPURegionOptions) AddFlags(fs *pflag.FlagSet) {
 	o.CPUShare.AddFlags(fs)
+	o.CPUBatch.AddFlags(fs)
 
 	// AddFlags adds flags to the specified FlagSet.
 	fs.StringToStringVar(&o.RestrictRefPolicy, "region-restrict-ref-policy", o.RestrictRefPolicy,
@@ -27,13 +27,17 @@ type MemoryAdvisorPluginsOptions struct {
 	*CacheReaperOptions
 	*MemoryProvisionerOptions
 	*NumaBalancerOptions
+	*MemoryThrottlerOptions
+	*NUMAMemoryMigratorOptions
 }
 
 func NewMemoryAdvisorPluginsOptions() *MemoryAdvisorPluginsOptions {
 	return &MemoryAdvisorPluginsOptions{
-		CacheReaperOptions:       NewCacheReaperOptions(),
-		MemoryProvisionerOptions: NewMemoryProvisionerOptions(),
-		NumaBalancerOptions:      NewNumaBalancerOptions(),
+		CacheReaperOptions:        NewCacheReaperOptions(),
+		MemoryProvisionerOptions:  NewMemoryProvisionerOptions(),
+		NumaBalancerOptions:       NewNumaBalancerOptions(),
+		MemoryThrottlerOptions:    NewMemoryThrottlerOptions(),
+		NUMAMemoryMigratorOptions: NewNUMAMemoryMigratorOptions(),
 	}
 }
 
@@ -41,6 +45,8 @@ func (o *MemoryAdvisorPluginsOptions) AddFlags(fs *pflag.FlagSet) {
 	o.CacheReaperOptions.AddFlags(fs)
 	o.MemoryProvisionerOptions.AddFlags(fs)
 	o.NumaBalancerOptions.AddFlags(fs)
+	o.MemoryThrottlerOptions.AddFlags(fs)
+	o.NUMAMemoryMigratorOptions.AddFlags(fs)
 }
 
 func (o *MemoryAdvisorPluginsOptions) ApplyTo(c *plugins.MemoryAdvisorPluginsConfiguration) error {
@@ -48,5 +54,7 @@ func (o *MemoryAdvisorPluginsOptions) ApplyTo(c *plugins.MemoryAdvisorPluginsCon
 	errList = append(errList, o.CacheReaperOptions.ApplyTo(c.CacheReaperConfiguration))
 	errList = append(errList, o.MemoryProvisionerOptions.ApplyTo(c.MemoryProvisionerConfiguration))
 	errList = append(errList, o.NumaBalancerOptions.ApplyTo(c.NumaBalancerConfiguration))
+	errList = append(errList, o.MemoryThrottlerOptions.ApplyTo(c.MemoryThrottlerConfiguration))
+	errList = append(errList, o.NUMAMemoryMigratorOptions.ApplyTo(c.NUMAMemoryMigratorConfiguration))
 	return errors.NewAggregate(errList)
 }
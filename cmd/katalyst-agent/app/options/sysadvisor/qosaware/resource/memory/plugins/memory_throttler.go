@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"github.com/spf13/pflag"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/memory/plugins"
+)
+
+type MemoryThrottlerOptions struct {
+	PSIAvg60Threshold     float64
+	NUMAPSIAvg60Threshold float64
+	ThrottleStepRatio     float64
+	RelaxStepRatio        float64
+	MinHighRatio          float64
+	ThrottledSharedPools  []string
+}
+
+func NewMemoryThrottlerOptions() *MemoryThrottlerOptions {
+	return &MemoryThrottlerOptions{
+		PSIAvg60Threshold:     10,
+		NUMAPSIAvg60Threshold: 10,
+		ThrottleStepRatio:     0.05,
+		RelaxStepRatio:        0.1,
+		MinHighRatio:          0.5,
+		ThrottledSharedPools:  []string{},
+	}
+}
+
+func (o *MemoryThrottlerOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.Float64Var(&o.PSIAvg60Threshold, "memory-throttler-psi-avg60-threshold", o.PSIAvg60Threshold,
+		"the threshold of node memory psi some avg60, memory-throttler starts throttling above it")
+	fs.Float64Var(&o.NUMAPSIAvg60Threshold, "memory-throttler-numa-psi-avg60-threshold", o.NUMAPSIAvg60Threshold,
+		"the threshold of NUMA memory psi some avg60, which is the max psi of non-throttled containers on the NUMA, "+
+			"memory-throttler starts throttling containers on the NUMA above it")
+	fs.Float64Var(&o.ThrottleStepRatio, "memory-throttler-throttle-step-ratio", o.ThrottleStepRatio,
+		"the ratio that memory.high decreases by in each round under memory pressure")
+	fs.Float64Var(&o.RelaxStepRatio, "memory-throttler-relax-step-ratio", o.RelaxStepRatio,
+		"the ratio of usage before throttling that memory.high increases by in each round without memory pressure")
+	fs.Float64Var(&o.MinHighRatio, "memory-throttler-min-high-ratio", o.MinHighRatio,
+		"the minimum ratio of memory.high to usage before throttling")
+	fs.StringSliceVar(&o.ThrottledSharedPools, "memory-throttler-throttled-shared-pools", o.ThrottledSharedPools,
+		"the low-priority shared pools whose containers can be throttled by memory-throttler")
+}
+
+func (o *MemoryThrottlerOptions) ApplyTo(c *plugins.MemoryThrottlerConfiguration) error {
+	c.PSIAvg60Threshold = o.PSIAvg60Threshold
+	c.NUMAPSIAvg60Threshold = o.NUMAPSIAvg60Threshold
+	c.ThrottleStepRatio = o.ThrottleStepRatio
+	c.RelaxStepRatio = o.RelaxStepRatio
+	c.MinHighRatio = o.MinHighRatio
+	c.ThrottledSharedPools = o.ThrottledSharedPools
+	return nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/memory/plugins"
+)
+
+type NUMAMemoryMigratorOptions struct {
+	RemoteMemoryRatioThreshold   float64
+	MaxMigrateBytesPerRound      int64
+	MaxMigrateContainersPerRound int
+	ContainerMigrateInterval     time.Duration
+	BandwidthPressureThreshold   float64
+}
+
+func NewNUMAMemoryMigratorOptions() *NUMAMemoryMigratorOptions {
+	return &NUMAMemoryMigratorOptions{
+		RemoteMemoryRatioThreshold:   0.3,
+		MaxMigrateBytesPerRound:      2 << 30,
+		MaxMigrateContainersPerRound: 2,
+		ContainerMigrateInterval:     10 * time.Minute,
+		BandwidthPressureThreshold:   0.7,
+	}
+}
+
+func (o *NUMAMemoryMigratorOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.Float64Var(&o.RemoteMemoryRatioThreshold, "numa-memory-migrator-remote-memory-ratio-threshold", o.RemoteMemoryRatioThreshold,
+		"the ratio of memory resided on NUMA nodes outside of container cpuset, above which pages of the container are migrated")
+	fs.Int64Var(&o.MaxMigrateBytesPerRound, "numa-memory-migrator-max-migrate-bytes-per-round", o.MaxMigrateBytesPerRound,
+		"the max total remote memory of containers to migrate in each round, and containers with more remote memory are migrated partially")
+	fs.IntVar(&o.MaxMigrateContainersPerRound, "numa-memory-migrator-max-migrate-containers-per-round", o.MaxMigrateContainersPerRound,
+		"the max number of containers to migrate in each round")
+	fs.DurationVar(&o.ContainerMigrateInterval, "numa-memory-migrator-container-migrate-interval", o.ContainerMigrateInterval,
+		"the minimum interval to migrate pages of the same container")
+	fs.Float64Var(&o.BandwidthPressureThreshold, "numa-memory-migrator-bandwidth-pressure-threshold", o.BandwidthPressureThreshold,
+		"the ratio of memory bandwidth to theoretical bandwidth of a NUMA node, above which migration from or to it is paused")
+}
+
+func (o *NUMAMemoryMigratorOptions) ApplyTo(c *plugins.NUMAMemoryMigratorConfiguration) error {
+	c.RemoteMemoryRatioThreshold = o.RemoteMemoryRatioThreshold
+	c.MaxMigrateBytesPerRound = o.MaxMigrateBytesPerRound
+	c.MaxMigrateContainersPerRound = o.MaxMigrateContainersPerRound
+	c.ContainerMigrateInterval = o.ContainerMigrateInterval
+	c.BandwidthPressureThreshold = o.BandwidthPressureThreshold
+	return nil
+}
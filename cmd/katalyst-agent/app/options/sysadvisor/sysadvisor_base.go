@@ -18,6 +18,7 @@ package sysadvisor
 
 import (
 	"fmt"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/errors"
 	cliflag "k8s.io/component-base/cli/flag"
@@ -39,6 +40,7 @@ type GenericSysAdvisorOptions struct {
 	ClearStateFileDirectory     bool
 	EnableShareCoresNumaBinding bool
 	SkipStateCorruption         bool
+	StateWarmStartTTL           time.Duration
 }
 
 // NewGenericSysAdvisorOptions creates a new Options with a default config.
@@ -54,6 +56,7 @@ func NewGenericSysAdvisorOptions() *GenericSysAdvisorOptions {
 		ClearStateFileDirectory:     false,
 		EnableShareCoresNumaBinding: true,
 		SkipStateCorruption:         false,
+		StateWarmStartTTL:           0,
 	}
 }
 
@@ -68,6 +71,8 @@ func (o *GenericSysAdvisorOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	fs.BoolVar(&o.ClearStateFileDirectory, "clear-state-dir", o.ClearStateFileDirectory, "clear state file when starting up (only for rollback)")
 	fs.BoolVar(&o.EnableShareCoresNumaBinding, "enable-share-cores-numa-binding", o.EnableShareCoresNumaBinding, "enable share cores with NUMA binding feature")
 	fs.BoolVar(&o.SkipStateCorruption, "skip-state-corruption", o.SkipStateCorruption, "skip meta cache state corruption")
+	fs.DurationVar(&o.StateWarmStartTTL, "state-warm-start-ttl", o.StateWarmStartTTL,
+		"max age of regulator history and headroom restored from state file to warm start advisors, 0 means disabled")
 }
 
 // ApplyTo fills up config with options
@@ -77,6 +82,7 @@ func (o *GenericSysAdvisorOptions) ApplyTo(c *sysadvisor.GenericSysAdvisorConfig
 	c.ClearStateFileDirectory = o.ClearStateFileDirectory
 	c.EnableShareCoresNumaBinding = o.EnableShareCoresNumaBinding
 	c.SkipStateCorruption = o.SkipStateCorruption
+	c.StateWarmStartTTL = o.StateWarmStartTTL
 	return nil
 }
 
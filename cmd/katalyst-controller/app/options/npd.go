@@ -17,15 +17,24 @@ limitations under the License.
 package options
 
 import (
+	"time"
+
 	cliflag "k8s.io/component-base/cli/flag"
 
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	apimetricnode "github.com/kubewharf/katalyst-api/pkg/metric/node"
+	apimetricpod "github.com/kubewharf/katalyst-api/pkg/metric/pod"
 	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/util/datasource/prometheus"
 )
 
 type NPDOptions struct {
 	NPDMetricsPlugins     []string
 	EnableScopeDuplicated bool
 	SyncWorkers           int
+
+	*LoadAwareMetricsPluginOptions
 }
 
 func NewNPDOptions() *NPDOptions {
@@ -33,6 +42,8 @@ func NewNPDOptions() *NPDOptions {
 		NPDMetricsPlugins:     []string{},
 		EnableScopeDuplicated: false,
 		SyncWorkers:           1,
+
+		LoadAwareMetricsPluginOptions: NewLoadAwareMetricsPluginOptions(),
 	}
 }
 
@@ -45,20 +56,170 @@ func (o *NPDOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 		"Whether metrics with the same scope can be updated by multiple plugins")
 	fs.IntVar(&o.SyncWorkers, "npd-sync-workers", o.SyncWorkers,
 		"Number of workers to sync npd status")
+
+	o.LoadAwareMetricsPluginOptions.AddFlags(fss)
 }
 
 func (o *NPDOptions) ApplyTo(c *controller.NPDConfig) error {
 	c.NPDMetricsPlugins = o.NPDMetricsPlugins
 	c.EnableScopeDuplicated = o.EnableScopeDuplicated
 	c.SyncWorkers = o.SyncWorkers
+
+	if err := o.LoadAwareMetricsPluginOptions.ApplyTo(c.LoadAwareMetricsPluginConfig); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (o *NPDOptions) Config() (*controller.NPDConfig, error) {
-	c := &controller.NPDConfig{}
+	c := controller.NewNPDConfig()
 	if err := o.ApplyTo(c); err != nil {
 		return nil, err
 	}
 
 	return c, nil
 }
+
+// LoadAwareMetricsPluginOptions holds the configurations for load aware metrics plugin.
+type LoadAwareMetricsPluginOptions struct {
+	// available datasource: custom-metric, prom
+	DataSource string
+	// DataSourcePromConfig is the prometheus datasource config
+	DataSourcePromConfig prometheus.PromConfig
+
+	SyncPeriod       time.Duration
+	NodeMetricsScope string
+	PodMetricsScope  string
+
+	Windows     []time.Duration
+	Step        time.Duration
+	Aggregators []string
+
+	NodeMetricQueries map[string]string
+	PodMetricQueries  map[string]string
+	NodeCustomMetrics map[string]string
+	PodCustomMetrics  map[string]string
+	NodeLabel         string
+	NamespaceLabel    string
+	PodLabel          string
+}
+
+func NewLoadAwareMetricsPluginOptions() *LoadAwareMetricsPluginOptions {
+	return &LoadAwareMetricsPluginOptions{
+		DataSource: "custom-metric",
+		DataSourcePromConfig: prometheus.PromConfig{
+			KeepAlive:                   60 * time.Second,
+			Timeout:                     3 * time.Minute,
+			BRateLimit:                  false,
+			MaxPointsLimitPerTimeSeries: 11000,
+		},
+
+		SyncPeriod:       time.Minute,
+		NodeMetricsScope: consts.NPDScopeLoadAware,
+		PodMetricsScope:  consts.NPDScopeLoadAware,
+
+		Windows: []time.Duration{15 * time.Minute, time.Hour},
+		Step:    time.Minute,
+		Aggregators: []string{
+			string(v1alpha1.AggregatorAvg),
+			string(v1alpha1.AggregatorMax),
+			string(v1alpha1.AggregatorP95),
+		},
+
+		// usage of node is accounted by the root cgroup in cadvisor
+		NodeMetricQueries: map[string]string{
+			consts.NPDMetricNameCPUUsage:             `sum by (node) (rate(container_cpu_usage_seconds_total{id="/"}[2m]))`,
+			consts.NPDMetricNameMemoryUsage:          `sum by (node) (container_memory_working_set_bytes{id="/"})`,
+			consts.NPDMetricNameNetworkReceiveBytes:  `sum by (node) (rate(container_network_receive_bytes_total{id="/"}[2m]))`,
+			consts.NPDMetricNameNetworkTransmitBytes: `sum by (node) (rate(container_network_transmit_bytes_total{id="/"}[2m]))`,
+		},
+		PodMetricQueries: map[string]string{
+			consts.NPDMetricNameCPUUsage:             `sum by (namespace, pod) (rate(container_cpu_usage_seconds_total{container!=""}[2m]))`,
+			consts.NPDMetricNameMemoryUsage:          `sum by (namespace, pod) (container_memory_working_set_bytes{container!=""})`,
+			consts.NPDMetricNameNetworkReceiveBytes:  `sum by (namespace, pod) (rate(container_network_receive_bytes_total{pod!=""}[2m]))`,
+			consts.NPDMetricNameNetworkTransmitBytes: `sum by (namespace, pod) (rate(container_network_transmit_bytes_total{pod!=""}[2m]))`,
+		},
+		// usage reported by katalyst agents into the custom metric store, and memory usage
+		// of node is accounted by total minus available memory
+		NodeCustomMetrics: map[string]string{
+			consts.NPDMetricNameCPUUsage: apimetricnode.CustomMetricNodeCPUUsage,
+			consts.NPDMetricNameMemoryUsage: apimetricnode.CustomMetricNodeMemoryTotal + "-" +
+				apimetricnode.CustomMetricNodeMemoryAvailable,
+		},
+		PodCustomMetrics: map[string]string{
+			consts.NPDMetricNameCPUUsage:    apimetricpod.CustomMetricPodCPUUsage,
+			consts.NPDMetricNameMemoryUsage: apimetricpod.CustomMetricPodMemoryUsage,
+		},
+		NodeLabel:      "node",
+		NamespaceLabel: "namespace",
+		PodLabel:       "pod",
+	}
+}
+
+// AddFlags adds flags to the specified FlagSet.
+func (o *LoadAwareMetricsPluginOptions) AddFlags(fss *cliflag.NamedFlagSets) {
+	fs := fss.FlagSet("npd-load-aware")
+
+	fs.StringVar(&o.DataSource, "npd-load-aware-datasource", o.DataSource, "available datasource: custom-metric, prom")
+	fs.StringVar(&o.DataSourcePromConfig.Address, "npd-load-aware-prometheus-address", o.DataSourcePromConfig.Address, "prometheus address")
+	fs.StringVar(&o.DataSourcePromConfig.Auth.Type, "npd-load-aware-prometheus-auth-type", o.DataSourcePromConfig.Auth.Type, "prometheus auth type")
+	fs.StringVar(&o.DataSourcePromConfig.Auth.Username, "npd-load-aware-prometheus-auth-username", o.DataSourcePromConfig.Auth.Username, "prometheus auth username")
+	fs.StringVar(&o.DataSourcePromConfig.Auth.Password, "npd-load-aware-prometheus-auth-password", o.DataSourcePromConfig.Auth.Password, "prometheus auth password")
+	fs.StringVar(&o.DataSourcePromConfig.Auth.BearerToken, "npd-load-aware-prometheus-auth-bearertoken", o.DataSourcePromConfig.Auth.BearerToken, "prometheus auth bearertoken")
+	fs.DurationVar(&o.DataSourcePromConfig.KeepAlive, "npd-load-aware-prometheus-keepalive", o.DataSourcePromConfig.KeepAlive, "prometheus keep alive")
+	fs.DurationVar(&o.DataSourcePromConfig.Timeout, "npd-load-aware-prometheus-timeout", o.DataSourcePromConfig.Timeout, "prometheus timeout")
+	fs.BoolVar(&o.DataSourcePromConfig.BRateLimit, "npd-load-aware-prometheus-bratelimit", o.DataSourcePromConfig.BRateLimit, "prometheus bratelimit")
+	fs.IntVar(&o.DataSourcePromConfig.MaxPointsLimitPerTimeSeries, "npd-load-aware-prometheus-maxpoints", o.DataSourcePromConfig.MaxPointsLimitPerTimeSeries, "prometheus max points limit per time series")
+
+	fs.DurationVar(&o.SyncPeriod, "npd-load-aware-sync-period", o.SyncPeriod,
+		"Period for load aware metrics plugin to refresh load profiles")
+	fs.StringVar(&o.NodeMetricsScope, "npd-load-aware-node-metrics-scope", o.NodeMetricsScope,
+		"The npd scope of node load profiles")
+	fs.StringVar(&o.PodMetricsScope, "npd-load-aware-pod-metrics-scope", o.PodMetricsScope,
+		"The npd scope of pod load profiles")
+	fs.DurationSliceVar(&o.Windows, "npd-load-aware-windows", o.Windows,
+		"Time windows that usage is aggregated over, notice that custom-metric datasource only keeps samples "+
+			"in the out-of-data period of the custom metric store (5m by default), so longer windows are "+
+			"actually aggregated over the retained samples")
+	fs.DurationVar(&o.Step, "npd-load-aware-step", o.Step,
+		"Resolution of usage samples in each window")
+	fs.StringSliceVar(&o.Aggregators, "npd-load-aware-aggregators", o.Aggregators,
+		"Aggregators of usage in each window, available: avg, max, min, count, p99, p95, p90")
+	fs.StringToStringVar(&o.NodeMetricQueries, "npd-load-aware-node-metric-queries", o.NodeMetricQueries,
+		"A map of npd metric name to promql of node usage, and the results should be grouped by node label")
+	fs.StringToStringVar(&o.PodMetricQueries, "npd-load-aware-pod-metric-queries", o.PodMetricQueries,
+		"A map of npd metric name to promql of pod usage, and the results should be grouped by namespace and pod labels")
+	fs.StringToStringVar(&o.NodeCustomMetrics, "npd-load-aware-node-custom-metrics", o.NodeCustomMetrics,
+		"A map of npd metric name to custom metric name of node usage, used by custom-metric datasource, "+
+			"and the difference of two custom metrics can be specified as <minuend>-<subtrahend>")
+	fs.StringToStringVar(&o.PodCustomMetrics, "npd-load-aware-pod-custom-metrics", o.PodCustomMetrics,
+		"A map of npd metric name to custom metric name of pod usage, used by custom-metric datasource, "+
+			"and the difference of two custom metrics can be specified as <minuend>-<subtrahend>")
+	fs.StringVar(&o.NodeLabel, "npd-load-aware-node-label", o.NodeLabel,
+		"The label of node name in results of node metric queries")
+	fs.StringVar(&o.NamespaceLabel, "npd-load-aware-namespace-label", o.NamespaceLabel,
+		"The label of pod namespace in results of pod metric queries")
+	fs.StringVar(&o.PodLabel, "npd-load-aware-pod-label", o.PodLabel,
+		"The label of pod name in results of pod metric queries")
+}
+
+// ApplyTo fills up config with options
+func (o *LoadAwareMetricsPluginOptions) ApplyTo(c *controller.LoadAwareMetricsPluginConfig) error {
+	c.DataSource = o.DataSource
+	c.DataSourcePromConfig = o.DataSourcePromConfig
+
+	c.SyncPeriod = o.SyncPeriod
+	c.NodeMetricsScope = o.NodeMetricsScope
+	c.PodMetricsScope = o.PodMetricsScope
+	c.Windows = o.Windows
+	c.Step = o.Step
+	c.Aggregators = o.Aggregators
+	c.NodeMetricQueries = o.NodeMetricQueries
+	c.PodMetricQueries = o.PodMetricQueries
+	c.NodeCustomMetrics = o.NodeCustomMetrics
+	c.PodCustomMetrics = o.PodCustomMetrics
+	c.NodeLabel = o.NodeLabel
+	c.NamespaceLabel = o.NamespaceLabel
+	c.PodLabel = o.PodLabel
+	return nil
+}
@@ -21,6 +21,7 @@ import (
 
 	cliflag "k8s.io/component-base/cli/flag"
 
+	apimetricpod "github.com/kubewharf/katalyst-api/pkg/metric/pod"
 	"github.com/kubewharf/katalyst-core/pkg/config/controller"
 	"github.com/kubewharf/katalyst-core/pkg/util/datasource/prometheus"
 )
@@ -36,10 +37,14 @@ type ResourceRecommenderOptions struct {
 	HealthProbeBindPort string `desc:"The port the health probe binds to."`
 	MetricsBindPort     string `desc:"The port the metric endpoint binds to."`
 
-	// available datasource: prom
+	// available datasource: prom, CustomMetric, LocalFile
 	DataSource []string
 	// DataSourcePromConfig is the prometheus datasource config
 	DataSourcePromConfig prometheus.PromConfig
+	// DataSourceCustomMetricConfig is the katalyst custom metric store datasource config
+	DataSourceCustomMetricConfig controller.CustomMetricDatasourceConfig
+	// DataSourceLocalFileConfig is the local snapshot file datasource config
+	DataSourceLocalFileConfig controller.LocalFileDatasourceConfig
 
 	// LogVerbosityLevel to specify log verbosity level. (The default level is 4)
 	// Set it to something larger than 4 if more detailed logs are needed.
@@ -62,6 +67,11 @@ func NewResourceRecommenderOptions() *ResourceRecommenderOptions {
 			BRateLimit:                  false,
 			MaxPointsLimitPerTimeSeries: 11000,
 		},
+		DataSourceCustomMetricConfig: controller.CustomMetricDatasourceConfig{
+			Timeout:          time.Minute,
+			CPUMetricName:    apimetricpod.CustomMetricPodCPUUsage,
+			MemoryMetricName: apimetricpod.CustomMetricPodMemoryUsage,
+		},
 		LogVerbosityLevel: "4",
 	}
 }
@@ -74,7 +84,7 @@ func (o *ResourceRecommenderOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	fs.StringVar(&o.HealthProbeBindPort, "resourcerecommend-health-probe-bind-port", "8080", "The port the health probe binds to.")
 	fs.StringVar(&o.MetricsBindPort, "resourcerecommend-metrics-bind-port", "8081", "The port the metric endpoint binds to.")
 
-	fs.StringSliceVar(&o.DataSource, "resourcerecommend-datasource", []string{"prom"}, "available datasource: prom")
+	fs.StringSliceVar(&o.DataSource, "resourcerecommend-datasource", []string{"prom"}, "available datasource: prom, CustomMetric, LocalFile")
 	fs.StringVar(&o.DataSourcePromConfig.Address, "resourcerecommend-prometheus-address", "", "prometheus address")
 	fs.StringVar(&o.DataSourcePromConfig.Auth.Type, "resourcerecommend-prometheus-auth-type", "", "prometheus auth type")
 	fs.StringVar(&o.DataSourcePromConfig.Auth.Username, "resourcerecommend-prometheus-auth-username", "", "prometheus auth username")
@@ -87,6 +97,16 @@ func (o *ResourceRecommenderOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	fs.StringVar(&o.DataSourcePromConfig.BaseFilter, "resourcerecommend-prometheus-promql-base-filter", "", ""+
 		"Get basic filters in promql for historical usage data. This filter is added to all promql statements. "+
 		"Supports filters format of promql, e.g: group=\\\"Katalyst\\\",cluster=\\\"cfeaf782fasdfe\\\"")
+	fs.StringVar(&o.DataSourceCustomMetricConfig.Address, "resourcerecommend-custom-metric-address", "",
+		"address of katalyst custom metric store server")
+	fs.DurationVar(&o.DataSourceCustomMetricConfig.Timeout, "resourcerecommend-custom-metric-timeout",
+		o.DataSourceCustomMetricConfig.Timeout, "timeout of requests to katalyst custom metric store server")
+	fs.StringVar(&o.DataSourceCustomMetricConfig.CPUMetricName, "resourcerecommend-custom-metric-cpu-metric",
+		o.DataSourceCustomMetricConfig.CPUMetricName, "name of pod cpu usage metric in katalyst custom metric store")
+	fs.StringVar(&o.DataSourceCustomMetricConfig.MemoryMetricName, "resourcerecommend-custom-metric-memory-metric",
+		o.DataSourceCustomMetricConfig.MemoryMetricName, "name of pod memory usage metric in katalyst custom metric store")
+	fs.StringVar(&o.DataSourceLocalFileConfig.Path, "resourcerecommend-local-file-path", "",
+		"path of the local json time series snapshot file, which is used for offline replays")
 	fs.IntVar(&o.RecSyncWorkers, "res-sync-workers", defaultRecSyncWorkers, "num of goroutine to sync recs")
 	fs.DurationVar(&o.RecSyncPeriod, "resource-recommend-resync-period", defaultResourceRecommendReSyncPeriod, "period for recommend controller to sync resource recommend")
 }
@@ -97,6 +117,8 @@ func (o *ResourceRecommenderOptions) ApplyTo(c *controller.ResourceRecommenderCo
 	c.MetricsBindPort = o.MetricsBindPort
 	c.DataSource = o.DataSource
 	c.DataSourcePromConfig = o.DataSourcePromConfig
+	c.DataSourceCustomMetricConfig = o.DataSourceCustomMetricConfig
+	c.DataSourceLocalFileConfig = o.DataSourceLocalFileConfig
 	c.LogVerbosityLevel = o.LogVerbosityLevel
 	c.RecSyncWorkers = o.RecSyncWorkers
 	c.RecSyncPeriod = o.RecSyncPeriod
@@ -18,7 +18,9 @@ package options
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/spf13/pflag"
 	scheduleroptions "k8s.io/kubernetes/cmd/kube-scheduler/app/options"
 
 	"github.com/kubewharf/katalyst-api/pkg/client/informers/externalversions"
@@ -27,22 +29,34 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/client"
 	"github.com/kubewharf/katalyst-core/pkg/config/generic"
 	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/util"
 )
 
 // Options has all the params needed to run a Scheduler
 type Options struct {
 	*scheduleroptions.Options
 	*options.QoSOptions
+
+	// GangWaitingTimeout is the max duration for members of a pod group to wait for the others
+	GangWaitingTimeout time.Duration
 }
 
 // NewOptions returns default scheduler app options.
 func NewOptions() *Options {
 	return &Options{
-		Options:    scheduleroptions.NewOptions(),
-		QoSOptions: options.NewQoSOptions(),
+		Options:            scheduleroptions.NewOptions(),
+		QoSOptions:         options.NewQoSOptions(),
+		GangWaitingTimeout: util.DefaultGangWaitingTimeout,
 	}
 }
 
+// AddFlags adds katalyst specific flags to the specified FlagSet.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	o.QoSOptions.AddFlags(fs)
+	fs.DurationVar(&o.GangWaitingTimeout, "gang-waiting-timeout", o.GangWaitingTimeout,
+		"max duration for members of a pod group to wait for the others before the pod group is rolled back")
+}
+
 // Config return a scheduler config object
 func (o *Options) Config() (*schedulerappconfig.Config, *generic.QoSConfiguration, error) {
 	config, err := o.Options.Config()
@@ -50,6 +64,10 @@ func (o *Options) Config() (*schedulerappconfig.Config, *generic.QoSConfiguratio
 		return nil, nil, err
 	}
 
+	if o.GangWaitingTimeout <= 0 {
+		return nil, nil, fmt.Errorf("gang-waiting-timeout should be positive, got %v", o.GangWaitingTimeout)
+	}
+
 	qosConfig := generic.NewQoSConfiguration()
 	if err = o.QoSOptions.ApplyTo(qosConfig); err != nil {
 		return nil, nil, err
@@ -105,7 +105,7 @@ kube-scheduler is the reference implementation.
 	for _, f := range nfs.FlagSets {
 		fs.AddFlagSet(f)
 	}
-	opts.QoSOptions.AddFlags(fs)
+	opts.AddFlags(fs)
 
 	cols, _, _ := term.TerminalSize(cmd.OutOrStdout())
 	cliflag.SetUsageAndHelpFunc(cmd, *nfs, cols)
@@ -316,6 +316,7 @@ func Setup(ctx context.Context, opts *options.Options, outOfTreeRegistryOptions
 		return nil, nil, err
 	}
 	util.SetQoSConfig(qosConfig)
+	util.SetGangWaitingTimeout(opts.GangWaitingTimeout)
 
 	// Get the completed config
 	cc := c.Complete()
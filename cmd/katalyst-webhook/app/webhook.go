@@ -114,6 +114,7 @@ var WebhooksDisabledByDefault = sets.NewString()
 func NewWebhookInitializers() map[string]InitFunc {
 	webhooks := make(map[string]InitFunc)
 	webhooks[validating.VPAWebhookName] = validating.StartVPAWebhook
+	webhooks[validating.PodWebhookName] = validating.StartPodWebhook
 	webhooks[mutating.PodWebhookName] = mutating.StartPodWebhook
 	webhooks[mutating.NodeWebhookName] = mutating.StartNodeWebhook
 	return webhooks
@@ -64,6 +64,7 @@ require (
 	k8s.io/cri-api v0.25.3
 	k8s.io/klog/v2 v2.80.1
 	k8s.io/kube-aggregator v0.24.6
+	k8s.io/kube-scheduler v0.24.6
 	k8s.io/kubelet v0.24.6
 	k8s.io/kubernetes v1.24.16
 	k8s.io/metrics v0.25.0
@@ -166,7 +167,6 @@ require (
 	k8s.io/cloud-provider v0.24.16 // indirect
 	k8s.io/csi-translation-lib v0.24.16 // indirect
 	k8s.io/kube-openapi v0.0.0-20221012153701-172d655c2280 // indirect
-	k8s.io/kube-scheduler v0.24.6 // indirect
 	k8s.io/mount-utils v0.24.16 // indirect
 	sigs.k8s.io/apiserver-network-proxy/konnectivity-client v0.0.37 // indirect
 	sigs.k8s.io/json v0.0.0-20220713155537-f223a00ba0e2 // indirect
@@ -38,11 +38,15 @@ func GetSinkInitializers() map[string]sink.Initializer {
 
 func init() {
 	RegisterSink(sink.SinkNameLogBased, sink.NewLogBasedAuditSink)
+	RegisterSink(sink.SinkNameFileBased, sink.NewFileBasedAuditSink)
 }
 
 type AuditManager struct {
 	sinks    map[string]sink.Interface
 	eventBus eventbus.EventBus
+
+	// queryAddress is the address to serve audit history from queryable sinks
+	queryAddress string
 }
 
 func NewAuditManager(conf *global.AuditConfiguration, eventbus eventbus.EventBus, emitter metrics.MetricEmitter) *AuditManager {
@@ -57,7 +61,7 @@ func NewAuditManager(conf *global.AuditConfiguration, eventbus eventbus.EventBus
 		}
 	}
 
-	return &AuditManager{sinks: sinks, eventBus: eventbus}
+	return &AuditManager{sinks: sinks, eventBus: eventbus, queryAddress: conf.QueryAddress}
 }
 
 func (m *AuditManager) Run(ctx context.Context) {
@@ -65,5 +69,9 @@ func (m *AuditManager) Run(ctx context.Context) {
 		general.Infof("start sink: %v", n)
 		go i.Run(ctx, m.eventBus)
 	}
+
+	if m.queryAddress != "" && m.hasQueryableSink() {
+		go m.serve(ctx, m.queryAddress)
+	}
 	<-ctx.Done()
 }
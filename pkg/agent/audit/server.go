@@ -0,0 +1,229 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/audit/sink"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	ServingQueryPath  = "/audit/records"
+	ServingReplayPath = "/audit/replay"
+)
+
+const (
+	QueryParamSink        = "sink"
+	QueryParamType        = "type"
+	QueryParamPodUID      = "podUID"
+	QueryParamContainerID = "containerID"
+	QueryParamPath        = "path"
+	QueryParamFile        = "file"
+	QueryParamStart       = "start"
+	QueryParamEnd         = "end"
+	QueryParamLimit       = "limit"
+)
+
+const (
+	defaultQueryLimit = 1000
+	shutdownTimeout   = 5 * time.Second
+)
+
+// serve starts a local http server to query audit history from queryable sinks,
+// and it will be shut down when the context is done.
+func (m *AuditManager) serve(ctx context.Context, address string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(ServingQueryPath, m.handleQuery)
+	mux.HandleFunc(ServingReplayPath, m.handleReplay)
+
+	server := &http.Server{Addr: address, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	general.Infof("start audit query server on %v", address)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		general.Errorf("audit query server exited: %v", err)
+	}
+}
+
+func (m *AuditManager) handleQuery(w http.ResponseWriter, r *http.Request) {
+	if r == nil || r.Method != http.MethodGet || r.URL == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprintf(w, "Request must be GET with Query URL")
+		return
+	}
+
+	q, err := parseQuery(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprintf(w, "Parse query err: %v", err)
+		return
+	}
+
+	sinkName := r.URL.Query().Get(QueryParamSink)
+	queryables := m.getQueryableSinks(sinkName)
+	if len(queryables) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprintf(w, "No queryable audit sink found")
+		return
+	}
+
+	var records []*sink.Record
+	for name, queryable := range queryables {
+		sinkRecords, err := queryable.Query(q)
+		if err != nil {
+			general.Errorf("query audit sink %v err: %v", name, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = fmt.Fprintf(w, "Query sink %v err: %v", name, err)
+			return
+		}
+		records = append(records, sinkRecords...)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+	if len(records) > q.Limit {
+		records = records[len(records)-q.Limit:]
+	}
+
+	bytes, err := json.Marshal(records)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, "Marshal records err: %v", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(bytes)
+}
+
+// handleReplay streams all the records satisfying the query as json lines without limit,
+// and records of each sink are replayed in the order they were persisted.
+func (m *AuditManager) handleReplay(w http.ResponseWriter, r *http.Request) {
+	if r == nil || r.Method != http.MethodGet || r.URL == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprintf(w, "Request must be GET with Query URL")
+		return
+	}
+
+	q, err := parseQuery(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprintf(w, "Parse query err: %v", err)
+		return
+	}
+	// replay is not limited, and the limit param is only meaningful for query
+	q.Limit = 0
+
+	sinkName := r.URL.Query().Get(QueryParamSink)
+	queryables := m.getQueryableSinks(sinkName)
+	if len(queryables) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprintf(w, "No queryable audit sink found")
+		return
+	}
+
+	names := make([]string, 0, len(queryables))
+	for name := range queryables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+	for _, name := range names {
+		err := queryables[name].Replay(q, func(record *sink.Record) error {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			return nil
+		})
+		if err != nil {
+			// the status has been sent, so we can only stop streaming here
+			general.Errorf("replay audit sink %v err: %v", name, err)
+			return
+		}
+	}
+}
+
+// getQueryableSinks returns all queryable sinks if name is empty, otherwise the named one
+func (m *AuditManager) getQueryableSinks(name string) map[string]sink.Queryable {
+	queryables := make(map[string]sink.Queryable)
+	for n, s := range m.sinks {
+		if name != "" && n != name {
+			continue
+		}
+
+		if queryable, ok := s.(sink.Queryable); ok {
+			queryables[n] = queryable
+		}
+	}
+	return queryables
+}
+
+func (m *AuditManager) hasQueryableSink() bool {
+	return len(m.getQueryableSinks("")) > 0
+}
+
+func parseQuery(r *http.Request) (*sink.Query, error) {
+	values := r.URL.Query()
+	q := &sink.Query{
+		Type:        sink.RecordType(values.Get(QueryParamType)),
+		PodUID:      values.Get(QueryParamPodUID),
+		ContainerID: values.Get(QueryParamContainerID),
+		Path:        values.Get(QueryParamPath),
+		File:        values.Get(QueryParamFile),
+		Limit:       defaultQueryLimit,
+	}
+
+	var err error
+	if start := values.Get(QueryParamStart); start != "" {
+		if q.Start, err = time.Parse(time.RFC3339, start); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %v", QueryParamStart, start, err)
+		}
+	}
+	if end := values.Get(QueryParamEnd); end != "" {
+		if q.End, err = time.Parse(time.RFC3339, end); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %v", QueryParamEnd, end, err)
+		}
+	}
+	if limit := values.Get(QueryParamLimit); limit != "" {
+		if q.Limit, err = strconv.Atoi(limit); err != nil || q.Limit <= 0 {
+			return nil, fmt.Errorf("invalid %s %q", QueryParamLimit, limit)
+		}
+	}
+	return q, nil
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/audit/sink"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/global"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+)
+
+func TestAuditManager_handleQuery(t *testing.T) {
+	t.Parallel()
+
+	auditConf := &global.AuditConfiguration{
+		Sinks:      []string{sink.SinkNameLogBased, sink.SinkNameFileBased},
+		BufferSize: 100,
+		FileAuditSinkConfiguration: global.FileAuditSinkConfiguration{
+			Dir: t.TempDir(),
+		},
+	}
+	manager := NewAuditManager(auditConf, eventbus.NewEventBus(100), metrics.DummyMetrics{})
+	require.True(t, manager.hasQueryableSink())
+
+	handler := manager.sinks[sink.SinkNameFileBased].GetHandler()
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, handler(eventbus.RawCGroupEvent{
+			BaseEventImpl: eventbus.BaseEventImpl{Time: now.Add(time.Duration(i) * time.Second)},
+			CGroupPath:    "/kubepods/burstable/pod1234/c1",
+			CGroupFile:    "memory.high",
+		}))
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		wantStatus int
+		wantCount  int
+	}{
+		{
+			name:       "query by pod",
+			method:     http.MethodGet,
+			url:        ServingQueryPath + "?podUID=1234",
+			wantStatus: http.StatusOK,
+			wantCount:  3,
+		},
+		{
+			name:       "query with limit",
+			method:     http.MethodGet,
+			url:        ServingQueryPath + "?containerID=c1&limit=2",
+			wantStatus: http.StatusOK,
+			wantCount:  2,
+		},
+		{
+			name:       "query mismatched pod",
+			method:     http.MethodGet,
+			url:        ServingQueryPath + "?podUID=5678",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid start time",
+			method:     http.MethodGet,
+			url:        ServingQueryPath + "?start=yesterday",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "non-queryable sink",
+			method:     http.MethodGet,
+			url:        ServingQueryPath + "?sink=" + sink.SinkNameLogBased,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "invalid method",
+			method:     http.MethodPost,
+			url:        ServingQueryPath,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			manager.handleQuery(w, httptest.NewRequest(tt.method, tt.url, nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var records []*sink.Record
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &records))
+			assert.Len(t, records, tt.wantCount)
+		})
+	}
+}
+
+func TestAuditManager_handleReplay(t *testing.T) {
+	t.Parallel()
+
+	auditConf := &global.AuditConfiguration{
+		Sinks:      []string{sink.SinkNameFileBased},
+		BufferSize: 100,
+		FileAuditSinkConfiguration: global.FileAuditSinkConfiguration{
+			Dir: t.TempDir(),
+		},
+	}
+	manager := NewAuditManager(auditConf, eventbus.NewEventBus(100), metrics.DummyMetrics{})
+
+	handler := manager.sinks[sink.SinkNameFileBased].GetHandler()
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, handler(eventbus.RawCGroupEvent{
+			BaseEventImpl: eventbus.BaseEventImpl{Time: now.Add(time.Duration(i) * time.Second)},
+			CGroupPath:    "/kubepods/burstable/pod1234/c1",
+			CGroupFile:    "memory.high",
+		}))
+	}
+
+	// limit is ignored by replay
+	w := httptest.NewRecorder()
+	manager.handleReplay(w, httptest.NewRequest(http.MethodGet, ServingReplayPath+"?podUID=1234&limit=1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var times []time.Time
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		record := &sink.Record{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), record))
+		times = append(times, record.Time)
+	}
+	require.Len(t, times, 3)
+	for i := 1; i < len(times); i++ {
+		assert.True(t, times[i-1].Before(times[i]))
+	}
+
+	w = httptest.NewRecorder()
+	manager.handleReplay(w, httptest.NewRequest(http.MethodGet, ServingReplayPath+"?end=yesterday", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -29,25 +29,22 @@ type BaseAuditSink struct {
 	eventbus eventbus.EventBus
 }
 
-func (b *BaseAuditSink) Run(ctx context.Context, bus eventbus.EventBus) {
-	err := bus.Subscribe(consts.TopicNameApplyCGroup, b.GetName(), b.GetBufferSize(), b.GetHandler())
-	if err != nil {
-		general.Errorf("subscribe %v failed", consts.TopicNameApplyCGroup)
-	}
-
-	err = bus.Subscribe(consts.TopicNameApplyProcFS, b.GetName(), b.GetBufferSize(), b.GetHandler())
-	if err != nil {
-		general.Errorf("subscribe %v failed", consts.TopicNameApplyProcFS)
-	}
-
-	err = bus.Subscribe(consts.TopicNameApplySysFS, b.GetName(), b.GetBufferSize(), b.GetHandler())
-	if err != nil {
-		general.Errorf("subscribe %v failed", consts.TopicNameApplySysFS)
-	}
+// auditTopics are the topics of events subscribed by audit sinks
+var auditTopics = []string{
+	consts.TopicNameApplyCGroup,
+	consts.TopicNameApplyProcFS,
+	consts.TopicNameApplySysFS,
+	consts.TopicNameSyscall,
+	consts.TopicNameQRMAllocation,
+	consts.TopicNameEviction,
+}
 
-	err = bus.Subscribe(consts.TopicNameSyscall, b.GetName(), b.GetBufferSize(), b.GetHandler())
-	if err != nil {
-		general.Errorf("subscribe %v failed", consts.TopicNameSyscall)
+func (b *BaseAuditSink) Run(ctx context.Context, bus eventbus.EventBus) {
+	for _, topic := range auditTopics {
+		err := bus.Subscribe(topic, b.GetName(), b.GetBufferSize(), b.GetHandler())
+		if err != nil {
+			general.Errorf("subscribe %v failed", topic)
+		}
 	}
 	<-ctx.Done()
 }
@@ -100,6 +100,8 @@ func TestBaseAuditSink_Run(t *testing.T) {
 			mockBus.On("Subscribe", consts.TopicNameApplyProcFS, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(nil)
 			mockBus.On("Subscribe", consts.TopicNameApplySysFS, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(nil)
 			mockBus.On("Subscribe", consts.TopicNameSyscall, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(nil)
+			mockBus.On("Subscribe", consts.TopicNameQRMAllocation, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(nil)
+			mockBus.On("Subscribe", consts.TopicNameEviction, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(nil)
 
 			// Create a context that can be canceled
 			ctx, cancel := context.WithCancel(context.Background())
@@ -127,6 +129,8 @@ func TestBaseAuditSink_Run(t *testing.T) {
 			mockBus.On("Subscribe", consts.TopicNameApplyProcFS, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(errors.New("subscribe failed"))
 			mockBus.On("Subscribe", consts.TopicNameApplySysFS, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(nil)
 			mockBus.On("Subscribe", consts.TopicNameSyscall, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(errors.New("subscribe failed"))
+			mockBus.On("Subscribe", consts.TopicNameQRMAllocation, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(errors.New("subscribe failed"))
+			mockBus.On("Subscribe", consts.TopicNameEviction, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(errors.New("subscribe failed"))
 
 			// Create a context that can be canceled
 			ctx, cancel := context.WithCancel(context.Background())
@@ -158,6 +162,8 @@ func TestBaseAuditSink_Run(t *testing.T) {
 			mockBus.On("Subscribe", consts.TopicNameApplyProcFS, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(context.Canceled)
 			mockBus.On("Subscribe", consts.TopicNameApplySysFS, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(context.Canceled)
 			mockBus.On("Subscribe", consts.TopicNameSyscall, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(context.Canceled)
+			mockBus.On("Subscribe", consts.TopicNameQRMAllocation, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(context.Canceled)
+			mockBus.On("Subscribe", consts.TopicNameEviction, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(context.Canceled)
 
 			// Run the base sink
 			baseSink.Run(ctx, mockBus)
@@ -167,6 +173,8 @@ func TestBaseAuditSink_Run(t *testing.T) {
 			mockBus.AssertCalled(t, "Subscribe", consts.TopicNameApplyProcFS, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc"))
 			mockBus.AssertCalled(t, "Subscribe", consts.TopicNameApplySysFS, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc"))
 			mockBus.AssertCalled(t, "Subscribe", consts.TopicNameSyscall, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc"))
+			mockBus.AssertCalled(t, "Subscribe", consts.TopicNameQRMAllocation, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc"))
+			mockBus.AssertCalled(t, "Subscribe", consts.TopicNameEviction, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc"))
 		})
 	})
 }
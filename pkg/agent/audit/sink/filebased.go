@@ -0,0 +1,405 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/global"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	SinkNameFileBased = "file"
+
+	defaultAuditFileDir = "/var/log/katalyst/audit"
+
+	activeAuditFileName    = "audit.log"
+	rotatedAuditFilePrefix = "audit-"
+	rotatedAuditFileSuffix = ".log"
+	rotatedAuditTimeLayout = "20060102T150405.000000000"
+
+	// buffered records are flushed periodically instead of on every write,
+	// and querying always flushes them first to see the latest records
+	fileFlushInterval = time.Second
+
+	metricsNameFileSinkWriteFailed = "audit_file_sink_write_failed"
+	metricsNameFileSinkRotated     = "audit_file_sink_rotated"
+)
+
+// FileBasedAuditSink persists audit records as json lines into local files,
+// and the files are rotated by size and age to limit the disk usage.
+type FileBasedAuditSink struct {
+	BaseAuditSink
+
+	mutex      sync.Mutex
+	conf       *global.FileAuditSinkConfiguration
+	bufferSize int
+	emitter    metrics.MetricEmitter
+
+	file        *os.File
+	writer      *bufio.Writer
+	fileSize    int64
+	fileCreated time.Time
+	// closed is set when the sink stops running, and records are dropped after that
+	closed bool
+}
+
+var _ Queryable = &FileBasedAuditSink{}
+
+func NewFileBasedAuditSink(c *global.AuditConfiguration, emitter metrics.MetricEmitter) Interface {
+	conf := c.FileAuditSinkConfiguration
+	if conf.Dir == "" {
+		conf.Dir = defaultAuditFileDir
+	}
+
+	sink := &FileBasedAuditSink{
+		conf:       &conf,
+		bufferSize: c.BufferSize,
+		emitter:    emitter,
+	}
+	sink.Interface = sink
+	return sink
+}
+
+func (f *FileBasedAuditSink) GetHandler() eventbus.ConsumeFunc {
+	return func(event interface{}) error {
+		if event == nil {
+			general.Warningf("ignore nil event")
+			return nil
+		}
+
+		record := NewRecordFromEvent(event)
+		if record == nil {
+			general.Warningf("unsupported event type:%v", reflect.TypeOf(event))
+			return nil
+		}
+
+		if err := f.write(record); err != nil {
+			general.Errorf("[audit file] write record failed: %v", err)
+			_ = f.emitter.StoreInt64(metricsNameFileSinkWriteFailed, 1, metrics.MetricTypeNameCount)
+			return err
+		}
+		return nil
+	}
+}
+
+func (f *FileBasedAuditSink) GetName() string {
+	return SinkNameFileBased
+}
+
+func (f *FileBasedAuditSink) GetBufferSize() int {
+	return f.bufferSize
+}
+
+// Run subscribes audit topics and flushes buffered records periodically,
+// and the active file is closed when the context is done.
+func (f *FileBasedAuditSink) Run(ctx context.Context, bus eventbus.EventBus) {
+	go wait.UntilWithContext(ctx, func(_ context.Context) {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		f.flush()
+	}, fileFlushInterval)
+
+	f.BaseAuditSink.Run(ctx, bus)
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.closeActiveFile(); err != nil {
+		general.Errorf("[audit file] close active file failed: %v", err)
+	}
+	f.closed = true
+}
+
+// Query returns the latest audit records satisfying the query from both active and rotated files,
+// and the records are sorted by time in ascending order. Only the latest q.Limit matched records
+// are kept in memory while reading the files.
+func (f *FileBasedAuditSink) Query(q *Query) ([]*Record, error) {
+	limit := 0
+	if q != nil && q.Limit > 0 {
+		limit = q.Limit
+	}
+
+	var records []*Record
+	err := f.Replay(q, func(record *Record) error {
+		records = append(records, record)
+		// compact the buffer lazily to avoid copying on every record
+		if limit > 0 && len(records) >= 2*limit {
+			records = append(records[:0], records[len(records)-limit:]...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+	if limit > 0 && len(records) > limit {
+		records = records[len(records)-limit:]
+	}
+	return records, nil
+}
+
+// Replay streams all the audit records satisfying the query to fn from the oldest file to the
+// latest one, in the order they were persisted. Rotated files which had been rotated before
+// the start of the query are skipped, since all records in them are older than that.
+func (f *FileBasedAuditSink) Replay(q *Query, fn func(*Record) error) error {
+	f.mutex.Lock()
+	f.flush()
+	files, err := f.listRotatedFiles()
+	f.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if q != nil && !q.Start.IsZero() {
+		first := 0
+		for ; first < len(files); first++ {
+			rotatedTime, err := parseRotatedTime(files[first])
+			if err != nil || !rotatedTime.Before(q.Start) {
+				break
+			}
+		}
+		files = files[first:]
+	}
+	files = append(files, filepath.Join(f.conf.Dir, activeAuditFileName))
+
+	for _, file := range files {
+		if err := readRecords(file, q, fn); err != nil {
+			if os.IsNotExist(err) {
+				// the file may be removed by rotation during replaying
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// flush writes buffered records into the active file; caller must hold the lock.
+func (f *FileBasedAuditSink) flush() {
+	if f.writer == nil {
+		return
+	}
+	if err := f.writer.Flush(); err != nil {
+		general.Warningf("[audit file] flush active file failed: %v", err)
+	}
+}
+
+func (f *FileBasedAuditSink) write(record *Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal record failed: %v", err)
+	}
+	data = append(data, '\n')
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.closed {
+		general.Warningf("[audit file] drop record of %v since the sink is closed", record.Type)
+		return nil
+	}
+
+	if err := f.rotateIfNeeded(int64(len(data))); err != nil {
+		return err
+	}
+
+	n, err := f.writer.Write(data)
+	f.fileSize += int64(n)
+	return err
+}
+
+// rotateIfNeeded opens the active file if it's not opened yet, and rotates it if
+// the size or the age of it exceeds the limit; caller must hold the lock.
+func (f *FileBasedAuditSink) rotateIfNeeded(incoming int64) error {
+	if f.file == nil {
+		if err := f.openActiveFile(); err != nil {
+			return err
+		}
+	}
+
+	maxSize := int64(f.conf.MaxFileSizeMB) << 20
+	sizeExceeded := maxSize > 0 && f.fileSize > 0 && f.fileSize+incoming > maxSize
+	ageExceeded := f.conf.MaxFileAge > 0 && f.fileSize > 0 && time.Since(f.fileCreated) > f.conf.MaxFileAge
+	if !sizeExceeded && !ageExceeded {
+		return nil
+	}
+
+	if err := f.closeActiveFile(); err != nil {
+		return err
+	}
+
+	activePath := filepath.Join(f.conf.Dir, activeAuditFileName)
+	rotatedPath := filepath.Join(f.conf.Dir,
+		rotatedAuditFilePrefix+time.Now().Format(rotatedAuditTimeLayout)+rotatedAuditFileSuffix)
+	if err := os.Rename(activePath, rotatedPath); err != nil {
+		return fmt.Errorf("rotate %s failed: %v", activePath, err)
+	}
+	_ = f.emitter.StoreInt64(metricsNameFileSinkRotated, 1, metrics.MetricTypeNameCount)
+
+	f.cleanRotatedFiles()
+	return f.openActiveFile()
+}
+
+func (f *FileBasedAuditSink) openActiveFile() error {
+	if err := os.MkdirAll(f.conf.Dir, 0o755); err != nil {
+		return fmt.Errorf("create audit dir %s failed: %v", f.conf.Dir, err)
+	}
+
+	activePath := filepath.Join(f.conf.Dir, activeAuditFileName)
+	file, err := os.OpenFile(activePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open %s failed: %v", activePath, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("stat %s failed: %v", activePath, err)
+	}
+
+	f.file = file
+	f.writer = bufio.NewWriter(file)
+	f.fileSize = info.Size()
+	// the creation time is not available for all filesystems, so we regard
+	// the modify time of a non-empty file as its created time conservatively
+	f.fileCreated = time.Now()
+	if info.Size() > 0 {
+		f.fileCreated = info.ModTime()
+	}
+	return nil
+}
+
+func (f *FileBasedAuditSink) closeActiveFile() error {
+	if f.file == nil {
+		return nil
+	}
+
+	flushErr := f.writer.Flush()
+	closeErr := f.file.Close()
+	f.file, f.writer, f.fileSize = nil, nil, 0
+	if flushErr != nil {
+		return flushErr
+	}
+	return closeErr
+}
+
+// cleanRotatedFiles removes rotated files exceeding max backups or retention period
+func (f *FileBasedAuditSink) cleanRotatedFiles() {
+	files, err := f.listRotatedFiles()
+	if err != nil {
+		general.Errorf("[audit file] list rotated files failed: %v", err)
+		return
+	}
+
+	for i, file := range files {
+		expired := false
+		if f.conf.MaxBackups > 0 && len(files)-i > f.conf.MaxBackups {
+			expired = true
+		} else if f.conf.RetentionPeriod > 0 {
+			if rotatedTime, err := parseRotatedTime(file); err == nil && time.Since(rotatedTime) > f.conf.RetentionPeriod {
+				expired = true
+			}
+		}
+
+		if expired {
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				general.Errorf("[audit file] remove %s failed: %v", file, err)
+			} else {
+				general.Infof("[audit file] removed expired audit file %s", file)
+			}
+		}
+	}
+}
+
+// listRotatedFiles returns rotated files sorted from the oldest to the latest
+func (f *FileBasedAuditSink) listRotatedFiles() ([]string, error) {
+	entries, err := os.ReadDir(f.conf.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), rotatedAuditFilePrefix) ||
+			!strings.HasSuffix(entry.Name(), rotatedAuditFileSuffix) {
+			continue
+		}
+		files = append(files, filepath.Join(f.conf.Dir, entry.Name()))
+	}
+
+	// the time layout makes lexical order the same as chronological order
+	sort.Strings(files)
+	return files, nil
+}
+
+func parseRotatedTime(file string) (time.Time, error) {
+	name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(file), rotatedAuditFilePrefix), rotatedAuditFileSuffix)
+	return time.ParseInLocation(rotatedAuditTimeLayout, name, time.Local)
+}
+
+// readRecords reads records from the file line by line, and passes the matched ones to fn
+func readRecords(file string, q *Query, fn func(*Record) error) error {
+	fd, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	scanner := bufio.NewScanner(fd)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		record := &Record{}
+		if err := json.Unmarshal(line, record); err != nil {
+			// skip partially written lines, i.e. the agent crashed while writing
+			general.Warningf("[audit file] skip invalid record in %s: %v", file, err)
+			continue
+		}
+
+		if q.Match(record) {
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,387 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/global"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+)
+
+func newTestFileBasedAuditSink(t *testing.T, conf *global.FileAuditSinkConfiguration) *FileBasedAuditSink {
+	conf.Dir = t.TempDir()
+	s := NewFileBasedAuditSink(&global.AuditConfiguration{
+		BufferSize:                 100,
+		FileAuditSinkConfiguration: *conf,
+	}, metrics.DummyMetrics{})
+	return s.(*FileBasedAuditSink)
+}
+
+func TestFileBasedAuditSink_Query(t *testing.T) {
+	t.Parallel()
+
+	s := newTestFileBasedAuditSink(t, &global.FileAuditSinkConfiguration{})
+	handler := s.GetHandler()
+
+	now := time.Now()
+	events := []interface{}{
+		eventbus.RawCGroupEvent{
+			BaseEventImpl: eventbus.BaseEventImpl{Time: now.Add(-3 * time.Minute)},
+			CGroupPath:    "/sys/fs/cgroup/kubepods/burstable/pod1234/c1",
+			CGroupFile:    "cpu.max",
+			Data:          "100000 100000",
+		},
+		eventbus.RawProcfsEvent{
+			BaseEventImpl: eventbus.BaseEventImpl{Time: now.Add(-2 * time.Minute)},
+			ProcPath:      "/proc/sys/vm",
+			ProcFile:      "drop_caches",
+			Data:          "3",
+		},
+		eventbus.SyscallEvent{
+			BaseEventImpl: eventbus.BaseEventImpl{Time: now.Add(-1 * time.Minute)},
+			PodUID:        "1234",
+			ContainerID:   "c1",
+			Syscall:       "setns",
+		},
+		// unsupported events are ignored
+		"unknown",
+	}
+	for _, event := range events {
+		assert.NoError(t, handler(event))
+	}
+
+	tests := []struct {
+		name  string
+		query *Query
+		want  []RecordType
+	}{
+		{
+			name:  "all records",
+			query: nil,
+			want:  []RecordType{RecordTypeCGroup, RecordTypeProcFS, RecordTypeSyscall},
+		},
+		{
+			name:  "by pod",
+			query: &Query{PodUID: "1234"},
+			want:  []RecordType{RecordTypeCGroup, RecordTypeSyscall},
+		},
+		{
+			name:  "by type",
+			query: &Query{Type: RecordTypeProcFS},
+			want:  []RecordType{RecordTypeProcFS},
+		},
+		{
+			name:  "by path prefix and file",
+			query: &Query{Path: "/sys/fs/cgroup/kubepods", File: "cpu.max"},
+			want:  []RecordType{RecordTypeCGroup},
+		},
+		{
+			name:  "by time range",
+			query: &Query{Start: now.Add(-150 * time.Second), End: now},
+			want:  []RecordType{RecordTypeProcFS, RecordTypeSyscall},
+		},
+		{
+			name:  "latest records with limit",
+			query: &Query{Limit: 1},
+			want:  []RecordType{RecordTypeSyscall},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := s.Query(tt.query)
+			require.NoError(t, err)
+
+			var got []RecordType
+			for _, r := range records {
+				got = append(got, r.Type)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFileBasedAuditSink_Rotate(t *testing.T) {
+	t.Parallel()
+
+	s := newTestFileBasedAuditSink(t, &global.FileAuditSinkConfiguration{
+		MaxFileSizeMB: 1,
+		MaxBackups:    2,
+	})
+	handler := s.GetHandler()
+
+	// each record is larger than 256KiB, so one file holds at most three records
+	data := strings.Repeat("x", 300<<10)
+	for i := 0; i < 20; i++ {
+		require.NoError(t, handler(eventbus.RawSysfsEvent{
+			BaseEventImpl: eventbus.BaseEventImpl{Time: time.Now()},
+			SysPath:       "/sys/kernel/mm",
+			SysFile:       "file",
+			Data:          data,
+		}))
+	}
+
+	rotated, err := s.listRotatedFiles()
+	require.NoError(t, err)
+	assert.Len(t, rotated, 2)
+
+	for _, file := range append(rotated, filepath.Join(s.conf.Dir, activeAuditFileName)) {
+		info, err := os.Stat(file)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, info.Size(), int64(1<<20))
+	}
+
+	// records in removed files are not queryable any more
+	records, err := s.Query(&Query{Type: RecordTypeSysFS})
+	require.NoError(t, err)
+	assert.Less(t, len(records), 20)
+	assert.Greater(t, len(records), 0)
+}
+
+func TestFileBasedAuditSink_Replay(t *testing.T) {
+	t.Parallel()
+
+	s := newTestFileBasedAuditSink(t, &global.FileAuditSinkConfiguration{})
+
+	now := time.Now()
+	writeRotated := func(rotatedTime, recordTime time.Time) {
+		data, err := json.Marshal(&Record{Type: RecordTypeSysFS, Time: recordTime})
+		require.NoError(t, err)
+		file := filepath.Join(s.conf.Dir,
+			rotatedAuditFilePrefix+rotatedTime.Format(rotatedAuditTimeLayout)+rotatedAuditFileSuffix)
+		require.NoError(t, os.WriteFile(file, append(data, '\n'), 0o644))
+	}
+	// the record in a file rotated before the start of query is skipped without reading,
+	// even if its record time is corrupted to be in the query range
+	writeRotated(now.Add(-2*time.Hour), now.Add(-10*time.Minute))
+	writeRotated(now.Add(-30*time.Minute), now.Add(-40*time.Minute))
+	writeRotated(now.Add(-20*time.Minute), now.Add(-25*time.Minute))
+
+	handler := s.GetHandler()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, handler(eventbus.RawSysfsEvent{
+			BaseEventImpl: eventbus.BaseEventImpl{Time: now.Add(time.Duration(i-3) * time.Minute)},
+			SysPath:       "/sys/kernel/mm",
+			SysFile:       "file",
+		}))
+	}
+
+	var replayed []time.Time
+	err := s.Replay(&Query{Start: now.Add(-time.Hour)}, func(r *Record) error {
+		replayed = append(replayed, r.Time)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, replayed, 5)
+	assert.True(t, replayed[0].Equal(now.Add(-40*time.Minute)))
+	assert.True(t, replayed[1].Equal(now.Add(-25*time.Minute)))
+
+	// replay stops once the callback fails
+	count := 0
+	err = s.Replay(nil, func(r *Record) error {
+		count++
+		return assert.AnError
+	})
+	assert.Equal(t, assert.AnError, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestFileBasedAuditSink_Run(t *testing.T) {
+	t.Parallel()
+
+	s := newTestFileBasedAuditSink(t, &global.FileAuditSinkConfiguration{})
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		s.Run(ctx, eventbus.NewEventBus(10))
+		close(stopped)
+	}()
+
+	handler := s.GetHandler()
+	require.NoError(t, handler(eventbus.RawSysfsEvent{
+		BaseEventImpl: eventbus.BaseEventImpl{Time: time.Now()},
+		SysPath:       "/sys/kernel/mm",
+		SysFile:       "file",
+	}))
+
+	// buffered records are flushed periodically
+	activePath := filepath.Join(s.conf.Dir, activeAuditFileName)
+	assert.Eventually(t, func() bool {
+		info, err := os.Stat(activePath)
+		return err == nil && info.Size() > 0
+	}, 5*time.Second, 100*time.Millisecond)
+
+	cancel()
+	<-stopped
+
+	s.mutex.Lock()
+	assert.Nil(t, s.file)
+	assert.True(t, s.closed)
+	s.mutex.Unlock()
+
+	// records are dropped after the sink is closed
+	require.NoError(t, handler(eventbus.RawSysfsEvent{
+		BaseEventImpl: eventbus.BaseEventImpl{Time: time.Now()},
+		SysPath:       "/sys/kernel/mm",
+		SysFile:       "file",
+	}))
+	records, err := s.Query(nil)
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+}
+
+func TestFileBasedAuditSink_CleanExpired(t *testing.T) {
+	t.Parallel()
+
+	s := newTestFileBasedAuditSink(t, &global.FileAuditSinkConfiguration{
+		RetentionPeriod: time.Hour,
+	})
+
+	expired := filepath.Join(s.conf.Dir,
+		rotatedAuditFilePrefix+time.Now().Add(-2*time.Hour).Format(rotatedAuditTimeLayout)+rotatedAuditFileSuffix)
+	retained := filepath.Join(s.conf.Dir,
+		rotatedAuditFilePrefix+time.Now().Add(-30*time.Minute).Format(rotatedAuditTimeLayout)+rotatedAuditFileSuffix)
+	require.NoError(t, os.WriteFile(expired, nil, 0o644))
+	require.NoError(t, os.WriteFile(retained, nil, 0o644))
+
+	s.cleanRotatedFiles()
+
+	_, err := os.Stat(expired)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(retained)
+	assert.NoError(t, err)
+}
+
+func Test_parsePodContainerFromCgroupPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		path          string
+		wantPodUID    string
+		wantContainer string
+	}{
+		{
+			name:          "cgroupfs driver",
+			path:          "/sys/fs/cgroup/cpu/kubepods/burstable/pod0b8d2a2c-1111/abcdef",
+			wantPodUID:    "0b8d2a2c-1111",
+			wantContainer: "abcdef",
+		},
+		{
+			name:          "systemd driver",
+			path:          "/sys/fs/cgroup/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod0b8d2a2c_1111.slice/cri-containerd-abcdef.scope",
+			wantPodUID:    "0b8d2a2c-1111",
+			wantContainer: "abcdef",
+		},
+		{
+			name:       "pod level cgroup",
+			path:       "/kubepods/besteffort/pod0b8d2a2c-1111",
+			wantPodUID: "0b8d2a2c-1111",
+		},
+		{
+			name: "non-pod cgroup",
+			path: "/sys/fs/cgroup/kubepods/burstable",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			podUID, containerID := parsePodContainerFromCgroupPath(tt.path)
+			assert.Equal(t, tt.wantPodUID, podUID)
+			assert.Equal(t, tt.wantContainer, containerID)
+		})
+	}
+}
+
+func TestNewRecordFromEvent(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	tests := []struct {
+		name  string
+		event interface{}
+		want  *Record
+	}{
+		{
+			name: "qrm allocation event",
+			event: eventbus.QRMAllocationEvent{
+				BaseEventImpl: eventbus.BaseEventImpl{Time: now},
+				PluginName:    "qrm_cpu_plugin_dynamic",
+				ResourceName:  "cpu",
+				Action:        eventbus.QRMAllocationActionAdvisor,
+				PoolName:      "share",
+				Result:        "2-5",
+				OldResult:     "2-3",
+			},
+			want: &Record{
+				Time:     now,
+				Type:     RecordTypeQRMAllocation,
+				Plugin:   "qrm_cpu_plugin_dynamic",
+				Resource: "cpu",
+				Action:   eventbus.QRMAllocationActionAdvisor,
+				Pool:     "share",
+				Data:     "2-5",
+				OldData:  "2-3",
+			},
+		},
+		{
+			name: "eviction event",
+			event: eventbus.EvictionEvent{
+				BaseEventImpl:      eventbus.BaseEventImpl{Time: now},
+				Phase:              eventbus.EvictionPhaseKilled,
+				PluginName:         "memory-pressure-eviction-plugin",
+				KillerName:         "evict-api",
+				PodUID:             "1234",
+				Reason:             "memory pressure",
+				GracePeriodSeconds: 30,
+			},
+			want: &Record{
+				Time:        now,
+				Type:        RecordTypeEviction,
+				PodUID:      "1234",
+				Plugin:      "memory-pressure-eviction-plugin",
+				Action:      eventbus.EvictionPhaseKilled,
+				Reason:      "memory pressure",
+				Killer:      "evict-api",
+				GracePeriod: 30,
+			},
+		},
+		{
+			name:  "unsupported event",
+			event: "unknown",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, NewRecordFromEvent(tt.event))
+		})
+	}
+}
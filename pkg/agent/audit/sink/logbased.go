@@ -56,6 +56,10 @@ func (f *LogBasedAuditSink) GetHandler() eventbus.ConsumeFunc {
 			general.Infof("[audit log] procfs event: %+v", e)
 		case eventbus.RawSysfsEvent:
 			general.Infof("[audit log] sysfs event: %+v", e)
+		case eventbus.QRMAllocationEvent:
+			general.Infof("[audit log] qrm allocation event: %+v", e)
+		case eventbus.EvictionEvent:
+			general.Infof("[audit log] eviction event: %+v", e)
 		default:
 			general.Warningf("unsupported event type:%v", reflect.TypeOf(event))
 		}
@@ -0,0 +1,233 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+)
+
+type RecordType string
+
+const (
+	RecordTypeCGroup  RecordType = "cgroup"
+	RecordTypeProcFS  RecordType = "procfs"
+	RecordTypeSysFS   RecordType = "sysfs"
+	RecordTypeSyscall RecordType = "syscall"
+
+	RecordTypeQRMAllocation RecordType = "qrm-allocation"
+	RecordTypeEviction      RecordType = "eviction"
+)
+
+const (
+	podCgroupPathPrefix = "pod"
+	// cgroup directories of pods managed by systemd driver are like kubepods-burstable-pod<uid>.slice,
+	// and dashes in pod uid are replaced by underscores
+	systemdPodCgroupPathSuffix = ".slice"
+	systemdPodCgroupPathSep    = "-pod"
+)
+
+// Record is the structured audit record persisted by sinks
+type Record struct {
+	Time        time.Time             `json:"time"`
+	Type        RecordType            `json:"type"`
+	Cost        time.Duration         `json:"cost,omitempty"`
+	PodUID      string                `json:"podUID,omitempty"`
+	ContainerID string                `json:"containerID,omitempty"`
+	Path        string                `json:"path,omitempty"`
+	File        string                `json:"file,omitempty"`
+	Data        string                `json:"data,omitempty"`
+	OldData     string                `json:"oldData,omitempty"`
+	Syscall     string                `json:"syscall,omitempty"`
+	Logs        []eventbus.SyscallLog `json:"logs,omitempty"`
+
+	// fields below are used by decisions made by qrm plugins and eviction manager,
+	// to correlate low-level records with the higher-level decisions causing them
+	PodNamespace string `json:"podNamespace,omitempty"`
+	PodName      string `json:"podName,omitempty"`
+	Container    string `json:"container,omitempty"`
+	Plugin       string `json:"plugin,omitempty"`
+	Resource     string `json:"resource,omitempty"`
+	Action       string `json:"action,omitempty"`
+	Pool         string `json:"pool,omitempty"`
+	QoSLevel     string `json:"qosLevel,omitempty"`
+	Request      string `json:"request,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+	Killer       string `json:"killer,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	GracePeriod  int64  `json:"gracePeriod,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// Query is the filter to query audit records, and empty fields are ignored
+type Query struct {
+	Type        RecordType
+	PodUID      string
+	ContainerID string
+	// Path matches records whose path has it as prefix, i.e. cgroup path of a pod
+	Path  string
+	File  string
+	Start time.Time
+	End   time.Time
+	// Limit is the max number of records returned, and the latest records are kept
+	Limit int
+}
+
+// NewRecordFromEvent converts events published on event bus into audit record,
+// and returns nil for unsupported events.
+func NewRecordFromEvent(event interface{}) *Record {
+	switch e := event.(type) {
+	case eventbus.RawCGroupEvent:
+		podUID, containerID := parsePodContainerFromCgroupPath(e.CGroupPath)
+		return &Record{
+			Time:        e.Time,
+			Type:        RecordTypeCGroup,
+			Cost:        e.Cost,
+			PodUID:      podUID,
+			ContainerID: containerID,
+			Path:        e.CGroupPath,
+			File:        e.CGroupFile,
+			Data:        e.Data,
+			OldData:     e.OldData,
+		}
+	case eventbus.RawProcfsEvent:
+		return &Record{
+			Time:    e.Time,
+			Type:    RecordTypeProcFS,
+			Cost:    e.Cost,
+			Path:    e.ProcPath,
+			File:    e.ProcFile,
+			Data:    e.Data,
+			OldData: e.OldData,
+		}
+	case eventbus.RawSysfsEvent:
+		return &Record{
+			Time:    e.Time,
+			Type:    RecordTypeSysFS,
+			Cost:    e.Cost,
+			Path:    e.SysPath,
+			File:    e.SysFile,
+			Data:    e.Data,
+			OldData: e.OldData,
+		}
+	case eventbus.SyscallEvent:
+		return &Record{
+			Time:        e.Time,
+			Type:        RecordTypeSyscall,
+			Cost:        e.Cost,
+			PodUID:      e.PodUID,
+			ContainerID: e.ContainerID,
+			Syscall:     e.Syscall,
+			Logs:        e.Logs,
+		}
+	case eventbus.QRMAllocationEvent:
+		return &Record{
+			Time:         e.Time,
+			Type:         RecordTypeQRMAllocation,
+			Cost:         e.Cost,
+			PodUID:       e.PodUID,
+			PodNamespace: e.PodNamespace,
+			PodName:      e.PodName,
+			Container:    e.ContainerName,
+			Path:         e.CgroupPath,
+			Plugin:       e.PluginName,
+			Resource:     e.ResourceName,
+			Action:       e.Action,
+			Pool:         e.PoolName,
+			QoSLevel:     e.QoSLevel,
+			Request:      e.Request,
+			Data:         e.Result,
+			OldData:      e.OldResult,
+			Reason:       e.Reason,
+			Error:        e.Error,
+		}
+	case eventbus.EvictionEvent:
+		return &Record{
+			Time:         e.Time,
+			Type:         RecordTypeEviction,
+			PodUID:       e.PodUID,
+			PodNamespace: e.PodNamespace,
+			PodName:      e.PodName,
+			Plugin:       e.PluginName,
+			Action:       e.Phase,
+			Scope:        e.Scope,
+			Reason:       e.Reason,
+			Killer:       e.KillerName,
+			GracePeriod:  e.GracePeriodSeconds,
+			Error:        e.Error,
+		}
+	default:
+		return nil
+	}
+}
+
+// Match returns true if the record satisfies all conditions in query
+func (q *Query) Match(r *Record) bool {
+	if r == nil {
+		return false
+	}
+
+	if q == nil {
+		return true
+	}
+
+	switch {
+	case q.Type != "" && q.Type != r.Type,
+		q.PodUID != "" && q.PodUID != r.PodUID,
+		q.ContainerID != "" && q.ContainerID != r.ContainerID,
+		q.Path != "" && !strings.HasPrefix(r.Path, q.Path),
+		q.File != "" && q.File != r.File,
+		!q.Start.IsZero() && r.Time.Before(q.Start),
+		!q.End.IsZero() && r.Time.After(q.End):
+		return false
+	}
+	return true
+}
+
+// parsePodContainerFromCgroupPath parses pod uid and container id from cgroup path like
+// /sys/fs/cgroup/cpu/kubepods/burstable/pod<uid>/<container-id> (cgroupfs driver) or
+// /sys/fs/cgroup/kubepods.slice/kubepods-pod<uid>.slice/cri-containerd-<container-id>.scope (systemd driver)
+func parsePodContainerFromCgroupPath(cgroupPath string) (string, string) {
+	segments := strings.Split(filepath.Clean(cgroupPath), string(filepath.Separator))
+	for i, segment := range segments {
+		podUID := ""
+		if strings.HasPrefix(segment, podCgroupPathPrefix) {
+			podUID = strings.TrimPrefix(segment, podCgroupPathPrefix)
+		} else if strings.HasSuffix(segment, systemdPodCgroupPathSuffix) && strings.Contains(segment, systemdPodCgroupPathSep) {
+			segment = strings.TrimSuffix(segment, systemdPodCgroupPathSuffix)
+			podUID = strings.ReplaceAll(segment[strings.LastIndex(segment, systemdPodCgroupPathSep)+len(systemdPodCgroupPathSep):], "_", "-")
+		}
+
+		if podUID == "" {
+			continue
+		}
+
+		containerID := ""
+		if i+1 < len(segments) {
+			containerID = segments[i+1]
+			if idx := strings.LastIndex(containerID, "-"); strings.HasSuffix(containerID, ".scope") && idx >= 0 {
+				containerID = strings.TrimSuffix(containerID[idx+1:], ".scope")
+			}
+		}
+		return podUID, containerID
+	}
+
+	return "", ""
+}
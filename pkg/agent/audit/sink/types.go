@@ -32,3 +32,12 @@ type Interface interface {
 	GetBufferSize() int
 	Run(ctx context.Context, bus eventbus.EventBus)
 }
+
+// Queryable is implemented by sinks which persist audit records and support querying history
+type Queryable interface {
+	// Query returns the latest records satisfying the query, sorted by time in ascending order
+	Query(q *Query) ([]*Record, error)
+	// Replay streams all the records satisfying the query to fn in the order they were persisted,
+	// and it stops once fn returns an error
+	Replay(q *Query, fn func(*Record) error) error
+}
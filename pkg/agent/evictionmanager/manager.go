@@ -44,6 +44,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/plugin"
 	"github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/plugin/memory"
 	"github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/plugin/network"
+	"github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/plugin/psi"
 	"github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/plugin/resource"
 	"github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/plugin/rootfs"
 	"github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/podkiller"
@@ -59,6 +60,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/metrics"
 	"github.com/kubewharf/katalyst-core/pkg/util/credential"
 	"github.com/kubewharf/katalyst-core/pkg/util/credential/authorization"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
 	"github.com/kubewharf/katalyst-core/pkg/util/general"
 	"github.com/kubewharf/katalyst-core/pkg/util/native"
 )
@@ -68,6 +70,7 @@ const (
 	MetricsNameRunningPodCNT          = "running_pod_cnt"
 	MetricsNameCandidatePodCNT        = "candidate_pod_cnt"
 	MetricsNameDryRunVictimPodCNT     = "dryrun_victims_cnt"
+	MetricsNameShadowVictimPodCNT     = "shadow_victims_cnt"
 	MetricsNameRequestConditionCNT    = "request_condition_cnt"
 	MetricsNameEvictionPluginCalled   = "eviction_plugin_called"
 	MetricsNameEvictionPluginValidate = "eviction_plugin_validate"
@@ -117,6 +120,11 @@ type EvictionManger struct {
 	killQueue    rule.EvictionQueue
 	killStrategy rule.EvictionStrategy
 
+	// shadowPodKiller and shadowKillQueue are used for plugins in shadow run mode,
+	// so that their decisions never affect the production ones
+	shadowPodKiller podkiller.PodKiller
+	shadowKillQueue rule.EvictionQueue
+
 	// metaGetter is used to collect metadata universal metaServer.
 	metaGetter *metaserver.MetaServer
 	// emitter is used to emit metrics.
@@ -131,6 +139,8 @@ type EvictionManger struct {
 	conditionsLastObservedAt map[string]conditionObservedAt
 	// thresholdsFirstObservedAt map eviction plugin name to *pluginapi.Condition with firstly observed timestamp.
 	thresholdsFirstObservedAt map[string]thresholdObservedAt
+	// shadowThresholdsFirstObservedAt is the same as thresholdsFirstObservedAt but for shadow run plugins.
+	shadowThresholdsFirstObservedAt map[string]thresholdObservedAt
 
 	cnrTaintReporter control.Reporter
 
@@ -152,6 +162,8 @@ func NewInnerEvictionPluginInitializers() map[string]plugin.InitFunc {
 	innerEvictionPluginInitializers[rootfs.EvictionPluginNamePodRootfsPressure] = rootfs.NewPodRootfsPressureEvictionPlugin
 	innerEvictionPluginInitializers[network.EvictionPluginNameNetwork] = network.NewNICEvictionPlugin
 	innerEvictionPluginInitializers[rootfs.EvictionPluginNamePodRootfsOveruse] = rootfs.NewPodRootfsOveruseEvictionPlugin
+	innerEvictionPluginInitializers[psi.EvictionPluginNameIOPressure] = psi.NewIOPressureEvictionPlugin
+	innerEvictionPluginInitializers[psi.EvictionPluginNameCPUPressure] = psi.NewCPUPressureEvictionPlugin
 	return innerEvictionPluginInitializers
 }
 
@@ -161,6 +173,20 @@ func NewPodKillerInitializers() map[string]podkiller.InitFunc {
 	podKillerInitializers[consts.KillerNameEvictionKiller] = podkiller.NewEvictionAPIKiller
 	podKillerInitializers[consts.KillerNameDeletionKiller] = podkiller.NewDeletionAPIKiller
 	podKillerInitializers[consts.KillerNameContainerKiller] = podkiller.NewContainerKiller
+	podKillerInitializers[consts.KillerNameContainerRestartKiller] = func(conf *pkgconfig.Configuration, client kubernetes.Interface,
+		recorder events.EventRecorder, emitter metrics.MetricEmitter,
+	) (podkiller.Killer, error) {
+		fallbackInitializer, ok := podKillerInitializers[conf.ContainerRestartKillerFallback]
+		if !ok || conf.ContainerRestartKillerFallback == consts.KillerNameContainerRestartKiller {
+			return nil, fmt.Errorf("unsupported fallback killer %v", conf.ContainerRestartKillerFallback)
+		}
+
+		fallback, err := initializeKiller(conf.ContainerRestartKillerFallback, fallbackInitializer, conf, client, recorder, emitter)
+		if err != nil {
+			return nil, err
+		}
+		return podkiller.NewContainerRestartKiller(conf, fallback, client, recorder, emitter)
+	}
 	return podKillerInitializers
 }
 
@@ -230,8 +256,6 @@ func initializeQoSAwareKiller(
 func NewEvictionManager(genericClient *client.GenericClientSet, recorder events.EventRecorder,
 	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter, conf *pkgconfig.Configuration,
 ) (*EvictionManger, error) {
-	queue := rule.NewFIFOEvictionQueue(conf.EvictionBurst)
-
 	killer, err := initializeQoSAwareKiller(NewPodKillerInitializers(), conf, genericClient.KubeClient, recorder, emitter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to init QoS killer: %v", err)
@@ -266,9 +290,15 @@ func NewEvictionManager(genericClient *client.GenericClientSet, recorder events.
 		recordManager = &record.DummyEvictionRecordManager{}
 	}
 
+	if conf.EvictionRespectPDB && conf.RecordManager == "" {
+		general.Warningf("pdb is ignored in eviction queue since no record manager is specified")
+	}
+
 	e := &EvictionManger{
-		killQueue:    queue,
-		killStrategy: rule.NewEvictionStrategyImpl(conf),
+		killQueue:       newEvictionQueue(conf, recordManager, emitter),
+		killStrategy:    rule.NewEvictionStrategyImpl(conf),
+		shadowPodKiller: podkiller.NewShadowPodKiller(recorder, emitter),
+		shadowKillQueue: newEvictionQueue(conf, recordManager, emitter),
 
 		metaGetter:                metaServer,
 		emitter:                   emitter,
@@ -285,6 +315,8 @@ func NewEvictionManager(genericClient *client.GenericClientSet, recorder events.
 		cred:                      credential.DefaultCredential(),
 		auth:                      authorization.DefaultAccessControl(),
 		recordManager:             recordManager,
+
+		shadowThresholdsFirstObservedAt: make(map[string]thresholdObservedAt),
 	}
 
 	cred, credErr := credential.GetCredential(conf.GenericConfiguration, conf.DynamicAgentConfiguration)
@@ -303,6 +335,16 @@ func NewEvictionManager(genericClient *client.GenericClientSet, recorder events.
 	return e, nil
 }
 
+// newEvictionQueue returns the eviction queue paced by rate limits and pdb if any of them is enabled
+func newEvictionQueue(conf *pkgconfig.Configuration, recordManager record.EvictionRecordManager,
+	emitter metrics.MetricEmitter,
+) rule.EvictionQueue {
+	if conf.NodeEvictionQPS > 0 || conf.WorkloadEvictionQPS > 0 || conf.EvictionRespectPDB {
+		return rule.NewRateLimitedEvictionQueue(conf, recordManager, emitter)
+	}
+	return rule.NewFIFOEvictionQueue(conf.EvictionBurst)
+}
+
 func (m *EvictionManger) getEvictionPlugins(genericClient *client.GenericClientSet, recorder events.EventRecorder, metaServer *metaserver.MetaServer,
 	emitter metrics.MetricEmitter, conf *pkgconfig.Configuration, innerEvictionPluginInitializers map[string]plugin.InitFunc,
 ) {
@@ -327,6 +369,7 @@ func (m *EvictionManger) Run(ctx context.Context) {
 	general.RegisterHeartbeatCheck(reportTaintHealthCheckName, reportTaintToleration,
 		general.HealthzCheckStateNotReady, reportTaintToleration)
 	m.podKiller.Start(ctx)
+	m.shadowPodKiller.Start(ctx)
 	m.podNotifier.Start(ctx)
 	for _, endpoint := range m.endpoints {
 		endpoint.Start()
@@ -365,7 +408,7 @@ func (m *EvictionManger) sync(ctx context.Context) {
 	general.Infof(" currently, there are %v candidate pods", len(pods))
 	_ = m.emitter.StoreInt64(MetricsNameCandidatePodCNT, int64(len(pods)), metrics.MetricTypeNameRaw)
 
-	collector, collectErr := m.collectEvictionResult(ctx, pods)
+	collector, collectErr := m.collectEvictionResult(ctx, pods, false)
 	if collectErr != nil {
 		general.Infof("collect eviction result error:%v", collectErr)
 	}
@@ -376,22 +419,58 @@ func (m *EvictionManger) sync(ctx context.Context) {
 		errList = append(errList, notifyErr)
 	}
 
-	evictErr := m.doEvict(collector.getSoftEvictPods(), collector.getForceEvictPods())
+	evictErr := m.doEvict(collector.getSoftEvictPods(), collector.getForceEvictPods(), false)
 	if evictErr != nil {
 		errList = append(errList, evictErr)
 	}
+
+	if len(m.conf.GetDynamicConfiguration().ShadowRun) > 0 {
+		m.shadowSync(ctx, pods)
+	}
+
 	if len(errList) > 0 {
 		err = errors.NewAggregate(errList)
 	}
 }
 
-func (m *EvictionManger) collectEvictionResult(ctx context.Context, pods []*v1.Pod) (*evictionRespCollector, error) {
+// shadowSync runs the same selection logic as sync for plugins in shadow run mode, but pods
+// are handed over to shadow pod killer, and errors never affect the health of eviction manager.
+func (m *EvictionManger) shadowSync(ctx context.Context, pods []*v1.Pod) {
+	collector, collectErr := m.collectEvictionResult(ctx, pods, true)
+	if collectErr != nil {
+		general.Infof("[ShadowRun] collect eviction result error:%v", collectErr)
+	}
+
+	if err := m.doEvict(collector.getSoftEvictPods(), collector.getForceEvictPods(), true); err != nil {
+		general.Errorf("[ShadowRun] evict pods failed: %v", err)
+	}
+}
+
+// isShadowRun returns true if the plugin is in shadow run mode, and dry run takes precedence over it
+func isShadowRun(dryRunPlugins, shadowRunPlugins []string, pluginName string) bool {
+	if len(shadowRunPlugins) == 0 {
+		return false
+	}
+
+	if len(dryRunPlugins) > 0 && general.IsNameEnabled(pluginName, nil, dryRunPlugins) {
+		return false
+	}
+	return general.IsNameEnabled(pluginName, nil, shadowRunPlugins)
+}
+
+// collectEvictionResult collects eviction results from plugins in shadow run mode if shadow is true,
+// otherwise from the others.
+func (m *EvictionManger) collectEvictionResult(ctx context.Context, pods []*v1.Pod, shadow bool) (*evictionRespCollector, error) {
 	dynamicConfig := m.conf.GetDynamicConfiguration()
 	collector := newEvictionRespCollector(dynamicConfig.DryRun, m.conf, m.emitter)
 	var errList []error
 
 	m.endpointLock.RLock()
 	for pluginName, ep := range m.endpoints {
+		if isShadowRun(dynamicConfig.DryRun, dynamicConfig.ShadowRun, pluginName) != shadow {
+			continue
+		}
+
 		_ = m.emitter.StoreInt64(MetricsNameEvictionPluginCalled, 1, metrics.MetricTypeNameCount,
 			metrics.MetricTag{Key: "name", Val: pluginName})
 
@@ -426,21 +505,32 @@ func (m *EvictionManger) collectEvictionResult(ctx context.Context, pods []*v1.P
 
 	// track when a threshold was first observed
 	now := m.clock.Now()
-	thresholdsFirstObservedAt := thresholdsFirstObservedAt(collector.currentMetThresholds, m.thresholdsFirstObservedAt, now)
+	lastThresholdsFirstObservedAt := m.thresholdsFirstObservedAt
+	if shadow {
+		lastThresholdsFirstObservedAt = m.shadowThresholdsFirstObservedAt
+	}
+	thresholdsFirstObservedAt := thresholdsFirstObservedAt(collector.currentMetThresholds, lastThresholdsFirstObservedAt, now)
 	thresholdsMet := thresholdsMetGracePeriod(thresholdsFirstObservedAt, now)
 	logConfirmedThresholdMet(thresholdsMet)
 
-	// track when a condition was last observed
-	conditionsLastObservedAt := conditionsLastObservedAt(collector.currentConditions, m.conditionsLastObservedAt, now)
-	// conditions report true if it has been observed within the transition period window
-	conditions := conditionsObservedSince(conditionsLastObservedAt, m.conf.ConditionTransitionPeriod, now)
-	logConfirmedConditions(conditions)
-
-	m.conditionLock.Lock()
-	m.conditions = conditions
-	m.conditionsLastObservedAt = conditionsLastObservedAt
-	m.thresholdsFirstObservedAt = thresholdsFirstObservedAt
-	m.conditionLock.Unlock()
+	if shadow {
+		// conditions requested by shadow run plugins are never reported
+		m.conditionLock.Lock()
+		m.shadowThresholdsFirstObservedAt = thresholdsFirstObservedAt
+		m.conditionLock.Unlock()
+	} else {
+		// track when a condition was last observed
+		conditionsLastObservedAt := conditionsLastObservedAt(collector.currentConditions, m.conditionsLastObservedAt, now)
+		// conditions report true if it has been observed within the transition period window
+		conditions := conditionsObservedSince(conditionsLastObservedAt, m.conf.ConditionTransitionPeriod, now)
+		logConfirmedConditions(conditions)
+
+		m.conditionLock.Lock()
+		m.conditions = conditions
+		m.conditionsLastObservedAt = conditionsLastObservedAt
+		m.thresholdsFirstObservedAt = thresholdsFirstObservedAt
+		m.conditionLock.Unlock()
+	}
 
 	// get eviction records for all candidate pods
 	records := m.getEvictionRecords(ctx, collector.currentCandidatePods)
@@ -528,7 +618,8 @@ func (m *EvictionManger) doNotify(softEvictPods map[string]*rule.RuledEvictPod)
 	return errors.NewAggregate(errList)
 }
 
-func (m *EvictionManger) doEvict(softEvictPods, forceEvictPods map[string]*rule.RuledEvictPod) error {
+// doEvict chooses pods to be evicted from candidates, and kills them by shadow pod killer if shadow is true
+func (m *EvictionManger) doEvict(softEvictPods, forceEvictPods map[string]*rule.RuledEvictPod, shadow bool) error {
 	softEvictPods = filterOutCandidatePodsWithForcePods(softEvictPods, forceEvictPods)
 	bestSuitedCandidate := m.getEvictPodFromCandidates(softEvictPods)
 	if bestSuitedCandidate != nil && bestSuitedCandidate.Pod != nil {
@@ -539,14 +630,24 @@ func (m *EvictionManger) doEvict(softEvictPods, forceEvictPods map[string]*rule.
 	rpList := rule.RuledEvictPodList{}
 	for _, rp := range forceEvictPods {
 		if rp != nil && rp.EvictPod.Pod != nil && m.killStrategy.CandidateValidate(rp) {
-			general.Infof(" ready to evict %s/%s, reason: %s", rp.Pod.Namespace, rp.Pod.Name, rp.Reason)
+			general.Infof(" ready to evict %s/%s, reason: %s, shadow: %v", rp.Pod.Namespace, rp.Pod.Name, rp.Reason, shadow)
 			rpList = append(rpList, rp)
+			if !shadow {
+				publishCandidateEvent(rp)
+			}
 		} else {
 			general.Warningf(" found nil pod in forceEvictPods")
 		}
 	}
 
-	err := m.killWithRules(rpList)
+	if shadow {
+		// shadow pod killer records pods by itself, so there is no need to emit victim metrics
+		_ = m.emitter.StoreInt64(MetricsNameShadowVictimPodCNT, int64(len(rpList)), metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: "type", Val: "total"})
+		return killWithRules(m.shadowKillQueue, m.shadowPodKiller, rpList)
+	}
+
+	err := killWithRules(m.killQueue, m.podKiller, rpList)
 	if err != nil {
 		general.Errorf(" got err: %v in EvictPods", err)
 		return err
@@ -674,10 +775,26 @@ func (m *EvictionManger) isVersionCompatibleWithPlugin(versions []string) bool {
 
 // killWithRules send killing requests according to pre-defined rules
 // currently, we will use FIFO (with rate limiting) to
-func (m *EvictionManger) killWithRules(rpList rule.RuledEvictPodList) error {
+func killWithRules(queue rule.EvictionQueue, podKiller podkiller.PodKiller, rpList rule.RuledEvictPodList) error {
 	// withdraw previous candidate killing pods by set override params as true
-	m.killQueue.Add(rpList, true)
-	return m.podKiller.EvictPods(m.killQueue.Pop())
+	queue.Add(rpList, true)
+	return podKiller.EvictPods(queue.Pop())
+}
+
+// publishCandidateEvent publishes the pod selected to be evicted to event bus for auditing
+func publishCandidateEvent(rp *rule.RuledEvictPod) {
+	_ = eventbus.GetDefaultEventBus().Publish(consts.TopicNameEviction, eventbus.EvictionEvent{
+		BaseEventImpl: eventbus.BaseEventImpl{
+			Time: time.Now(),
+		},
+		Phase:        eventbus.EvictionPhaseCandidate,
+		PluginName:   rp.EvictionPluginName,
+		Scope:        rp.Scope,
+		PodUID:       string(rp.Pod.UID),
+		PodNamespace: rp.Pod.Namespace,
+		PodName:      rp.Pod.Name,
+		Reason:       rp.Reason,
+	})
 }
 
 // getEvictPodFromCandidates returns the most critical pod to be evicted
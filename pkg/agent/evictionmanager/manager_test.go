@@ -284,6 +284,8 @@ func TestEvictionManger_collectEvictionResult(t *testing.T) {
 	tests := []struct {
 		name               string
 		dryrun             []string
+		shadowrun          []string
+		shadow             bool
 		wantSoftEvictPods  sets.String
 		wantForceEvictPods sets.String
 		wantConditions     sets.String
@@ -346,6 +348,41 @@ func TestEvictionManger_collectEvictionResult(t *testing.T) {
 			wantForceEvictPods: sets.String{},
 			wantConditions:     sets.String{},
 		},
+		{
+			name:      "shadowrun plugin1 without shadow",
+			shadowrun: []string{"plugin1"},
+			wantSoftEvictPods: sets.String{
+				"pod-3": sets.Empty{},
+			},
+			wantForceEvictPods: sets.String{
+				"pod-3": sets.Empty{},
+			},
+			wantConditions: sets.String{
+				"diskPressure": sets.Empty{},
+			},
+		},
+		{
+			name:      "shadowrun plugin1 with shadow",
+			shadowrun: []string{"plugin1"},
+			shadow:    true,
+			wantSoftEvictPods: sets.String{
+				"pod-1": sets.Empty{},
+				"pod-5": sets.Empty{},
+			},
+			wantForceEvictPods: sets.String{
+				"pod-2": sets.Empty{},
+			},
+			wantConditions: sets.String{},
+		},
+		{
+			name:               "dryrun takes precedence over shadowrun",
+			dryrun:             []string{"plugin1"},
+			shadowrun:          []string{"plugin1"},
+			shadow:             true,
+			wantSoftEvictPods:  sets.String{},
+			wantForceEvictPods: sets.String{},
+			wantConditions:     sets.String{},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -354,8 +391,9 @@ func TestEvictionManger_collectEvictionResult(t *testing.T) {
 
 			mgr := makeEvictionManager(t)
 			mgr.conf.GetDynamicConfiguration().DryRun = tt.dryrun
+			mgr.conf.GetDynamicConfiguration().ShadowRun = tt.shadowrun
 
-			collector, _ := mgr.collectEvictionResult(context.Background(), pods)
+			collector, _ := mgr.collectEvictionResult(context.Background(), pods, tt.shadow)
 			gotForceEvictPods := sets.String{}
 			gotSoftEvictPods := sets.String{}
 			gotConditions := sets.String{}
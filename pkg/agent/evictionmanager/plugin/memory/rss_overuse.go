@@ -175,7 +175,7 @@ func (r *RssOveruseEvictionPlugin) GetEvictPods(_ context.Context, request *plug
 
 		if podRss > threshold*float64(memRequest) {
 			result = append(result, &pluginapi.EvictPod{
-				Pod:        pod,
+				Pod:        r.annotateTargetContainer(pod, threshold),
 				Reason:     fmt.Sprintf(RssOveruseEvictionReason, threshold, podRss, memRequest),
 				ForceEvict: false,
 			})
@@ -185,6 +185,38 @@ func (r *RssOveruseEvictionPlugin) GetEvictPods(_ context.Context, request *plug
 	return &pluginapi.GetEvictPodsResponse{EvictPods: result}, nil
 }
 
+// annotateTargetContainer returns a copy of the pod annotated with the container which
+// overuses its memory request most, so that the killer may restart only that container.
+func (r *RssOveruseEvictionPlugin) annotateTargetContainer(pod *v1.Pod, threshold float64) *v1.Pod {
+	var (
+		targetContainer string
+		maxOveruse      float64
+	)
+	for _, container := range pod.Spec.Containers {
+		containerRss, err := helper.GetContainerMetric(r.metaServer.MetricsFetcher, r.emitter, string(pod.UID),
+			container.Name, consts.MetricMemRssContainer, nonExistNumaID)
+		if err != nil {
+			return pod
+		}
+
+		overuse := containerRss - threshold*float64(container.Resources.Requests.Memory().Value())
+		if overuse > maxOveruse {
+			targetContainer, maxOveruse = container.Name, overuse
+		}
+	}
+
+	if targetContainer == "" {
+		return pod
+	}
+
+	podCopy := pod.DeepCopy()
+	if podCopy.Annotations == nil {
+		podCopy.Annotations = make(map[string]string)
+	}
+	podCopy.Annotations[consts.PodAnnotationEvictionTargetContainerKey] = targetContainer
+	return podCopy
+}
+
 func (r *RssOveruseEvictionPlugin) Start() {
 	return
 }
@@ -368,6 +368,10 @@ func TestRssOveruseEvictionPlugin_GetEvictPods(t *testing.T) {
 		gotPods := sets.String{}
 		for i := range evictPods.EvictPods {
 			gotPods.Insert(evictPods.EvictPods[i].Pod.Name)
+			assert.NotEmpty(t, evictPods.EvictPods[i].Pod.Annotations[consts.PodAnnotationEvictionTargetContainerKey])
+		}
+		for _, pod := range pods {
+			assert.Empty(t, pod.Annotations[consts.PodAnnotationEvictionTargetContainerKey])
 		}
 
 		assert.Equal(t, tt.wantedResult, gotPods)
@@ -0,0 +1,45 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package psi
+
+import (
+	"k8s.io/client-go/tools/events"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/plugin"
+	"github.com/kubewharf/katalyst-core/pkg/client"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
+)
+
+const (
+	EvictionPluginNameCPUPressure = "cpu-psi-pressure-eviction-plugin"
+	EvictionScopeCPUPressure      = "CPUPSIPressure"
+	evictionConditionCPUPressure  = "CPUPSIPressure"
+)
+
+// NewCPUPressureEvictionPlugin returns a plugin evicting pods by node level cpu pressure stall information
+func NewCPUPressureEvictionPlugin(_ *client.GenericClientSet, _ events.EventRecorder,
+	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter, conf *config.Configuration,
+) plugin.EvictionPlugin {
+	psiConf := conf.PSIPressureEvictionConfiguration
+	return newPSIPressureEvictionPlugin(EvictionPluginNameCPUPressure, common.PressureResourceCPU,
+		evictionConditionCPUPressure, EvictionScopeCPUPressure,
+		psiConf.EnableCPUPressureEviction, psiConf.CPUPressureThresholds, psiConf,
+		conf.GenericConfiguration.QoSConfiguration, metaServer, emitter)
+}
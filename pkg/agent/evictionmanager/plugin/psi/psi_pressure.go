@@ -0,0 +1,380 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package psi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/procfs"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kubernetes/pkg/kubelet/util/format"
+
+	pluginapi "github.com/kubewharf/katalyst-api/pkg/protocol/evictionplugin/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/eviction"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
+	cgroupmgr "github.com/kubewharf/katalyst-core/pkg/util/cgroup/manager"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+	"github.com/kubewharf/katalyst-core/pkg/util/process"
+	procfsmgr "github.com/kubewharf/katalyst-core/pkg/util/procfs/manager"
+)
+
+const (
+	syncTolerationTurns = 3
+
+	metricsNamePSIPressureMet          = "psi_pressure_eviction_met"
+	metricsNamePSIPressureContribution = "psi_pressure_eviction_pod_contribution"
+
+	metricsTagKeyResource  = "resource"
+	metricsTagKeyThreshold = "threshold"
+)
+
+// nodePSIReader reads node level pressure stall information of the resource
+type nodePSIReader func(resource common.PressureResource) (*common.PressureStats, error)
+
+// podPSIReader reads pod level pressure stall information of the resource
+type podPSIReader func(pod *v1.Pod, resource common.PressureResource) (*common.PressureStats, error)
+
+// thresholdMetResult records the first threshold met by node level pressure
+type thresholdMetResult struct {
+	name      string
+	threshold float64
+	observed  float64
+}
+
+// podStallSample is the cumulative stall time of a pod at a specific time,
+// and it's used to calculate the stall contribution of the pod
+type podStallSample struct {
+	total     uint64
+	timestamp time.Time
+}
+
+// psiPressureEvictionPlugin is the common implementation of io and cpu pressure eviction plugins,
+// it detects node level pressure by /proc/pressure/<resource>, and chooses pods with the highest
+// stall contribution (growth rate of stall time in pod level pressure) as candidates.
+type psiPressureEvictionPlugin struct {
+	*process.StopControl
+
+	pluginName    string
+	resource      common.PressureResource
+	conditionName string
+	evictionScope string
+
+	enabled    bool
+	thresholds eviction.PSIThresholds
+	conf       *eviction.PSIPressureEvictionConfiguration
+
+	metaServer *metaserver.MetaServer
+	emitter    metrics.MetricEmitter
+	qosConf    *generic.QoSConfiguration
+
+	readNodePSI nodePSIReader
+	readPodPSI  podPSIReader
+
+	mutex         sync.RWMutex
+	metResult     *thresholdMetResult
+	podSamples    map[string]podStallSample
+	contributions map[string]float64
+}
+
+func newPSIPressureEvictionPlugin(pluginName string, resource common.PressureResource,
+	conditionName, evictionScope string, enabled bool, thresholds eviction.PSIThresholds,
+	conf *eviction.PSIPressureEvictionConfiguration, qosConf *generic.QoSConfiguration,
+	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
+) *psiPressureEvictionPlugin {
+	return &psiPressureEvictionPlugin{
+		StopControl:   process.NewStopControl(time.Time{}),
+		pluginName:    pluginName,
+		resource:      resource,
+		conditionName: conditionName,
+		evictionScope: evictionScope,
+		enabled:       enabled,
+		thresholds:    thresholds,
+		conf:          conf,
+		metaServer:    metaServer,
+		emitter:       emitter,
+		qosConf:       qosConf,
+		readNodePSI:   readNodePSI,
+		readPodPSI:    readPodPSI,
+		podSamples:    make(map[string]podStallSample),
+		contributions: make(map[string]float64),
+	}
+}
+
+func (p *psiPressureEvictionPlugin) Name() string {
+	if p == nil {
+		return ""
+	}
+	return p.pluginName
+}
+
+func (p *psiPressureEvictionPlugin) Start() {
+	if !p.enabled {
+		general.Infof("%s is disabled", p.pluginName)
+		return
+	}
+
+	// pod level pressure is only exposed by cgroup v2, and contributions of pods
+	// can't be calculated without it, so the plugin is disabled explicitly on v1
+	if !common.CheckCgroup2UnifiedMode() {
+		general.Infof("%s is disabled since psi pressure eviction is not supported on cgroup v1", p.pluginName)
+		p.enabled = false
+		return
+	}
+
+	general.RegisterHeartbeatCheck(p.pluginName, syncTolerationTurns*p.conf.PSIPressureSyncPeriod,
+		general.HealthzCheckStateNotReady, syncTolerationTurns*p.conf.PSIPressureSyncPeriod)
+	go wait.UntilWithContext(context.TODO(), p.sync, p.conf.PSIPressureSyncPeriod)
+}
+
+func (p *psiPressureEvictionPlugin) ThresholdMet(_ context.Context, _ *pluginapi.GetThresholdMetRequest) (*pluginapi.ThresholdMetResponse, error) {
+	resp := &pluginapi.ThresholdMetResponse{
+		MetType:       pluginapi.ThresholdMetType_NOT_MET,
+		EvictionScope: p.evictionScope,
+	}
+	if !p.enabled {
+		return resp, nil
+	}
+
+	p.mutex.RLock()
+	metResult := p.metResult
+	p.mutex.RUnlock()
+	if metResult == nil {
+		return resp, nil
+	}
+
+	// the grace window is guaranteed by eviction manager, which only handles the
+	// threshold when it has been met for the whole grace period
+	return &pluginapi.ThresholdMetResponse{
+		ThresholdValue:     metResult.threshold,
+		ObservedValue:      metResult.observed,
+		ThresholdOperator:  pluginapi.ThresholdOperator_GREATER_THAN,
+		MetType:            pluginapi.ThresholdMetType_HARD_MET,
+		EvictionScope:      p.evictionScope,
+		GracePeriodSeconds: int64(p.conf.PSIPressureGraceWindow.Seconds()),
+		Condition: &pluginapi.Condition{
+			ConditionType: pluginapi.ConditionType_NODE_CONDITION,
+			Effects:       []string{string(v1.TaintEffectNoSchedule)},
+			ConditionName: p.conditionName,
+			MetCondition:  true,
+		},
+	}, nil
+}
+
+func (p *psiPressureEvictionPlugin) GetTopEvictionPods(_ context.Context, request *pluginapi.GetTopEvictionPodsRequest) (*pluginapi.GetTopEvictionPodsResponse, error) {
+	if request == nil {
+		return nil, fmt.Errorf("GetTopEvictionPods got nil request")
+	}
+
+	if !p.enabled || len(request.ActivePods) == 0 {
+		return &pluginapi.GetTopEvictionPodsResponse{}, nil
+	}
+
+	p.mutex.RLock()
+	candidates := make([]*v1.Pod, 0, len(request.ActivePods))
+	contributions := make(map[string]float64, len(request.ActivePods))
+	for _, pod := range request.ActivePods {
+		contribution, ok := p.contributions[string(pod.UID)]
+		if !ok || !p.isCandidatePod(pod) {
+			continue
+		}
+		candidates = append(candidates, pod)
+		contributions[string(pod.UID)] = contribution
+	}
+	p.mutex.RUnlock()
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return contributions[string(candidates[i].UID)] > contributions[string(candidates[j].UID)]
+	})
+	if uint64(len(candidates)) > request.TopN {
+		candidates = candidates[:request.TopN]
+	}
+
+	for _, pod := range candidates {
+		general.Infof("%s chooses pod %s with stall contribution %.2f%%",
+			p.pluginName, format.Pod(pod), contributions[string(pod.UID)])
+	}
+
+	resp := &pluginapi.GetTopEvictionPodsResponse{
+		TargetPods: candidates,
+	}
+	if gracePeriod := p.conf.PSIPressureEvictionGracePeriod; gracePeriod > 0 {
+		resp.DeletionOptions = &pluginapi.DeletionOptions{
+			GracePeriodSeconds: gracePeriod,
+		}
+	}
+	return resp, nil
+}
+
+func (p *psiPressureEvictionPlugin) GetEvictPods(_ context.Context, request *pluginapi.GetEvictPodsRequest) (*pluginapi.GetEvictPodsResponse, error) {
+	if request == nil {
+		return nil, fmt.Errorf("GetEvictPods got nil request")
+	}
+
+	return &pluginapi.GetEvictPodsResponse{}, nil
+}
+
+func (p *psiPressureEvictionPlugin) sync(ctx context.Context) {
+	stats, err := p.readNodePSI(p.resource)
+	_ = general.UpdateHealthzStateByError(p.pluginName, err)
+	if err != nil {
+		general.Errorf("%s read node pressure failed: %v", p.pluginName, err)
+		return
+	}
+
+	metResult := checkThresholds(stats, p.thresholds)
+	if metResult != nil {
+		general.Infof("%s node %s pressure met threshold %s, threshold: %.2f, observed: %.2f",
+			p.pluginName, p.resource, metResult.name, metResult.threshold, metResult.observed)
+		_ = p.emitter.StoreInt64(metricsNamePSIPressureMet, 1, metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: metricsTagKeyResource, Val: string(p.resource)},
+			metrics.MetricTag{Key: metricsTagKeyThreshold, Val: metResult.name})
+	}
+
+	pods, err := p.metaServer.GetPodList(ctx, native.PodIsActive)
+	if err != nil {
+		general.Errorf("%s list pods failed: %v", p.pluginName, err)
+	}
+
+	now := time.Now()
+	podSamples := make(map[string]podStallSample, len(pods))
+	contributions := make(map[string]float64, len(pods))
+
+	p.mutex.RLock()
+	prevSamples := p.podSamples
+	p.mutex.RUnlock()
+
+	for _, pod := range pods {
+		if !p.isCandidatePod(pod) {
+			continue
+		}
+
+		podStats, err := p.readPodPSI(pod, p.resource)
+		if err != nil {
+			general.Warningf("%s read pressure of pod %s failed: %v", p.pluginName, format.Pod(pod), err)
+			continue
+		}
+
+		podUID := string(pod.UID)
+		sample := podStallSample{total: podStats.Some.Total, timestamp: now}
+		podSamples[podUID] = sample
+		contributions[podUID] = calculateContribution(prevSamples[podUID], sample, podStats)
+		_ = p.emitter.StoreFloat64(metricsNamePSIPressureContribution, contributions[podUID], metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: metricsTagKeyResource, Val: string(p.resource)},
+			metrics.MetricTag{Key: "namespace", Val: pod.Namespace},
+			metrics.MetricTag{Key: "name", Val: pod.Name})
+	}
+
+	p.mutex.Lock()
+	p.metResult = metResult
+	p.podSamples = podSamples
+	p.contributions = contributions
+	p.mutex.Unlock()
+}
+
+// isCandidatePod returns true if the pod is in qos levels that can be evicted
+func (p *psiPressureEvictionPlugin) isCandidatePod(pod *v1.Pod) bool {
+	if pod == nil {
+		return false
+	}
+
+	qosLevel, err := p.qosConf.GetQoSLevelForPod(pod)
+	if err != nil {
+		general.Warningf("%s get qos level of pod %s failed: %v", p.pluginName, format.Pod(pod), err)
+		return false
+	}
+	return sets.NewString(p.conf.PSIPressureCandidateQoSLevels...).Has(qosLevel)
+}
+
+// checkThresholds returns the first threshold met by pressure stats, and nil if none is met
+func checkThresholds(stats *common.PressureStats, thresholds eviction.PSIThresholds) *thresholdMetResult {
+	if stats == nil {
+		return nil
+	}
+
+	for _, item := range []thresholdMetResult{
+		{name: "some.avg10", threshold: thresholds.SomeAvg10, observed: stats.Some.Avg10},
+		{name: "some.avg60", threshold: thresholds.SomeAvg60, observed: stats.Some.Avg60},
+		{name: "full.avg10", threshold: thresholds.FullAvg10, observed: stats.Full.Avg10},
+		{name: "full.avg60", threshold: thresholds.FullAvg60, observed: stats.Full.Avg60},
+	} {
+		if item.threshold > 0 && item.observed > item.threshold {
+			result := item
+			return &result
+		}
+	}
+	return nil
+}
+
+// calculateContribution returns the percentage of time the pod was stalled since the previous
+// sample, and it falls back to some.avg10 if the previous sample is not available.
+func calculateContribution(prev, cur podStallSample, stats *common.PressureStats) float64 {
+	elapsed := cur.timestamp.Sub(prev.timestamp)
+	if prev.timestamp.IsZero() || elapsed <= 0 || cur.total < prev.total {
+		return stats.Some.Avg10
+	}
+
+	// total stall time is in microseconds
+	return float64(cur.total-prev.total) / float64(elapsed.Microseconds()) * 100
+}
+
+func readNodePSI(resource common.PressureResource) (*common.PressureStats, error) {
+	psi, err := procfsmgr.GetPSIStatsForResource(string(resource))
+	if err != nil {
+		return nil, err
+	}
+
+	return &common.PressureStats{
+		Some: toPressureAvg(psi.Some),
+		Full: toPressureAvg(psi.Full),
+	}, nil
+}
+
+func readPodPSI(pod *v1.Pod, resource common.PressureResource) (*common.PressureStats, error) {
+	subsys := common.CgroupSubsysCPU
+	if resource == common.PressureResourceIO {
+		subsys = common.CgroupSubsysIO
+	}
+
+	podPath, err := common.GetPodAbsCgroupPath(subsys, string(pod.UID))
+	if err != nil {
+		return nil, err
+	}
+	return cgroupmgr.GetPressureWithAbsolutePath(podPath, resource)
+}
+
+func toPressureAvg(line *procfs.PSILine) common.PressureAvg {
+	if line == nil {
+		return common.PressureAvg{}
+	}
+
+	return common.PressureAvg{
+		Avg10:  line.Avg10,
+		Avg60:  line.Avg60,
+		Avg300: line.Avg300,
+		Total:  line.Total,
+	}
+}
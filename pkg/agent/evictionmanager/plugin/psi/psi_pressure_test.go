@@ -0,0 +1,230 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package psi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	pluginapi "github.com/kubewharf/katalyst-api/pkg/protocol/evictionplugin/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/eviction"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/pod"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
+)
+
+func makePod(uid, qosLevel string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod-" + uid,
+			Namespace: "default",
+			UID:       types.UID(uid),
+			Annotations: map[string]string{
+				apiconsts.PodAnnotationQoSLevelKey: qosLevel,
+			},
+		},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+}
+
+func makeTestPlugin(pods []*v1.Pod, nodeStats *common.PressureStats, podStats map[string]*common.PressureStats) *psiPressureEvictionPlugin {
+	conf := eviction.NewPSIPressureEvictionConfiguration()
+	conf.PSIPressureGraceWindow = time.Minute
+	conf.PSIPressureEvictionGracePeriod = 30
+	conf.PSIPressureCandidateQoSLevels = []string{apiconsts.PodAnnotationQoSLevelReclaimedCores}
+
+	metaServer := &metaserver.MetaServer{MetaAgent: &agent.MetaAgent{
+		PodFetcher: &pod.PodFetcherStub{PodList: pods},
+	}}
+
+	p := newPSIPressureEvictionPlugin(EvictionPluginNameIOPressure, common.PressureResourceIO,
+		evictionConditionIOPressure, EvictionScopeIOPressure, true,
+		eviction.PSIThresholds{SomeAvg10: 60, FullAvg10: 40}, conf,
+		generic.NewQoSConfiguration(), metaServer, metrics.DummyMetrics{})
+	p.readNodePSI = func(common.PressureResource) (*common.PressureStats, error) {
+		return nodeStats, nil
+	}
+	p.readPodPSI = func(pod *v1.Pod, _ common.PressureResource) (*common.PressureStats, error) {
+		stats, ok := podStats[string(pod.UID)]
+		if !ok {
+			return nil, fmt.Errorf("no pressure for pod %s", pod.UID)
+		}
+		return stats, nil
+	}
+	return p
+}
+
+func TestCheckThresholds(t *testing.T) {
+	t.Parallel()
+
+	thresholds := eviction.PSIThresholds{SomeAvg10: 60, SomeAvg60: 50, FullAvg10: 40}
+	tests := []struct {
+		name     string
+		stats    *common.PressureStats
+		wantName string
+	}{
+		{
+			name:  "nil stats",
+			stats: nil,
+		},
+		{
+			name: "below thresholds",
+			stats: &common.PressureStats{
+				Some: common.PressureAvg{Avg10: 10, Avg60: 10},
+				Full: common.PressureAvg{Avg10: 10, Avg60: 90},
+			},
+		},
+		{
+			name: "some avg60 met",
+			stats: &common.PressureStats{
+				Some: common.PressureAvg{Avg10: 10, Avg60: 55},
+			},
+			wantName: "some.avg60",
+		},
+		{
+			name: "first met threshold returned",
+			stats: &common.PressureStats{
+				Some: common.PressureAvg{Avg10: 70},
+				Full: common.PressureAvg{Avg10: 50},
+			},
+			wantName: "some.avg10",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := checkThresholds(tt.stats, thresholds)
+			if tt.wantName == "" {
+				assert.Nil(t, result)
+				return
+			}
+			require.NotNil(t, result)
+			assert.Equal(t, tt.wantName, result.name)
+		})
+	}
+}
+
+func TestCalculateContribution(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	stats := &common.PressureStats{Some: common.PressureAvg{Avg10: 12}}
+	tests := []struct {
+		name string
+		prev podStallSample
+		cur  podStallSample
+		want float64
+	}{
+		{
+			name: "no previous sample",
+			cur:  podStallSample{total: 1000, timestamp: now},
+			want: 12,
+		},
+		{
+			name: "counter reset",
+			prev: podStallSample{total: 2000, timestamp: now.Add(-time.Second)},
+			cur:  podStallSample{total: 1000, timestamp: now},
+			want: 12,
+		},
+		{
+			name: "stalled half of the time",
+			prev: podStallSample{total: 1000, timestamp: now.Add(-time.Second)},
+			cur:  podStallSample{total: 501000, timestamp: now},
+			want: 50,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.InDelta(t, tt.want, calculateContribution(tt.prev, tt.cur, stats), 1e-6)
+		})
+	}
+}
+
+func TestPSIPressureEvictionPlugin(t *testing.T) {
+	t.Parallel()
+
+	pods := []*v1.Pod{
+		makePod("p1", apiconsts.PodAnnotationQoSLevelReclaimedCores),
+		makePod("p2", apiconsts.PodAnnotationQoSLevelReclaimedCores),
+		makePod("p3", apiconsts.PodAnnotationQoSLevelDedicatedCores),
+	}
+	nodeStats := &common.PressureStats{Some: common.PressureAvg{Avg10: 80}}
+	podStats := map[string]*common.PressureStats{
+		"p1": {Some: common.PressureAvg{Avg10: 10}},
+		"p2": {Some: common.PressureAvg{Avg10: 30}},
+		"p3": {Some: common.PressureAvg{Avg10: 90}},
+	}
+	p := makeTestPlugin(pods, nodeStats, podStats)
+	p.sync(context.TODO())
+
+	met, err := p.ThresholdMet(context.TODO(), &pluginapi.GetThresholdMetRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, pluginapi.ThresholdMetType_HARD_MET, met.MetType)
+	assert.Equal(t, EvictionScopeIOPressure, met.EvictionScope)
+	assert.Equal(t, int64(60), met.GracePeriodSeconds)
+	assert.Equal(t, float64(60), met.ThresholdValue)
+	assert.Equal(t, float64(80), met.ObservedValue)
+
+	resp, err := p.GetTopEvictionPods(context.TODO(), &pluginapi.GetTopEvictionPodsRequest{
+		ActivePods: pods,
+		TopN:       1,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.TargetPods, 1)
+	assert.Equal(t, types.UID("p2"), resp.TargetPods[0].UID)
+	assert.Equal(t, int64(30), resp.DeletionOptions.GracePeriodSeconds)
+
+	// pressure recovered
+	nodeStats.Some.Avg10 = 10
+	p.sync(context.TODO())
+	met, err = p.ThresholdMet(context.TODO(), &pluginapi.GetThresholdMetRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, pluginapi.ThresholdMetType_NOT_MET, met.MetType)
+}
+
+func TestPSIPressureEvictionPluginDisabled(t *testing.T) {
+	t.Parallel()
+
+	pods := []*v1.Pod{makePod("p1", apiconsts.PodAnnotationQoSLevelReclaimedCores)}
+	p := makeTestPlugin(pods, &common.PressureStats{Some: common.PressureAvg{Avg10: 80}},
+		map[string]*common.PressureStats{"p1": {Some: common.PressureAvg{Avg10: 10}}})
+	p.sync(context.TODO())
+	p.enabled = false
+
+	met, err := p.ThresholdMet(context.TODO(), &pluginapi.GetThresholdMetRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, pluginapi.ThresholdMetType_NOT_MET, met.MetType)
+
+	resp, err := p.GetTopEvictionPods(context.TODO(), &pluginapi.GetTopEvictionPodsRequest{ActivePods: pods, TopN: 1})
+	require.NoError(t, err)
+	assert.Empty(t, resp.TargetPods)
+}
@@ -49,6 +49,8 @@ const (
 
 	minRootfsOveruseThreshold                   = 20 * 1024 * 1024 * 1024 // 20GB
 	minRootfsOverusePercentageThreshold float32 = 0.1
+
+	nonExistNumaID = -1
 )
 
 // PodRootfsOveruseEvictionPlugin implements the EvictPlugin interface.
@@ -146,7 +148,7 @@ func (r *PodRootfsOveruseEvictionPlugin) GetEvictPods(_ context.Context, request
 	for i := 0; i < rootfsEvictionConfig.RootfsOveruseEvictionCount && i < len(usageItemList); i++ {
 		item := usageItemList[i]
 		evictPod := &pluginapi.EvictPod{
-			Pod:    item.pod,
+			Pod:    r.annotateTargetContainer(item.pod),
 			Reason: fmt.Sprintf("rootfs overuse threshold met, used: %d, threshold: %d", item.usage, item.threshold),
 		}
 		if deletionOptions.GracePeriodSeconds > 0 {
@@ -158,8 +160,41 @@ func (r *PodRootfsOveruseEvictionPlugin) GetEvictPods(_ context.Context, request
 	return &pluginapi.GetEvictPodsResponse{EvictPods: result}, nil
 }
 
+// annotateTargetContainer returns a copy of the pod annotated with the container which uses
+// rootfs most, so that the killer may restart only that container. The stopped container is
+// also asked to be removed, since its writable layer isn't freed until it's removed.
+func (r *PodRootfsOveruseEvictionPlugin) annotateTargetContainer(pod *v1.Pod) *v1.Pod {
+	var (
+		targetContainer string
+		maxUsed         float64
+	)
+	for _, container := range pod.Spec.Containers {
+		containerRootfsUsed, err := helper.GetContainerMetric(r.metaServer.MetricsFetcher, r.emitter, string(pod.UID),
+			container.Name, consts.MetricsContainerRootfsUsed, nonExistNumaID)
+		if err != nil {
+			return pod
+		}
+
+		if containerRootfsUsed > maxUsed {
+			targetContainer, maxUsed = container.Name, containerRootfsUsed
+		}
+	}
+
+	if targetContainer == "" {
+		return pod
+	}
+
+	podCopy := pod.DeepCopy()
+	if podCopy.Annotations == nil {
+		podCopy.Annotations = make(map[string]string)
+	}
+	podCopy.Annotations[consts.PodAnnotationEvictionTargetContainerKey] = targetContainer
+	podCopy.Annotations[consts.PodAnnotationEvictionRemoveTargetContainerKey] = "true"
+	return podCopy
+}
+
 func (r *PodRootfsOveruseEvictionPlugin) getPodRootfsUsed(pod *v1.Pod) (int64, int64, error) {
-	podRootfsUsed, err := helper.GetPodMetric(r.metaServer.MetricsFetcher, r.emitter, pod, consts.MetricsContainerRootfsUsed, -1)
+	podRootfsUsed, err := helper.GetPodMetric(r.metaServer.MetricsFetcher, r.emitter, pod, consts.MetricsContainerRootfsUsed, nonExistNumaID)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -269,6 +269,15 @@ func TestRootfsOveruseEvictionPlugin_GetEvictPods(t *testing.T) {
 				if string(pod.Pod.UID) != tc.expectResult[i] {
 					t.Errorf("GetEvictPods failed, expect result: %v, got: %v", tc.expectResult, resp.EvictPods)
 				}
+				if pod.Pod.Annotations[consts.PodAnnotationEvictionTargetContainerKey] != "containerName" ||
+					pod.Pod.Annotations[consts.PodAnnotationEvictionRemoveTargetContainerKey] != "true" {
+					t.Errorf("GetEvictPods failed, unexpected annotations: %v", pod.Pod.Annotations)
+				}
+			}
+			for _, pod := range activePods {
+				if _, ok := pod.Annotations[consts.PodAnnotationEvictionTargetContainerKey]; ok {
+					t.Errorf("GetEvictPods modified active pod: %v", pod.UID)
+				}
 			}
 		})
 	}
@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podkiller
+
+import (
+	"encoding/json"
+
+	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager"
+	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager/checksum"
+)
+
+const containerRestartCheckpointName = "container_restart_killer_checkpoint"
+
+var _ checkpointmanager.Checkpoint = &ContainerRestartCheckpoint{}
+
+// ContainerRestartCheckpoint persists restart times of containers restarted by ContainerRestartKiller,
+// and the times are stored as unix nanoseconds to keep the checksum stable across marshaling.
+type ContainerRestartCheckpoint struct {
+	Restarts map[string][]int64 `json:"restarts"`
+	Checksum checksum.Checksum  `json:"checksum"`
+}
+
+func NewContainerRestartCheckpoint() *ContainerRestartCheckpoint {
+	return &ContainerRestartCheckpoint{
+		Restarts: make(map[string][]int64),
+	}
+}
+
+// MarshalCheckpoint returns marshaled checkpoint
+func (cp *ContainerRestartCheckpoint) MarshalCheckpoint() ([]byte, error) {
+	// make sure checksum wasn't set before, so it doesn't affect output checksum
+	cp.Checksum = 0
+	cp.Checksum = checksum.New(cp)
+	return json.Marshal(*cp)
+}
+
+// UnmarshalCheckpoint tries to unmarshal passed bytes to checkpoint
+func (cp *ContainerRestartCheckpoint) UnmarshalCheckpoint(blob []byte) error {
+	return json.Unmarshal(blob, cp)
+}
+
+// VerifyChecksum verifies that current checksum of checkpoint is valid
+func (cp *ContainerRestartCheckpoint) VerifyChecksum() error {
+	ck := cp.Checksum
+	cp.Checksum = 0
+	err := ck.Verify(cp)
+	cp.Checksum = ck
+	return err
+}
@@ -0,0 +1,312 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podkiller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/events"
+	cri "k8s.io/cri-api/pkg/apis"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager"
+	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager/errors"
+	"k8s.io/kubernetes/pkg/kubelet/container"
+	"k8s.io/kubernetes/pkg/kubelet/cri/remote"
+	clocks "k8s.io/utils/clock"
+
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const MetricsNameRestartContainer = "restart_container"
+
+// ContainerRestartKiller implements Killer interface, it restarts the container specified by
+// eviction plugins instead of evicting the whole pod, so that overuse of a sidecar won't take
+// down the main workload; and it falls back to pod eviction if the container can't be restarted
+// or has been restarted too many times within a window. Restart times are checkpointed so that
+// the fallback still works across agent restarts.
+type ContainerRestartKiller struct {
+	containerManager cri.ContainerManager
+	client           kubernetes.Interface
+	recorder         events.EventRecorder
+	emitter          metrics.MetricEmitter
+	clock            clocks.Clock
+
+	fallback      Killer
+	targetPlugins sets.String
+	maxRestarts   int
+	window        time.Duration
+
+	// checkpointManager is nil if restart times are not checkpointed
+	checkpointManager checkpointmanager.CheckpointManager
+
+	mutex sync.Mutex
+	// restarts maps container key to the timestamps it was restarted by this killer
+	restarts map[string][]time.Time
+}
+
+// NewContainerRestartKiller returns a ContainerRestartKiller which evicts pods by the given fallback killer
+// if containers can't be restarted, and the fallback killer must evict the whole pod.
+func NewContainerRestartKiller(conf *config.Configuration, fallback Killer, client kubernetes.Interface,
+	recorder events.EventRecorder, emitter metrics.MetricEmitter,
+) (Killer, error) {
+	if fallback == nil {
+		return nil, fmt.Errorf("nil fallback killer")
+	}
+
+	remoteRuntimeService, err := remote.NewRemoteRuntimeService(conf.RuntimeEndpoint, 2*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpointManager checkpointmanager.CheckpointManager
+	if conf.ContainerRestartKillerCheckpointDir != "" {
+		checkpointManager, err = checkpointmanager.NewCheckpointManager(conf.ContainerRestartKillerCheckpointDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize checkpoint manager: %v", err)
+		}
+	}
+
+	c := &ContainerRestartKiller{
+		containerManager:  remoteRuntimeService,
+		client:            client,
+		recorder:          recorder,
+		emitter:           emitter,
+		clock:             clocks.RealClock{},
+		fallback:          fallback,
+		targetPlugins:     sets.NewString(conf.ContainerRestartKillerPlugins...),
+		maxRestarts:       conf.ContainerRestartKillerMaxRestarts,
+		window:            conf.ContainerRestartKillerWindow,
+		checkpointManager: checkpointManager,
+		restarts:          make(map[string][]time.Time),
+	}
+	c.restoreCheckpoint()
+	return c, nil
+}
+
+func (c *ContainerRestartKiller) Name() string { return consts.KillerNameContainerRestartKiller }
+
+func (c *ContainerRestartKiller) Evict(ctx context.Context, pod *v1.Pod, gracePeriodSeconds int64, reason, plugin string) error {
+	if pod == nil {
+		return fmt.Errorf("pod is nil")
+	}
+
+	containerName, containerID, fallbackReason := c.getTargetContainer(pod, plugin)
+	if fallbackReason != "" {
+		klog.Infof("[container-restart-killer] evict pod %v/%v instead of restarting container: %v",
+			pod.Namespace, pod.Name, fallbackReason)
+		return c.fallback.Evict(ctx, pod, gracePeriodSeconds, fmt.Sprintf("%s; %s", reason, fallbackReason), plugin)
+	}
+
+	key := containerKey(pod, containerName)
+	now := c.clock.Now()
+	if restarts := c.getRestarts(key, now); c.maxRestarts >= 0 && restarts >= c.maxRestarts {
+		fallbackReason = fmt.Sprintf("container %s has been restarted %d times in %v", containerName, restarts, c.window)
+		klog.Infof("[container-restart-killer] evict pod %v/%v instead of restarting container: %v",
+			pod.Namespace, pod.Name, fallbackReason)
+		if err := c.fallback.Evict(ctx, pod, gracePeriodSeconds, fmt.Sprintf("%s; %s", reason, fallbackReason), plugin); err != nil {
+			return err
+		}
+
+		c.mutex.Lock()
+		delete(c.restarts, key)
+		c.storeCheckpoint(now)
+		c.mutex.Unlock()
+		return nil
+	}
+
+	restartReason := fmt.Sprintf("container %s is restarted by plugin %s; reason: %s", containerName, plugin, reason)
+	if err := c.annotatePod(ctx, pod, restartReason); err != nil {
+		klog.Warningf("[container-restart-killer] failed to annotate pod %v/%v: %v", pod.Namespace, pod.Name, err)
+	}
+
+	if err := c.containerManager.StopContainer(containerID, gracePeriodSeconds); err != nil {
+		c.recorder.Eventf(pod, nil, v1.EventTypeWarning, consts.EventReasonContainerStopped, consts.EventActionContainerStopping,
+			"Failed to restart container %v; reason: %s", containerName, reason)
+		c.emitRestartMetric(pod, containerName, plugin, "failed")
+		return fmt.Errorf("ContainerRestartKiller stop container %v failed with error: %v", containerID, err)
+	}
+
+	// kubelet starts a new container for the removed one as well, and removing
+	// is best-effort since the container has been stopped anyway
+	if pod.Annotations[consts.PodAnnotationEvictionRemoveTargetContainerKey] == "true" {
+		if err := c.containerManager.RemoveContainer(containerID); err != nil {
+			klog.Warningf("[container-restart-killer] failed to remove container %v/%v for pod %v/%v: %v",
+				containerName, containerID, pod.Namespace, pod.Name, err)
+		}
+	}
+
+	c.mutex.Lock()
+	c.restarts[key] = append(c.restarts[key], now)
+	c.storeCheckpoint(now)
+	c.mutex.Unlock()
+
+	c.recorder.Eventf(pod, nil, v1.EventTypeNormal, consts.EventReasonContainerStopped, consts.EventActionContainerStopping,
+		"Successfully restart container %v; reason: %s", containerName, reason)
+	c.emitRestartMetric(pod, containerName, plugin, "succeeded")
+	klog.Infof("[container-restart-killer] successfully restart container %v/%v for pod %v/%v",
+		containerName, containerID, pod.Namespace, pod.Name)
+	return nil
+}
+
+// getTargetContainer returns the name and id of container to restart, or the reason
+// why the whole pod should be evicted instead
+func (c *ContainerRestartKiller) getTargetContainer(pod *v1.Pod, plugin string) (string, string, string) {
+	if !c.targetPlugins.Has(plugin) {
+		return "", "", fmt.Sprintf("plugin %s doesn't support container restart", plugin)
+	}
+
+	containerName := pod.Annotations[consts.PodAnnotationEvictionTargetContainerKey]
+	if containerName == "" {
+		return "", "", "no target container specified"
+	}
+
+	// stopped containers only come back for pods with Always restart policy, since a container
+	// exiting with zero code after being stopped is never restarted under OnFailure policy
+	if pod.Spec.RestartPolicy != "" && pod.Spec.RestartPolicy != v1.RestartPolicyAlways {
+		return "", "", fmt.Sprintf("restart policy of pod is %s", pod.Spec.RestartPolicy)
+	}
+
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name != containerName {
+			continue
+		}
+
+		if containerStatus.State.Running == nil || containerStatus.ContainerID == "" {
+			return "", "", fmt.Sprintf("container %s is not running", containerName)
+		}
+		return containerName, container.ParseContainerID(containerStatus.ContainerID).ID, ""
+	}
+	return "", "", fmt.Sprintf("container %s not found", containerName)
+}
+
+// getRestarts returns restart times of the container in window, and drops the expired ones
+func (c *ContainerRestartKiller) getRestarts(key string, now time.Time) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	restarts := make([]time.Time, 0, len(c.restarts[key]))
+	for _, t := range c.restarts[key] {
+		if now.Sub(t) <= c.window {
+			restarts = append(restarts, t)
+		}
+	}
+
+	if len(restarts) == 0 {
+		delete(c.restarts, key)
+	} else {
+		c.restarts[key] = restarts
+	}
+	return len(restarts)
+}
+
+// restoreCheckpoint loads restart times from checkpoint, and it starts with empty
+// restart times if the checkpoint doesn't exist or is corrupted
+func (c *ContainerRestartKiller) restoreCheckpoint() {
+	if c.checkpointManager == nil {
+		return
+	}
+
+	checkpoint := NewContainerRestartCheckpoint()
+	if err := c.checkpointManager.GetCheckpoint(containerRestartCheckpointName, checkpoint); err != nil {
+		if err != errors.ErrCheckpointNotFound {
+			klog.Warningf("[container-restart-killer] failed to restore checkpoint: %v", err)
+		}
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for key, restarts := range checkpoint.Restarts {
+		for _, t := range restarts {
+			c.restarts[key] = append(c.restarts[key], time.Unix(0, t))
+		}
+	}
+	klog.Infof("[container-restart-killer] restored restart times of %d containers", len(c.restarts))
+}
+
+// storeCheckpoint writes restart times in window into checkpoint, and restart times
+// of other containers are dropped at the same time; it must be called with mutex held.
+func (c *ContainerRestartKiller) storeCheckpoint(now time.Time) {
+	if c.checkpointManager == nil {
+		return
+	}
+
+	checkpoint := NewContainerRestartCheckpoint()
+	for key, restarts := range c.restarts {
+		inWindow := make([]time.Time, 0, len(restarts))
+		for _, t := range restarts {
+			if now.Sub(t) <= c.window {
+				inWindow = append(inWindow, t)
+				checkpoint.Restarts[key] = append(checkpoint.Restarts[key], t.UnixNano())
+			}
+		}
+
+		if len(inWindow) == 0 {
+			delete(c.restarts, key)
+		} else {
+			c.restarts[key] = inWindow
+		}
+	}
+
+	if err := c.checkpointManager.CreateCheckpoint(containerRestartCheckpointName, checkpoint); err != nil {
+		klog.Errorf("[container-restart-killer] failed to store checkpoint: %v", err)
+	}
+}
+
+func (c *ContainerRestartKiller) annotatePod(ctx context.Context, pod *v1.Pod, reason string) error {
+	if c.client == nil {
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				consts.PodAnnotationContainerRestartReasonKey: reason,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.client.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, apitypes.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+func (c *ContainerRestartKiller) emitRestartMetric(pod *v1.Pod, containerName, plugin, state string) {
+	_ = c.emitter.StoreInt64(MetricsNameRestartContainer, 1, metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "state", Val: state},
+		metrics.MetricTag{Key: "pod_ns", Val: pod.Namespace},
+		metrics.MetricTag{Key: "pod_name", Val: pod.Name},
+		metrics.MetricTag{Key: "container_name", Val: containerName},
+		metrics.MetricTag{Key: "plugin_name", Val: plugin})
+}
+
+func containerKey(pod *v1.Pod, containerName string) string {
+	return fmt.Sprintf("%s/%s", pod.UID, containerName)
+}
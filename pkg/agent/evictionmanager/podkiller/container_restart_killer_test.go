@@ -0,0 +1,326 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podkiller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+	critesting "k8s.io/cri-api/pkg/apis/testing"
+	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager"
+	testingclock "k8s.io/utils/clock/testing"
+
+	pluginapi "github.com/kubewharf/katalyst-api/pkg/protocol/evictionplugin/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/rule"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	metaserverpod "github.com/kubewharf/katalyst-core/pkg/metaserver/agent/pod"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+type countingKiller struct {
+	DummyKiller
+	evicted int
+}
+
+func (c *countingKiller) Evict(_ context.Context, _ *v1.Pod, _ int64, _, _ string) error {
+	c.evicted++
+	return nil
+}
+
+func makeRestartTestPod(restartPolicy v1.RestartPolicy, targetContainer string) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod-1",
+			Namespace: "default",
+			UID:       "uid-1",
+		},
+		Spec: v1.PodSpec{
+			RestartPolicy: restartPolicy,
+			Containers: []v1.Container{
+				{Name: "container-01"},
+				{Name: "container-02"},
+			},
+		},
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					Name:        "container-01",
+					ContainerID: "containerd://container-01",
+					State:       v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+				},
+				{
+					Name:        "container-02",
+					ContainerID: "containerd://container-02",
+					State:       v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+				},
+			},
+		},
+	}
+	if targetContainer != "" {
+		pod.Annotations = map[string]string{consts.PodAnnotationEvictionTargetContainerKey: targetContainer}
+	}
+	return pod
+}
+
+func TestContainerRestartKiller_Evict(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		pod              *v1.Pod
+		plugin           string
+		times            int
+		wantStopped      []string
+		wantFallbackKill int
+	}{
+		{
+			name:             "restart target container",
+			pod:              makeRestartTestPod(v1.RestartPolicyAlways, "container-02"),
+			plugin:           "rss-overuse-eviction-plugin",
+			times:            1,
+			wantStopped:      []string{"container-02"},
+			wantFallbackKill: 0,
+		},
+		{
+			name:             "plugin not supported",
+			pod:              makeRestartTestPod(v1.RestartPolicyAlways, "container-02"),
+			plugin:           "other-plugin",
+			times:            1,
+			wantFallbackKill: 1,
+		},
+		{
+			name:             "no target container",
+			pod:              makeRestartTestPod(v1.RestartPolicyAlways, ""),
+			plugin:           "rss-overuse-eviction-plugin",
+			times:            1,
+			wantFallbackKill: 1,
+		},
+		{
+			name:             "restart policy never",
+			pod:              makeRestartTestPod(v1.RestartPolicyNever, "container-02"),
+			plugin:           "rss-overuse-eviction-plugin",
+			times:            1,
+			wantFallbackKill: 1,
+		},
+		{
+			name:             "restart policy on failure",
+			pod:              makeRestartTestPod(v1.RestartPolicyOnFailure, "container-02"),
+			plugin:           "rss-overuse-eviction-plugin",
+			times:            1,
+			wantFallbackKill: 1,
+		},
+		{
+			name:             "target container not found",
+			pod:              makeRestartTestPod(v1.RestartPolicyAlways, "container-03"),
+			plugin:           "rss-overuse-eviction-plugin",
+			times:            1,
+			wantFallbackKill: 1,
+		},
+		{
+			name:             "fall back after max restarts",
+			pod:              makeRestartTestPod(v1.RestartPolicyAlways, "container-01"),
+			plugin:           "rss-overuse-eviction-plugin",
+			times:            3,
+			wantStopped:      []string{"container-01"},
+			wantFallbackKill: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeRuntimeService := critesting.NewFakeRuntimeService()
+			fakeRuntimeService.Containers["container-01"] = &critesting.FakeContainer{}
+			fakeRuntimeService.Containers["container-02"] = &critesting.FakeContainer{}
+
+			fallback := &countingKiller{}
+			killer := &ContainerRestartKiller{
+				containerManager: fakeRuntimeService,
+				client:           fake.NewSimpleClientset(tt.pod),
+				recorder:         events.NewFakeRecorder(10),
+				emitter:          metrics.DummyMetrics{},
+				clock:            testingclock.NewFakeClock(time.Now()),
+				fallback:         fallback,
+				targetPlugins:    sets.NewString("rss-overuse-eviction-plugin"),
+				maxRestarts:      2,
+				window:           time.Hour,
+				restarts:         make(map[string][]time.Time),
+			}
+			assert.Equal(t, consts.KillerNameContainerRestartKiller, killer.Name())
+
+			for i := 0; i < tt.times; i++ {
+				require.NoError(t, killer.Evict(context.TODO(), tt.pod, 0, "test", tt.plugin))
+			}
+
+			stopped := sets.NewString()
+			for id, c := range fakeRuntimeService.Containers {
+				if c.State == runtimeapi.ContainerState_CONTAINER_EXITED {
+					stopped.Insert(id)
+				}
+			}
+			assert.Equal(t, sets.NewString(tt.wantStopped...), stopped)
+			assert.Equal(t, tt.wantFallbackKill, fallback.evicted)
+
+			if len(tt.wantStopped) > 0 {
+				pod, err := killer.client.CoreV1().Pods(tt.pod.Namespace).Get(context.TODO(), tt.pod.Name, metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.NotEmpty(t, pod.Annotations[consts.PodAnnotationContainerRestartReasonKey])
+			}
+		})
+	}
+}
+
+func TestContainerRestartKiller_RemoveTargetContainer(t *testing.T) {
+	t.Parallel()
+
+	pod := makeRestartTestPod(v1.RestartPolicyAlways, "container-02")
+	pod.Annotations[consts.PodAnnotationEvictionRemoveTargetContainerKey] = "true"
+
+	fakeRuntimeService := critesting.NewFakeRuntimeService()
+	fakeRuntimeService.Containers["container-01"] = &critesting.FakeContainer{}
+	fakeRuntimeService.Containers["container-02"] = &critesting.FakeContainer{}
+
+	fallback := &countingKiller{}
+	killer := &ContainerRestartKiller{
+		containerManager: fakeRuntimeService,
+		client:           fake.NewSimpleClientset(pod),
+		recorder:         events.NewFakeRecorder(10),
+		emitter:          metrics.DummyMetrics{},
+		clock:            testingclock.NewFakeClock(time.Now()),
+		fallback:         fallback,
+		targetPlugins:    sets.NewString("rootfs-overuse-eviction-plugin"),
+		maxRestarts:      2,
+		window:           time.Hour,
+		restarts:         make(map[string][]time.Time),
+	}
+	require.NoError(t, killer.Evict(context.TODO(), pod, 0, "test", "rootfs-overuse-eviction-plugin"))
+	assert.Contains(t, fakeRuntimeService.Containers, "container-01")
+	assert.NotContains(t, fakeRuntimeService.Containers, "container-02")
+	assert.Equal(t, 0, fallback.evicted)
+}
+
+func TestContainerRestartKiller_Checkpoint(t *testing.T) {
+	t.Parallel()
+
+	checkpointManager, err := checkpointmanager.NewCheckpointManager(t.TempDir())
+	require.NoError(t, err)
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	pod := makeRestartTestPod(v1.RestartPolicyAlways, "container-01")
+
+	newKiller := func(fallback Killer) *ContainerRestartKiller {
+		fakeRuntimeService := critesting.NewFakeRuntimeService()
+		fakeRuntimeService.Containers["container-01"] = &critesting.FakeContainer{}
+
+		killer := &ContainerRestartKiller{
+			containerManager:  fakeRuntimeService,
+			client:            fake.NewSimpleClientset(pod),
+			recorder:          events.NewFakeRecorder(10),
+			emitter:           metrics.DummyMetrics{},
+			clock:             fakeClock,
+			fallback:          fallback,
+			targetPlugins:     sets.NewString("rss-overuse-eviction-plugin"),
+			maxRestarts:       2,
+			window:            time.Hour,
+			checkpointManager: checkpointManager,
+			restarts:          make(map[string][]time.Time),
+		}
+		killer.restoreCheckpoint()
+		return killer
+	}
+
+	fallback := &countingKiller{}
+	killer := newKiller(fallback)
+	require.NoError(t, killer.Evict(context.TODO(), pod, 0, "test", "rss-overuse-eviction-plugin"))
+	fakeClock.Step(time.Minute)
+	require.NoError(t, killer.Evict(context.TODO(), pod, 0, "test", "rss-overuse-eviction-plugin"))
+	assert.Equal(t, 0, fallback.evicted)
+
+	// restart times are restored after the killer is re-created, so that it falls back
+	killer = newKiller(fallback)
+	assert.Len(t, killer.restarts[containerKey(pod, "container-01")], 2)
+	require.NoError(t, killer.Evict(context.TODO(), pod, 0, "test", "rss-overuse-eviction-plugin"))
+	assert.Equal(t, 1, fallback.evicted)
+
+	// restart times are cleared in checkpoint after falling back
+	killer = newKiller(fallback)
+	assert.Empty(t, killer.restarts)
+}
+
+func TestNewContainerRestartKiller(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewContainerRestartKiller(config.NewConfiguration(), nil, nil, nil, metrics.DummyMetrics{})
+	assert.Error(t, err)
+}
+
+func TestAsynchronizedPodKiller_ContainerRestart(t *testing.T) {
+	t.Parallel()
+
+	// the pod fetched by pod killer doesn't have annotations set by eviction plugins
+	pod := makeRestartTestPod(v1.RestartPolicyAlways, "")
+	evictPod := makeRestartTestPod(v1.RestartPolicyAlways, "container-02")
+
+	fakeRuntimeService := critesting.NewFakeRuntimeService()
+	fakeRuntimeService.Containers["container-01"] = &critesting.FakeContainer{}
+	fakeRuntimeService.Containers["container-02"] = &critesting.FakeContainer{}
+
+	fallback := &countingKiller{}
+	client := fake.NewSimpleClientset(pod)
+	killer := &ContainerRestartKiller{
+		containerManager: fakeRuntimeService,
+		client:           client,
+		recorder:         events.NewFakeRecorder(10),
+		emitter:          metrics.DummyMetrics{},
+		clock:            testingclock.NewFakeClock(time.Now()),
+		fallback:         fallback,
+		targetPlugins:    sets.NewString("rss-overuse-eviction-plugin"),
+		maxRestarts:      2,
+		window:           time.Hour,
+		restarts:         make(map[string][]time.Time),
+	}
+
+	podKiller := NewAsynchronizedPodKiller(killer, &metaserverpod.PodFetcherStub{PodList: []*v1.Pod{pod}}, client).(*AsynchronizedPodKiller)
+	defer podKiller.queue.ShutDown()
+	require.NoError(t, podKiller.EvictPod(&rule.RuledEvictPod{
+		EvictPod: &pluginapi.EvictPod{
+			Pod:                evictPod,
+			Reason:             "test",
+			EvictionPluginName: "rss-overuse-eviction-plugin",
+		},
+	}))
+
+	key, _ := podKiller.queue.Get()
+	err, requeue := podKiller.sync(key.(string))
+	podKiller.queue.Done(key)
+	require.NoError(t, err)
+	assert.False(t, requeue)
+
+	assert.Equal(t, runtimeapi.ContainerState_CONTAINER_EXITED, fakeRuntimeService.Containers["container-02"].State)
+	assert.NotEqual(t, runtimeapi.ContainerState_CONTAINER_EXITED, fakeRuntimeService.Containers["container-01"].State)
+	assert.Equal(t, 0, fallback.evicted)
+}
@@ -39,6 +39,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/rule"
 	"github.com/kubewharf/katalyst-core/pkg/consts"
 	metaserverpod "github.com/kubewharf/katalyst-core/pkg/metaserver/agent/pod"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
 )
 
 // PodKiller implements the killing actions for given pods.
@@ -97,6 +98,7 @@ func (s *SynchronizedPodKiller) EvictPod(rp *rule.RuledEvictPod) error {
 	}
 
 	err = s.killer.Evict(context.Background(), rp.Pod, gracePeriod, rp.Reason, rp.EvictionPluginName)
+	publishKillEvent(s.killer.Name(), rp.Pod, gracePeriod, rp.Reason, rp.EvictionPluginName, err)
 	if err != nil {
 		return fmt.Errorf("evict pod: %s/%s failed with error: %v", rp.Pod.Namespace, rp.Pod.Name, err)
 	}
@@ -147,16 +149,40 @@ type evictPodInfo struct {
 	Pod    *v1.Pod
 	Reason string
 	Plugin string
+	// TargetContainer and RemoveTargetContainer are specified by eviction plugins in pod annotations,
+	// and they must be carried here since the pod is fetched again before it's killed
+	TargetContainer       string
+	RemoveTargetContainer string
 }
 
 func getEvictPodInfo(rp *rule.RuledEvictPod) *evictPodInfo {
 	return &evictPodInfo{
-		Pod:    rp.Pod.DeepCopy(),
-		Reason: rp.Reason,
-		Plugin: rp.EvictionPluginName,
+		Pod:                   rp.Pod.DeepCopy(),
+		Reason:                rp.Reason,
+		Plugin:                rp.EvictionPluginName,
+		TargetContainer:       rp.Pod.Annotations[consts.PodAnnotationEvictionTargetContainerKey],
+		RemoveTargetContainer: rp.Pod.Annotations[consts.PodAnnotationEvictionRemoveTargetContainerKey],
 	}
 }
 
+// withTargetContainer returns a copy of the pod annotated with the target container
+// of eviction, and the pod is returned as is if no container is targeted
+func (e *evictPodInfo) withTargetContainer(pod *v1.Pod) *v1.Pod {
+	if e.TargetContainer == "" {
+		return pod
+	}
+
+	pod = pod.DeepCopy()
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[consts.PodAnnotationEvictionTargetContainerKey] = e.TargetContainer
+	if e.RemoveTargetContainer != "" {
+		pod.Annotations[consts.PodAnnotationEvictionRemoveTargetContainerKey] = e.RemoveTargetContainer
+	}
+	return pod
+}
+
 func NewAsynchronizedPodKiller(killer Killer, podFetcher metaserverpod.PodFetcher, client kubernetes.Interface) PodKiller {
 	a := &AsynchronizedPodKiller{
 		killer:         killer,
@@ -352,9 +378,11 @@ func (a *AsynchronizedPodKiller) sync(key string) (retError error, requeue bool)
 	}
 	reason = a.processingPods[podKey][gracePeriodSeconds].Reason
 	plugin = a.processingPods[podKey][gracePeriodSeconds].Plugin
+	pod = a.processingPods[podKey][gracePeriodSeconds].withTargetContainer(pod)
 	a.RUnlock()
 
 	err = a.killer.Evict(context.Background(), pod, gracePeriodSeconds, reason, plugin)
+	publishKillEvent(a.killer.Name(), pod, gracePeriodSeconds, reason, plugin, err)
 	if err != nil {
 		return err, true
 	} else {
@@ -362,6 +390,28 @@ func (a *AsynchronizedPodKiller) sync(key string) (retError error, requeue bool)
 	}
 }
 
+// publishKillEvent publishes the kill result to event bus for auditing
+func publishKillEvent(killerName string, pod *v1.Pod, gracePeriodSeconds int64, reason, plugin string, err error) {
+	event := eventbus.EvictionEvent{
+		BaseEventImpl: eventbus.BaseEventImpl{
+			Time: time.Now(),
+		},
+		Phase:              eventbus.EvictionPhaseKilled,
+		PluginName:         plugin,
+		KillerName:         killerName,
+		PodUID:             string(pod.UID),
+		PodNamespace:       pod.Namespace,
+		PodName:            pod.Name,
+		Reason:             reason,
+		GracePeriodSeconds: gracePeriodSeconds,
+	}
+	if err != nil {
+		event.Phase = eventbus.EvictionPhaseKillFail
+		event.Error = err.Error()
+	}
+	_ = eventbus.GetDefaultEventBus().Publish(consts.TopicNameEviction, event)
+}
+
 func podKeyFunc(podNamespace, podName string, uid string) string {
 	return strings.Join([]string{podNamespace, podName, uid}, consts.KeySeparator)
 }
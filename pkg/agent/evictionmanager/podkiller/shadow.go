@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podkiller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/klog/v2"
+	clocks "k8s.io/utils/clock"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/rule"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+)
+
+const (
+	MetricsNameShadowKillPod = "shadow_kill_pod"
+
+	// shadowRecordInterval is the minimal interval to record the same pod selected by the same plugin,
+	// since plugins in shadow run mode keep selecting the same pods until they are gone.
+	shadowRecordInterval = 10 * time.Minute
+)
+
+// ShadowPodKiller never kills pods, it only records pods that would have been
+// evicted by emitting metrics, events and audit records, so that decisions of
+// plugins in shadow run mode can be compared with the production ones.
+type ShadowPodKiller struct {
+	recorder events.EventRecorder
+	emitter  metrics.MetricEmitter
+	clock    clocks.Clock
+
+	mutex sync.Mutex
+	// lastRecorded maps pod uid and plugin name to the last time it was recorded
+	lastRecorded map[string]time.Time
+}
+
+func NewShadowPodKiller(recorder events.EventRecorder, emitter metrics.MetricEmitter) PodKiller {
+	return &ShadowPodKiller{
+		recorder:     recorder,
+		emitter:      emitter,
+		clock:        clocks.RealClock{},
+		lastRecorded: make(map[string]time.Time),
+	}
+}
+
+func (s *ShadowPodKiller) Name() string { return consts.KillerNameShadowKiller }
+
+func (s *ShadowPodKiller) Start(_ context.Context) {
+	klog.Infof("[shadow] pod-killer started")
+}
+
+func (s *ShadowPodKiller) EvictPod(rp *rule.RuledEvictPod) error {
+	if rp == nil || rp.Pod == nil {
+		return fmt.Errorf("EvictPod got nil pod")
+	}
+
+	gracePeriod, err := getGracefulDeletionPeriod(rp.Pod, rp.DeletionOptions)
+	if err != nil {
+		return fmt.Errorf("getGracefulDeletionPeriod for pod: %s/%s failed with error: %v", rp.Pod.Namespace, rp.Pod.Name, err)
+	}
+
+	now := s.clock.Now()
+	if !s.shouldRecord(fmt.Sprintf("%s/%s", rp.Pod.UID, rp.EvictionPluginName), now) {
+		klog.V(4).Infof("[shadow] pod-killer skip recording pod %v/%v by plugin %v since it's recorded recently",
+			rp.Pod.Namespace, rp.Pod.Name, rp.EvictionPluginName)
+		return nil
+	}
+
+	klog.Infof("[shadow] pod-killer would evict pod %v/%v with graceful seconds %v by plugin %v, reason: %v",
+		rp.Pod.Namespace, rp.Pod.Name, gracePeriod, rp.EvictionPluginName, rp.Reason)
+
+	if s.recorder != nil {
+		s.recorder.Eventf(rp.Pod, nil, v1.EventTypeNormal, consts.EventReasonEvictShadowed, consts.EventActionEvicting,
+			"Pod would have been evicted by shadow run plugin %s; reason: %s", rp.EvictionPluginName, rp.Reason)
+	}
+	_ = s.emitter.StoreInt64(MetricsNameShadowKillPod, 1, metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "pod_ns", Val: rp.Pod.Namespace},
+		metrics.MetricTag{Key: "pod_name", Val: rp.Pod.Name},
+		metrics.MetricTag{Key: "plugin_name", Val: rp.EvictionPluginName},
+		metrics.MetricTag{Key: "scope", Val: rp.Scope})
+	_ = eventbus.GetDefaultEventBus().Publish(consts.TopicNameEviction, eventbus.EvictionEvent{
+		BaseEventImpl: eventbus.BaseEventImpl{
+			Time: now,
+		},
+		Phase:              eventbus.EvictionPhaseShadow,
+		PluginName:         rp.EvictionPluginName,
+		KillerName:         consts.KillerNameShadowKiller,
+		Scope:              rp.Scope,
+		PodUID:             string(rp.Pod.UID),
+		PodNamespace:       rp.Pod.Namespace,
+		PodName:            rp.Pod.Name,
+		Reason:             rp.Reason,
+		GracePeriodSeconds: gracePeriod,
+	})
+	return nil
+}
+
+func (s *ShadowPodKiller) EvictPods(rpList rule.RuledEvictPodList) error {
+	var errList []error
+	for _, rp := range rpList {
+		if err := s.EvictPod(rp); err != nil {
+			errList = append(errList, err)
+		}
+	}
+	return errors.NewAggregate(errList)
+}
+
+// shouldRecord returns true if the key hasn't been recorded within shadowRecordInterval,
+// and it drops the expired keys so that records of deleted pods won't be kept forever.
+func (s *ShadowPodKiller) shouldRecord(key string, now time.Time) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for k, t := range s.lastRecorded {
+		if now.Sub(t) >= shadowRecordInterval {
+			delete(s.lastRecorded, k)
+		}
+	}
+
+	if _, ok := s.lastRecorded[key]; ok {
+		return false
+	}
+	s.lastRecorded[key] = now
+	return true
+}
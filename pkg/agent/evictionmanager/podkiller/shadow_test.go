@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podkiller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	testingclock "k8s.io/utils/clock/testing"
+
+	pluginapi "github.com/kubewharf/katalyst-api/pkg/protocol/evictionplugin/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/rule"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+func TestShadowPodKiller(t *testing.T) {
+	t.Parallel()
+
+	recorder := events.NewFakeRecorder(10)
+	killer := NewShadowPodKiller(recorder, metrics.DummyMetrics{})
+
+	rpList := rule.RuledEvictPodList{
+		{
+			EvictPod: &pluginapi.EvictPod{
+				Pod: &v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "default", UID: "pod-1"},
+				},
+				Reason:             "test",
+				EvictionPluginName: "plugin1",
+			},
+			Scope: rule.EvictionScopeForce,
+		},
+	}
+	assert.Equal(t, consts.KillerNameShadowKiller, killer.Name())
+
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	killer.(*ShadowPodKiller).clock = fakeClock
+
+	assert.NoError(t, killer.EvictPods(rpList))
+	assert.Len(t, recorder.Events, 1)
+
+	// the same pod selected by the same plugin is recorded only once within the interval
+	assert.NoError(t, killer.EvictPods(rpList))
+	assert.Len(t, recorder.Events, 1)
+
+	// but it's recorded if selected by another plugin
+	rpList[0].EvictionPluginName = "plugin2"
+	assert.NoError(t, killer.EvictPods(rpList))
+	assert.Len(t, recorder.Events, 2)
+
+	fakeClock.Step(shadowRecordInterval)
+	rpList[0].EvictionPluginName = "plugin1"
+	assert.NoError(t, killer.EvictPods(rpList))
+	assert.Len(t, recorder.Events, 3)
+
+	assert.Error(t, killer.EvictPod(nil))
+}
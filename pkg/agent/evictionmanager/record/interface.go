@@ -28,7 +28,9 @@ type EvictionRecordManager interface {
 }
 
 type EvictionRecord struct {
-	HasPDB             bool
+	HasPDB bool
+	// PDBKey is the namespace/name of the PodDisruptionBudget selecting the pod
+	PDBKey             string
 	Buckets            Buckets
 	DisruptionsAllowed int32
 	CurrentHealthy     int32
@@ -37,13 +39,13 @@ type EvictionRecord struct {
 }
 
 type Buckets struct {
-	List []Bucket
+	List []Bucket `json:"list"`
 }
 
 type Bucket struct {
-	Time     int64
-	Count    int64
-	Duration int64
+	Time     int64 `json:"time"`
+	Count    int64 `json:"count"`
+	Duration int64 `json:"duration"`
 }
 
 type DummyEvictionRecordManager struct{}
@@ -0,0 +1,360 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	policylisters "k8s.io/client-go/listers/policy/v1"
+	"k8s.io/client-go/tools/cache"
+	clocks "k8s.io/utils/clock"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/eviction"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	EvictionRecordManagerNameLocal = "local"
+
+	localRecordFileName        = "eviction_records.json"
+	localRecordSyncPeriod      = 30 * time.Second
+	localRecordSubscriberName  = "local-eviction-record-manager"
+	localRecordEventBufferSize = 100
+
+	metricsNameLocalRecordSyncFailed = "local_eviction_record_sync_failed"
+)
+
+// LocalEvictionRecordManager keeps eviction buckets of workloads in a local file, the buckets
+// are accumulated by eviction events published by pod killers, and PodDisruptionBudget
+// status of the workloads is watched from APIServer by a shared informer.
+type LocalEvictionRecordManager struct {
+	conf       *eviction.GenericEvictionConfiguration
+	metaServer *metaserver.MetaServer
+	emitter    metrics.MetricEmitter
+	clock      clocks.Clock
+
+	// pdbInformerFactory is nil if PodDisruptionBudget is not respected
+	pdbInformerFactory informers.SharedInformerFactory
+	pdbLister          policylisters.PodDisruptionBudgetLister
+	pdbSynced          cache.InformerSynced
+
+	mutex sync.RWMutex
+	// workloads maps workload key to its eviction buckets, ordered by time descending
+	workloads map[string]Buckets
+	// podWorkloads maps pod uid to its workload key, since pods may be
+	// gone when eviction events are handled
+	podWorkloads map[string]string
+	dirty        bool
+}
+
+var _ EvictionRecordManager = &LocalEvictionRecordManager{}
+
+func NewLocalEvictionRecordManager(options InitOptions) (EvictionRecordManager, error) {
+	if options.Conf == nil {
+		return nil, fmt.Errorf("nil configuration for local eviction record manager")
+	}
+
+	m := &LocalEvictionRecordManager{
+		conf:         options.Conf.GenericEvictionConfiguration,
+		metaServer:   options.MetaServer,
+		emitter:      options.Emitter,
+		clock:        clocks.RealClock{},
+		workloads:    make(map[string]Buckets),
+		podWorkloads: make(map[string]string),
+	}
+	if options.ClientSet != nil && options.ClientSet.KubeClient != nil && m.conf.EvictionRespectPDB {
+		m.pdbInformerFactory = informers.NewSharedInformerFactory(options.ClientSet.KubeClient, 0)
+		pdbInformer := m.pdbInformerFactory.Policy().V1().PodDisruptionBudgets()
+		m.pdbLister = pdbInformer.Lister()
+		m.pdbSynced = pdbInformer.Informer().HasSynced
+	}
+
+	if err := m.load(); err != nil {
+		general.Warningf("load local eviction records failed: %v", err)
+	}
+	return m, nil
+}
+
+func (m *LocalEvictionRecordManager) GetEvictionRecords(_ context.Context, evictionPods []*v1.Pod) (map[string]EvictionRecord, error) {
+	now := m.clock.Now()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	records := make(map[string]EvictionRecord, len(evictionPods))
+	for _, pod := range evictionPods {
+		if pod == nil {
+			continue
+		}
+
+		key := GetWorkloadKey(pod)
+		m.podWorkloads[string(pod.UID)] = key
+
+		r := EvictionRecord{
+			Buckets: Buckets{List: m.getBuckets(key, now)},
+		}
+		if pdb := m.matchPDB(pod); pdb != nil {
+			r.HasPDB = true
+			r.PDBKey = pdb.Namespace + "/" + pdb.Name
+			r.DisruptionsAllowed = pdb.Status.DisruptionsAllowed
+			r.CurrentHealthy = pdb.Status.CurrentHealthy
+			r.DesiredHealthy = pdb.Status.DesiredHealthy
+			r.ExpectedPods = pdb.Status.ExpectedPods
+		}
+		records[string(pod.UID)] = r
+	}
+	return records, nil
+}
+
+func (m *LocalEvictionRecordManager) Run(ctx context.Context) {
+	err := eventbus.GetDefaultEventBus().Subscribe(consts.TopicNameEviction, localRecordSubscriberName,
+		localRecordEventBufferSize, m.handleEvictionEvent)
+	if err != nil {
+		general.Errorf("subscribe eviction events failed: %v", err)
+	}
+
+	if m.pdbInformerFactory != nil {
+		m.pdbInformerFactory.Start(ctx.Done())
+		if !cache.WaitForCacheSync(ctx.Done(), m.pdbSynced) {
+			general.Errorf("wait for pdb informer synced failed")
+		}
+	}
+
+	wait.UntilWithContext(ctx, m.sync, localRecordSyncPeriod)
+}
+
+// RecordEviction accumulates one eviction of the workload into its latest bucket
+func (m *LocalEvictionRecordManager) RecordEviction(workloadKey string, evictedAt time.Time) {
+	bucketDuration := m.conf.EvictionRecordBucketDuration
+	if bucketDuration <= 0 {
+		bucketDuration = time.Minute
+	}
+	bucketTime := evictedAt.Truncate(bucketDuration).Unix()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	list := m.getBuckets(workloadKey, m.clock.Now())
+	if len(list) > 0 && list[0].Time == bucketTime {
+		list[0].Count++
+	} else {
+		list = append([]Bucket{{
+			Time:     bucketTime,
+			Count:    1,
+			Duration: int64(bucketDuration.Seconds()),
+		}}, list...)
+	}
+	m.workloads[workloadKey] = Buckets{List: list}
+	m.dirty = true
+}
+
+func (m *LocalEvictionRecordManager) handleEvictionEvent(event interface{}) error {
+	e, ok := event.(eventbus.EvictionEvent)
+	if !ok || e.Phase != eventbus.EvictionPhaseKilled {
+		return nil
+	}
+
+	m.mutex.RLock()
+	key, ok := m.podWorkloads[e.PodUID]
+	m.mutex.RUnlock()
+	if !ok {
+		if pod, err := m.getPod(e.PodUID); err == nil {
+			key = GetWorkloadKey(pod)
+		} else {
+			key = GetWorkloadKey(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: e.PodNamespace, Name: e.PodName}})
+		}
+	}
+
+	general.Infof("record eviction of pod %s/%s for workload %s", e.PodNamespace, e.PodName, key)
+	m.RecordEviction(key, e.Time)
+	return nil
+}
+
+func (m *LocalEvictionRecordManager) sync(ctx context.Context) {
+	var errList []error
+	if err := m.syncPods(ctx); err != nil {
+		errList = append(errList, err)
+	}
+	if err := m.persist(); err != nil {
+		errList = append(errList, err)
+	}
+
+	for _, err := range errList {
+		general.Errorf("sync local eviction records failed: %v", err)
+		_ = m.emitter.StoreInt64(metricsNameLocalRecordSyncFailed, 1, metrics.MetricTypeNameCount)
+	}
+}
+
+// syncPods refreshes workloads of pods on this node
+func (m *LocalEvictionRecordManager) syncPods(ctx context.Context) error {
+	if m.metaServer == nil {
+		return nil
+	}
+
+	pods, err := m.metaServer.GetPodList(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("list pods failed: %v", err)
+	}
+
+	podWorkloads := make(map[string]string, len(pods))
+	for _, pod := range pods {
+		podWorkloads[string(pod.UID)] = GetWorkloadKey(pod)
+	}
+
+	m.mutex.Lock()
+	m.podWorkloads = podWorkloads
+	m.mutex.Unlock()
+	return nil
+}
+
+// getBuckets returns buckets of the workload within the retention, and it must be called with lock held
+func (m *LocalEvictionRecordManager) getBuckets(workloadKey string, now time.Time) []Bucket {
+	expiredTime := now.Add(-m.conf.EvictionRecordRetention).Unix()
+
+	list := make([]Bucket, 0, len(m.workloads[workloadKey].List))
+	for _, bucket := range m.workloads[workloadKey].List {
+		if m.conf.EvictionRecordRetention > 0 && bucket.Time+bucket.Duration < expiredTime {
+			continue
+		}
+		list = append(list, bucket)
+	}
+	return list
+}
+
+// matchPDB returns the first PodDisruptionBudget selecting the pod from the informer cache
+func (m *LocalEvictionRecordManager) matchPDB(pod *v1.Pod) *policyv1.PodDisruptionBudget {
+	if m.pdbLister == nil {
+		return nil
+	}
+
+	pdbs, err := m.pdbLister.PodDisruptionBudgets(pod.Namespace).List(labels.Everything())
+	if err != nil {
+		general.Warningf("list pdb in namespace %s failed: %v", pod.Namespace, err)
+		return nil
+	}
+
+	for _, pdb := range pdbs {
+		if pdb.Spec.Selector == nil {
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			general.Warningf("invalid selector of pdb %s/%s: %v", pdb.Namespace, pdb.Name, err)
+			continue
+		}
+		if selector.Matches(labels.Set(pod.Labels)) {
+			return pdb
+		}
+	}
+	return nil
+}
+
+func (m *LocalEvictionRecordManager) getPod(podUID string) (*v1.Pod, error) {
+	if m.metaServer == nil {
+		return nil, fmt.Errorf("nil meta server")
+	}
+	return m.metaServer.GetPod(context.Background(), podUID)
+}
+
+func (m *LocalEvictionRecordManager) recordFilePath() string {
+	return filepath.Join(m.conf.EvictionRecordDir, localRecordFileName)
+}
+
+// load restores workload buckets from the local file if it exists
+func (m *LocalEvictionRecordManager) load() error {
+	data, err := os.ReadFile(m.recordFilePath())
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	workloads := make(map[string]Buckets)
+	if err := json.Unmarshal(data, &workloads); err != nil {
+		return fmt.Errorf("unmarshal eviction records failed: %v", err)
+	}
+
+	m.mutex.Lock()
+	m.workloads = workloads
+	m.mutex.Unlock()
+	return nil
+}
+
+// persist writes workload buckets into the local file if any of them changed,
+// and expired buckets are dropped at the same time
+func (m *LocalEvictionRecordManager) persist() error {
+	now := m.clock.Now()
+
+	m.mutex.Lock()
+	workloads := make(map[string]Buckets, len(m.workloads))
+	for key := range m.workloads {
+		if list := m.getBuckets(key, now); len(list) > 0 {
+			workloads[key] = Buckets{List: list}
+		}
+	}
+	dirty := m.dirty || len(workloads) != len(m.workloads)
+	m.workloads = workloads
+	m.dirty = false
+	m.mutex.Unlock()
+
+	if !dirty {
+		return nil
+	}
+
+	if err := m.writeFile(workloads); err != nil {
+		m.mutex.Lock()
+		m.dirty = true
+		m.mutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (m *LocalEvictionRecordManager) writeFile(workloads map[string]Buckets) error {
+	data, err := json.Marshal(workloads)
+	if err != nil {
+		return fmt.Errorf("marshal eviction records failed: %v", err)
+	}
+
+	if err := os.MkdirAll(m.conf.EvictionRecordDir, 0o755); err != nil {
+		return fmt.Errorf("create eviction record dir failed: %v", err)
+	}
+
+	// write into a temporary file first to avoid partial records after crash
+	tmpPath := m.recordFilePath() + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("write eviction records failed: %v", err)
+	}
+	return os.Rename(tmpPath, m.recordFilePath())
+}
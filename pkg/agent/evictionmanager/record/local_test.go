@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package record
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/kubewharf/katalyst-core/pkg/client"
+	pkgconfig "github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/pod"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+)
+
+func makeWorkloadPod(name, workload string, labels map[string]string) *v1.Pod {
+	isController := true
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			UID:       types.UID(name),
+			Labels:    labels,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "ReplicaSet",
+				Name:       workload,
+				Controller: &isController,
+			}},
+		},
+	}
+}
+
+func makeLocalEvictionRecordManager(t *testing.T, pods []*v1.Pod, objects ...*policyv1.PodDisruptionBudget) *LocalEvictionRecordManager {
+	conf := pkgconfig.NewConfiguration()
+	conf.EvictionRecordDir = t.TempDir()
+	conf.EvictionRecordBucketDuration = 10 * time.Minute
+	conf.EvictionRecordRetention = time.Hour
+	conf.EvictionRespectPDB = true
+
+	kubeClient := fake.NewSimpleClientset()
+	for _, pdb := range objects {
+		_, err := kubeClient.PolicyV1().PodDisruptionBudgets(pdb.Namespace).Create(context.TODO(), pdb, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	m, err := NewLocalEvictionRecordManager(InitOptions{
+		Conf: conf,
+		MetaServer: &metaserver.MetaServer{MetaAgent: &agent.MetaAgent{
+			PodFetcher: &pod.PodFetcherStub{PodList: pods},
+		}},
+		Emitter:   metrics.DummyMetrics{},
+		ClientSet: &client.GenericClientSet{KubeClient: kubeClient},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	t.Cleanup(cancel)
+	manager := m.(*LocalEvictionRecordManager)
+	manager.pdbInformerFactory.Start(ctx.Done())
+	require.True(t, cache.WaitForCacheSync(ctx.Done(), manager.pdbSynced))
+	return manager
+}
+
+func TestGetWorkloadKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "default/apps/v1,ReplicaSet,rs", GetWorkloadKey(makeWorkloadPod("p", "rs", nil)))
+	assert.Equal(t, "default/v1,Pod,p", GetWorkloadKey(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "p"}}))
+	assert.Equal(t, "", GetWorkloadKey(nil))
+}
+
+func TestLocalEvictionRecordManager(t *testing.T) {
+	t.Parallel()
+
+	pods := []*v1.Pod{
+		makeWorkloadPod("p-1", "w-1", map[string]string{"app": "w-1"}),
+		makeWorkloadPod("p-2", "w-1", map[string]string{"app": "w-1"}),
+		makeWorkloadPod("p-3", "w-2", map[string]string{"app": "w-2"}),
+	}
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "pdb-1", Namespace: "default"},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "w-1"}},
+		},
+		Status: policyv1.PodDisruptionBudgetStatus{
+			DisruptionsAllowed: 1,
+			CurrentHealthy:     2,
+			DesiredHealthy:     1,
+			ExpectedPods:       2,
+		},
+	}
+
+	m := makeLocalEvictionRecordManager(t, pods, pdb)
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	fakeClock := testingclock.NewFakeClock(now)
+	m.clock = fakeClock
+	m.sync(context.TODO())
+
+	// evictions published by pod killers are accumulated by workload
+	for _, e := range []eventbus.EvictionEvent{
+		{BaseEventImpl: eventbus.BaseEventImpl{Time: now.Add(-2 * time.Hour)}, Phase: eventbus.EvictionPhaseKilled, PodUID: "p-1"},
+		{BaseEventImpl: eventbus.BaseEventImpl{Time: now.Add(-time.Minute)}, Phase: eventbus.EvictionPhaseKilled, PodUID: "p-1"},
+		{BaseEventImpl: eventbus.BaseEventImpl{Time: now.Add(-time.Minute)}, Phase: eventbus.EvictionPhaseKilled, PodUID: "p-2"},
+		{BaseEventImpl: eventbus.BaseEventImpl{Time: now}, Phase: eventbus.EvictionPhaseCandidate, PodUID: "p-3"},
+	} {
+		assert.NoError(t, m.handleEvictionEvent(e))
+	}
+
+	records, err := m.GetEvictionRecords(context.TODO(), pods)
+	require.NoError(t, err)
+	assert.Equal(t, EvictionRecord{
+		HasPDB:             true,
+		PDBKey:             "default/pdb-1",
+		DisruptionsAllowed: 1,
+		CurrentHealthy:     2,
+		DesiredHealthy:     1,
+		ExpectedPods:       2,
+		Buckets: Buckets{List: []Bucket{{
+			Time:     now.Add(-10 * time.Minute).Unix(),
+			Count:    2,
+			Duration: 600,
+		}}},
+	}, records["p-2"])
+	assert.Equal(t, EvictionRecord{Buckets: Buckets{List: []Bucket{}}}, records["p-3"])
+
+	// records are restored from the local file
+	m.sync(context.TODO())
+	conf := pkgconfig.NewConfiguration()
+	conf.GenericEvictionConfiguration = m.conf
+	restored, err := NewLocalEvictionRecordManager(InitOptions{Conf: conf})
+	require.NoError(t, err)
+	restored.(*LocalEvictionRecordManager).clock = fakeClock
+	restoredRecords, err := restored.GetEvictionRecords(context.TODO(), pods[:1])
+	require.NoError(t, err)
+	assert.Equal(t, records["p-1"].Buckets, restoredRecords["p-1"].Buckets)
+
+	// expired buckets are dropped
+	fakeClock.Step(2 * time.Hour)
+	records, err = m.GetEvictionRecords(context.TODO(), pods[:1])
+	require.NoError(t, err)
+	assert.Empty(t, records["p-1"].Buckets.List)
+}
@@ -37,6 +37,10 @@ type InitOptions struct {
 
 type InitFunc func(InitOptions) (EvictionRecordManager, error)
 
+func init() {
+	RegisterEvictionRecordManagerInitializer(EvictionRecordManagerNameLocal, NewLocalEvictionRecordManager)
+}
+
 func RegisterEvictionRecordManagerInitializer(name string, initFunc InitFunc) {
 	initializers.Store(name, initFunc)
 }
@@ -0,0 +1,39 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package record
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+// GetWorkloadKey returns the key of workload that the pod belongs to, and pods
+// without controller are treated as workloads of their own.
+func GetWorkloadKey(pod *v1.Pod) string {
+	if pod == nil {
+		return ""
+	}
+
+	if owner := metav1.GetControllerOf(pod); owner != nil {
+		return fmt.Sprintf("%s/%s", pod.Namespace, native.GenerateObjectOwnerReferenceKey(*owner))
+	}
+	return fmt.Sprintf("%s/%s,%s,%s", pod.Namespace, "v1", "Pod", pod.Name)
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rule
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/time/rate"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clocks "k8s.io/utils/clock"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/record"
+	pkgconfig "github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	metricsNameEvictionQueueBlocked = "eviction_queue_blocked"
+
+	blockedReasonNodeRateLimit     = "node_rate_limit"
+	blockedReasonWorkloadRateLimit = "workload_rate_limit"
+	blockedReasonPDB               = "pdb"
+)
+
+// workloadLimiter is the token bucket of a workload, and it's garbage
+// collected if not used for a long time
+type workloadLimiter struct {
+	*rate.Limiter
+	lastUsed time.Time
+}
+
+// RateLimitedEvictionQueue paces EvictPods popped from FIFOEvictionQueue, it enforces
+// node-wide and per-workload token buckets, and keeps EvictPods in the queue if the
+// disruption budget of their PodDisruptionBudget has been exhausted; EvictPods requested
+// with force eviction by plugins are never paced.
+//
+// Token buckets are kept in memory. Workload buckets are restored from the eviction
+// records persisted by record manager when they are created, while the node-wide bucket
+// starts full after agent restarts.
+type RateLimitedEvictionQueue struct {
+	*FIFOEvictionQueue
+
+	nodeLimiter      *rate.Limiter
+	workloadQPS      rate.Limit
+	workloadBurst    int
+	workloadLimiters map[string]*workloadLimiter
+	respectPDB       bool
+
+	recordManager record.EvictionRecordManager
+	emitter       metrics.MetricEmitter
+	clock         clocks.Clock
+}
+
+func NewRateLimitedEvictionQueue(conf *pkgconfig.Configuration, recordManager record.EvictionRecordManager,
+	emitter metrics.MetricEmitter,
+) EvictionQueue {
+	q := &RateLimitedEvictionQueue{
+		FIFOEvictionQueue: &FIFOEvictionQueue{
+			limited: conf.EvictionBurst,
+			podIDs:  make(map[types.UID]interface{}),
+		},
+		workloadLimiters: make(map[string]*workloadLimiter),
+		respectPDB:       conf.EvictionRespectPDB,
+		recordManager:    recordManager,
+		emitter:          emitter,
+		clock:            clocks.RealClock{},
+	}
+
+	if conf.NodeEvictionQPS > 0 {
+		q.nodeLimiter = rate.NewLimiter(rate.Limit(conf.NodeEvictionQPS), general.Max(conf.NodeEvictionQPSBurst, 1))
+	}
+	if conf.WorkloadEvictionQPS > 0 {
+		q.workloadQPS = rate.Limit(conf.WorkloadEvictionQPS)
+		q.workloadBurst = general.Max(conf.WorkloadEvictionQPSBurst, 1)
+	}
+	return q
+}
+
+func (q *RateLimitedEvictionQueue) Pop() RuledEvictPodList {
+	q.Lock()
+	defer q.Unlock()
+
+	now := q.clock.Now()
+	q.gcWorkloadLimiters(now)
+	records := q.getEvictionRecords()
+
+	rpList := RuledEvictPodList{}
+	remained := RuledEvictPodList{}
+	disruptions := make(map[string]int32)
+	for _, rp := range q.rpList {
+		if q.limited >= 0 && len(rpList) >= q.limited {
+			remained = append(remained, rp)
+			continue
+		}
+
+		if rp.Scope == EvictionScopeForce {
+			rpList = append(rpList, rp)
+			continue
+		}
+
+		r, hasRecord := records[string(rp.Pod.UID)]
+		// disruptions are accounted per PodDisruptionBudget, since a PodDisruptionBudget
+		// may select pods of several workloads and vice versa
+		pdbLimited := q.respectPDB && hasRecord && r.HasPDB
+		if pdbLimited && r.DisruptionsAllowed-disruptions[r.PDBKey] <= 0 {
+			q.blocked(rp, blockedReasonPDB)
+			remained = append(remained, rp)
+			continue
+		}
+
+		if reason := q.acquire(record.GetWorkloadKey(rp.Pod), r.Buckets, now); reason != "" {
+			q.blocked(rp, reason)
+			remained = append(remained, rp)
+			continue
+		}
+
+		if pdbLimited {
+			disruptions[r.PDBKey]++
+		}
+		rpList = append(rpList, rp)
+	}
+
+	q.rpList = remained
+	for _, rp := range rpList {
+		delete(q.podIDs, rp.Pod.UID)
+	}
+	return rpList
+}
+
+// acquire takes one token from both workload and node token buckets, and returns
+// the blocked reason if any of them has no available token
+func (q *RateLimitedEvictionQueue) acquire(workloadKey string, buckets record.Buckets, now time.Time) string {
+	var workloadReservation *rate.Reservation
+	if limiter := q.getWorkloadLimiter(workloadKey, buckets, now); limiter != nil {
+		workloadReservation = limiter.ReserveN(now, 1)
+		if !workloadReservation.OK() || workloadReservation.DelayFrom(now) > 0 {
+			workloadReservation.CancelAt(now)
+			return blockedReasonWorkloadRateLimit
+		}
+	}
+
+	if q.nodeLimiter != nil {
+		nodeReservation := q.nodeLimiter.ReserveN(now, 1)
+		if !nodeReservation.OK() || nodeReservation.DelayFrom(now) > 0 {
+			nodeReservation.CancelAt(now)
+			if workloadReservation != nil {
+				workloadReservation.CancelAt(now)
+			}
+			return blockedReasonNodeRateLimit
+		}
+	}
+	return ""
+}
+
+// getWorkloadLimiter returns the token bucket of the workload, and a newly created one consumes
+// tokens of the evictions recorded within its refill duration, so that the pace of workloads
+// survives agent restarts.
+func (q *RateLimitedEvictionQueue) getWorkloadLimiter(workloadKey string, buckets record.Buckets, now time.Time) *rate.Limiter {
+	if q.workloadQPS <= 0 {
+		return nil
+	}
+
+	limiter, ok := q.workloadLimiters[workloadKey]
+	if !ok {
+		limiter = &workloadLimiter{Limiter: rate.NewLimiter(q.workloadQPS, q.workloadBurst)}
+		if evicted := q.recentEvictions(buckets, now); evicted > 0 {
+			limiter.ReserveN(now, general.Min(evicted, q.workloadBurst))
+		}
+		q.workloadLimiters[workloadKey] = limiter
+	}
+	limiter.lastUsed = now
+	return limiter.Limiter
+}
+
+// recentEvictions counts the evictions in buckets overlapping with the refill duration
+func (q *RateLimitedEvictionQueue) recentEvictions(buckets record.Buckets, now time.Time) int {
+	since := now.Add(-q.workloadRefillDuration()).Unix()
+
+	evicted := 0
+	for _, bucket := range buckets.List {
+		if bucket.Time+bucket.Duration > since {
+			evicted += int(bucket.Count)
+		}
+	}
+	return evicted
+}
+
+// workloadRefillDuration is the duration for an empty workload token bucket to be full again
+func (q *RateLimitedEvictionQueue) workloadRefillDuration() time.Duration {
+	return time.Duration(float64(q.workloadBurst) / float64(q.workloadQPS) * float64(time.Second))
+}
+
+// gcWorkloadLimiters drops token buckets that have been refilled completely,
+// since they are the same as newly created ones
+func (q *RateLimitedEvictionQueue) gcWorkloadLimiters(now time.Time) {
+	if q.workloadQPS <= 0 {
+		return
+	}
+
+	refillDuration := q.workloadRefillDuration()
+	for key, limiter := range q.workloadLimiters {
+		if now.Sub(limiter.lastUsed) > refillDuration {
+			delete(q.workloadLimiters, key)
+		}
+	}
+}
+
+// getEvictionRecords returns eviction records of EvictPods in the queue, and it must be called with lock held
+func (q *RateLimitedEvictionQueue) getEvictionRecords() map[string]record.EvictionRecord {
+	if (!q.respectPDB && q.workloadQPS <= 0) || q.recordManager == nil || len(q.rpList) == 0 {
+		return nil
+	}
+
+	pods := make([]*v1.Pod, 0, len(q.rpList))
+	for _, rp := range q.rpList {
+		pods = append(pods, rp.Pod)
+	}
+
+	records, err := q.recordManager.GetEvictionRecords(context.Background(), pods)
+	if err != nil {
+		general.Errorf("failed to get eviction records: %v", err)
+		return nil
+	}
+	return records
+}
+
+func (q *RateLimitedEvictionQueue) blocked(rp *RuledEvictPod, reason string) {
+	general.Infof("keep pod %s/%s in eviction queue by %s", rp.Pod.Namespace, rp.Pod.Name, reason)
+	_ = q.emitter.StoreInt64(metricsNameEvictionQueueBlocked, 1, metrics.MetricTypeNameCount,
+		metrics.MetricTag{Key: "reason", Val: reason},
+		metrics.MetricTag{Key: "plugin", Val: rp.EvictionPluginName})
+}
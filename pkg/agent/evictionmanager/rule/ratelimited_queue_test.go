@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/record"
+	pkgconfig "github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+type fakeEvictionRecordManager struct {
+	records map[string]record.EvictionRecord
+}
+
+func (f *fakeEvictionRecordManager) GetEvictionRecords(_ context.Context, _ []*v1.Pod) (map[string]record.EvictionRecord, error) {
+	return f.records, nil
+}
+
+func (f *fakeEvictionRecordManager) Run(_ context.Context) {}
+
+func makeWorkloadRuledEvictPod(name, workload, scope string) *RuledEvictPod {
+	rp := makeRuledEvictPod(name, scope)
+	isController := true
+	rp.Pod.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: "apps/v1",
+		Kind:       "ReplicaSet",
+		Name:       workload,
+		Controller: &isController,
+	}}
+	return rp
+}
+
+func TestRateLimitedEvictionQueue(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		comment string
+		setConf func(conf *pkgconfig.Configuration)
+		records map[string]record.EvictionRecord
+		pods    RuledEvictPodList
+		// popResults are the results of popping after the clock steps for each element
+		steps      []time.Duration
+		popResults [][]string
+	}{
+		{
+			comment: "node level token bucket",
+			setConf: func(conf *pkgconfig.Configuration) {
+				conf.NodeEvictionQPS = 1
+				conf.NodeEvictionQPSBurst = 2
+			},
+			pods: RuledEvictPodList{
+				makeWorkloadRuledEvictPod("p-1", "w-1", EvictionScopeSoft),
+				makeWorkloadRuledEvictPod("p-2", "w-2", EvictionScopeSoft),
+				makeWorkloadRuledEvictPod("p-3", "w-3", EvictionScopeSoft),
+			},
+			steps:      []time.Duration{0, 0, time.Second},
+			popResults: [][]string{{"p-1", "p-2"}, {}, {"p-3"}},
+		},
+		{
+			comment: "workload level token bucket",
+			setConf: func(conf *pkgconfig.Configuration) {
+				conf.WorkloadEvictionQPS = 0.1
+				conf.WorkloadEvictionQPSBurst = 1
+			},
+			pods: RuledEvictPodList{
+				makeWorkloadRuledEvictPod("p-1", "w-1", EvictionScopeSoft),
+				makeWorkloadRuledEvictPod("p-2", "w-1", EvictionScopeSoft),
+				makeWorkloadRuledEvictPod("p-3", "w-2", EvictionScopeSoft),
+			},
+			steps:      []time.Duration{0, time.Second, 10 * time.Second},
+			popResults: [][]string{{"p-1", "p-3"}, {}, {"p-2"}},
+		},
+		{
+			comment: "force eviction is not paced",
+			setConf: func(conf *pkgconfig.Configuration) {
+				conf.NodeEvictionQPS = 0.1
+				conf.NodeEvictionQPSBurst = 1
+			},
+			pods: RuledEvictPodList{
+				makeWorkloadRuledEvictPod("p-1", "w-1", EvictionScopeSoft),
+				makeWorkloadRuledEvictPod("p-2", "w-2", EvictionScopeSoft),
+				makeWorkloadRuledEvictPod("p-3", "w-3", EvictionScopeForce),
+			},
+			steps:      []time.Duration{0},
+			popResults: [][]string{{"p-1", "p-3"}},
+		},
+		{
+			comment: "pdb disruption budget",
+			setConf: func(conf *pkgconfig.Configuration) {
+				conf.EvictionRespectPDB = true
+			},
+			records: map[string]record.EvictionRecord{
+				"p-1": {HasPDB: true, PDBKey: "default/pdb-1", DisruptionsAllowed: 1},
+				"p-2": {HasPDB: true, PDBKey: "default/pdb-1", DisruptionsAllowed: 1},
+				"p-3": {HasPDB: true, PDBKey: "default/pdb-2", DisruptionsAllowed: 0},
+				"p-4": {HasPDB: false},
+			},
+			pods: RuledEvictPodList{
+				makeWorkloadRuledEvictPod("p-1", "w-1", EvictionScopeSoft),
+				makeWorkloadRuledEvictPod("p-2", "w-1", EvictionScopeSoft),
+				makeWorkloadRuledEvictPod("p-3", "w-2", EvictionScopeSoft),
+				makeWorkloadRuledEvictPod("p-4", "w-3", EvictionScopeSoft),
+			},
+			steps:      []time.Duration{0},
+			popResults: [][]string{{"p-1", "p-4"}},
+		},
+		{
+			comment: "pdb disruption budget shared by workloads",
+			setConf: func(conf *pkgconfig.Configuration) {
+				conf.EvictionRespectPDB = true
+			},
+			records: map[string]record.EvictionRecord{
+				"p-1": {HasPDB: true, PDBKey: "default/pdb-1", DisruptionsAllowed: 1},
+				"p-2": {HasPDB: true, PDBKey: "default/pdb-1", DisruptionsAllowed: 1},
+				"p-3": {HasPDB: true, PDBKey: "default/pdb-2", DisruptionsAllowed: 1},
+			},
+			pods: RuledEvictPodList{
+				makeWorkloadRuledEvictPod("p-1", "w-1", EvictionScopeSoft),
+				makeWorkloadRuledEvictPod("p-2", "w-2", EvictionScopeSoft),
+				makeWorkloadRuledEvictPod("p-3", "w-2", EvictionScopeSoft),
+			},
+			steps:      []time.Duration{0},
+			popResults: [][]string{{"p-1", "p-3"}},
+		},
+		{
+			comment: "workload token bucket restored from eviction records",
+			setConf: func(conf *pkgconfig.Configuration) {
+				conf.WorkloadEvictionQPS = 0.1
+				conf.WorkloadEvictionQPSBurst = 1
+			},
+			records: map[string]record.EvictionRecord{
+				"p-1": {Buckets: record.Buckets{List: []record.Bucket{
+					{Time: time.Now().Unix(), Count: 1, Duration: 1},
+				}}},
+				"p-2": {Buckets: record.Buckets{List: []record.Bucket{
+					{Time: time.Now().Add(-time.Minute).Unix(), Count: 1, Duration: 1},
+				}}},
+			},
+			pods: RuledEvictPodList{
+				makeWorkloadRuledEvictPod("p-1", "w-1", EvictionScopeSoft),
+				makeWorkloadRuledEvictPod("p-2", "w-2", EvictionScopeSoft),
+			},
+			steps:      []time.Duration{0, 10 * time.Second},
+			popResults: [][]string{{"p-2"}, {"p-1"}},
+		},
+	} {
+		tc := tc
+		t.Run(tc.comment, func(t *testing.T) {
+			t.Parallel()
+
+			conf := pkgconfig.NewConfiguration()
+			conf.EvictionBurst = -1
+			tc.setConf(conf)
+
+			fakeClock := testingclock.NewFakeClock(time.Now())
+			q := NewRateLimitedEvictionQueue(conf, &fakeEvictionRecordManager{records: tc.records}, metrics.DummyMetrics{})
+			q.(*RateLimitedEvictionQueue).clock = fakeClock
+
+			q.Add(tc.pods, true)
+			for i, step := range tc.steps {
+				fakeClock.Step(step)
+				assert.Equal(t, tc.popResults[i], q.Pop().getPodNames(), "pop %d", i)
+			}
+		})
+	}
+}
@@ -18,6 +18,9 @@ package executor
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
@@ -29,17 +32,45 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/util/native"
 )
 
+// AllocationApplyErrorAnnotationKey is set in the annotations of resource allocation info
+// when ORM fails to apply it to cgroups, and it's persisted in ORM checkpoint as well.
+const AllocationApplyErrorAnnotationKey = "orm.katalyst.kubewharf.io/apply-error"
+
 type Executor interface {
 	UpdateContainerResources(pod *v1.Pod, container *v1.Container, resourceAllocation map[string]*v1alpha1.ResourceAllocationInfo) error
 }
 
+// PropertyErrors records errors of applying allocation results, keyed by resource name,
+// it will be returned by UpdateContainerResources if any of the properties fails.
+type PropertyErrors map[string]error
+
+func (pe PropertyErrors) Error() string {
+	resourceNames := make([]string, 0, len(pe))
+	for resourceName := range pe {
+		resourceNames = append(resourceNames, resourceName)
+	}
+	sort.Strings(resourceNames)
+
+	errMsgs := make([]string, 0, len(resourceNames))
+	for _, resourceName := range resourceNames {
+		errMsgs = append(errMsgs, fmt.Sprintf("%s: %v", resourceName, pe[resourceName]))
+	}
+	return strings.Join(errMsgs, "; ")
+}
+
 type Impl struct {
 	cgroupManager cgroupmgr.Manager
+
+	// those functions are only overridden in unit tests
+	containerAbsCgroupPath func(subsys, podUID, containerID string) (string, error)
+	isCgroupV2             func() bool
 }
 
 func NewExecutor(cgroupManager cgroupmgr.Manager) Executor {
 	return &Impl{
-		cgroupManager: cgroupManager,
+		cgroupManager:          cgroupManager,
+		containerAbsCgroupPath: common.GetContainerAbsCgroupPath,
+		isCgroupV2:             common.CheckCgroup2UnifiedMode,
 	}
 }
 
@@ -53,38 +84,218 @@ func (ei *Impl) UpdateContainerResources(pod *v1.Pod, container *v1.Container, r
 		return fmt.Errorf("empty resourceAllocation for pod: %v, container: %v", pod.Name, container.Name)
 	}
 
-	CPUSetData := &common.CPUSetData{}
+	containerID, err := native.GetContainerID(pod, container.Name)
+	if err != nil {
+		klog.Errorf("[ORM] GetContainerID fail, pod: %v, container: %v, err: %v", pod.Name, container.Name, err)
+		return err
+	}
+
+	var (
+		CPUSetData          = &common.CPUSetData{}
+		CPUData             = &common.CPUData{}
+		cpusetResourceNames []string
+		cpuResourceNames    []string
+	)
+	propertyErrors := make(PropertyErrors)
+
+	// iterate in order of resource names to make the result stable
+	resourceNames := make([]string, 0, len(resourceAllocation))
+	for resourceName := range resourceAllocation {
+		resourceNames = append(resourceNames, resourceName)
+	}
+	sort.Strings(resourceNames)
+
+	for _, resourceName := range resourceNames {
+		resourceAllocationInfo := resourceAllocation[resourceName]
+		if resourceAllocationInfo == nil || resourceAllocationInfo.AllocationResult == "" {
+			continue
+		}
 
-	for _, resourceAllocationInfo := range resourceAllocation {
+		result := resourceAllocationInfo.AllocationResult
 		switch resourceAllocationInfo.OciPropertyName {
 		case util.OCIPropertyNameCPUSetCPUs:
-			if resourceAllocationInfo.AllocationResult != "" {
-				CPUSetData.CPUs = resourceAllocationInfo.AllocationResult
-			}
+			CPUSetData.CPUs = result
+			cpusetResourceNames = append(cpusetResourceNames, resourceName)
 		case util.OCIPropertyNameCPUSetMems:
-			if resourceAllocationInfo.AllocationResult != "" {
-				CPUSetData.Mems = resourceAllocationInfo.AllocationResult
+			CPUSetData.Mems = result
+			cpusetResourceNames = append(cpusetResourceNames, resourceName)
+		case util.OCIPropertyNameCPUShares:
+			if CPUData.Shares, err = strconv.ParseUint(result, 10, 64); err != nil {
+				propertyErrors[resourceName] = fmt.Errorf("invalid cpu shares %q: %v", result, err)
+				continue
+			}
+			cpuResourceNames = append(cpuResourceNames, resourceName)
+		case util.OCIPropertyNameCPUQuota:
+			if CPUData.CpuQuota, err = strconv.ParseInt(result, 10, 64); err != nil {
+				propertyErrors[resourceName] = fmt.Errorf("invalid cpu quota %q: %v", result, err)
+				continue
+			}
+			cpuResourceNames = append(cpuResourceNames, resourceName)
+		case util.OCIPropertyNameCPUPeriod:
+			if CPUData.CpuPeriod, err = strconv.ParseUint(result, 10, 64); err != nil {
+				propertyErrors[resourceName] = fmt.Errorf("invalid cpu period %q: %v", result, err)
+				continue
+			}
+			cpuResourceNames = append(cpuResourceNames, resourceName)
+		case util.OCIPropertyNameMemoryLimitInBytes:
+			if err = ei.applyMemoryLimit(string(pod.UID), containerID, result); err != nil {
+				propertyErrors[resourceName] = err
+			}
+		case util.OCIPropertyNameMemoryHighInBytes:
+			if err = ei.applyMemoryHigh(string(pod.UID), containerID, result); err != nil {
+				propertyErrors[resourceName] = err
+			}
+		case util.OCIPropertyNameHugepageLimits:
+			if err = ei.applyHugepageLimits(string(pod.UID), containerID, result); err != nil {
+				propertyErrors[resourceName] = err
+			}
+		case util.OCIPropertyNameIOWeight:
+			if err = ei.applyIOWeight(string(pod.UID), containerID, result); err != nil {
+				propertyErrors[resourceName] = err
 			}
 		default:
 
 		}
 	}
 
-	absCgroupPath, err := ei.containerCgroupPath(pod, container)
+	if len(cpusetResourceNames) > 0 {
+		if err = ei.applyCPUSetData(string(pod.UID), containerID, CPUSetData); err != nil {
+			klog.Errorf("[ORM] commitCPUSet fail, pod: %v, container: %v, err: %v", pod.Name, container.Name, err)
+			for _, resourceName := range cpusetResourceNames {
+				propertyErrors[resourceName] = err
+			}
+		}
+	}
+
+	if len(cpuResourceNames) > 0 {
+		if err = ei.applyCPUData(string(pod.UID), containerID, CPUData); err != nil {
+			klog.Errorf("[ORM] applyCPU fail, pod: %v, container: %v, err: %v", pod.Name, container.Name, err)
+			for _, resourceName := range cpuResourceNames {
+				propertyErrors[resourceName] = err
+			}
+		}
+	}
+
+	if len(propertyErrors) > 0 {
+		klog.Errorf("[ORM] UpdateContainerResources partially fail, pod: %v, container: %v, err: %v",
+			pod.Name, container.Name, propertyErrors)
+		return propertyErrors
+	}
+
+	return nil
+}
+
+func (ei *Impl) applyCPUSetData(podUID, containerID string, data *common.CPUSetData) error {
+	absCgroupPath, err := ei.containerAbsCgroupPath(common.CgroupSubsysCPUSet, podUID, containerID)
 	if err != nil {
-		klog.Errorf("[ORM] containerCgroupPath fail, pod: %v, container: %v, err: %v", pod.Name, container.Name, err)
 		return err
 	}
 
-	err = ei.commitCPUSet(absCgroupPath, CPUSetData)
+	return ei.commitCPUSet(absCgroupPath, data)
+}
+
+func (ei *Impl) applyCPUData(podUID, containerID string, data *common.CPUData) error {
+	absCgroupPath, err := ei.containerAbsCgroupPath(common.CgroupSubsysCPU, podUID, containerID)
+	if err != nil {
+		return err
+	}
+
+	return ei.cgroupManager.ApplyCPU(absCgroupPath, data)
+}
+
+func (ei *Impl) applyMemoryLimit(podUID, containerID, result string) error {
+	limitInBytes, err := strconv.ParseInt(result, 10, 64)
+	if err != nil || limitInBytes <= 0 {
+		return fmt.Errorf("invalid memory limit %q", result)
+	}
+
+	absCgroupPath, err := ei.containerAbsCgroupPath(common.CgroupSubsysMemory, podUID, containerID)
 	if err != nil {
-		klog.Errorf("[ORM] commitCPUSet fail, pod: %v, container: %v, err: %v", pod.Name, container.Name, err)
 		return err
 	}
 
+	return ei.cgroupManager.ApplyMemory(absCgroupPath, &common.MemoryData{LimitInBytes: limitInBytes})
+}
+
+func (ei *Impl) applyMemoryHigh(podUID, containerID, result string) error {
+	if !ei.isCgroupV2() {
+		return fmt.Errorf("cgroups v1 does not support memory.high")
+	}
+
+	highInBytes, err := strconv.ParseInt(result, 10, 64)
+	if err != nil || highInBytes <= 0 {
+		return fmt.Errorf("invalid memory high %q", result)
+	}
+
+	absCgroupPath, err := ei.containerAbsCgroupPath(common.CgroupSubsysMemory, podUID, containerID)
+	if err != nil {
+		return err
+	}
+
+	return ei.cgroupManager.ApplyMemory(absCgroupPath, &common.MemoryData{HighInBytes: highInBytes})
+}
+
+// applyHugepageLimits writes hugetlb.<size>.limit_in_bytes for cgroup v1, and hugetlb.<size>.max for cgroup v2
+func (ei *Impl) applyHugepageLimits(podUID, containerID, result string) error {
+	absCgroupPath, err := ei.containerAbsCgroupPath(common.CgroupSubsysHugeTLB, podUID, containerID)
+	if err != nil {
+		return err
+	}
+
+	limitFileSuffix := "limit_in_bytes"
+	if ei.isCgroupV2() {
+		limitFileSuffix = "max"
+	}
+
+	var errMsgs []string
+	for _, item := range strings.Split(result, ",") {
+		fields := strings.Split(strings.TrimSpace(item), ":")
+		if len(fields) != 2 || fields[0] == "" {
+			errMsgs = append(errMsgs, fmt.Sprintf("invalid hugepage limit %q", item))
+			continue
+		}
+
+		if _, err := strconv.ParseUint(fields[1], 10, 64); err != nil {
+			errMsgs = append(errMsgs, fmt.Sprintf("invalid hugepage limit %q", item))
+			continue
+		}
+
+		fileName := fmt.Sprintf("hugetlb.%s.%s", fields[0], limitFileSuffix)
+		if err := ei.cgroupManager.ApplyUnifiedData(absCgroupPath, fileName, fields[1]); err != nil {
+			errMsgs = append(errMsgs, fmt.Sprintf("apply %s failed: %v", fileName, err))
+		}
+	}
+
+	if len(errMsgs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errMsgs, "; "))
+	}
 	return nil
 }
 
+// applyIOWeight writes the default io weight, it's converted to blkio.weight for cgroup v1
+func (ei *Impl) applyIOWeight(podUID, containerID, result string) error {
+	weight, err := strconv.ParseUint(result, 10, 64)
+	if err != nil || weight < 1 || weight > 10000 {
+		return fmt.Errorf("invalid io weight %q", result)
+	}
+
+	if ei.isCgroupV2() {
+		absCgroupPath, err := ei.containerAbsCgroupPath(common.CgroupSubsysIO, podUID, containerID)
+		if err != nil {
+			return err
+		}
+		return ei.cgroupManager.ApplyUnifiedData(absCgroupPath, "io.weight", fmt.Sprintf("default %d", weight))
+	}
+
+	absCgroupPath, err := ei.containerAbsCgroupPath(common.CgroupSubsysBlkIO, podUID, containerID)
+	if err != nil {
+		return err
+	}
+	// blkio.weight ranges in [10, 1000], refer to the reversed conversion in runc
+	blkioWeight := 10 + (weight-1)*990/9999
+	return ei.cgroupManager.ApplyUnifiedData(absCgroupPath, "blkio.weight", strconv.FormatUint(blkioWeight, 10))
+}
+
 // applyCPUSet apply CPUSet data by cgroupManager
 func (ei *Impl) applyCPUSet(absCgroupPath string, data *common.CPUSetData) error {
 	return ei.cgroupManager.ApplyCPUSet(absCgroupPath, data)
@@ -117,17 +328,3 @@ func (ei *Impl) commitCPUSet(absCgroupPath string, data *common.CPUSetData) erro
 
 	return nil
 }
-
-func (ei *Impl) containerCgroupPath(pod *v1.Pod, container *v1.Container) (string, error) {
-	containerID, err := native.GetContainerID(pod, container.Name)
-	if err != nil {
-		return "", err
-	}
-
-	absCgroupPath, err := common.GetContainerAbsCgroupPath(common.CgroupSubsysCPUSet, string(pod.UID), containerID)
-	if err != nil {
-		return "", err
-	}
-
-	return absCgroupPath, nil
-}
@@ -17,6 +17,7 @@ limitations under the License.
 package executor
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -24,6 +25,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
 
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/util"
 	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
 	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/manager"
 )
@@ -98,3 +100,171 @@ func TestCommitCPUSet(t *testing.T) {
 	})
 	assert.Nil(t, err)
 }
+
+type recordingCgroupManager struct {
+	manager.FakeCgroupManager
+
+	failedFiles map[string]bool
+	cpuSet      *common.CPUSetData
+	cpu         *common.CPUData
+	memory      *common.MemoryData
+	files       map[string]string
+}
+
+func (r *recordingCgroupManager) ApplyCPUSet(_ string, data *common.CPUSetData) error {
+	r.cpuSet = data
+	return nil
+}
+
+func (r *recordingCgroupManager) ApplyCPU(_ string, data *common.CPUData) error {
+	r.cpu = data
+	return nil
+}
+
+func (r *recordingCgroupManager) ApplyMemory(_ string, data *common.MemoryData) error {
+	// properties are applied in separate calls, so merge them to check all together
+	if r.memory == nil {
+		r.memory = &common.MemoryData{}
+	}
+	if data.LimitInBytes != 0 {
+		r.memory.LimitInBytes = data.LimitInBytes
+	}
+	if data.HighInBytes != 0 {
+		r.memory.HighInBytes = data.HighInBytes
+	}
+	return nil
+}
+
+func (r *recordingCgroupManager) ApplyUnifiedData(absCgroupPath, cgroupFileName, data string) error {
+	if r.failedFiles[cgroupFileName] {
+		return fmt.Errorf("write %s failed", cgroupFileName)
+	}
+	r.files[absCgroupPath+"/"+cgroupFileName] = data
+	return nil
+}
+
+func (r *recordingCgroupManager) GetCPUSet(_ string) (*common.CPUSetStats, error) {
+	return &common.CPUSetStats{}, nil
+}
+
+func TestImpl_UpdateContainerResourcesProperties(t *testing.T) {
+	t.Parallel()
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testPod",
+			UID:  "testUID",
+		},
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					Name:        "testContainer",
+					ContainerID: "containerd://containerID",
+				},
+			},
+		},
+	}
+	container := &v1.Container{Name: "testContainer"}
+
+	tests := []struct {
+		name            string
+		cgroupV2        bool
+		failedFiles     map[string]bool
+		allocation      map[string]*v1alpha1.ResourceAllocationInfo
+		wantCPUSet      *common.CPUSetData
+		wantCPU         *common.CPUData
+		wantMemory      *common.MemoryData
+		wantFiles       map[string]string
+		wantErrResource []string
+	}{
+		{
+			name:     "cgroup v2 all properties",
+			cgroupV2: true,
+			allocation: map[string]*v1alpha1.ResourceAllocationInfo{
+				"cpu":            {OciPropertyName: util.OCIPropertyNameCPUSetCPUs, AllocationResult: "0-3"},
+				"memory":         {OciPropertyName: util.OCIPropertyNameCPUSetMems, AllocationResult: "0"},
+				"cpu_shares":     {OciPropertyName: util.OCIPropertyNameCPUShares, AllocationResult: "1024"},
+				"cpu_quota":      {OciPropertyName: util.OCIPropertyNameCPUQuota, AllocationResult: "200000"},
+				"cpu_period":     {OciPropertyName: util.OCIPropertyNameCPUPeriod, AllocationResult: "100000"},
+				"memory_limit":   {OciPropertyName: util.OCIPropertyNameMemoryLimitInBytes, AllocationResult: "1073741824"},
+				"memory_high":    {OciPropertyName: util.OCIPropertyNameMemoryHighInBytes, AllocationResult: "536870912"},
+				"hugepage_limit": {OciPropertyName: util.OCIPropertyNameHugepageLimits, AllocationResult: "2MB:2097152,1GB:0"},
+				"io_weight":      {OciPropertyName: util.OCIPropertyNameIOWeight, AllocationResult: "500"},
+			},
+			wantCPUSet: &common.CPUSetData{CPUs: "0-3", Mems: "0"},
+			wantCPU:    &common.CPUData{Shares: 1024, CpuQuota: 200000, CpuPeriod: 100000},
+			wantMemory: &common.MemoryData{LimitInBytes: 1073741824, HighInBytes: 536870912},
+			wantFiles: map[string]string{
+				"/hugetlb/hugetlb.2MB.max": "2097152",
+				"/hugetlb/hugetlb.1GB.max": "0",
+				"/io/io.weight":            "default 500",
+			},
+		},
+		{
+			name:     "cgroup v1 properties",
+			cgroupV2: false,
+			allocation: map[string]*v1alpha1.ResourceAllocationInfo{
+				"memory_high":    {OciPropertyName: util.OCIPropertyNameMemoryHighInBytes, AllocationResult: "536870912"},
+				"hugepage_limit": {OciPropertyName: util.OCIPropertyNameHugepageLimits, AllocationResult: "2MB:2097152"},
+				"io_weight":      {OciPropertyName: util.OCIPropertyNameIOWeight, AllocationResult: "10000"},
+			},
+			wantFiles: map[string]string{
+				"/hugetlb/hugetlb.2MB.limit_in_bytes": "2097152",
+				"/blkio/blkio.weight":                 "1000",
+			},
+			wantErrResource: []string{"memory_high"},
+		},
+		{
+			name:        "invalid and failed properties",
+			cgroupV2:    true,
+			failedFiles: map[string]bool{"io.weight": true},
+			allocation: map[string]*v1alpha1.ResourceAllocationInfo{
+				"cpu_shares":     {OciPropertyName: util.OCIPropertyNameCPUShares, AllocationResult: "-1"},
+				"cpu_quota":      {OciPropertyName: util.OCIPropertyNameCPUQuota, AllocationResult: "-1"},
+				"memory_limit":   {OciPropertyName: util.OCIPropertyNameMemoryLimitInBytes, AllocationResult: "abc"},
+				"hugepage_limit": {OciPropertyName: util.OCIPropertyNameHugepageLimits, AllocationResult: "2MB"},
+				"io_weight":      {OciPropertyName: util.OCIPropertyNameIOWeight, AllocationResult: "100"},
+				"unknown":        {OciPropertyName: "Unknown", AllocationResult: "100"},
+			},
+			wantCPU:         &common.CPUData{CpuQuota: -1},
+			wantFiles:       map[string]string{},
+			wantErrResource: []string{"cpu_shares", "hugepage_limit", "io_weight", "memory_limit"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cgroupManager := &recordingCgroupManager{
+				failedFiles: tt.failedFiles,
+				files:       make(map[string]string),
+			}
+			impl := &Impl{
+				cgroupManager: cgroupManager,
+				containerAbsCgroupPath: func(subsys, _, _ string) (string, error) {
+					return "/" + subsys, nil
+				},
+				isCgroupV2: func() bool { return tt.cgroupV2 },
+			}
+
+			err := impl.UpdateContainerResources(pod, container, tt.allocation)
+			if len(tt.wantErrResource) == 0 {
+				assert.NoError(t, err)
+			} else {
+				propertyErrors, ok := err.(PropertyErrors)
+				assert.True(t, ok)
+				gotErrResource := make([]string, 0, len(propertyErrors))
+				for resourceName := range propertyErrors {
+					gotErrResource = append(gotErrResource, resourceName)
+				}
+				assert.ElementsMatch(t, tt.wantErrResource, gotErrResource)
+			}
+
+			assert.Equal(t, tt.wantCPUSet, cgroupManager.cpuSet)
+			assert.Equal(t, tt.wantCPU, cgroupManager.cpu)
+			assert.Equal(t, tt.wantMemory, cgroupManager.memory)
+			assert.Equal(t, tt.wantFiles, cgroupManager.files)
+		})
+	}
+}
@@ -572,6 +572,9 @@ func (m *ManagerImpl) syncContainer(pod *v1.Pod, container *v1.Container) error
 	}
 
 	err := m.resourceExecutor.UpdateContainerResources(pod, container, containerAllResources)
+	if m.updateApplyErrors(pod, container, containerAllResources, err) {
+		_ = m.writeCheckpoint()
+	}
 	if err != nil {
 		klog.Errorf("[ORM] UpdateContainerResources fail, pod: %v, container: %v, err: %v", pod.Name, container.Name, err)
 		return err
@@ -580,6 +583,29 @@ func (m *ManagerImpl) syncContainer(pod *v1.Pod, container *v1.Container) error
 	return nil
 }
 
+// updateApplyErrors records the per-property errors of executor into pod resources, so that
+// they can be persisted in checkpoint; errors not related to any property (e.g. container
+// not found) won't be recorded since they will be retried in the next round of reconciling.
+func (m *ManagerImpl) updateApplyErrors(pod *v1.Pod, container *v1.Container, resources ResourceAllocation, err error) bool {
+	propertyErrors, isPropertyErrors := err.(executor.PropertyErrors)
+	if err != nil && !isPropertyErrors {
+		return false
+	}
+
+	changed := false
+	for resourceName := range resources {
+		errMsg := ""
+		if propertyErr := propertyErrors[resourceName]; propertyErr != nil {
+			errMsg = propertyErr.Error()
+		}
+
+		if m.podResources.updateApplyError(string(pod.UID), container.Name, resourceName, errMsg) {
+			changed = true
+		}
+	}
+	return changed
+}
+
 func (m *ManagerImpl) reconcile() {
 	klog.V(5).Infof("[ORM] reconcile...")
 	resourceAllocationResps := make(map[string]*pluginapi.GetResourcesAllocationResponse)
@@ -28,6 +28,7 @@ import (
 	pluginapi "k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
 
 	"github.com/kubewharf/katalyst-core/pkg/agent/orm/checkpoint"
+	"github.com/kubewharf/katalyst-core/pkg/agent/orm/executor"
 )
 
 type (
@@ -108,7 +109,47 @@ func (pres *podResourcesChk) insert(podUID, contName, resourceName string, alloc
 		pres.resources[podUID][contName] = make(ResourceAllocation)
 	}
 
-	pres.resources[podUID][contName][resourceName] = proto.Clone(allocationInfo).(*pluginapi.ResourceAllocationInfo)
+	newAllocationInfo := proto.Clone(allocationInfo).(*pluginapi.ResourceAllocationInfo)
+	// keep the apply error recorded before, since allocation info refreshed from plugins never carries it,
+	// and it will only be updated by updateApplyError after the allocation result is applied again
+	if oldAllocationInfo := pres.resources[podUID][contName][resourceName]; oldAllocationInfo != nil {
+		if errMsg, ok := oldAllocationInfo.Annotations[executor.AllocationApplyErrorAnnotationKey]; ok {
+			if newAllocationInfo.Annotations == nil {
+				newAllocationInfo.Annotations = make(map[string]string)
+			}
+			if _, found := newAllocationInfo.Annotations[executor.AllocationApplyErrorAnnotationKey]; !found {
+				newAllocationInfo.Annotations[executor.AllocationApplyErrorAnnotationKey] = errMsg
+			}
+		}
+	}
+	pres.resources[podUID][contName][resourceName] = newAllocationInfo
+}
+
+// updateApplyError sets or clears the apply error in annotations of the resource allocation info,
+// and returns whether the allocation info has been changed.
+func (pres *podResourcesChk) updateApplyError(podUID, contName, resourceName, errMsg string) bool {
+	pres.Lock()
+	defer pres.Unlock()
+
+	if pres.resources[podUID] == nil || pres.resources[podUID][contName] == nil {
+		return false
+	}
+
+	allocationInfo := pres.resources[podUID][contName][resourceName]
+	if allocationInfo == nil || allocationInfo.Annotations[executor.AllocationApplyErrorAnnotationKey] == errMsg {
+		return false
+	}
+
+	if errMsg == "" {
+		delete(allocationInfo.Annotations, executor.AllocationApplyErrorAnnotationKey)
+		return true
+	}
+
+	if allocationInfo.Annotations == nil {
+		allocationInfo.Annotations = make(map[string]string)
+	}
+	allocationInfo.Annotations[executor.AllocationApplyErrorAnnotationKey] = errMsg
+	return true
 }
 
 func (pres *podResourcesChk) deleteResourceAllocationInfo(podUID, contName, resourceName string) {
@@ -25,6 +25,7 @@ import (
 	pluginapi "k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
 
 	"github.com/kubewharf/katalyst-core/pkg/agent/orm/checkpoint"
+	"github.com/kubewharf/katalyst-core/pkg/agent/orm/executor"
 )
 
 func TestPodResources(t *testing.T) {
@@ -180,3 +181,30 @@ func generateCpuSetMemsAllocationInfo() *pluginapi.ResourceAllocationInfo {
 		ResourceHints:     &pluginapi.ListOfTopologyHints{},
 	}
 }
+
+func TestPodResourcesUpdateApplyError(t *testing.T) {
+	t.Parallel()
+
+	podResource := newPodResourcesChk()
+	podResource.insert("testPod", "testContainer", "cpu", generateCpuSetCpusAllocationInfo())
+
+	assert.False(t, podResource.updateApplyError("nonPod", "testContainer", "cpu", "failed"))
+	assert.False(t, podResource.updateApplyError("testPod", "testContainer", "cpu", ""))
+
+	assert.True(t, podResource.updateApplyError("testPod", "testContainer", "cpu", "failed"))
+	assert.False(t, podResource.updateApplyError("testPod", "testContainer", "cpu", "failed"))
+
+	// apply error should be persisted into checkpoint
+	restored := newPodResourcesChk()
+	restored.fromCheckpointData(podResource.toCheckpointData())
+	assert.Equal(t, "failed",
+		restored.containerResource("testPod", "testContainer", "cpu").Annotations[executor.AllocationApplyErrorAnnotationKey])
+
+	// refreshing allocation info from plugin should not reset the apply error
+	podResource.insert("testPod", "testContainer", "cpu", generateCpuSetCpusAllocationInfo())
+	assert.False(t, podResource.updateApplyError("testPod", "testContainer", "cpu", "failed"))
+
+	assert.True(t, podResource.updateApplyError("testPod", "testContainer", "cpu", ""))
+	assert.NotContains(t, podResource.containerResource("testPod", "testContainer", "cpu").Annotations,
+		executor.AllocationApplyErrorAnnotationKey)
+}
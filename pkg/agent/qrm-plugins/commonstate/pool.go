@@ -30,6 +30,7 @@ import (
 const (
 	PoolNameShare           = "share"
 	PoolNameReclaim         = "reclaim"
+	PoolNameBatch           = "batch"
 	PoolNameDedicated       = "dedicated"
 	PoolNameReserve         = "reserve"
 	PoolNamePrefixIsolation = "isolation"
@@ -75,7 +76,7 @@ func GetPoolType(poolName string) string {
 		return PoolNamePrefixSystem
 	}
 	switch poolName {
-	case PoolNameReclaim, PoolNameDedicated, PoolNameReserve, PoolNameInterrupt, PoolNameFallback:
+	case PoolNameReclaim, PoolNameBatch, PoolNameDedicated, PoolNameReserve, PoolNameInterrupt, PoolNameFallback:
 		return poolName
 	default:
 		return PoolNameShare
@@ -957,6 +957,7 @@ func (p *DynamicPolicy) Allocate(ctx context.Context,
 			"podName", req.PodName,
 			"containerName", req.ContainerName,
 		)
+		util.PublishAllocationEvent(p.name, qosLevel, startTime, req, resp, respErr)
 		return
 	}()
 
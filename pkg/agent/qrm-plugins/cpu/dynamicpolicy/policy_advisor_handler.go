@@ -51,6 +51,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/metrics"
 	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
 	cgroupmgr "github.com/kubewharf/katalyst-core/pkg/util/cgroup/manager"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
 	"github.com/kubewharf/katalyst-core/pkg/util/general"
 	"github.com/kubewharf/katalyst-core/pkg/util/machine"
 	"github.com/kubewharf/katalyst-core/pkg/util/metric"
@@ -473,7 +474,11 @@ func (p *DynamicPolicy) allocateByCPUAdvisor(
 	general.Infof("allocateByCPUAdvisor is called")
 	_ = p.emitter.StoreInt64(util.MetricNameHandleAdvisorRespCalled, 1, metrics.MetricTypeNameRaw)
 	p.Lock()
+	poolsCPUSet := getPoolsCPUSet(p.state.GetPodEntries())
 	defer func() {
+		if err == nil {
+			p.publishPoolResizeEvents(startTime, poolsCPUSet, getPoolsCPUSet(p.state.GetPodEntries()))
+		}
 		p.Unlock()
 		if err != nil {
 			_ = p.emitter.StoreInt64(util.MetricNameHandleAdvisorRespFailed, 1, metrics.MetricTypeNameRaw)
@@ -523,6 +528,57 @@ func (p *DynamicPolicy) allocateByCPUAdvisor(
 	return nil
 }
 
+// publishPoolResizeEvents publishes events for pools whose cpusets are changed by cpu-advisor
+func (p *DynamicPolicy) publishPoolResizeEvents(startTime time.Time, oldPools, newPools map[string]machine.CPUSet) {
+	for poolName, newCPUSet := range newPools {
+		oldCPUSet, ok := oldPools[poolName]
+		if ok && oldCPUSet.Equals(newCPUSet) {
+			continue
+		}
+
+		util.PublishAdvisorAllocationEvent(eventbus.QRMAllocationEvent{
+			BaseEventImpl: eventbus.BaseEventImpl{
+				Time: startTime,
+			},
+			Cost:         time.Since(startTime),
+			PluginName:   p.name,
+			ResourceName: string(v1.ResourceCPU),
+			PoolName:     poolName,
+			Result:       newCPUSet.String(),
+			OldResult:    oldCPUSet.String(),
+		})
+	}
+
+	for poolName, oldCPUSet := range oldPools {
+		if _, ok := newPools[poolName]; ok {
+			continue
+		}
+
+		util.PublishAdvisorAllocationEvent(eventbus.QRMAllocationEvent{
+			BaseEventImpl: eventbus.BaseEventImpl{
+				Time: startTime,
+			},
+			Cost:         time.Since(startTime),
+			PluginName:   p.name,
+			ResourceName: string(v1.ResourceCPU),
+			PoolName:     poolName,
+			OldResult:    oldCPUSet.String(),
+			Reason:       "pool is deleted",
+		})
+	}
+}
+
+// getPoolsCPUSet returns cpusets of all pools in pod entries
+func getPoolsCPUSet(podEntries state.PodEntries) map[string]machine.CPUSet {
+	poolsCPUSet := make(map[string]machine.CPUSet)
+	for poolName, entries := range podEntries {
+		if allocationInfo := entries.GetPoolEntry(); allocationInfo != nil {
+			poolsCPUSet[poolName] = allocationInfo.AllocationResult.Clone()
+		}
+	}
+	return poolsCPUSet
+}
+
 func (p *DynamicPolicy) applyCgroupConfigs(resp *advisorapi.ListAndWatchResponse) error {
 	for _, calculationInfo := range resp.ExtraEntries {
 		if !general.IsPathExists(common.GetAbsCgroupPath(common.DefaultSelectedSubsys, calculationInfo.CgroupPath)) {
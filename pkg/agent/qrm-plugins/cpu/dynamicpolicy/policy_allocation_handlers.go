@@ -1254,7 +1254,13 @@ func (p *DynamicPolicy) applyPoolsAndIsolatedInfo(poolsCPUSet map[string]machine
 					newPodEntries[podUID][containerName].OriginalTopologyAwareAssignments = machine.DeepcopyCPUAssignment(poolEntry.TopologyAwareAssignments)
 				}
 			case apiconsts.PodAnnotationQoSLevelReclaimedCores:
-				poolEntry, err := p.getAllocationPoolEntry(allocationInfo, allocationInfo.OwnerPoolName, newPodEntries)
+				ownerPoolName := allocationInfo.OwnerPoolName
+				// batch pool is generated by cpu advisor, put containers back to reclaim pool if it's gone
+				if ownerPoolName == commonstate.PoolNameBatch && newPodEntries.CheckPoolEmpty(commonstate.PoolNameBatch) {
+					ownerPoolName = commonstate.PoolNameReclaim
+				}
+
+				poolEntry, err := p.getAllocationPoolEntry(allocationInfo, ownerPoolName, newPodEntries)
 				if err != nil {
 					return err
 				}
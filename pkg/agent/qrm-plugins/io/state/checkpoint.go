@@ -0,0 +1,62 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"encoding/json"
+
+	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager"
+	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager/checksum"
+)
+
+var _ checkpointmanager.Checkpoint = &IOPluginCheckpoint{}
+
+type IOPluginCheckpoint struct {
+	PolicyName   string            `json:"policyName"`
+	MachineState DeviceMap         `json:"machineState"`
+	PodEntries   PodEntries        `json:"pod_entries"`
+	Checksum     checksum.Checksum `json:"checksum"`
+}
+
+func NewIOPluginCheckpoint() *IOPluginCheckpoint {
+	return &IOPluginCheckpoint{
+		PodEntries:   make(PodEntries),
+		MachineState: make(DeviceMap),
+	}
+}
+
+// MarshalCheckpoint returns marshaled checkpoint
+func (cp *IOPluginCheckpoint) MarshalCheckpoint() ([]byte, error) {
+	// make sure checksum wasn't set before, so it doesn't affect output checksum
+	cp.Checksum = 0
+	cp.Checksum = checksum.New(cp)
+	return json.Marshal(*cp)
+}
+
+// UnmarshalCheckpoint tries to unmarshal passed bytes to checkpoint
+func (cp *IOPluginCheckpoint) UnmarshalCheckpoint(blob []byte) error {
+	return json.Unmarshal(blob, cp)
+}
+
+// VerifyChecksum verifies that current checksum of checkpoint is valid
+func (cp *IOPluginCheckpoint) VerifyChecksum() error {
+	ck := cp.Checksum
+	cp.Checksum = 0
+	err := ck.Verify(cp)
+	cp.Checksum = ck
+	return err
+}
@@ -0,0 +1,292 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"encoding/json"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+// IOQuantity is the throttling quantity of a block device,
+// and zero value of each field means it is not limited.
+type IOQuantity struct {
+	ReadBps   uint64 `json:"read_bps"`
+	WriteBps  uint64 `json:"write_bps"`
+	ReadIOPS  uint64 `json:"read_iops"`
+	WriteIOPS uint64 `json:"write_iops"`
+}
+
+// DeviceInfo describes a block device managed by io plugin
+type DeviceInfo struct {
+	Name     string     `json:"name"`
+	DeviceID string     `json:"device_id"` // major:minor of the block device
+	NumaNode int        `json:"numa_node"`
+	Capacity IOQuantity `json:"capacity"`
+}
+
+type AllocationInfo struct {
+	commonstate.AllocationMeta `json:",inline"`
+
+	DeviceName string     `json:"device_name"` // we do not support cross-device throttling
+	DeviceID   string     `json:"device_id"`
+	Quantity   IOQuantity `json:"quantity"`
+	// PodQuantityCharged is true if the pod-level quantity in annotation is charged to this container,
+	// and it makes sure that the pod-level quantity is charged only once for each pod
+	PodQuantityCharged bool `json:"pod_quantity_charged,omitempty"`
+}
+
+type (
+	ContainerEntries map[string]*AllocationInfo  // Keyed by container name
+	PodEntries       map[string]ContainerEntries // Keyed by pod UID
+)
+
+// DeviceState indicates the status of a block device, including the capacity/allocation
+type DeviceState struct {
+	DeviceID    string     `json:"device_id"`
+	NumaNode    int        `json:"numa_node"`
+	Capacity    IOQuantity `json:"capacity"`
+	Allocatable IOQuantity `json:"allocatable"`
+	Allocated   IOQuantity `json:"allocated"`
+	Free        IOQuantity `json:"free"`
+	PodEntries  PodEntries `json:"pod_entries"`
+}
+
+type DeviceMap map[string]*DeviceState // keyed by device name i.e. sda
+
+// IsZero returns true if none of the dimensions is limited
+func (q IOQuantity) IsZero() bool {
+	return q.ReadBps == 0 && q.WriteBps == 0 && q.ReadIOPS == 0 && q.WriteIOPS == 0
+}
+
+// Add returns the sum of the two quantities
+func (q IOQuantity) Add(other IOQuantity) IOQuantity {
+	return IOQuantity{
+		ReadBps:   q.ReadBps + other.ReadBps,
+		WriteBps:  q.WriteBps + other.WriteBps,
+		ReadIOPS:  q.ReadIOPS + other.ReadIOPS,
+		WriteIOPS: q.WriteIOPS + other.WriteIOPS,
+	}
+}
+
+// Sub returns the difference of the two quantities, and each dimension is floored to zero
+func (q IOQuantity) Sub(other IOQuantity) IOQuantity {
+	sub := func(a, b uint64) uint64 {
+		if a < b {
+			return 0
+		}
+		return a - b
+	}
+
+	return IOQuantity{
+		ReadBps:   sub(q.ReadBps, other.ReadBps),
+		WriteBps:  sub(q.WriteBps, other.WriteBps),
+		ReadIOPS:  sub(q.ReadIOPS, other.ReadIOPS),
+		WriteIOPS: sub(q.WriteIOPS, other.WriteIOPS),
+	}
+}
+
+// Satisfy returns true if q, as the free quantity of a device, is enough for req;
+// dimensions with zero capacity are considered as unmanaged and always satisfied.
+func (q IOQuantity) Satisfy(capacity, req IOQuantity) bool {
+	satisfy := func(free, limit, request uint64) bool {
+		return limit == 0 || free >= request
+	}
+
+	return satisfy(q.ReadBps, capacity.ReadBps, req.ReadBps) &&
+		satisfy(q.WriteBps, capacity.WriteBps, req.WriteBps) &&
+		satisfy(q.ReadIOPS, capacity.ReadIOPS, req.ReadIOPS) &&
+		satisfy(q.WriteIOPS, capacity.WriteIOPS, req.WriteIOPS)
+}
+
+// Bandwidth returns the smaller one of the read and write bandwidth,
+// and it's used to report the disk bandwidth resource as a scalar.
+func (q IOQuantity) Bandwidth() uint64 {
+	if q.ReadBps == 0 {
+		return q.WriteBps
+	} else if q.WriteBps == 0 {
+		return q.ReadBps
+	}
+	return general.MinUInt64(q.ReadBps, q.WriteBps)
+}
+
+// IOPS returns the smaller one of the read and write iops,
+// and it's used to report the disk iops resource as a scalar.
+func (q IOQuantity) IOPS() uint64 {
+	if q.ReadIOPS == 0 {
+		return q.WriteIOPS
+	} else if q.WriteIOPS == 0 {
+		return q.ReadIOPS
+	}
+	return general.MinUInt64(q.ReadIOPS, q.WriteIOPS)
+}
+
+func (ai *AllocationInfo) String() string {
+	if ai == nil {
+		return ""
+	}
+
+	contentBytes, err := json.Marshal(ai)
+	if err != nil {
+		general.LoggerWithPrefix("AllocationInfo.String", general.LoggingPKGFull).Errorf("marshal AllocationInfo failed with error: %v", err)
+		return ""
+	}
+	return string(contentBytes)
+}
+
+func (ai *AllocationInfo) Clone() *AllocationInfo {
+	if ai == nil {
+		return nil
+	}
+
+	return &AllocationInfo{
+		AllocationMeta:     *ai.AllocationMeta.Clone(),
+		DeviceName:         ai.DeviceName,
+		DeviceID:           ai.DeviceID,
+		Quantity:           ai.Quantity,
+		PodQuantityCharged: ai.PodQuantityCharged,
+	}
+}
+
+func (pe PodEntries) Clone() PodEntries {
+	clone := make(PodEntries)
+	for podUID, containerEntries := range pe {
+		clone[podUID] = make(ContainerEntries)
+		for containerName, allocationInfo := range containerEntries {
+			clone[podUID][containerName] = allocationInfo.Clone()
+		}
+	}
+	return clone
+}
+
+func (pe PodEntries) String() string {
+	if pe == nil {
+		return ""
+	}
+
+	contentBytes, err := json.Marshal(pe)
+	if err != nil {
+		general.LoggerWithPrefix("PodEntries.String", general.LoggingPKGFull).Errorf("marshal PodEntries failed with error: %v", err)
+		return ""
+	}
+	return string(contentBytes)
+}
+
+func (ds *DeviceState) String() string {
+	if ds == nil {
+		return ""
+	}
+
+	contentBytes, err := json.Marshal(ds)
+	if err != nil {
+		general.LoggerWithPrefix("DeviceState.String", general.LoggingPKGFull).Errorf("marshal DeviceState failed with error: %v", err)
+		return ""
+	}
+	return string(contentBytes)
+}
+
+func (ds *DeviceState) Clone() *DeviceState {
+	if ds == nil {
+		return nil
+	}
+
+	return &DeviceState{
+		DeviceID:    ds.DeviceID,
+		NumaNode:    ds.NumaNode,
+		Capacity:    ds.Capacity,
+		Allocatable: ds.Allocatable,
+		Allocated:   ds.Allocated,
+		Free:        ds.Free,
+		PodEntries:  ds.PodEntries.Clone(),
+	}
+}
+
+// SetAllocationInfo adds a new AllocationInfo (for pod/container pairs) into the given DeviceState
+func (ds *DeviceState) SetAllocationInfo(podUID string, containerName string, allocationInfo *AllocationInfo) {
+	if ds == nil {
+		return
+	}
+
+	if allocationInfo == nil {
+		general.LoggerWithPrefix("DeviceState.SetAllocationInfo", general.LoggingPKGFull).Errorf("passed allocationInfo is nil")
+		return
+	}
+
+	if ds.PodEntries == nil {
+		ds.PodEntries = make(PodEntries)
+	}
+
+	if _, ok := ds.PodEntries[podUID]; !ok {
+		ds.PodEntries[podUID] = make(ContainerEntries)
+	}
+
+	ds.PodEntries[podUID][containerName] = allocationInfo.Clone()
+}
+
+func (dm DeviceMap) Clone() DeviceMap {
+	clone := make(DeviceMap)
+	for devName, ds := range dm {
+		clone[devName] = ds.Clone()
+	}
+	return clone
+}
+
+func (dm DeviceMap) String() string {
+	if dm == nil {
+		return ""
+	}
+
+	contentBytes, err := json.Marshal(dm)
+	if err != nil {
+		general.LoggerWithPrefix("DeviceMap.String", general.LoggingPKGFull).Errorf("marshal DeviceMap failed with error: %v", err)
+		return ""
+	}
+	return string(contentBytes)
+}
+
+// reader is used to get information from local states
+type reader interface {
+	GetMachineState() DeviceMap
+	GetPodEntries() PodEntries
+	GetAllocationInfo(podUID, containerName string) *AllocationInfo
+}
+
+// writer is used to store information into local states,
+// and it also provides functionality to maintain the local files
+type writer interface {
+	SetMachineState(deviceMap DeviceMap, persist bool)
+	SetPodEntries(podEntries PodEntries, persist bool)
+	SetAllocationInfo(podUID, containerName string, allocationInfo *AllocationInfo, persist bool)
+
+	Delete(podUID, containerName string, persist bool)
+	ClearState()
+	StoreState() error
+}
+
+// ReadonlyState interface only provides methods for tracking pod assignments
+type ReadonlyState interface {
+	reader
+
+	GetDevices() []DeviceInfo
+}
+
+// State interface provides methods for tracking and setting pod assignments
+type State interface {
+	writer
+	ReadonlyState
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/qrm/statedirectory"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/customcheckpointmanager"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/state"
+)
+
+const (
+	metricMetaCacheStoreStateDuration = "metacache_store_state_duration"
+)
+
+var (
+	_          State          = &stateCheckpoint{}
+	_          state.Storable = &stateCheckpoint{}
+	generalLog general.Logger = general.LoggerWithPrefix("io_plugin", general.LoggingPKGFull)
+)
+
+// stateCheckpoint is an in-memory implementation of State;
+// everytime we want to read or write states, those requests will always
+// go to in-memory State, and then go to disk State, i.e. in write-back mode
+type stateCheckpoint struct {
+	sync.RWMutex
+	cache             *ioPluginState
+	policyName        string
+	checkpointManager checkpointmanager.CheckpointManager
+	checkpointName    string
+	// when we add new properties to checkpoint,
+	// it will cause checkpoint corruption and we should skip it
+	skipStateCorruption bool
+	emitter             metrics.MetricEmitter
+	devices             []DeviceInfo
+}
+
+func NewCheckpointState(
+	stateDirectoryConfig *statedirectory.StateDirectoryConfiguration,
+	checkpointName, policyName string, devices []DeviceInfo,
+	skipStateCorruption bool, emitter metrics.MetricEmitter,
+) (State, error) {
+	currentStateDir, otherStateDir := stateDirectoryConfig.GetCurrentAndPreviousStateFileDirectory()
+
+	sc := &stateCheckpoint{
+		cache:               NewIOPluginState(devices),
+		policyName:          policyName,
+		checkpointName:      checkpointName,
+		skipStateCorruption: skipStateCorruption,
+		emitter:             emitter,
+		devices:             devices,
+	}
+
+	cm, err := customcheckpointmanager.NewCustomCheckpointManager(currentStateDir, otherStateDir, checkpointName,
+		"io_plugin", sc, skipStateCorruption)
+	if err != nil {
+		return nil, fmt.Errorf("[io_plugin] failed to initialize custom checkpoint manager: %v", err)
+	}
+	sc.checkpointManager = cm
+
+	return sc, nil
+}
+
+// RestoreState implements Storable interface and restores the cache from checkpoint and returns if the state has changed.
+func (sc *stateCheckpoint) RestoreState(cp checkpointmanager.Checkpoint) (bool, error) {
+	checkpoint, ok := cp.(*IOPluginCheckpoint)
+	if !ok {
+		return false, fmt.Errorf("checkpoint type assertion failed, expect *IOPluginCheckpoint, got %T", cp)
+	}
+
+	if sc.policyName != checkpoint.PolicyName && !sc.skipStateCorruption {
+		return false, fmt.Errorf("[io_plugin] configured policy %q differs from state checkpoint policy %q", sc.policyName, checkpoint.PolicyName)
+	}
+
+	generatedIOState := GenerateMachineStateFromPodEntries(sc.devices, checkpoint.PodEntries)
+
+	sc.cache.SetMachineState(generatedIOState)
+	sc.cache.SetPodEntries(checkpoint.PodEntries)
+
+	if !reflect.DeepEqual(generatedIOState, checkpoint.MachineState) {
+		generalLog.Warningf("machine state changed: "+
+			"generatedIOState: %s; checkpointMachineState: %s",
+			generatedIOState.String(), checkpoint.MachineState.String())
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (sc *stateCheckpoint) storeState() error {
+	startTime := time.Now()
+	general.InfoS("called")
+	defer func() {
+		elapsed := time.Since(startTime)
+		general.InfoS("finished", "duration", elapsed)
+		_ = sc.emitter.StoreFloat64(metricMetaCacheStoreStateDuration, float64(elapsed/time.Millisecond), metrics.MetricTypeNameRaw)
+	}()
+	checkpoint := sc.InitNewCheckpoint(false)
+
+	err := sc.checkpointManager.CreateCheckpoint(sc.checkpointName, checkpoint)
+	if err != nil {
+		generalLog.ErrorS(err, "could not save checkpoint")
+		return err
+	}
+	return nil
+}
+
+// InitNewCheckpoint implements Storable interface and initializes an empty or non-empty new checkpoint.
+func (sc *stateCheckpoint) InitNewCheckpoint(empty bool) checkpointmanager.Checkpoint {
+	checkpoint := NewIOPluginCheckpoint()
+	if empty {
+		return checkpoint
+	}
+	checkpoint.PolicyName = sc.policyName
+	checkpoint.MachineState = sc.cache.GetMachineState()
+	checkpoint.PodEntries = sc.cache.GetPodEntries()
+	return checkpoint
+}
+
+func (sc *stateCheckpoint) GetDevices() []DeviceInfo {
+	sc.RLock()
+	defer sc.RUnlock()
+
+	return sc.cache.GetDevices()
+}
+
+func (sc *stateCheckpoint) GetMachineState() DeviceMap {
+	sc.RLock()
+	defer sc.RUnlock()
+
+	return sc.cache.GetMachineState()
+}
+
+func (sc *stateCheckpoint) GetAllocationInfo(podUID, containerName string) *AllocationInfo {
+	sc.RLock()
+	defer sc.RUnlock()
+
+	return sc.cache.GetAllocationInfo(podUID, containerName)
+}
+
+func (sc *stateCheckpoint) GetPodEntries() PodEntries {
+	sc.RLock()
+	defer sc.RUnlock()
+
+	return sc.cache.GetPodEntries()
+}
+
+func (sc *stateCheckpoint) SetMachineState(deviceMap DeviceMap, persist bool) {
+	sc.Lock()
+	defer sc.Unlock()
+
+	sc.cache.SetMachineState(deviceMap)
+	if persist {
+		err := sc.storeState()
+		if err != nil {
+			generalLog.ErrorS(err, "store machineState to checkpoint error")
+		}
+	}
+}
+
+func (sc *stateCheckpoint) SetAllocationInfo(
+	podUID, containerName string, allocationInfo *AllocationInfo, persist bool,
+) {
+	sc.Lock()
+	defer sc.Unlock()
+
+	sc.cache.SetAllocationInfo(podUID, containerName, allocationInfo)
+	if persist {
+		err := sc.storeState()
+		if err != nil {
+			generalLog.ErrorS(err, "store allocationInfo to checkpoint error")
+		}
+	}
+}
+
+func (sc *stateCheckpoint) SetPodEntries(podEntries PodEntries, persist bool) {
+	sc.Lock()
+	defer sc.Unlock()
+
+	sc.cache.SetPodEntries(podEntries)
+	if persist {
+		err := sc.storeState()
+		if err != nil {
+			generalLog.ErrorS(err, "store pod entries to checkpoint error")
+		}
+	}
+}
+
+func (sc *stateCheckpoint) Delete(podUID, containerName string, persist bool) {
+	sc.Lock()
+	defer sc.Unlock()
+
+	sc.cache.Delete(podUID, containerName)
+	if persist {
+		err := sc.storeState()
+		if err != nil {
+			generalLog.ErrorS(err, "store state after delete operation to checkpoint error")
+		}
+	}
+}
+
+func (sc *stateCheckpoint) ClearState() {
+	sc.Lock()
+	defer sc.Unlock()
+
+	sc.cache.ClearState()
+	err := sc.storeState()
+	if err != nil {
+		generalLog.ErrorS(err, "store state after clear operation to checkpoint error")
+	}
+}
+
+func (sc *stateCheckpoint) StoreState() error {
+	sc.Lock()
+	defer sc.Unlock()
+	return sc.storeState()
+}
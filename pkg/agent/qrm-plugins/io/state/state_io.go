@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"sync"
+)
+
+// ioPluginState is an in-memory implementation of State;
+// everytime we want to read or write states, those requests will always
+// go to in-memory State, and then go to disk State, i.e. in write-back mode
+type ioPluginState struct {
+	sync.RWMutex
+
+	devices []DeviceInfo
+
+	machineState DeviceMap
+	podEntries   PodEntries
+}
+
+func NewIOPluginState(devices []DeviceInfo) *ioPluginState {
+	generalLog.InfoS("initializing new io plugin in-memory state store")
+
+	return &ioPluginState{
+		devices:      devices,
+		machineState: GenerateMachineState(devices),
+		podEntries:   make(PodEntries),
+	}
+}
+
+func (s *ioPluginState) GetDevices() []DeviceInfo {
+	s.RLock()
+	defer s.RUnlock()
+
+	clonedDevices := make([]DeviceInfo, len(s.devices))
+	copy(clonedDevices, s.devices)
+
+	return clonedDevices
+}
+
+func (s *ioPluginState) GetMachineState() DeviceMap {
+	s.RLock()
+	defer s.RUnlock()
+
+	return s.machineState.Clone()
+}
+
+func (s *ioPluginState) GetAllocationInfo(podUID, containerName string) *AllocationInfo {
+	s.RLock()
+	defer s.RUnlock()
+
+	if res, ok := s.podEntries[podUID][containerName]; ok {
+		return res.Clone()
+	}
+	return nil
+}
+
+func (s *ioPluginState) GetPodEntries() PodEntries {
+	s.RLock()
+	defer s.RUnlock()
+
+	return s.podEntries.Clone()
+}
+
+func (s *ioPluginState) SetMachineState(deviceMap DeviceMap) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.machineState = deviceMap.Clone()
+	generalLog.InfoS("updated io plugin machine state",
+		"DeviceMap", deviceMap.String())
+}
+
+func (s *ioPluginState) SetAllocationInfo(podUID, containerName string, allocationInfo *AllocationInfo) {
+	s.Lock()
+	defer s.Unlock()
+
+	if _, ok := s.podEntries[podUID]; !ok {
+		s.podEntries[podUID] = make(ContainerEntries)
+	}
+
+	s.podEntries[podUID][containerName] = allocationInfo.Clone()
+	generalLog.InfoS("updated io plugin pod resource entries",
+		"podUID", podUID,
+		"containerName", containerName,
+		"allocationInfo", allocationInfo.String())
+}
+
+func (s *ioPluginState) SetPodEntries(podEntries PodEntries) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.podEntries = podEntries.Clone()
+	generalLog.InfoS("updated io plugin pod resource entries",
+		"podEntries", podEntries.String())
+}
+
+func (s *ioPluginState) Delete(podUID, containerName string) {
+	s.Lock()
+	defer s.Unlock()
+
+	if _, ok := s.podEntries[podUID]; !ok {
+		return
+	}
+
+	delete(s.podEntries[podUID], containerName)
+	if len(s.podEntries[podUID]) == 0 {
+		delete(s.podEntries, podUID)
+	}
+	generalLog.InfoS("deleted container entry", "podUID", podUID, "containerName", containerName)
+}
+
+func (s *ioPluginState) ClearState() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.machineState = GenerateMachineState(s.devices)
+	s.podEntries = make(PodEntries)
+
+	generalLog.InfoS("cleared state")
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/qrm/statedirectory"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+func TestCheckpointStateRestore(t *testing.T) {
+	t.Parallel()
+
+	devices := []DeviceInfo{
+		{Name: "sda", DeviceID: "8:0", Capacity: IOQuantity{ReadBps: 1000, WriteBps: 1000}},
+	}
+	stateDirectoryConfig := &statedirectory.StateDirectoryConfiguration{
+		StateFileDirectory: t.TempDir(),
+	}
+
+	st, err := NewCheckpointState(stateDirectoryConfig, "test_io_state", "static", devices, false, metrics.DummyMetrics{})
+	require.NoError(t, err)
+
+	allocationInfo := &AllocationInfo{
+		DeviceName: "sda",
+		DeviceID:   "8:0",
+		Quantity:   IOQuantity{ReadBps: 100, WriteBps: 300},
+	}
+	allocationInfo.PodUid = "pod1"
+	allocationInfo.ContainerName = "c1"
+	st.SetAllocationInfo("pod1", "c1", allocationInfo, true)
+
+	machineState := GenerateMachineStateFromPodEntries(devices, st.GetPodEntries())
+	st.SetMachineState(machineState, true)
+	assert.Equal(t, IOQuantity{ReadBps: 100, WriteBps: 300}, machineState["sda"].Allocated)
+	assert.Equal(t, IOQuantity{ReadBps: 900, WriteBps: 700}, machineState["sda"].Free)
+
+	restored, err := NewCheckpointState(stateDirectoryConfig, "test_io_state", "static", devices, false, metrics.DummyMetrics{})
+	require.NoError(t, err)
+	assert.Equal(t, st.GetPodEntries(), restored.GetPodEntries())
+	assert.Equal(t, st.GetMachineState(), restored.GetMachineState())
+
+	restored.Delete("pod1", "c1", true)
+	assert.Nil(t, restored.GetAllocationInfo("pod1", "c1"))
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+// GenerateMachineState returns DeviceMap based on the managed block devices
+func GenerateMachineState(devices []DeviceInfo) DeviceMap {
+	defaultMachineState := make(DeviceMap)
+	for _, device := range devices {
+		defaultMachineState[device.Name] = &DeviceState{
+			DeviceID:    device.DeviceID,
+			NumaNode:    device.NumaNode,
+			Capacity:    device.Capacity,
+			Allocatable: device.Capacity,
+			Free:        device.Capacity,
+			PodEntries:  make(PodEntries),
+		}
+	}
+	return defaultMachineState
+}
+
+// GenerateMachineStateFromPodEntries returns DeviceMap based on
+// the managed block devices along with existed pod entries
+func GenerateMachineStateFromPodEntries(devices []DeviceInfo, podEntries PodEntries) DeviceMap {
+	machineState := GenerateMachineState(devices)
+
+	for devName, deviceState := range machineState {
+		var allocated IOQuantity
+		for podUID, containerEntries := range podEntries {
+			for containerName, allocationInfo := range containerEntries {
+				if containerName != "" && allocationInfo != nil && allocationInfo.DeviceName == devName {
+					allocated = allocated.Add(allocationInfo.Quantity)
+					deviceState.SetAllocationInfo(podUID, containerName, allocationInfo)
+				}
+			}
+		}
+
+		deviceState.Allocated = allocated
+		if !deviceState.Allocatable.Satisfy(deviceState.Capacity, allocated) {
+			general.LoggerWithPrefix("GenerateMachineStateFromPodEntries", general.LoggingPKGFull).
+				Warningf("invalid allocated io quantity: %+v on device: %s with allocatable quantity: %+v",
+					allocated, devName, deviceState.Allocatable)
+		}
+		deviceState.Free = deviceState.Allocatable.Sub(allocated)
+		machineState[devName] = deviceState
+	}
+
+	return machineState
+}
@@ -22,27 +22,48 @@ import (
 	"sync"
 	"time"
 
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	pluginapi "k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
+	maputil "k8s.io/kubernetes/pkg/util/maps"
 
 	"github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-api/pkg/plugins/skeleton"
 	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/agent"
 	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/agent/qrm"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/io/handlers/dirtymem"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/io/handlers/iocost"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/io/handlers/ioweight"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/io/state"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/util"
 	"github.com/kubewharf/katalyst-core/pkg/agent/utilcomponent/periodicalhandler"
 	"github.com/kubewharf/katalyst-core/pkg/config"
+	dynamicconfig "github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic"
 	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	coreconsts "github.com/kubewharf/katalyst-core/pkg/consts"
 	"github.com/kubewharf/katalyst-core/pkg/metaserver"
 	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
+	cgroupmgr "github.com/kubewharf/katalyst-core/pkg/util/cgroup/manager"
 	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/metric"
 )
 
 const (
 	// IOResourcePluginPolicyNameStatic is the policy name of static io resource plugin
 	IOResourcePluginPolicyNameStatic = string(consts.ResourcePluginPolicyNameStatic)
+
+	IOPluginStateFileName = "io_plugin_state"
+
+	clearResidualStateHandlerName = "ClearResidualIOState"
+	applyIOThrottleHandlerName    = "ApplyIOThrottle"
+
+	stateCheckPeriod   = 30 * time.Second
+	maxResidualTime    = 5 * time.Minute
+	applyIOThrottleGap = 5 * time.Second
+
+	metricNameApplyIOThrottleFailed = "apply_io_throttle_failed"
 )
 
 // StaticPolicy is the static io policy
@@ -60,6 +81,18 @@ type StaticPolicy struct {
 
 	enableSettingWBT      bool
 	enableSettingIOWeight bool
+
+	// state is only initialized when io throttling is enabled,
+	// and the plugin won't allocate any resource if it's nil.
+	state                   state.State
+	defaultDevice           string
+	podAnnotationKeptKeys   []string
+	podLabelKeptKeys        []string
+	residualHitMap          map[string]int64
+	applyIOThrottleFunc     func(podUID, containerID, devID string, data *common.IOThrottleData) error
+	applyPodIOThrottleFunc  func(podUID, devID string, data *common.IOThrottleData) error
+	isContainerCgroupExists func(podUID, containerID string) (bool, error)
+	isPodCgroupExists       func(podUID string) bool
 }
 
 // NewStaticPolicy returns a static io policy
@@ -72,20 +105,57 @@ func NewStaticPolicy(agentCtx *agent.GenericContext, conf *config.Configuration,
 	})
 
 	policyImplement := &StaticPolicy{
-		emitter:               wrappedEmitter,
-		metaServer:            agentCtx.MetaServer,
-		agentCtx:              agentCtx,
-		stopCh:                make(chan struct{}),
-		name:                  fmt.Sprintf("%s_%s", agentName, IOResourcePluginPolicyNameStatic),
-		qosConfig:             conf.QoSConfiguration,
-		enableSettingWBT:      conf.EnableSettingWBT,
-		enableSettingIOWeight: conf.EnableSettingIOWeight,
+		emitter:                 wrappedEmitter,
+		metaServer:              agentCtx.MetaServer,
+		agentCtx:                agentCtx,
+		stopCh:                  make(chan struct{}),
+		name:                    fmt.Sprintf("%s_%s", agentName, IOResourcePluginPolicyNameStatic),
+		qosConfig:               conf.QoSConfiguration,
+		enableSettingWBT:        conf.EnableSettingWBT,
+		enableSettingIOWeight:   conf.EnableSettingIOWeight,
+		podAnnotationKeptKeys:   conf.PodAnnotationKeptKeys,
+		podLabelKeptKeys:        conf.PodLabelKeptKeys,
+		residualHitMap:          make(map[string]int64),
+		applyIOThrottleFunc:     cgroupmgr.ApplyIOThrottleForContainer,
+		applyPodIOThrottleFunc:  cgroupmgr.ApplyIOThrottleForPod,
+		isContainerCgroupExists: common.IsContainerCgroupExist,
+		isPodCgroupExists:       common.IsPodCgroupExist,
+	}
+
+	if !conf.EnableIOThrottle {
+		// there is no resource needed to be topology-aware and synchronously allocated in this plugin,
+		// so not to wrap the plugin by RegistrationPluginWrapper and it won't be registered to QRM framework.
+		return true, &agent.PluginWrapper{GenericPlugin: policyImplement}, nil
+	}
+
+	devices, err := loadIOThrottleDevices(conf.IOThrottleDeviceConfigFile)
+	if err != nil {
+		return false, agent.ComponentStub{}, fmt.Errorf("loadIOThrottleDevices failed with error: %v", err)
+	} else if len(devices) == 0 {
+		return false, agent.ComponentStub{}, fmt.Errorf("no device configured in %s", conf.IOThrottleDeviceConfigFile)
+	}
+
+	policyImplement.defaultDevice = conf.IOThrottleDefaultDevice
+	if policyImplement.defaultDevice == "" {
+		policyImplement.defaultDevice = devices[0].Name
 	}
 
-	// todo: currently there is no resource needed to be topology-aware and synchronously allocated in this plugin,
-	// so not to wrap the plugin by RegistrationPluginWrapper and it won't be registered to QRM framework temporarily.
+	stateImpl, err := state.NewCheckpointState(conf.StateDirectoryConfiguration, IOPluginStateFileName,
+		IOResourcePluginPolicyNameStatic, devices, conf.SkipIOStateCorruption, wrappedEmitter)
+	if err != nil {
+		return false, agent.ComponentStub{}, fmt.Errorf("NewCheckpointState failed with error: %v", err)
+	}
+	policyImplement.state = stateImpl
+
+	pluginWrapper, err := skeleton.NewRegistrationPluginWrapper(policyImplement, conf.QRMPluginSocketDirs,
+		func(key string, value int64) {
+			_ = wrappedEmitter.StoreInt64(key, value, metrics.MetricTypeNameRaw)
+		})
+	if err != nil {
+		return false, agent.ComponentStub{}, fmt.Errorf("static policy new plugin wrapper failed with error: %v", err)
+	}
 
-	return true, &agent.PluginWrapper{GenericPlugin: policyImplement}, nil
+	return true, &agent.PluginWrapper{GenericPlugin: pluginWrapper}, nil
 }
 
 // Start starts this plugin
@@ -141,6 +211,20 @@ func (p *StaticPolicy) Start() (err error) {
 		general.Infof("setIOCost failed, err=%v", err)
 	}
 
+	if p.state != nil {
+		err = periodicalhandler.RegisterPeriodicalHandler(qrm.QRMIOPluginPeriodicalHandlerGroupName,
+			clearResidualStateHandlerName, p.clearResidualState, stateCheckPeriod)
+		if err != nil {
+			general.Errorf("start %v failed, err: %v", clearResidualStateHandlerName, err)
+		}
+
+		err = periodicalhandler.RegisterPeriodicalHandler(qrm.QRMIOPluginPeriodicalHandlerGroupName,
+			applyIOThrottleHandlerName, p.applyIOThrottle, applyIOThrottleGap)
+		if err != nil {
+			general.Errorf("start %v failed, err: %v", applyIOThrottleHandlerName, err)
+		}
+	}
+
 	go wait.Until(func() {
 		periodicalhandler.ReadyToStartHandlersByGroup(qrm.QRMIOPluginPeriodicalHandlerGroupName)
 	}, 5*time.Second, p.stopCh)
@@ -174,8 +258,10 @@ func (p *StaticPolicy) Name() string {
 
 // ResourceName returns resource names managed by this plugin
 func (p *StaticPolicy) ResourceName() string {
-	// todo: return correct value when there is resource needed to be topology-aware and synchronously allocated in this plugin
-	return ""
+	if p.state == nil {
+		return ""
+	}
+	return coreconsts.ResourceDiskBandwidth
 }
 
 // GetTopologyHints returns hints of corresponding resources
@@ -186,6 +272,7 @@ func (p *StaticPolicy) GetTopologyHints(_ context.Context,
 		return nil, fmt.Errorf("GetTopologyHints got nil req")
 	}
 
+	// disk bandwidth has no numa preference
 	return util.PackResourceHintsResponse(req, p.ResourceName(), nil)
 }
 
@@ -203,6 +290,18 @@ func (p *StaticPolicy) RemovePod(_ context.Context,
 		return nil, fmt.Errorf("RemovePod got nil req")
 	}
 
+	if p.state == nil {
+		return &pluginapi.RemovePodResponse{}, nil
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	if err := p.removePod(req.PodUid); err != nil {
+		general.ErrorS(err, "remove pod failed with error", "podUID", req.PodUid)
+		return nil, err
+	}
+
 	return &pluginapi.RemovePodResponse{}, nil
 }
 
@@ -210,21 +309,140 @@ func (p *StaticPolicy) RemovePod(_ context.Context,
 func (p *StaticPolicy) GetResourcesAllocation(_ context.Context,
 	_ *pluginapi.GetResourcesAllocationRequest,
 ) (*pluginapi.GetResourcesAllocationResponse, error) {
-	return &pluginapi.GetResourcesAllocationResponse{}, nil
+	if p.state == nil {
+		return &pluginapi.GetResourcesAllocationResponse{}, nil
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	podResources := make(map[string]*pluginapi.ContainerResources)
+	for podUID, containerEntries := range p.state.GetPodEntries() {
+		for containerName, allocationInfo := range containerEntries {
+			if allocationInfo == nil {
+				continue
+			}
+
+			if podResources[podUID] == nil {
+				podResources[podUID] = &pluginapi.ContainerResources{}
+			}
+
+			if podResources[podUID].ContainerResources == nil {
+				podResources[podUID].ContainerResources = make(map[string]*pluginapi.ResourceAllocation)
+			}
+
+			podResources[podUID].ContainerResources[containerName] = &pluginapi.ResourceAllocation{
+				ResourceAllocation: packResourceAllocationInfo(allocationInfo),
+			}
+		}
+	}
+
+	return &pluginapi.GetResourcesAllocationResponse{
+		PodResources: podResources,
+	}, nil
 }
 
 // GetTopologyAwareResources returns allocation results of corresponding resources as topology aware format
 func (p *StaticPolicy) GetTopologyAwareResources(_ context.Context,
-	_ *pluginapi.GetTopologyAwareResourcesRequest,
+	req *pluginapi.GetTopologyAwareResourcesRequest,
 ) (*pluginapi.GetTopologyAwareResourcesResponse, error) {
-	return &pluginapi.GetTopologyAwareResourcesResponse{}, nil
+	if p.state == nil {
+		return &pluginapi.GetTopologyAwareResourcesResponse{}, nil
+	} else if req == nil {
+		return nil, fmt.Errorf("GetTopologyAwareResources got nil req")
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	allocationInfo := p.state.GetAllocationInfo(req.PodUid, req.ContainerName)
+	if allocationInfo == nil {
+		return &pluginapi.GetTopologyAwareResourcesResponse{}, nil
+	}
+
+	deviceState := p.state.GetMachineState()[allocationInfo.DeviceName]
+	if deviceState == nil {
+		return nil, fmt.Errorf("failed to find device %s for pod %s, container %s",
+			allocationInfo.DeviceName, req.PodUid, req.ContainerName)
+	}
+
+	allocatedResources := make(map[string]*pluginapi.TopologyAwareResource, len(scalarResourceQuantityFuncs))
+	for resourceName, quantityFunc := range scalarResourceQuantityFuncs {
+		quantity := float64(quantityFunc(allocationInfo.Quantity))
+		topologyAwareQuantityList := []*pluginapi.TopologyAwareQuantity{
+			generateDeviceTopologyAwareQuantity(allocationInfo.DeviceName, deviceState, quantity),
+		}
+
+		allocatedResources[resourceName] = &pluginapi.TopologyAwareResource{
+			IsNodeResource:                    true,
+			IsScalarResource:                  true,
+			AggregatedQuantity:                quantity,
+			OriginalAggregatedQuantity:        quantity,
+			TopologyAwareQuantityList:         topologyAwareQuantityList,
+			OriginalTopologyAwareQuantityList: topologyAwareQuantityList,
+		}
+	}
+
+	return &pluginapi.GetTopologyAwareResourcesResponse{
+		PodUid:       allocationInfo.PodUid,
+		PodName:      allocationInfo.PodName,
+		PodNamespace: allocationInfo.PodNamespace,
+		ContainerTopologyAwareResources: &pluginapi.ContainerTopologyAwareResources{
+			ContainerName:      allocationInfo.ContainerName,
+			AllocatedResources: allocatedResources,
+		},
+	}, nil
 }
 
 // GetTopologyAwareAllocatableResources returns corresponding allocatable resources as topology aware format
 func (p *StaticPolicy) GetTopologyAwareAllocatableResources(_ context.Context,
 	_ *pluginapi.GetTopologyAwareAllocatableResourcesRequest,
 ) (*pluginapi.GetTopologyAwareAllocatableResourcesResponse, error) {
-	return &pluginapi.GetTopologyAwareAllocatableResourcesResponse{}, nil
+	if p.state == nil {
+		return &pluginapi.GetTopologyAwareAllocatableResourcesResponse{}, nil
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	machineState := p.state.GetMachineState()
+	devices := p.state.GetDevices()
+
+	allocatableResources := make(map[string]*pluginapi.AllocatableTopologyAwareResource, len(scalarResourceQuantityFuncs))
+	for resourceName, quantityFunc := range scalarResourceQuantityFuncs {
+		topologyAwareAllocatableQuantityList := make([]*pluginapi.TopologyAwareQuantity, 0, len(devices))
+		topologyAwareCapacityQuantityList := make([]*pluginapi.TopologyAwareQuantity, 0, len(devices))
+
+		var aggregatedAllocatableQuantity, aggregatedCapacityQuantity uint64 = 0, 0
+		for _, device := range devices {
+			deviceState := machineState[device.Name]
+			if deviceState == nil {
+				return nil, fmt.Errorf("nil deviceState for device: %s", device.Name)
+			}
+
+			allocatable := quantityFunc(deviceState.Allocatable)
+			capacity := quantityFunc(deviceState.Capacity)
+			topologyAwareAllocatableQuantityList = append(topologyAwareAllocatableQuantityList,
+				generateDeviceTopologyAwareQuantity(device.Name, deviceState, float64(allocatable)))
+			topologyAwareCapacityQuantityList = append(topologyAwareCapacityQuantityList,
+				generateDeviceTopologyAwareQuantity(device.Name, deviceState, float64(capacity)))
+			aggregatedAllocatableQuantity += allocatable
+			aggregatedCapacityQuantity += capacity
+		}
+
+		allocatableResources[resourceName] = &pluginapi.AllocatableTopologyAwareResource{
+			IsNodeResource:                       true,
+			IsScalarResource:                     true,
+			AggregatedAllocatableQuantity:        float64(aggregatedAllocatableQuantity),
+			TopologyAwareAllocatableQuantityList: topologyAwareAllocatableQuantityList,
+			AggregatedCapacityQuantity:           float64(aggregatedCapacityQuantity),
+			TopologyAwareCapacityQuantityList:    topologyAwareCapacityQuantityList,
+		}
+	}
+
+	return &pluginapi.GetTopologyAwareAllocatableResourcesResponse{
+		AllocatableResources: allocatableResources,
+	}, nil
 }
 
 // GetResourcePluginOptions returns options to be communicated with Resource Manager
@@ -245,10 +463,10 @@ func (p *StaticPolicy) Allocate(_ context.Context,
 	req *pluginapi.ResourceRequest,
 ) (resp *pluginapi.ResourceAllocationResponse, err error) {
 	if req == nil {
-		return nil, fmt.Errorf("GetTopologyHints got nil req")
+		return nil, fmt.Errorf("Allocate got nil req")
 	}
 
-	return &pluginapi.ResourceAllocationResponse{
+	emptyResponse := &pluginapi.ResourceAllocationResponse{
 		PodUid:         req.PodUid,
 		PodNamespace:   req.PodNamespace,
 		PodName:        req.PodName,
@@ -260,7 +478,127 @@ func (p *StaticPolicy) Allocate(_ context.Context,
 		ResourceName:   p.ResourceName(),
 		Labels:         general.DeepCopyMap(req.Labels),
 		Annotations:    general.DeepCopyMap(req.Annotations),
-	}, nil
+	}
+
+	// currently, not to deal with init containers
+	if p.state == nil || req.ContainerType == pluginapi.ContainerType_INIT {
+		return emptyResponse, nil
+	}
+
+	// since qos config util will filter out annotation keys not related to katalyst QoS,
+	// we copy original pod annotations here to use them later
+	podAnnotations := maputil.CopySS(req.Annotations)
+
+	qosLevel, err := util.GetKatalystQoSLevelFromResourceReq(p.qosConfig, req, p.podAnnotationKeptKeys, p.podLabelKeptKeys)
+	if err != nil {
+		err = fmt.Errorf("GetKatalystQoSLevelFromResourceReq for pod: %s/%s, container: %s failed with error: %v",
+			req.PodNamespace, req.PodName, req.ContainerName, err)
+		general.Errorf("%s", err.Error())
+		return nil, err
+	}
+
+	p.Lock()
+	defer func() {
+		if err := p.state.StoreState(); err != nil {
+			general.ErrorS(err, "store state failed", "podName", req.PodName, "containerName", req.ContainerName)
+		}
+		p.Unlock()
+		if err != nil {
+			_ = p.emitter.StoreInt64(util.MetricNameAllocateFailed, 1, metrics.MetricTypeNameRaw,
+				metrics.MetricTag{Key: "error_message", Val: metric.MetricTagValueFormat(err)})
+		}
+	}()
+
+	requirement, err := getIOThrottleRequirement(req, podAnnotations, p.isPodQuantityCharged(req.PodUid, req.ContainerName))
+	if err != nil {
+		err = fmt.Errorf("getIOThrottleRequirement for pod: %s/%s, container: %s failed with error: %v",
+			req.PodNamespace, req.PodName, req.ContainerName, err)
+		general.Errorf("%s", err.Error())
+		return nil, err
+	}
+
+	if requirement.Device == "" {
+		requirement.Device = p.defaultDevice
+	}
+
+	general.InfoS("called",
+		"podNamespace", req.PodNamespace,
+		"podName", req.PodName,
+		"containerName", req.ContainerName,
+		"qosLevel", qosLevel,
+		"resourceRequests", req.ResourceRequests,
+		"ioThrottleRequirement", *requirement)
+
+	allocationInfo := p.state.GetAllocationInfo(req.PodUid, req.ContainerName)
+
+	// sidecars and containers without io requirement are not throttled,
+	// and we return a trivial allocationResult to avoid re-allocating
+	if req.ContainerType == pluginapi.ContainerType_SIDECAR || requirement.IOQuantity.IsZero() {
+		if allocationInfo != nil {
+			general.InfoS("remove stale allocation",
+				"podNamespace", req.PodNamespace,
+				"podName", req.PodName,
+				"containerName", req.ContainerName,
+				"currentResult", allocationInfo.String())
+			p.resetIOThrottle(req.PodUid, req.ContainerName, allocationInfo)
+			p.state.Delete(req.PodUid, req.ContainerName, false)
+			p.state.SetMachineState(state.GenerateMachineStateFromPodEntries(p.state.GetDevices(), p.state.GetPodEntries()), false)
+		}
+		return packAllocationResponse(req, &state.AllocationInfo{})
+	}
+
+	if allocationInfo != nil && allocationInfo.DeviceName == requirement.Device &&
+		allocationInfo.Quantity == requirement.IOQuantity && allocationInfo.PodQuantityCharged == requirement.podQuantityCharged {
+		general.InfoS("already allocated and meet requirement",
+			"podNamespace", req.PodNamespace,
+			"podName", req.PodName,
+			"containerName", req.ContainerName,
+			"currentResult", allocationInfo.String())
+		return packAllocationResponse(req, allocationInfo)
+	}
+
+	// exclude the current allocation of this container before checking whether the device is able to satisfy it
+	podEntries := p.state.GetPodEntries()
+	if podEntries[req.PodUid] != nil {
+		delete(podEntries[req.PodUid], req.ContainerName)
+	}
+	machineState := state.GenerateMachineStateFromPodEntries(p.state.GetDevices(), podEntries)
+
+	deviceState, ok := machineState[requirement.Device]
+	if !ok || deviceState == nil {
+		err = fmt.Errorf("device %s for pod: %s/%s, container: %s isn't managed by io plugin",
+			requirement.Device, req.PodNamespace, req.PodName, req.ContainerName)
+		general.Errorf("%s", err.Error())
+		return nil, err
+	}
+
+	if !deviceState.Free.Satisfy(deviceState.Capacity, requirement.IOQuantity) {
+		general.Errorf("insufficient io quantity on device %s to satisfy pod: %s/%s, container: %s, requirement: %+v, free: %+v",
+			requirement.Device, req.PodNamespace, req.PodName, req.ContainerName, requirement.IOQuantity, deviceState.Free)
+		err = fmt.Errorf("failed to meet the io requirement %+v on device %s", requirement.IOQuantity, requirement.Device)
+		return nil, err
+	}
+
+	// the previous limit is left in cgroup if it's no longer applied to the same cgroup and device
+	if allocationInfo != nil && (allocationInfo.DeviceName != requirement.Device ||
+		allocationInfo.PodQuantityCharged != requirement.podQuantityCharged) {
+		p.resetIOThrottle(req.PodUid, req.ContainerName, allocationInfo)
+	}
+
+	// generate allocationInfo and update the checkpoint accordingly
+	newAllocation := &state.AllocationInfo{
+		AllocationMeta: commonstate.GenerateGenericContainerAllocationMeta(req,
+			commonstate.EmptyOwnerPoolName, qosLevel),
+		DeviceName:         requirement.Device,
+		DeviceID:           deviceState.DeviceID,
+		Quantity:           requirement.IOQuantity,
+		PodQuantityCharged: requirement.podQuantityCharged,
+	}
+
+	p.state.SetAllocationInfo(req.PodUid, req.ContainerName, newAllocation, false)
+	p.state.SetMachineState(state.GenerateMachineStateFromPodEntries(p.state.GetDevices(), p.state.GetPodEntries()), false)
+
+	return packAllocationResponse(req, newAllocation)
 }
 
 // AllocateForPod is called during pod admit so that the resource
@@ -280,3 +618,203 @@ func (p *StaticPolicy) PreStartContainer(context.Context,
 ) (*pluginapi.PreStartContainerResponse, error) {
 	return &pluginapi.PreStartContainerResponse{}, nil
 }
+
+// isPodQuantityCharged returns true if the pod-level quantity has been charged
+// to containers of the given pod other than the given container
+func (p *StaticPolicy) isPodQuantityCharged(podUID, containerName string) bool {
+	for name, allocationInfo := range p.state.GetPodEntries()[podUID] {
+		if name != containerName && allocationInfo != nil && allocationInfo.PodQuantityCharged {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *StaticPolicy) removePod(podUID string) error {
+	podEntries := p.state.GetPodEntries()
+	if _, ok := podEntries[podUID]; !ok {
+		return nil
+	}
+
+	for containerName, allocationInfo := range podEntries[podUID] {
+		p.resetIOThrottle(podUID, containerName, allocationInfo)
+	}
+	delete(podEntries, podUID)
+
+	p.state.SetPodEntries(podEntries, false)
+	p.state.SetMachineState(state.GenerateMachineStateFromPodEntries(p.state.GetDevices(), podEntries), false)
+
+	err := p.state.StoreState()
+	if err != nil {
+		general.Errorf("store state failed with error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// applyIOThrottle applies io.max of all allocated containers; since cgroups of containers
+// are not created yet during allocation, we should apply them asynchronously.
+func (p *StaticPolicy) applyIOThrottle(_ *config.Configuration,
+	_ interface{},
+	_ *dynamicconfig.DynamicAgentConfiguration,
+	_ metrics.MetricEmitter,
+	_ *metaserver.MetaServer,
+) {
+	if p.metaServer == nil {
+		general.Errorf("nil metaServer")
+		return
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	for podUID, containerEntries := range p.state.GetPodEntries() {
+		for containerName, allocationInfo := range containerEntries {
+			if allocationInfo == nil || allocationInfo.DeviceID == "" || allocationInfo.Quantity.IsZero() {
+				continue
+			}
+
+			data := &common.IOThrottleData{
+				ReadBps:   allocationInfo.Quantity.ReadBps,
+				WriteBps:  allocationInfo.Quantity.WriteBps,
+				ReadIOPS:  allocationInfo.Quantity.ReadIOPS,
+				WriteIOPS: allocationInfo.Quantity.WriteIOPS,
+			}
+			if err := p.applyIOThrottleData(podUID, containerName, allocationInfo, data); err != nil {
+				general.Errorf("apply io throttle failed, pod: %s, container: %s, device: %s, data: %s, err: %v",
+					podUID, containerName, allocationInfo.DeviceName, data.String(), err)
+				_ = p.emitter.StoreInt64(metricNameApplyIOThrottleFailed, 1, metrics.MetricTypeNameRaw,
+					metrics.MetricTag{Key: "device", Val: allocationInfo.DeviceName})
+			}
+		}
+	}
+}
+
+// resetIOThrottle removes io.max applied for the allocation, and it's best-effort
+// since cgroups may have been removed along with the pod or container.
+func (p *StaticPolicy) resetIOThrottle(podUID, containerName string, allocationInfo *state.AllocationInfo) {
+	if allocationInfo == nil || allocationInfo.DeviceID == "" || allocationInfo.Quantity.IsZero() {
+		return
+	}
+
+	if err := p.applyIOThrottleData(podUID, containerName, allocationInfo, &common.IOThrottleData{}); err != nil {
+		general.Warningf("reset io throttle failed, pod: %s, container: %s, device: %s, err: %v",
+			podUID, containerName, allocationInfo.DeviceName, err)
+	}
+}
+
+// applyIOThrottleData applies io.max for the allocation; the pod-level quantity in annotation
+// is applied to the pod cgroup to be shared by all containers, while others are applied to
+// the container cgroup. It's skipped if the cgroup doesn't exist.
+func (p *StaticPolicy) applyIOThrottleData(podUID, containerName string,
+	allocationInfo *state.AllocationInfo, data *common.IOThrottleData,
+) error {
+	if allocationInfo.PodQuantityCharged {
+		if !p.isPodCgroupExists(podUID) {
+			general.Infof("pod cgroup does not exist, pod: %s", podUID)
+			return nil
+		}
+		return p.applyPodIOThrottleFunc(podUID, allocationInfo.DeviceID, data)
+	}
+
+	if p.metaServer == nil {
+		return fmt.Errorf("nil metaServer")
+	}
+
+	containerID, err := p.metaServer.GetContainerID(podUID, containerName)
+	if err != nil {
+		return fmt.Errorf("get container id failed: %v", err)
+	}
+
+	if exist, err := p.isContainerCgroupExists(podUID, containerID); err != nil {
+		return fmt.Errorf("check if container cgroup exists failed, container id: %s, err: %v", containerID, err)
+	} else if !exist {
+		general.Infof("container cgroup does not exist, pod: %s, container: %s(%s)", podUID, containerName, containerID)
+		return nil
+	}
+
+	return p.applyIOThrottleFunc(podUID, containerID, allocationInfo.DeviceID, data)
+}
+
+// clearResidualState is used to clean residual pods in local state
+func (p *StaticPolicy) clearResidualState(_ *config.Configuration,
+	_ interface{},
+	_ *dynamicconfig.DynamicAgentConfiguration,
+	_ metrics.MetricEmitter,
+	_ *metaserver.MetaServer,
+) {
+	general.Infof("exec")
+
+	if p.metaServer == nil {
+		general.Errorf("nil metaServer")
+		return
+	}
+
+	podList, err := p.metaServer.GetPodList(context.Background(), nil)
+	if err != nil {
+		general.Errorf("get pod list failed: %v", err)
+		return
+	}
+
+	podSet := sets.NewString()
+	for _, pod := range podList {
+		podSet.Insert(fmt.Sprintf("%v", pod.UID))
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	residualSet := make(map[string]bool)
+	podEntries := p.state.GetPodEntries()
+	for podUID := range podEntries {
+		if !podSet.Has(podUID) {
+			residualSet[podUID] = true
+			p.residualHitMap[podUID] += 1
+			general.Infof("found pod: %s with state but doesn't show up in pod watcher, hit count: %d", podUID, p.residualHitMap[podUID])
+		}
+	}
+
+	podsToDelete := sets.NewString()
+	for podUID, hitCount := range p.residualHitMap {
+		if !residualSet[podUID] {
+			general.Infof("already found pod: %s in pod watcher or its state is cleared, delete it from residualHitMap", podUID)
+			delete(p.residualHitMap, podUID)
+			continue
+		}
+
+		if time.Duration(hitCount)*stateCheckPeriod >= maxResidualTime {
+			podsToDelete.Insert(podUID)
+		}
+	}
+
+	if podsToDelete.Len() == 0 {
+		return
+	}
+
+	for _, podUID := range podsToDelete.UnsortedList() {
+		general.Infof("clear residual pod: %s in state", podUID)
+		delete(podEntries, podUID)
+	}
+
+	p.state.SetPodEntries(podEntries, false)
+	p.state.SetMachineState(state.GenerateMachineStateFromPodEntries(p.state.GetDevices(), podEntries), false)
+
+	if err = p.state.StoreState(); err != nil {
+		general.Errorf("store state failed: %v", err)
+	}
+}
+
+// generateDeviceTopologyAwareQuantity returns the topology aware quantity of the block device,
+// and it will be reported as a child zone of the numa node which the device attaches to.
+func generateDeviceTopologyAwareQuantity(devName string, deviceState *state.DeviceState, value float64) *pluginapi.TopologyAwareQuantity {
+	return &pluginapi.TopologyAwareQuantity{
+		ResourceValue: value,
+		Node:          uint64(deviceState.NumaNode),
+		Name:          devName,
+		Type:          TopologyTypeDisk,
+		TopologyLevel: pluginapi.TopologyLevel_NUMA,
+		Annotations: map[string]string{
+			consts.ResourceAnnotationKeyResourceIdentifier: devName,
+		},
+	}
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package staticpolicy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	pluginapi "k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/agent/qrm"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/io/state"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/qrm/statedirectory"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/pod"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
+)
+
+const (
+	testDeviceName = "sda"
+	testDeviceID   = "8:0"
+	testPodUID     = "pod-uid"
+)
+
+func makeIOThrottlePolicy(t *testing.T) *StaticPolicy {
+	devices := []state.DeviceInfo{
+		{
+			Name:     testDeviceName,
+			DeviceID: testDeviceID,
+			Capacity: state.IOQuantity{ReadBps: 1000, WriteBps: 1000, WriteIOPS: 100},
+		},
+	}
+
+	stateImpl, err := state.NewCheckpointState(&statedirectory.StateDirectoryConfiguration{
+		StateFileDirectory: t.TempDir(),
+	}, IOPluginStateFileName, IOResourcePluginPolicyNameStatic, devices, false, metrics.DummyMetrics{})
+	require.NoError(t, err)
+
+	metaServer := makeMetaServer()
+	metaServer.PodFetcher = &pod.PodFetcherStub{PodList: []*v1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{UID: testPodUID},
+			Status: v1.PodStatus{
+				ContainerStatuses: []v1.ContainerStatus{
+					{Name: "c1", ContainerID: "containerd://c1-id"},
+					{Name: "c2", ContainerID: "containerd://c2-id"},
+					{Name: "c3", ContainerID: "containerd://c3-id"},
+				},
+			},
+		},
+	}}
+
+	return &StaticPolicy{
+		name:                    fmt.Sprintf("%s_%s", qrm.QRMPluginNameIO, IOResourcePluginPolicyNameStatic),
+		stopCh:                  make(chan struct{}),
+		emitter:                 metrics.DummyMetrics{},
+		metaServer:              metaServer,
+		qosConfig:               generateTestConfiguration(t).QoSConfiguration,
+		state:                   stateImpl,
+		defaultDevice:           testDeviceName,
+		residualHitMap:          make(map[string]int64),
+		applyIOThrottleFunc:     func(_, _, _ string, _ *common.IOThrottleData) error { return nil },
+		applyPodIOThrottleFunc:  func(_, _ string, _ *common.IOThrottleData) error { return nil },
+		isContainerCgroupExists: func(_, _ string) (bool, error) { return true, nil },
+		isPodCgroupExists:       func(_ string) bool { return true },
+	}
+}
+
+func makeIOThrottleRequest(containerName string, bandwidth float64, annotation string) *pluginapi.ResourceRequest {
+	req := &pluginapi.ResourceRequest{
+		PodUid:        testPodUID,
+		PodNamespace:  "default",
+		PodName:       "test-pod",
+		ContainerName: containerName,
+		ContainerType: pluginapi.ContainerType_MAIN,
+		ResourceName:  consts.ResourceDiskBandwidth,
+		ResourceRequests: map[string]float64{
+			consts.ResourceDiskBandwidth: bandwidth,
+		},
+		Annotations: map[string]string{
+			apiconsts.PodAnnotationQoSLevelKey: apiconsts.PodAnnotationQoSLevelSharedCores,
+		},
+	}
+
+	if annotation != "" {
+		req.Annotations[consts.PodAnnotationIOThrottleKey] = annotation
+	}
+	return req
+}
+
+func TestStaticPolicy_AllocateWithIOThrottle(t *testing.T) {
+	t.Parallel()
+
+	p := makeIOThrottlePolicy(t)
+	assert.Equal(t, consts.ResourceDiskBandwidth, p.ResourceName())
+
+	// allocate with the extended resource only
+	resp, err := p.Allocate(context.Background(), makeIOThrottleRequest("c1", 400, ""))
+	require.NoError(t, err)
+	assert.Equal(t, float64(400), resp.AllocationResult.ResourceAllocation[consts.ResourceDiskBandwidth].AllocatedQuantity)
+	assert.Equal(t, testDeviceName, resp.AllocationResult.ResourceAllocation[consts.ResourceDiskBandwidth].AllocationResult)
+
+	// annotation takes precedence over the extended resource
+	_, err = p.Allocate(context.Background(), makeIOThrottleRequest("c2", 400, `{"read_bps": 200, "write_iops": 60}`))
+	require.NoError(t, err)
+
+	allocationInfo := p.state.GetAllocationInfo(testPodUID, "c2")
+	require.NotNil(t, allocationInfo)
+	assert.Equal(t, state.IOQuantity{ReadBps: 200, WriteBps: 400, WriteIOPS: 60}, allocationInfo.Quantity)
+	assert.Equal(t, testDeviceID, allocationInfo.DeviceID)
+
+	deviceState := p.state.GetMachineState()[testDeviceName]
+	assert.Equal(t, state.IOQuantity{ReadBps: 600, WriteBps: 800, WriteIOPS: 60}, deviceState.Allocated)
+	assert.Equal(t, state.IOQuantity{ReadBps: 400, WriteBps: 200, WriteIOPS: 40}, deviceState.Free)
+
+	// pod-level quantity in annotation is charged only once for each pod
+	_, err = p.Allocate(context.Background(), makeIOThrottleRequest("c3", 100, `{"read_bps": 200, "write_iops": 60}`))
+	require.NoError(t, err)
+	allocationInfo = p.state.GetAllocationInfo(testPodUID, "c3")
+	require.NotNil(t, allocationInfo)
+	assert.Equal(t, state.IOQuantity{ReadBps: 100, WriteBps: 100}, allocationInfo.Quantity)
+	assert.False(t, allocationInfo.PodQuantityCharged)
+
+	// stale allocation is removed if the container has no requirement any more
+	_, err = p.Allocate(context.Background(), makeIOThrottleRequest("c3", 0, `{"read_bps": 200, "write_iops": 60}`))
+	require.NoError(t, err)
+	assert.Nil(t, p.state.GetAllocationInfo(testPodUID, "c3"))
+	assert.Equal(t, state.IOQuantity{ReadBps: 600, WriteBps: 800, WriteIOPS: 60}, p.state.GetMachineState()[testDeviceName].Allocated)
+
+	// insufficient write iops
+	req := makeIOThrottleRequest("c1", 0, `{"write_iops": 50}`)
+	req.PodUid = "another-pod-uid"
+	_, err = p.Allocate(context.Background(), req)
+	assert.Error(t, err)
+
+	// unknown device
+	_, err = p.Allocate(context.Background(), makeIOThrottleRequest("c3", 100, `{"device": "sdb"}`))
+	assert.Error(t, err)
+
+	// re-allocate with a larger requirement should exclude the previous allocation
+	_, err = p.Allocate(context.Background(), makeIOThrottleRequest("c1", 600, ""))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000), p.state.GetMachineState()[testDeviceName].Allocated.WriteBps)
+
+	allocatable, err := p.GetTopologyAwareAllocatableResources(context.Background(), &pluginapi.GetTopologyAwareAllocatableResourcesRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, float64(1000), allocatable.AllocatableResources[consts.ResourceDiskBandwidth].AggregatedAllocatableQuantity)
+	assert.Equal(t, TopologyTypeDisk, allocatable.AllocatableResources[consts.ResourceDiskBandwidth].TopologyAwareAllocatableQuantityList[0].Type)
+	assert.Equal(t, float64(100), allocatable.AllocatableResources[consts.ResourceDiskIOPS].AggregatedCapacityQuantity)
+
+	resources, err := p.GetTopologyAwareResources(context.Background(), &pluginapi.GetTopologyAwareResourcesRequest{
+		PodUid:        testPodUID,
+		ContainerName: "c2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, float64(200), resources.ContainerTopologyAwareResources.AllocatedResources[consts.ResourceDiskBandwidth].AggregatedQuantity)
+	assert.Equal(t, float64(60), resources.ContainerTopologyAwareResources.AllocatedResources[consts.ResourceDiskIOPS].AggregatedQuantity)
+
+	allocations, err := p.GetResourcesAllocation(context.Background(), &pluginapi.GetResourcesAllocationRequest{})
+	require.NoError(t, err)
+	assert.Len(t, allocations.PodResources[testPodUID].ContainerResources, 2)
+	assert.Equal(t, float64(60),
+		allocations.PodResources[testPodUID].ContainerResources["c2"].ResourceAllocation[consts.ResourceDiskIOPS].AllocatedQuantity)
+
+	_, err = p.RemovePod(context.Background(), &pluginapi.RemovePodRequest{PodUid: testPodUID})
+	require.NoError(t, err)
+	assert.Empty(t, p.state.GetPodEntries())
+	assert.Equal(t, state.IOQuantity{}, p.state.GetMachineState()[testDeviceName].Allocated)
+}
+
+func TestStaticPolicy_ApplyIOThrottle(t *testing.T) {
+	t.Parallel()
+
+	p := makeIOThrottlePolicy(t)
+
+	// cgroup path -> device id -> applied data
+	applied := make(map[string]map[string]common.IOThrottleData)
+	record := func(cgroup, devID string, data *common.IOThrottleData) {
+		if applied[cgroup] == nil {
+			applied[cgroup] = make(map[string]common.IOThrottleData)
+		}
+		applied[cgroup][devID] = *data
+	}
+	p.applyIOThrottleFunc = func(podUID, containerID, devID string, data *common.IOThrottleData) error {
+		record(podUID+"/"+containerID, devID, data)
+		return nil
+	}
+	p.applyPodIOThrottleFunc = func(podUID, devID string, data *common.IOThrottleData) error {
+		record(podUID, devID, data)
+		return nil
+	}
+
+	_, err := p.Allocate(context.Background(), makeIOThrottleRequest("c1", 0, `{"read_bps": 200, "write_iops": 60}`))
+	require.NoError(t, err)
+	_, err = p.Allocate(context.Background(), makeIOThrottleRequest("c2", 100, `{"read_bps": 200, "write_iops": 60}`))
+	require.NoError(t, err)
+	_, err = p.Allocate(context.Background(), makeIOThrottleRequest("c3", 0, `{"read_bps": 200, "write_iops": 60}`))
+	require.NoError(t, err)
+
+	// pod-level quantity is applied to the pod cgroup, and others to container cgroups
+	p.applyIOThrottle(nil, nil, nil, nil, nil)
+	assert.Equal(t, map[string]map[string]common.IOThrottleData{
+		testPodUID:            {testDeviceID: {ReadBps: 200, WriteIOPS: 60}},
+		testPodUID + "/c2-id": {testDeviceID: {ReadBps: 100, WriteBps: 100}},
+	}, applied)
+
+	// io.max is reset when the allocation of the container is dropped
+	_, err = p.Allocate(context.Background(), makeIOThrottleRequest("c2", 0, `{"read_bps": 200, "write_iops": 60}`))
+	require.NoError(t, err)
+	assert.Equal(t, common.IOThrottleData{}, applied[testPodUID+"/c2-id"][testDeviceID])
+
+	// io.max is reset when the pod is removed
+	_, err = p.RemovePod(context.Background(), &pluginapi.RemovePodRequest{PodUid: testPodUID})
+	require.NoError(t, err)
+	assert.Equal(t, common.IOThrottleData{}, applied[testPodUID][testDeviceID])
+
+	// cgroups that don't exist are skipped
+	p.isPodCgroupExists = func(_ string) bool { return false }
+	delete(applied, testPodUID)
+	_, err = p.Allocate(context.Background(), makeIOThrottleRequest("c1", 0, `{"read_bps": 200}`))
+	require.NoError(t, err)
+	p.applyIOThrottle(nil, nil, nil, nil, nil)
+	assert.NotContains(t, applied, testPodUID)
+}
+
+func TestLoadIOThrottleDevices(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	blockDir := filepath.Join(tmpDir, "block")
+	require.NoError(t, os.MkdirAll(filepath.Join(blockDir, "sda", "device"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(blockDir, "sda", "dev"), []byte("8:0\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(blockDir, "sda", "device", "numa_node"), []byte("1\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(blockDir, "vda"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(blockDir, "vda", "dev"), []byte("253:0\n"), 0o644))
+
+	configFile := filepath.Join(tmpDir, "devices.json")
+	require.NoError(t, os.WriteFile(configFile,
+		[]byte(`{"vda": {"read_bps": 100, "write_bps": 200}, "sda": {"read_iops": 10}}`), 0o644))
+
+	devices, err := loadIOThrottleDevicesFromDir(configFile, blockDir)
+	require.NoError(t, err)
+	assert.Equal(t, []state.DeviceInfo{
+		{Name: "sda", DeviceID: "8:0", NumaNode: 1, Capacity: state.IOQuantity{ReadIOPS: 10}},
+		{Name: "vda", DeviceID: "253:0", NumaNode: 0, Capacity: state.IOQuantity{ReadBps: 100, WriteBps: 200}},
+	}, devices)
+
+	_, err = loadIOThrottleDevicesFromDir("", blockDir)
+	assert.Error(t, err)
+}
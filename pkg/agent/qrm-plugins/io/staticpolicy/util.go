@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package staticpolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	pluginapi "k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/io/state"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+// TopologyTypeDisk is the topology zone type of block devices reported by io plugin
+const TopologyTypeDisk = "Disk"
+
+// sysBlockDir is the sysfs directory of block devices
+const sysBlockDir = "/sys/block"
+
+// scalarResourceQuantityFuncs are the scalar resources reported by io plugin,
+// and the funcs convert IOQuantity into the quantity of each resource.
+var scalarResourceQuantityFuncs = map[string]func(state.IOQuantity) uint64{
+	consts.ResourceDiskBandwidth: state.IOQuantity.Bandwidth,
+	consts.ResourceDiskIOPS:      state.IOQuantity.IOPS,
+}
+
+// ioThrottleRequirement is the json format of consts.PodAnnotationIOThrottleKey
+type ioThrottleRequirement struct {
+	Device string `json:"device,omitempty"`
+	state.IOQuantity
+
+	// podQuantityCharged is true if the pod-level quantity in annotation is included
+	podQuantityCharged bool
+}
+
+// loadIOThrottleDevices loads the managed block devices and their capacities from config file,
+// and the config file is a json map from device names to the capacities.
+func loadIOThrottleDevices(configFile string) ([]state.DeviceInfo, error) {
+	return loadIOThrottleDevicesFromDir(configFile, sysBlockDir)
+}
+
+func loadIOThrottleDevicesFromDir(configFile, blockDir string) ([]state.DeviceInfo, error) {
+	if configFile == "" {
+		return nil, fmt.Errorf("empty io throttle device config file")
+	}
+
+	deviceCapacities := make(map[string]state.IOQuantity)
+	if err := general.LoadJsonConfig(configFile, &deviceCapacities); err != nil {
+		return nil, fmt.Errorf("load io throttle device config failed with error: %v", err)
+	}
+
+	devNames := make([]string, 0, len(deviceCapacities))
+	for devName := range deviceCapacities {
+		devNames = append(devNames, devName)
+	}
+	sort.Strings(devNames)
+
+	devices := make([]state.DeviceInfo, 0, len(devNames))
+	for _, devName := range devNames {
+		devIDBytes, err := ioutil.ReadFile(filepath.Join(blockDir, devName, "dev"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get device id of %s, err %v", devName, err)
+		}
+
+		devices = append(devices, state.DeviceInfo{
+			Name:     devName,
+			DeviceID: strings.TrimSpace(string(devIDBytes)),
+			NumaNode: getDeviceNumaNode(blockDir, devName),
+			Capacity: deviceCapacities[devName],
+		})
+	}
+
+	return devices, nil
+}
+
+// getDeviceNumaNode returns the numa node the device attaches to, and
+// devices without numa affinity (e.g. virtual disks) are considered to be on numa 0.
+func getDeviceNumaNode(blockDir, devName string) int {
+	numaBytes, err := ioutil.ReadFile(filepath.Join(blockDir, devName, "device", "numa_node"))
+	if err != nil {
+		return 0
+	}
+
+	numaNode, err := strconv.Atoi(strings.TrimSpace(string(numaBytes)))
+	if err != nil || numaNode < 0 {
+		return 0
+	}
+	return numaNode
+}
+
+// getIOThrottleRequirement parses io throttling requirement of the container from both the
+// extended resource and the annotation, and the annotation takes precedence for bandwidth.
+// since the annotation is pod-level, its quantity is charged to the first allocated container
+// and applied to the pod cgroup, so that it limits all containers of the pod together; it's
+// skipped for other containers of the same pod, but the device in it still works.
+func getIOThrottleRequirement(req *pluginapi.ResourceRequest, podAnnotations map[string]string,
+	podQuantityCharged bool,
+) (*ioThrottleRequirement, error) {
+	requirement := &ioThrottleRequirement{}
+	if value, ok := podAnnotations[consts.PodAnnotationIOThrottleKey]; ok && value != "" {
+		if err := json.Unmarshal([]byte(value), requirement); err != nil {
+			return nil, fmt.Errorf("unmarshal %s: %s failed with error: %v", consts.PodAnnotationIOThrottleKey, value, err)
+		}
+	}
+
+	if !requirement.IOQuantity.IsZero() {
+		if podQuantityCharged {
+			requirement.IOQuantity = state.IOQuantity{}
+		} else {
+			requirement.podQuantityCharged = true
+		}
+	}
+
+	if bandwidth, ok := req.ResourceRequests[consts.ResourceDiskBandwidth]; ok && bandwidth > 0 {
+		if requirement.ReadBps == 0 {
+			requirement.ReadBps = uint64(bandwidth)
+		}
+
+		if requirement.WriteBps == 0 {
+			requirement.WriteBps = uint64(bandwidth)
+		}
+	}
+
+	return requirement, nil
+}
+
+// packAllocationResponse fills pluginapi.ResourceAllocationResponse with information from AllocationInfo and pluginapi.ResourceRequest
+func packAllocationResponse(req *pluginapi.ResourceRequest, allocationInfo *state.AllocationInfo) (*pluginapi.ResourceAllocationResponse, error) {
+	if allocationInfo == nil {
+		return nil, fmt.Errorf("packAllocationResponse got nil allocationInfo")
+	} else if req == nil {
+		return nil, fmt.Errorf("packAllocationResponse got nil request")
+	}
+
+	return &pluginapi.ResourceAllocationResponse{
+		PodUid:         req.PodUid,
+		PodNamespace:   req.PodNamespace,
+		PodName:        req.PodName,
+		ContainerName:  req.ContainerName,
+		ContainerType:  req.ContainerType,
+		ContainerIndex: req.ContainerIndex,
+		PodRole:        req.PodRole,
+		PodType:        req.PodType,
+		ResourceName:   req.ResourceName,
+		AllocationResult: &pluginapi.ResourceAllocation{
+			ResourceAllocation: packResourceAllocationInfo(allocationInfo),
+		},
+		Labels:      general.DeepCopyMap(req.Labels),
+		Annotations: general.DeepCopyMap(req.Annotations),
+	}, nil
+}
+
+// packResourceAllocationInfo returns allocation info of all scalar resources reported by io plugin
+func packResourceAllocationInfo(allocationInfo *state.AllocationInfo) map[string]*pluginapi.ResourceAllocationInfo {
+	resourceAllocation := make(map[string]*pluginapi.ResourceAllocationInfo, len(scalarResourceQuantityFuncs))
+	for resourceName, quantityFunc := range scalarResourceQuantityFuncs {
+		resourceAllocation[resourceName] = &pluginapi.ResourceAllocationInfo{
+			IsNodeResource:    true,
+			IsScalarResource:  true, // to avoid re-allocating
+			AllocatedQuantity: float64(quantityFunc(allocationInfo.Quantity)),
+			AllocationResult:  allocationInfo.DeviceName,
+			Annotations: map[string]string{
+				consts.PodAnnotationIOThrottleKey: allocationInfo.String(),
+			},
+		}
+	}
+	return resourceAllocation
+}
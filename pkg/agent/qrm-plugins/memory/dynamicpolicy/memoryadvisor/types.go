@@ -27,6 +27,8 @@ const (
 	ControlKnobKeySwapMax            MemoryControlKnobName = "swap_max"
 	ControlKnowKeyMemoryOffloading   MemoryControlKnobName = "memory_offloading"
 	ControlKnobKeyMemoryNUMAHeadroom MemoryControlKnobName = "memory_numa_headroom"
+	ControlKnobKeyMemoryHigh         MemoryControlKnobName = "memory_high"
+	ControlKnobKeyMigrateNUMAMemory  MemoryControlKnobName = "migrate_numa_memory"
 )
 
 type MemoryNUMAHeadroom map[int]int64
@@ -73,6 +73,7 @@ const (
 	memoryPluginAsyncWorkTopicSetExtraCGMemLimit = "qrm_memory_plugin_set_extra_mem_limit"
 	memoryPluginAsyncWorkTopicMovePage           = "qrm_memory_plugin_move_page"
 	memoryPluginAsyncWorkTopicMemoryOffloading   = "qrm_memory_plugin_mem_offload"
+	memoryPluginAsyncWorkTopicSetMemoryHigh      = "qrm_memory_plugin_set_memory_high"
 
 	dropCacheTimeoutSeconds          = 30
 	setExtraCGMemLimitTimeoutSeconds = 60
@@ -162,6 +163,10 @@ type DynamicPolicy struct {
 
 	numaAllocationReactor                         reactor.AllocationReactor
 	numaBindResultResourceAllocationAnnotationKey string
+
+	// controlKnobEventStates keeps the last published control knobs handled by advice from
+	// memory-advisor, so that events are only published on changes; it's protected by the lock.
+	controlKnobEventStates map[string]controlKnobEventState
 }
 
 func NewDynamicPolicy(agentCtx *agent.GenericContext, conf *config.Configuration,
@@ -280,6 +285,10 @@ func NewDynamicPolicy(agentCtx *agent.GenericContext, conf *config.Configuration
 		memoryadvisor.ControlKnobHandlerWithChecker(policyImplement.handleAdvisorMemoryOffloading))
 	memoryadvisor.RegisterControlKnobHandler(memoryadvisor.ControlKnobKeyMemoryNUMAHeadroom,
 		memoryadvisor.ControlKnobHandlerWithChecker(policyImplement.handleAdvisorMemoryNUMAHeadroom))
+	memoryadvisor.RegisterControlKnobHandler(memoryadvisor.ControlKnobKeyMemoryHigh,
+		memoryadvisor.ControlKnobHandlerWithChecker(policyImplement.handleAdvisorMemoryHigh))
+	memoryadvisor.RegisterControlKnobHandler(memoryadvisor.ControlKnobKeyMigrateNUMAMemory,
+		memoryadvisor.ControlKnobHandlerWithChecker(policyImplement.handleAdvisorMigrateNUMAMemory))
 
 	if policyImplement.enableEvictingLogCache {
 		policyImplement.logCacheEvictionManager = logcache.NewManager(conf, agentCtx.MetaServer)
@@ -1025,6 +1034,7 @@ func (p *DynamicPolicy) Allocate(ctx context.Context,
 			"podName", req.PodName,
 			"containerName", req.ContainerName,
 		)
+		util.PublishAllocationEvent(p.name, qosLevel, startTime, req, resp, respErr)
 		return
 	}()
 
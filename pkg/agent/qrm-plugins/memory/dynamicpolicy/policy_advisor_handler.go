@@ -24,6 +24,7 @@ import (
 	"os"
 	"path"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/samber/lo"
@@ -31,6 +32,7 @@ import (
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/apimachinery/pkg/util/wait"
 	pluginapi "k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
@@ -55,6 +57,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
 	cgroupcommon "github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
 	cgroupmgr "github.com/kubewharf/katalyst-core/pkg/util/cgroup/manager"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
 	"github.com/kubewharf/katalyst-core/pkg/util/general"
 	"github.com/kubewharf/katalyst-core/pkg/util/machine"
 	"github.com/kubewharf/katalyst-core/pkg/util/metric"
@@ -288,6 +291,7 @@ func (p *DynamicPolicy) handleAdvisorResp(advisorResp *advisorsvc.ListAndWatchRe
 	podResourceEntries := p.state.GetPodResourceEntries()
 
 	handlers := memoryadvisor.GetRegisteredControlKnobHandlers()
+	handledControlKnobs := sets.NewString()
 
 	for entryName, entry := range advisorResp.PodEntries {
 		if entry == nil {
@@ -311,9 +315,12 @@ func (p *DynamicPolicy) handleAdvisorResp(advisorResp *advisorsvc.ListAndWatchRe
 					"controlKnobValue", controlKnobValue)
 				handler := handlers[memoryadvisor.MemoryControlKnobName(controlKnobName)]
 				if handler != nil {
+					handleStartTime := time.Now()
 					err := handler(nil, nil, nil,
 						p.emitter, p.metaServer,
 						entryName, subEntryName, calculationInfo, podResourceEntries)
+					handledControlKnobs.Insert(p.publishControlKnobEvent(handleStartTime, entryName, subEntryName, "",
+						controlKnobName, controlKnobValue, err))
 
 					if err != nil {
 						general.ErrorS(err, "handle control knob failed",
@@ -355,9 +362,12 @@ func (p *DynamicPolicy) handleAdvisorResp(advisorResp *advisorsvc.ListAndWatchRe
 				"controlKnobValue", controlKnobValue)
 			handler := handlers[memoryadvisor.MemoryControlKnobName(controlKnobName)]
 			if handler != nil {
+				handleStartTime := time.Now()
 				err := handler(nil, nil, nil,
 					p.emitter, p.metaServer,
 					"", "", calculationInfo, podResourceEntries)
+				handledControlKnobs.Insert(p.publishControlKnobEvent(handleStartTime, "", "", calculationInfo.CgroupPath,
+					controlKnobName, controlKnobValue, err))
 
 				if err != nil {
 					general.ErrorS(err, "handle control knob failed",
@@ -379,6 +389,13 @@ func (p *DynamicPolicy) handleAdvisorResp(advisorResp *advisorsvc.ListAndWatchRe
 		}
 	}
 
+	// control knobs no longer advised are forgotten, and they are published again once advised
+	for key := range p.controlKnobEventStates {
+		if !handledControlKnobs.Has(key) {
+			delete(p.controlKnobEventStates, key)
+		}
+	}
+
 	resourcesMachineState, err := state.GenerateMachineStateFromPodEntries(p.state.GetMachineInfo(), podResourceEntries, p.state.GetMachineState(), p.state.GetReservedMemory())
 	if err != nil {
 		return fmt.Errorf("calculate machineState by updated pod entries failed with error: %v", err)
@@ -392,6 +409,52 @@ func (p *DynamicPolicy) handleAdvisorResp(advisorResp *advisorsvc.ListAndWatchRe
 	return nil
 }
 
+// controlKnobEventState is the value and error of the last published control knob
+type controlKnobEventState struct {
+	value string
+	err   string
+}
+
+// publishControlKnobEvent publishes the control knob handled by advice from memory-advisor only if
+// the value or error differs from the last published one, and returns the key of the control knob
+func (p *DynamicPolicy) publishControlKnobEvent(startTime time.Time, podUID, containerName, cgroupPath string,
+	controlKnobName, controlKnobValue string, err error,
+) string {
+	key := strings.Join([]string{podUID, containerName, cgroupPath, controlKnobName}, "/")
+	current := controlKnobEventState{value: controlKnobValue}
+	if err != nil {
+		current.err = err.Error()
+	}
+
+	if p.controlKnobEventStates == nil {
+		p.controlKnobEventStates = make(map[string]controlKnobEventState)
+	}
+	last, ok := p.controlKnobEventStates[key]
+	if ok && last == current {
+		return key
+	}
+	p.controlKnobEventStates[key] = current
+
+	event := eventbus.QRMAllocationEvent{
+		BaseEventImpl: eventbus.BaseEventImpl{
+			Time: startTime,
+		},
+		Cost:          time.Since(startTime),
+		PluginName:    p.name,
+		ResourceName:  string(v1.ResourceMemory),
+		PodUID:        podUID,
+		ContainerName: containerName,
+		CgroupPath:    cgroupPath,
+		Result:        fmt.Sprintf("%s=%s", controlKnobName, controlKnobValue),
+		Error:         current.err,
+	}
+	if ok {
+		event.OldResult = fmt.Sprintf("%s=%s", controlKnobName, last.value)
+	}
+	util.PublishAdvisorAllocationEvent(event)
+	return key
+}
+
 func (p *DynamicPolicy) handleAdvisorMemoryLimitInBytes(
 	_ *config.Configuration,
 	_ interface{},
@@ -505,6 +568,119 @@ func (p *DynamicPolicy) handleAdvisorDropCache(
 	return nil
 }
 
+func (p *DynamicPolicy) handleAdvisorMemoryHigh(
+	_ *config.Configuration,
+	_ interface{},
+	_ *dynamicconfig.DynamicAgentConfiguration,
+	emitter metrics.MetricEmitter,
+	metaServer *metaserver.MetaServer,
+	entryName, subEntryName string,
+	calculationInfo *advisorsvc.CalculationInfo, _ state.PodResourceEntries,
+) error {
+	memoryHigh := calculationInfo.CalculationResult.Values[string(memoryadvisor.ControlKnobKeyMemoryHigh)]
+	memoryHighInt64, err := strconv.ParseInt(memoryHigh, 10, 64)
+	if err != nil {
+		return fmt.Errorf("parse %s: %s failed with error: %v", memoryadvisor.ControlKnobKeyMemoryHigh, memoryHigh, err)
+	}
+
+	var absCGPath, setMemoryHighWorkName string
+	if calculationInfo.CgroupPath == "" {
+		setMemoryHighWorkName = util.GetContainerAsyncWorkName(entryName, subEntryName, memoryPluginAsyncWorkTopicSetMemoryHigh)
+		containerID, err := metaServer.GetContainerID(entryName, subEntryName)
+		if err != nil {
+			return fmt.Errorf("get container id of pod: %s container: %s failed with error: %v", entryName, subEntryName, err)
+		}
+		absCGPath, err = common.GetContainerAbsCgroupPath(common.CgroupSubsysMemory, entryName, containerID)
+		if err != nil {
+			return fmt.Errorf("GetContainerAbsCgroupPath failed with error: %v", err)
+		}
+	} else {
+		setMemoryHighWorkName = util.GetCgroupAsyncWorkName(calculationInfo.CgroupPath, memoryPluginAsyncWorkTopicSetMemoryHigh)
+		absCGPath = common.GetAbsCgroupPath(common.CgroupSubsysMemory, calculationInfo.CgroupPath)
+	}
+
+	err = p.asyncWorkers.AddWork(setMemoryHighWorkName,
+		&asyncworker.Work{
+			Fn:          cgroupmgr.SetMemoryHighWithAbsolutePath,
+			Params:      []interface{}{absCGPath, memoryHighInt64},
+			DeliveredAt: time.Now(),
+		}, asyncworker.DuplicateWorkPolicyOverride)
+	if err != nil {
+		return fmt.Errorf("add work: %s pod: %s container: %s cgroup: %s failed with error: %v",
+			setMemoryHighWorkName, entryName, subEntryName, absCGPath, err)
+	}
+
+	_ = emitter.StoreInt64(util.MetricNameMemoryHandleAdvisorMemoryHigh, memoryHighInt64,
+		metrics.MetricTypeNameRaw, metrics.ConvertMapToTags(map[string]string{
+			"entryName":    entryName,
+			"subEntryName": subEntryName,
+			"cgroupPath":   calculationInfo.CgroupPath,
+		})...)
+	return nil
+}
+
+// handleAdvisorMigrateNUMAMemory migrates pages of the container asynchronously, and the
+// migration shares the limited workers with page moving caused by numaset changes.
+func (p *DynamicPolicy) handleAdvisorMigrateNUMAMemory(
+	_ *config.Configuration,
+	_ interface{},
+	_ *dynamicconfig.DynamicAgentConfiguration,
+	emitter metrics.MetricEmitter,
+	metaServer *metaserver.MetaServer,
+	entryName, subEntryName string,
+	calculationInfo *advisorsvc.CalculationInfo, _ state.PodResourceEntries,
+) error {
+	advice := &types.NUMAMemoryMigrationAdvice{}
+	value := calculationInfo.CalculationResult.Values[string(memoryadvisor.ControlKnobKeyMigrateNUMAMemory)]
+	err := json.Unmarshal([]byte(value), advice)
+	if err != nil {
+		return fmt.Errorf("unmarshal %s: %s failed with error: %v",
+			memoryadvisor.ControlKnobKeyMigrateNUMAMemory, value, err)
+	}
+
+	sourceNUMAs, destNUMAs := machine.NewCPUSet(advice.SourceNUMAs...), machine.NewCPUSet(advice.DestNUMAs...)
+	if sourceNUMAs.IsEmpty() || destNUMAs.IsEmpty() {
+		return fmt.Errorf("invalid %s: %s with empty numas", memoryadvisor.ControlKnobKeyMigrateNUMAMemory, value)
+	}
+
+	movePagesWorkers, ok := p.asyncLimitedWorkersMap[memoryPluginAsyncWorkTopicMovePage]
+	if !ok {
+		return fmt.Errorf("asyncLimitedWorkers for %s not found", memoryPluginAsyncWorkTopicMovePage)
+	}
+
+	containerID, err := metaServer.GetContainerID(entryName, subEntryName)
+	if err != nil {
+		return fmt.Errorf("get container id of pod: %s container: %s failed with error: %v", entryName, subEntryName, err)
+	}
+
+	// pages are moved by move_pages if the amount is limited, since migrate_pages can't limit it
+	migratePagesWork := &asyncworker.Work{
+		Name:        util.GetContainerAsyncWorkName(entryName, subEntryName, memoryPluginAsyncWorkTopicMovePage),
+		UID:         uuid.NewUUID(),
+		Fn:          MigratePagesForContainer,
+		Params:      []interface{}{entryName, containerID, p.topology.NumNUMANodes, sourceNUMAs, destNUMAs},
+		DeliveredAt: time.Now(),
+	}
+	if advice.MaxBytes > 0 {
+		migratePagesWork.Fn = MovePagesForContainerWithLimit
+		migratePagesWork.Params = []interface{}{entryName, containerID, sourceNUMAs, destNUMAs, advice.MaxBytes}
+	}
+	err = movePagesWorkers.AddWork(migratePagesWork, asyncworker.DuplicateWorkPolicyDiscard)
+	if err != nil {
+		return fmt.Errorf("add work: %s pod: %s container: %s failed with error: %v",
+			migratePagesWork.Name, entryName, subEntryName, err)
+	}
+
+	general.Infof("migrate pages of pod: %s container: %s from numas: %s to numas: %s, max bytes: %d",
+		entryName, subEntryName, sourceNUMAs.String(), destNUMAs.String(), advice.MaxBytes)
+	_ = emitter.StoreInt64(util.MetricNameMemoryHandleAdvisorMigrateNUMAMemory, 1,
+		metrics.MetricTypeNameRaw, metrics.ConvertMapToTags(map[string]string{
+			"entryName":    entryName,
+			"subEntryName": subEntryName,
+		})...)
+	return nil
+}
+
 func (p *DynamicPolicy) handleAdvisorMemoryNUMAHeadroom(
 	_ *config.Configuration,
 	_ interface{},
@@ -78,6 +78,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/metaserver/external"
 	"github.com/kubewharf/katalyst-core/pkg/metrics"
 	"github.com/kubewharf/katalyst-core/pkg/util/asyncworker"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
 	"github.com/kubewharf/katalyst-core/pkg/util/machine"
 	"github.com/kubewharf/katalyst-core/pkg/util/qos"
 )
@@ -5043,3 +5044,48 @@ func TestSwitchBetweenAPIs(t *testing.T) {
 		})
 	}
 }
+
+func TestDynamicPolicy_publishControlKnobEvent(t *testing.T) {
+	t.Parallel()
+
+	pluginName := "TestDynamicPolicy_publishControlKnobEvent"
+	events := make(chan eventbus.QRMAllocationEvent, 10)
+	err := eventbus.GetDefaultEventBus().Subscribe(coreconsts.TopicNameQRMAllocation, pluginName, 10,
+		func(event interface{}) error {
+			if e := event.(eventbus.QRMAllocationEvent); e.PluginName == pluginName {
+				events <- e
+			}
+			return nil
+		})
+	require.NoError(t, err)
+
+	p := &DynamicPolicy{name: pluginName}
+	publish := func(value string, err error) {
+		p.publishControlKnobEvent(time.Now(), "pod-uid", "c", "", "memory_limit_in_bytes", value, err)
+	}
+	expectEvent := func(result, oldResult, errMsg string) {
+		select {
+		case event := <-events:
+			assert.Equal(t, result, event.Result)
+			assert.Equal(t, oldResult, event.OldResult)
+			assert.Equal(t, errMsg, event.Error)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for control knob event")
+		}
+	}
+
+	// unchanged values and errors are published only once
+	publish("100", nil)
+	publish("100", nil)
+	publish("200", nil)
+	publish("200", fmt.Errorf("failed"))
+	publish("200", fmt.Errorf("failed"))
+	expectEvent("memory_limit_in_bytes=100", "", "")
+	expectEvent("memory_limit_in_bytes=200", "memory_limit_in_bytes=100", "")
+	expectEvent("memory_limit_in_bytes=200", "memory_limit_in_bytes=200", "failed")
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected control knob event: %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -171,6 +171,14 @@ containerLoop:
 // sourceNUMAs to destNUMAs, which has more fine-grained locks than migrate_page.
 func MovePagesForContainer(ctx context.Context, podUID, containerId string,
 	sourceNUMAs, destNUMAs machine.CPUSet,
+) error {
+	return MovePagesForContainerWithLimit(ctx, podUID, containerId, sourceNUMAs, destNUMAs, 0)
+}
+
+// MovePagesForContainerWithLimit is the same as MovePagesForContainer, except that at most
+// maxBytes of memory on sourceNUMAs are migrated, and no limit is applied if maxBytes is 0.
+func MovePagesForContainerWithLimit(ctx context.Context, podUID, containerId string,
+	sourceNUMAs, destNUMAs machine.CPUSet, maxBytes int64,
 ) error {
 	sourceNUMAs = sourceNUMAs.Difference(destNUMAs)
 	if len(sourceNUMAs.ToSliceInt()) == 0 {
@@ -190,6 +198,7 @@ func MovePagesForContainer(ctx context.Context, podUID, containerId string,
 	startTime := time.Now()
 	logs := make([]eventbus.SyscallLog, 0)
 	var errList []error
+	leftBytes := maxBytes
 containerLoop:
 	for _, containerPidStr := range containerPids {
 		select {
@@ -198,6 +207,10 @@ containerLoop:
 		default:
 		}
 
+		if maxBytes > 0 && leftBytes <= 0 {
+			break
+		}
+
 		pid, err := strconv.Atoi(containerPidStr)
 		if err != nil {
 			errList = append(errList, fmt.Errorf("pod: %s, container: %s, pid: %s invalid ",
@@ -206,7 +219,9 @@ containerLoop:
 		}
 
 		start := time.Now()
-		if err = MovePagesForProcess(ctx, ProcDir, pid, sourceNUMAs.ToSliceInt(), destNUMAs.ToSliceInt()); err != nil {
+		movedBytes, err := movePagesForProcessWithLimit(ctx, ProcDir, pid, sourceNUMAs.ToSliceInt(), destNUMAs.ToSliceInt(), leftBytes)
+		leftBytes -= movedBytes
+		if err != nil {
 			errList = append(errList, fmt.Errorf("Move pages for pod: %s, container: %s, pid: %d failed: %v ",
 				podUID, containerId, pid, err))
 			continue
@@ -249,25 +264,39 @@ containerLoop:
 }
 
 func MovePagesForProcess(ctx context.Context, procDir string, pid int, srcNumas []int, dstNumas []int) error {
+	_, err := movePagesForProcessWithLimit(ctx, procDir, pid, srcNumas, dstNumas, 0)
+	return err
+}
+
+// movePagesForProcessWithLimit moves at most maxBytes of pages on srcNumas (no limit if maxBytes
+// is 0), and returns the bytes of pages to be moved.
+func movePagesForProcessWithLimit(ctx context.Context, procDir string, pid int, srcNumas []int, dstNumas []int,
+	maxBytes int64,
+) (int64, error) {
 	pidSmapsInfo, err := getProcessPageStats(procDir, pid)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if pidSmapsInfo == nil {
 		general.Warningf("get pid smaps info nil, procDir: %s pid: %d", procDir, pid)
-		return nil
+		return 0, nil
 	}
 
 	srcNumasBitSet, err := bitmask.NewBitMask(srcNumas...)
 	if err != nil {
-		return fmt.Errorf("failed to NewBitMask allowd numas %+v", srcNumas)
+		return 0, fmt.Errorf("failed to NewBitMask allowd numas %+v", srcNumas)
 	}
 
 	pagesMargin := GetNumaForPagesMaxEachTime
 	var pagesAdrr []uint64
+	var pagesSize []int64
 	var phyPagesAddr []uint64
+	var phyPagesBytes int64
 	var maxGetProcessPagesNuma int64
+	limitReached := func() bool {
+		return maxBytes > 0 && phyPagesBytes >= maxBytes
+	}
 
 	getPhyPagesOnSourceNumas := func() {
 		t0 := time.Now()
@@ -281,21 +310,31 @@ func MovePagesForProcess(ctx context.Context, procDir string, pid int, srcNumas
 		}
 
 		for i, n := range pagesNuma {
+			if limitReached() {
+				return
+			}
 			if srcNumasBitSet.IsSet(int(n)) {
 				pageAddr := pagesAdrr[i]
 				phyPagesAddr = append(phyPagesAddr, pageAddr)
+				phyPagesBytes += pagesSize[i]
 			}
 		}
 	}
 
+vmaLoop:
 	for _, vma := range pidSmapsInfo.vmas {
 		for addr := vma.start; addr < vma.end; addr += uint64(vma.pageSize) {
 			pagesAdrr = append(pagesAdrr, addr)
+			pagesSize = append(pagesSize, vma.pageSize)
 			pagesMargin--
 			if pagesMargin == 0 {
 				getPhyPagesOnSourceNumas()
 				pagesMargin = GetNumaForPagesMaxEachTime
 				pagesAdrr = pagesAdrr[:0]
+				pagesSize = pagesSize[:0]
+				if limitReached() {
+					break vmaLoop
+				}
 			}
 		}
 	}
@@ -303,23 +342,23 @@ func MovePagesForProcess(ctx context.Context, procDir string, pid int, srcNumas
 	general.Infof("getProcessPagesNuma pid: %d, timeCost max: %d ms\n", pid, maxGetProcessPagesNuma)
 
 	// handle left pagesAddr whose length less than GetNumaForPagesMaxEachTime
-	if len(pagesAdrr) > 0 {
+	if len(pagesAdrr) > 0 && !limitReached() {
 		getPhyPagesOnSourceNumas()
 	}
 
 	if len(phyPagesAddr) == 0 {
-		return nil
+		return 0, nil
 	}
 
 	// needless get getNumasFreePageRatio after each move_pages,
 	// only call getNumasFreePageRatio here is enough.
 	dstNumasFreeMemRatio, err := getNumasFreePageRatio(SystemNodeDir, dstNumas)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if len(dstNumasFreeMemRatio) == 0 {
-		return fmt.Errorf("pid: %d dstNumasFreeMemRatio is zero", pid)
+		return 0, fmt.Errorf("pid: %d dstNumasFreeMemRatio is zero", pid)
 	}
 
 	ratioTotal := 0
@@ -344,7 +383,7 @@ numaLoop:
 		pagesCount := len(phyPagesAddr) * ratio / ratioTotal
 		totalPages += pagesCount
 		if totalPages > len(phyPagesAddr) {
-			return fmt.Errorf("impossible, totalPages:%d greater than phyPagesAddr length: %d", totalPages, len(phyPagesAddr))
+			return 0, fmt.Errorf("impossible, totalPages:%d greater than phyPagesAddr length: %d", totalPages, len(phyPagesAddr))
 		}
 
 		numaCount++
@@ -363,7 +402,7 @@ numaLoop:
 		}
 	}
 
-	return utilerrors.NewAggregate(errList)
+	return phyPagesBytes, utilerrors.NewAggregate(errList)
 }
 
 func moveProcessPagesToOneNuma(ctx context.Context, pid int32, pagesAddr []uint64, dstNuma int) (err error) {
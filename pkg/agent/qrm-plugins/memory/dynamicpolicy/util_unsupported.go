@@ -37,3 +37,9 @@ func MovePagesForContainer(ctx context.Context, podUID, containerId string,
 ) error {
 	return fmt.Errorf("unsupported MovePagesForContainer")
 }
+
+func MovePagesForContainerWithLimit(ctx context.Context, podUID, containerId string,
+	sourceNUMAs, destNUMAs machine.CPUSet, maxBytes int64,
+) error {
+	return fmt.Errorf("unsupported MovePagesForContainerWithLimit")
+}
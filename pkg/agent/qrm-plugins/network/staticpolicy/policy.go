@@ -703,6 +703,7 @@ func (p *StaticPolicy) Allocate(ctx context.Context,
 		"netBandwidthReq(Mbps)", reqInt,
 		"netClassID", netClassID)
 
+	startTime := time.Now()
 	p.Lock()
 	defer func() {
 		if err := p.state.StoreState(); err != nil {
@@ -718,6 +719,7 @@ func (p *StaticPolicy) Allocate(ctx context.Context,
 			}
 			_ = p.emitter.StoreInt64(util.MetricNameAllocateFailed, 1, metrics.MetricTypeNameRaw, metricTags...)
 		}
+		util.PublishAllocationEvent(p.name, qosLevel, startTime, req, resp, err)
 	}()
 
 	emptyResponse := &pluginapi.ResourceAllocationResponse{
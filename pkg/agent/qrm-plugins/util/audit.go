@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	pluginapi "k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
+
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+)
+
+// PublishAllocationEvent publishes the allocation decision for a container to event bus,
+// so that audit sinks are able to correlate low-level cgroup writes with the decision.
+func PublishAllocationEvent(pluginName, qosLevel string, startTime time.Time,
+	req *pluginapi.ResourceRequest, resp *pluginapi.ResourceAllocationResponse, err error,
+) {
+	if req == nil {
+		return
+	}
+
+	action := eventbus.QRMAllocationActionAllocate
+	if existReallocAnno, _ := IsReallocation(req.Annotations); existReallocAnno || PodInplaceUpdateResizing(req) {
+		action = eventbus.QRMAllocationActionReallocate
+	}
+
+	event := eventbus.QRMAllocationEvent{
+		BaseEventImpl: eventbus.BaseEventImpl{
+			Time: startTime,
+		},
+		Cost:          time.Since(startTime),
+		PluginName:    pluginName,
+		ResourceName:  req.ResourceName,
+		Action:        action,
+		PodUID:        req.PodUid,
+		PodNamespace:  req.PodNamespace,
+		PodName:       req.PodName,
+		ContainerName: req.ContainerName,
+		QoSLevel:      qosLevel,
+		Request:       formatResourceRequests(req.ResourceRequests),
+	}
+	if resp != nil && resp.AllocationResult != nil {
+		event.Result = formatResourceAllocation(resp.AllocationResult.ResourceAllocation)
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+
+	_ = eventbus.GetDefaultEventBus().Publish(consts.TopicNameQRMAllocation, event)
+}
+
+// formatResourceRequests formats requests as sorted "name=quantity" pairs
+func formatResourceRequests(requests map[string]float64) string {
+	items := make([]string, 0, len(requests))
+	for name, quantity := range requests {
+		items = append(items, fmt.Sprintf("%s=%v", name, quantity))
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+// formatResourceAllocation formats allocation as sorted "name=result(quantity)" pairs
+func formatResourceAllocation(allocation map[string]*pluginapi.ResourceAllocationInfo) string {
+	items := make([]string, 0, len(allocation))
+	for name, info := range allocation {
+		if info == nil {
+			continue
+		}
+		items = append(items, fmt.Sprintf("%s=%s(%v)", name, info.AllocationResult, info.AllocatedQuantity))
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+// PublishAdvisorAllocationEvent publishes the allocation adjusted by advice from sysadvisor to event bus,
+// e.g. resizing of cpu pools or updating of memory control knobs.
+func PublishAdvisorAllocationEvent(event eventbus.QRMAllocationEvent) {
+	event.Action = eventbus.QRMAllocationActionAdvisor
+	_ = eventbus.GetDefaultEventBus().Publish(consts.TopicNameQRMAllocation, event)
+}
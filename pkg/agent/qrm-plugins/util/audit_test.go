@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pluginapi "k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
+
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+)
+
+func TestPublishAllocationEvent(t *testing.T) {
+	t.Parallel()
+
+	events := make(chan eventbus.QRMAllocationEvent, 10)
+	err := eventbus.GetDefaultEventBus().Subscribe(consts.TopicNameQRMAllocation, "TestPublishAllocationEvent", 10,
+		func(event interface{}) error {
+			events <- event.(eventbus.QRMAllocationEvent)
+			return nil
+		})
+	require.NoError(t, err)
+
+	req := &pluginapi.ResourceRequest{
+		PodUid:        "pod-uid",
+		PodNamespace:  "default",
+		PodName:       "pod",
+		ContainerName: "container",
+		ResourceName:  "cpu",
+		ResourceRequests: map[string]float64{
+			"cpu": 2,
+		},
+		Annotations: map[string]string{
+			PodAnnotationResourceReallocationKey: "true",
+		},
+	}
+	resp := &pluginapi.ResourceAllocationResponse{
+		AllocationResult: &pluginapi.ResourceAllocation{
+			ResourceAllocation: map[string]*pluginapi.ResourceAllocationInfo{
+				"cpu": {
+					AllocatedQuantity: 2,
+					AllocationResult:  "0-1",
+				},
+			},
+		},
+	}
+
+	PublishAllocationEvent("cpu_plugin", "shared_cores", time.Now(), req, resp, nil)
+	PublishAllocationEvent("cpu_plugin", "shared_cores", time.Now(), req, nil, fmt.Errorf("insufficient"))
+
+	select {
+	case event := <-events:
+		assert.Equal(t, eventbus.QRMAllocationActionReallocate, event.Action)
+		assert.Equal(t, "pod-uid", event.PodUID)
+		assert.Equal(t, "container", event.ContainerName)
+		assert.Equal(t, "cpu=2", event.Request)
+		assert.Equal(t, "cpu=0-1(2)", event.Result)
+		assert.Empty(t, event.Error)
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for allocation event")
+	}
+
+	select {
+	case event := <-events:
+		assert.Empty(t, event.Result)
+		assert.Equal(t, "insufficient", event.Error)
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for allocation event")
+	}
+}
@@ -52,6 +52,8 @@ const (
 	MetricNameMemoryHandleAdvisorCPUSetMems           = "memory_handle_advisor_cpuset_mems"
 	MetricNameMemoryHandlerAdvisorMemoryOffload       = "memory_handler_advisor_memory_offloading"
 	MetricNameMemoryHandlerAdvisorMemoryNUMAHeadroom  = "memory_handler_advisor_memory_numa_headroom"
+	MetricNameMemoryHandleAdvisorMemoryHigh           = "memory_handle_advisor_memory_high"
+	MetricNameMemoryHandleAdvisorMigrateNUMAMemory    = "memory_handle_advisor_migrate_numa_memory"
 	MetricNameMemoryOOMPriorityDeleteFailed           = "memory_oom_priority_delete_failed"
 	MetricNameMemoryOOMPriorityUpdateFailed           = "memory_oom_priority_update_failed"
 	MetricNameMemoryNumaBalance                       = "memory_handle_numa_balance"
@@ -104,6 +106,15 @@ const (
 	OCIPropertyNameCPUSetCPUs         = "CpusetCpus"
 	OCIPropertyNameCPUSetMems         = "CpusetMems"
 	OCIPropertyNameMemoryLimitInBytes = "MemoryLimitInBytes"
+
+	OCIPropertyNameMemoryHighInBytes = "MemoryHighInBytes"
+	OCIPropertyNameCPUShares         = "CpuShares"
+	OCIPropertyNameCPUQuota          = "CpuQuota"
+	OCIPropertyNameCPUPeriod         = "CpuPeriod"
+	// OCIPropertyNameHugepageLimits is formatted as comma separated <page size>:<limit in bytes>, e.g. 2MB:1073741824,1GB:0
+	OCIPropertyNameHugepageLimits = "HugepageLimits"
+	// OCIPropertyNameIOWeight is the io weight in cgroup v2 scale, i.e. [1, 10000]
+	OCIPropertyNameIOWeight = "IOWeight"
 )
 
 const (
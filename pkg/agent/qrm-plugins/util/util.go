@@ -30,6 +30,7 @@ import (
 
 	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
 	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
 	"github.com/kubewharf/katalyst-core/pkg/util/asyncworker"
 	"github.com/kubewharf/katalyst-core/pkg/util/general"
 	"github.com/kubewharf/katalyst-core/pkg/util/machine"
@@ -58,6 +59,8 @@ func GetQuantityFromResourceReq(req *pluginapi.ResourceRequest) (int, float64, e
 				return 0, 0, nil
 			}
 			return general.Max(int(math.Ceil(req.ResourceRequests[key])), 0), req.ResourceRequests[key], nil
+		case consts.ResourceDiskBandwidth:
+			return general.Max(int(math.Ceil(req.ResourceRequests[key])), 0), req.ResourceRequests[key], nil
 		default:
 			return 0, 0, fmt.Errorf("invalid request resource name: %s", key)
 		}
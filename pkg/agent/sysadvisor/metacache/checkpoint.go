@@ -18,6 +18,7 @@ package metacache
 
 import (
 	"encoding/json"
+	"time"
 
 	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager"
 	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager/checksum"
@@ -25,8 +26,38 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
 )
 
-var _ checkpointmanager.Checkpoint = &MetaCacheCheckpoint{}
+// MetaCacheCheckpointVersion is the version of checkpoint written by current metacache,
+// and it should be bumped with a new checkpoint type and a migration from the previous one
+// whenever the checkpoint layout or the semantics of its entries changes
+const MetaCacheCheckpointVersion = 1
 
+var (
+	_ checkpointmanager.Checkpoint = &metaCacheCheckpointVersion{}
+	_ checkpointmanager.Checkpoint = &MetaCacheCheckpoint{}
+	_ checkpointmanager.Checkpoint = &MetaCacheCheckpointV1{}
+)
+
+// metaCacheCheckpointVersion only decodes the version of checkpoint to decide which
+// checkpoint type should be used to restore it, and checkpoints without version are
+// regarded as version 0; checksum is left to be verified by the concrete checkpoint type
+type metaCacheCheckpointVersion struct {
+	Version int `json:"version"`
+}
+
+func (cp *metaCacheCheckpointVersion) MarshalCheckpoint() ([]byte, error) {
+	return json.Marshal(*cp)
+}
+
+func (cp *metaCacheCheckpointVersion) UnmarshalCheckpoint(blob []byte) error {
+	return json.Unmarshal(blob, cp)
+}
+
+func (cp *metaCacheCheckpointVersion) VerifyChecksum() error {
+	return nil
+}
+
+// MetaCacheCheckpoint is the checkpoint of version 0, and it must be kept unchanged
+// since the checksum is calculated with its type name and layout
 type MetaCacheCheckpoint struct {
 	PodEntries      types.PodEntries      `json:"pod_entries"`
 	PoolEntries     types.PoolEntries     `json:"pool_entries"`
@@ -65,3 +96,81 @@ func (cp *MetaCacheCheckpoint) VerifyChecksum() error {
 	cp.Checksum = ck
 	return err
 }
+
+// InferenceResultEntry stores a persisted inference result, and it's
+// only valid before Timestamp (unix nano) plus TTL
+type InferenceResultEntry struct {
+	Result    json.RawMessage `json:"result"`
+	Timestamp int64           `json:"timestamp"`
+	TTL       time.Duration   `json:"ttl"`
+}
+
+// IsFresh returns true if the inference result is still valid at the given time
+func (e *InferenceResultEntry) IsFresh(now time.Time) bool {
+	return e != nil && now.Before(time.Unix(0, e.Timestamp).Add(e.TTL))
+}
+
+// MetaCacheCheckpointV1 extends MetaCacheCheckpoint with entries used to warm start
+// advisors after restart, and the update time (unix nano) is recorded to judge
+// whether they are fresh enough to be reused
+type MetaCacheCheckpointV1 struct {
+	Version int `json:"version"`
+
+	PodEntries      types.PodEntries      `json:"pod_entries"`
+	PoolEntries     types.PoolEntries     `json:"pool_entries"`
+	RegionEntries   types.RegionEntries   `json:"region_entries"`
+	HeadroomEntries types.HeadroomEntries `json:"headroom_entries"`
+
+	HeadroomHistory     types.HeadroomHistory            `json:"headroom_history"`
+	RegulatorEntries    types.RegulatorEntries           `json:"regulator_entries"`
+	RegulatorUpdateTime int64                            `json:"regulator_update_time"`
+	InferenceResults    map[string]*InferenceResultEntry `json:"inference_results"`
+
+	Checksum checksum.Checksum `json:"checksum"`
+}
+
+func NewMetaCacheCheckpointV1() *MetaCacheCheckpointV1 {
+	return &MetaCacheCheckpointV1{
+		Version:          1,
+		PodEntries:       make(types.PodEntries),
+		PoolEntries:      make(types.PoolEntries),
+		RegionEntries:    make(types.RegionEntries),
+		HeadroomEntries:  make(types.HeadroomEntries),
+		HeadroomHistory:  make(types.HeadroomHistory),
+		RegulatorEntries: make(types.RegulatorEntries),
+		InferenceResults: make(map[string]*InferenceResultEntry),
+	}
+}
+
+// MarshalCheckpoint returns marshaled checkpoint
+func (cp *MetaCacheCheckpointV1) MarshalCheckpoint() ([]byte, error) {
+	// make sure checksum wasn't set before so it doesn't affect output checksum
+	cp.Checksum = 0
+	cp.Checksum = checksum.New(cp)
+	return json.Marshal(*cp)
+}
+
+// UnmarshalCheckpoint tries to unmarshal passed bytes to checkpoint
+func (cp *MetaCacheCheckpointV1) UnmarshalCheckpoint(blob []byte) error {
+	return json.Unmarshal(blob, cp)
+}
+
+// VerifyChecksum verifies that current checksum of checkpoint is valid
+func (cp *MetaCacheCheckpointV1) VerifyChecksum() error {
+	ck := cp.Checksum
+	cp.Checksum = 0
+	err := ck.Verify(cp)
+	cp.Checksum = ck
+	return err
+}
+
+// migrateMetaCacheCheckpointV0 converts checkpoint of version 0 to version 1, and headroom
+// history is left empty since we can't tell whether the headroom entries are still fresh
+func migrateMetaCacheCheckpointV0(cp *MetaCacheCheckpoint) *MetaCacheCheckpointV1 {
+	migrated := NewMetaCacheCheckpointV1()
+	migrated.PodEntries = cp.PodEntries
+	migrated.PoolEntries = cp.PoolEntries
+	migrated.RegionEntries = cp.RegionEntries
+	migrated.HeadroomEntries = cp.HeadroomEntries
+	return migrated
+}
@@ -30,7 +30,7 @@ import (
 func TestCheckpoint(t *testing.T) {
 	t.Parallel()
 
-	cp := NewMetaCacheCheckpoint()
+	cp := NewMetaCacheCheckpointV1()
 	cp.PoolEntries = map[string]*types.PoolInfo{
 		"p1": {
 			PoolName: "p1",
@@ -70,10 +70,35 @@ func TestCheckpoint(t *testing.T) {
 	checkpoint, err := cp.MarshalCheckpoint()
 	assert.NoError(t, err)
 
-	cp = NewMetaCacheCheckpoint()
+	cp = NewMetaCacheCheckpointV1()
 	err = cp.UnmarshalCheckpoint(checkpoint)
 	assert.NoError(t, err)
 
 	err = cp.VerifyChecksum()
 	assert.NoError(t, err)
 }
+
+func TestLegacyCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	// generated by MetaCacheCheckpoint.MarshalCheckpoint before version was introduced
+	legacy := []byte(`{"pod_entries":{},"pool_entries":{},"region_entries":{},` +
+		`"headroom_entries":{"cpu":{"numa_headroom":{"0":10},"total_headroom":10}},"checksum":1622592662}`)
+
+	version := &metaCacheCheckpointVersion{}
+	assert.NoError(t, version.UnmarshalCheckpoint(legacy))
+	assert.Equal(t, 0, version.Version)
+
+	cp := NewMetaCacheCheckpoint()
+	assert.NoError(t, cp.UnmarshalCheckpoint(legacy))
+	assert.NoError(t, cp.VerifyChecksum())
+
+	migrated := migrateMetaCacheCheckpointV0(cp)
+	assert.Equal(t, MetaCacheCheckpointVersion, migrated.Version)
+	assert.Equal(t, 10., migrated.HeadroomEntries["cpu"].TotalHeadroom)
+	assert.Empty(t, migrated.HeadroomHistory)
+
+	current := NewMetaCacheCheckpointV1()
+	assert.NoError(t, current.UnmarshalCheckpoint(legacy))
+	assert.Error(t, current.VerifyChecksum())
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package metacache
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"reflect"
@@ -48,8 +49,15 @@ import (
 const (
 	stateFileName             string = "sys_advisor_state"
 	storeStateWarningDuration        = 2 * time.Second
+
+	// warmStartStoreInterval is the min interval to store state triggered by updates of warm
+	// start entries, since they are updated every advisor cycle; updates within the interval
+	// are kept in memory and stored along with the next state storing
+	warmStartStoreInterval = 30 * time.Second
 )
 
+var errUnknownCheckpointVersion = fmt.Errorf("unknown checkpoint version")
+
 // metric names for metacache
 const (
 	metricMetaCacheStoreStateDuration = "metacache_store_state_duration"
@@ -74,6 +82,12 @@ type MetaReader interface {
 
 	// GetHeadroomEntries returns a HeadroomInfo for specified resourceName like cpu, memory
 	GetHeadroomEntries(resourceName string) (*types.HeadroomInfo, bool)
+	// GetHeadroomHistory returns headroom records for specified resourceName updated within warm start ttl
+	// in time order, and it's used to report headroom before advisors are updated after restart
+	GetHeadroomHistory(resourceName string) []types.HeadroomRecord
+
+	// GetRegulatorInfo returns a RegulatorInfo copy by key only if it's updated within warm start ttl
+	GetRegulatorInfo(key string) (*types.RegulatorInfo, bool)
 	// RangeRegionInfo applies a function to every regionName, regionInfo set.
 	// If f returns false, range stops the iteration.
 	RangeRegionInfo(f func(regionName string, regionInfo *types.RegionInfo) bool)
@@ -82,6 +96,9 @@ type MetaReader interface {
 	GetFilteredInferenceResult(filterFunc func(result interface{}) (interface{}, error), modelName string) (interface{}, error)
 	// GetInferenceResult gets specified model inference result
 	GetInferenceResult(modelName string) (interface{}, error)
+	// GetPersistedInferenceResult unmarshals persisted inference result into the given
+	// pointer, and returns false if it doesn't exist or exceeds its ttl
+	GetPersistedInferenceResult(modelName string, result interface{}) (bool, error)
 	// GetModelInput gets model input, the dimension of model input is : "container", "numa", "node"
 	GetModelInput(metricDimension string) (metric map[string]interface{}, err error)
 
@@ -126,9 +143,14 @@ type MetaWriter interface {
 	SetRegionInfo(regionName string, regionInfo *types.RegionInfo) error
 	// SetHeadroomEntries store the headroomInfo of resourceName
 	SetHeadroomEntries(resourceName string, headroomInfo *types.HeadroomInfo) error
+	// SetRegulatorEntries overwrites the whole regulator entries
+	SetRegulatorEntries(entries types.RegulatorEntries) error
 
 	// SetInferenceResult sets specified model inference result
 	SetInferenceResult(modelName string, result interface{}) error
+	// SetInferenceResultWithTTL sets specified model inference result, and persists
+	// it into checkpoint to be reused within ttl after restart
+	SetInferenceResultWithTTL(modelName string, result interface{}, ttl time.Duration) error
 
 	// SetModelInput sets model input, the dimension of model input is : "container", "numa", "node"
 	SetModelInput(metricDimension string, metric map[string]interface{}) error
@@ -164,15 +186,29 @@ type MetaCacheImp struct {
 	regionMutex   sync.RWMutex
 
 	headroomEntries types.HeadroomEntries
+	headroomHistory types.HeadroomHistory
 	headroomMutex   sync.RWMutex
 
+	regulatorEntries    types.RegulatorEntries
+	regulatorUpdateTime time.Time
+	regulatorMutex      sync.RWMutex
+
+	// warmStartTTL is the max age of restored entries to be reused
+	warmStartTTL time.Duration
+
 	checkpointManager checkpointmanager.CheckpointManager
 	checkpointName    string
 
+	// lastStoreStateTime is used to throttle state storing triggered by warm start entries
+	lastStoreStateTime     time.Time
+	warmStartStoreInterval time.Duration
+	storeStateMutex        sync.Mutex
+
 	emitter metrics.MetricEmitter
 
-	modelToResult map[string]interface{}
-	modelMutex    sync.RWMutex
+	modelToResult    map[string]interface{}
+	persistedResults map[string]*InferenceResultEntry
+	modelMutex       sync.RWMutex
 
 	modelInput      map[string]map[string]interface{}
 	modelInputMutex sync.RWMutex
@@ -210,10 +246,15 @@ func NewMetaCacheImp(conf *config.Configuration, emitterPool metricspool.Metrics
 		podEntries:               make(types.PodEntries),
 		poolEntries:              make(types.PoolEntries),
 		regionEntries:            make(types.RegionEntries),
+		headroomHistory:          make(types.HeadroomHistory),
+		regulatorEntries:         make(types.RegulatorEntries),
+		warmStartTTL:             conf.GenericSysAdvisorConfiguration.StateWarmStartTTL,
 		checkpointManager:        checkpointManager,
 		checkpointName:           stateFileName,
+		warmStartStoreInterval:   warmStartStoreInterval,
 		emitter:                  emitter,
 		modelToResult:            make(map[string]interface{}),
+		persistedResults:         make(map[string]*InferenceResultEntry),
 		modelInput:               make(map[string]map[string]interface{}),
 		featureGates:             make(map[string]*advisorsvc.FeatureGate),
 		containerCreateTimestamp: make(map[string]int64),
@@ -303,6 +344,33 @@ func (mc *MetaCacheImp) GetHeadroomEntries(resourceName string) (*types.Headroom
 	return headroomInfo.Clone(), ok
 }
 
+func (mc *MetaCacheImp) GetHeadroomHistory(resourceName string) []types.HeadroomRecord {
+	mc.headroomMutex.RLock()
+	defer mc.headroomMutex.RUnlock()
+
+	var records []types.HeadroomRecord
+	for _, record := range mc.headroomHistory[resourceName] {
+		if mc.isFresh(fromUnixNano(record.Timestamp)) {
+			records = append(records, types.HeadroomRecord{
+				HeadroomInfo: *record.HeadroomInfo.Clone(),
+				Timestamp:    record.Timestamp,
+			})
+		}
+	}
+	return records
+}
+
+func (mc *MetaCacheImp) GetRegulatorInfo(key string) (*types.RegulatorInfo, bool) {
+	mc.regulatorMutex.RLock()
+	defer mc.regulatorMutex.RUnlock()
+
+	if !mc.isFresh(mc.regulatorUpdateTime) {
+		return nil, false
+	}
+	regulatorInfo, ok := mc.regulatorEntries[key]
+	return regulatorInfo.Clone(), ok
+}
+
 // GetFilteredInferenceResult gets specified model inference result with filter function
 // whether it returns a deep copied result depends on the implementation of filterFunc
 func (mc *MetaCacheImp) GetFilteredInferenceResult(filterFunc func(result interface{}) (interface{}, error),
@@ -328,6 +396,22 @@ func (mc *MetaCacheImp) GetInferenceResult(modelName string) (interface{}, error
 	return mc.GetFilteredInferenceResult(nil, modelName)
 }
 
+// GetPersistedInferenceResult unmarshals persisted inference result into the given pointer
+func (mc *MetaCacheImp) GetPersistedInferenceResult(modelName string, result interface{}) (bool, error) {
+	mc.modelMutex.RLock()
+	defer mc.modelMutex.RUnlock()
+
+	entry, ok := mc.persistedResults[modelName]
+	if !ok || !entry.IsFresh(time.Now()) {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(entry.Result, result); err != nil {
+		return false, fmt.Errorf("unmarshal persisted result for model: %s failed: %v", modelName, err)
+	}
+	return true, nil
+}
+
 // GetModelInput gets model input, the dimension of model input is : "container", "numa", "node"
 func (mc *MetaCacheImp) GetModelInput(metricDimension string) (map[string]interface{}, error) {
 	mc.modelInputMutex.RLock()
@@ -598,6 +682,38 @@ func (mc *MetaCacheImp) SetInferenceResult(modelName string, result interface{})
 	return nil
 }
 
+// SetInferenceResultWithTTL sets specified model inference result and persists it
+func (mc *MetaCacheImp) SetInferenceResultWithTTL(modelName string, result interface{}, ttl time.Duration) error {
+	if result == nil {
+		return fmt.Errorf("nil result")
+	} else if ttl <= 0 {
+		return mc.SetInferenceResult(modelName, result)
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshal result for model: %s failed: %v", modelName, err)
+	}
+
+	mc.modelMutex.Lock()
+	defer mc.modelMutex.Unlock()
+
+	mc.modelToResult[modelName] = result
+
+	// copy on write, since persisted results may be read by storeState triggered by other writers
+	persistedResults := make(map[string]*InferenceResultEntry, len(mc.persistedResults)+1)
+	for name, entry := range mc.persistedResults {
+		persistedResults[name] = entry
+	}
+	persistedResults[modelName] = &InferenceResultEntry{
+		Result:    raw,
+		Timestamp: time.Now().UnixNano(),
+		TTL:       ttl,
+	}
+	mc.persistedResults = persistedResults
+	return mc.storeWarmStartState()
+}
+
 // SetModelInput sets model input
 func (mc *MetaCacheImp) SetModelInput(metricDimension string, metric map[string]interface{}) error {
 	mc.modelInputMutex.Lock()
@@ -627,20 +743,73 @@ func (mc *MetaCacheImp) SetHeadroomEntries(resourceName string, headroomInfo *ty
 			mc.headroomEntries = make(map[string]*types.HeadroomInfo)
 		}
 		mc.headroomEntries[resourceName] = headroomInfo.Clone()
+		mc.appendHeadroomHistory(resourceName, headroomInfo, time.Now())
 	}
-	return mc.storeState()
+	return mc.storeWarmStartState()
+}
+
+func (mc *MetaCacheImp) SetRegulatorEntries(entries types.RegulatorEntries) error {
+	mc.regulatorMutex.Lock()
+	defer mc.regulatorMutex.Unlock()
+
+	mc.regulatorEntries = entries.Clone()
+	mc.regulatorUpdateTime = time.Now()
+	return mc.storeWarmStartState()
 }
 
 /*
 	other helper functions
 */
 
+// appendHeadroomHistory appends a headroom record and drops the ones out of warm start ttl,
+// and the history is copied on write since it may be read by storeState triggered by other writers
+func (mc *MetaCacheImp) appendHeadroomHistory(resourceName string, headroomInfo *types.HeadroomInfo, now time.Time) {
+	if mc.warmStartTTL <= 0 {
+		return
+	}
+
+	records := make([]types.HeadroomRecord, 0, len(mc.headroomHistory[resourceName])+1)
+	for _, record := range mc.headroomHistory[resourceName] {
+		if now.Sub(fromUnixNano(record.Timestamp)) < mc.warmStartTTL {
+			records = append(records, record)
+		}
+	}
+	records = append(records, types.HeadroomRecord{
+		HeadroomInfo: *headroomInfo.Clone(),
+		Timestamp:    now.UnixNano(),
+	})
+
+	headroomHistory := make(types.HeadroomHistory, len(mc.headroomHistory)+1)
+	for name, history := range mc.headroomHistory {
+		headroomHistory[name] = history
+	}
+	headroomHistory[resourceName] = records
+	mc.headroomHistory = headroomHistory
+}
+
+// storeWarmStartState stores state for updates of warm start entries, and it's skipped
+// if state has been stored within warmStartStoreInterval
+func (mc *MetaCacheImp) storeWarmStartState() error {
+	mc.storeStateMutex.Lock()
+	lastStoreStateTime := mc.lastStoreStateTime
+	mc.storeStateMutex.Unlock()
+
+	if time.Since(lastStoreStateTime) < mc.warmStartStoreInterval {
+		return nil
+	}
+	return mc.storeState()
+}
+
 func (mc *MetaCacheImp) storeState() error {
-	checkpoint := NewMetaCacheCheckpoint()
+	checkpoint := NewMetaCacheCheckpointV1()
 	checkpoint.PodEntries = mc.podEntries
 	checkpoint.PoolEntries = mc.poolEntries
 	checkpoint.RegionEntries = mc.regionEntries
 	checkpoint.HeadroomEntries = mc.headroomEntries
+	checkpoint.HeadroomHistory = mc.headroomHistory
+	checkpoint.RegulatorEntries = mc.regulatorEntries
+	checkpoint.RegulatorUpdateTime = unixNano(mc.regulatorUpdateTime)
+	checkpoint.InferenceResults = mc.persistedResults
 
 	startTime := time.Now()
 	defer func(t time.Time) {
@@ -657,17 +826,25 @@ func (mc *MetaCacheImp) storeState() error {
 	}
 	klog.Infof("[metacache] store state succeeded")
 
+	mc.storeStateMutex.Lock()
+	mc.lastStoreStateTime = startTime
+	mc.storeStateMutex.Unlock()
+
 	return nil
 }
 
 func (mc *MetaCacheImp) restoreState() error {
-	checkpoint := NewMetaCacheCheckpoint()
+	checkpoint, migrated, err := mc.getCheckpoint()
 
 	foundAndSkippedStateCorruption := false
-	if err := mc.checkpointManager.GetCheckpoint(mc.checkpointName, checkpoint); err != nil {
+	if err != nil {
 		if err == errors.ErrCheckpointNotFound {
 			// create a new store state
-			klog.Infof("[metacache] checkpoint %v doesn't exist, create it", mc.checkpointName, err)
+			klog.Infof("[metacache] checkpoint %v doesn't exist, create it", mc.checkpointName)
+			return mc.storeState()
+		} else if err == errUnknownCheckpointVersion {
+			// the checkpoint is written by a newer version, and we can't tell how to restore it
+			klog.Warningf("[metacache] checkpoint %v is incompatible, discard it", mc.checkpointName)
 			return mc.storeState()
 		} else if err == errors.ErrCorruptCheckpoint {
 			if !mc.skipStateCorruption {
@@ -684,10 +861,23 @@ func (mc *MetaCacheImp) restoreState() error {
 	mc.poolEntries = checkpoint.PoolEntries
 	mc.regionEntries = checkpoint.RegionEntries
 	mc.headroomEntries = checkpoint.HeadroomEntries
+	if checkpoint.HeadroomHistory != nil {
+		mc.headroomHistory = checkpoint.HeadroomHistory
+	}
+	if checkpoint.RegulatorEntries != nil {
+		mc.regulatorEntries = checkpoint.RegulatorEntries
+		mc.regulatorUpdateTime = fromUnixNano(checkpoint.RegulatorUpdateTime)
+	}
+	if checkpoint.InferenceResults != nil {
+		mc.persistedResults = checkpoint.InferenceResults
+	}
 
 	if foundAndSkippedStateCorruption {
 		klog.Infof("[metacache] checkpoint %v recovery corrupt, create it", mc.checkpointName)
 		return mc.storeState()
+	} else if migrated {
+		klog.Infof("[metacache] checkpoint %v is migrated to version %v", mc.checkpointName, MetaCacheCheckpointVersion)
+		return mc.storeState()
 	}
 
 	klog.Infof("[metacache] restore state succeeded")
@@ -695,6 +885,47 @@ func (mc *MetaCacheImp) restoreState() error {
 	return nil
 }
 
+// getCheckpoint gets checkpoint with the type matching its version, and migrates it to current version
+// if it's written by a previous version; the checkpoint is still returned if it's corrupted
+func (mc *MetaCacheImp) getCheckpoint() (checkpoint *MetaCacheCheckpointV1, migrated bool, err error) {
+	version := &metaCacheCheckpointVersion{}
+	if err := mc.checkpointManager.GetCheckpoint(mc.checkpointName, version); err != nil {
+		return NewMetaCacheCheckpointV1(), false, err
+	}
+
+	switch version.Version {
+	case 0:
+		checkpointV0 := NewMetaCacheCheckpoint()
+		err = mc.checkpointManager.GetCheckpoint(mc.checkpointName, checkpointV0)
+		return migrateMetaCacheCheckpointV0(checkpointV0), true, err
+	case 1:
+		checkpoint = NewMetaCacheCheckpointV1()
+		err = mc.checkpointManager.GetCheckpoint(mc.checkpointName, checkpoint)
+		return checkpoint, false, err
+	default:
+		return nil, false, errUnknownCheckpointVersion
+	}
+}
+
+// isFresh returns true if entries updated at the given time can be reused for warm start
+func (mc *MetaCacheImp) isFresh(updateTime time.Time) bool {
+	return mc.warmStartTTL > 0 && !updateTime.IsZero() && time.Since(updateTime) < mc.warmStartTTL
+}
+
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnixNano(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
 func (mc *MetaCacheImp) setContainerCreateTimestamp(podUID, containerName string, timestamp int64) {
 	mc.containerCreateTimestamp[fmt.Sprintf("%s/%s", podUID, containerName)] = timestamp
 }
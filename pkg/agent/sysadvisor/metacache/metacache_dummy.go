@@ -26,7 +26,9 @@ func NewDummyMetaCacheImp() *MetaCacheImp {
 		podEntries:               make(types.PodEntries),
 		poolEntries:              make(types.PoolEntries),
 		regionEntries:            make(types.RegionEntries),
+		regulatorEntries:         make(types.RegulatorEntries),
 		modelToResult:            make(map[string]interface{}),
+		persistedResults:         make(map[string]*InferenceResultEntry),
 		containerCreateTimestamp: make(map[string]int64),
 		emitter:                  metrics.DummyMetrics{},
 	}
@@ -18,6 +18,7 @@ package metacache
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"reflect"
 	"testing"
@@ -29,7 +30,9 @@ import (
 	borweinconsts "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/models/borwein/consts"
 	borweinutils "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/models/borwein/utils"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config"
 	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	metricspool "github.com/kubewharf/katalyst-core/pkg/metrics/metrics-pool"
 )
 
 func TestMetaCacheImp_GetFilteredInferenceResult(t *testing.T) {
@@ -297,3 +300,152 @@ func TestRangeAndDeleteContainerWithSafeTime(t *testing.T) {
 	require.Equal(t, 0, len(mc.podEntries), "failed to delete container before safe time")
 	require.Equal(t, 0, len(mc.containerCreateTimestamp), "failed to delete container create timestamp before safe time")
 }
+
+func TestMetaCacheImp_WarmStart(t *testing.T) {
+	t.Parallel()
+
+	newMetaCache := func(dir string, ttl time.Duration) (*MetaCacheImp, error) {
+		conf := config.NewConfiguration()
+		conf.GenericSysAdvisorConfiguration.StateFileDirectory = dir
+		conf.GenericSysAdvisorConfiguration.StateWarmStartTTL = ttl
+		return NewMetaCacheImp(conf, metricspool.DummyMetricsEmitterPool{}, nil)
+	}
+	ci := &types.ContainerInfo{PodUID: "pod1", ContainerName: "c1"}
+	headroom := &types.HeadroomInfo{TotalHeadroom: 10, NUMAHeadroom: map[int]float64{0: 4, 1: 6}}
+	regulatorKey := types.GetRegulatorKey("share-1", types.CPUProvisionPolicyCanonical, "non-reclaimed-cpu-requirement")
+	regulatorInfo := &types.RegulatorInfo{LatestValue: 8, LatestRampDownTime: time.Now().UnixNano()}
+
+	t.Run("reuse fresh entries", func(t *testing.T) {
+		t.Parallel()
+
+		dir, err := ioutil.TempDir("", "mc-test-warm-start")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		mc, err := newMetaCache(dir, time.Minute)
+		require.NoError(t, err)
+		mc.warmStartStoreInterval = 0
+		require.NoError(t, mc.AddContainer("pod1", "c1", ci))
+		require.NoError(t, mc.SetHeadroomEntries("cpu", headroom))
+		require.NoError(t, mc.SetHeadroomEntries("cpu", &types.HeadroomInfo{TotalHeadroom: 8, NUMAHeadroom: map[int]float64{0: 4, 1: 4}}))
+		require.NoError(t, mc.SetRegulatorEntries(types.RegulatorEntries{regulatorKey: regulatorInfo}))
+		require.NoError(t, mc.SetInferenceResultWithTTL("fresh", []int{1, 2}, time.Hour))
+		require.NoError(t, mc.SetInferenceResultWithTTL("expired", []int{3}, time.Nanosecond))
+		require.NoError(t, mc.SetInferenceResultWithTTL("memory-only", []int{4}, 0))
+
+		restored, err := newMetaCache(dir, time.Minute)
+		require.NoError(t, err)
+
+		_, ok := restored.GetContainerInfo("pod1", "c1")
+		require.True(t, ok)
+		history := restored.GetHeadroomHistory("cpu")
+		require.Equal(t, 2, len(history))
+		require.Equal(t, *headroom, history[0].HeadroomInfo)
+		require.Equal(t, 8., history[1].TotalHeadroom)
+		gotRegulator, ok := restored.GetRegulatorInfo(regulatorKey)
+		require.True(t, ok)
+		require.Equal(t, regulatorInfo, gotRegulator)
+
+		var result []int
+		found, err := restored.GetPersistedInferenceResult("fresh", &result)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, []int{1, 2}, result)
+		found, err = restored.GetPersistedInferenceResult("expired", &result)
+		require.NoError(t, err)
+		require.False(t, found)
+		found, err = restored.GetPersistedInferenceResult("memory-only", &result)
+		require.NoError(t, err)
+		require.False(t, found)
+		_, err = restored.GetInferenceResult("fresh")
+		require.Error(t, err)
+
+		disabled, err := newMetaCache(dir, 0)
+		require.NoError(t, err)
+		require.Empty(t, disabled.GetHeadroomHistory("cpu"))
+		_, ok = disabled.GetRegulatorInfo(regulatorKey)
+		require.False(t, ok)
+		_, ok = disabled.GetHeadroomEntries("cpu")
+		require.True(t, ok)
+	})
+
+	t.Run("discard incompatible entries", func(t *testing.T) {
+		t.Parallel()
+
+		dir, err := ioutil.TempDir("", "mc-test-warm-start-version")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		checkpointManager, err := checkpointmanager.NewCheckpointManager(dir)
+		require.NoError(t, err)
+		cp := NewMetaCacheCheckpointV1()
+		cp.Version = MetaCacheCheckpointVersion + 1
+		cp.PodEntries = types.PodEntries{"pod1": {"c1": ci}}
+		cp.RegulatorEntries = types.RegulatorEntries{regulatorKey: regulatorInfo}
+		cp.RegulatorUpdateTime = time.Now().UnixNano()
+		require.NoError(t, checkpointManager.CreateCheckpoint(stateFileName, cp))
+
+		restored, err := newMetaCache(dir, time.Minute)
+		require.NoError(t, err)
+		_, ok := restored.GetContainerInfo("pod1", "c1")
+		require.False(t, ok)
+		_, ok = restored.GetRegulatorInfo(regulatorKey)
+		require.False(t, ok)
+
+		current := NewMetaCacheCheckpointV1()
+		require.NoError(t, checkpointManager.GetCheckpoint(stateFileName, current))
+		require.Equal(t, MetaCacheCheckpointVersion, current.Version)
+	})
+
+	t.Run("throttle storing warm start entries", func(t *testing.T) {
+		t.Parallel()
+
+		dir, err := ioutil.TempDir("", "mc-test-warm-start-throttle")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		mc, err := newMetaCache(dir, time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, mc.SetRegulatorEntries(types.RegulatorEntries{regulatorKey: regulatorInfo}))
+
+		restored, err := newMetaCache(dir, time.Minute)
+		require.NoError(t, err)
+		_, ok := restored.GetRegulatorInfo(regulatorKey)
+		require.False(t, ok)
+
+		// warm start entries are stored along with other entries
+		require.NoError(t, mc.AddContainer("pod1", "c1", ci))
+		restored, err = newMetaCache(dir, time.Minute)
+		require.NoError(t, err)
+		_, ok = restored.GetRegulatorInfo(regulatorKey)
+		require.True(t, ok)
+	})
+
+	t.Run("restore legacy checkpoint", func(t *testing.T) {
+		t.Parallel()
+
+		dir, err := ioutil.TempDir("", "mc-test-warm-start-legacy")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		checkpointManager, err := checkpointmanager.NewCheckpointManager(dir)
+		require.NoError(t, err)
+		cp := NewMetaCacheCheckpoint()
+		cp.PodEntries = types.PodEntries{"pod1": {"c1": ci}}
+		cp.HeadroomEntries = types.HeadroomEntries{"cpu": headroom}
+		require.NoError(t, checkpointManager.CreateCheckpoint(stateFileName, cp))
+
+		restored, err := newMetaCache(dir, time.Minute)
+		require.NoError(t, err)
+		_, ok := restored.GetContainerInfo("pod1", "c1")
+		require.True(t, ok)
+		_, ok = restored.GetHeadroomEntries("cpu")
+		require.True(t, ok)
+		require.Empty(t, restored.GetHeadroomHistory("cpu"))
+
+		// the legacy checkpoint should be migrated to current one
+		current := NewMetaCacheCheckpointV1()
+		require.NoError(t, checkpointManager.GetCheckpoint(stateFileName, current))
+		require.Equal(t, MetaCacheCheckpointVersion, current.Version)
+	})
+}
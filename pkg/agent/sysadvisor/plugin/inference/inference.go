@@ -30,6 +30,7 @@ import (
 	genericinput "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/modelinputfetcher/generic"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/modelresultfetcher"
 	borweinresult "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/modelresultfetcher/borwein"
+	localresult "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/modelresultfetcher/local"
 	"github.com/kubewharf/katalyst-core/pkg/config"
 	metricemitter "github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/metric-emitter"
 	"github.com/kubewharf/katalyst-core/pkg/config/generic"
@@ -44,6 +45,8 @@ func init() {
 		genericinput.NewGenericModelInputFetcher)
 	modelresultfetcher.RegisterModelResultFetcherInitFunc(borweinresult.BorweinModelResultFetcherName,
 		borweinresult.NewBorweinModelResultFetcher)
+	modelresultfetcher.RegisterModelResultFetcherInitFunc(localresult.LocalModelResultFetcherName,
+		localresult.NewLocalModelResultFetcher)
 }
 
 type InferencePlugin struct {
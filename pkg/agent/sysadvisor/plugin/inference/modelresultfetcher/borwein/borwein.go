@@ -90,6 +90,7 @@ type BorweinModelResultFetcher struct {
 	nodeFeatureNames                   []string          // handled by GetNodeFeature
 	containerFeatureNames              []string          // handled by GetContainerFeature
 	modelNameToInferenceSvcSockAbsPath map[string]string // map modelName to inference server sock path
+	resultTTL                          time.Duration
 
 	emitter metrics.MetricEmitter
 
@@ -100,6 +101,8 @@ type BorweinModelResultFetcher struct {
 func (bmrf *BorweinModelResultFetcher) FetchModelResult(ctx context.Context, metaReader metacache.MetaReader,
 	metaWriter metacache.MetaWriter, metaServer *metaserver.MetaServer,
 ) error {
+	bmrf.warmStartModelResults(metaReader, metaWriter)
+
 	bmrf.clientLock.RLock()
 	if len(bmrf.modelNameToInferenceSvcClient) == 0 {
 		bmrf.clientLock.RUnlock()
@@ -164,7 +167,8 @@ func (bmrf *BorweinModelResultFetcher) FetchModelResult(ctx context.Context, met
 				return
 			}
 
-			err = metaWriter.SetInferenceResult(borweinutils.GetInferenceResultKey(modelName), borweinInferenceResults)
+			err = metaWriter.SetInferenceResultWithTTL(borweinutils.GetInferenceResultKey(modelName),
+				borweinInferenceResults, bmrf.resultTTL)
 			if err != nil {
 				_ = bmrf.emitter.StoreInt64(metricSetInferenceResultFailed, 1, metrics.MetricTypeNameRaw)
 				errCh <- fmt.Errorf("SetInferenceResult from model: %s failed with error: %v", modelName, err)
@@ -184,6 +188,32 @@ func (bmrf *BorweinModelResultFetcher) FetchModelResult(ctx context.Context, met
 	return errors.NewAggregate(errList)
 }
 
+// warmStartModelResults restores persisted inference results for models without any result since restart,
+// so that they can be consumed before the first inference finishes
+func (bmrf *BorweinModelResultFetcher) warmStartModelResults(metaReader metacache.MetaReader, metaWriter metacache.MetaWriter) {
+	for modelName := range bmrf.modelNameToInferenceSvcSockAbsPath {
+		resultKey := borweinutils.GetInferenceResultKey(modelName)
+		if _, err := metaReader.GetInferenceResult(resultKey); err == nil {
+			continue
+		}
+
+		results := borweintypes.NewBorweinInferenceResults()
+		found, err := metaReader.GetPersistedInferenceResult(resultKey, results)
+		if err != nil {
+			general.Errorf("get persisted inference result for model: %s failed: %v", modelName, err)
+			continue
+		} else if !found {
+			continue
+		}
+
+		if err := metaWriter.SetInferenceResult(resultKey, results); err != nil {
+			general.Errorf("warm start inference result for model: %s failed: %v", modelName, err)
+			continue
+		}
+		general.Infof("warm start inference result for model: %s with timestamp: %d", modelName, results.Timestamp)
+	}
+}
+
 func (bmrf *BorweinModelResultFetcher) parseInferenceRespForPods(requestContainers []*types.ContainerInfo,
 	resp *borweininfsvc.InferenceResponse,
 ) (*borweintypes.BorweinInferenceResults, error) {
@@ -417,6 +447,7 @@ func NewBorweinModelResultFetcher(fetcherName string, conf *config.Configuration
 		nodeFeatureNames:                   conf.BorweinConfiguration.NodeFeatureNames,
 		containerFeatureNames:              conf.BorweinConfiguration.ContainerFeatureNames,
 		modelNameToInferenceSvcSockAbsPath: conf.BorweinConfiguration.ModelNameToInferenceSvcSockAbsPath,
+		resultTTL:                          conf.InferencePluginConfiguration.ResultTTL,
 	}
 
 	// fetcher initializing doesn't block sys-advisor main process
@@ -0,0 +1,308 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	inferenceConsts "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/consts"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/modelresultfetcher"
+	borweininfsvc "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/models/borwein/inferencesvc"
+	borweintypes "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/models/borwein/types"
+	borweinutils "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/models/borwein/utils"
+	localmodel "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/models/local"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	metricspool "github.com/kubewharf/katalyst-core/pkg/metrics/metrics-pool"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	LocalModelResultFetcherName = "local_model_result_fetcher"
+
+	localModelFileSuffix = ".json"
+
+	metricLoadModelFailed          = "local_model_load_failed"
+	metricEvaluateFailedRatio      = "local_model_evaluate_failed_ratio"
+	metricSetInferenceResultFailed = "local_model_set_inference_result_failed"
+)
+
+// genericOutput is in the same format as generic outputs of borwein regression models
+type genericOutput struct {
+	PredictValue float64 `json:"predict_value"`
+}
+
+// loadedModel records the file status of a model artifact to detect changes
+type loadedModel struct {
+	model   *localmodel.Model
+	modTime time.Time
+	size    int64
+}
+
+// LocalModelResultFetcher evaluates model artifacts in local directory against features
+// from model input fetchers, and writes results into metacache in the same format and
+// under the same key as BorweinModelResultFetcher does, so that consumers of borwein
+// results (e.g. borwein model controller) work with local models transparently. A local
+// model should be named after the borwein model it replaces, e.g. latency_regression.json.
+type LocalModelResultFetcher struct {
+	name      string
+	modelDir  string
+	resultTTL time.Duration
+
+	emitter metrics.MetricEmitter
+
+	// models are only accessed in FetchModelResult, which is called sequentially
+	models map[string]*loadedModel
+}
+
+func (lmrf *LocalModelResultFetcher) FetchModelResult(ctx context.Context, metaReader metacache.MetaReader,
+	metaWriter metacache.MetaWriter, metaServer *metaserver.MetaServer,
+) error {
+	lmrf.reloadModels()
+	if len(lmrf.models) == 0 {
+		return fmt.Errorf("no model is loaded from %s", lmrf.modelDir)
+	}
+
+	requestContainers := []*types.ContainerInfo{}
+	metaReader.RangeContainer(func(podUID string, containerName string, containerInfo *types.ContainerInfo) bool {
+		if containerInfo == nil {
+			general.Warningf("pod: %s, container: %s has nil containerInfo", podUID, containerName)
+			return true
+		} else if containerInfo.ContainerType != v1alpha1.ContainerType_MAIN {
+			return true
+		}
+
+		requestContainers = append(requestContainers, containerInfo)
+		return true
+	})
+
+	if len(requestContainers) == 0 {
+		general.Warningf("there is no target container to inference for")
+		return nil
+	}
+
+	nodeFeatures, err := metaReader.GetModelInput(inferenceConsts.MetricDimensionNode)
+	if err != nil {
+		return fmt.Errorf("get model input failed with error: %v", err)
+	}
+	podFeatures, err := metaReader.GetModelInput(inferenceConsts.MetricDimensionContainer)
+	if err != nil {
+		return fmt.Errorf("get model input failed with error: %v", err)
+	}
+
+	var errList []error
+	for modelName, loaded := range lmrf.models {
+		results := lmrf.evaluate(modelName, loaded.model, requestContainers, nodeFeatures, podFeatures)
+
+		err = metaWriter.SetInferenceResultWithTTL(borweinutils.GetInferenceResultKey(modelName), results, lmrf.resultTTL)
+		if err != nil {
+			_ = lmrf.emitter.StoreInt64(metricSetInferenceResultFailed, 1, metrics.MetricTypeNameRaw,
+				metrics.MetricTag{Key: "model", Val: modelName})
+			errList = append(errList, fmt.Errorf("SetInferenceResult from model: %s failed with error: %v", modelName, err))
+		}
+	}
+
+	return errors.NewAggregate(errList)
+}
+
+// evaluate calculates results for all containers, and containers failed to be
+// evaluated are skipped so that they won't affect others
+func (lmrf *LocalModelResultFetcher) evaluate(modelName string, model *localmodel.Model,
+	requestContainers []*types.ContainerInfo, nodeFeatures, podFeatures map[string]interface{},
+) *borweintypes.BorweinInferenceResults {
+	results := borweintypes.NewBorweinInferenceResults()
+	results.Timestamp = time.Now().UnixMilli()
+
+	failedCnt := 0
+	for _, containerInfo := range requestContainers {
+		var containerFeatures map[string]interface{}
+		if containers, ok := podFeatures[containerInfo.PodUID].(map[string]map[string]interface{}); ok {
+			containerFeatures = containers[containerInfo.ContainerName]
+		}
+
+		features := make([]float64, 0, len(model.FeatureNames))
+		for _, featureName := range model.FeatureNames {
+			features = append(features, getFeatureValue(featureName, containerFeatures, nodeFeatures))
+		}
+
+		output, err := model.Evaluate(features)
+		if err != nil {
+			general.Warningf("evaluate model: %s for pod: %s/%s, container: %s failed: %v", modelName,
+				containerInfo.PodNamespace, containerInfo.PodName, containerInfo.ContainerName, err)
+			failedCnt++
+			continue
+		}
+
+		result := &borweininfsvc.InferenceResult{
+			InferenceType: model.GetInferenceType(),
+			Output:        float32(output),
+			Percentile:    float32(model.Percentile),
+			ModelVersion:  model.Version,
+		}
+		if result.InferenceType == borweininfsvc.InferenceType_Other {
+			// consumers of borwein results parse outputs of other type from generic output
+			genericOutput, err := json.Marshal(&genericOutput{PredictValue: output})
+			if err != nil {
+				general.Warningf("marshal generic output of model: %s for pod: %s/%s, container: %s failed: %v", modelName,
+					containerInfo.PodNamespace, containerInfo.PodName, containerInfo.ContainerName, err)
+				failedCnt++
+				continue
+			}
+			result.GenericOutput = string(genericOutput)
+		}
+
+		results.SetInferenceResults(containerInfo.PodUID, containerInfo.ContainerName, result)
+	}
+
+	_ = lmrf.emitter.StoreFloat64(metricEvaluateFailedRatio, float64(failedCnt)/float64(len(requestContainers)),
+		metrics.MetricTypeNameRaw, metrics.MetricTag{Key: "model", Val: modelName})
+	return results
+}
+
+// reloadModels loads artifacts changed since last time, and keeps the previous version
+// of a model if its new artifact is invalid; models without artifacts are removed.
+func (lmrf *LocalModelResultFetcher) reloadModels() {
+	entries, err := os.ReadDir(lmrf.modelDir)
+	if err != nil {
+		general.Errorf("read model directory %s failed: %v", lmrf.modelDir, err)
+		return
+	}
+
+	existing := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), localModelFileSuffix) {
+			continue
+		}
+
+		modelName := strings.TrimSuffix(entry.Name(), localModelFileSuffix)
+		existing[modelName] = true
+
+		info, err := entry.Info()
+		if err != nil {
+			general.Errorf("get info of model %s failed: %v", modelName, err)
+			continue
+		}
+
+		loaded, ok := lmrf.models[modelName]
+		if ok && loaded.modTime.Equal(info.ModTime()) && loaded.size == info.Size() {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(lmrf.modelDir, entry.Name()))
+		if err != nil {
+			general.Errorf("read model %s failed: %v", modelName, err)
+			continue
+		}
+
+		model, err := localmodel.LoadModel(data)
+		if err != nil {
+			_ = lmrf.emitter.StoreInt64(metricLoadModelFailed, 1, metrics.MetricTypeNameRaw,
+				metrics.MetricTag{Key: "model", Val: modelName})
+			general.Errorf("load model %s failed: %v", modelName, err)
+			continue
+		}
+
+		lmrf.models[modelName] = &loadedModel{
+			model:   model,
+			modTime: info.ModTime(),
+			size:    info.Size(),
+		}
+		general.Infof("load model %s with version: %s", modelName, model.Version)
+	}
+
+	for modelName := range lmrf.models {
+		if !existing[modelName] {
+			general.Infof("remove model %s since its artifact doesn't exist", modelName)
+			delete(lmrf.models, modelName)
+		}
+	}
+}
+
+// getFeatureValue prefers container features to node features, and returns NaN if
+// the feature is missing or can't be parsed as a number
+func getFeatureValue(featureName string, containerFeatures, nodeFeatures map[string]interface{}) float64 {
+	value, ok := containerFeatures[featureName]
+	if !ok {
+		value, ok = nodeFeatures[featureName]
+		if !ok {
+			return math.NaN()
+		}
+	}
+
+	switch v := value.(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return math.NaN()
+		}
+		return f
+	default:
+		return math.NaN()
+	}
+}
+
+func NewLocalModelResultFetcher(fetcherName string, conf *config.Configuration, extraConf interface{},
+	emitterPool metricspool.MetricsEmitterPool, metaServer *metaserver.MetaServer,
+	metaCache metacache.MetaCache,
+) (modelresultfetcher.ModelResultFetcher, error) {
+	if conf == nil || conf.InferencePluginConfiguration == nil {
+		return nil, fmt.Errorf("nil conf")
+	} else if !conf.InferencePluginConfiguration.EnableLocalModelResultFetcher {
+		return nil, nil
+	} else if conf.InferencePluginConfiguration.LocalModelDirectory == "" {
+		return nil, fmt.Errorf("empty local model directory")
+	}
+
+	return &LocalModelResultFetcher{
+		name:      fetcherName,
+		modelDir:  conf.InferencePluginConfiguration.LocalModelDirectory,
+		resultTTL: conf.InferencePluginConfiguration.ResultTTL,
+		emitter:   emitterPool.GetDefaultMetricsEmitter().WithTags(LocalModelResultFetcherName),
+		models:    make(map[string]*loadedModel),
+	}, nil
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	inferenceConsts "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/consts"
+	borweintypes "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/models/borwein/types"
+	borweinutils "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/models/borwein/utils"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	metricspool "github.com/kubewharf/katalyst-core/pkg/metrics/metrics-pool"
+)
+
+func writeModel(t *testing.T, dir, modelName, data string, modTime time.Time) {
+	path := filepath.Join(dir, modelName+localModelFileSuffix)
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func getOutput(t *testing.T, metaReader metacache.MetaReader, modelName, podUID, containerName string) (float64, bool) {
+	result, err := metaReader.GetInferenceResult(borweinutils.GetInferenceResultKey(modelName))
+	require.NoError(t, err)
+	results, ok := result.(*borweintypes.BorweinInferenceResults)
+	require.True(t, ok)
+
+	containerResults := results.Results[podUID][containerName]
+	if len(containerResults) == 0 {
+		return 0, false
+	}
+	return float64(containerResults[0].Output), true
+}
+
+func TestNewLocalModelResultFetcher(t *testing.T) {
+	t.Parallel()
+
+	conf := config.NewConfiguration()
+	fetcher, err := NewLocalModelResultFetcher(LocalModelResultFetcherName, conf, nil,
+		metricspool.DummyMetricsEmitterPool{}, nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, fetcher)
+
+	conf.InferencePluginConfiguration.EnableLocalModelResultFetcher = true
+	_, err = NewLocalModelResultFetcher(LocalModelResultFetcherName, conf, nil,
+		metricspool.DummyMetricsEmitterPool{}, nil, nil)
+	require.Error(t, err)
+
+	conf.InferencePluginConfiguration.LocalModelDirectory = t.TempDir()
+	fetcher, err = NewLocalModelResultFetcher(LocalModelResultFetcherName, conf, nil,
+		metricspool.DummyMetricsEmitterPool{}, nil, nil)
+	require.NoError(t, err)
+	require.NotNil(t, fetcher)
+}
+
+func TestLocalModelResultFetcher_FetchModelResult(t *testing.T) {
+	t.Parallel()
+
+	modelDir := t.TempDir()
+	conf := config.NewConfiguration()
+	conf.GenericSysAdvisorConfiguration.StateFileDirectory = t.TempDir()
+	conf.InferencePluginConfiguration.EnableLocalModelResultFetcher = true
+	conf.InferencePluginConfiguration.LocalModelDirectory = modelDir
+
+	metaCache, err := metacache.NewMetaCacheImp(conf, metricspool.DummyMetricsEmitterPool{}, nil)
+	require.NoError(t, err)
+	fetcher, err := NewLocalModelResultFetcher(LocalModelResultFetcherName, conf, nil,
+		metricspool.DummyMetricsEmitterPool{}, nil, metaCache)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.Error(t, fetcher.FetchModelResult(ctx, metaCache, metaCache, nil), "no model is loaded")
+
+	require.NoError(t, metaCache.AddContainer("pod1", "c1", &types.ContainerInfo{
+		PodUID: "pod1", ContainerName: "c1", ContainerType: v1alpha1.ContainerType_MAIN,
+	}))
+	require.NoError(t, metaCache.AddContainer("pod2", "c2", &types.ContainerInfo{
+		PodUID: "pod2", ContainerName: "c2", ContainerType: v1alpha1.ContainerType_MAIN,
+	}))
+	require.NoError(t, metaCache.AddContainer("pod2", "sidecar", &types.ContainerInfo{
+		PodUID: "pod2", ContainerName: "sidecar", ContainerType: v1alpha1.ContainerType_SIDECAR,
+	}))
+	require.NoError(t, metaCache.SetModelInput(inferenceConsts.MetricDimensionNode, map[string]interface{}{
+		"node_load": 2.0,
+	}))
+	require.NoError(t, metaCache.SetModelInput(inferenceConsts.MetricDimensionContainer, map[string]interface{}{
+		"pod1": map[string]map[string]interface{}{
+			"c1": {"cpu_usage": "3"},
+		},
+		"pod2": map[string]map[string]interface{}{
+			"c2": {"cpu_usage": 5, "node_load": 4.0},
+		},
+	}))
+
+	now := time.Now()
+	writeModel(t, modelDir, "test_model",
+		`{"type": "linear", "version": "v1", "feature_names": ["cpu_usage", "node_load"], "linear": {"weights": [1, 10]}}`,
+		now.Add(-time.Minute))
+	require.NoError(t, fetcher.FetchModelResult(ctx, metaCache, metaCache, nil))
+
+	output, ok := getOutput(t, metaCache, "test_model", "pod1", "c1")
+	require.True(t, ok)
+	require.InDelta(t, 23, output, 1e-6)
+	output, ok = getOutput(t, metaCache, "test_model", "pod2", "c2")
+	require.True(t, ok)
+	require.InDelta(t, 45, output, 1e-6)
+	_, ok = getOutput(t, metaCache, "test_model", "pod2", "sidecar")
+	require.False(t, ok)
+
+	// invalid artifact doesn't replace the loaded model
+	writeModel(t, modelDir, "test_model", `{"type": "linear"}`, now.Add(-time.Second))
+	require.NoError(t, fetcher.FetchModelResult(ctx, metaCache, metaCache, nil))
+	output, ok = getOutput(t, metaCache, "test_model", "pod1", "c1")
+	require.True(t, ok)
+	require.InDelta(t, 23, output, 1e-6)
+
+	// changed artifact is reloaded, and containers with missing features are skipped
+	writeModel(t, modelDir, "test_model",
+		`{"type": "linear", "version": "v2", "feature_names": ["cpu_usage", "qps"], "linear": {"weights": [2, 1]}}`, now)
+	require.NoError(t, metaCache.SetModelInput(inferenceConsts.MetricDimensionContainer, map[string]interface{}{
+		"pod1": map[string]map[string]interface{}{
+			"c1": {"cpu_usage": 3, "qps": 1},
+		},
+	}))
+	require.NoError(t, fetcher.FetchModelResult(ctx, metaCache, metaCache, nil))
+	output, ok = getOutput(t, metaCache, "test_model", "pod1", "c1")
+	require.True(t, ok)
+	require.InDelta(t, 7, output, 1e-6)
+	_, ok = getOutput(t, metaCache, "test_model", "pod2", "c2")
+	require.False(t, ok)
+
+	// removed artifact is unloaded
+	require.NoError(t, os.Remove(filepath.Join(modelDir, "test_model"+localModelFileSuffix)))
+	require.Error(t, fetcher.FetchModelResult(ctx, metaCache, metaCache, nil))
+}
+
+func TestGetFeatureValue(t *testing.T) {
+	t.Parallel()
+
+	containerFeatures := map[string]interface{}{"a": 1, "b": "2.5", "c": true, "d": "x", "e": []int{1}}
+	nodeFeatures := map[string]interface{}{"a": 10, "f": int64(3)}
+
+	require.Equal(t, 1.0, getFeatureValue("a", containerFeatures, nodeFeatures))
+	require.Equal(t, 2.5, getFeatureValue("b", containerFeatures, nodeFeatures))
+	require.Equal(t, 1.0, getFeatureValue("c", containerFeatures, nodeFeatures))
+	require.Equal(t, 3.0, getFeatureValue("f", containerFeatures, nodeFeatures))
+	require.True(t, math.IsNaN(getFeatureValue("d", containerFeatures, nodeFeatures)))
+	require.True(t, math.IsNaN(getFeatureValue("e", containerFeatures, nodeFeatures)))
+	require.True(t, math.IsNaN(getFeatureValue("g", containerFeatures, nodeFeatures)))
+}
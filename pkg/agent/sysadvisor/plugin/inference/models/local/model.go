@@ -0,0 +1,224 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package local defines model artifacts which can be evaluated in-process,
+// so that inference doesn't depend on any external inference service.
+package local
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	borweininfsvc "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/models/borwein/inferencesvc"
+)
+
+type ModelType string
+
+const (
+	ModelTypeLinear ModelType = "linear"
+	ModelTypeGBDT   ModelType = "gbdt"
+)
+
+type LinkFunction string
+
+const (
+	LinkFunctionIdentity LinkFunction = "identity"
+	LinkFunctionSigmoid  LinkFunction = "sigmoid"
+)
+
+// Model is the artifact of a local model, and it's stored as json. Features are
+// matched by FeatureNames, and the raw score is transformed by Link as output.
+type Model struct {
+	Type          ModelType    `json:"type"`
+	Version       string       `json:"version"`
+	InferenceType string       `json:"inference_type"`
+	Percentile    float64      `json:"percentile"`
+	FeatureNames  []string     `json:"feature_names"`
+	Link          LinkFunction `json:"link"`
+
+	Linear *LinearModel `json:"linear,omitempty"`
+	GBDT   *GBDTModel   `json:"gbdt,omitempty"`
+
+	inferenceType borweininfsvc.InferenceType
+}
+
+// LinearModel calculates raw score as Intercept + sum(Weights[i] * features[i])
+type LinearModel struct {
+	Weights   []float64 `json:"weights"`
+	Intercept float64   `json:"intercept"`
+}
+
+// GBDTModel calculates raw score as BaseScore + sum(leaf values of Trees)
+type GBDTModel struct {
+	BaseScore float64 `json:"base_score"`
+	Trees     []Tree  `json:"trees"`
+}
+
+// Tree is a decision tree with Nodes[0] as root, and children must be placed
+// after their parents to make sure the evaluation always terminates.
+type Tree struct {
+	Nodes []TreeNode `json:"nodes"`
+}
+
+// TreeNode goes left if feature value is less than Threshold, and goes
+// by DefaultLeft if the feature is missing; it's ignored for leaf nodes.
+type TreeNode struct {
+	Leaf  bool    `json:"leaf"`
+	Value float64 `json:"value"`
+
+	Feature     int     `json:"feature"`
+	Threshold   float64 `json:"threshold"`
+	Left        int     `json:"left"`
+	Right       int     `json:"right"`
+	DefaultLeft bool    `json:"default_left"`
+}
+
+// LoadModel parses and validates model artifact
+func LoadModel(data []byte) (*Model, error) {
+	model := &Model{}
+	if err := json.Unmarshal(data, model); err != nil {
+		return nil, fmt.Errorf("unmarshal model failed: %v", err)
+	}
+
+	if err := model.validate(); err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+func (m *Model) validate() error {
+	if len(m.FeatureNames) == 0 {
+		return fmt.Errorf("empty feature names")
+	}
+
+	if m.InferenceType == "" {
+		m.inferenceType = borweininfsvc.InferenceType_Other
+	} else if value, ok := borweininfsvc.InferenceType_value[m.InferenceType]; ok {
+		m.inferenceType = borweininfsvc.InferenceType(value)
+	} else {
+		return fmt.Errorf("unknown inference type: %s", m.InferenceType)
+	}
+
+	switch m.Link {
+	case "":
+		m.Link = LinkFunctionIdentity
+	case LinkFunctionIdentity, LinkFunctionSigmoid:
+	default:
+		return fmt.Errorf("unknown link function: %s", m.Link)
+	}
+
+	switch m.Type {
+	case ModelTypeLinear:
+		if m.Linear == nil {
+			return fmt.Errorf("nil linear model")
+		} else if len(m.Linear.Weights) != len(m.FeatureNames) {
+			return fmt.Errorf("count of weights: %d and features: %d are not same",
+				len(m.Linear.Weights), len(m.FeatureNames))
+		}
+	case ModelTypeGBDT:
+		if m.GBDT == nil {
+			return fmt.Errorf("nil gbdt model")
+		}
+		for idx, tree := range m.GBDT.Trees {
+			if err := tree.validate(len(m.FeatureNames)); err != nil {
+				return fmt.Errorf("invalid tree %d: %v", idx, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown model type: %s", m.Type)
+	}
+
+	return nil
+}
+
+func (t *Tree) validate(featureCount int) error {
+	if len(t.Nodes) == 0 {
+		return fmt.Errorf("empty nodes")
+	}
+
+	for idx, node := range t.Nodes {
+		if node.Leaf {
+			continue
+		}
+
+		if node.Feature < 0 || node.Feature >= featureCount {
+			return fmt.Errorf("node %d refers to invalid feature %d", idx, node.Feature)
+		}
+		for _, child := range []int{node.Left, node.Right} {
+			if child <= idx || child >= len(t.Nodes) {
+				return fmt.Errorf("node %d has invalid child %d", idx, child)
+			}
+		}
+	}
+	return nil
+}
+
+// GetInferenceType returns the inference type that results of this model belong to
+func (m *Model) GetInferenceType() borweininfsvc.InferenceType {
+	return m.inferenceType
+}
+
+// Evaluate calculates output for the given features, which are ordered the same as
+// FeatureNames and NaN stands for missing ones. Missing features are only supported
+// by gbdt models.
+func (m *Model) Evaluate(features []float64) (float64, error) {
+	if len(features) != len(m.FeatureNames) {
+		return 0, fmt.Errorf("count of features: %d and feature names: %d are not same",
+			len(features), len(m.FeatureNames))
+	}
+
+	var score float64
+	switch m.Type {
+	case ModelTypeLinear:
+		score = m.Linear.Intercept
+		for idx, weight := range m.Linear.Weights {
+			if math.IsNaN(features[idx]) {
+				return 0, fmt.Errorf("missing feature: %s", m.FeatureNames[idx])
+			}
+			score += weight * features[idx]
+		}
+	case ModelTypeGBDT:
+		score = m.GBDT.BaseScore
+		for idx := range m.GBDT.Trees {
+			score += m.GBDT.Trees[idx].evaluate(features)
+		}
+	default:
+		return 0, fmt.Errorf("unknown model type: %s", m.Type)
+	}
+
+	if m.Link == LinkFunctionSigmoid {
+		score = 1 / (1 + math.Exp(-score))
+	}
+	return score, nil
+}
+
+func (t *Tree) evaluate(features []float64) float64 {
+	idx := 0
+	for {
+		node := t.Nodes[idx]
+		if node.Leaf {
+			return node.Value
+		}
+
+		value := features[node.Feature]
+		if (math.IsNaN(value) && node.DefaultLeft) || value < node.Threshold {
+			idx = node.Left
+		} else {
+			idx = node.Right
+		}
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	borweininfsvc "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/models/borwein/inferencesvc"
+)
+
+const testGBDTModel = `{
+	"type": "gbdt",
+	"version": "v1",
+	"inference_type": "ClassificationOverload",
+	"percentile": 0.5,
+	"feature_names": ["cpu_usage", "qps"],
+	"link": "sigmoid",
+	"gbdt": {
+		"base_score": 0,
+		"trees": [
+			{"nodes": [
+				{"feature": 0, "threshold": 10, "left": 1, "right": 2, "default_left": true},
+				{"leaf": true, "value": -1},
+				{"leaf": true, "value": 1}
+			]},
+			{"nodes": [
+				{"feature": 1, "threshold": 100, "left": 1, "right": 2},
+				{"leaf": true, "value": -0.5},
+				{"leaf": true, "value": 0.5}
+			]}
+		]
+	}
+}`
+
+func sigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+func TestLoadModel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{
+			name: "valid linear model",
+			data: `{"type": "linear", "feature_names": ["a", "b"], "linear": {"weights": [1, 2], "intercept": 1}}`,
+		},
+		{
+			name: "valid gbdt model",
+			data: testGBDTModel,
+		},
+		{
+			name:    "mismatched weights",
+			data:    `{"type": "linear", "feature_names": ["a", "b"], "linear": {"weights": [1]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown inference type",
+			data:    `{"type": "linear", "inference_type": "unknown", "feature_names": ["a"], "linear": {"weights": [1]}}`,
+			wantErr: true,
+		},
+		{
+			name: "cyclic tree",
+			data: `{"type": "gbdt", "feature_names": ["a"], "gbdt": {"trees": [{"nodes": [
+				{"feature": 0, "threshold": 1, "left": 0, "right": 1},
+				{"leaf": true, "value": 1}
+			]}]}}`,
+			wantErr: true,
+		},
+		{
+			name: "invalid feature index",
+			data: `{"type": "gbdt", "feature_names": ["a"], "gbdt": {"trees": [{"nodes": [
+				{"feature": 1, "threshold": 1, "left": 1, "right": 2},
+				{"leaf": true, "value": 1},
+				{"leaf": true, "value": 2}
+			]}]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			data:    `{"type": `,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := LoadModel([]byte(tt.data))
+			require.Equal(t, tt.wantErr, err != nil, "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestModel_Evaluate(t *testing.T) {
+	t.Parallel()
+
+	linearModel, err := LoadModel([]byte(`{"type": "linear", "feature_names": ["a", "b"], "linear": {"weights": [1, 2], "intercept": 1}}`))
+	require.NoError(t, err)
+	require.Equal(t, borweininfsvc.InferenceType_Other, linearModel.GetInferenceType())
+
+	gbdtModel, err := LoadModel([]byte(testGBDTModel))
+	require.NoError(t, err)
+	require.Equal(t, borweininfsvc.InferenceType_ClassificationOverload, gbdtModel.GetInferenceType())
+
+	tests := []struct {
+		name     string
+		model    *Model
+		features []float64
+		want     float64
+		wantErr  bool
+	}{
+		{
+			name:     "linear model",
+			model:    linearModel,
+			features: []float64{2, 3},
+			want:     9,
+		},
+		{
+			name:     "linear model with missing feature",
+			model:    linearModel,
+			features: []float64{2, math.NaN()},
+			wantErr:  true,
+		},
+		{
+			name:     "linear model with mismatched features",
+			model:    linearModel,
+			features: []float64{2},
+			wantErr:  true,
+		},
+		{
+			name:     "gbdt model goes left",
+			model:    gbdtModel,
+			features: []float64{5, 50},
+			want:     sigmoid(-1.5),
+		},
+		{
+			name:     "gbdt model goes right",
+			model:    gbdtModel,
+			features: []float64{10, 200},
+			want:     sigmoid(1.5),
+		},
+		{
+			name:     "gbdt model with missing features",
+			model:    gbdtModel,
+			features: []float64{math.NaN(), math.NaN()},
+			want:     sigmoid(-0.5),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.model.Evaluate(tt.features)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.InDelta(t, tt.want, got, 1e-9)
+		})
+	}
+}
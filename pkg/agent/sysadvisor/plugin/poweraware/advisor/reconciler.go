@@ -85,6 +85,10 @@ func (p *powerReconciler) Reconcile(ctx context.Context, desired *spec.PowerSpec
 		general.Infof("pap: req to evict target percentage %d", actionPlan.Arg)
 		return false, nil
 	default:
+		// lift the capped limits back gradually once power usage falls below the budget
+		if raiser, ok := p.capper.(capper.PowerRaiser); ok && actual < desired.Budget {
+			raiser.Raise(ctx, desired.Budget, actual)
+		}
 		// todo: add feature of pod suppressions (with their resource usage)
 		return false, nil
 	}
@@ -225,3 +225,57 @@ func Test_powerReconciler_Reconcile_DryRun(t *testing.T) {
 		})
 	}
 }
+
+type dummyPowerRaiser struct {
+	dummyPowerCapper
+	raiseCalled bool
+}
+
+func (d *dummyPowerRaiser) Raise(ctx context.Context, targetWatts, currWatt int) {
+	d.raiseCalled = true
+}
+
+func Test_powerReconciler_Raises_Below_Budget(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		actual          int
+		wantRaiseCalled bool
+	}{
+		{
+			name:            "power below budget should raise capped limits",
+			actual:          100,
+			wantRaiseCalled: true,
+		},
+		{
+			name:            "power above budget should not raise",
+			actual:          150,
+			wantRaiseCalled: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			raiser := &dummyPowerRaiser{}
+			p := &powerReconciler{
+				evictor:  &dummyPercentageEvictor{},
+				capper:   raiser,
+				strategy: &dummyStrategy{},
+				emitter:  metricspool.DummyMetricsEmitterPool{}.GetDefaultMetricsEmitter().WithTags("test"),
+			}
+
+			freqCapped, err := p.Reconcile(context.TODO(), &spec.PowerSpec{
+				Alert:      spec.PowerAlertP0,
+				Budget:     127,
+				InternalOp: spec.InternalOpNoop,
+			}, tt.actual)
+			assert.NoError(t, err)
+			assert.False(t, freqCapped)
+			assert.Equal(t, tt.wantRaiseCalled, raiser.raiseCalled)
+			assert.False(t, raiser.capCalled)
+		})
+	}
+}
@@ -26,6 +26,12 @@ type PowerCapper interface {
 	Cap(ctx context.Context, targetWatts, currWatt int)
 }
 
+// PowerRaiser is implemented by cappers which are able to raise the capped limits back
+// gradually when the power usage falls below the target, instead of resetting them at once
+type PowerRaiser interface {
+	Raise(ctx context.Context, targetWatts, currWatt int)
+}
+
 // noopCapper is placeholder for disabled power capping server
 type noopCapper struct{}
 
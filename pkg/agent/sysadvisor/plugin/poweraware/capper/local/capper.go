@@ -0,0 +1,622 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/poweraware/capper"
+	powermetric "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/poweraware/metric"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/poweraware/reader"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/poweraware"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+const (
+	fileRAPLPowerLimit = "constraint_0_power_limit_uw"
+
+	fileScalingMaxFreq = "scaling_max_freq"
+	fileCPUInfoMinFreq = "cpuinfo_min_freq"
+
+	// checkpointFileName keeps the original values of the settings changed by capping, so that
+	// they can be restored even if sys-advisor restarts while the node is being capped
+	checkpointFileName = "local_power_capper_checkpoint"
+
+	// package power limit is never lowered below this share of its original value,
+	// as too low a limit could starve the system services
+	minPowerLimitRatio = 0.2
+)
+
+var (
+	cpuDirRegexp = regexp.MustCompile(`^cpu(\d+)$`)
+
+	_ capper.PowerRaiser = &localCapper{}
+)
+
+// ReclaimedCPUsGetter returns the cpus of reclaimed pool, which are throttled ahead of the others
+type ReclaimedCPUsGetter func() machine.CPUSet
+
+// localCapper enforces the power capping target by sys-advisor itself, without any external capper;
+// it either lowers the rapl package power limits, or the cpufreq scaling_max_freq of reclaimed cpus first
+// and then of the other cpus only if throttling the reclaimed ones alone is not enough.
+type localCapper struct {
+	method        string
+	powercapRoot  string
+	cpuFreqRoot   string
+	stateFileDir  string
+	reclaimedCPUs ReclaimedCPUsGetter
+	emitter       metrics.MetricEmitter
+
+	mutex   sync.Mutex
+	started bool
+
+	packages    []reader.RAPLDomain
+	lastEnergy  []uint64
+	lastSampled time.Time
+	// original values of the settings changed by capping, keyed by file path,
+	// and they are checkpointed into stateFileDir whenever changed
+	origValues map[string]uint64
+
+	cpus []int
+}
+
+func (l *localCapper) Init() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	switch l.method {
+	case poweraware.LocalCapperMethodPowercap:
+		domains, err := reader.DiscoverRAPLDomains(l.powercapRoot)
+		if err != nil {
+			return errors.Wrap(err, "failed to discover rapl domains")
+		}
+		for _, domain := range domains {
+			if !domain.IsPackage() {
+				continue
+			}
+			if _, err := reader.ReadUint64File(filepath.Join(domain.Path, fileRAPLPowerLimit)); err != nil {
+				return errors.Wrapf(err, "failed to read power limit of rapl domain %s", domain.Name)
+			}
+			l.packages = append(l.packages, domain)
+		}
+		if len(l.packages) == 0 {
+			return errors.New("no rapl package domain found")
+		}
+	case poweraware.LocalCapperMethodCPUFreq:
+		cpus, err := l.discoverCPUs()
+		if err != nil {
+			return err
+		}
+		l.cpus = cpus
+	default:
+		return fmt.Errorf("unknown local capper method %q", l.method)
+	}
+
+	l.restoreCheckpoint()
+	general.Infof("pap: local capper initialized with method %s", l.method)
+	return nil
+}
+
+// restoreCheckpoint restores the settings left capped by the previous run of sys-advisor
+func (l *localCapper) restoreCheckpoint() {
+	data, err := os.ReadFile(l.checkpointPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			klog.Errorf("pap: failed to read local capper checkpoint: %v", err)
+		}
+		return
+	}
+
+	origValues := make(map[string]uint64)
+	if err := json.Unmarshal(data, &origValues); err != nil {
+		klog.Errorf("pap: failed to unmarshal local capper checkpoint: %v", err)
+		return
+	}
+
+	for path, value := range origValues {
+		general.Infof("pap: restore %s to %d left capped by previous run", path, value)
+		if err := writeUint64(path, value); err != nil {
+			klog.Errorf("pap: failed to restore %s to %d: %v", path, value, err)
+			l.origValues[path] = value
+		}
+	}
+	l.checkpoint()
+}
+
+// checkpoint persists the original values, and the checkpoint is removed if nothing is capped
+func (l *localCapper) checkpoint() {
+	path := l.checkpointPath()
+	if len(l.origValues) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			klog.Errorf("pap: failed to remove local capper checkpoint: %v", err)
+		}
+		return
+	}
+
+	data, err := json.Marshal(l.origValues)
+	if err == nil {
+		err = os.MkdirAll(l.stateFileDir, 0o755)
+	}
+	if err == nil {
+		// write into a temporary file first to avoid partial checkpoint after crash
+		tmpPath := path + ".tmp"
+		if err = os.WriteFile(tmpPath, data, 0o644); err == nil {
+			err = os.Rename(tmpPath, path)
+		}
+	}
+	if err != nil {
+		klog.Errorf("pap: failed to write local capper checkpoint: %v", err)
+		powermetric.EmitErrorCode(l.emitter, powermetric.ErrorCodeOther)
+	}
+}
+
+func (l *localCapper) checkpointPath() string {
+	return filepath.Join(l.stateFileDir, checkpointFileName)
+}
+
+func (l *localCapper) discoverCPUs() ([]int, error) {
+	entries, err := os.ReadDir(l.cpuFreqRoot)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list cpus")
+	}
+
+	var cpus []int
+	for _, entry := range entries {
+		matches := cpuDirRegexp.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+		cpu, err := strconv.Atoi(matches[1])
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(l.cpuFreqPath(cpu, fileScalingMaxFreq)); err != nil {
+			continue
+		}
+		cpus = append(cpus, cpu)
+	}
+	if len(cpus) == 0 {
+		return nil, fmt.Errorf("no cpufreq capable cpu found under %s", l.cpuFreqRoot)
+	}
+
+	sort.Ints(cpus)
+	return cpus, nil
+}
+
+func (l *localCapper) Start() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.started = true
+	if l.method == poweraware.LocalCapperMethodPowercap {
+		l.samplePackages(time.Now())
+	}
+	return nil
+}
+
+func (l *localCapper) Stop() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.started = false
+	return nil
+}
+
+// Reset restores all the settings changed by capping to their original values
+func (l *localCapper) Reset() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if len(l.origValues) == 0 {
+		return
+	}
+
+	for path, value := range l.origValues {
+		if err := writeUint64(path, value); err != nil {
+			klog.Errorf("pap: failed to restore %s to %d: %v", path, value, err)
+			powermetric.EmitErrorCode(l.emitter, powermetric.ErrorCodeOther)
+			continue
+		}
+		delete(l.origValues, path)
+	}
+	l.checkpoint()
+
+	powermetric.EmitPowerCapReset(l.emitter)
+}
+
+func (l *localCapper) Cap(_ context.Context, targetWatts, currWatt int) {
+	l.cap(targetWatts, currWatt, time.Now())
+}
+
+func (l *localCapper) cap(targetWatts, currWatt int, now time.Time) {
+	capInst, err := capper.NewCapInstruction(targetWatts, currWatt)
+	if err != nil || targetWatts <= 0 {
+		klog.Warningf("pap: invalid cap request from %d to %d watt", currWatt, targetWatts)
+		powermetric.EmitErrorCode(l.emitter, powermetric.ErrorCodeOther)
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if !l.started {
+		general.Warningf("pap: local power capper is not started")
+		powermetric.EmitErrorCode(l.emitter, powermetric.ErrorCodePowerCapperUnavailable)
+		return
+	}
+
+	if l.method == poweraware.LocalCapperMethodPowercap {
+		err = l.capByPowercap(float64(targetWatts)/float64(currWatt), now)
+	} else {
+		err = l.capByCPUFreq(targetWatts, currWatt)
+	}
+	l.checkpoint()
+	if err != nil {
+		klog.Errorf("pap: local capper failed to cap from %d to %d watt: %v", currWatt, targetWatts, err)
+		powermetric.EmitErrorCode(l.emitter, powermetric.ErrorCodeOther)
+		return
+	}
+
+	powermetric.EmitPowerCapInstruction(l.emitter, capInst)
+}
+
+// Raise lifts the capped settings by the share of power headroom below the target, and the
+// settings are never raised above their original values; it's a no-op if nothing is capped.
+func (l *localCapper) Raise(_ context.Context, targetWatts, currWatt int) {
+	if targetWatts <= currWatt || currWatt <= 0 {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if !l.started || len(l.origValues) == 0 {
+		return
+	}
+
+	var err error
+	if l.method == poweraware.LocalCapperMethodPowercap {
+		err = l.raiseByPowercap(float64(targetWatts) / float64(currWatt))
+	} else {
+		err = l.raiseByCPUFreq(targetWatts, currWatt)
+	}
+	l.checkpoint()
+	if err != nil {
+		klog.Errorf("pap: local capper failed to raise from %d to %d watt: %v", currWatt, targetWatts, err)
+		powermetric.EmitErrorCode(l.emitter, powermetric.ErrorCodeOther)
+	}
+}
+
+// raiseByPowercap scales up the power limit of each capped package by ratio, up to its original value
+func (l *localCapper) raiseByPowercap(ratio float64) error {
+	var errs []error
+	for _, pkg := range l.packages {
+		limitPath := filepath.Join(pkg.Path, fileRAPLPowerLimit)
+		orig, ok := l.origValues[limitPath]
+		if !ok {
+			continue
+		}
+		limit, err := reader.ReadUint64File(limitPath)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		newLimit := uint64(float64(limit) * ratio)
+		if newLimit > orig {
+			newLimit = orig
+		}
+		if newLimit <= limit {
+			continue
+		}
+
+		general.Infof("pap: raise power limit of %s from %d to %d uw", pkg.Name, limit, newLimit)
+		if err := writeUint64(limitPath, newLimit); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if newLimit == orig {
+			delete(l.origValues, limitPath)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// capByPowercap scales the power limit of each package by ratio, based on its actual power usage since
+// last sample if known; the limit is only ever lowered while capping, and restored on Reset
+func (l *localCapper) capByPowercap(ratio float64, now time.Time) error {
+	prevEnergy, prevSampled := l.lastEnergy, l.lastSampled
+	l.samplePackages(now)
+	elapsed := now.Sub(prevSampled)
+
+	var errs []error
+	for i, pkg := range l.packages {
+		limitPath := filepath.Join(pkg.Path, fileRAPLPowerLimit)
+		limit, err := reader.ReadUint64File(limitPath)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		orig, ok := l.origValues[limitPath]
+		if !ok {
+			orig = limit
+		}
+
+		basis := limit
+		if prevEnergy != nil && l.lastEnergy != nil && elapsed > 0 {
+			usage := uint64(float64(reader.EnergyDeltaUJ(prevEnergy[i], l.lastEnergy[i], pkg.MaxEnergyRangeUJ)) /
+				elapsed.Seconds())
+			if usage > 0 && usage < basis {
+				basis = usage
+			}
+		}
+
+		newLimit := uint64(float64(basis) * ratio)
+		if floor := uint64(float64(orig) * minPowerLimitRatio); newLimit < floor {
+			newLimit = floor
+		}
+		if newLimit >= limit {
+			continue
+		}
+
+		general.Infof("pap: lower power limit of %s from %d to %d uw", pkg.Name, limit, newLimit)
+		l.recordOrig(limitPath, limit)
+		if err := writeUint64(limitPath, newLimit); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func (l *localCapper) samplePackages(now time.Time) {
+	energy := make([]uint64, len(l.packages))
+	for i, pkg := range l.packages {
+		value, err := pkg.ReadEnergyUJ()
+		if err != nil {
+			// drop the sample so that the next capping falls back to current power limits
+			l.lastEnergy = nil
+			return
+		}
+		energy[i] = value
+	}
+	l.lastEnergy = energy
+	l.lastSampled = now
+}
+
+type cpuFreq struct {
+	cpu     int
+	maxFreq uint64
+	minFreq uint64
+}
+
+// cappedFreq is a cpu whose scaling_max_freq has been lowered from origFreq by capping
+type cappedFreq struct {
+	cpu      int
+	maxFreq  uint64
+	origFreq uint64
+}
+
+// capByCPUFreq lowers the sum of scaling_max_freq of all cpus by the share of power to shed, approximating power with
+// the sum of frequencies; reclaimed cpus are throttled down to their minimum frequency before any other cpu
+func (l *localCapper) capByCPUFreq(targetWatts, currWatt int) error {
+	reclaimed := machine.NewCPUSet()
+	if l.reclaimedCPUs != nil {
+		reclaimed = l.reclaimedCPUs()
+	}
+
+	var reclaimedFreqs, otherFreqs []cpuFreq
+	var total uint64
+	for _, cpu := range l.cpus {
+		maxFreq, err := reader.ReadUint64File(l.cpuFreqPath(cpu, fileScalingMaxFreq))
+		if err != nil {
+			return errors.Wrapf(err, "failed to read max freq of cpu %d", cpu)
+		}
+		minFreq, err := reader.ReadUint64File(l.cpuFreqPath(cpu, fileCPUInfoMinFreq))
+		if err != nil || minFreq > maxFreq {
+			minFreq = maxFreq
+		}
+
+		freq := cpuFreq{cpu: cpu, maxFreq: maxFreq, minFreq: minFreq}
+		if reclaimed.Contains(cpu) {
+			reclaimedFreqs = append(reclaimedFreqs, freq)
+		} else {
+			otherFreqs = append(otherFreqs, freq)
+		}
+		total += maxFreq
+	}
+
+	need := total * uint64(currWatt-targetWatts) / uint64(currWatt)
+	var errs []error
+	for _, group := range [][]cpuFreq{reclaimedFreqs, otherFreqs} {
+		if need == 0 {
+			break
+		}
+		var cut uint64
+		cut, errs = l.lowerCPUFreq(group, need, errs)
+		need -= cut
+	}
+
+	if need > 0 {
+		general.Warningf("pap: all cpus are at minimum frequency, %d khz short of target", need)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// raiseByCPUFreq adds the share of power headroom to the sum of scaling_max_freq of capped cpus;
+// other cpus are raised back to their original frequency before any reclaimed cpu
+func (l *localCapper) raiseByCPUFreq(targetWatts, currWatt int) error {
+	reclaimed := machine.NewCPUSet()
+	if l.reclaimedCPUs != nil {
+		reclaimed = l.reclaimedCPUs()
+	}
+
+	var reclaimedFreqs, otherFreqs []cappedFreq
+	var total uint64
+	for _, cpu := range l.cpus {
+		maxFreq, err := reader.ReadUint64File(l.cpuFreqPath(cpu, fileScalingMaxFreq))
+		if err != nil {
+			return errors.Wrapf(err, "failed to read max freq of cpu %d", cpu)
+		}
+		total += maxFreq
+
+		orig, ok := l.origValues[l.cpuFreqPath(cpu, fileScalingMaxFreq)]
+		if !ok || orig <= maxFreq {
+			continue
+		}
+
+		freq := cappedFreq{cpu: cpu, maxFreq: maxFreq, origFreq: orig}
+		if reclaimed.Contains(cpu) {
+			reclaimedFreqs = append(reclaimedFreqs, freq)
+		} else {
+			otherFreqs = append(otherFreqs, freq)
+		}
+	}
+
+	room := total * uint64(targetWatts-currWatt) / uint64(currWatt)
+	var errs []error
+	for _, group := range [][]cappedFreq{otherFreqs, reclaimedFreqs} {
+		if room == 0 {
+			break
+		}
+		var added uint64
+		added, errs = l.raiseCPUFreq(group, room, errs)
+		room -= added
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// raiseCPUFreq spreads the frequency raise over the group in proportion to each cpu's capped amount
+func (l *localCapper) raiseCPUFreq(group []cappedFreq, room uint64, errs []error) (uint64, []error) {
+	var capped uint64
+	for _, freq := range group {
+		capped += freq.origFreq - freq.maxFreq
+	}
+	if capped == 0 {
+		return 0, errs
+	}
+
+	added := room
+	if added > capped {
+		added = capped
+	}
+
+	for _, freq := range group {
+		delta := added * (freq.origFreq - freq.maxFreq) / capped
+		if delta == 0 {
+			continue
+		}
+
+		path := l.cpuFreqPath(freq.cpu, fileScalingMaxFreq)
+		if err := writeUint64(path, freq.maxFreq+delta); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if freq.maxFreq+delta >= freq.origFreq {
+			delete(l.origValues, path)
+		}
+	}
+
+	return added, errs
+}
+
+// lowerCPUFreq spreads the frequency cut over the group in proportion to each cpu's headroom above minimum
+func (l *localCapper) lowerCPUFreq(group []cpuFreq, need uint64, errs []error) (uint64, []error) {
+	var headroom uint64
+	for _, freq := range group {
+		headroom += freq.maxFreq - freq.minFreq
+	}
+	if headroom == 0 {
+		return 0, errs
+	}
+
+	cut := need
+	if cut > headroom {
+		cut = headroom
+	}
+
+	for _, freq := range group {
+		delta := cut * (freq.maxFreq - freq.minFreq) / headroom
+		if delta == 0 {
+			continue
+		}
+
+		path := l.cpuFreqPath(freq.cpu, fileScalingMaxFreq)
+		l.recordOrig(path, freq.maxFreq)
+		if err := writeUint64(path, freq.maxFreq-delta); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return cut, errs
+}
+
+func (l *localCapper) cpuFreqPath(cpu int, file string) string {
+	return filepath.Join(l.cpuFreqRoot, fmt.Sprintf("cpu%d", cpu), "cpufreq", file)
+}
+
+// recordOrig keeps the value of a setting before it is first changed by capping,
+// and it must be called right before the setting is actually written
+func (l *localCapper) recordOrig(path string, value uint64) {
+	if _, ok := l.origValues[path]; !ok {
+		l.origValues[path] = value
+	}
+}
+
+func writeUint64(path string, value uint64) error {
+	return os.WriteFile(path, []byte(strconv.FormatUint(value, 10)), 0o644)
+}
+
+// NewLocalCapper returns a power capper throttling the node by sys-advisor itself
+func NewLocalCapper(conf *poweraware.PowerAwarePluginConfiguration, stateFileDir string,
+	reclaimedCPUs ReclaimedCPUsGetter, emitter metrics.MetricEmitter,
+) capper.PowerCapper {
+	powercapRoot := conf.PowercapRoot
+	if powercapRoot == "" {
+		powercapRoot = reader.DefaultPowercapRoot
+	}
+	cpuFreqRoot := conf.CPUFreqRoot
+	if cpuFreqRoot == "" {
+		cpuFreqRoot = "/sys/devices/system/cpu"
+	}
+
+	return &localCapper{
+		method:        conf.LocalCapperMethod,
+		powercapRoot:  powercapRoot,
+		cpuFreqRoot:   cpuFreqRoot,
+		stateFileDir:  stateFileDir,
+		reclaimedCPUs: reclaimedCPUs,
+		emitter:       emitter,
+		origValues:    make(map[string]uint64),
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/poweraware/reader"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/poweraware"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func writeValue(t *testing.T, path string, value uint64) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(strconv.FormatUint(value, 10)), 0o644))
+}
+
+func readValue(t *testing.T, path string) uint64 {
+	t.Helper()
+	value, err := reader.ReadUint64File(path)
+	require.NoError(t, err)
+	return value
+}
+
+func TestLocalCapper_cpuFreq(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	for cpu := 0; cpu < 4; cpu++ {
+		dir := filepath.Join(root, "cpu"+strconv.Itoa(cpu), "cpufreq")
+		writeValue(t, filepath.Join(dir, "scaling_max_freq"), 3_000_000)
+		writeValue(t, filepath.Join(dir, "cpuinfo_min_freq"), 1_000_000)
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "cpuidle"), 0o755))
+
+	conf := &poweraware.PowerAwarePluginConfiguration{
+		LocalCapperMethod: poweraware.LocalCapperMethodCPUFreq,
+		CPUFreqRoot:       root,
+	}
+	stateDir := t.TempDir()
+	reclaimedCPUs := func() machine.CPUSet { return machine.NewCPUSet(2, 3) }
+	c := NewLocalCapper(conf, stateDir, reclaimedCPUs, &metrics.DummyMetrics{}).(*localCapper)
+	require.NoError(t, c.Init())
+	assert.Equal(t, []int{0, 1, 2, 3}, c.cpus)
+
+	maxFreqs := func() []uint64 {
+		var freqs []uint64
+		for cpu := 0; cpu < 4; cpu++ {
+			freqs = append(freqs, readValue(t, c.cpuFreqPath(cpu, fileScalingMaxFreq)))
+		}
+		return freqs
+	}
+
+	// not started yet
+	c.cap(900, 1000, time.Now())
+	assert.Equal(t, []uint64{3_000_000, 3_000_000, 3_000_000, 3_000_000}, maxFreqs())
+
+	require.NoError(t, c.Start())
+
+	// 10% of 12 GHz is taken from reclaimed cpus only
+	c.cap(900, 1000, time.Now())
+	assert.Equal(t, []uint64{3_000_000, 3_000_000, 2_400_000, 2_400_000}, maxFreqs())
+
+	// 50% of 10.8 GHz exhausts reclaimed cpus, and the rest is taken from the others
+	c.cap(500, 1000, time.Now())
+	assert.Equal(t, []uint64{1_700_000, 1_700_000, 1_000_000, 1_000_000}, maxFreqs())
+
+	// invalid request is ignored
+	c.cap(1000, 900, time.Now())
+	assert.Equal(t, []uint64{1_700_000, 1_700_000, 1_000_000, 1_000_000}, maxFreqs())
+
+	// 20% of 5.4 GHz is given back to the other cpus before reclaimed ones
+	c.Raise(context.TODO(), 600, 500)
+	assert.Equal(t, []uint64{2_240_000, 2_240_000, 1_000_000, 1_000_000}, maxFreqs())
+
+	// a restarted capper restores the settings left capped according to the checkpoint
+	restarted := NewLocalCapper(conf, stateDir, reclaimedCPUs, &metrics.DummyMetrics{}).(*localCapper)
+	require.NoError(t, restarted.Init())
+	assert.Equal(t, []uint64{3_000_000, 3_000_000, 3_000_000, 3_000_000}, maxFreqs())
+	assert.Empty(t, restarted.origValues)
+	_, err := os.Stat(filepath.Join(stateDir, checkpointFileName))
+	assert.True(t, os.IsNotExist(err))
+
+	c.cap(500, 1000, time.Now())
+	c.Reset()
+	assert.Equal(t, []uint64{3_000_000, 3_000_000, 3_000_000, 3_000_000}, maxFreqs())
+	assert.Empty(t, c.origValues)
+	require.NoError(t, c.Stop())
+}
+
+func TestLocalCapper_powercap(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	pkg := filepath.Join(root, "intel-rapl:0")
+	require.NoError(t, os.MkdirAll(pkg, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(pkg, "name"), []byte("package-0\n"), 0o644))
+	writeValue(t, filepath.Join(pkg, "max_energy_range_uj"), 1_000_000_000)
+	writeValue(t, filepath.Join(pkg, "energy_uj"), 900_000_000)
+	writeValue(t, filepath.Join(pkg, "constraint_0_power_limit_uw"), 200_000_000)
+
+	conf := &poweraware.PowerAwarePluginConfiguration{
+		LocalCapperMethod: poweraware.LocalCapperMethodPowercap,
+		PowercapRoot:      root,
+	}
+	stateDir := t.TempDir()
+	c := NewLocalCapper(conf, stateDir, nil, &metrics.DummyMetrics{}).(*localCapper)
+	require.NoError(t, c.Init())
+	require.NoError(t, c.Start())
+	start := c.lastSampled
+	limitPath := filepath.Join(pkg, "constraint_0_power_limit_uw")
+
+	// package draws 100 W across the counter wraparound, so its limit goes to 80% of actual usage
+	writeValue(t, filepath.Join(pkg, "energy_uj"), 0)
+	c.cap(800, 1000, start.Add(time.Second))
+	assert.Equal(t, uint64(80_000_000), readValue(t, limitPath))
+
+	// without usage since last sample the limit is scaled, but never below the floor
+	c.cap(100, 1000, start.Add(time.Second))
+	assert.Equal(t, uint64(40_000_000), readValue(t, limitPath))
+	_, err := os.Stat(filepath.Join(stateDir, checkpointFileName))
+	assert.NoError(t, err)
+
+	// the limit is raised by the share of headroom, but never above the original one
+	c.Raise(context.TODO(), 1000, 500)
+	assert.Equal(t, uint64(80_000_000), readValue(t, limitPath))
+	c.Raise(context.TODO(), 1000, 100)
+	assert.Equal(t, uint64(200_000_000), readValue(t, limitPath))
+	assert.Empty(t, c.origValues)
+	_, err = os.Stat(filepath.Join(stateDir, checkpointFileName))
+	assert.True(t, os.IsNotExist(err))
+
+	c.cap(800, 1000, start.Add(2*time.Second))
+	c.Reset()
+	assert.Equal(t, uint64(200_000_000), readValue(t, limitPath))
+}
+
+func TestLocalCapper_Init(t *testing.T) {
+	t.Parallel()
+
+	conf := &poweraware.PowerAwarePluginConfiguration{
+		LocalCapperMethod: "unknown",
+	}
+	assert.Error(t, NewLocalCapper(conf, t.TempDir(), nil, &metrics.DummyMetrics{}).Init())
+
+	conf = &poweraware.PowerAwarePluginConfiguration{
+		LocalCapperMethod: poweraware.LocalCapperMethodCPUFreq,
+		CPUFreqRoot:       t.TempDir(),
+	}
+	assert.Error(t, NewLocalCapper(conf, t.TempDir(), nil, &metrics.DummyMetrics{}).Init())
+}
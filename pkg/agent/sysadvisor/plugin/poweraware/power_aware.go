@@ -22,12 +22,14 @@ import (
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/poweraware/advisor"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/poweraware/advisor/action/strategy"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/poweraware/advisor/action/strategy/assess"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/poweraware/capper"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/poweraware/capper/local"
 	capserver "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/poweraware/capper/server"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/poweraware/evictor"
 	evictserver "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/poweraware/evictor/server"
@@ -37,6 +39,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/metaserver"
 	metricspool "github.com/kubewharf/katalyst-core/pkg/metrics/metrics-pool"
 	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
 )
 
 const metricName = "poweraware-advisor-plugin"
@@ -74,7 +77,7 @@ func NewPowerAwarePlugin(
 	_ interface{},
 	emitterPool metricspool.MetricsEmitterPool,
 	metaServer *metaserver.MetaServer,
-	_ metacache.MetaCache,
+	metaCache metacache.MetaCache,
 ) (plugin.SysAdvisorPlugin, error) {
 	emitter := emitterPool.GetDefaultMetricsEmitter().WithTags(metricName)
 
@@ -91,6 +94,10 @@ func NewPowerAwarePlugin(
 	var powerCapper capper.PowerCapper
 	if conf.DisablePowerCapping {
 		powerCapper = capper.NewNoopCapper()
+	} else if conf.PowerAwarePluginConfiguration.PowerCapper == poweraware.PowerCapperLocal {
+		general.Infof("pap: local power capper by %s", conf.PowerAwarePluginConfiguration.LocalCapperMethod)
+		powerCapper = local.NewLocalCapper(conf.PowerAwarePluginConfiguration,
+			conf.GenericSysAdvisorConfiguration.StateFileDirectory, getReclaimedCPUs(metaCache), emitter)
 	} else {
 		if powerCapper, err = capserver.NewPowerCapPlugin(conf, emitter); err != nil {
 			return nil, errors.Wrap(err, "pap: failed to create power aware plugin")
@@ -106,7 +113,13 @@ func NewPowerAwarePlugin(
 		assessor = assess.NewCPUFreqChangeAssessor(0, metaServer)
 	}
 
-	powerReader := reader.NewMetricStorePowerReader(metaServer)
+	var powerReader reader.PowerReader
+	if conf.PowerAwarePluginConfiguration.PowerReader == poweraware.PowerReaderRAPL {
+		general.Infof("pap: rapl as power reader")
+		powerReader = reader.NewRAPLPowerReader(conf.PowerAwarePluginConfiguration.PowercapRoot)
+	} else {
+		powerReader = reader.NewMetricStorePowerReader(metaServer)
+	}
 	percentageEvictor := evictor.NewPowerLoadEvict(conf.QoSConfiguration, emitter, metaServer.PodFetcher, podEvictor)
 	powerStrategy := strategy.NewEvictFirstStrategy(emitter, percentageEvictor, metaServer, powerCapper, assessor)
 	reconciler := advisor.NewReconciler(conf.PowerAwarePluginConfiguration.DryRun, emitter,
@@ -124,6 +137,19 @@ func NewPowerAwarePlugin(
 	return newPluginWithAdvisor(pluginName, conf, powerAdvisor)
 }
 
+func getReclaimedCPUs(metaCache metacache.MetaCache) local.ReclaimedCPUsGetter {
+	return func() machine.CPUSet {
+		if metaCache == nil {
+			return machine.NewCPUSet()
+		}
+		poolInfo, ok := metaCache.GetPoolInfo(commonstate.PoolNameReclaim)
+		if !ok || poolInfo == nil {
+			return machine.NewCPUSet()
+		}
+		return poolInfo.TopologyAwareAssignments.MergeCPUSet()
+	}
+}
+
 func newPluginWithAdvisor(pluginName string, conf *config.Configuration, advisor advisor.PowerAwareAdvisor) (plugin.SysAdvisorPlugin, error) {
 	return &powerAwarePlugin{
 		name:    pluginName,
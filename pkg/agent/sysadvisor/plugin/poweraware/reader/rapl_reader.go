@@ -0,0 +1,213 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultPowercapRoot = "/sys/class/powercap"
+
+	raplDomainGlob         = "intel-rapl:*"
+	raplFileName           = "name"
+	raplFileEnergy         = "energy_uj"
+	raplFileMaxEnergyRange = "max_energy_range_uj"
+
+	raplDomainPackagePrefix = "package"
+	raplDomainDRAM          = "dram"
+)
+
+// RAPLDomain is one powercap energy counter zone, e.g. package-0 or a dram sub-zone of it
+type RAPLDomain struct {
+	Name             string
+	Path             string
+	MaxEnergyRangeUJ uint64
+}
+
+func (d RAPLDomain) IsPackage() bool {
+	return strings.HasPrefix(d.Name, raplDomainPackagePrefix)
+}
+
+func (d RAPLDomain) ReadEnergyUJ() (uint64, error) {
+	return ReadUint64File(filepath.Join(d.Path, raplFileEnergy))
+}
+
+// DiscoverRAPLDomains lists the package and dram zones under the powercap root, sorted by path;
+// other zones (e.g. core, uncore, psys) are skipped as they overlap with package or cover the whole platform
+func DiscoverRAPLDomains(root string) ([]RAPLDomain, error) {
+	paths, err := filepath.Glob(filepath.Join(root, raplDomainGlob))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list rapl zones")
+	}
+
+	// sub-zones are exposed both as top level links and nested dirs, so walk one level down as well
+	nested, err := filepath.Glob(filepath.Join(root, raplDomainGlob, raplDomainGlob))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list rapl sub zones")
+	}
+	paths = append(paths, nested...)
+
+	seen := make(map[string]struct{})
+	domains := make([]RAPLDomain, 0, len(paths))
+	for _, path := range paths {
+		zone := filepath.Base(path)
+		if _, ok := seen[zone]; ok {
+			continue
+		}
+		seen[zone] = struct{}{}
+
+		nameBytes, err := os.ReadFile(filepath.Join(path, raplFileName))
+		if err != nil {
+			continue
+		}
+		name := strings.TrimSpace(string(nameBytes))
+		if !strings.HasPrefix(name, raplDomainPackagePrefix) && name != raplDomainDRAM {
+			continue
+		}
+
+		maxRange, err := ReadUint64File(filepath.Join(path, raplFileMaxEnergyRange))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read max energy range of rapl zone %s", zone)
+		}
+
+		domains = append(domains, RAPLDomain{
+			Name:             name,
+			Path:             path,
+			MaxEnergyRangeUJ: maxRange,
+		})
+	}
+
+	if len(domains) == 0 {
+		return nil, fmt.Errorf("no rapl package or dram zone found under %s", root)
+	}
+
+	sort.Slice(domains, func(i, j int) bool {
+		return filepath.Base(domains[i].Path) < filepath.Base(domains[j].Path)
+	})
+	return domains, nil
+}
+
+// EnergyDeltaUJ returns the energy consumed between two counter samples, taking the counter wraparound into account
+func EnergyDeltaUJ(prev, curr, maxRange uint64) uint64 {
+	if curr >= prev {
+		return curr - prev
+	}
+	return maxRange - prev + curr
+}
+
+// ReadUint64File reads a file containing a single unsigned integer, e.g. sysfs counters and settings
+func ReadUint64File(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// raplPowerReader derives the power usage of cpu packages and dram from powercap energy counters;
+// the reading is the average power since the previous sample
+type raplPowerReader struct {
+	root string
+
+	mutex       sync.Mutex
+	domains     []RAPLDomain
+	lastEnergy  []uint64
+	lastSampled time.Time
+}
+
+func (r *raplPowerReader) Init() error {
+	domains, err := DiscoverRAPLDomains(r.root)
+	if err != nil {
+		return errors.Wrap(err, "failed to discover rapl domains")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.domains = domains
+	return r.sample(time.Now())
+}
+
+func (r *raplPowerReader) Get(ctx context.Context) (int, error) {
+	return r.get(ctx, time.Now())
+}
+
+func (r *raplPowerReader) get(_ context.Context, now time.Time) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.domains) == 0 {
+		return 0, errors.New("rapl power reader is not initialized")
+	}
+
+	prevEnergy, prevSampled := r.lastEnergy, r.lastSampled
+	if err := r.sample(now); err != nil {
+		return 0, err
+	}
+
+	elapsed := now.Sub(prevSampled)
+	if elapsed <= 0 {
+		return 0, fmt.Errorf("invalid rapl sampling interval %v", elapsed)
+	}
+
+	var totalUJ uint64
+	for i, domain := range r.domains {
+		totalUJ += EnergyDeltaUJ(prevEnergy[i], r.lastEnergy[i], domain.MaxEnergyRangeUJ)
+	}
+
+	return int(float64(totalUJ) / float64(elapsed.Microseconds())), nil
+}
+
+// sample records the current counters of all domains; caller must hold the mutex
+func (r *raplPowerReader) sample(now time.Time) error {
+	energy := make([]uint64, len(r.domains))
+	for i, domain := range r.domains {
+		value, err := domain.ReadEnergyUJ()
+		if err != nil {
+			return errors.Wrapf(err, "failed to read energy of rapl domain %s", domain.Name)
+		}
+		energy[i] = value
+	}
+
+	r.lastEnergy = energy
+	r.lastSampled = now
+	return nil
+}
+
+func (r *raplPowerReader) Cleanup() {}
+
+// NewRAPLPowerReader returns a power reader of the rapl energy counters under powercap root,
+// which works without any external power metric source
+func NewRAPLPowerReader(root string) PowerReader {
+	if root == "" {
+		root = DefaultPowercapRoot
+	}
+	return &raplPowerReader{
+		root: root,
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRAPLZone(t *testing.T, dir, name string, energy, maxRange uint64) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "name"), []byte(name+"\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "energy_uj"), []byte(strconv.FormatUint(energy, 10)), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "max_energy_range_uj"), []byte(strconv.FormatUint(maxRange, 10)), 0o644))
+}
+
+func setRAPLEnergy(t *testing.T, dir string, energy uint64) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "energy_uj"), []byte(strconv.FormatUint(energy, 10)), 0o644))
+}
+
+func TestDiscoverRAPLDomains(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeRAPLZone(t, filepath.Join(root, "intel-rapl:0"), "package-0", 0, 1000)
+	writeRAPLZone(t, filepath.Join(root, "intel-rapl:0", "intel-rapl:0:0"), "core", 0, 1000)
+	writeRAPLZone(t, filepath.Join(root, "intel-rapl:0", "intel-rapl:0:1"), "dram", 0, 1000)
+	writeRAPLZone(t, filepath.Join(root, "intel-rapl:1"), "package-1", 0, 1000)
+	writeRAPLZone(t, filepath.Join(root, "intel-rapl:2"), "psys", 0, 1000)
+
+	domains, err := DiscoverRAPLDomains(root)
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		names = append(names, domain.Name)
+	}
+	assert.Equal(t, []string{"package-0", "dram", "package-1"}, names)
+	assert.True(t, domains[0].IsPackage())
+	assert.False(t, domains[1].IsPackage())
+
+	_, err = DiscoverRAPLDomains(t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestEnergyDeltaUJ(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, uint64(300), EnergyDeltaUJ(100, 400, 1000))
+	assert.Equal(t, uint64(300), EnergyDeltaUJ(900, 200, 1000))
+	assert.Equal(t, uint64(0), EnergyDeltaUJ(500, 500, 1000))
+}
+
+func TestRAPLPowerReader_get(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	pkg := filepath.Join(root, "intel-rapl:0")
+	dram := filepath.Join(root, "intel-rapl:0", "intel-rapl:0:0")
+	writeRAPLZone(t, pkg, "package-0", 1_000_000, 10_000_000)
+	writeRAPLZone(t, dram, "dram", 9_500_000, 10_000_000)
+
+	r := NewRAPLPowerReader(root).(*raplPowerReader)
+	require.NoError(t, r.Init())
+	start := r.lastSampled
+
+	// package: 8 J and dram: 0.2 J consumed in 2 seconds
+	setRAPLEnergy(t, pkg, 1_000_000+8_000_000)
+	setRAPLEnergy(t, dram, 9_500_000+200_000)
+	got, err := r.get(context.TODO(), start.Add(2*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 4, got)
+
+	setRAPLEnergy(t, pkg, 1_000_000)
+	setRAPLEnergy(t, dram, 200_000)
+	got, err = r.get(context.TODO(), start.Add(4*time.Second))
+	require.NoError(t, err)
+	// package wrapped: 10_000_000-9_000_000+1_000_000 = 2 J; dram wrapped: 10_000_000-9_700_000+200_000 = 0.5 J
+	assert.Equal(t, 1, got)
+
+	_, err = r.get(context.TODO(), start.Add(4*time.Second))
+	assert.Error(t, err)
+}
+
+func TestRAPLPowerReader_notInitialized(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewRAPLPowerReader(t.TempDir()).Get(context.TODO())
+	assert.Error(t, err)
+}
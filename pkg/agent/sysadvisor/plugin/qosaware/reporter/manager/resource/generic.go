@@ -225,6 +225,7 @@ func (m *GenericHeadroomManager) sync(_ context.Context) {
 	originResultFromAdvisor, numaResult, err := subAdvisor.GetHeadroom()
 	if err != nil {
 		klog.Errorf("get origin result %s from headroomAdvisor failed: %v", m.resourceName, err)
+		m.tryWarmStart()
 		return
 	}
 
@@ -253,6 +254,7 @@ func (m *GenericHeadroomManager) sync(_ context.Context) {
 
 	if reportResult == nil || !numaResultReady {
 		klog.Infof("skip update reclaimed resource %s without enough valid sample: %v", m.resourceName, numaResultReady)
+		m.tryWarmStart()
 		return
 	}
 
@@ -289,6 +291,35 @@ func (m *GenericHeadroomManager) sync(_ context.Context) {
 	}
 }
 
+// tryWarmStart reports the fresh headroom history restored from metacache if nothing has been reported
+// since restart, to avoid a dip of reported headroom before advisor and sliding windows are ready;
+// the lowest headroom in history is reported to avoid overcommitting reclaimed resources
+func (m *GenericHeadroomManager) tryWarmStart() {
+	if m.lastReportResult != nil || m.metaCache == nil {
+		return
+	}
+
+	history := m.metaCache.GetHeadroomHistory(string(m.resourceName))
+	if len(history) == 0 {
+		return
+	}
+
+	headroomInfo := &history[0].HeadroomInfo
+	for i := range history {
+		if history[i].TotalHeadroom < headroomInfo.TotalHeadroom {
+			headroomInfo = &history[i].HeadroomInfo
+		}
+	}
+
+	klog.Infof("headroom manager for %s warm starts with headroom %v", m.resourceName, headroomInfo.TotalHeadroom)
+	m.setLastReportResult(*resource.NewMilliQuantity(int64(headroomInfo.TotalHeadroom*1000), resource.DecimalSI))
+	for numaID, headroom := range headroomInfo.NUMAHeadroom {
+		result := m.reportResultTransformer(*resource.NewMilliQuantity(int64(headroom*1000), resource.DecimalSI))
+		m.lastNUMAReportResult[numaID] = result
+		m.emitNUMAResourceToMetric(numaID, metricsNameHeadroomReportNUMAResult, result)
+	}
+}
+
 func (m *GenericHeadroomManager) emitResourceToMetric(metricsName string, value resource.Quantity) {
 	_ = m.emitter.StoreInt64(metricsName, value.Value(), metrics.MetricTypeNameRaw,
 		metrics.MetricTag{Key: "resourceName", Val: string(m.resourceName)})
@@ -30,6 +30,7 @@ import (
 	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
 	hmadvisor "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
 	"github.com/kubewharf/katalyst-core/pkg/config"
 	"github.com/kubewharf/katalyst-core/pkg/metaserver"
 	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent"
@@ -192,3 +193,47 @@ func TestGenericHeadroomManager_Allocatable(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, int64(100000), capacity.MilliValue())
 }
+
+func TestGenericHeadroomManager_WarmStart(t *testing.T) {
+	t.Parallel()
+
+	conf := generateMachineConfig(t)
+	conf.GenericSysAdvisorConfiguration.StateWarmStartTTL = time.Minute
+	metaCache, err := metacache.NewMetaCacheImp(conf, metricspool.DummyMetricsEmitterPool{}, metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}))
+	require.NoError(t, err)
+	require.NoError(t, metaCache.SetHeadroomEntries(string(v1.ResourceCPU), &types.HeadroomInfo{
+		TotalHeadroom: 12,
+		NUMAHeadroom:  map[int]float64{0: 5, 1: 7},
+	}))
+	require.NoError(t, metaCache.SetHeadroomEntries(string(v1.ResourceCPU), &types.HeadroomInfo{
+		TotalHeadroom: 16,
+		NUMAHeadroom:  map[int]float64{0: 8, 1: 8},
+	}))
+
+	r := hmadvisor.NewResourceAdvisorStub()
+	r.SetHeadroom(v1.ResourceCPU, resource.MustParse("20"))
+	m := NewGenericHeadroomManager(v1.ResourceCPU, true, false,
+		time.Second, r, metrics.DummyMetrics{},
+		GenericSlidingWindowOptions{
+			SlidingWindowTime: 10 * time.Second,
+			MinStep:           resource.MustParse("0.3"),
+			MaxStep:           resource.MustParse("4"),
+		},
+		func() GenericReclaimOptions {
+			return GenericReclaimOptions{EnableReclaim: true}
+		},
+		generateTestMetaServer(t),
+		metaCache,
+	)
+
+	// sliding window isn't ready, so the lowest fresh headroom restored from metacache is reported
+	m.sync(context.Background())
+	allocatable, err := m.GetAllocatable()
+	require.NoError(t, err)
+	require.Equal(t, int64(12000), allocatable.MilliValue())
+	numaAllocatable, err := m.GetNumaAllocatable()
+	require.NoError(t, err)
+	numa0, numa1 := numaAllocatable[0], numaAllocatable[1]
+	require.Equal(t, int64(5000), numa0.MilliValue())
+	require.Equal(t, int64(7000), numa1.MilliValue())
+}
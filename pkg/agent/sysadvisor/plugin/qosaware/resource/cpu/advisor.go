@@ -39,6 +39,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region/headroompolicy"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region/provisionpolicy"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/helper"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
 	"github.com/kubewharf/katalyst-core/pkg/config"
 	"github.com/kubewharf/katalyst-core/pkg/metaserver"
@@ -71,6 +72,8 @@ func init() {
 	provisionpolicy.RegisterInitializer(types.CPUProvisionPolicyCanonical, provisionpolicy.NewPolicyCanonical)
 	provisionpolicy.RegisterInitializer(types.CPUProvisionPolicyRama, provisionpolicy.NewPolicyRama)
 	provisionpolicy.RegisterInitializer(types.CPUProvisionPolicyDynamicQuota, provisionpolicy.NewPolicyDynamicQuota)
+	provisionpolicy.RegisterInitializer(types.CPUProvisionPolicyMPC, provisionpolicy.NewPolicyMPC)
+	provisionpolicy.RegisterInitializer(types.CPUProvisionPolicyThroughput, provisionpolicy.NewPolicyThroughput)
 
 	headroompolicy.RegisterInitializer(types.CPUHeadroomPolicyNone, headroompolicy.NewPolicyNone)
 	headroompolicy.RegisterInitializer(types.CPUHeadroomPolicyCanonical, headroompolicy.NewPolicyCanonical)
@@ -108,6 +111,9 @@ type cpuResourceAdvisor struct {
 	isolator        isolation.Isolator
 	isolationSafety bool
 
+	// throughputIndicatorsCache is reset in each cycle
+	throughputIndicatorsCache *helper.ThroughputIndicatorsCache
+
 	mutex      sync.RWMutex
 	metaCache  metacache.MetaCache
 	metaServer *metaserver.MetaServer
@@ -133,11 +139,18 @@ func NewCPUResourceAdvisor(conf *config.Configuration, extraConf interface{}, me
 
 		isolator: isolation.NewLoadIsolator(conf, extraConf, emitter, metaCache, metaServer),
 
+		throughputIndicatorsCache: helper.NewThroughputIndicatorsCache(metaServer, conf.ThroughputIndicators),
+
 		metaCache:  metaCache,
 		metaServer: metaServer,
 		emitter:    emitter,
 	}
 
+	if conf.CPUIsolationConfiguration.InterferenceIsolation.Enabled {
+		cra.isolator = isolation.NewUnionIsolator(conf, metaCache, cra.isolator,
+			isolation.NewInterferenceIsolator(conf, extraConf, emitter, metaCache, metaServer))
+	}
+
 	cra.updateReservedForReclaim()
 
 	if err := cra.initializeProvisionAssembler(); err != nil {
@@ -201,6 +214,7 @@ func (cra *cpuResourceAdvisor) update() (*types.InternalCPUCalculationResult, er
 	general.InfoS("acquired lock", "duration", time.Since(startTime))
 	defer cra.mutex.Unlock()
 
+	cra.throughputIndicatorsCache.Reset()
 	result, err := cra.updateWithIsolationGuardian(true)
 	if err != nil {
 		if err == errIsolationSafetyCheckFailed {
@@ -388,6 +402,9 @@ func (cra *cpuResourceAdvisor) assignContainersToRegions() error {
 		} else if ci.Isolated || cra.conf.IsolationForceEnablePools.Has(ci.OriginOwnerPoolName) {
 			// isolated pool should not exist in metaCache.poolEntries
 			return true
+		} else if ci.QoSLevel == consts.PodAnnotationQoSLevelReclaimedCores {
+			// reclaim pool is not owned by batch region, and batch pool is generated by provision assembler
+			return true
 		} else {
 			// todo currently, we may call setPoolRegions multiple time, and we
 			//  depend on the reentrant of it, need to refine
@@ -416,6 +433,8 @@ func (cra *cpuResourceAdvisor) assignToRegions(ci *types.ContainerInfo) ([]regio
 		return cra.assignShareContainerToRegions(ci)
 	case consts.PodAnnotationQoSLevelDedicatedCores:
 		return cra.assignDedicatedContainerToRegions(ci)
+	case consts.PodAnnotationQoSLevelReclaimedCores:
+		return cra.assignReclaimedContainerToRegions(ci)
 	default:
 		return nil, nil
 	}
@@ -527,6 +546,41 @@ func (cra *cpuResourceAdvisor) assignDedicatedContainerToRegions(ci *types.Conta
 	return regions, nil
 }
 
+func (cra *cpuResourceAdvisor) assignReclaimedContainerToRegions(ci *types.ContainerInfo) ([]region.QoSRegion, error) {
+	// only reclaimed cores containers with throughput slo are assigned to batch region
+	if len(cra.conf.ThroughputIndicators) == 0 {
+		return nil, nil
+	}
+	indicators, err := cra.throughputIndicatorsCache.Get(context.Background(), ci.PodUID)
+	if err != nil {
+		// keep the container in reclaim pool as best-effort ones, instead of failing all regions
+		klog.Warningf("[qosaware-cpu] get throughput indicators for container %s/%s failed, fall back to reclaim pool: %v",
+			ci.PodUID, ci.ContainerName, err)
+		return nil, nil
+	} else if len(indicators) == 0 {
+		return nil, nil
+	}
+
+	// if there already exists a batch region for this pod, just reuse it
+	regions, err := cra.getContainerRegions(ci, types.QoSRegionTypeBatch)
+	if err != nil {
+		return nil, err
+	} else if len(regions) > 0 {
+		return regions, nil
+	}
+
+	// all batch pods share only one region in batch pool
+	for _, r := range cra.regionMap {
+		if r.Type() == types.QoSRegionTypeBatch {
+			return []region.QoSRegion{r}, nil
+		}
+	}
+
+	r := region.NewQoSRegionBatch(ci, cra.conf, cra.extraConf, cra.throughputIndicatorsCache, cra.metaCache, cra.metaServer, cra.emitter)
+	klog.Infof("create a new batch region (%s/%s) for container %s/%s", r.OwnerPoolName(), r.Name(), ci.PodUID, ci.ContainerName)
+	return []region.QoSRegion{r}, nil
+}
+
 // gcRegionMap deletes empty regions in region map
 func (cra *cpuResourceAdvisor) gcRegionMap() {
 	for regionName, r := range cra.regionMap {
@@ -563,10 +617,15 @@ func (cra *cpuResourceAdvisor) updateAdvisorEssentials() {
 
 	for _, r := range cra.regionMap {
 		// set binding numas for non numa binding regions
-		if !r.IsNumaBinding() && r.Type() == configapi.QoSRegionTypeShare {
+		if !r.IsNumaBinding() && (r.Type() == configapi.QoSRegionTypeShare || r.Type() == types.QoSRegionTypeBatch) {
 			r.SetBindingNumas(cra.nonBindingNumas)
 		}
 
+		// batch region doesn't share reserved resource with other regions
+		if r.Type() == types.QoSRegionTypeBatch {
+			continue
+		}
+
 		// accumulate region quantity for each numa
 		for _, numaID := range r.GetBindingNumas().ToSliceInt() {
 			cra.numRegionsPerNuma[numaID] += 1
@@ -255,6 +255,11 @@ func (cra *cpuResourceAdvisor) getRegionMaxRequirement(r region.QoSRegion) float
 			})
 			res = general.MaxFloat64(1, res)
 		}
+	case types.QoSRegionTypeBatch:
+		// batch pool may take up all cpus that would be reclaimed otherwise
+		for _, numaID := range r.GetBindingNumas().ToSliceInt() {
+			res += float64(cra.numaAvailable[numaID])
+		}
 	default:
 		for _, numaID := range r.GetBindingNumas().ToSliceInt() {
 			res += float64(cra.numaAvailable[numaID] - cra.reservedForReclaim[numaID])
@@ -283,6 +288,8 @@ func (cra *cpuResourceAdvisor) getRegionMinRequirement(r region.QoSRegion) float
 		return res
 	case configapi.QoSRegionTypeDedicated:
 		return types.MinDedicatedCPURequirement
+	case types.QoSRegionTypeBatch:
+		return types.MinBatchCPURequirement
 	default:
 		klog.Errorf("[qosaware-cpu] unknown region type %v", r.Type())
 		return 0.0
@@ -291,6 +298,10 @@ func (cra *cpuResourceAdvisor) getRegionMinRequirement(r region.QoSRegion) float
 
 func (cra *cpuResourceAdvisor) getRegionReservedForReclaim(r region.QoSRegion) float64 {
 	res := 0.0
+	// batch pool itself comes from reclaimed resources, so nothing is reserved for reclaim in it
+	if r.Type() == types.QoSRegionTypeBatch {
+		return res
+	}
 	for _, numaID := range r.GetBindingNumas().ToSliceInt() {
 		divider := cra.numRegionsPerNuma[numaID]
 		if divider < 1 {
@@ -303,6 +314,9 @@ func (cra *cpuResourceAdvisor) getRegionReservedForReclaim(r region.QoSRegion) f
 
 func (cra *cpuResourceAdvisor) getRegionReservedForAllocate(r region.QoSRegion) float64 {
 	res := 0.0
+	if r.Type() == types.QoSRegionTypeBatch {
+		return res
+	}
 	for _, numaID := range r.GetBindingNumas().ToSliceInt() {
 		divider := cra.numRegionsPerNuma[numaID]
 		if divider < 1 {
@@ -315,6 +329,7 @@ func (cra *cpuResourceAdvisor) getRegionReservedForAllocate(r region.QoSRegion)
 
 func (cra *cpuResourceAdvisor) updateRegionEntries() {
 	entries := make(types.RegionEntries)
+	regulatorEntries := make(types.RegulatorEntries)
 	for regionName, r := range cra.regionMap {
 		regionInfo := &types.RegionInfo{
 			RegionName:    r.Name(),
@@ -324,7 +339,10 @@ func (cra *cpuResourceAdvisor) updateRegionEntries() {
 			Pods:          r.GetPods(),
 		}
 
-		if r.Type() == configapi.QoSRegionTypeShare || r.Type() == configapi.QoSRegionTypeDedicated {
+		// batch pool is counted into reclaimed resources by headroom assembler, so the headroom
+		// policy of batch region is none by default
+		if r.Type() == configapi.QoSRegionTypeShare || r.Type() == configapi.QoSRegionTypeDedicated ||
+			r.Type() == types.QoSRegionTypeBatch {
 			headroom, err := r.GetHeadroom()
 			if err != nil {
 				general.ErrorS(err, "failed to get region headroom", "regionName", r.Name())
@@ -343,11 +361,15 @@ func (cra *cpuResourceAdvisor) updateRegionEntries() {
 		}
 
 		entries[regionName] = regionInfo
+		for key, info := range r.GetRegulatorEntries() {
+			regulatorEntries[key] = info
+		}
 
 		general.InfoS("region info", "info", regionInfo)
 	}
 
 	_ = cra.metaCache.SetRegionEntries(entries)
+	_ = cra.metaCache.SetRegulatorEntries(regulatorEntries)
 }
 
 func (cra *cpuResourceAdvisor) updateRegionStatus() {
@@ -127,6 +127,7 @@ func (ha *HeadroomAssemblerCommon) getHeadroomDefault() (resource.Quantity, map[
 
 			cpuSets = cpuSets.Union(cpuSet)
 		}
+		cpuSets = cpuSets.Union(ha.getBatchCPUSet(nonBindingNUMAs))
 
 		reclaimMetrics, err := metricHelper.GetReclaimMetrics(cpuSets, common.GetReclaimRelativeRootCgroupPath(ha.conf.ReclaimRelativeRootCgroupPath, commonstate.FakedNUMAID), ha.metaServer.MetricsFetcher)
 		if err != nil {
@@ -217,6 +218,7 @@ func (ha *HeadroomAssemblerCommon) getHeadroomByUtil() (resource.Quantity, map[i
 			cpusets = cpusets.Union(cpuSet)
 			lastReclaimedCPUPerNumaForCalculate[numaID] = reclaimedCPUs[numaID]
 		}
+		cpusets = cpusets.Union(ha.getBatchCPUSet(nonBindingNUMAs))
 
 		reclaimMetrics, err := metricHelper.GetReclaimMetrics(cpusets, common.GetReclaimRelativeRootCgroupPath(ha.conf.ReclaimRelativeRootCgroupPath, commonstate.FakedNUMAID), ha.metaServer.MetricsFetcher)
 		if err != nil {
@@ -27,11 +27,13 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/helper"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
 	metaserverHelper "github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric/helper"
 	"github.com/kubewharf/katalyst-core/pkg/util"
 	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
 	"github.com/kubewharf/katalyst-core/pkg/util/native"
 )
 
@@ -180,3 +182,20 @@ func (ha *HeadroomAssemblerCommon) getReclaimNUMABindingTopo(reclaimPool *types.
 
 	return
 }
+
+// getBatchCPUSet returns cpus of batch pool in the given numas; since batch pool is
+// carved out of reclaimed resources, it should be counted into reclaimed headroom too
+func (ha *HeadroomAssemblerCommon) getBatchCPUSet(numaIDs []int) machine.CPUSet {
+	cpuSet := machine.NewCPUSet()
+	batchPoolInfo, ok := ha.metaReader.GetPoolInfo(commonstate.PoolNameBatch)
+	if !ok || batchPoolInfo == nil {
+		return cpuSet
+	}
+
+	for _, numaID := range numaIDs {
+		if cpus, ok := batchPoolInfo.TopologyAwareAssignments[numaID]; ok {
+			cpuSet = cpuSet.Union(cpus)
+		}
+	}
+	return cpuSet
+}
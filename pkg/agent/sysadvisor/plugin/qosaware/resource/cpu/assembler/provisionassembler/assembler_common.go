@@ -218,6 +218,8 @@ func (pa *ProvisionAssemblerCommon) assembleWithoutNUMAExclusivePool(
 		return err
 	}
 
+	batchRequirement := extractBatchRegionRequirement(regionHelper.GetRegions(numaID, types.QoSRegionTypeBatch))
+
 	// skip empty numa binding region
 	if len(shareRegions) == 0 && len(isolationRegions) == 0 && len(dedicatedRegions) == 0 && numaID != commonstate.FakedNUMAID {
 		return nil
@@ -234,10 +236,28 @@ func (pa *ProvisionAssemblerCommon) assembleWithoutNUMAExclusivePool(
 
 	reservedForReclaim := getNUMAsResource(*pa.reservedForReclaim, numaSet)
 	shareAndIsolatedDedicatedPoolAvailable := getNUMAsResource(*pa.numaAvailable, numaSet)
+	sharePoolSizeRequirements := getPoolSizeRequirements(shareInfo)
 	if !*pa.allowSharedCoresOverlapReclaimedCores {
 		shareAndIsolatedDedicatedPoolAvailable -= reservedForReclaim
 	}
-	sharePoolSizeRequirements := getPoolSizeRequirements(shareInfo)
+
+	// carve batch pool out of the resources that would be reclaimed otherwise, so that batch pods
+	// get dedicated cpus to meet their throughput slo, but never squeeze share, isolation and
+	// dedicated pools below their requirements
+	batchPoolSize := 0
+	if nodeEnableReclaim && batchRequirement > 0 {
+		isolationLowers := general.SumUpMapValues(isolationInfo.isolationLowerSizes)
+		nonReclaimRequirements := general.SumUpMapValues(sharePoolSizeRequirements) + isolationLowers +
+			general.SumUpMapValues(getPoolSizeRequirements(dedicatedInfo))
+		batchAvailable := shareAndIsolatedDedicatedPoolAvailable - nonReclaimRequirements
+		if *pa.allowSharedCoresOverlapReclaimedCores {
+			// reclaim pool overlaps with share and dedicated pools in this mode,
+			// so they must be left large enough to hold resources reserved for reclaim
+			batchAvailable = general.Min(batchAvailable, shareAndIsolatedDedicatedPoolAvailable-isolationLowers-reservedForReclaim)
+		}
+		batchPoolSize = general.Min(batchRequirement, general.Max(batchAvailable, 0))
+		shareAndIsolatedDedicatedPoolAvailable -= batchPoolSize
+	}
 
 	isolationUppers := general.SumUpMapValues(isolationInfo.isolationUpperSizes)
 	isolationPoolSizes := isolationInfo.isolationUpperSizes
@@ -274,6 +294,8 @@ func (pa *ProvisionAssemblerCommon) assembleWithoutNUMAExclusivePool(
 	general.InfoS("pool info",
 		"numaID", numaID,
 		"reservedForReclaim", reservedForReclaim,
+		"batchRequirement", batchRequirement,
+		"batchPoolSize", batchPoolSize,
 		"shareRequirements", shareInfo.requirements,
 		"shareRequests", shareInfo.requests,
 		"shareReclaimEnable", shareInfo.reclaimEnable,
@@ -304,6 +326,10 @@ func (pa *ProvisionAssemblerCommon) assembleWithoutNUMAExclusivePool(
 		}
 	}
 
+	if batchPoolSize > 0 {
+		result.SetPoolEntry(commonstate.PoolNameBatch, numaID, batchPoolSize, -1)
+	}
+
 	// assemble reclaim pool
 	var reclaimedCoresSize, overlapReclaimedCoresSize int
 	reclaimedCoresQuota := float64(-1)
@@ -541,6 +567,21 @@ func getPoolSizeRequirements(info regionInfo) map[string]int {
 	return result
 }
 
+// extractBatchRegionRequirement returns the sum of cpu requirements of batch regions, and
+// batch regions without valid provision require nothing more than reserved resource for reclaim
+func extractBatchRegionRequirement(batchRegions []region.QoSRegion) int {
+	requirement := 0
+	for _, r := range batchRegions {
+		controlKnob, err := r.GetProvision()
+		if err != nil {
+			general.Warningf("get provision of batch region %v failed: %v", r.Name(), err)
+			continue
+		}
+		requirement += int(math.Ceil(controlKnob[types.ControlKnobReclaimedBatchCPURequirement].Value))
+	}
+	return requirement
+}
+
 type isolationRegionInfo struct {
 	isolationUpperSizes map[string]int
 	isolationLowerSizes map[string]int
@@ -17,6 +17,7 @@ limitations under the License.
 package provisionassembler
 
 import (
+	"fmt"
 	"os"
 	"testing"
 
@@ -29,6 +30,7 @@ import (
 	"github.com/kubewharf/katalyst-api/pkg/consts"
 	katalyst_base "github.com/kubewharf/katalyst-core/cmd/base"
 	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
@@ -141,6 +143,10 @@ func (fake *FakeRegion) GetHeadroom() (float64, error) {
 	return fake.headroom, nil
 }
 
+func (fake *FakeRegion) GetRegulatorEntries() types.RegulatorEntries {
+	return types.RegulatorEntries{}
+}
+
 func (fake *FakeRegion) IsThrottled() bool {
 	return fake.throttled
 }
@@ -262,6 +268,15 @@ func TestAssembleProvision(t *testing.T) {
 				0: machine.NewCPUSet(1, 2, 3, 4, 5, 6, 7, 8, 9, 10),
 			},
 		},
+		"batch": {
+			PoolName: "batch",
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.NewCPUSet(11, 12, 13, 14),
+			},
+			OriginalTopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.NewCPUSet(11, 12, 13, 14),
+			},
+		},
 	}
 	tests := []struct {
 		name                                  string
@@ -988,6 +1003,86 @@ func TestAssembleProvision(t *testing.T) {
 				"reclaim": {-1: map[string]int{"share-a": 18, "share-b": 16}},
 			},
 		},
+		{
+			name:            "share and batch pool non binding NUMAs",
+			enableReclaimed: true,
+			poolInfos: []testCasePoolConfig{
+				{
+					poolName:      "share",
+					poolType:      configapi.QoSRegionTypeShare,
+					numa:          machine.NewCPUSet(0, 1),
+					isNumaBinding: false,
+					provision: types.ControlKnob{
+						configapi.ControlKnobNonReclaimedCPURequirement: {Value: 6},
+					},
+				},
+				{
+					poolName:      "batch",
+					poolType:      types.QoSRegionTypeBatch,
+					numa:          machine.NewCPUSet(0, 1),
+					isNumaBinding: false,
+					provision: types.ControlKnob{
+						types.ControlKnobReclaimedBatchCPURequirement: {Value: 10},
+					},
+				},
+			},
+			expectPoolEntries: map[string]map[int]types.CPUResource{
+				"share": {
+					-1: types.CPUResource{Size: 6, Quota: -1},
+				},
+				"batch": {
+					-1: types.CPUResource{Size: 10, Quota: -1},
+				},
+				"reserve": {
+					-1: types.CPUResource{Size: 0, Quota: -1},
+				},
+				"reclaim": {
+					-1: types.CPUResource{Size: 32, Quota: -1},
+				},
+			},
+		},
+		{
+			name:                                  "share and batch pool non binding NUMAs, overlap reclaimed cores",
+			enableReclaimed:                       true,
+			allowSharedCoresOverlapReclaimedCores: true,
+			poolInfos: []testCasePoolConfig{
+				{
+					poolName:      "share",
+					poolType:      configapi.QoSRegionTypeShare,
+					numa:          machine.NewCPUSet(0, 1),
+					isNumaBinding: false,
+					provision: types.ControlKnob{
+						configapi.ControlKnobNonReclaimedCPURequirement: {Value: 6},
+					},
+				},
+				{
+					poolName:      "batch",
+					poolType:      types.QoSRegionTypeBatch,
+					numa:          machine.NewCPUSet(0, 1),
+					isNumaBinding: false,
+					provision: types.ControlKnob{
+						types.ControlKnobReclaimedBatchCPURequirement: {Value: 50},
+					},
+				},
+			},
+			expectPoolEntries: map[string]map[int]types.CPUResource{
+				"share": {
+					-1: types.CPUResource{Size: 8, Quota: -1},
+				},
+				"batch": {
+					-1: types.CPUResource{Size: 40, Quota: -1},
+				},
+				"reserve": {
+					-1: types.CPUResource{Size: 0, Quota: -1},
+				},
+				"reclaim": {
+					-1: types.CPUResource{Size: 0, Quota: -1},
+				},
+			},
+			expectPoolOverlapInfo: map[string]map[int]map[string]int{
+				"reclaim": {-1: map[string]int{"share": 8}},
+			},
+		},
 	}
 
 	reservedForReclaim := map[int]int{
@@ -1074,3 +1169,25 @@ func generateTestConf(t *testing.T, enableReclaim bool) *config.Configuration {
 	conf.GetDynamicConfiguration().EnableReclaim = enableReclaim
 	return conf
 }
+
+type failedProvisionRegion struct {
+	*FakeRegion
+}
+
+func (r *failedProvisionRegion) GetProvision() (types.ControlKnob, error) {
+	return nil, fmt.Errorf("no succeeded policy")
+}
+
+func TestExtractBatchRegionRequirement(t *testing.T) {
+	t.Parallel()
+
+	batch := NewFakeRegion("batch-1", types.QoSRegionTypeBatch, commonstate.PoolNameBatch)
+	batch.SetProvision(types.ControlKnob{
+		types.ControlKnobReclaimedBatchCPURequirement: types.ControlKnobItem{Value: 3.2},
+	})
+	failed := &failedProvisionRegion{FakeRegion: NewFakeRegion("batch-2", types.QoSRegionTypeBatch, commonstate.PoolNameBatch)}
+
+	require.Equal(t, 0, extractBatchRegionRequirement(nil))
+	require.Equal(t, 4, extractBatchRegionRequirement([]region.QoSRegion{batch}))
+	require.Equal(t, 4, extractBatchRegionRequirement([]region.QoSRegion{batch, failed}))
+}
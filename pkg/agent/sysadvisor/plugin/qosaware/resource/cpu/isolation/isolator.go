@@ -16,6 +16,19 @@ limitations under the License.
 
 package isolation
 
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
 // Isolator works as a helper component to judge the isolation status
 // for each existing container; we will get different implementations.
 type Isolator interface {
@@ -23,3 +36,106 @@ type Isolator interface {
 	// the returned slice contains the isolated pod-uid
 	GetIsolatedPods() []string
 }
+
+// unionIsolator merges isolated pods from several isolators, and a pod is
+// isolated as long as any of the isolators regards it as isolated. since each
+// isolator only limits its own results, the pool limitations are applied again
+// to the merged results, i.e. isolated pods in a pool can't exceed the larger one
+// of pod-ratio and max-isolated-pods, and at least one pod should be left in the pool.
+type unionIsolator struct {
+	conf       *cpu.CPUIsolationConfiguration
+	metaReader metacache.MetaReader
+	isolators  []Isolator
+
+	// isolatedPods records pod-uid isolated in the last round, to avoid flapping when exceeding limitations
+	isolatedPods     sets.String
+	configTranslator *general.CommonSuffixTranslator
+}
+
+func NewUnionIsolator(conf *config.Configuration, metaCache metacache.MetaReader, isolators ...Isolator) Isolator {
+	return &unionIsolator{
+		conf:             conf.CPUIsolationConfiguration,
+		metaReader:       metaCache,
+		isolators:        isolators,
+		isolatedPods:     sets.NewString(),
+		configTranslator: general.NewCommonSuffixTranslator(commonstate.NUMAPoolInfix),
+	}
+}
+
+func (u *unionIsolator) GetIsolatedPods() []string {
+	// candidates are ranked by the order of isolators that firstly regard them as isolated
+	ranks := make(map[string]int)
+	for idx, isolator := range u.isolators {
+		for _, podUID := range isolator.GetIsolatedPods() {
+			if _, ok := ranks[podUID]; !ok {
+				ranks[podUID] = idx
+			}
+		}
+	}
+
+	podPools := make(map[string]string)
+	podCounts := make(map[string]int)
+	u.metaReader.RangeContainer(func(podUID string, _ string, ci *types.ContainerInfo) bool {
+		if !checkTargetContainer(ci) {
+			return true
+		}
+		if _, ok := podPools[podUID]; !ok {
+			podPools[podUID] = ci.OriginOwnerPoolName
+			podCounts[ci.OriginOwnerPoolName]++
+		}
+		return true
+	})
+
+	candidates := make(map[string][]string)
+	for podUID := range ranks {
+		pool, ok := podPools[podUID]
+		if !ok {
+			general.Warningf("pod %v can't be isolated: not found in any shared pool", podUID)
+			continue
+		}
+		candidates[pool] = append(candidates[pool], podUID)
+	}
+
+	uidSets := sets.NewString()
+	for pool, podUIDs := range candidates {
+		// prefer pods that have already been isolated to avoid flapping, and then by isolator orders
+		sort.Slice(podUIDs, func(a, b int) bool {
+			ia, ib := u.isolatedPods.Has(podUIDs[a]), u.isolatedPods.Has(podUIDs[b])
+			if ia != ib {
+				return ia
+			}
+			if ranks[podUIDs[a]] != ranks[podUIDs[b]] {
+				return ranks[podUIDs[a]] < ranks[podUIDs[b]]
+			}
+			return podUIDs[a] < podUIDs[b]
+		})
+
+		maxPods := general.Min(u.getPoolMaxIsolatedPods(pool, podCounts[pool]), podCounts[pool]-1)
+		for idx, podUID := range podUIDs {
+			if idx >= maxPods {
+				general.Warningf("pod %v can't be isolated: exceeds max pods %v in pool %v", podUID, maxPods, pool)
+				continue
+			}
+			uidSets.Insert(podUID)
+		}
+	}
+
+	u.isolatedPods = uidSets
+	return uidSets.List()
+}
+
+// getPoolMaxIsolatedPods returns the larger one of pod-ratio and max-isolated-pods for the pool,
+// so that merging isolators never isolates less pods than any single one of them
+func (u *unionIsolator) getPoolMaxIsolatedPods(pool string, totalPods int) int {
+	key := u.configTranslator.Translate(pool)
+
+	ratio := u.conf.IsolatedMaxPodRatio
+	if r, ok := u.conf.IsolatedMaxPoolPodRatios[key]; ok {
+		ratio = r
+	}
+	maxPods := u.conf.InterferenceIsolation.MaxIsolatedPods
+	if m, ok := u.conf.InterferenceIsolation.MaxPoolIsolatedPods[key]; ok {
+		maxPods = m
+	}
+	return general.Max(int(float32(totalPods)*ratio), maxPods)
+}
@@ -0,0 +1,335 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isolation
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/clock"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu"
+	metric_consts "github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	// cpiBaselineRiseFactor is the weight of the latest cpi when the baseline follows upwards,
+	// and it should be small enough to make sure persistent degradation can still be observed
+	cpiBaselineRiseFactor = 0.01
+
+	metricInterferenceIsolationScore = "interference_isolation_score"
+)
+
+// podInterferenceSignals records interference signals aggregated from containers in one pod
+type podInterferenceSignals struct {
+	pool string
+
+	memBandwidth   float64
+	llcMiss        float64
+	cpiDegradation float64
+	throttleRatio  float64
+
+	score float64
+}
+
+type podInterferenceState struct {
+	isolated             bool
+	offendFirstObserved  *time.Time
+	quietFirstObserved   *time.Time
+	lastObservedPoolName string
+}
+
+// InterferenceIsolator decides isolation states based on interference fingerprints for pods,
+// i.e. pods occupying most of the memory bandwidth and llc in the pool while their neighbours
+// are suffering from cpi degradation and cpu throttling are regarded as noisy neighbours.
+type InterferenceIsolator struct {
+	conf *cpu.CPUIsolationConfiguration
+
+	emitter    metrics.MetricEmitter
+	metaReader metacache.MetaReader
+	metaServer *metaserver.MetaServer
+
+	mutex sync.Mutex
+	// map from pod-uid to podInterferenceState
+	states map[string]*podInterferenceState
+	// map from pod/container pair to cpi baseline
+	cpiBaselines map[string]float64
+
+	configTranslator *general.CommonSuffixTranslator
+	clock            clock.Clock
+}
+
+func NewInterferenceIsolator(conf *config.Configuration, _ interface{}, emitter metrics.MetricEmitter,
+	metaCache metacache.MetaReader, metaServer *metaserver.MetaServer,
+) Isolator {
+	return &InterferenceIsolator{
+		conf: conf.CPUIsolationConfiguration,
+
+		emitter:    emitter,
+		metaReader: metaCache,
+		metaServer: metaServer,
+
+		states:       make(map[string]*podInterferenceState),
+		cpiBaselines: make(map[string]float64),
+
+		configTranslator: general.NewCommonSuffixTranslator(commonstate.NUMAPoolInfix),
+		clock:            clock.RealClock{},
+	}
+}
+
+func (i *InterferenceIsolator) GetIsolatedPods() []string {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if i.conf.IsolationDisabled || !i.conf.InterferenceIsolation.Enabled {
+		i.states = make(map[string]*podInterferenceState)
+		return []string{}
+	}
+
+	signals := i.collectSignals()
+	i.scoreSignals(signals)
+
+	now := i.clock.Now()
+	candidates := make(map[string][]string)
+	for podUID, s := range signals {
+		if i.checkPodIsolated(podUID, s, now) {
+			candidates[s.pool] = append(candidates[s.pool], podUID)
+		}
+	}
+
+	podCounts := make(map[string]int)
+	for _, s := range signals {
+		podCounts[s.pool]++
+	}
+
+	uidSets := sets.NewString()
+	for pool, podUIDs := range candidates {
+		// prefer pods that have already been isolated to avoid flapping, and then the ones with higher scores
+		sort.Slice(podUIDs, func(a, b int) bool {
+			sa, sb := i.states[podUIDs[a]], i.states[podUIDs[b]]
+			if sa.isolated != sb.isolated {
+				return sa.isolated
+			}
+			if signals[podUIDs[a]].score != signals[podUIDs[b]].score {
+				return signals[podUIDs[a]].score > signals[podUIDs[b]].score
+			}
+			return podUIDs[a] < podUIDs[b]
+		})
+
+		// at least one pod should be left in the pool
+		maxPods := general.Min(i.getPoolMaxIsolatedPods(pool), podCounts[pool]-1)
+		for idx, podUID := range podUIDs {
+			if idx >= maxPods {
+				general.Warningf("pod %v can't be isolated: exceeds max pods %v in pool %v", podUID, maxPods, pool)
+				i.states[podUID].isolated = false
+				continue
+			}
+
+			general.Infof("add pod %v to isolation for interference score %.3f in pool %v", podUID, signals[podUID].score, pool)
+			i.states[podUID].isolated = true
+			uidSets.Insert(podUID)
+		}
+	}
+
+	// clear in-memory cached isolation states if the corresponding pod exited or is no longer a target
+	for podUID := range i.states {
+		if _, ok := signals[podUID]; !ok {
+			delete(i.states, podUID)
+		}
+	}
+	return uidSets.List()
+}
+
+// checkPodIsolated returns true if the pod keeps as an offender for lock-in duration,
+// or it has been isolated and doesn't keep quiet for lock-out duration
+func (i *InterferenceIsolator) checkPodIsolated(podUID string, s *podInterferenceSignals, now time.Time) bool {
+	state, ok := i.states[podUID]
+	if !ok || state.lastObservedPoolName != s.pool {
+		state = &podInterferenceState{lastObservedPoolName: s.pool}
+		i.states[podUID] = state
+	}
+
+	if s.score > i.conf.InterferenceIsolation.ScoreThreshold {
+		state.quietFirstObserved = nil
+		if state.offendFirstObserved == nil {
+			state.offendFirstObserved = &now
+		}
+		return state.isolated || !state.offendFirstObserved.Add(i.conf.InterferenceIsolation.LockInDuration).After(now)
+	}
+
+	state.offendFirstObserved = nil
+	if !state.isolated {
+		return false
+	}
+	if state.quietFirstObserved == nil {
+		state.quietFirstObserved = &now
+	}
+	if !state.quietFirstObserved.Add(i.conf.InterferenceIsolation.LockOutDuration).After(now) {
+		state.isolated = false
+		state.quietFirstObserved = nil
+		return false
+	}
+	return true
+}
+
+// collectSignals aggregates interference signals from containers into pods for each target pool
+func (i *InterferenceIsolator) collectSignals() map[string]*podInterferenceSignals {
+	signals := make(map[string]*podInterferenceSignals)
+	existed := sets.NewString()
+
+	i.metaReader.RangeContainer(func(podUID string, containerName string, ci *types.ContainerInfo) bool {
+		if !checkTargetContainer(ci) {
+			return true
+		} else if i.conf.IsolationDisabledPools.Has(i.configTranslator.Translate(ci.OriginOwnerPoolName)) {
+			return true
+		}
+
+		s, ok := signals[podUID]
+		if !ok {
+			s = &podInterferenceSignals{pool: ci.OriginOwnerPoolName}
+			signals[podUID] = s
+		}
+
+		meta := containerMeta(ci)
+		existed.Insert(meta)
+
+		s.memBandwidth += i.getContainerMetric(ci, metric_consts.MetricMemBandwidthReadContainer)
+		s.memBandwidth += i.getContainerMetric(ci, metric_consts.MetricMemBandwidthWriteContainer)
+		s.llcMiss += i.getContainerMetric(ci, metric_consts.MetricCPUL3CacheMissRateContainer)
+		s.cpiDegradation = general.MaxFloat64(s.cpiDegradation, i.getCPIDegradation(ci, meta))
+		s.throttleRatio = general.MaxFloat64(s.throttleRatio, i.getThrottleRatio(ci))
+		return true
+	})
+
+	for meta := range i.cpiBaselines {
+		if !existed.Has(meta) {
+			delete(i.cpiBaselines, meta)
+		}
+	}
+	return signals
+}
+
+// scoreSignals calculates interference score for each pod, which consists of the share of memory bandwidth
+// and llc misses in its pool, and the pressure its neighbours are suffering weighted by its own share;
+// pods are not scored at all unless their neighbours are actually suffering
+func (i *InterferenceIsolator) scoreSignals(signals map[string]*podInterferenceSignals) {
+	type poolSummary struct {
+		pods           int
+		memBandwidth   float64
+		llcMiss        float64
+		cpiDegradation float64
+		throttleRatio  float64
+	}
+
+	summaries := make(map[string]*poolSummary)
+	for _, s := range signals {
+		ps, ok := summaries[s.pool]
+		if !ok {
+			ps = &poolSummary{}
+			summaries[s.pool] = ps
+		}
+		ps.pods++
+		ps.memBandwidth += s.memBandwidth
+		ps.llcMiss += s.llcMiss
+		ps.cpiDegradation += s.cpiDegradation
+		ps.throttleRatio += s.throttleRatio
+	}
+
+	weights := i.conf.InterferenceIsolation
+	for podUID, s := range signals {
+		ps := summaries[s.pool]
+		if ps.pods <= 1 {
+			s.score = 0
+			continue
+		}
+
+		memBandwidthShare := safeShare(s.memBandwidth, ps.memBandwidth)
+		llcMissShare := safeShare(s.llcMiss, ps.llcMiss)
+		neighbourCPIDegradation := (ps.cpiDegradation - s.cpiDegradation) / float64(ps.pods-1)
+		neighbourThrottleRatio := (ps.throttleRatio - s.throttleRatio) / float64(ps.pods-1)
+		neighbourPressure := weights.CPIDegradationWeight*neighbourCPIDegradation + weights.ThrottleWeight*neighbourThrottleRatio
+
+		if neighbourPressure < weights.NeighbourPressureThreshold {
+			s.score = 0
+		} else {
+			s.score = weights.MemBandwidthWeight*memBandwidthShare + weights.LLCMissWeight*llcMissShare +
+				neighbourPressure*(memBandwidthShare+llcMissShare)/2
+		}
+
+		general.InfofV(4, "pod %v in pool %v interference score %.3f: mbw share %.3f, llc share %.3f, "+
+			"neighbour cpi degradation %.3f, neighbour throttle ratio %.3f", podUID, s.pool, s.score,
+			memBandwidthShare, llcMissShare, neighbourCPIDegradation, neighbourThrottleRatio)
+		_ = i.emitter.StoreFloat64(metricInterferenceIsolationScore, s.score, metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: "pool", Val: s.pool}, metrics.MetricTag{Key: "pod", Val: podUID})
+	}
+}
+
+// getCPIDegradation returns the ratio that the current cpi exceeds its baseline
+func (i *InterferenceIsolator) getCPIDegradation(ci *types.ContainerInfo, meta string) float64 {
+	cpi := i.getContainerMetric(ci, metric_consts.MetricCPUCPIContainer)
+	if cpi <= 0 {
+		return 0
+	}
+
+	baseline, ok := i.cpiBaselines[meta]
+	if !ok || cpi < baseline {
+		// the baseline follows downwards immediately, since lower cpi is always achievable
+		i.cpiBaselines[meta] = cpi
+		return 0
+	}
+
+	i.cpiBaselines[meta] = baseline + cpiBaselineRiseFactor*(cpi-baseline)
+	return cpi/baseline - 1
+}
+
+// getThrottleRatio returns the ratio of throttled periods of the container
+func (i *InterferenceIsolator) getThrottleRatio(ci *types.ContainerInfo) float64 {
+	return safeShare(i.getContainerMetric(ci, metric_consts.MetricCPUNrThrottledRateContainer),
+		i.getContainerMetric(ci, metric_consts.MetricCPUNrPeriodRateContainer))
+}
+
+func (i *InterferenceIsolator) getContainerMetric(ci *types.ContainerInfo, metricName string) float64 {
+	m, err := i.metaServer.GetContainerMetric(ci.PodUID, ci.ContainerName, metricName)
+	if err != nil {
+		general.InfofV(4, "get %v for pod %v container %v err: %v", metricName, ci.PodName, ci.ContainerName, err)
+		return 0
+	}
+	return m.Value
+}
+
+func (i *InterferenceIsolator) getPoolMaxIsolatedPods(pool string) int {
+	if maxPods, ok := i.conf.InterferenceIsolation.MaxPoolIsolatedPods[i.configTranslator.Translate(pool)]; ok {
+		return maxPods
+	}
+	return i.conf.InterferenceIsolation.MaxIsolatedPods
+}
+
+func safeShare(part, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return part / total
+}
@@ -0,0 +1,343 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isolation
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/kubewharf/katalyst-api/pkg/consts"
+	katalyst_base "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu"
+	metric_consts "github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/pod"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	metricspool "github.com/kubewharf/katalyst-core/pkg/metrics/metrics-pool"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+	utilmetric "github.com/kubewharf/katalyst-core/pkg/util/metric"
+)
+
+type interferenceSample struct {
+	memBandwidth float64
+	llcMiss      float64
+	cpi          float64
+	throttled    float64
+}
+
+func TestInterferenceIsolator(t *testing.T) {
+	t.Parallel()
+
+	ckDir, err := ioutil.TempDir("", "checkpoint-TestInterferenceIsolator")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(ckDir) }()
+
+	sfDir, err := ioutil.TempDir("", "state")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(sfDir) }()
+
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+	require.NotNil(t, conf)
+	conf.GenericSysAdvisorConfiguration.StateFileDirectory = sfDir
+	conf.MetaServerConfiguration.CheckpointManagerDir = ckDir
+
+	metaCache, err := metacache.NewMetaCacheImp(conf, metricspool.DummyMetricsEmitterPool{}, metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}))
+	require.NoError(t, err)
+
+	genericCtx, err := katalyst_base.GenerateFakeGenericContext([]runtime.Object{})
+	require.NoError(t, err)
+
+	metaServer, err := metaserver.NewMetaServer(genericCtx.Client, metrics.DummyMetrics{}, conf)
+	require.NoError(t, err)
+
+	var pods []*v1.Pod
+	for _, uid := range []string{"uid1", "uid2", "uid3", "uid4", "uid5"} {
+		pods = append(pods, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-" + uid, Namespace: "default", UID: k8stypes.UID(uid)}})
+	}
+
+	metricFetcher := metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}).(*metric.FakeMetricsFetcher)
+	metaServer.MetaAgent = &agent.MetaAgent{
+		PodFetcher:     &pod.PodFetcherStub{PodList: pods},
+		MetricsFetcher: metricFetcher,
+	}
+
+	containerPools := map[string]string{
+		"uid1": commonstate.PoolNameShare,
+		"uid2": commonstate.PoolNameShare,
+		"uid3": commonstate.PoolNameShare,
+		"uid4": "batch",
+		"uid5": "batch",
+	}
+	for uid, pool := range containerPools {
+		ci := makeContainerInfo(uid, "default", "pod-"+uid, "c-"+uid,
+			consts.PodAnnotationQoSLevelSharedCores, pool, nil, map[int]machine.CPUSet{}, 4, 4)
+		require.NoError(t, metaCache.SetContainerInfo(uid, ci.ContainerName, ci))
+	}
+
+	setSamples := func(samples map[string]interferenceSample) {
+		now := time.Now()
+		for uid, s := range samples {
+			containerName := "c-" + uid
+			metricFetcher.SetContainerMetric(uid, containerName, metric_consts.MetricMemBandwidthReadContainer, utilmetric.MetricData{Value: s.memBandwidth / 2, Time: &now})
+			metricFetcher.SetContainerMetric(uid, containerName, metric_consts.MetricMemBandwidthWriteContainer, utilmetric.MetricData{Value: s.memBandwidth / 2, Time: &now})
+			metricFetcher.SetContainerMetric(uid, containerName, metric_consts.MetricCPUL3CacheMissRateContainer, utilmetric.MetricData{Value: s.llcMiss, Time: &now})
+			metricFetcher.SetContainerMetric(uid, containerName, metric_consts.MetricCPUCPIContainer, utilmetric.MetricData{Value: s.cpi, Time: &now})
+			metricFetcher.SetContainerMetric(uid, containerName, metric_consts.MetricCPUNrThrottledRateContainer, utilmetric.MetricData{Value: s.throttled, Time: &now})
+			metricFetcher.SetContainerMetric(uid, containerName, metric_consts.MetricCPUNrPeriodRateContainer, utilmetric.MetricData{Value: 10, Time: &now})
+		}
+	}
+
+	quiet := map[string]interferenceSample{
+		"uid1": {memBandwidth: 10, llcMiss: 10, cpi: 1},
+		"uid2": {memBandwidth: 10, llcMiss: 10, cpi: 1},
+		"uid3": {memBandwidth: 10, llcMiss: 10, cpi: 1},
+		"uid4": {memBandwidth: 10, llcMiss: 10, cpi: 1},
+		"uid5": {memBandwidth: 10, llcMiss: 10, cpi: 1},
+	}
+	noisy := map[string]interferenceSample{
+		"uid1": {memBandwidth: 80, llcMiss: 80, cpi: 1},
+		"uid2": {memBandwidth: 10, llcMiss: 10, cpi: 2, throttled: 5},
+		"uid3": {memBandwidth: 10, llcMiss: 10, cpi: 2, throttled: 5},
+		"uid4": {memBandwidth: 90, llcMiss: 90, cpi: 1},
+		"uid5": {memBandwidth: 10, llcMiss: 10, cpi: 2, throttled: 5},
+	}
+	// offenders occupy most of the shared resources, but their neighbours are not suffering
+	greedy := map[string]interferenceSample{
+		"uid1": {memBandwidth: 80, llcMiss: 80, cpi: 1},
+		"uid2": {memBandwidth: 10, llcMiss: 10, cpi: 1},
+		"uid3": {memBandwidth: 10, llcMiss: 10, cpi: 1},
+		"uid4": {memBandwidth: 90, llcMiss: 90, cpi: 1},
+		"uid5": {memBandwidth: 10, llcMiss: 10, cpi: 1},
+	}
+
+	newConf := func() *cpu.CPUIsolationConfiguration {
+		return &cpu.CPUIsolationConfiguration{
+			IsolationDisabledPools: sets.NewString(),
+			InterferenceIsolation: cpu.InterferenceIsolationConfiguration{
+				Enabled:              true,
+				ScoreThreshold:       0.5,
+				MemBandwidthWeight:   0.4,
+				LLCMissWeight:        0.4,
+				CPIDegradationWeight: 0.5,
+				ThrottleWeight:       0.5,
+				LockInDuration:       time.Minute,
+				LockOutDuration:      5 * time.Minute,
+				MaxIsolatedPods:      1,
+				MaxPoolIsolatedPods:  map[string]int{},
+
+				NeighbourPressureThreshold: 0.1,
+			},
+		}
+	}
+
+	type step struct {
+		elapsed time.Duration
+		samples map[string]interferenceSample
+		expects []string
+	}
+
+	for _, tc := range []struct {
+		comment string
+		conf    func() *cpu.CPUIsolationConfiguration
+		steps   []step
+	}{
+		{
+			comment: "interference isolation disabled",
+			conf: func() *cpu.CPUIsolationConfiguration {
+				c := newConf()
+				c.InterferenceIsolation.Enabled = false
+				return c
+			},
+			steps: []step{
+				{elapsed: 0, samples: quiet, expects: []string{}},
+				{elapsed: 0, samples: noisy, expects: []string{}},
+				{elapsed: 2 * time.Minute, samples: noisy, expects: []string{}},
+			},
+		},
+		{
+			comment: "lock-in and lock-out by interference score",
+			conf:    newConf,
+			steps: []step{
+				{elapsed: 0, samples: quiet, expects: []string{}},
+				{elapsed: 0, samples: noisy, expects: []string{}},
+				{elapsed: 30 * time.Second, samples: noisy, expects: []string{}},
+				{elapsed: 30 * time.Second, samples: noisy, expects: []string{"uid1", "uid4"}},
+				{elapsed: time.Minute, samples: quiet, expects: []string{"uid1", "uid4"}},
+				{elapsed: 4 * time.Minute, samples: quiet, expects: []string{"uid1", "uid4"}},
+				{elapsed: time.Minute, samples: quiet, expects: []string{}},
+			},
+		},
+		{
+			comment: "no neighbour pressure",
+			conf:    newConf,
+			steps: []step{
+				{elapsed: 0, samples: quiet, expects: []string{}},
+				{elapsed: 0, samples: greedy, expects: []string{}},
+				{elapsed: 2 * time.Minute, samples: greedy, expects: []string{}},
+			},
+		},
+		{
+			comment: "offender flaps before lock-in",
+			conf:    newConf,
+			steps: []step{
+				{elapsed: 0, samples: noisy, expects: []string{}},
+				{elapsed: 40 * time.Second, samples: quiet, expects: []string{}},
+				{elapsed: 40 * time.Second, samples: noisy, expects: []string{}},
+				{elapsed: time.Minute, samples: noisy, expects: []string{"uid1", "uid4"}},
+			},
+		},
+		{
+			comment: "pool cap and disabled pools",
+			conf: func() *cpu.CPUIsolationConfiguration {
+				c := newConf()
+				c.IsolationDisabledPools = sets.NewString("batch")
+				c.InterferenceIsolation.MaxPoolIsolatedPods = map[string]int{commonstate.PoolNameShare: 0}
+				return c
+			},
+			steps: []step{
+				{elapsed: 0, samples: noisy, expects: []string{}},
+				{elapsed: 2 * time.Minute, samples: noisy, expects: []string{}},
+			},
+		},
+	} {
+		t.Logf("test cases: %v", tc.comment)
+
+		conf.CPUIsolationConfiguration = tc.conf()
+		isolator := NewInterferenceIsolator(conf, struct{}{}, metrics.DummyMetrics{}, metaCache, metaServer)
+		fakeClock := testingclock.NewFakeClock(time.Now())
+		isolator.(*InterferenceIsolator).clock = fakeClock
+
+		for i, s := range tc.steps {
+			fakeClock.Step(s.elapsed)
+			setSamples(s.samples)
+			assert.EqualValues(t, s.expects, isolator.GetIsolatedPods(), "step %v", i)
+		}
+	}
+}
+
+func TestUnionIsolator(t *testing.T) {
+	t.Parallel()
+
+	ckDir, err := ioutil.TempDir("", "checkpoint-TestUnionIsolator")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(ckDir) }()
+
+	sfDir, err := ioutil.TempDir("", "state")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(sfDir) }()
+
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+	require.NotNil(t, conf)
+	conf.GenericSysAdvisorConfiguration.StateFileDirectory = sfDir
+	conf.MetaServerConfiguration.CheckpointManagerDir = ckDir
+	conf.CPUIsolationConfiguration = &cpu.CPUIsolationConfiguration{
+		IsolatedMaxPodRatio:      0.5,
+		IsolatedMaxPoolPodRatios: map[string]float32{},
+		InterferenceIsolation: cpu.InterferenceIsolationConfiguration{
+			MaxIsolatedPods:     1,
+			MaxPoolIsolatedPods: map[string]int{"batch": 2},
+		},
+	}
+
+	metaCache, err := metacache.NewMetaCacheImp(conf, metricspool.DummyMetricsEmitterPool{}, metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}))
+	require.NoError(t, err)
+
+	containerPools := map[string]string{
+		"uid1": commonstate.PoolNameShare,
+		"uid2": commonstate.PoolNameShare,
+		"uid3": commonstate.PoolNameShare,
+		"uid4": commonstate.PoolNameShare,
+		"uid5": commonstate.PoolNameShare,
+		"uid6": "batch",
+		"uid7": "batch",
+	}
+	for uid, pool := range containerPools {
+		ci := makeContainerInfo(uid, "default", "pod-"+uid, "c-"+uid,
+			consts.PodAnnotationQoSLevelSharedCores, pool, nil, map[int]machine.CPUSet{}, 4, 4)
+		require.NoError(t, metaCache.SetContainerInfo(uid, ci.ContainerName, ci))
+	}
+
+	load := &staticIsolator{}
+	interference := &staticIsolator{}
+	isolator := NewUnionIsolator(conf, metaCache, load, interference)
+
+	for _, tc := range []struct {
+		comment      string
+		load         []string
+		interference []string
+		expects      []string
+	}{
+		{
+			comment: "no isolated pods",
+			expects: []string{},
+		},
+		{
+			comment:      "merge isolated pods within limitations",
+			load:         []string{"uid2", "uid1"},
+			interference: []string{"uid1", "uid6"},
+			expects:      []string{"uid1", "uid2", "uid6"},
+		},
+		{
+			comment:      "exceed pod-ratio and keep isolated pods in priority",
+			load:         []string{"uid3", "uid1"},
+			interference: []string{"uid2"},
+			expects:      []string{"uid1", "uid2"},
+		},
+		{
+			comment:      "at least one pod left in the pool",
+			interference: []string{"uid6", "uid7"},
+			expects:      []string{"uid6"},
+		},
+		{
+			comment:      "ignore pods out of shared pools",
+			interference: []string{"uid8"},
+			expects:      []string{},
+		},
+	} {
+		t.Logf("test cases: %v", tc.comment)
+
+		load.pods = tc.load
+		interference.pods = tc.interference
+		assert.EqualValues(t, tc.expects, isolator.GetIsolatedPods())
+	}
+}
+
+type staticIsolator struct {
+	pods []string
+}
+
+func (s *staticIsolator) GetIsolatedPods() []string {
+	return s.pods
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionpolicy
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+
+	configapi "github.com/kubewharf/katalyst-api/pkg/apis/config/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/helper"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	metricMPCPredictedCost    = "mpc_predicted_cost"
+	metricMPCIndicatorTarget  = "mpc_indicator_target"
+	metricMPCIndicatorCurrent = "mpc_indicator_current"
+)
+
+// PolicyMPC sizes share regions by model predictive control over the service business indicators
+// (e.g. rpc latency, error rate) published in spd, instead of by cpu usage only.
+type PolicyMPC struct {
+	*PolicyBase
+	conf       *config.Configuration
+	controller *helper.MPCController
+
+	// serviceIndicatorsGetter is replaced in unit tests
+	serviceIndicatorsGetter func() (map[string]helper.MPCIndicator, error)
+}
+
+func NewPolicyMPC(regionName string, regionType configapi.QoSRegionType, ownerPoolName string,
+	conf *config.Configuration, _ interface{}, metaReader metacache.MetaReader,
+	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
+) ProvisionPolicy {
+	mpcConf := conf.PolicyMPC
+	p := &PolicyMPC{
+		conf:       conf,
+		PolicyBase: NewPolicyBase(regionName, regionType, ownerPoolName, metaReader, metaServer, emitter),
+		controller: helper.NewMPCController(helper.MPCParams{
+			Horizon:         mpcConf.Horizon,
+			CPUCostWeight:   mpcConf.CPUCostWeight,
+			MoveCostWeight:  mpcConf.MoveCostWeight,
+			Resolution:      mpcConf.Resolution,
+			MaxRampUpStep:   mpcConf.MaxRampUpStep,
+			MaxRampDownStep: mpcConf.MaxRampDownStep,
+		}),
+	}
+	p.serviceIndicatorsGetter = p.getServiceIndicators
+
+	return p
+}
+
+func (p *PolicyMPC) Update() error {
+	// sanity check
+	if err := p.sanityCheck(); err != nil {
+		return err
+	}
+
+	indicators, err := p.serviceIndicatorsGetter()
+	if err != nil {
+		return err
+	}
+	if len(indicators) == 0 {
+		return fmt.Errorf("no service indicator found for %v", p.GetMetaInfo())
+	}
+
+	lowerBound, upperBound := p.ResourceLowerBound, p.ResourceUpperBound
+	if constraint, ok := p.conf.PolicyMPC.RegionConstraints[p.ownerPoolName]; ok {
+		lowerBound = math.Max(lowerBound, constraint.MinCPU)
+		upperBound = math.Min(upperBound, constraint.MaxCPU)
+	}
+
+	knobValue := p.ControlKnobs[configapi.ControlKnobNonReclaimedCPURequirement].Value
+	cpuRequirement, cost := p.controller.Solve(knobValue, lowerBound, upperBound, indicators)
+
+	for name, indicator := range indicators {
+		tags := metrics.ConvertMapToTags(map[string]string{
+			"region_name":    p.regionName,
+			"indicator_name": name,
+		})
+		_ = p.emitter.StoreFloat64(metricMPCIndicatorTarget, indicator.Target, metrics.MetricTypeNameRaw, tags...)
+		_ = p.emitter.StoreFloat64(metricMPCIndicatorCurrent, indicator.Current, metrics.MetricTypeNameRaw, tags...)
+	}
+	_ = p.emitter.StoreFloat64(metricMPCPredictedCost, cost, metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "region_name", Val: p.regionName})
+
+	general.InfoS("[qosaware-cpu-mpc] solve result", "meta", p.GetMetaInfo(), "last knobValue", knobValue,
+		"cpuRequirement", cpuRequirement, "cost", cost, "lowerBound", lowerBound, "upperBound", upperBound)
+
+	p.controlKnobAdjusted = types.ControlKnob{
+		configapi.ControlKnobNonReclaimedCPURequirement: types.ControlKnobItem{
+			Value:  cpuRequirement,
+			Action: types.ControlKnobActionNone,
+		},
+	}
+
+	return nil
+}
+
+// getServiceIndicators collects business indicators of all pods in region from spd, and keeps the one
+// violating its setpoint the most for each indicator, as pods of the same region may belong to different services
+func (p *PolicyMPC) getServiceIndicators() (map[string]helper.MPCIndicator, error) {
+	ctx := context.Background()
+	indicators := make(map[string]helper.MPCIndicator)
+
+	for podUID := range p.podSet {
+		pod, err := p.metaServer.GetPod(ctx, podUID)
+		if err != nil {
+			klog.Warningf("[qosaware-cpu-mpc] failed to get pod %v: %v", podUID, err)
+			continue
+		}
+
+		targets, values, err := p.metaServer.ServiceBusinessIndicators(ctx, pod.ObjectMeta)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		} else if err != nil {
+			continue
+		}
+
+		for name, target := range targets {
+			params, ok := p.conf.PolicyMPC.IndicatorParams[name]
+			if !ok {
+				continue
+			}
+			current, ok := values[name]
+			if !ok {
+				continue
+			}
+
+			// the setpoint of indicators improved by more cpu is upper bound, and lower bound for the others
+			bound := target.UpperBound
+			if params.Elasticity > 0 {
+				bound = target.LowerBound
+			}
+			if bound == nil || *bound <= 0 || current <= 0 {
+				continue
+			}
+
+			indicator := helper.MPCIndicator{
+				MPCIndicatorParams: params,
+				Current:            current,
+				Target:             *bound,
+			}
+			if existing, ok := indicators[name]; !ok || worseThan(indicator, existing) {
+				indicators[name] = indicator
+			}
+		}
+	}
+
+	return indicators, nil
+}
+
+func worseThan(a, b helper.MPCIndicator) bool {
+	if a.Elasticity < 0 {
+		return a.Current/a.Target > b.Current/b.Target
+	}
+	return a.Current/a.Target < b.Current/b.Target
+}
+
+func (p *PolicyMPC) sanityCheck() error {
+	var errList []error
+
+	// 1. check if enable reclaim
+	if !p.conf.GetDynamicConfiguration().EnableReclaim {
+		errList = append(errList, fmt.Errorf("reclaim disabled"))
+	}
+
+	// 2. check region type, only share region is sized by service indicators
+	if p.regionType != configapi.QoSRegionTypeShare {
+		errList = append(errList, fmt.Errorf("unsupported region type %v", p.regionType))
+	}
+
+	// 3. check control knob legality
+	if v, ok := p.ControlKnobs[configapi.ControlKnobNonReclaimedCPURequirement]; !ok || v.Value < 0 {
+		errList = append(errList, fmt.Errorf("illegal control knob %v", p.ControlKnobs))
+	}
+
+	return errors.NewAggregate(errList)
+}
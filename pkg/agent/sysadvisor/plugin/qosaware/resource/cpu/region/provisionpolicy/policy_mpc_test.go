@@ -0,0 +1,186 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionpolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8types "k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+
+	configapi "github.com/kubewharf/katalyst-api/pkg/apis/config/v1alpha1"
+	workloadv1alpha1 "github.com/kubewharf/katalyst-api/pkg/apis/workload/v1alpha1"
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/pod"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/spd"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util"
+)
+
+func newTestPolicyMPC(t *testing.T, regionType configapi.QoSRegionType, enableReclaim bool,
+	profiles map[k8types.UID]spd.DummyPodServiceProfile, constraints map[string]types.MPCRegionConstraint,
+) *PolicyMPC {
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+	conf.GetDynamicConfiguration().EnableReclaim = enableReclaim
+	conf.PolicyMPC.RegionConstraints = constraints
+
+	var pods []*v1.Pod
+	podSet := make(types.PodSet)
+	for uid := range profiles {
+		pods = append(pods, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: string(uid), UID: uid}})
+		podSet.Insert(string(uid), "c1")
+	}
+
+	metaServer := &metaserver.MetaServer{
+		MetaAgent: &agent.MetaAgent{
+			PodFetcher: &pod.PodFetcherStub{PodList: pods},
+		},
+		ServiceProfilingManager: spd.NewDummyServiceProfilingManager(profiles),
+	}
+
+	p := NewPolicyMPC("share-region", regionType, "share", conf, nil, nil, metaServer, metrics.DummyMetrics{}).(*PolicyMPC)
+	p.SetPodSet(podSet)
+	p.SetEssentials(types.ResourceEssentials{
+		ResourceUpperBound: 40,
+		ResourceLowerBound: 2,
+	}, types.ControlEssentials{
+		ControlKnobs: types.ControlKnob{
+			configapi.ControlKnobNonReclaimedCPURequirement: {Value: 10},
+		},
+	})
+	return p
+}
+
+func latencyProfile(current, upperBound float64) spd.DummyPodServiceProfile {
+	name := string(workloadv1alpha1.ServiceBusinessIndicatorNameRPCLatency)
+	return spd.DummyPodServiceProfile{
+		BusinessIndicatorTarget: spd.IndicatorTarget{
+			name:      util.IndicatorTarget{UpperBound: pointer.Float64(upperBound)},
+			"unknown": util.IndicatorTarget{UpperBound: pointer.Float64(1)},
+		},
+		BusinessIndicatorValue: map[string]float64{
+			name:      current,
+			"unknown": 100,
+		},
+	}
+}
+
+func TestPolicyMPC_Update(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		regionType    configapi.QoSRegionType
+		enableReclaim bool
+		profiles      map[k8types.UID]spd.DummyPodServiceProfile
+		constraints   map[string]types.MPCRegionConstraint
+		wantErr       bool
+		assertion     func(t *testing.T, got float64)
+	}{
+		{
+			name:          "worst pod drives scaling up",
+			regionType:    configapi.QoSRegionTypeShare,
+			enableReclaim: true,
+			profiles: map[k8types.UID]spd.DummyPodServiceProfile{
+				"pod1": latencyProfile(150, 100),
+				"pod2": latencyProfile(30, 100),
+			},
+			assertion: func(t *testing.T, got float64) {
+				assert.Greater(t, got, 10.0)
+			},
+		},
+		{
+			name:          "all pods within target scale down",
+			regionType:    configapi.QoSRegionTypeShare,
+			enableReclaim: true,
+			profiles: map[k8types.UID]spd.DummyPodServiceProfile{
+				"pod1": latencyProfile(30, 100),
+				"pod2": latencyProfile(20, 100),
+			},
+			assertion: func(t *testing.T, got float64) {
+				assert.Less(t, got, 10.0)
+			},
+		},
+		{
+			name:          "region constraint bounds result",
+			regionType:    configapi.QoSRegionTypeShare,
+			enableReclaim: true,
+			profiles: map[k8types.UID]spd.DummyPodServiceProfile{
+				"pod1": latencyProfile(300, 100),
+			},
+			constraints: map[string]types.MPCRegionConstraint{
+				"share": {MinCPU: 4, MaxCPU: 11},
+			},
+			assertion: func(t *testing.T, got float64) {
+				assert.Equal(t, 11.0, got)
+			},
+		},
+		{
+			name:          "no service indicator",
+			regionType:    configapi.QoSRegionTypeShare,
+			enableReclaim: true,
+			profiles: map[k8types.UID]spd.DummyPodServiceProfile{
+				"pod1": {},
+			},
+			wantErr: true,
+		},
+		{
+			name:          "reclaim disabled",
+			regionType:    configapi.QoSRegionTypeShare,
+			enableReclaim: false,
+			profiles: map[k8types.UID]spd.DummyPodServiceProfile{
+				"pod1": latencyProfile(150, 100),
+			},
+			wantErr: true,
+		},
+		{
+			name:          "dedicated region unsupported",
+			regionType:    configapi.QoSRegionTypeDedicated,
+			enableReclaim: true,
+			profiles: map[k8types.UID]spd.DummyPodServiceProfile{
+				"pod1": latencyProfile(150, 100),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := newTestPolicyMPC(t, tt.regionType, tt.enableReclaim, tt.profiles, tt.constraints)
+			err := p.Update()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			knob, err := p.GetControlKnobAdjusted()
+			require.NoError(t, err)
+			tt.assertion(t, knob[configapi.ControlKnobNonReclaimedCPURequirement].Value)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionpolicy
+
+import (
+	"context"
+	"fmt"
+
+	configapi "github.com/kubewharf/katalyst-api/pkg/apis/config/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/helper"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	metricThroughputBatchCPURequirement = "throughput_batch_cpu_requirement"
+)
+
+// PolicyThroughput sizes batch regions by the throughput slo of reclaimed_cores pods, so that
+// batch pods are guaranteed with enough cpu to meet their throughput targets within reclaim pool.
+type PolicyThroughput struct {
+	*PolicyBase
+	conf            *config.Configuration
+	indicatorsCache *helper.ThroughputIndicatorsCache
+}
+
+// ThroughputIndicatorsCacheSetter is implemented by policies relying on throughput indicators of pods,
+// so that the indicators cached by advisor in each cycle can be shared with them.
+type ThroughputIndicatorsCacheSetter interface {
+	SetThroughputIndicatorsCache(indicatorsCache *helper.ThroughputIndicatorsCache)
+}
+
+func NewPolicyThroughput(regionName string, regionType configapi.QoSRegionType, ownerPoolName string,
+	conf *config.Configuration, _ interface{}, metaReader metacache.MetaReader,
+	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
+) ProvisionPolicy {
+	p := &PolicyThroughput{
+		conf:       conf,
+		PolicyBase: NewPolicyBase(regionName, regionType, ownerPoolName, metaReader, metaServer, emitter),
+	}
+	return p
+}
+
+func (p *PolicyThroughput) SetThroughputIndicatorsCache(indicatorsCache *helper.ThroughputIndicatorsCache) {
+	p.indicatorsCache = indicatorsCache
+}
+
+func (p *PolicyThroughput) Update() error {
+	// sanity check
+	if err := p.sanityCheck(); err != nil {
+		return err
+	}
+
+	// batch pods are not guaranteed if reclaim is disabled, and then the batch region
+	// requires nothing more than the reserved resource for reclaim pool
+	if !p.conf.GetDynamicConfiguration().EnableReclaim {
+		general.InfoS("[qosaware-cpu-throughput] reclaim disabled", "meta", p.GetMetaInfo())
+		p.setBatchCPURequirement(0)
+		return nil
+	}
+
+	requirement, err := helper.EstimateBatchCPURequirement(context.Background(), p.podSet, p.indicatorsCache,
+		p.conf.MaxThroughputScaleRatio, p.metaReader)
+	if err != nil {
+		return err
+	}
+	requirement = general.Clamp(requirement, p.ResourceLowerBound, p.ResourceUpperBound)
+
+	general.InfoS("[qosaware-cpu-throughput] update batch cpu requirement", "meta", p.GetMetaInfo(),
+		"requirement", requirement, "#pod", len(p.podSet))
+	p.setBatchCPURequirement(requirement)
+	return nil
+}
+
+func (p *PolicyThroughput) setBatchCPURequirement(requirement float64) {
+	_ = p.emitter.StoreFloat64(metricThroughputBatchCPURequirement, requirement, metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "region", Val: p.regionName})
+
+	p.controlKnobAdjusted = types.ControlKnob{
+		types.ControlKnobReclaimedBatchCPURequirement: types.ControlKnobItem{
+			Value:  requirement,
+			Action: types.ControlKnobActionNone,
+		},
+	}
+}
+
+func (p *PolicyThroughput) sanityCheck() error {
+	// only batch region is supported
+	if p.regionType != types.QoSRegionTypeBatch {
+		return fmt.Errorf("unsupported region type %v", p.regionType)
+	} else if p.indicatorsCache == nil {
+		return fmt.Errorf("throughput indicators cache is not set")
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionpolicy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8types "k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+
+	configapi "github.com/kubewharf/katalyst-api/pkg/apis/config/v1alpha1"
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/helper"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/pod"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/spd"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	metricspool "github.com/kubewharf/katalyst-core/pkg/metrics/metrics-pool"
+	"github.com/kubewharf/katalyst-core/pkg/util"
+	utilmetric "github.com/kubewharf/katalyst-core/pkg/util/metric"
+)
+
+const testThroughputIndicator = "jobs_per_minute"
+
+type testBatchPod struct {
+	usage      float64
+	request    float64
+	throughput float64
+	target     float64
+}
+
+func newTestPolicyThroughput(t *testing.T, regionType configapi.QoSRegionType, enableReclaim bool,
+	batchPods map[string]testBatchPod,
+) *PolicyThroughput {
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+	conf.GenericSysAdvisorConfiguration.StateFileDirectory = t.TempDir()
+	conf.GetDynamicConfiguration().EnableReclaim = enableReclaim
+	conf.ThroughputIndicators = []string{testThroughputIndicator}
+	conf.MaxThroughputScaleRatio = 2
+
+	metricsFetcher := metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}).(*metric.FakeMetricsFetcher)
+	metaCache, err := metacache.NewMetaCacheImp(conf, metricspool.DummyMetricsEmitterPool{}, metricsFetcher)
+	require.NoError(t, err)
+
+	now := time.Now()
+	var pods []*v1.Pod
+	podSet := make(types.PodSet)
+	profiles := make(map[k8types.UID]spd.DummyPodServiceProfile)
+	for uid, batchPod := range batchPods {
+		pods = append(pods, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: uid, UID: k8types.UID(uid)}})
+		podSet.Insert(uid, "c1")
+		profiles[k8types.UID(uid)] = spd.DummyPodServiceProfile{
+			BusinessIndicatorTarget: spd.IndicatorTarget{
+				testThroughputIndicator: util.IndicatorTarget{LowerBound: pointer.Float64(batchPod.target)},
+			},
+			BusinessIndicatorValue: map[string]float64{testThroughputIndicator: batchPod.throughput},
+		}
+
+		require.NoError(t, metaCache.AddContainer(uid, "c1", &types.ContainerInfo{
+			PodUID: uid, PodName: uid, ContainerName: "c1",
+			QoSLevel: apiconsts.PodAnnotationQoSLevelReclaimedCores, CPURequest: batchPod.request,
+		}))
+		if batchPod.usage > 0 {
+			metricsFetcher.SetContainerMetric(uid, "c1", consts.MetricCPUUsageContainer,
+				utilmetric.MetricData{Value: batchPod.usage, Time: &now})
+		}
+	}
+
+	metaServer := &metaserver.MetaServer{
+		MetaAgent: &agent.MetaAgent{
+			PodFetcher:     &pod.PodFetcherStub{PodList: pods},
+			MetricsFetcher: metricsFetcher,
+		},
+		ServiceProfilingManager: spd.NewDummyServiceProfilingManager(profiles),
+	}
+
+	p := NewPolicyThroughput("batch-region", regionType, "reclaim", conf, nil, metaCache, metaServer, metrics.DummyMetrics{}).(*PolicyThroughput)
+	p.SetThroughputIndicatorsCache(helper.NewThroughputIndicatorsCache(metaServer, conf.ThroughputIndicators))
+	p.SetPodSet(podSet)
+	p.SetEssentials(types.ResourceEssentials{
+		ResourceUpperBound: 20,
+		ResourceLowerBound: 1,
+	}, types.ControlEssentials{})
+	return p
+}
+
+func TestPolicyThroughput_Update(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		regionType    configapi.QoSRegionType
+		enableReclaim bool
+		batchPods     map[string]testBatchPod
+		want          float64
+		wantErr       bool
+	}{
+		{
+			name:          "usage scaled by throughput",
+			regionType:    types.QoSRegionTypeBatch,
+			enableReclaim: true,
+			batchPods: map[string]testBatchPod{
+				"pod1": {usage: 4, throughput: 50, target: 100},
+				"pod2": {usage: 2, throughput: 200, target: 100},
+			},
+			want: 9,
+		},
+		{
+			name:          "scale ratio restricted",
+			regionType:    types.QoSRegionTypeBatch,
+			enableReclaim: true,
+			batchPods: map[string]testBatchPod{
+				"pod1": {usage: 4, throughput: 10, target: 100},
+			},
+			want: 8,
+		},
+		{
+			name:          "fall back to request without usage",
+			regionType:    types.QoSRegionTypeBatch,
+			enableReclaim: true,
+			batchPods: map[string]testBatchPod{
+				"pod1": {request: 3, throughput: 100, target: 100},
+			},
+			want: 3,
+		},
+		{
+			name:          "restricted by resource upper bound",
+			regionType:    types.QoSRegionTypeBatch,
+			enableReclaim: true,
+			batchPods: map[string]testBatchPod{
+				"pod1": {usage: 15, throughput: 50, target: 100},
+			},
+			want: 20,
+		},
+		{
+			name:          "reclaim disabled",
+			regionType:    types.QoSRegionTypeBatch,
+			enableReclaim: false,
+			batchPods: map[string]testBatchPod{
+				"pod1": {usage: 4, throughput: 50, target: 100},
+			},
+			want: 0,
+		},
+		{
+			name:          "share region unsupported",
+			regionType:    configapi.QoSRegionTypeShare,
+			enableReclaim: true,
+			batchPods: map[string]testBatchPod{
+				"pod1": {usage: 4, throughput: 50, target: 100},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := newTestPolicyThroughput(t, tt.regionType, tt.enableReclaim, tt.batchPods)
+			err := p.Update()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			knob, err := p.GetControlKnobAdjusted()
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, knob[types.ControlKnobReclaimedBatchCPURequirement].Value, 1e-6)
+		})
+	}
+}
+
+func TestPolicyThroughput_IndicatorsCache(t *testing.T) {
+	t.Parallel()
+
+	p := newTestPolicyThroughput(t, types.QoSRegionTypeBatch, true, map[string]testBatchPod{
+		"pod1": {usage: 4, throughput: 50, target: 100},
+	})
+	getRequirement := func() float64 {
+		require.NoError(t, p.Update())
+		knob, err := p.GetControlKnobAdjusted()
+		require.NoError(t, err)
+		return knob[types.ControlKnobReclaimedBatchCPURequirement].Value
+	}
+	assert.InDelta(t, 8, getRequirement(), 1e-6)
+
+	// throughput is caught up with the target, but cached indicators are used until reset
+	p.metaServer.ServiceProfilingManager = spd.NewDummyServiceProfilingManager(map[k8types.UID]spd.DummyPodServiceProfile{
+		"pod1": {
+			BusinessIndicatorTarget: spd.IndicatorTarget{
+				testThroughputIndicator: util.IndicatorTarget{LowerBound: pointer.Float64(100)},
+			},
+			BusinessIndicatorValue: map[string]float64{testThroughputIndicator: 100},
+		},
+	})
+	assert.InDelta(t, 8, getRequirement(), 1e-6)
+
+	p.indicatorsCache.Reset()
+	assert.InDelta(t, 4, getRequirement(), 1e-6)
+}
@@ -70,6 +70,8 @@ type QoSRegion interface {
 	GetProvision() (types.ControlKnob, error)
 	// GetHeadroom returns the latest updated cpu headroom estimation
 	GetHeadroom() (float64, error)
+	// GetRegulatorEntries returns the regulation history of each provision policy to be persisted
+	GetRegulatorEntries() types.RegulatorEntries
 
 	IsThrottled() bool
 
@@ -93,16 +93,25 @@ type internalHeadroomPolicy struct {
 type provisionPolicyResult struct {
 	msg                        string
 	essentials                 types.ResourceEssentials
+	regulatorName              types.CPURegulatorName
 	regulatorOptions           regulator.RegulatorOptions
 	controlKnobValueRegulators map[v1alpha1.ControlKnobName]regulator.Regulator
+
+	// regulatorInfoGetter returns persisted regulation history to warm start new regulators
+	regulatorInfoGetter func(name v1alpha1.ControlKnobName) (*types.RegulatorInfo, bool)
 }
 
-func newProvisionPolicyResult(essentials types.ResourceEssentials, regulatorOptions regulator.RegulatorOptions, msg string) *provisionPolicyResult {
+func newProvisionPolicyResult(essentials types.ResourceEssentials, regulatorName types.CPURegulatorName,
+	regulatorOptions regulator.RegulatorOptions, msg string,
+	regulatorInfoGetter func(name v1alpha1.ControlKnobName) (*types.RegulatorInfo, bool),
+) *provisionPolicyResult {
 	return &provisionPolicyResult{
 		msg:                        msg,
 		essentials:                 essentials,
+		regulatorName:              regulatorName,
 		regulatorOptions:           regulatorOptions,
 		controlKnobValueRegulators: make(map[v1alpha1.ControlKnobName]regulator.Regulator),
+		regulatorInfoGetter:        regulatorInfoGetter,
 	}
 }
 
@@ -123,6 +132,12 @@ func (r *provisionPolicyResult) regulateControlKnob(currentControlKnob, effectiv
 		reg, ok := r.controlKnobValueRegulators[name]
 		if !ok || reg == nil {
 			reg = r.newRegulator(name)
+			if r.regulatorInfoGetter != nil {
+				if info, found := r.regulatorInfoGetter(name); found {
+					klog.InfoS("[provisionPolicyResult] warm start regulator", "region", r.msg, "knob", name, "info", *info)
+					reg.RestoreRegulatorInfo(*info)
+				}
+			}
 		}
 		effectiveKnobItem, ok := effectiveControlKnob[name]
 		if ok {
@@ -143,14 +158,35 @@ func (r *provisionPolicyResult) regulateControlKnob(currentControlKnob, effectiv
 // newRegulator new regulator according to the control knob name
 func (r *provisionPolicyResult) newRegulator(name v1alpha1.ControlKnobName) regulator.Regulator {
 	switch name {
-	// only non-reclaimed cpu size need regulate now
-	case v1alpha1.ControlKnobNonReclaimedCPURequirement:
+	// only non-reclaimed and batch cpu size need regulate now
+	case v1alpha1.ControlKnobNonReclaimedCPURequirement, types.ControlKnobReclaimedBatchCPURequirement:
+		if r.regulatorName == types.CPURegulatorAdaptive {
+			return regulator.NewAdaptiveCPURegulator(r.essentials, r.regulatorOptions)
+		}
 		return regulator.NewCPURegulator(r.essentials, r.regulatorOptions)
 	default:
 		return regulator.NewDummyRegulator()
 	}
 }
 
+// getCPURegulatorName returns the regulator configured for the region type, or the default one if not set;
+// unknown regulator names are already rejected by options validation
+func getCPURegulatorName(conf *config.Configuration, regionType v1alpha1.QoSRegionType) types.CPURegulatorName {
+	if name, ok := conf.RegulatorNames[string(regionType)]; ok && name != "" {
+		return types.CPURegulatorName(name)
+	}
+	return types.CPURegulatorDefault
+}
+
+// getRegulatorInfos is to get regulation history from regulators
+func (r *provisionPolicyResult) getRegulatorInfos() map[v1alpha1.ControlKnobName]types.RegulatorInfo {
+	infos := make(map[v1alpha1.ControlKnobName]types.RegulatorInfo)
+	for name, reg := range r.controlKnobValueRegulators {
+		infos[name] = reg.GetRegulatorInfo()
+	}
+	return infos
+}
+
 // getControlKnob is to get final control knob from regulators
 func (r *provisionPolicyResult) getControlKnob() types.ControlKnob {
 	controlKnob := make(types.ControlKnob)
@@ -198,6 +234,8 @@ type QoSRegionBase struct {
 	// ctrl knob need policy restrict
 	ctrlKnobsNeedPolicyRestrict map[v1alpha1.ControlKnobName]bool
 
+	// cpuRegulatorName selects the regulator for cpu requirement of this region
+	cpuRegulatorName types.CPURegulatorName
 	// cpuRegulatorOptions is the regulator options for cpu regulator
 	cpuRegulatorOptions regulator.RegulatorOptions
 
@@ -249,10 +287,21 @@ func NewQoSRegionBase(name string, ownerPoolName string, regionType v1alpha1.QoS
 		headroomPolicyNameInUse:  types.CPUHeadroomPolicyNone,
 
 		provisionPolicyResults: make(map[types.CPUProvisionPolicyName]*provisionPolicyResult),
+		cpuRegulatorName:       getCPURegulatorName(conf, regionType),
 		cpuRegulatorOptions: regulator.RegulatorOptions{
 			MaxRampUpStep:     conf.MaxRampUpStep,
 			MaxRampDownStep:   conf.MaxRampDownStep,
 			MinRampDownPeriod: conf.MinRampDownPeriod,
+			AdaptiveOptions: regulator.AdaptiveRegulatorOptions{
+				HysteresisUpBand:     conf.AdaptiveRegulator.HysteresisUpBand,
+				HysteresisDownBand:   conf.AdaptiveRegulator.HysteresisDownBand,
+				SmoothingFactor:      conf.AdaptiveRegulator.SmoothingFactor,
+				OscillationWindow:    conf.AdaptiveRegulator.OscillationWindow,
+				OscillationThreshold: conf.AdaptiveRegulator.OscillationThreshold,
+				DampingFactor:        conf.AdaptiveRegulator.DampingFactor,
+			},
+			Emitter:    emitter,
+			RegionName: name,
 			NeedHTAligned: func() bool {
 				return machine.SmtActive() &&
 					!conf.GetDynamicConfiguration().AllowSharedCoresOverlapReclaimedCores &&
@@ -507,6 +556,30 @@ func (r *QoSRegionBase) GetHeadroom() (float64, error) {
 	return 0, fmt.Errorf("failed to get valid headroom")
 }
 
+func (r *QoSRegionBase) GetRegulatorEntries() types.RegulatorEntries {
+	r.Lock()
+	defer r.Unlock()
+
+	entries := make(types.RegulatorEntries)
+	for policyName, result := range r.provisionPolicyResults {
+		for knob, info := range result.getRegulatorInfos() {
+			info := info
+			entries[types.GetRegulatorKey(r.name, policyName, string(knob))] = &info
+		}
+	}
+	return entries
+}
+
+// getRegulatorInfoGetter returns the getter of persisted regulation history for the provision policy
+func (r *QoSRegionBase) getRegulatorInfoGetter(policyName types.CPUProvisionPolicyName) func(v1alpha1.ControlKnobName) (*types.RegulatorInfo, bool) {
+	return func(knob v1alpha1.ControlKnobName) (*types.RegulatorInfo, bool) {
+		if r.metaReader == nil {
+			return nil, false
+		}
+		return r.metaReader.GetRegulatorInfo(types.GetRegulatorKey(r.name, policyName, string(knob)))
+	}
+}
+
 func (r *QoSRegionBase) GetProvisionPolicy() (policyTopPriority types.CPUProvisionPolicyName, policyInUse types.CPUProvisionPolicyName) {
 	r.Lock()
 	defer r.Unlock()
@@ -604,6 +677,13 @@ func getRegionNameFromMetaCache(ci *types.ContainerInfo, numaID int, metaReader
 				}
 			}
 		}
+	} else if ci.QoSLevel == consts.PodAnnotationQoSLevelReclaimedCores {
+		for regionName := range ci.RegionNames {
+			regionInfo, ok := metaReader.GetRegionInfo(regionName)
+			if ok && regionInfo.RegionType == types.QoSRegionTypeBatch {
+				return regionName
+			}
+		}
 	}
 	return ""
 }
@@ -721,7 +801,8 @@ func (r *QoSRegionBase) regulateProvisionControlKnob(originControlKnob map[types
 
 		policyResult, ok := r.provisionPolicyResults[internal.name]
 		if !ok || policyResult == nil {
-			policyResult = newProvisionPolicyResult(r.ResourceEssentials, r.cpuRegulatorOptions, r.getMetaInfo())
+			policyResult = newProvisionPolicyResult(r.ResourceEssentials, r.cpuRegulatorName, r.cpuRegulatorOptions, r.getMetaInfo(),
+				r.getRegulatorInfoGetter(internal.name))
 			policyResult.regulateControlKnob(controlKnob, effectiveControlKnob)
 		} else {
 			policyResult.setEssentials(r.ResourceEssentials)
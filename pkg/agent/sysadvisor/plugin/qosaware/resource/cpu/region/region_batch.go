@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package region
+
+import (
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region/provisionpolicy"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/helper"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+type QoSRegionBatch struct {
+	*QoSRegionBase
+	indicatorsCache *helper.ThroughputIndicatorsCache
+}
+
+// NewQoSRegionBatch returns a region instance for reclaimed pods with throughput slo, which is
+// sized as batch pool carved out of reclaimed resources to make sure these batch pods get enough
+// cpu to meet their targets
+func NewQoSRegionBatch(ci *types.ContainerInfo, conf *config.Configuration, extraConf interface{},
+	indicatorsCache *helper.ThroughputIndicatorsCache, metaReader metacache.MetaReader,
+	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
+) QoSRegion {
+	regionName := getRegionNameFromMetaCache(ci, commonstate.FakedNUMAID, metaReader)
+	if regionName == "" {
+		regionName = string(types.QoSRegionTypeBatch) + types.RegionNameSeparator + string(uuid.NewUUID())
+	}
+
+	r := &QoSRegionBatch{
+		QoSRegionBase:   NewQoSRegionBase(regionName, commonstate.PoolNameBatch, types.QoSRegionTypeBatch, conf, extraConf, false, false, metaReader, metaServer, emitter),
+		indicatorsCache: indicatorsCache,
+	}
+	return r
+}
+
+func (r *QoSRegionBatch) TryUpdateProvision() {
+	r.Lock()
+	defer r.Unlock()
+
+	// update each provision policy
+	r.updateProvisionPolicy()
+
+	// get raw provision control knob
+	rawControlKnobs := r.getProvisionControlKnob()
+
+	// regulate control knobs
+	r.regulateProvisionControlKnob(rawControlKnobs, r.getEffectiveControlKnobs())
+}
+
+func (r *QoSRegionBatch) updateProvisionPolicy() {
+	r.ControlEssentials = types.ControlEssentials{
+		ControlKnobs: r.getEffectiveControlKnobs(),
+	}
+
+	// update internal policy
+	for _, internal := range r.provisionPolicies {
+		internal.updateStatus = types.PolicyUpdateFailed
+
+		// set essentials for policy and regulator
+		internal.policy.SetPodSet(r.podSet)
+		internal.policy.SetBindingNumas(r.bindingNumas, false)
+		internal.policy.SetEssentials(r.ResourceEssentials, r.ControlEssentials)
+		if setter, ok := internal.policy.(provisionpolicy.ThroughputIndicatorsCacheSetter); ok {
+			setter.SetThroughputIndicatorsCache(r.indicatorsCache)
+		}
+
+		// run an episode of policy update
+		if err := internal.policy.Update(); err != nil {
+			klog.Errorf("[qosaware-cpu] update policy %v failed: %v", internal.name, err)
+			continue
+		}
+		internal.updateStatus = types.PolicyUpdateSucceeded
+	}
+}
+
+// getEffectiveControlKnobs returns the last batch cpu requirement, and it's nil for new regions
+func (r *QoSRegionBatch) getEffectiveControlKnobs() types.ControlKnob {
+	regionInfo, ok := r.metaReader.GetRegionInfo(r.name)
+	if ok {
+		if _, existed := regionInfo.ControlKnobMap[types.ControlKnobReclaimedBatchCPURequirement]; existed {
+			return regionInfo.ControlKnobMap
+		}
+	}
+	return nil
+}
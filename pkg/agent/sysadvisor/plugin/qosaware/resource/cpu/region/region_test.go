@@ -122,6 +122,32 @@ func TestGetRegionNameFromMetaCache(t *testing.T) {
 			},
 			regionName: "",
 		},
+		{
+			name: "batch-region",
+			container: &types.ContainerInfo{
+				QoSLevel:    consts.PodAnnotationQoSLevelReclaimedCores,
+				RegionNames: sets.NewString("batch-t"),
+			},
+			region: &types.RegionInfo{
+				RegionName: "batch-t",
+				RegionType: types.QoSRegionTypeBatch,
+			},
+			numaID:     commonstate.FakedNUMAID,
+			regionName: "batch-t",
+		},
+		{
+			name: "batch-region-type-miss",
+			container: &types.ContainerInfo{
+				QoSLevel:    consts.PodAnnotationQoSLevelReclaimedCores,
+				RegionNames: sets.NewString("share-t"),
+			},
+			region: &types.RegionInfo{
+				RegionName: "share-t",
+				RegionType: configapi.QoSRegionTypeShare,
+			},
+			numaID:     commonstate.FakedNUMAID,
+			regionName: "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,186 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package regulator
+
+import (
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	metricAdaptiveRegulatorPre      = "cpu_adaptive_regulator_pre_regulation"
+	metricAdaptiveRegulatorSmoothed = "cpu_adaptive_regulator_smoothed"
+	metricAdaptiveRegulatorPost     = "cpu_adaptive_regulator_post_regulation"
+	metricAdaptiveRegulatorDamped   = "cpu_adaptive_regulator_damped"
+)
+
+type AdaptiveRegulatorOptions struct {
+	// HysteresisUpBand and HysteresisDownBand are the cpu cores a requirement must exceed
+	// above or below the current value before it is followed
+	HysteresisUpBand   float64
+	HysteresisDownBand float64
+
+	// SmoothingFactor is the weight of the latest raw requirement in exponential smoothing, within (0, 1]
+	SmoothingFactor float64
+
+	// OscillationWindow is the number of latest regulations checked for oscillation, and the requirement
+	// is regarded as oscillating if its change direction flips at least OscillationThreshold times within
+	OscillationWindow    int
+	OscillationThreshold int
+
+	// DampingFactor scales down smoothing factor and widens hysteresis bands while oscillating, within (0, 1]
+	DampingFactor float64
+}
+
+// AdaptiveCPURegulator keeps region size from flapping when raw cpu requirement oscillates around
+// a threshold: raw requirement is exponentially smoothed and then held within hysteresis bands of the
+// current value, and once the change direction flips too often within window, smoothing and hysteresis
+// are damped until no flip is left in window. The result is further restricted as CPURegulator does.
+type AdaptiveCPURegulator struct {
+	*CPURegulator
+
+	smoothed    float64
+	initialized bool
+	// directions records the change direction (-1, 0 or 1) of the latest regulations
+	directions []int
+	damped     bool
+}
+
+var _ Regulator = &AdaptiveCPURegulator{}
+
+// NewAdaptiveCPURegulator returns an adaptive cpu regulator instance with immutable parameters
+func NewAdaptiveCPURegulator(essentials types.ResourceEssentials, options RegulatorOptions) Regulator {
+	return &AdaptiveCPURegulator{
+		CPURegulator: NewCPURegulator(essentials, options).(*CPURegulator),
+	}
+}
+
+// Regulate runs an episode of adaptive regulation and stores the result as the latest cpu requirement
+func (a *AdaptiveCPURegulator) Regulate(controlKnob types.ControlKnobItem, effectiveControlKnob *types.ControlKnobItem) {
+	opts := a.AdaptiveOptions
+	raw := controlKnob.Value
+
+	smoothingFactor, upBand, downBand := opts.SmoothingFactor, opts.HysteresisUpBand, opts.HysteresisDownBand
+	if smoothingFactor <= 0 || smoothingFactor > 1 {
+		smoothingFactor = 1
+	}
+	if a.damped && opts.DampingFactor > 0 && opts.DampingFactor < 1 {
+		smoothingFactor *= opts.DampingFactor
+		upBand /= opts.DampingFactor
+		downBand /= opts.DampingFactor
+	}
+
+	if !a.initialized {
+		a.smoothed = raw
+	} else {
+		a.smoothed = smoothingFactor*raw + (1-smoothingFactor)*a.smoothed
+	}
+
+	// hold current value if smoothed requirement stays within hysteresis bands
+	held := a.smoothed + a.ReservedForAllocate
+	baseline, hasBaseline := a.baseline(effectiveControlKnob)
+	if hasBaseline {
+		if (held >= baseline && held-baseline <= upBand) || (held < baseline && baseline-held <= downBand) {
+			held = baseline
+		}
+	}
+
+	cpuRequirementRound := a.round(held)
+	cpuRequirementSlowdown := a.slowdown(cpuRequirementRound, effectiveControlKnob)
+	cpuRequirementClamp := a.clamp(cpuRequirementSlowdown)
+
+	if hasBaseline {
+		a.recordDirection(float64(cpuRequirementClamp) - baseline)
+	}
+
+	klog.Infof("[qosaware-cpu] adaptive regulator raw: %.2f, smoothed: %.2f, held: %.2f, after round: %d, "+
+		"after slowdown: %d, after clamp: %d, damped: %v", raw, a.smoothed, held, cpuRequirementRound,
+		cpuRequirementSlowdown, cpuRequirementClamp, a.damped)
+
+	a.updateControlKnob(float64(cpuRequirementClamp))
+	a.initialized = true
+	a.emit(raw, float64(cpuRequirementClamp))
+}
+
+// RestoreRegulatorInfo restores the cpu regulator, and starts smoothing from the restored requirement
+func (a *AdaptiveCPURegulator) RestoreRegulatorInfo(info types.RegulatorInfo) {
+	a.CPURegulator.RestoreRegulatorInfo(info)
+	a.smoothed = info.LatestValue - a.ReservedForAllocate
+	a.initialized = true
+}
+
+// baseline is the current requirement that hysteresis is relative to
+func (a *AdaptiveCPURegulator) baseline(effectiveControlKnob *types.ControlKnobItem) (float64, bool) {
+	if effectiveControlKnob != nil {
+		return effectiveControlKnob.Value, true
+	}
+	return a.latestControlKnobItem.Value, a.initialized
+}
+
+// recordDirection appends the latest change direction and updates damping state by direction flips within window
+func (a *AdaptiveCPURegulator) recordDirection(delta float64) {
+	window := a.AdaptiveOptions.OscillationWindow
+	if window <= 0 || a.AdaptiveOptions.OscillationThreshold <= 0 {
+		return
+	}
+
+	direction := 0
+	if delta > 0 {
+		direction = 1
+	} else if delta < 0 {
+		direction = -1
+	}
+	a.directions = append(a.directions, direction)
+	if len(a.directions) > window {
+		a.directions = a.directions[len(a.directions)-window:]
+	}
+
+	flips, last := 0, 0
+	for _, d := range a.directions {
+		if d == 0 {
+			continue
+		}
+		if last != 0 && d != last {
+			flips++
+		}
+		last = d
+	}
+	// damping is released only after no flip is left in window, to avoid flapping of damping itself
+	if flips >= a.AdaptiveOptions.OscillationThreshold {
+		a.damped = true
+	} else if flips == 0 {
+		a.damped = false
+	}
+}
+
+func (a *AdaptiveCPURegulator) emit(pre, post float64) {
+	if a.Emitter == nil {
+		return
+	}
+
+	tags := []metrics.MetricTag{{Key: "region_name", Val: a.RegionName}}
+	_ = a.Emitter.StoreFloat64(metricAdaptiveRegulatorPre, pre, metrics.MetricTypeNameRaw, tags...)
+	_ = a.Emitter.StoreFloat64(metricAdaptiveRegulatorSmoothed, a.smoothed, metrics.MetricTypeNameRaw, tags...)
+	_ = a.Emitter.StoreFloat64(metricAdaptiveRegulatorPost, post, metrics.MetricTypeNameRaw, tags...)
+	damped := 0.0
+	if a.damped {
+		damped = 1
+	}
+	_ = a.Emitter.StoreFloat64(metricAdaptiveRegulatorDamped, damped, metrics.MetricTypeNameRaw, tags...)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package regulator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+func newTestAdaptiveRegulator(adaptiveOptions AdaptiveRegulatorOptions) *AdaptiveCPURegulator {
+	return NewAdaptiveCPURegulator(types.ResourceEssentials{
+		ResourceUpperBound: 40,
+		ResourceLowerBound: 2,
+	}, RegulatorOptions{
+		MaxRampUpStep:   10,
+		MaxRampDownStep: 2,
+		NeedHTAligned:   func() bool { return false },
+		AdaptiveOptions: adaptiveOptions,
+		Emitter:         metrics.DummyMetrics{},
+		RegionName:      "share",
+	}).(*AdaptiveCPURegulator)
+}
+
+// regulate feeds raw requirements in sequence with the previous result as effective control knob
+func regulate(a *AdaptiveCPURegulator, raws ...float64) []int {
+	var results []int
+	for _, raw := range raws {
+		var effective *types.ControlKnobItem
+		if a.initialized {
+			effective = &types.ControlKnobItem{Value: float64(a.GetRequirement())}
+		}
+		a.Regulate(types.ControlKnobItem{Value: raw}, effective)
+		results = append(results, a.GetRequirement())
+	}
+	return results
+}
+
+func TestAdaptiveCPURegulator_Regulate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options AdaptiveRegulatorOptions
+		raws    []float64
+		want    []int
+	}{
+		{
+			name: "hysteresis holds small changes",
+			options: AdaptiveRegulatorOptions{
+				HysteresisUpBand:   0.5,
+				HysteresisDownBand: 2,
+				SmoothingFactor:    1,
+			},
+			raws: []float64{10, 10.4, 12, 10.5, 9, 5},
+			want: []int{10, 10, 12, 12, 10, 8},
+		},
+		{
+			name: "exponential smoothing",
+			options: AdaptiveRegulatorOptions{
+				SmoothingFactor: 0.5,
+			},
+			raws: []float64{10, 20, 20, 20},
+			want: []int{10, 15, 18, 19},
+		},
+		{
+			name:    "no adaptive options behaves as cpu regulator",
+			options: AdaptiveRegulatorOptions{},
+			raws:    []float64{10, 30, 5, 50},
+			want:    []int{10, 20, 18, 28},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			a := newTestAdaptiveRegulator(tt.options)
+			assert.Equal(t, tt.want, regulate(a, tt.raws...))
+		})
+	}
+}
+
+func TestAdaptiveCPURegulator_OscillationDamping(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAdaptiveRegulator(AdaptiveRegulatorOptions{
+		HysteresisUpBand:     1,
+		HysteresisDownBand:   1,
+		SmoothingFactor:      1,
+		OscillationWindow:    6,
+		OscillationThreshold: 3,
+		DampingFactor:        0.5,
+	})
+
+	// raw requirement flapping between 10 and 14 is followed until flips reach threshold
+	results := regulate(a, 10, 14, 10, 14, 10)
+	assert.Equal(t, []int{10, 14, 12, 14, 12}, results)
+	assert.True(t, a.damped)
+
+	// damped regulation is smoothed and held by widened bands
+	results = regulate(a, 14, 10, 14, 10)
+	assert.Equal(t, []int{12, 12, 12, 12}, results)
+
+	// damping is released once no flip is left in window
+	regulate(a, 12, 12, 12, 12, 12, 12)
+	assert.False(t, a.damped)
+}
+
+func TestAdaptiveCPURegulator_RestoreRegulatorInfo(t *testing.T) {
+	t.Parallel()
+
+	info := types.RegulatorInfo{LatestValue: 20, LatestRampDownTime: time.Now().UnixNano()}
+
+	restored := newTestAdaptiveRegulator(AdaptiveRegulatorOptions{SmoothingFactor: 1})
+	restored.MinRampDownPeriod = time.Minute
+	restored.RestoreRegulatorInfo(info)
+	assert.Equal(t, 20, restored.GetRequirement())
+	assert.Equal(t, info, restored.GetRegulatorInfo())
+
+	// ramp down is still restricted by the restored ramp down time
+	assert.Equal(t, []int{20}, regulate(restored, 10))
+
+	fresh := newTestAdaptiveRegulator(AdaptiveRegulatorOptions{SmoothingFactor: 1})
+	fresh.MinRampDownPeriod = time.Minute
+	fresh.latestRampDownTime = time.Now().Add(-time.Minute)
+	fresh.RestoreRegulatorInfo(types.RegulatorInfo{LatestValue: 20})
+	assert.Equal(t, []int{18}, regulate(fresh, 10))
+}
@@ -23,6 +23,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
 	"github.com/kubewharf/katalyst-core/pkg/util/general"
 )
 
@@ -38,6 +39,11 @@ type Regulator interface {
 
 	// GetRequirement returns the latest regulated requirement
 	GetRequirement() int
+
+	// GetRegulatorInfo returns the regulation history to be persisted
+	GetRegulatorInfo() types.RegulatorInfo
+	// RestoreRegulatorInfo warm starts regulator with the persisted regulation history
+	RestoreRegulatorInfo(info types.RegulatorInfo)
 }
 
 // DummyRegulator always get requirement without regulate
@@ -62,6 +68,14 @@ func (d *DummyRegulator) GetRequirement() int {
 	return int(d.latestControlKnobValue.Value)
 }
 
+func (d *DummyRegulator) GetRegulatorInfo() types.RegulatorInfo {
+	return types.RegulatorInfo{LatestValue: d.latestControlKnobValue.Value}
+}
+
+func (d *DummyRegulator) RestoreRegulatorInfo(info types.RegulatorInfo) {
+	d.latestControlKnobValue.Value = info.LatestValue
+}
+
 // CPURegulator gets raw cpu requirement data from policy and generates real cpu requirement
 // for a certain region with fine-grained strategies to be robust
 type CPURegulator struct {
@@ -89,6 +103,13 @@ type RegulatorOptions struct {
 
 	// MinRampDownPeriod is the min time gap between two consecutive cpu requirement ramp down
 	MinRampDownPeriod time.Duration
+
+	// AdaptiveOptions is only used by adaptive regulator
+	AdaptiveOptions AdaptiveRegulatorOptions
+
+	// Emitter and RegionName are used to emit regulation details
+	Emitter    metrics.MetricEmitter
+	RegionName string
 }
 
 // NewCPURegulator returns a cpu regulator instance with immutable parameters
@@ -135,6 +156,23 @@ func (c *CPURegulator) GetRequirement() int {
 	return int(c.latestControlKnobItem.Value)
 }
 
+// GetRegulatorInfo returns the latest cpu requirement and ramp down time to be persisted
+func (c *CPURegulator) GetRegulatorInfo() types.RegulatorInfo {
+	return types.RegulatorInfo{
+		LatestValue:        c.latestControlKnobItem.Value,
+		LatestRampDownTime: c.latestRampDownTime.UnixNano(),
+	}
+}
+
+// RestoreRegulatorInfo restores the latest cpu requirement and ramp down time, so that
+// ramp down frequency is still restricted across restart
+func (c *CPURegulator) RestoreRegulatorInfo(info types.RegulatorInfo) {
+	c.latestControlKnobItem.Value = info.LatestValue
+	if info.LatestRampDownTime > 0 {
+		c.latestRampDownTime = time.Unix(0, info.LatestRampDownTime)
+	}
+}
+
 func (c *CPURegulator) slowdown(cpuRequirement int, effectiveControlKnobItem *types.ControlKnobItem) int {
 	if effectiveControlKnobItem == nil {
 		return cpuRequirement
@@ -21,6 +21,7 @@ import (
 	"io/ioutil"
 	"math"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -39,6 +40,8 @@ import (
 	internalfake "github.com/kubewharf/katalyst-api/pkg/client/clientset/versioned/fake"
 	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	inferenceconsts "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/consts"
+	localfetcher "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/modelresultfetcher/local"
 	borweinconsts "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/models/borwein/consts"
 	borweininfsvc "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/models/borwein/inferencesvc"
 	borweintypes "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/inference/models/borwein/types"
@@ -486,6 +489,51 @@ func Test_updateCPUUsageIndicatorOffset(t *testing.T) {
 	}
 }
 
+func Test_updateCPUUsageIndicatorOffsetWithLocalModel(t *testing.T) {
+	t.Parallel()
+	podUID1 := "test-pod-uid1"
+	podUID2 := "test-pod-uid2"
+	containerName := "test-container"
+
+	conf := initConf(t, t.TempDir(), t.TempDir())
+	modelDir := t.TempDir()
+	conf.InferencePluginConfiguration.EnableLocalModelResultFetcher = true
+	conf.InferencePluginConfiguration.LocalModelDirectory = modelDir
+
+	// local model named after the borwein model it replaces
+	require.NoError(t, os.WriteFile(filepath.Join(modelDir, borweinconsts.ModelNameBorweinLatencyRegression+".json"),
+		[]byte(`{"type": "linear", "version": "v1", "feature_names": ["net"], "linear": {"weights": [1]}}`), 0o644))
+
+	mc, err := metacache.NewMetaCacheImp(conf, metricspool.DummyMetricsEmitterPool{}, nil)
+	require.NoError(t, err)
+	for _, podUID := range []string{podUID1, podUID2} {
+		require.NoError(t, mc.AddContainer(podUID, containerName, &types.ContainerInfo{
+			PodUID:        podUID,
+			ContainerName: containerName,
+			ContainerType: v1alpha1.ContainerType_MAIN,
+		}))
+	}
+	require.NoError(t, mc.SetModelInput(inferenceconsts.MetricDimensionNode, map[string]interface{}{}))
+	require.NoError(t, mc.SetModelInput(inferenceconsts.MetricDimensionContainer, map[string]interface{}{
+		podUID1: map[string]map[string]interface{}{containerName: {"net": 1.0}},
+		podUID2: map[string]map[string]interface{}{containerName: {"net": -0.5}},
+	}))
+
+	fetcher, err := localfetcher.NewLocalModelResultFetcher(localfetcher.LocalModelResultFetcherName, conf, nil,
+		metricspool.DummyMetricsEmitterPool{}, nil, mc)
+	require.NoError(t, err)
+	require.NoError(t, fetcher.FetchModelResult(context.Background(), mc, mc, nil))
+
+	got, err := updateCPUUsageIndicatorOffset(types.PodSet{
+		podUID1: sets.NewString(containerName),
+		podUID2: sets.NewString(containerName),
+	}, 0.03, conf.BorweinParameters[string(workloadv1alpha1.ServiceSystemIndicatorNameCPUUsageRatio)],
+		mc, conf, metrics.DummyMetrics{}, "test")
+	require.NoError(t, err)
+	// average predict value is 0.25, which is the same as test1 of Test_updateCPUUsageIndicatorOffset
+	require.Equal(t, 0.15, got)
+}
+
 func TestBorweinController_updateIndicatorOffsets(t *testing.T) {
 	t.Parallel()
 	podUID1 := "test-pod-uid1"
@@ -0,0 +1,133 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helper
+
+import (
+	"math"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+// MPCParams holds the cost weights and move constraints of mpc controller
+type MPCParams struct {
+	Horizon         int
+	CPUCostWeight   float64
+	MoveCostWeight  float64
+	Resolution      float64
+	MaxRampUpStep   float64
+	MaxRampDownStep float64
+}
+
+// MPCIndicator is the observed value and setpoint of a service indicator along with its model
+type MPCIndicator struct {
+	types.MPCIndicatorParams
+	Current float64
+	Target  float64
+}
+
+// MPCController is a model predictive controller of cpu requirement. Each indicator is predicted to move
+// towards its steady state at the candidate cpu as a first order lag, and the steady state is extrapolated
+// from the current observation by elasticity. Control moves are blocked into a single cpu level reached
+// by rate limited ramps, and the level with the least cost over horizon is picked, of which only the first
+// move is applied as in receding horizon control.
+type MPCController struct {
+	params MPCParams
+}
+
+func NewMPCController(params MPCParams) *MPCController {
+	if params.Horizon <= 0 {
+		params.Horizon = 1
+	}
+	if params.Resolution <= 0 {
+		params.Resolution = 1
+	}
+	return &MPCController{params: params}
+}
+
+// Solve returns the cpu requirement for next period within [lowerBound, upperBound], along with its predicted cost
+func (c *MPCController) Solve(current, lowerBound, upperBound float64, indicators map[string]MPCIndicator) (float64, float64) {
+	if upperBound < lowerBound {
+		upperBound = lowerBound
+	}
+	best, bestCost := current, math.Inf(1)
+	for _, candidate := range c.candidates(current, lowerBound, upperBound) {
+		cost := c.cost(candidate, current, upperBound, indicators)
+		if cost < bestCost || (cost == bestCost && math.Abs(candidate-current) < math.Abs(best-current)) {
+			best, bestCost = candidate, cost
+		}
+	}
+
+	return general.Clamp(c.ramp(current, best), lowerBound, upperBound), bestCost
+}
+
+func (c *MPCController) candidates(current, lowerBound, upperBound float64) []float64 {
+	candidates := []float64{general.Clamp(current, lowerBound, upperBound)}
+	for value := lowerBound; value < upperBound; value += c.params.Resolution {
+		candidates = append(candidates, value)
+	}
+	return append(candidates, upperBound)
+}
+
+// ramp moves cpu one period towards the target level, bounded by max ramp up and down steps
+func (c *MPCController) ramp(from, to float64) float64 {
+	if c.params.MaxRampUpStep > 0 && to-from > c.params.MaxRampUpStep {
+		return from + c.params.MaxRampUpStep
+	}
+	if c.params.MaxRampDownStep > 0 && from-to > c.params.MaxRampDownStep {
+		return from - c.params.MaxRampDownStep
+	}
+	return to
+}
+
+// cost simulates the trajectory of holding the candidate level over horizon
+func (c *MPCController) cost(candidate, current, upperBound float64, indicators map[string]MPCIndicator) float64 {
+	predicted := make(map[string]float64, len(indicators))
+	for name, indicator := range indicators {
+		predicted[name] = indicator.Current
+	}
+
+	// indicators are observed at current cpu, which must be positive to extrapolate from
+	reference := math.Max(current, c.params.Resolution)
+	scale := math.Max(upperBound, 1)
+	cpu, cost := current, 0.0
+	for k := 0; k < c.params.Horizon; k++ {
+		next := c.ramp(cpu, candidate)
+		cost += c.params.MoveCostWeight * math.Pow((next-cpu)/scale, 2)
+		cost += c.params.CPUCostWeight * next / scale
+		cpu = next
+
+		for name, indicator := range indicators {
+			steady := indicator.Current * math.Pow(math.Max(cpu, c.params.Resolution)/reference, indicator.Elasticity)
+			predicted[name] += indicator.Smoothing * (steady - predicted[name])
+			cost += indicator.Weight * math.Pow(violation(indicator, predicted[name]), 2)
+		}
+	}
+	return cost
+}
+
+// violation returns how far the indicator is beyond its setpoint relatively, which is zero if satisfied;
+// the setpoint is the upper bound if the indicator is improved by more cpu, and the lower bound otherwise
+func violation(indicator MPCIndicator, value float64) float64 {
+	if indicator.Target <= 0 {
+		return 0
+	}
+	if indicator.Elasticity < 0 {
+		return math.Max(0, value/indicator.Target-1)
+	}
+	return math.Max(0, 1-value/indicator.Target)
+}
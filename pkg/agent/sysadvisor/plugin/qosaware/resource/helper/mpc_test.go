@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+var (
+	testLatencyParams = types.MPCIndicatorParams{Weight: 1, Elasticity: -1, Smoothing: 0.5}
+	testQPSParams     = types.MPCIndicatorParams{Weight: 1, Elasticity: 1, Smoothing: 0.5}
+)
+
+func TestMPCController_Solve(t *testing.T) {
+	t.Parallel()
+
+	params := MPCParams{
+		Horizon:         5,
+		CPUCostWeight:   0.1,
+		MoveCostWeight:  0.5,
+		Resolution:      0.5,
+		MaxRampUpStep:   10,
+		MaxRampDownStep: 2,
+	}
+
+	tests := []struct {
+		name       string
+		current    float64
+		lowerBound float64
+		upperBound float64
+		indicators map[string]MPCIndicator
+		assertion  func(t *testing.T, got float64)
+	}{
+		{
+			name:       "latency above target scales up",
+			current:    10,
+			lowerBound: 2,
+			upperBound: 40,
+			indicators: map[string]MPCIndicator{
+				"latency": {MPCIndicatorParams: testLatencyParams, Current: 150, Target: 100},
+			},
+			assertion: func(t *testing.T, got float64) {
+				assert.Greater(t, got, 10.0)
+				assert.LessOrEqual(t, got, 20.0)
+			},
+		},
+		{
+			name:       "latency far below target scales down by ramp down step",
+			current:    10,
+			lowerBound: 2,
+			upperBound: 40,
+			indicators: map[string]MPCIndicator{
+				"latency": {MPCIndicatorParams: testLatencyParams, Current: 30, Target: 100},
+			},
+			assertion: func(t *testing.T, got float64) {
+				assert.Equal(t, 8.0, got)
+			},
+		},
+		{
+			name:       "latency close to target holds",
+			current:    10,
+			lowerBound: 2,
+			upperBound: 40,
+			indicators: map[string]MPCIndicator{
+				"latency": {MPCIndicatorParams: testLatencyParams, Current: 100, Target: 100},
+			},
+			assertion: func(t *testing.T, got float64) {
+				assert.InDelta(t, 10.0, got, 0.5)
+			},
+		},
+		{
+			name:       "qps below target scales up",
+			current:    10,
+			lowerBound: 2,
+			upperBound: 40,
+			indicators: map[string]MPCIndicator{
+				"qps": {MPCIndicatorParams: testQPSParams, Current: 600, Target: 1000},
+			},
+			assertion: func(t *testing.T, got float64) {
+				assert.Greater(t, got, 10.0)
+			},
+		},
+		{
+			name:       "result is bounded by constraints",
+			current:    10,
+			lowerBound: 2,
+			upperBound: 12,
+			indicators: map[string]MPCIndicator{
+				"latency": {MPCIndicatorParams: testLatencyParams, Current: 400, Target: 100},
+			},
+			assertion: func(t *testing.T, got float64) {
+				assert.Equal(t, 12.0, got)
+			},
+		},
+		{
+			name:       "current out of constraints is pulled back",
+			current:    1,
+			lowerBound: 4,
+			upperBound: 12,
+			indicators: map[string]MPCIndicator{
+				"latency": {MPCIndicatorParams: testLatencyParams, Current: 20, Target: 100},
+			},
+			assertion: func(t *testing.T, got float64) {
+				assert.Equal(t, 4.0, got)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, _ := NewMPCController(params).Solve(tt.current, tt.lowerBound, tt.upperBound, tt.indicators)
+			tt.assertion(t, got)
+		})
+	}
+}
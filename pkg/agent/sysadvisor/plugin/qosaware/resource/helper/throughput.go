@@ -0,0 +1,165 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helper
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/spd"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+// ThroughputIndicator is the throughput slo of batch pods, e.g. jobs per minute, and the
+// throughput is expected to be no less than its target
+type ThroughputIndicator struct {
+	Current float64
+	Target  float64
+}
+
+// GetPodThroughputIndicators returns throughput indicators of the pod with valid targets in spd,
+// and pods without spd are regarded as having no throughput indicators.
+func GetPodThroughputIndicators(ctx context.Context, metaServer *metaserver.MetaServer, podUID string,
+	indicatorNames []string,
+) (map[string]ThroughputIndicator, error) {
+	indicators := make(map[string]ThroughputIndicator)
+	if len(indicatorNames) == 0 {
+		return indicators, nil
+	}
+
+	pod, err := metaServer.GetPod(ctx, podUID)
+	if err != nil {
+		return nil, err
+	}
+
+	targets, values, err := metaServer.ServiceBusinessIndicators(ctx, pod.ObjectMeta)
+	if err != nil && !spd.IsSPDNameOrResourceNotFound(err) {
+		return nil, err
+	} else if err != nil {
+		return indicators, nil
+	}
+
+	for _, name := range indicatorNames {
+		target, ok := targets[name]
+		if !ok || target.LowerBound == nil || *target.LowerBound <= 0 {
+			continue
+		}
+		indicators[name] = ThroughputIndicator{
+			Current: values[name],
+			Target:  *target.LowerBound,
+		}
+	}
+	return indicators, nil
+}
+
+// ThroughputIndicatorsCache caches throughput indicators of pods within one advisor cycle, since
+// getting them involves pod and spd lookups, and it should be reset at the beginning of each cycle.
+type ThroughputIndicatorsCache struct {
+	mutex          sync.Mutex
+	metaServer     *metaserver.MetaServer
+	indicatorNames []string
+	indicators     map[string]map[string]ThroughputIndicator
+}
+
+func NewThroughputIndicatorsCache(metaServer *metaserver.MetaServer, indicatorNames []string) *ThroughputIndicatorsCache {
+	return &ThroughputIndicatorsCache{
+		metaServer:     metaServer,
+		indicatorNames: indicatorNames,
+		indicators:     make(map[string]map[string]ThroughputIndicator),
+	}
+}
+
+// Get returns cached throughput indicators of the pod, and failures are not cached
+// so that they are retried on the next call
+func (c *ThroughputIndicatorsCache) Get(ctx context.Context, podUID string) (map[string]ThroughputIndicator, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if indicators, ok := c.indicators[podUID]; ok {
+		return indicators, nil
+	}
+
+	indicators, err := GetPodThroughputIndicators(ctx, c.metaServer, podUID, c.indicatorNames)
+	if err != nil {
+		return nil, err
+	}
+	c.indicators[podUID] = indicators
+	return indicators, nil
+}
+
+// Reset drops all cached indicators
+func (c *ThroughputIndicatorsCache) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.indicators = make(map[string]map[string]ThroughputIndicator)
+}
+
+// EstimateBatchCPURequirement estimates cpu requirement of batch pods to meet their throughput slo.
+// Assuming throughput grows linearly with cpu, cpu usage of each pod is scaled by the ratio of target
+// to current throughput of its most violated indicator, within [1/maxScaleRatio, maxScaleRatio].
+func EstimateBatchCPURequirement(ctx context.Context, podSet types.PodSet, indicatorsCache *ThroughputIndicatorsCache,
+	maxScaleRatio float64, metaReader metacache.MetaReader,
+) (float64, error) {
+	requirement := 0.0
+	for podUID, containerSet := range podSet {
+		usage := 0.0
+		for containerName := range containerSet {
+			usage += getContainerCPUUsage(podUID, containerName, metaReader)
+		}
+
+		indicators, err := indicatorsCache.Get(ctx, podUID)
+		if err != nil {
+			return 0, err
+		}
+
+		// indicators without current throughput are ignored, since the pod may be just started
+		ratio := 0.0
+		for _, indicator := range indicators {
+			if indicator.Current > 0 {
+				ratio = math.Max(ratio, indicator.Target/indicator.Current)
+			}
+		}
+		if ratio == 0 {
+			ratio = 1
+		}
+		ratio = general.Clamp(ratio, 1/maxScaleRatio, maxScaleRatio)
+
+		general.InfoS("estimate batch pod cpu requirement", "podUID", podUID, "usage", usage, "ratio", ratio)
+		requirement += usage * ratio
+	}
+	return requirement, nil
+}
+
+// getContainerCPUUsage returns cpu usage of the container, and falls back to cpu request if usage is absent
+func getContainerCPUUsage(podUID, containerName string, metaReader metacache.MetaReader) float64 {
+	usage, err := metaReader.GetContainerMetric(podUID, containerName, consts.MetricCPUUsageContainer)
+	if err == nil && usage.Value > 0 {
+		return usage.Value
+	}
+
+	ci, ok := metaReader.GetContainerInfo(podUID, containerName)
+	if !ok || ci == nil {
+		return 0
+	}
+	return ci.CPURequest
+}
@@ -50,6 +50,8 @@ func init() {
 	memadvisorplugin.RegisterInitializer(memadvisorplugin.MemsetBinder, memadvisorplugin.NewMemsetBinder)
 	memadvisorplugin.RegisterInitializer(memadvisorplugin.NumaMemoryBalancer, memadvisorplugin.NewMemoryBalancer)
 	memadvisorplugin.RegisterInitializer(memadvisorplugin.TransparentMemoryOffloading, memadvisorplugin.NewTransparentMemoryOffloading)
+	memadvisorplugin.RegisterInitializer(memadvisorplugin.MemoryThrottler, memadvisorplugin.NewMemoryThrottler)
+	memadvisorplugin.RegisterInitializer(memadvisorplugin.NUMAMemoryMigrator, memadvisorplugin.NewNUMAMemoryMigrator)
 	memadvisorplugin.RegisterInitializer(provisioner.MemoryProvisioner, provisioner.NewMemoryProvisioner)
 }
 
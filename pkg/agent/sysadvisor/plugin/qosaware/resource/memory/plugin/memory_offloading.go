@@ -44,12 +44,18 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
 	"github.com/kubewharf/katalyst-core/pkg/util/general"
 	"github.com/kubewharf/katalyst-core/pkg/util/native"
+	sysfscommon "github.com/kubewharf/katalyst-core/pkg/util/sysfs/common"
 )
 
 const (
 	TransparentMemoryOffloading = "transparent-memory-offloading"
 
 	MetricMemoryOffloading = "memory_offloading"
+
+	MetricMemoryOffloadingSwapUsage                     = "memory_offloading_swap_usage"
+	MetricMemoryOffloadingCompressedSwapUtilization     = "memory_offloading_compressed_swap_utilization"
+	MetricMemoryOffloadingCompressedSwapCompressionRate = "memory_offloading_compressed_swap_compression_ratio"
+	MetricMemoryOffloadingCompressedSwapWritebackRate   = "memory_offloading_compressed_swap_writeback_rate"
 )
 
 const (
@@ -72,6 +78,12 @@ var tmoPolicyFuncs sync.Map
 // TMO block funcs to filter the containers required to disable TMO
 var tmoBlockFuncs sync.Map
 
+// getCompressedSwapStats reads node-level compressed swap stats, and it's only
+// used by compressed swap policy
+var getCompressedSwapStats = func(memTotal float64) (*sysfscommon.CompressedSwapStats, error) {
+	return sysfscommon.GetCompressedSwapStats(sysfscommon.DefaultSysFSRoot, memTotal)
+}
+
 type TmoStats struct {
 	obj                  string
 	qosLevel             string
@@ -84,7 +96,12 @@ type TmoStats struct {
 	refaultActivate      float64
 	cache                float64
 	mapped               float64
+	swapUsage            float64
 	offloadingTargetSize float64
+	updateTime           time.Time
+	// compressedSwap is nil if neither zswap nor zram is enabled,
+	// and it's only collected for compressed swap policy
+	compressedSwap *sysfscommon.CompressedSwapStats
 }
 
 type TmoPolicyFn func(
@@ -161,6 +178,65 @@ func integratedPolicyFunc(lastStats TmoStats, currStats TmoStats, conf *tmoconf.
 	return err, result
 }
 
+// compressedSwapPolicyFunc calculates offloading size by integrated policy, and scales it down according to
+// the status of compressed swap pools if swap is enabled, so that offloading stops before the pools are full
+// instead of thrashing.
+func compressedSwapPolicyFunc(lastStats TmoStats, currStats TmoStats, conf *tmoconf.TMOConfigDetail, emitter metrics.MetricEmitter) (error, float64) {
+	if conf.CompressedSwapPolicyConf == nil {
+		return errors.New("compressed swap policy requires compressed swap policy configurations"), 0
+	}
+	err, result := integratedPolicyFunc(lastStats, currStats, conf, emitter)
+	if err != nil {
+		return err, 0
+	}
+
+	if conf.EnableSwap && currStats.compressedSwap != nil {
+		result *= compressedSwapScaleRatio(lastStats, currStats, conf.CompressedSwapPolicyConf, emitter)
+	}
+
+	_ = emitter.StoreFloat64(MetricMemoryOffloading, result, metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "policy", Val: string(tmoconf.TMOPolicyNameCompressedSwap)},
+		metrics.MetricTag{Key: "obj", Val: currStats.obj},
+		metrics.MetricTag{Key: "qos_level", Val: currStats.qosLevel})
+	return nil, result
+}
+
+// compressedSwapScaleRatio returns 0 if pool utilization exceeds the cap or writeback rate exceeds the limit,
+// otherwise the ratio decreases as pool utilization increases or compression ratio decreases.
+func compressedSwapScaleRatio(lastStats TmoStats, currStats TmoStats, conf *tmoconf.CompressedSwapPolicyConf, emitter metrics.MetricEmitter) float64 {
+	curr := currStats.compressedSwap
+	compressionRatio := curr.CompressionRatio()
+	writebackRate := 0.0
+	if last := lastStats.compressedSwap; last != nil {
+		if interval := currStats.updateTime.Sub(lastStats.updateTime).Seconds(); interval > 0 {
+			writebackRate = math.Max(0, curr.WrittenBackBytes-last.WrittenBackBytes) / interval
+		}
+	}
+
+	ratio := 0.0
+	if curr.Utilization < conf.PoolUtilizationCap && (conf.MaxWritebackRate <= 0 || writebackRate <= conf.MaxWritebackRate) {
+		ratio = 1 - curr.Utilization/conf.PoolUtilizationCap
+		if compressionRatio > 0 && compressionRatio < conf.MinCompressionRatio {
+			ratio *= compressionRatio / conf.MinCompressionRatio
+		}
+	}
+
+	general.InfoS("compressed swap info", "obj", currStats.obj, "utilization", curr.Utilization, "poolUtilizationCap", conf.PoolUtilizationCap,
+		"compressionRatio", compressionRatio, "minCompressionRatio", conf.MinCompressionRatio,
+		"writebackRate", general.FormatMemoryQuantity(writebackRate), "maxWritebackRate", general.FormatMemoryQuantity(conf.MaxWritebackRate),
+		"swapUsage", general.FormatMemoryQuantity(currStats.swapUsage), "ratio", ratio)
+	for metricName, value := range map[string]float64{
+		MetricMemoryOffloadingCompressedSwapUtilization:     curr.Utilization,
+		MetricMemoryOffloadingCompressedSwapCompressionRate: compressionRatio,
+		MetricMemoryOffloadingCompressedSwapWritebackRate:   writebackRate,
+	} {
+		_ = emitter.StoreFloat64(metricName, value, metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: "obj", Val: currStats.obj},
+			metrics.MetricTag{Key: "qos_level", Val: currStats.qosLevel})
+	}
+	return ratio
+}
+
 type TMOBlockFn func(ci *types.ContainerInfo, conf interface{}, dynamicConf interface{}) bool
 
 func DummyTMOBlockFn(ci *types.ContainerInfo, conf interface{}, dynamicConf interface{}) bool {
@@ -171,6 +247,7 @@ func init() {
 	RegisterTMOPolicyFunc(v1alpha1.TMOPolicyNamePSI, psiPolicyFunc)
 	RegisterTMOPolicyFunc(v1alpha1.TMOPolicyNameRefault, refaultPolicyFunc)
 	RegisterTMOPolicyFunc(v1alpha1.TMOPolicyNameIntegrated, integratedPolicyFunc)
+	RegisterTMOPolicyFunc(tmoconf.TMOPolicyNameCompressedSwap, compressedSwapPolicyFunc)
 	RegisterTMOBlockFunc(DummyTMOBlockFnName, DummyTMOBlockFn)
 }
 
@@ -236,6 +313,7 @@ func NewTmoEngineInstance(obj interface{}, metaServer *metaserver.MetaServer, em
 	}
 	if ci, ok := obj.(*types.ContainerInfo); ok {
 		tmoEngine.containerInfo = ci
+		tmoEngine.conf = tmo.NewQoSLevelTMOConfigDetail(tmoConf.DefaultConfigurations, katalystapiconsts.QoSLevel(ci.QoSLevel))
 	}
 	return tmoEngine
 }
@@ -305,6 +383,10 @@ func (tmoEngine *tmoEngineInstance) getStats() (TmoStats, error) {
 		tmoStats.cache = memCache.Value
 		tmoStats.mapped = memMappedFile.Value
 		tmoStats.offloadingTargetSize = tmoEngine.offloadingTargetSize
+		// swap usage is only used for accounting, so it's not required
+		if swapUsage, err := metaserver.GetCgroupMetric(relativePath, consts.MetricMemSwapCgroup); err == nil {
+			tmoStats.swapUsage = swapUsage.Value
+		}
 		general.Infof("Memory Usage of Cgroup %s, memUsage: %v, cache: %v, mapped: %v", tmoEngine.cgpath, memUsage.Value, memCache.Value, memMappedFile.Value)
 		return nil
 	}
@@ -359,6 +441,10 @@ func (tmoEngine *tmoEngineInstance) getStats() (TmoStats, error) {
 		tmoStats.cache = memCache.Value
 		tmoStats.mapped = memMappedFile.Value
 		tmoStats.offloadingTargetSize = tmoEngine.offloadingTargetSize
+		// swap usage is only used for accounting, so it's not required
+		if swapUsage, err := metaserver.GetContainerMetric(podUID, containerName, consts.MetricMemSwapContainer); err == nil {
+			tmoStats.swapUsage = swapUsage.Value
+		}
 		general.Infof("Memory Usage of Pod %v, Container %v, memUsage: %v, cache: %v, mapped: %v", podUID, containerName, memUsage.Value, memCache.Value, memMappedFile.Value)
 		return nil
 	}
@@ -372,7 +458,26 @@ func (tmoEngine *tmoEngineInstance) getStats() (TmoStats, error) {
 		tmoStats.obj = strings.Join([]string{tmoEngine.containerInfo.PodNamespace, tmoEngine.containerInfo.PodName}, "/")
 		tmoStats.qosLevel = tmoEngine.containerInfo.QoSLevel
 	}
-	return *tmoStats, err
+	if err != nil {
+		return *tmoStats, err
+	}
+
+	tmoStats.updateTime = time.Now()
+	_ = tmoEngine.emitter.StoreFloat64(MetricMemoryOffloadingSwapUsage, tmoStats.swapUsage, metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "obj", Val: tmoStats.obj},
+		metrics.MetricTag{Key: "qos_level", Val: tmoStats.qosLevel})
+
+	if tmoEngine.conf.PolicyName == tmoconf.TMOPolicyNameCompressedSwap {
+		memTotal, err := tmoEngine.metaServer.GetNodeMetric(consts.MetricMemTotalSystem)
+		if err != nil {
+			return *tmoStats, err
+		}
+		tmoStats.compressedSwap, err = getCompressedSwapStats(memTotal.Value)
+		if err != nil {
+			return *tmoStats, err
+		}
+	}
+	return *tmoStats, nil
 }
 
 func (tmoEngine *tmoEngineInstance) GetOffloadingTargetSize() float64 {
@@ -401,6 +506,11 @@ func (tmoEngine *tmoEngineInstance) LoadConf(detail *tmo.TMOConfigDetail) {
 		tmoEngine.conf.RefaultPolicyConf.ReclaimAccuracyTarget = refaultPolicyConfDynamic.ReclaimAccuracyTarget
 		tmoEngine.conf.RefaultPolicyConf.ReclaimScanEfficiencyTarget = refaultPolicyConfDynamic.ReclaimScanEfficiencyTarget
 	}
+	if compressedSwapPolicyConf := detail.CompressedSwapPolicyConf; compressedSwapPolicyConf != nil {
+		tmoEngine.conf.CompressedSwapPolicyConf.PoolUtilizationCap = compressedSwapPolicyConf.PoolUtilizationCap
+		tmoEngine.conf.CompressedSwapPolicyConf.MinCompressionRatio = compressedSwapPolicyConf.MinCompressionRatio
+		tmoEngine.conf.CompressedSwapPolicyConf.MaxWritebackRate = compressedSwapPolicyConf.MaxWritebackRate
+	}
 }
 
 func (tmoEngine *tmoEngineInstance) CalculateOffloadingTargetSize() {
@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	tmoconf "github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/tmo"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	sysfscommon "github.com/kubewharf/katalyst-core/pkg/util/sysfs/common"
+)
+
+func TestCompressedSwapPolicyFunc(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	lastStats := TmoStats{
+		updateTime: now.Add(-10 * time.Second),
+		compressedSwap: &sysfscommon.CompressedSwapStats{
+			WrittenBackBytes: 100 << 20,
+		},
+	}
+	// psi policy results in 1% of memory usage, and refault policy results in 10% of
+	// inactive memory since last offloading size is 0
+	currStats := TmoStats{
+		obj:         "default/pod",
+		memUsage:    100 << 20,
+		memInactive: 10 << 20,
+		updateTime:  now,
+	}
+
+	tests := []struct {
+		name           string
+		enableSwap     bool
+		compressedSwap *sysfscommon.CompressedSwapStats
+		want           float64
+	}{
+		{
+			name:       "compressed swap is disabled",
+			enableSwap: true,
+			want:       1 << 20,
+		},
+		{
+			name: "swap is disabled",
+			compressedSwap: &sysfscommon.CompressedSwapStats{
+				Utilization: 0.95,
+			},
+			want: 1 << 20,
+		},
+		{
+			name:       "scaled by pool utilization",
+			enableSwap: true,
+			compressedSwap: &sysfscommon.CompressedSwapStats{
+				Utilization:      0.45,
+				OriginalBytes:    300,
+				CompressedBytes:  100,
+				WrittenBackBytes: 100 << 20,
+			},
+			want: 0.5 * (1 << 20),
+		},
+		{
+			name:       "scaled by pool utilization and compression ratio",
+			enableSwap: true,
+			compressedSwap: &sysfscommon.CompressedSwapStats{
+				Utilization:      0.45,
+				OriginalBytes:    100,
+				CompressedBytes:  100,
+				WrittenBackBytes: 100 << 20,
+			},
+			want: 0.25 * (1 << 20),
+		},
+		{
+			name:       "pool utilization exceeds cap",
+			enableSwap: true,
+			compressedSwap: &sysfscommon.CompressedSwapStats{
+				Utilization:      0.9,
+				WrittenBackBytes: 100 << 20,
+			},
+			want: 0,
+		},
+		{
+			name:       "writeback rate exceeds limit",
+			enableSwap: true,
+			compressedSwap: &sysfscommon.CompressedSwapStats{
+				WrittenBackBytes: 300 << 20,
+			},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := tmoconf.NewTMOConfigDetail(tmoconf.NewTMODefaultConfigurations())
+			conf.EnableSwap = tt.enableSwap
+			conf.PSIPolicyConf.PsiAvg60Threshold = 1
+
+			stats := currStats
+			stats.compressedSwap = tt.compressedSwap
+			err, result := compressedSwapPolicyFunc(lastStats, stats, conf, metrics.DummyMetrics{})
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.want, result, 1)
+		})
+	}
+}
+
+func TestNewQoSLevelTMOConfigDetail(t *testing.T) {
+	t.Parallel()
+
+	defaultConfigs := tmoconf.NewTMODefaultConfigurations()
+	defaultConfigs.TMOCompressedSwapPolicyQoSLevelPoolUtilizationCaps["reclaimed_cores"] = 0.95
+
+	reclaimed := tmoconf.NewQoSLevelTMOConfigDetail(defaultConfigs, "reclaimed_cores")
+	assert.Equal(t, 0.95, reclaimed.CompressedSwapPolicyConf.PoolUtilizationCap)
+
+	shared := tmoconf.NewQoSLevelTMOConfigDetail(defaultConfigs, "shared_cores")
+	assert.Equal(t, tmoconf.DefaultTMOCompressedSwapPolicyPoolUtilizationCap, shared.CompressedSwapPolicyConf.PoolUtilizationCap)
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"strconv"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/memory/dynamicpolicy/memoryadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+	procfsmgr "github.com/kubewharf/katalyst-core/pkg/util/procfs/manager"
+)
+
+const (
+	MemoryThrottler = "memory-throttler"
+
+	metricMemoryThrottlerMemoryHigh = "memory_throttler_memory_high"
+
+	memoryHighUnlimited = -1
+)
+
+// nodeMemoryPSIReader returns node memory psi some avg60 in percentage
+type nodeMemoryPSIReader func() (float64, error)
+
+func readNodeMemoryPSI() (float64, error) {
+	psi, err := procfsmgr.GetPSIStatsForResource(string(common.PressureResourceMemory))
+	if err != nil {
+		return 0, err
+	} else if psi.Some == nil {
+		return 0, nil
+	}
+	return psi.Some.Avg60, nil
+}
+
+// throttleState records memory.high of a throttled container, and baseline
+// is the memory usage before throttling
+type throttleState struct {
+	baseline float64
+	high     float64
+}
+
+// memoryThrottler sets memory.high for reclaimed and low-priority shared containers to throttle their
+// allocation rate when node or NUMA memory is under pressure, and relaxes it gradually after pressure eases.
+type memoryThrottler struct {
+	mutex      sync.RWMutex
+	conf       *config.Configuration
+	metaReader metacache.MetaReader
+	metaServer *metaserver.MetaServer
+	emitter    metrics.MetricEmitter
+	psiReader  nodeMemoryPSIReader
+
+	throttledSharedPools sets.String
+
+	// states are only accessed in Reconcile, and advices are generated from it
+	states  map[consts.PodContainerName]*throttleState
+	advices []types.ContainerMemoryAdvices
+}
+
+func NewMemoryThrottler(conf *config.Configuration, extraConfig interface{}, metaReader metacache.MetaReader,
+	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
+) MemoryAdvisorPlugin {
+	return &memoryThrottler{
+		conf:                 conf,
+		metaReader:           metaReader,
+		metaServer:           metaServer,
+		emitter:              emitter,
+		psiReader:            readNodeMemoryPSI,
+		throttledSharedPools: sets.NewString(conf.ThrottledSharedPools...),
+		states:               make(map[consts.PodContainerName]*throttleState),
+	}
+}
+
+func (mt *memoryThrottler) Reconcile(status *types.MemoryPressureStatus) error {
+	nodePressure := status.NodeCondition != nil && status.NodeCondition.State != types.MemoryPressureNoRisk
+	if psiAvg60, err := mt.psiReader(); err != nil {
+		general.Errorf("failed to read node memory psi: %v", err)
+	} else if psiAvg60 >= mt.conf.PSIAvg60Threshold {
+		general.InfoS("node memory psi exceeds threshold", "psiAvg60", psiAvg60, "threshold", mt.conf.PSIAvg60Threshold)
+		nodePressure = true
+	}
+
+	pressureNUMAs := sets.NewInt()
+	for numaID, condition := range status.NUMAConditions {
+		if condition != nil && condition.State != types.MemoryPressureNoRisk {
+			pressureNUMAs.Insert(numaID)
+		}
+	}
+	for numaID, psiAvg60 := range mt.getNUMAMemoryPSI() {
+		if psiAvg60 >= mt.conf.NUMAPSIAvg60Threshold {
+			general.InfoS("numa memory psi exceeds threshold", "numaID", numaID, "psiAvg60", psiAvg60,
+				"threshold", mt.conf.NUMAPSIAvg60Threshold)
+			pressureNUMAs.Insert(numaID)
+		}
+	}
+
+	states := make(map[consts.PodContainerName]*throttleState)
+	advices := make([]types.ContainerMemoryAdvices, 0)
+	mt.metaReader.RangeContainer(func(podUID string, containerName string, ci *types.ContainerInfo) bool {
+		if !mt.isCandidate(ci) {
+			return true
+		}
+
+		key := native.GeneratePodContainerName(podUID, containerName)
+		underPressure := nodePressure
+		for numaID := range ci.TopologyAwareAssignments {
+			underPressure = underPressure || pressureNUMAs.Has(numaID)
+		}
+
+		state, err := mt.updateState(ci, mt.states[key], underPressure)
+		if err != nil {
+			general.ErrorS(err, "failed to update throttle state", "podName", ci.PodName, "containerName", containerName)
+			state = mt.states[key]
+		}
+
+		// memory.high is only advised for containers throttled in this or the previous round,
+		// so that unlimited is sent once after throttling is removed
+		memoryHigh := int64(memoryHighUnlimited)
+		if state != nil {
+			states[key] = state
+			memoryHigh = int64(state.high)
+		} else if mt.states[key] == nil {
+			return true
+		}
+
+		advices = append(advices, types.ContainerMemoryAdvices{
+			PodUID:        podUID,
+			ContainerName: containerName,
+			Values:        map[string]string{string(memoryadvisor.ControlKnobKeyMemoryHigh): strconv.FormatInt(memoryHigh, 10)},
+		})
+		_ = mt.emitter.StoreInt64(metricMemoryThrottlerMemoryHigh, memoryHigh, metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: "podName", Val: ci.PodName},
+			metrics.MetricTag{Key: "containerName", Val: containerName})
+		return true
+	})
+
+	mt.mutex.Lock()
+	defer mt.mutex.Unlock()
+	mt.states = states
+	mt.advices = advices
+	return nil
+}
+
+// getNUMAMemoryPSI returns memory psi some avg60 of each NUMA, which is the max psi of containers on
+// the NUMA, since kernel doesn't provide psi for NUMA nodes; candidates are skipped because their
+// psi rises along with throttling, and they would keep the NUMA under pressure otherwise
+func (mt *memoryThrottler) getNUMAMemoryPSI() map[int]float64 {
+	numaPSI := make(map[int]float64)
+	mt.metaReader.RangeContainer(func(podUID string, containerName string, ci *types.ContainerInfo) bool {
+		if ci == nil || mt.isCandidate(ci) {
+			return true
+		}
+
+		psi, err := mt.metaServer.GetContainerMetric(podUID, containerName, consts.MetricMemPsiAvg60Container)
+		if err != nil {
+			return true
+		}
+		for numaID := range ci.TopologyAwareAssignments {
+			numaPSI[numaID] = general.MaxFloat64(numaPSI[numaID], psi.Value)
+		}
+		return true
+	})
+	return numaPSI
+}
+
+// updateState returns the new throttle state of the container, and nil means it isn't throttled
+func (mt *memoryThrottler) updateState(ci *types.ContainerInfo, state *throttleState, underPressure bool) (*throttleState, error) {
+	if !underPressure {
+		if state == nil {
+			return nil, nil
+		}
+
+		high := state.high + state.baseline*mt.conf.RelaxStepRatio
+		if high >= state.baseline {
+			general.InfoS("remove memory throttling", "podName", ci.PodName, "containerName", ci.ContainerName)
+			return nil, nil
+		}
+		return &throttleState{baseline: state.baseline, high: high}, nil
+	}
+
+	usage, err := mt.metaServer.GetContainerMetric(ci.PodUID, ci.ContainerName, consts.MetricMemUsageContainer)
+	if err != nil {
+		return nil, err
+	}
+
+	if state == nil {
+		state = &throttleState{baseline: usage.Value, high: usage.Value}
+	}
+
+	high := general.MaxFloat64(general.MinFloat64(state.high, usage.Value)*(1-mt.conf.ThrottleStepRatio),
+		state.baseline*mt.conf.MinHighRatio)
+	general.InfoS("throttle memory", "podName", ci.PodName, "containerName", ci.ContainerName,
+		"usage", general.FormatMemoryQuantity(usage.Value), "baseline", general.FormatMemoryQuantity(state.baseline),
+		"memoryHigh", general.FormatMemoryQuantity(high))
+	return &throttleState{baseline: state.baseline, high: high}, nil
+}
+
+func (mt *memoryThrottler) isCandidate(ci *types.ContainerInfo) bool {
+	if ci == nil || ci.ContainerType != v1alpha1.ContainerType_MAIN {
+		return false
+	}
+
+	switch ci.QoSLevel {
+	case apiconsts.PodAnnotationQoSLevelReclaimedCores:
+		return true
+	case apiconsts.PodAnnotationQoSLevelSharedCores:
+		return mt.throttledSharedPools.Has(ci.OriginOwnerPoolName)
+	default:
+		return false
+	}
+}
+
+func (mt *memoryThrottler) GetAdvices() types.InternalMemoryCalculationResult {
+	mt.mutex.RLock()
+	defer mt.mutex.RUnlock()
+
+	result := types.InternalMemoryCalculationResult{
+		ContainerEntries: make([]types.ContainerMemoryAdvices, 0, len(mt.advices)),
+	}
+	result.ContainerEntries = append(result.ContainerEntries, mt.advices...)
+	return result
+}
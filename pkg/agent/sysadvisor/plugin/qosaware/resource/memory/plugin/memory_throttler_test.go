@@ -0,0 +1,185 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/memory/dynamicpolicy/memoryadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	metricspool "github.com/kubewharf/katalyst-core/pkg/metrics/metrics-pool"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+	utilmetric "github.com/kubewharf/katalyst-core/pkg/util/metric"
+)
+
+func TestMemoryThrottler(t *testing.T) {
+	t.Parallel()
+
+	conf := config.NewConfiguration()
+	conf.GenericSysAdvisorConfiguration.StateFileDirectory = t.TempDir()
+	conf.PSIAvg60Threshold = 10
+	conf.NUMAPSIAvg60Threshold = 20
+	conf.ThrottleStepRatio = 0.1
+	conf.RelaxStepRatio = 0.1
+	conf.MinHighRatio = 0.8
+	conf.ThrottledSharedPools = []string{"batch"}
+
+	metricsFetcher := metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}).(*metric.FakeMetricsFetcher)
+	metaCache, err := metacache.NewMetaCacheImp(conf, metricspool.DummyMetricsEmitterPool{}, metricsFetcher)
+	require.NoError(t, err)
+	metaServer := &metaserver.MetaServer{MetaAgent: &agent.MetaAgent{MetricsFetcher: metricsFetcher}}
+
+	containers := []*types.ContainerInfo{
+		{
+			PodUID: "reclaimed-pod", PodName: "reclaimed-pod", ContainerName: "c",
+			ContainerType: v1alpha1.ContainerType_MAIN, QoSLevel: apiconsts.PodAnnotationQoSLevelReclaimedCores,
+			TopologyAwareAssignments: types.TopologyAwareAssignment{0: machine.NewCPUSet(0)},
+		},
+		{
+			PodUID: "batch-pod", PodName: "batch-pod", ContainerName: "c",
+			ContainerType: v1alpha1.ContainerType_MAIN, QoSLevel: apiconsts.PodAnnotationQoSLevelSharedCores,
+			OriginOwnerPoolName: "batch", TopologyAwareAssignments: types.TopologyAwareAssignment{1: machine.NewCPUSet(1)},
+		},
+		{
+			PodUID: "share-pod", PodName: "share-pod", ContainerName: "c",
+			ContainerType: v1alpha1.ContainerType_MAIN, QoSLevel: apiconsts.PodAnnotationQoSLevelSharedCores,
+			OriginOwnerPoolName: "share", TopologyAwareAssignments: types.TopologyAwareAssignment{0: machine.NewCPUSet(0)},
+		},
+		{
+			PodUID: "reclaimed-pod", PodName: "reclaimed-pod", ContainerName: "sidecar",
+			ContainerType: v1alpha1.ContainerType_SIDECAR, QoSLevel: apiconsts.PodAnnotationQoSLevelReclaimedCores,
+		},
+	}
+	now := time.Now()
+	for _, ci := range containers {
+		require.NoError(t, metaCache.AddContainer(ci.PodUID, ci.ContainerName, ci))
+		metricsFetcher.SetContainerMetric(ci.PodUID, ci.ContainerName, consts.MetricMemUsageContainer,
+			utilmetric.MetricData{Value: 1000, Time: &now})
+	}
+
+	psi := 0.0
+	throttler := NewMemoryThrottler(conf, nil, metaCache, metaServer, metrics.DummyMetrics{}).(*memoryThrottler)
+	throttler.psiReader = func() (float64, error) { return psi, nil }
+
+	noPressure := &types.MemoryPressureStatus{
+		NodeCondition:  &types.MemoryPressureCondition{State: types.MemoryPressureNoRisk},
+		NUMAConditions: map[int]*types.MemoryPressureCondition{0: {State: types.MemoryPressureNoRisk}, 1: {State: types.MemoryPressureNoRisk}},
+	}
+	numa0Pressure := &types.MemoryPressureStatus{
+		NodeCondition:  &types.MemoryPressureCondition{State: types.MemoryPressureNoRisk},
+		NUMAConditions: map[int]*types.MemoryPressureCondition{0: {State: types.MemoryPressureTuneMemCg}, 1: {State: types.MemoryPressureNoRisk}},
+	}
+
+	getAdvices := func() map[string]string {
+		advices := make(map[string]string)
+		for _, entry := range throttler.GetAdvices().ContainerEntries {
+			advices[entry.PodUID+"/"+entry.ContainerName] = entry.Values[string(memoryadvisor.ControlKnobKeyMemoryHigh)]
+		}
+		return advices
+	}
+
+	rounds := []struct {
+		name   string
+		status *types.MemoryPressureStatus
+		psi    float64
+		// memory psi of share-pod on numa0 and batch-pod on numa1
+		sharePSI    float64
+		batchPSI    float64
+		wantNUMAPSI map[int]float64
+		want        map[string]string
+	}{
+		{
+			name:   "no pressure",
+			status: noPressure,
+			want:   map[string]string{},
+		},
+		{
+			name:   "numa0 pressure throttles containers on numa0",
+			status: numa0Pressure,
+			want:   map[string]string{"reclaimed-pod/c": "900"},
+		},
+		{
+			name:   "node psi pressure throttles all candidates",
+			status: numa0Pressure,
+			psi:    20,
+			want:   map[string]string{"reclaimed-pod/c": "810", "batch-pod/c": "900"},
+		},
+		{
+			name:   "memory high is limited by min high ratio",
+			status: numa0Pressure,
+			psi:    20,
+			want:   map[string]string{"reclaimed-pod/c": "800", "batch-pod/c": "810"},
+		},
+		{
+			name:   "relax gradually",
+			status: noPressure,
+			want:   map[string]string{"reclaimed-pod/c": "900", "batch-pod/c": "910"},
+		},
+		{
+			name:   "remove throttling after relaxed to baseline",
+			status: noPressure,
+			want:   map[string]string{"reclaimed-pod/c": "-1", "batch-pod/c": "-1"},
+		},
+		{
+			name:   "no advices after throttling removed",
+			status: noPressure,
+			want:   map[string]string{},
+		},
+		{
+			name:        "psi of candidates doesn't make numa pressure",
+			status:      noPressure,
+			sharePSI:    10,
+			batchPSI:    30,
+			wantNUMAPSI: map[int]float64{0: 10},
+			want:        map[string]string{},
+		},
+		{
+			name:        "numa0 psi pressure throttles containers on numa0",
+			status:      noPressure,
+			sharePSI:    25,
+			batchPSI:    30,
+			wantNUMAPSI: map[int]float64{0: 25},
+			want:        map[string]string{"reclaimed-pod/c": "900"},
+		},
+	}
+
+	for _, round := range rounds {
+		psi = round.psi
+		metricsFetcher.SetContainerMetric("share-pod", "c", consts.MetricMemPsiAvg60Container,
+			utilmetric.MetricData{Value: round.sharePSI, Time: &now})
+		metricsFetcher.SetContainerMetric("batch-pod", "c", consts.MetricMemPsiAvg60Container,
+			utilmetric.MetricData{Value: round.batchPSI, Time: &now})
+		if round.wantNUMAPSI != nil {
+			require.Equal(t, round.wantNUMAPSI, throttler.getNUMAMemoryPSI(), round.name)
+		}
+		require.NoError(t, throttler.Reconcile(round.status), round.name)
+		require.Equal(t, round.want, getAdvices(), fmt.Sprintf("round: %s", round.name))
+	}
+}
@@ -0,0 +1,272 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/memory/dynamicpolicy/memoryadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+const (
+	NUMAMemoryMigrator = "numa-memory-migrator"
+
+	metricNUMAMemoryMigratorRemoteRatio = "numa_memory_migrator_remote_ratio"
+	metricNUMAMemoryMigratorMigrate     = "numa_memory_migrator_migrate"
+	metricNUMAMemoryMigratorPaused      = "numa_memory_migrator_paused"
+)
+
+// migrateCandidate is a container whose memory resides on NUMA nodes outside of its cpuset
+type migrateCandidate struct {
+	ci          *types.ContainerInfo
+	sourceNUMAs machine.CPUSet
+	destNUMAs   machine.CPUSet
+	remoteBytes float64
+	remoteRatio float64
+}
+
+// numaMemoryMigrator migrates pages of containers whose cpuset and memory residence diverge,
+// e.g. after cpus are moved to other NUMA nodes. To avoid disturbing workloads, containers
+// are migrated gradually with budgets of bytes and containers in each round and a cooldown
+// for each container, and migration is paused if source or destination NUMA nodes are under
+// bandwidth pressure. Containers in pools balanced by memory balancer are left to it, to
+// avoid migrating their pages back and forth.
+type numaMemoryMigrator struct {
+	mutex      sync.RWMutex
+	conf       *config.Configuration
+	metaReader metacache.MetaReader
+	metaServer *metaserver.MetaServer
+	emitter    metrics.MetricEmitter
+
+	// balancedPools are pools whose memory is managed by memory balancer
+	balancedPools sets.String
+	// lastMigrateTime is only accessed in Reconcile
+	lastMigrateTime map[consts.PodContainerName]time.Time
+	advices         []types.ContainerMemoryAdvices
+}
+
+func NewNUMAMemoryMigrator(conf *config.Configuration, extraConfig interface{}, metaReader metacache.MetaReader,
+	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
+) MemoryAdvisorPlugin {
+	balancedPools := sets.NewString()
+	for _, plugin := range conf.MemoryAdvisorPlugins {
+		if plugin == NumaMemoryBalancer {
+			balancedPools.Insert(conf.NumaBalancerConfiguration.SupportedPools...)
+		}
+	}
+
+	return &numaMemoryMigrator{
+		conf:            conf,
+		metaReader:      metaReader,
+		metaServer:      metaServer,
+		emitter:         emitter,
+		balancedPools:   balancedPools,
+		lastMigrateTime: make(map[consts.PodContainerName]time.Time),
+	}
+}
+
+func (m *numaMemoryMigrator) Reconcile(_ *types.MemoryPressureStatus) error {
+	now := time.Now()
+	existing := make(map[consts.PodContainerName]bool)
+	candidates := make([]*migrateCandidate, 0)
+	m.metaReader.RangeContainer(func(podUID string, containerName string, ci *types.ContainerInfo) bool {
+		if !m.isCandidate(ci) {
+			return true
+		}
+
+		key := native.GeneratePodContainerName(podUID, containerName)
+		existing[key] = true
+
+		candidate, err := m.getMigrateCandidate(ci)
+		if err != nil {
+			general.ErrorS(err, "failed to get numa memory", "podName", ci.PodName, "containerName", containerName)
+			return true
+		} else if candidate == nil {
+			return true
+		}
+
+		_ = m.emitter.StoreFloat64(metricNUMAMemoryMigratorRemoteRatio, candidate.remoteRatio, metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: "podName", Val: ci.PodName},
+			metrics.MetricTag{Key: "containerName", Val: containerName})
+
+		if candidate.remoteRatio < m.conf.RemoteMemoryRatioThreshold {
+			return true
+		} else if lastTime, ok := m.lastMigrateTime[key]; ok && now.Sub(lastTime) < m.conf.ContainerMigrateInterval {
+			return true
+		}
+		candidates = append(candidates, candidate)
+		return true
+	})
+
+	for key := range m.lastMigrateTime {
+		if !existing[key] {
+			delete(m.lastMigrateTime, key)
+		}
+	}
+
+	// containers with higher remote ratio are migrated first
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].remoteRatio > candidates[j].remoteRatio
+	})
+
+	advices := make([]types.ContainerMemoryAdvices, 0)
+	budget := float64(m.conf.MaxMigrateBytesPerRound)
+	for _, candidate := range candidates {
+		if len(advices) >= m.conf.MaxMigrateContainersPerRound || budget <= 0 {
+			break
+		}
+
+		if pressureNUMA, ok := m.getBandwidthPressureNUMA(candidate.sourceNUMAs.Union(candidate.destNUMAs)); ok {
+			general.InfoS("pause migration under bandwidth pressure", "podName", candidate.ci.PodName,
+				"containerName", candidate.ci.ContainerName, "numa", pressureNUMA)
+			_ = m.emitter.StoreInt64(metricNUMAMemoryMigratorPaused, 1, metrics.MetricTypeNameRaw,
+				metrics.MetricTag{Key: "podName", Val: candidate.ci.PodName},
+				metrics.MetricTag{Key: "containerName", Val: candidate.ci.ContainerName})
+			continue
+		}
+
+		// containers with more remote memory than the budget are migrated partially,
+		// and the rest is migrated in later rounds
+		migrateBytes := math.Min(candidate.remoteBytes, budget)
+		migrationAdvice := types.NUMAMemoryMigrationAdvice{
+			SourceNUMAs: candidate.sourceNUMAs.ToSliceInt(),
+			DestNUMAs:   candidate.destNUMAs.ToSliceInt(),
+		}
+		if migrateBytes < candidate.remoteBytes {
+			migrationAdvice.MaxBytes = int64(migrateBytes)
+		}
+		advice, err := json.Marshal(migrationAdvice)
+		if err != nil {
+			general.Errorf("marshal numa memory migration advice failed: %v", err)
+			continue
+		}
+
+		general.InfoS("migrate numa memory", "podName", candidate.ci.PodName, "containerName", candidate.ci.ContainerName,
+			"sourceNUMAs", candidate.sourceNUMAs.String(), "destNUMAs", candidate.destNUMAs.String(),
+			"remoteBytes", general.FormatMemoryQuantity(candidate.remoteBytes), "remoteRatio", candidate.remoteRatio,
+			"migrateBytes", general.FormatMemoryQuantity(migrateBytes))
+		_ = m.emitter.StoreFloat64(metricNUMAMemoryMigratorMigrate, migrateBytes, metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: "podName", Val: candidate.ci.PodName},
+			metrics.MetricTag{Key: "containerName", Val: candidate.ci.ContainerName})
+
+		advices = append(advices, types.ContainerMemoryAdvices{
+			PodUID:        candidate.ci.PodUID,
+			ContainerName: candidate.ci.ContainerName,
+			Values:        map[string]string{string(memoryadvisor.ControlKnobKeyMigrateNUMAMemory): string(advice)},
+		})
+		m.lastMigrateTime[native.GeneratePodContainerName(candidate.ci.PodUID, candidate.ci.ContainerName)] = now
+		budget -= migrateBytes
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.advices = advices
+	return nil
+}
+
+// getMigrateCandidate returns nil if the container has no memory outside of its cpuset
+func (m *numaMemoryMigrator) getMigrateCandidate(ci *types.ContainerInfo) (*migrateCandidate, error) {
+	numaMemory, err := m.metaServer.GetContainerNumaMetrics(ci.PodUID, ci.ContainerName, consts.MetricsMemTotalPerNumaContainer)
+	if err != nil {
+		return nil, err
+	}
+
+	destNUMAs := machine.GetCPUAssignmentNUMAs(ci.TopologyAwareAssignments)
+	sourceNUMAs := machine.NewCPUSet()
+	var localBytes, remoteBytes float64
+	for numaID, data := range numaMemory {
+		if destNUMAs.Contains(numaID) {
+			localBytes += data.Value
+		} else if data.Value > 0 {
+			remoteBytes += data.Value
+			sourceNUMAs.Add(numaID)
+		}
+	}
+
+	if remoteBytes <= 0 {
+		return nil, nil
+	}
+	return &migrateCandidate{
+		ci:          ci,
+		sourceNUMAs: sourceNUMAs,
+		destNUMAs:   destNUMAs,
+		remoteBytes: remoteBytes,
+		remoteRatio: remoteBytes / (localBytes + remoteBytes),
+	}, nil
+}
+
+// getBandwidthPressureNUMA returns the first NUMA node whose bandwidth exceeds the threshold, and
+// NUMA nodes without bandwidth metrics are considered to be free of pressure
+func (m *numaMemoryMigrator) getBandwidthPressureNUMA(numas machine.CPUSet) (int, bool) {
+	for _, numaID := range numas.ToSliceInt() {
+		bandwidth, err := m.metaServer.GetNumaMetric(numaID, consts.MetricMemBandwidthNuma)
+		if err != nil {
+			continue
+		}
+		theory, err := m.metaServer.GetNumaMetric(numaID, consts.MetricMemBandwidthTheoryNuma)
+		if err != nil || theory.Value <= 0 {
+			continue
+		}
+
+		if bandwidth.Value/theory.Value >= m.conf.BandwidthPressureThreshold {
+			return numaID, true
+		}
+	}
+	return 0, false
+}
+
+// isCandidate filters main containers with cpuset assigned, and reclaimed containers
+// are excluded since their memory is managed by memset binder, as well as containers
+// in pools balanced by memory balancer
+func (m *numaMemoryMigrator) isCandidate(ci *types.ContainerInfo) bool {
+	if ci == nil || ci.ContainerType != v1alpha1.ContainerType_MAIN ||
+		ci.QoSLevel == apiconsts.PodAnnotationQoSLevelReclaimedCores || m.balancedPools.Has(ci.OwnerPoolName) {
+		return false
+	}
+	return !machine.GetCPUAssignmentNUMAs(ci.TopologyAwareAssignments).IsEmpty()
+}
+
+// GetAdvices returns migration advices only once after each Reconcile, to avoid
+// migrating pages of the same container repeatedly
+func (m *numaMemoryMigrator) GetAdvices() types.InternalMemoryCalculationResult {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	result := types.InternalMemoryCalculationResult{
+		ContainerEntries: make([]types.ContainerMemoryAdvices, 0, len(m.advices)),
+	}
+	result.ContainerEntries = append(result.ContainerEntries, m.advices...)
+	m.advices = nil
+	return result
+}
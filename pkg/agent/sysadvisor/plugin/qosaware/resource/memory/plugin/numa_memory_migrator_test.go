@@ -0,0 +1,155 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/memory/dynamicpolicy/memoryadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	metricspool "github.com/kubewharf/katalyst-core/pkg/metrics/metrics-pool"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+	utilmetric "github.com/kubewharf/katalyst-core/pkg/util/metric"
+)
+
+func TestNUMAMemoryMigrator(t *testing.T) {
+	t.Parallel()
+
+	conf := config.NewConfiguration()
+	conf.GenericSysAdvisorConfiguration.StateFileDirectory = t.TempDir()
+	conf.RemoteMemoryRatioThreshold = 0.3
+	conf.MaxMigrateBytesPerRound = 1000
+	conf.MaxMigrateContainersPerRound = 2
+	conf.ContainerMigrateInterval = time.Hour
+	conf.BandwidthPressureThreshold = 0.8
+	conf.MemoryAdvisorPlugins = []types.MemoryAdvisorPluginName{NumaMemoryBalancer}
+	conf.NumaBalancerConfiguration.SupportedPools = []string{commonstate.PoolNameShare}
+
+	metricsFetcher := metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}).(*metric.FakeMetricsFetcher)
+	metaCache, err := metacache.NewMetaCacheImp(conf, metricspool.DummyMetricsEmitterPool{}, metricsFetcher)
+	require.NoError(t, err)
+	metaServer := &metaserver.MetaServer{MetaAgent: &agent.MetaAgent{MetricsFetcher: metricsFetcher}}
+
+	now := time.Now()
+	// numa memory of each container in the order of numa0, numa1 and numa2
+	containers := map[*types.ContainerInfo][]float64{
+		// 80% memory is remote and is migrated first
+		{
+			PodUID: "pod-1", PodName: "pod-1", ContainerName: "c",
+			ContainerType: v1alpha1.ContainerType_MAIN, QoSLevel: apiconsts.PodAnnotationQoSLevelSharedCores,
+			TopologyAwareAssignments: types.TopologyAwareAssignment{0: machine.NewCPUSet(0)},
+		}: {200, 800, 0},
+		// 75% memory is remote, and exceeds the budget of one round
+		{
+			PodUID: "pod-2", PodName: "pod-2", ContainerName: "c",
+			ContainerType: v1alpha1.ContainerType_MAIN, QoSLevel: apiconsts.PodAnnotationQoSLevelDedicatedCores,
+			TopologyAwareAssignments: types.TopologyAwareAssignment{1: machine.NewCPUSet(1)},
+		}: {0, 500, 1500},
+		// 10% memory is remote, which is below the threshold
+		{
+			PodUID: "pod-3", PodName: "pod-3", ContainerName: "c",
+			ContainerType: v1alpha1.ContainerType_MAIN, QoSLevel: apiconsts.PodAnnotationQoSLevelSharedCores,
+			TopologyAwareAssignments: types.TopologyAwareAssignment{0: machine.NewCPUSet(0), 1: machine.NewCPUSet(1)},
+		}: {450, 450, 100},
+		// reclaimed containers are skipped
+		{
+			PodUID: "pod-4", PodName: "pod-4", ContainerName: "c",
+			ContainerType: v1alpha1.ContainerType_MAIN, QoSLevel: apiconsts.PodAnnotationQoSLevelReclaimedCores,
+			TopologyAwareAssignments: types.TopologyAwareAssignment{0: machine.NewCPUSet(0)},
+		}: {0, 1000, 0},
+		// containers in pools balanced by memory balancer are skipped
+		{
+			PodUID: "pod-5", PodName: "pod-5", ContainerName: "c", OwnerPoolName: commonstate.PoolNameShare,
+			ContainerType: v1alpha1.ContainerType_MAIN, QoSLevel: apiconsts.PodAnnotationQoSLevelSharedCores,
+			TopologyAwareAssignments: types.TopologyAwareAssignment{0: machine.NewCPUSet(0)},
+		}: {0, 1000, 0},
+		// 50% memory is remote, and is migrated within the budget left
+		{
+			PodUID: "pod-6", PodName: "pod-6", ContainerName: "c",
+			ContainerType: v1alpha1.ContainerType_MAIN, QoSLevel: apiconsts.PodAnnotationQoSLevelSharedCores,
+			TopologyAwareAssignments: types.TopologyAwareAssignment{0: machine.NewCPUSet(0)},
+		}: {100, 100, 0},
+		// 40% memory is remote, but exceeds the max containers in one round
+		{
+			PodUID: "pod-7", PodName: "pod-7", ContainerName: "c",
+			ContainerType: v1alpha1.ContainerType_MAIN, QoSLevel: apiconsts.PodAnnotationQoSLevelSharedCores,
+			TopologyAwareAssignments: types.TopologyAwareAssignment{0: machine.NewCPUSet(0)},
+		}: {60, 40, 0},
+	}
+	for ci, numaMemory := range containers {
+		require.NoError(t, metaCache.AddContainer(ci.PodUID, ci.ContainerName, ci))
+		for numaID, value := range numaMemory {
+			metricsFetcher.SetContainerNumaMetric(ci.PodUID, ci.ContainerName, numaID, consts.MetricsMemTotalPerNumaContainer,
+				utilmetric.MetricData{Value: value, Time: &now})
+		}
+	}
+	setBandwidth := func(numaID int, bandwidth float64) {
+		metricsFetcher.SetNumaMetric(numaID, consts.MetricMemBandwidthNuma, utilmetric.MetricData{Value: bandwidth, Time: &now})
+		metricsFetcher.SetNumaMetric(numaID, consts.MetricMemBandwidthTheoryNuma, utilmetric.MetricData{Value: 100, Time: &now})
+	}
+	setBandwidth(0, 10)
+	setBandwidth(1, 10)
+	setBandwidth(2, 90)
+
+	migrator := NewNUMAMemoryMigrator(conf, nil, metaCache, metaServer, metrics.DummyMetrics{}).(*numaMemoryMigrator)
+	getAdvices := func() map[string]types.NUMAMemoryMigrationAdvice {
+		advices := make(map[string]types.NUMAMemoryMigrationAdvice)
+		for _, entry := range migrator.GetAdvices().ContainerEntries {
+			advice := types.NUMAMemoryMigrationAdvice{}
+			require.NoError(t, json.Unmarshal([]byte(entry.Values[string(memoryadvisor.ControlKnobKeyMigrateNUMAMemory)]), &advice))
+			advices[entry.PodUID] = advice
+		}
+		return advices
+	}
+
+	// pod-1 and pod-6 are migrated within the budget
+	require.NoError(t, migrator.Reconcile(nil))
+	require.Equal(t, map[string]types.NUMAMemoryMigrationAdvice{
+		"pod-1": {SourceNUMAs: []int{1}, DestNUMAs: []int{0}},
+		"pod-6": {SourceNUMAs: []int{1}, DestNUMAs: []int{0}},
+	}, getAdvices())
+	// advices are only returned once
+	require.Empty(t, getAdvices())
+
+	// pod-1 and pod-6 are in cooldown, pod-2 is paused since numa2 is under bandwidth pressure,
+	// and pod-7 is migrated
+	require.NoError(t, migrator.Reconcile(nil))
+	require.Equal(t, map[string]types.NUMAMemoryMigrationAdvice{
+		"pod-7": {SourceNUMAs: []int{1}, DestNUMAs: []int{0}},
+	}, getAdvices())
+
+	// pod-2 is partially migrated within the budget after bandwidth pressure is relieved
+	setBandwidth(2, 10)
+	require.NoError(t, migrator.Reconcile(nil))
+	require.Equal(t, map[string]types.NUMAMemoryMigrationAdvice{
+		"pod-2": {SourceNUMAs: []int{2}, DestNUMAs: []int{1}, MaxBytes: 1000},
+	}, getAdvices())
+}
@@ -832,6 +832,10 @@ func (cs *cpuServer) assembleNormalPodEntries(calculationEntriesMap map[string]*
 	if !ci.Isolated && ci.OwnerPoolName != ci.OriginOwnerPoolName {
 		calculationInfo.OwnerPoolName = ci.OriginOwnerPoolName
 	}
+	// if batch pool is carved out of reclaim pool, pass batch pool for containers in batch region
+	if _, ok := calculationEntriesMap[commonstate.PoolNameBatch]; ok && cs.inBatchRegion(ci) {
+		calculationInfo.OwnerPoolName = commonstate.PoolNameBatch
+	}
 
 	if ci.QoSLevel == consts.PodAnnotationQoSLevelSharedCores || ci.QoSLevel == consts.PodAnnotationQoSLevelReclaimedCores {
 		if calculationInfo.OwnerPoolName == "" {
@@ -856,6 +860,19 @@ func (cs *cpuServer) assembleNormalPodEntries(calculationEntriesMap map[string]*
 	return nil
 }
 
+// inBatchRegion returns true if the given reclaimed_cores container is assigned to batch region
+func (cs *cpuServer) inBatchRegion(ci *types.ContainerInfo) bool {
+	if ci.QoSLevel != consts.PodAnnotationQoSLevelReclaimedCores {
+		return false
+	}
+	for regionName := range ci.RegionNames {
+		if regionInfo, ok := cs.metaCache.GetRegionInfo(regionName); ok && regionInfo.RegionType == types.QoSRegionTypeBatch {
+			return true
+		}
+	}
+	return false
+}
+
 func (cs *cpuServer) assembleDedicatedNUMABindingPodEntries(
 	advisorResp *types.InternalCPUCalculationResult,
 	calculationEntriesMap map[string]*cpuadvisor.CalculationEntries,
@@ -436,6 +436,38 @@ func TestAssemblePodEntries(t *testing.T) {
 	require.Equal(t, 2, len(calcResult), "reclaimed pool container is ignored")
 }
 
+func TestAssembleBatchPodEntries(t *testing.T) {
+	t.Parallel()
+
+	cs := newTestCPUServer(t, nil, nil)
+	require.NoError(t, cs.metaCache.SetRegionInfo("batch-t", &types.RegionInfo{
+		RegionName: "batch-t",
+		RegionType: types.QoSRegionTypeBatch,
+	}))
+	ci := &types.ContainerInfo{
+		OwnerPoolName:       commonstate.PoolNameReclaim,
+		OriginOwnerPoolName: commonstate.PoolNameReclaim,
+		QoSLevel:            consts.PodAnnotationQoSLevelReclaimedCores,
+		RegionNames:         sets.NewString("batch-t"),
+	}
+
+	calcResult := map[string]*cpuadvisor.CalculationEntries{commonstate.PoolNameReclaim: {}}
+	require.NoError(t, cs.assembleNormalPodEntries(calcResult, "11", ci))
+	require.Equal(t, commonstate.PoolNameReclaim, calcResult["11"].Entries[""].OwnerPoolName,
+		"batch container should stay in reclaim pool without batch pool")
+
+	calcResult = map[string]*cpuadvisor.CalculationEntries{commonstate.PoolNameReclaim: {}, commonstate.PoolNameBatch: {}}
+	require.NoError(t, cs.assembleNormalPodEntries(calcResult, "11", ci))
+	require.Equal(t, commonstate.PoolNameBatch, calcResult["11"].Entries[""].OwnerPoolName,
+		"batch container should be put into batch pool")
+
+	ci.RegionNames = sets.NewString()
+	calcResult = map[string]*cpuadvisor.CalculationEntries{commonstate.PoolNameReclaim: {}, commonstate.PoolNameBatch: {}}
+	require.NoError(t, cs.assembleNormalPodEntries(calcResult, "11", ci))
+	require.Equal(t, commonstate.PoolNameReclaim, calcResult["11"].Entries[""].OwnerPoolName,
+		"best-effort reclaimed container should stay in reclaim pool")
+}
+
 func TestConcurrencyGetCheckpointAndAddContainer(t *testing.T) {
 	t.Parallel()
 
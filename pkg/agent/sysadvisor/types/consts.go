@@ -35,6 +35,7 @@ const (
 
 	MinShareCPURequirement     = 4
 	MinDedicatedCPURequirement = 4
+	MinBatchCPURequirement     = 1
 
 	MaxRampUpStep   = 10
 	MaxRampDownStep = 2
@@ -43,6 +43,8 @@ const (
 	CPUProvisionPolicyCanonical    CPUProvisionPolicyName = "canonical"
 	CPUProvisionPolicyRama         CPUProvisionPolicyName = "rama"
 	CPUProvisionPolicyDynamicQuota CPUProvisionPolicyName = "dynamic-quota"
+	CPUProvisionPolicyMPC          CPUProvisionPolicyName = "mpc"
+	CPUProvisionPolicyThroughput   CPUProvisionPolicyName = "throughput"
 )
 
 // CPUHeadroomPolicyName defines policy names for cpu advisor headroom estimation
@@ -57,6 +59,24 @@ const (
 	CPUHeadroomPolicyNUMAExclusive CPUHeadroomPolicyName = "numa-exclusive"
 )
 
+const (
+	// QoSRegionTypeBatch is for reclaimed_cores pods with throughput slo (e.g. jobs per minute in spd),
+	// whose cpu requirement is carved out of reclaimed resources as a separate batch pool, so that
+	// batch pods don't compete with best-effort reclaimed_cores pods in reclaim pool
+	QoSRegionTypeBatch v1alpha1.QoSRegionType = "batch"
+
+	// ControlKnobReclaimedBatchCPURequirement is the cpu requirement of batch region to meet throughput slo
+	ControlKnobReclaimedBatchCPURequirement v1alpha1.ControlKnobName = "reclaimed-batch-cpu-requirement"
+)
+
+// CPURegulatorName defines regulators for cpu advisor to restrict raw cpu requirement from provision policy
+type CPURegulatorName string
+
+const (
+	CPURegulatorDefault  CPURegulatorName = "default"
+	CPURegulatorAdaptive CPURegulatorName = "adaptive"
+)
+
 // CPUProvisionAssemblerName defines assemblers for cpu advisor to generate node
 // provision result from region control knobs
 type CPUProvisionAssemblerName string
@@ -173,11 +193,31 @@ type RegionInfo struct {
 
 type HeadroomEntries map[string]*HeadroomInfo
 
+// RegulatorInfo records the regulation history of a control knob,
+// which is used to warm start the regulator after restart
+type RegulatorInfo struct {
+	LatestValue        float64 `json:"latest_value"`
+	LatestRampDownTime int64   `json:"latest_ramp_down_time"` // unix nano
+}
+
+// RegulatorEntries stores regulator info keyed by region name, provision policy and control knob
+type RegulatorEntries map[string]*RegulatorInfo
+
 type HeadroomInfo struct {
 	NUMAHeadroom  map[int]float64 `json:"numa_headroom"`
 	TotalHeadroom float64         `json:"total_headroom"`
 }
 
+// HeadroomRecord is a headroom sample updated at Timestamp (unix nano)
+type HeadroomRecord struct {
+	HeadroomInfo
+	Timestamp int64 `json:"timestamp"`
+}
+
+// HeadroomHistory stores headroom records within a time window keyed by resource name,
+// which is used to warm start headroom reporting after restart
+type HeadroomHistory map[string][]HeadroomRecord
+
 // InternalCPUCalculationResult conveys minimal information to cpu server for composing
 // calculation result
 type InternalCPUCalculationResult struct {
@@ -223,3 +263,22 @@ type FirstOrderPIDParams struct {
 	DeadbandUpperPct     float64
 	DeadbandLowerPct     float64
 }
+
+// MPCIndicatorParams holds the model of a service indicator in mpc policy, where the indicator
+// is assumed to converge to steady state of y ~ cpu^Elasticity as a first order lag
+type MPCIndicatorParams struct {
+	// Weight is the cost weight of the indicator violating its target
+	Weight float64
+	// Elasticity is negative for indicators improved by more cpu (e.g. latency, error rate),
+	// whose target is the upper bound; and positive for those growing with cpu under saturated
+	// load (e.g. throughput of offline jobs), whose target is the lower bound
+	Elasticity float64
+	// Smoothing is the share of the gap to steady state closed in one period, within (0, 1]
+	Smoothing float64
+}
+
+// MPCRegionConstraint holds the cpu bounds of regions in mpc policy, on top of region resource bounds
+type MPCRegionConstraint struct {
+	MinCPU float64
+	MaxCPU float64
+}
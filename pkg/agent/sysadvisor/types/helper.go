@@ -211,6 +211,30 @@ func (re RegionEntries) Clone() RegionEntries {
 	return clone
 }
 
+func (ri *RegulatorInfo) Clone() *RegulatorInfo {
+	if ri == nil {
+		return nil
+	}
+	clone := *ri
+	return &clone
+}
+
+func (re RegulatorEntries) Clone() RegulatorEntries {
+	if re == nil {
+		return nil
+	}
+	clone := make(RegulatorEntries)
+	for key, regulatorInfo := range re {
+		clone[key] = regulatorInfo.Clone()
+	}
+	return clone
+}
+
+// GetRegulatorKey returns the key of regulator entries
+func GetRegulatorKey(regionName string, policyName CPUProvisionPolicyName, controlKnobName string) string {
+	return fmt.Sprintf("%s/%s/%s", regionName, policyName, controlKnobName)
+}
+
 func (rs RegionStatus) Clone() RegionStatus {
 	clone := RegionStatus{
 		OvershootStatus: make(map[string]OvershootType),
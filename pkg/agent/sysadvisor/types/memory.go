@@ -87,3 +87,11 @@ type NumaMemoryBalanceAdvice struct {
 	// if the successful migrated memory ratio is over this threshold, this turn can be considered successful.
 	Threshold float64 `json:"threshold"`
 }
+
+// NUMAMemoryMigrationAdvice advises to migrate pages of a container from SourceNUMAs to DestNUMAs,
+// and at most MaxBytes of memory are migrated if it's positive
+type NUMAMemoryMigrationAdvice struct {
+	SourceNUMAs []int `json:"sourceNUMAs"`
+	DestNUMAs   []int `json:"destNUMAs"`
+	MaxBytes    int64 `json:"maxBytes,omitempty"`
+}
@@ -16,7 +16,15 @@ limitations under the License.
 
 package eviction
 
-import "github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/crd"
+import (
+	"strings"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/crd"
+)
+
+// ShadowRunAnnotationKey is the annotation of AdminQoSConfiguration to specify shadow run plugins,
+// and its value is a comma separated list with the same format as ShadowRun
+const ShadowRunAnnotationKey = "eviction.katalyst.kubewharf.io/shadow-run-plugins"
 
 type EvictionConfiguration struct {
 	// Dryrun plugins is the list of plugins to dryrun
@@ -25,6 +33,11 @@ type EvictionConfiguration struct {
 	// first item for a particular name wins
 	DryRun []string
 
+	// ShadowRun plugins is the list of plugins to run in shadow mode, results of these plugins
+	// go through the whole candidate selection, sorting and queueing, but pods are only recorded
+	// instead of being killed; it has the same format as DryRun, and dry run takes precedence.
+	ShadowRun []string
+
 	*CPUPressureEvictionConfiguration
 	*MemoryPressureEvictionConfiguration
 	*RootfsPressureEvictionConfiguration
@@ -50,6 +63,17 @@ func (c *EvictionConfiguration) ApplyConfiguration(conf *crd.DynamicConfigCRD) {
 		c.DryRun = aqc.Spec.Config.EvictionConfig.DryRun
 	}
 
+	if aqc := conf.AdminQoSConfiguration; aqc != nil {
+		if shadowRun, ok := aqc.Annotations[ShadowRunAnnotationKey]; ok {
+			c.ShadowRun = nil
+			for _, name := range strings.Split(shadowRun, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					c.ShadowRun = append(c.ShadowRun, name)
+				}
+			}
+		}
+	}
+
 	c.CPUPressureEvictionConfiguration.ApplyConfiguration(conf)
 	c.MemoryPressureEvictionConfiguration.ApplyConfiguration(conf)
 	c.RootfsPressureEvictionConfiguration.ApplyTo(conf)
@@ -36,8 +36,15 @@ const (
 	DefaultTMOPSIPolicyPSIAvg60Threshold               float64                = 0.1
 	DefaultTMORefaultPolicyReclaimAccuracyTarget       float64                = 0.99
 	DefaultTMORefaultPolicyReclaimScanEfficiencyTarget float64                = 0.6
+	DefaultTMOCompressedSwapPolicyPoolUtilizationCap   float64                = 0.9
+	DefaultTMOCompressedSwapPolicyMinCompressionRatio  float64                = 2
+	DefaultTMOCompressedSwapPolicyMaxWritebackRate     float64                = 16 * 1024 * 1024
 )
 
+// TMOPolicyNameCompressedSwap is a TMO policy which takes compressed swap pools (zswap
+// and zram) into account, and it's not defined in api since it has no dynamic configurations.
+const TMOPolicyNameCompressedSwap v1alpha1.TMOPolicyName = "CompressedSwap"
+
 type TransparentMemoryOffloadingConfiguration struct {
 	DefaultConfigurations *TMODefaultConfigurations
 	QoSLevelConfigs       map[consts.QoSLevel]*TMOConfigDetail
@@ -63,6 +70,12 @@ type TMODefaultConfigurations struct {
 	DefaultTMOPSIPolicyPSIAvg60Threshold               float64
 	DefaultTMORefaultPolicyReclaimAccuracyTarget       float64
 	DefaultTMORefaultPolicyReclaimScanEfficiencyTarget float64
+	DefaultTMOCompressedSwapPolicyPoolUtilizationCap   float64
+	DefaultTMOCompressedSwapPolicyMinCompressionRatio  float64
+	DefaultTMOCompressedSwapPolicyMaxWritebackRate     float64
+	// TMOCompressedSwapPolicyQoSLevelPoolUtilizationCaps overrides the default
+	// pool utilization cap for each qos level
+	TMOCompressedSwapPolicyQoSLevelPoolUtilizationCaps map[consts.QoSLevel]float64
 }
 
 func NewTMODefaultConfigurations() *TMODefaultConfigurations {
@@ -75,9 +88,21 @@ func NewTMODefaultConfigurations() *TMODefaultConfigurations {
 		DefaultTMOPSIPolicyPSIAvg60Threshold:               DefaultTMOPSIPolicyPSIAvg60Threshold,
 		DefaultTMORefaultPolicyReclaimAccuracyTarget:       DefaultTMORefaultPolicyReclaimAccuracyTarget,
 		DefaultTMORefaultPolicyReclaimScanEfficiencyTarget: DefaultTMORefaultPolicyReclaimScanEfficiencyTarget,
+		DefaultTMOCompressedSwapPolicyPoolUtilizationCap:   DefaultTMOCompressedSwapPolicyPoolUtilizationCap,
+		DefaultTMOCompressedSwapPolicyMinCompressionRatio:  DefaultTMOCompressedSwapPolicyMinCompressionRatio,
+		DefaultTMOCompressedSwapPolicyMaxWritebackRate:     DefaultTMOCompressedSwapPolicyMaxWritebackRate,
+		TMOCompressedSwapPolicyQoSLevelPoolUtilizationCaps: map[consts.QoSLevel]float64{},
 	}
 }
 
+// GetCompressedSwapPoolUtilizationCap returns the pool utilization cap of the qos level
+func (c *TMODefaultConfigurations) GetCompressedSwapPoolUtilizationCap(qosLevel consts.QoSLevel) float64 {
+	if poolUtilizationCap, ok := c.TMOCompressedSwapPolicyQoSLevelPoolUtilizationCaps[qosLevel]; ok {
+		return poolUtilizationCap
+	}
+	return c.DefaultTMOCompressedSwapPolicyPoolUtilizationCap
+}
+
 type TMOConfigDetail struct {
 	EnableTMO  bool
 	EnableSwap bool
@@ -85,6 +110,7 @@ type TMOConfigDetail struct {
 	PolicyName v1alpha1.TMOPolicyName
 	*PSIPolicyConf
 	*RefaultPolicyConf
+	*CompressedSwapPolicyConf
 }
 
 func NewTMOConfigDetail(defaultConfigs *TMODefaultConfigurations) *TMOConfigDetail {
@@ -102,9 +128,22 @@ func NewTMOConfigDetail(defaultConfigs *TMODefaultConfigurations) *TMOConfigDeta
 			ReclaimAccuracyTarget:       defaultConfigs.DefaultTMORefaultPolicyReclaimAccuracyTarget,
 			ReclaimScanEfficiencyTarget: defaultConfigs.DefaultTMORefaultPolicyReclaimScanEfficiencyTarget,
 		},
+		CompressedSwapPolicyConf: &CompressedSwapPolicyConf{
+			PoolUtilizationCap:  defaultConfigs.DefaultTMOCompressedSwapPolicyPoolUtilizationCap,
+			MinCompressionRatio: defaultConfigs.DefaultTMOCompressedSwapPolicyMinCompressionRatio,
+			MaxWritebackRate:    defaultConfigs.DefaultTMOCompressedSwapPolicyMaxWritebackRate,
+		},
 	}
 }
 
+// NewQoSLevelTMOConfigDetail returns default TMOConfigDetail with the
+// compressed swap pool utilization cap of the qos level
+func NewQoSLevelTMOConfigDetail(defaultConfigs *TMODefaultConfigurations, qosLevel consts.QoSLevel) *TMOConfigDetail {
+	tmoConfigDetail := NewTMOConfigDetail(defaultConfigs)
+	tmoConfigDetail.CompressedSwapPolicyConf.PoolUtilizationCap = defaultConfigs.GetCompressedSwapPoolUtilizationCap(qosLevel)
+	return tmoConfigDetail
+}
+
 type TMOBlockConfig struct {
 	LabelsSelector      labels.Selector
 	AnnotationsSelector labels.Selector
@@ -121,6 +160,18 @@ type RefaultPolicyConf struct {
 	ReclaimScanEfficiencyTarget float64
 }
 
+// CompressedSwapPolicyConf stops offloading when compressed swap pools are nearly full,
+// compress poorly or keep writing back to backing devices, to avoid swap thrashing.
+type CompressedSwapPolicyConf struct {
+	// PoolUtilizationCap is the max utilization of compressed swap pools to offload memory
+	PoolUtilizationCap float64
+	// MinCompressionRatio is the compression ratio below which offloading size is scaled down
+	MinCompressionRatio float64
+	// MaxWritebackRate is the max rate (bytes per second) of writing back to backing devices
+	// to offload memory, and non-positive value means no limit
+	MaxWritebackRate float64
+}
+
 func ApplyTMOConfigDetail(tmoConfigDetail *TMOConfigDetail, tmoConfigDetailDynamic v1alpha1.TMOConfigDetail) {
 	if tmoConfigDetailDynamic.EnableTMO != nil {
 		tmoConfigDetail.EnableTMO = *tmoConfigDetailDynamic.EnableTMO
@@ -159,7 +210,7 @@ func (c *TransparentMemoryOffloadingConfiguration) ApplyConfiguration(conf *crd.
 	if tmoConf := conf.TransparentMemoryOffloadingConfiguration; tmoConf != nil {
 		if tmoConf.Spec.Config.QoSLevelConfig != nil {
 			for _, qosLevelConfig := range tmoConf.Spec.Config.QoSLevelConfig {
-				tmoConfigDetail := NewTMOConfigDetail(c.DefaultConfigurations)
+				tmoConfigDetail := NewQoSLevelTMOConfigDetail(c.DefaultConfigurations, qosLevelConfig.QoSLevel)
 				ApplyTMOConfigDetail(tmoConfigDetail, qosLevelConfig.ConfigDetail)
 				c.QoSLevelConfigs[qosLevelConfig.QoSLevel] = tmoConfigDetail
 
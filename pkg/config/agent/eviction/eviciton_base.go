@@ -49,6 +49,19 @@ type GenericEvictionConfiguration struct {
 	// QoSPodKillers specify the pod killer implementation for different QoS levels
 	QoSPodKillers map[string]string
 
+	// ContainerRestartKillerPlugins are the eviction plugins whose results are handled
+	// by restarting the target container if container restart killer is used
+	ContainerRestartKillerPlugins []string
+	// ContainerRestartKillerMaxRestarts is the max restart times of a container in
+	// ContainerRestartKillerWindow before falling back to pod eviction
+	ContainerRestartKillerMaxRestarts int
+	ContainerRestartKillerWindow      time.Duration
+	// ContainerRestartKillerFallback is the killer used to evict the whole pod
+	ContainerRestartKillerFallback string
+	// ContainerRestartKillerCheckpointDir is the directory where container restart killer
+	// checkpoints restart times of containers, so that they survive agent restarts
+	ContainerRestartKillerCheckpointDir string
+
 	// StrictAuthentication means whether to authenticate plugins strictly
 	StrictAuthentication bool
 
@@ -58,6 +71,25 @@ type GenericEvictionConfiguration struct {
 	// RecordManager specifies the eviction record manager to use
 	RecordManager string
 
+	// EvictionRecordDir is the directory where the local eviction record manager persists records
+	EvictionRecordDir string
+	// EvictionRecordBucketDuration is the time span aggregated into one eviction bucket of a workload
+	EvictionRecordBucketDuration time.Duration
+	// EvictionRecordRetention is how long eviction buckets of a workload are kept
+	EvictionRecordRetention time.Duration
+
+	// NodeEvictionQPS and NodeEvictionQPSBurst limit the eviction rate of the whole node
+	// by a token bucket, and non-positive qps disables the limit
+	NodeEvictionQPS      float64
+	NodeEvictionQPSBurst int
+	// WorkloadEvictionQPS and WorkloadEvictionQPSBurst limit the eviction rate of pods
+	// belonging to the same workload, and non-positive qps disables the limit
+	WorkloadEvictionQPS      float64
+	WorkloadEvictionQPSBurst int
+	// EvictionRespectPDB means pods are kept in eviction queue if the disruption budget
+	// of their PodDisruptionBudget is exhausted
+	EvictionRespectPDB bool
+
 	// HostPathNotifierRootPath
 	HostPathNotifierRootPath string
 }
@@ -66,6 +98,7 @@ type EvictionConfiguration struct {
 	*ReclaimedResourcesEvictionConfiguration
 	*MemoryPressureEvictionConfiguration
 	*CPUPressureEvictionConfiguration
+	*PSIPressureEvictionConfiguration
 }
 
 func NewGenericEvictionConfiguration() *GenericEvictionConfiguration {
@@ -81,5 +114,6 @@ func NewEvictionConfiguration() *EvictionConfiguration {
 		ReclaimedResourcesEvictionConfiguration: NewReclaimedResourcesEvictionConfiguration(),
 		MemoryPressureEvictionConfiguration:     NewMemoryPressureEvictionPluginConfiguration(),
 		CPUPressureEvictionConfiguration:        NewCPUPressureEvictionConfiguration(),
+		PSIPressureEvictionConfiguration:        NewPSIPressureEvictionConfiguration(),
 	}
 }
@@ -0,0 +1,52 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eviction
+
+import (
+	"time"
+)
+
+// PSIThresholds are the thresholds of pressure stall information in percentage,
+// and zero value means the corresponding threshold is disabled
+type PSIThresholds struct {
+	SomeAvg10 float64
+	SomeAvg60 float64
+	FullAvg10 float64
+	FullAvg60 float64
+}
+
+// PSIPressureEvictionConfiguration is the config of PSI based io and cpu pressure eviction
+type PSIPressureEvictionConfiguration struct {
+	EnableIOPressureEviction  bool
+	IOPressureThresholds      PSIThresholds
+	EnableCPUPressureEviction bool
+	CPUPressureThresholds     PSIThresholds
+
+	// PSIPressureSyncPeriod is the interval to collect node and pod level pressure
+	PSIPressureSyncPeriod time.Duration
+	// PSIPressureGraceWindow is the duration that thresholds must keep being met before evicting pods
+	PSIPressureGraceWindow time.Duration
+	// PSIPressureCandidateQoSLevels are the qos levels of pods that can be evicted
+	PSIPressureCandidateQoSLevels []string
+	// PSIPressureEvictionGracePeriod is the grace period in seconds of pod deletion
+	PSIPressureEvictionGracePeriod int64
+}
+
+// NewPSIPressureEvictionConfiguration returns a new PSIPressureEvictionConfiguration
+func NewPSIPressureEvictionConfiguration() *PSIPressureEvictionConfiguration {
+	return &PSIPressureEvictionConfiguration{}
+}
@@ -16,9 +16,31 @@ limitations under the License.
 
 package global
 
+import "time"
+
 type AuditConfiguration struct {
 	Sinks      []string
 	BufferSize int
+
+	FileAuditSinkConfiguration
+
+	// QueryAddress is the local address to serve audit history queries,
+	// and the query server is disabled if it is empty.
+	QueryAddress string
+}
+
+// FileAuditSinkConfiguration is the configuration for file-based audit sink
+type FileAuditSinkConfiguration struct {
+	// Dir is the directory to store audit files
+	Dir string
+	// MaxFileSizeMB is the max size of one audit file before it gets rotated
+	MaxFileSizeMB int
+	// MaxFileAge is the max duration of one audit file before it gets rotated
+	MaxFileAge time.Duration
+	// MaxBackups is the max number of rotated audit files to retain
+	MaxBackups int
+	// RetentionPeriod is the max duration to retain rotated audit files
+	RetentionPeriod time.Duration
 }
 
 func NewAuditConfiguration() *AuditConfiguration {
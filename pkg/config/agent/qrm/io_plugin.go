@@ -23,6 +23,7 @@ type IOQRMPluginConfig struct {
 	WritebackThrottlingOption
 	IOCostOption
 	IOWeightOption
+	IOThrottleOption
 }
 
 type WritebackThrottlingOption struct {
@@ -46,6 +47,15 @@ type IOWeightOption struct {
 	IOWeightCgroupLevelConfigFile string
 }
 
+type IOThrottleOption struct {
+	EnableIOThrottle bool
+	// IOThrottleDeviceConfigFile is the json file mapping block device names to their capacities
+	IOThrottleDeviceConfigFile string
+	// IOThrottleDefaultDevice is the device to throttle if pod doesn't specify one
+	IOThrottleDefaultDevice string
+	SkipIOStateCorruption   bool
+}
+
 func NewIOQRMPluginConfig() *IOQRMPluginConfig {
 	return &IOQRMPluginConfig{}
 }
@@ -23,6 +23,15 @@ import (
 // InferencePluginConfiguration stores configurations of inference plugin
 type InferencePluginConfiguration struct {
 	SyncPeriod time.Duration
+
+	// ResultTTL is the ttl of inference results persisted into metacache checkpoint,
+	// and results are not persisted if it's not positive
+	ResultTTL time.Duration
+
+	// EnableLocalModelResultFetcher enables evaluating model artifacts in LocalModelDirectory
+	// in-process, and artifacts are reloaded once they are changed
+	EnableLocalModelResultFetcher bool
+	LocalModelDirectory           string
 }
 
 // NewInferencePluginConfiguration creates a new inference plugin configuration
@@ -21,6 +21,17 @@ const (
 	DVFSIndicationCPUFreq = "cpufreq"
 )
 
+const (
+	PowerReaderMetricStore = "metric-store"
+	PowerReaderRAPL        = "rapl"
+
+	PowerCapperServer = "server"
+	PowerCapperLocal  = "local"
+
+	LocalCapperMethodPowercap = "powercap"
+	LocalCapperMethodCPUFreq  = "cpufreq"
+)
+
 type PowerAwarePluginConfiguration struct {
 	DryRun                           bool
 	DisablePowerCapping              bool
@@ -28,6 +39,16 @@ type PowerAwarePluginConfiguration struct {
 	PowerCappingAdvisorSocketAbsPath string
 	AnnotationKeyPrefix              string
 	DVFSIndication                   string
+
+	// PowerReader is the source of node power usage, either metric-store (malachite) or rapl
+	PowerReader string
+	// PowerCapper is where the capping is carried out, either by the external capper
+	// connected to the capping server, or locally by sys-advisor itself
+	PowerCapper string
+	// LocalCapperMethod is how the local capper throttles: powercap limits or cpufreq scaling_max_freq
+	LocalCapperMethod string
+	PowercapRoot      string
+	CPUFreqRoot       string
 }
 
 // NewPowerAwarePluginConfiguration creates a default config
@@ -17,6 +17,8 @@ limitations under the License.
 package cpu
 
 import (
+	"time"
+
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -51,6 +53,37 @@ type CPUIsolationConfiguration struct {
 	// IsolationIncludeSidecarRequirement indicates whether to include sidecar requirements
 	// when calculating CPU isolation
 	IsolationIncludeSidecarRequirement bool
+
+	// InterferenceIsolation configures the isolator by interference signals, which works along with
+	// the load isolator to catch noisy neighbours without high cpu load (e.g. cache thrashers)
+	InterferenceIsolation InterferenceIsolationConfiguration
+}
+
+type InterferenceIsolationConfiguration struct {
+	Enabled bool
+
+	// ScoreThreshold is the interference score for a pod to be regarded as an offender
+	ScoreThreshold float64
+
+	// weights of interference signals in score, where memory bandwidth and llc misses are the share
+	// of pod in its pool, and cpi degradation and throttling are the pressure on its neighbours
+	MemBandwidthWeight   float64
+	LLCMissWeight        float64
+	CPIDegradationWeight float64
+	ThrottleWeight       float64
+
+	// NeighbourPressureThreshold is the minimum weighted pressure of cpi degradation and throttling on
+	// neighbours for a pod to be scored, since occupying shared resources alone doesn't hurt anyone
+	NeighbourPressureThreshold float64
+
+	// LockInDuration is how long an offender lasts before isolated, and
+	// LockOutDuration is how long an isolated pod stays quiet before released
+	LockInDuration  time.Duration
+	LockOutDuration time.Duration
+
+	// MaxIsolatedPods caps isolated pods in each pool, and MaxPoolIsolatedPods overrides it by pool
+	MaxIsolatedPods     int
+	MaxPoolIsolatedPods map[string]int
 }
 
 // NewCPUIsolationConfiguration creates new resource advisor configurations
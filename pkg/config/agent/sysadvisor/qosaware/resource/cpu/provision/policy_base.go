@@ -22,6 +22,8 @@ type CPUProvisionPolicyConfiguration struct {
 	CPURegulatorConfiguration
 	// PolicyRama is the configuration for policy rama
 	PolicyRama *PolicyRamaConfiguration
+	// PolicyMPC is the configuration for policy mpc
+	PolicyMPC *PolicyMPCConfiguration
 	// enable to use control knob cpu quota when cgroup2 available
 	EnableControlKnobCPUQuota bool
 }
@@ -29,6 +31,7 @@ type CPUProvisionPolicyConfiguration struct {
 func NewCPUProvisionPolicyConfiguration() *CPUProvisionPolicyConfiguration {
 	return &CPUProvisionPolicyConfiguration{
 		PolicyRama: NewPolicyRamaConfiguration(),
+		PolicyMPC:  NewPolicyMPCConfiguration(),
 	}
 }
 
@@ -41,4 +44,28 @@ type CPURegulatorConfiguration struct {
 
 	// MinRampDownPeriod is the min time gap between two consecutive cpu requirement ramp down
 	MinRampDownPeriod time.Duration
+
+	// RegulatorNames selects the regulator by region type, and the default regulator is used if not set
+	RegulatorNames map[string]string
+
+	// AdaptiveRegulator is the configuration for adaptive regulator
+	AdaptiveRegulator AdaptiveRegulatorConfiguration
+}
+
+type AdaptiveRegulatorConfiguration struct {
+	// HysteresisUpBand and HysteresisDownBand are the cpu cores a requirement must exceed
+	// above or below the current value before it is followed
+	HysteresisUpBand   float64
+	HysteresisDownBand float64
+
+	// SmoothingFactor is the weight of the latest raw requirement in exponential smoothing, within (0, 1]
+	SmoothingFactor float64
+
+	// OscillationWindow is the number of latest regulations checked for oscillation, and the requirement
+	// is regarded as oscillating if its change direction flips at least OscillationThreshold times within
+	OscillationWindow    int
+	OscillationThreshold int
+
+	// DampingFactor scales down smoothing factor and widens hysteresis bands while oscillating, within (0, 1]
+	DampingFactor float64
 }
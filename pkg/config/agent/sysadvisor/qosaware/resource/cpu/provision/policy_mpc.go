@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provision
+
+import (
+	"github.com/kubewharf/katalyst-api/pkg/apis/workload/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+const (
+	MPCIndicatorErrorRate = "ErrorRate"
+)
+
+type PolicyMPCConfiguration struct {
+	// Horizon is the number of periods predicted by the controller
+	Horizon int
+	// CPUCostWeight is the cost weight of cpu normalized by region upper bound
+	CPUCostWeight float64
+	// MoveCostWeight is the cost weight of cpu change between two periods
+	MoveCostWeight float64
+	// Resolution is the granularity of candidate cpu requirements
+	Resolution      float64
+	MaxRampUpStep   float64
+	MaxRampDownStep float64
+	// IndicatorParams is keyed by spd business indicator name, and indicators without params are ignored;
+	// traffic-driven indicators (e.g. qps) are not configured by default, since they drop along with load
+	// rather than with cpu and would drive cpu up when traffic falls
+	IndicatorParams map[string]types.MPCIndicatorParams
+	// RegionConstraints is keyed by region owner pool name
+	RegionConstraints map[string]types.MPCRegionConstraint
+}
+
+func NewPolicyMPCConfiguration() *PolicyMPCConfiguration {
+	return &PolicyMPCConfiguration{
+		Horizon:         5,
+		CPUCostWeight:   0.1,
+		MoveCostWeight:  0.5,
+		Resolution:      0.5,
+		MaxRampUpStep:   types.MaxRampUpStep,
+		MaxRampDownStep: types.MaxRampDownStep,
+		IndicatorParams: map[string]types.MPCIndicatorParams{
+			string(v1alpha1.ServiceBusinessIndicatorNameRPCLatency): {
+				Weight:     1.0,
+				Elasticity: -1.0,
+				Smoothing:  0.5,
+			},
+			MPCIndicatorErrorRate: {
+				Weight:     1.0,
+				Elasticity: -1.0,
+				Smoothing:  0.5,
+			},
+		},
+		RegionConstraints: map[string]types.MPCRegionConstraint{},
+	}
+}
@@ -0,0 +1,35 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package region
+
+// CPUBatchConfiguration stores configurations of cpu batch region
+type CPUBatchConfiguration struct {
+	// ThroughputIndicators are spd business indicators regarded as throughput slo, and reclaimed_cores
+	// pods with targets of any of them are assigned to batch region; batch region is disabled if it's empty
+	ThroughputIndicators []string
+	// MaxThroughputScaleRatio restricts the ratio between cpu requirement and cpu usage of batch region,
+	// to avoid a burst of cpu requirement when throughput is far below its target
+	MaxThroughputScaleRatio float64
+}
+
+// NewCPUBatchConfiguration creates new batch region configurations
+func NewCPUBatchConfiguration() *CPUBatchConfiguration {
+	return &CPUBatchConfiguration{
+		ThroughputIndicators:    []string{},
+		MaxThroughputScaleRatio: 2,
+	}
+}
@@ -20,6 +20,7 @@ import "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
 
 type CPURegionConfiguration struct {
 	*CPUShareConfiguration
+	*CPUBatchConfiguration
 
 	RestrictRefPolicy map[types.CPUProvisionPolicyName]types.CPUProvisionPolicyName
 }
@@ -27,6 +28,7 @@ type CPURegionConfiguration struct {
 func NewCPURegionConfiguration() *CPURegionConfiguration {
 	return &CPURegionConfiguration{
 		CPUShareConfiguration: NewCPUShareConfiguration(),
+		CPUBatchConfiguration: NewCPUBatchConfiguration(),
 
 		RestrictRefPolicy: make(map[types.CPUProvisionPolicyName]types.CPUProvisionPolicyName),
 	}
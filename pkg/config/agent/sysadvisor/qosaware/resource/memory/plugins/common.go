@@ -20,12 +20,16 @@ type MemoryAdvisorPluginsConfiguration struct {
 	*CacheReaperConfiguration
 	*MemoryProvisionerConfiguration
 	*NumaBalancerConfiguration
+	*MemoryThrottlerConfiguration
+	*NUMAMemoryMigratorConfiguration
 }
 
 func NewMemoryAdvisorPluginsConfiguration() *MemoryAdvisorPluginsConfiguration {
 	return &MemoryAdvisorPluginsConfiguration{
-		CacheReaperConfiguration:       NewCacheReaperConfiguration(),
-		MemoryProvisionerConfiguration: NewMemoryProvisionerConfiguration(),
-		NumaBalancerConfiguration:      NewNumaBalancerConfiguration(),
+		CacheReaperConfiguration:        NewCacheReaperConfiguration(),
+		MemoryProvisionerConfiguration:  NewMemoryProvisionerConfiguration(),
+		NumaBalancerConfiguration:       NewNumaBalancerConfiguration(),
+		MemoryThrottlerConfiguration:    NewMemoryThrottlerConfiguration(),
+		NUMAMemoryMigratorConfiguration: NewNUMAMemoryMigratorConfiguration(),
 	}
 }
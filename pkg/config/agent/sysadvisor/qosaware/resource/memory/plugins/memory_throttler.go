@@ -0,0 +1,46 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+type MemoryThrottlerConfiguration struct {
+	// PSIAvg60Threshold is the threshold of node memory psi some avg60 to start throttling
+	PSIAvg60Threshold float64
+	// NUMAPSIAvg60Threshold is the threshold of NUMA memory psi some avg60 to start throttling
+	// containers on that NUMA, and NUMA memory psi is the max psi of non-throttled containers on it
+	NUMAPSIAvg60Threshold float64
+	// ThrottleStepRatio is the ratio that memory.high decreases by in each round under pressure
+	ThrottleStepRatio float64
+	// RelaxStepRatio is the ratio of usage before throttling that memory.high increases by
+	// in each round without pressure, and throttling is removed once it's back to that usage
+	RelaxStepRatio float64
+	// MinHighRatio limits memory.high above this ratio of usage before throttling
+	MinHighRatio float64
+	// ThrottledSharedPools are low-priority shared pools whose containers can be throttled
+	// along with reclaimed containers
+	ThrottledSharedPools []string
+}
+
+func NewMemoryThrottlerConfiguration() *MemoryThrottlerConfiguration {
+	return &MemoryThrottlerConfiguration{
+		PSIAvg60Threshold:     10,
+		NUMAPSIAvg60Threshold: 10,
+		ThrottleStepRatio:     0.05,
+		RelaxStepRatio:        0.1,
+		MinHighRatio:          0.5,
+		ThrottledSharedPools:  []string{},
+	}
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import "time"
+
+type NUMAMemoryMigratorConfiguration struct {
+	// RemoteMemoryRatioThreshold is the ratio of memory resided on NUMA nodes outside of
+	// container cpuset, above which pages of the container should be migrated
+	RemoteMemoryRatioThreshold float64
+	// MaxMigrateBytesPerRound limits the total remote memory of containers to migrate in each round,
+	// and containers with more remote memory than the budget left are migrated partially
+	MaxMigrateBytesPerRound int64
+	// MaxMigrateContainersPerRound limits the number of containers to migrate in each round
+	MaxMigrateContainersPerRound int
+	// ContainerMigrateInterval is the minimum interval to migrate pages of the same container
+	ContainerMigrateInterval time.Duration
+	// BandwidthPressureThreshold is the ratio of memory bandwidth to theoretical bandwidth of a NUMA
+	// node, above which migration from or to this NUMA node is paused
+	BandwidthPressureThreshold float64
+}
+
+func NewNUMAMemoryMigratorConfiguration() *NUMAMemoryMigratorConfiguration {
+	return &NUMAMemoryMigratorConfiguration{}
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package sysadvisor
 
 import (
+	"time"
+
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/inference"
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/metacache"
 	metricemitter "github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/metric-emitter"
@@ -32,6 +34,10 @@ type GenericSysAdvisorConfiguration struct {
 	ClearStateFileDirectory     bool
 	EnableShareCoresNumaBinding bool
 	SkipStateCorruption         bool
+
+	// StateWarmStartTTL is the max age of entries restored from state file to warm start advisors,
+	// and warm start is disabled if it's not positive
+	StateWarmStartTTL time.Duration
 }
 
 // NewGenericSysAdvisorConfiguration creates a new generic sysadvisor plugin configuration.
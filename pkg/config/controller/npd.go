@@ -16,18 +16,60 @@ limitations under the License.
 
 package controller
 
+import (
+	"time"
+
+	"github.com/kubewharf/katalyst-core/pkg/util/datasource/prometheus"
+)
+
 type NPDConfig struct {
 	NPDMetricsPlugins []string
 
 	EnableScopeDuplicated bool
 
 	SyncWorkers int
+
+	*LoadAwareMetricsPluginConfig
+}
+
+// LoadAwareMetricsPluginConfig holds the configurations for load aware metrics plugin,
+// which aggregates node and pod usage into load profiles of npd.
+type LoadAwareMetricsPluginConfig struct {
+	// available datasource: custom-metric, prom
+	DataSource string
+	// DataSourcePromConfig is the prometheus datasource config
+	DataSourcePromConfig prometheus.PromConfig
+
+	// SyncPeriod is the period to refresh load profiles
+	SyncPeriod time.Duration
+	// NodeMetricsScope and PodMetricsScope are scopes of load profiles in npd
+	NodeMetricsScope string
+	PodMetricsScope  string
+
+	// Windows are time windows that usage is aggregated over, and Step is the
+	// resolution of usage samples in each window
+	Windows     []time.Duration
+	Step        time.Duration
+	Aggregators []string
+
+	// NodeMetricQueries and PodMetricQueries map metric names in npd to promql of usage,
+	// and the results should be grouped by NodeLabel, or by NamespaceLabel and PodLabel.
+	NodeMetricQueries map[string]string
+	PodMetricQueries  map[string]string
+	// NodeCustomMetrics and PodCustomMetrics map metric names in npd to names of
+	// custom metrics, which are used for custom-metric datasource instead of promql.
+	NodeCustomMetrics map[string]string
+	PodCustomMetrics  map[string]string
+	NodeLabel         string
+	NamespaceLabel    string
+	PodLabel          string
 }
 
 func NewNPDConfig() *NPDConfig {
 	return &NPDConfig{
-		NPDMetricsPlugins:     []string{},
-		EnableScopeDuplicated: false,
-		SyncWorkers:           1,
+		NPDMetricsPlugins:            []string{},
+		EnableScopeDuplicated:        false,
+		SyncWorkers:                  1,
+		LoadAwareMetricsPluginConfig: &LoadAwareMetricsPluginConfig{},
 	}
 }
@@ -28,10 +28,14 @@ type ResourceRecommenderConfig struct {
 	HealthProbeBindPort string
 	MetricsBindPort     string
 
-	// available datasource: prom
+	// available datasource: prom, CustomMetric, LocalFile
 	DataSource []string
 	// DataSourcePromConfig is the prometheus datasource config
 	DataSourcePromConfig prometheus.PromConfig
+	// DataSourceCustomMetricConfig is the katalyst custom metric store datasource config
+	DataSourceCustomMetricConfig CustomMetricDatasourceConfig
+	// DataSourceLocalFileConfig is the local snapshot file datasource config
+	DataSourceLocalFileConfig LocalFileDatasourceConfig
 
 	// LogVerbosityLevel to specify log verbosity level. (The default level is 4)
 	// Set it to something larger than 4 if more detailed logs are needed.
@@ -42,6 +46,20 @@ type ResourceRecommenderConfig struct {
 	RecSyncPeriod  time.Duration
 }
 
+type CustomMetricDatasourceConfig struct {
+	// Address is the address of katalyst custom metric store server
+	Address string
+	Timeout time.Duration
+	// CPUMetricName and MemoryMetricName are the names of pod metrics in store
+	CPUMetricName    string
+	MemoryMetricName string
+}
+
+type LocalFileDatasourceConfig struct {
+	// Path is the path of the json snapshot file, which is used for offline replays
+	Path string
+}
+
 func NewResourceRecommenderConfig() *ResourceRecommenderConfig {
 	return &ResourceRecommenderConfig{}
 }
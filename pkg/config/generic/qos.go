@@ -347,6 +347,19 @@ func (c *QoSConfiguration) GetQoSEnhancementKVs(pod *v1.Pod, expandedAnnotations
 	return flattenedEnhancements
 }
 
+// CheckQoSEnhancementsForPod returns error if any enhancement annotation of the pod,
+// either by default key or by expanded key, can't be parsed into key-value pairs.
+func (c *QoSConfiguration) CheckQoSEnhancementsForPod(pod *v1.Pod) error {
+	annotations := c.getQoSEnhancements(MergeAnnotations(pod, map[string]string{}))
+	for enhancementKey, enhancementValue := range annotations {
+		flattenedEnhancements := map[string]string{}
+		if err := json.Unmarshal([]byte(enhancementValue), &flattenedEnhancements); err != nil {
+			return fmt.Errorf("parse enhancement %s failed: %v", enhancementKey, err)
+		}
+	}
+	return nil
+}
+
 // GetQoSEnhancements returns the standard katalyst QoS Enhancement Map for given annotations;
 // - ignore conflict cases: default enhancement key always prior to expand enhancement key
 func (c *QoSConfiguration) getQoSEnhancements(annotations map[string]string) map[string]string {
@@ -40,6 +40,7 @@ const (
 	EventReasonEvictCreated             = "EvictCreated"
 	EventReasonEvictExceededGracePeriod = "EvictExceededGracePeriod"
 	EventReasonEvictSucceeded           = "EvictSucceeded"
+	EventReasonEvictShadowed            = "EvictShadowed"
 
 	EventReasonNotifyFailed  = "NotifyFailed"
 	EventReasonNotifySuccess = "NotifySuccess"
@@ -98,6 +99,9 @@ const (
 	TopicNameApplyProcFS = "ApplyProcFS"
 	TopicNameApplySysFS  = "ApplySysFS"
 	TopicNameSyscall     = "Syscall"
+
+	TopicNameQRMAllocation = "QRMAllocation"
+	TopicNameEviction      = "Eviction"
 )
 
 const (
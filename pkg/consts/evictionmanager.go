@@ -21,10 +21,25 @@ const (
 	KillerNameEvictionKiller  = "eviction-api-killer"
 	KillerNameDeletionKiller  = "deletion-api-killer"
 	KillerNameContainerKiller = "container-killer"
+	KillerNameShadowKiller    = "shadow-killer"
+
+	KillerNameContainerRestartKiller = "container-restart-killer"
 
 	NotifierNameHostPath = "host-path-notifier"
 )
 
+const (
+	// PodAnnotationEvictionTargetContainerKey is set by eviction plugins on the pod in eviction
+	// results (it's never persisted) to specify the container causing the eviction
+	PodAnnotationEvictionTargetContainerKey = "katalyst.kubewharf.io/eviction_target_container"
+	// PodAnnotationEvictionRemoveTargetContainerKey is set by eviction plugins along with the target
+	// container if the stopped container should be removed, e.g. to free its writable layer
+	PodAnnotationEvictionRemoveTargetContainerKey = "katalyst.kubewharf.io/eviction_remove_target_container"
+	// PodAnnotationContainerRestartReasonKey records the reason why a container is restarted by
+	// container restart killer
+	PodAnnotationContainerRestartReasonKey = "katalyst.kubewharf.io/container_restart_reason"
+)
+
 const (
 	// EvictionPluginThresholdMetRPCTimeoutInSecs is timeout duration in secs for ThresholdMet RPC
 	EvictionPluginThresholdMetRPCTimeoutInSecs = 10
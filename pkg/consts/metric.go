@@ -259,6 +259,9 @@ const (
 	MetricCPUNrPeriodRateContainer      = MetricCPUNrPeriodContainer + Rate
 	MetricCPUThrottledTimeRateContainer = MetricCPUThrottledTimeContainer + Rate
 
+	MetricCPUPsiAvg10Container = "cpu.psiavg10.container"
+	MetricCPUPsiAvg60Container = "cpu.psiavg60.container"
+
 	MetricCPUUpdateTimeContainer = "cpu.updatetime.container"
 )
 
@@ -339,6 +342,9 @@ const (
 	MetricBlkioReadBpsContainer   = "blkio.read.bps.container"
 	MetricBlkioWriteBpsContainer  = "blkio.write.bps.container"
 
+	MetricBlkioPsiAvg10Container = "blkio.psiavg10.container"
+	MetricBlkioPsiAvg60Container = "blkio.psiavg60.container"
+
 	MetricBlkioUpdateTimeContainer = "blkio.updatetime.container"
 )
 
@@ -0,0 +1,32 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consts
+
+// NPDScopeLoadAware is the npd scope of node and pod load profiles, which are
+// aggregated from usage metrics over time windows (e.g. avg or p95 in last 15m).
+const (
+	NPDScopeLoadAware = "loadAware"
+)
+
+// const variables for metric names of load profiles in npd, and cpu usage is in
+// cores, memory usage is in bytes while network usage is in bytes per second.
+const (
+	NPDMetricNameCPUUsage             = "cpu_usage"
+	NPDMetricNameMemoryUsage          = "memory_usage"
+	NPDMetricNameNetworkReceiveBytes  = "network_receive_bytes"
+	NPDMetricNameNetworkTransmitBytes = "network_transmit_bytes"
+)
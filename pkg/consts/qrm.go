@@ -27,3 +27,15 @@ const (
 	// QRMResourceAnnotationKeyNUMABindResult is the annotation key for the numa binding result
 	QRMResourceAnnotationKeyNUMABindResult = "qrm.katalyst.kubewharf.io/numa_bind_result"
 )
+
+const (
+	// ResourceDiskBandwidth is the disk bandwidth (in bytes per second) allocated by io qrm plugin
+	ResourceDiskBandwidth = "resource.katalyst.kubewharf.io/disk_bandwidth"
+	// ResourceDiskIOPS is the disk iops allocated by io qrm plugin, and it's reported along with
+	// ResourceDiskBandwidth as an accompanying resource
+	ResourceDiskIOPS = "resource.katalyst.kubewharf.io/disk_iops"
+
+	// PodAnnotationIOThrottleKey is the pod annotation key to declare the io throttling requirements,
+	// and its value is json formatted, e.g. {"device": "sda", "read_bps": 104857600, "write_iops": 1000}
+	PodAnnotationIOThrottleKey = "katalyst.kubewharf.io/io_throttle"
+)
@@ -0,0 +1,26 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consts
+
+const (
+	// PodGroupLabelKey is the label of pods belonging to the same pod group, and
+	// pods in a pod group are reserved atomically before any of them can be bound.
+	PodGroupLabelKey = "katalyst.kubewharf.io/pod_group"
+	// PodAnnotationPodGroupMinMemberKey is the annotation of the minimum number of
+	// pods in a pod group to be reserved before any of them can be bound.
+	PodAnnotationPodGroupMinMemberKey = "katalyst.kubewharf.io/pod_group_min_member"
+)
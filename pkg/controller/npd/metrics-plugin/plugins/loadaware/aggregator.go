@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"math"
+	"sort"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+)
+
+// aggregateFuncs aggregate non-empty samples in a window
+var aggregateFuncs = map[v1alpha1.Aggregator]func(values []float64) float64{
+	v1alpha1.AggregatorAvg: func(values []float64) float64 {
+		sum := 0.
+		for _, value := range values {
+			sum += value
+		}
+		return sum / float64(len(values))
+	},
+	v1alpha1.AggregatorMax: func(values []float64) float64 {
+		result := values[0]
+		for _, value := range values[1:] {
+			result = math.Max(result, value)
+		}
+		return result
+	},
+	v1alpha1.AggregatorMin: func(values []float64) float64 {
+		result := values[0]
+		for _, value := range values[1:] {
+			result = math.Min(result, value)
+		}
+		return result
+	},
+	v1alpha1.AggregatorCount: func(values []float64) float64 {
+		return float64(len(values))
+	},
+	v1alpha1.AggregatorP99: percentile(0.99),
+	v1alpha1.AggregatorP95: percentile(0.95),
+	v1alpha1.AggregatorP90: percentile(0.90),
+}
+
+// percentile returns the nearest-rank percentile of values
+func percentile(p float64) func(values []float64) float64 {
+	return func(values []float64) float64 {
+		sorted := append([]float64(nil), values...)
+		sort.Float64s(sorted)
+
+		rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+		if rank < 0 {
+			rank = 0
+		}
+		return sorted[rank]
+	}
+}
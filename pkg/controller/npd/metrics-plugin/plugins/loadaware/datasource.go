@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	promapiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	customclient "k8s.io/metrics/pkg/client/custom_metrics"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+)
+
+// customMetricSubtractSeparator separates the two custom metrics in a query of their difference
+const customMetricSubtractSeparator = "-"
+
+// customMetricDataSource queries usage from the custom metric store of katalyst,
+// where the query is the name of a custom metric. all samples kept by the store
+// are returned, so step of the range is ignored, and samples are converted into
+// prometheus matrix labeled by the object, to be aggregated in the same way.
+//
+// the query can also be the difference of two custom metrics in the form of
+// "<minuend>-<subtrahend>" (e.g. total minus available memory of nodes), and each
+// sample of the minuend is subtracted by the sample of the subtrahend closest in time.
+//
+// notice that the store only keeps samples in a short period (5m by default),
+// so windows longer than that are actually aggregated over the retained samples.
+type customMetricDataSource struct {
+	client     customclient.CustomMetricsClient
+	namespaced bool
+
+	nodeLabel      string
+	namespaceLabel string
+	podLabel       string
+}
+
+var _ dataSource = &customMetricDataSource{}
+
+// newCustomMetricDataSource creates datasource for pods if namespaced, or for nodes otherwise
+func newCustomMetricDataSource(client customclient.CustomMetricsClient, namespaced bool,
+	conf *controller.LoadAwareMetricsPluginConfig,
+) *customMetricDataSource {
+	return &customMetricDataSource{
+		client:         client,
+		namespaced:     namespaced,
+		nodeLabel:      conf.NodeLabel,
+		namespaceLabel: conf.NamespaceLabel,
+		podLabel:       conf.PodLabel,
+	}
+}
+
+func (c *customMetricDataSource) QueryRange(_ context.Context, query string, r promapiv1.Range,
+	_ ...promapiv1.Option,
+) (model.Value, promapiv1.Warnings, error) {
+	metricNames := strings.Split(query, customMetricSubtractSeparator)
+	if len(metricNames) > 2 {
+		return nil, nil, fmt.Errorf("invalid custom metric query %v", query)
+	}
+
+	streams, err := c.queryStreams(metricNames[0], r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(metricNames) == 2 {
+		subtrahends, err := c.queryStreams(metricNames[1], r)
+		if err != nil {
+			return nil, nil, err
+		}
+		streams = subtractStreams(streams, subtrahends)
+	}
+
+	matrix := make(model.Matrix, 0, len(streams))
+	for _, stream := range streams {
+		matrix = append(matrix, stream)
+	}
+	sort.Sort(matrix)
+	return matrix, nil, nil
+}
+
+// queryStreams returns samples of the custom metric in the range, grouped by object and sorted by time
+func (c *customMetricDataSource) queryStreams(metricName string, r promapiv1.Range) (map[types.NamespacedName]*model.SampleStream, error) {
+	metricsGetter := c.client.RootScopedMetrics()
+	groupKind := schema.GroupKind{Kind: "Node"}
+	if c.namespaced {
+		metricsGetter = c.client.NamespacedMetrics(metav1.NamespaceAll)
+		groupKind = schema.GroupKind{Kind: "Pod"}
+	}
+
+	metricValues, err := metricsGetter.GetForObjects(groupKind, labels.Everything(), metricName, labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	streams := make(map[types.NamespacedName]*model.SampleStream)
+	for _, metricValue := range metricValues.Items {
+		t := metricValue.Timestamp.Time
+		if t.Before(r.Start) || t.After(r.End) {
+			continue
+		}
+
+		key := types.NamespacedName{
+			Namespace: metricValue.DescribedObject.Namespace,
+			Name:      metricValue.DescribedObject.Name,
+		}
+		stream, ok := streams[key]
+		if !ok {
+			stream = &model.SampleStream{Metric: c.objectLabels(key)}
+			streams[key] = stream
+		}
+		stream.Values = append(stream.Values, model.SamplePair{
+			Timestamp: model.TimeFromUnixNano(t.UnixNano()),
+			Value:     model.SampleValue(metricValue.Value.AsApproximateFloat64()),
+		})
+	}
+
+	for _, stream := range streams {
+		stream := stream
+		sort.Slice(stream.Values, func(i, j int) bool {
+			return stream.Values[i].Timestamp.Before(stream.Values[j].Timestamp)
+		})
+	}
+	return streams, nil
+}
+
+// subtractStreams subtracts each sample of minuends by the sample of subtrahends closest in time,
+// and objects without any subtrahend are dropped since the difference is unknown.
+func subtractStreams(minuends, subtrahends map[types.NamespacedName]*model.SampleStream) map[types.NamespacedName]*model.SampleStream {
+	results := make(map[types.NamespacedName]*model.SampleStream, len(minuends))
+	for key, minuend := range minuends {
+		subtrahend, ok := subtrahends[key]
+		if !ok || len(subtrahend.Values) == 0 {
+			continue
+		}
+
+		result := &model.SampleStream{Metric: minuend.Metric, Values: make([]model.SamplePair, 0, len(minuend.Values))}
+		j := 0
+		for _, sample := range minuend.Values {
+			// values are sorted by time, so the closest subtrahend only moves forward
+			for j+1 < len(subtrahend.Values) &&
+				absDuration(subtrahend.Values[j+1].Timestamp.Sub(sample.Timestamp)) <=
+					absDuration(subtrahend.Values[j].Timestamp.Sub(sample.Timestamp)) {
+				j++
+			}
+			result.Values = append(result.Values, model.SamplePair{
+				Timestamp: sample.Timestamp,
+				Value:     sample.Value - subtrahend.Values[j].Value,
+			})
+		}
+		results[key] = result
+	}
+	return results
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func (c *customMetricDataSource) objectLabels(key types.NamespacedName) model.Metric {
+	if c.namespaced {
+		return model.Metric{
+			model.LabelName(c.namespaceLabel): model.LabelValue(key.Namespace),
+			model.LabelName(c.podLabel):       model.LabelValue(key.Name),
+		}
+	}
+	return model.Metric{model.LabelName(c.nodeLabel): model.LabelValue(key.Name)}
+}
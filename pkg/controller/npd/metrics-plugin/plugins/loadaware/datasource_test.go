@@ -0,0 +1,165 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	promapiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/metrics/pkg/apis/custom_metrics/v1beta2"
+	customclient "k8s.io/metrics/pkg/client/custom_metrics"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+)
+
+// fakeCustomMetricsClient returns metric values by kind and metric name
+type fakeCustomMetricsClient struct {
+	values map[string][]v1beta2.MetricValue
+}
+
+func (f *fakeCustomMetricsClient) RootScopedMetrics() customclient.MetricsInterface { return f }
+
+func (f *fakeCustomMetricsClient) NamespacedMetrics(_ string) customclient.MetricsInterface { return f }
+
+func (f *fakeCustomMetricsClient) GetForObject(_ schema.GroupKind, _ string, _ string, _ labels.Selector) (*v1beta2.MetricValue, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (f *fakeCustomMetricsClient) GetForObjects(groupKind schema.GroupKind, _ labels.Selector, metricName string,
+	_ labels.Selector,
+) (*v1beta2.MetricValueList, error) {
+	values, ok := f.values[groupKind.Kind+"/"+metricName]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %v of %v", metricName, groupKind.Kind)
+	}
+	return &v1beta2.MetricValueList{Items: values}, nil
+}
+
+func makeCustomMetricValue(kind, namespace, name string, timestamp time.Time, value int64) v1beta2.MetricValue {
+	return v1beta2.MetricValue{
+		DescribedObject: v1.ObjectReference{Kind: kind, Namespace: namespace, Name: name},
+		Timestamp:       metav1.NewTime(timestamp),
+		Value:           *resource.NewQuantity(value, resource.DecimalSI),
+	}
+}
+
+func TestCustomMetricDataSource(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().Truncate(time.Second)
+	client := &fakeCustomMetricsClient{
+		values: map[string][]v1beta2.MetricValue{
+			"Node/node_cpu_usage": {
+				makeCustomMetricValue("Node", "", "node-1", now, 3),
+				makeCustomMetricValue("Node", "", "node-1", now.Add(-time.Minute), 2),
+				makeCustomMetricValue("Node", "", "node-2", now, 5),
+				// samples out of range are dropped
+				makeCustomMetricValue("Node", "", "node-1", now.Add(-time.Hour), 1),
+			},
+			"Node/node_system_memory_total": {
+				makeCustomMetricValue("Node", "", "node-1", now, 100),
+				makeCustomMetricValue("Node", "", "node-1", now.Add(-time.Minute), 100),
+				makeCustomMetricValue("Node", "", "node-2", now, 200),
+			},
+			"Node/node_system_memory_available": {
+				// subtracted by the closest sample in time
+				makeCustomMetricValue("Node", "", "node-1", now.Add(-time.Second), 40),
+				makeCustomMetricValue("Node", "", "node-1", now.Add(-time.Minute-time.Second), 70),
+			},
+			"Pod/pod_memory_usage": {
+				makeCustomMetricValue("Pod", "default", "pod-1", now, 1024),
+			},
+		},
+	}
+	conf := &controller.LoadAwareMetricsPluginConfig{NodeLabel: "node", NamespaceLabel: "namespace", PodLabel: "pod"}
+	r := promapiv1.Range{Start: now.Add(-5 * time.Minute), End: now, Step: time.Minute}
+
+	tests := []struct {
+		name       string
+		namespaced bool
+		query      string
+		want       model.Value
+		wantErr    bool
+	}{
+		{
+			name:  "node metrics",
+			query: "node_cpu_usage",
+			want: model.Matrix{
+				{Metric: model.Metric{"node": "node-1"}, Values: makeSamples(now, 2, 3)},
+				{Metric: model.Metric{"node": "node-2"}, Values: makeSamples(now, 5)},
+			},
+		},
+		{
+			name:  "difference of node metrics",
+			query: "node_system_memory_total-node_system_memory_available",
+			want: model.Matrix{
+				// node-2 is dropped without available memory
+				{Metric: model.Metric{"node": "node-1"}, Values: makeSamples(now, 30, 60)},
+			},
+		},
+		{
+			name:    "invalid difference",
+			query:   "node_cpu_usage-node_cpu_usage-node_cpu_usage",
+			wantErr: true,
+		},
+		{
+			name:    "unknown subtrahend",
+			query:   "node_cpu_usage-node_memory_usage",
+			wantErr: true,
+		},
+		{
+			name:       "pod metrics",
+			namespaced: true,
+			query:      "pod_memory_usage",
+			want: model.Matrix{
+				{Metric: model.Metric{"namespace": "default", "pod": "pod-1"}, Values: makeSamples(now, 1024)},
+			},
+		},
+		{
+			name:       "unknown metric",
+			namespaced: true,
+			query:      "node_cpu_usage",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ds := newCustomMetricDataSource(client, tt.namespaced, conf)
+			got, _, err := ds.QueryRange(context.TODO(), tt.query, r)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
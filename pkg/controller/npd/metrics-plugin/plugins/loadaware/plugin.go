@@ -0,0 +1,325 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	promapiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	metricsplugin "github.com/kubewharf/katalyst-core/pkg/controller/npd/metrics-plugin"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	datasourceprometheus "github.com/kubewharf/katalyst-core/pkg/util/datasource/prometheus"
+)
+
+const (
+	PluginName = "loadaware"
+
+	dataSourceCustomMetric = "custom-metric"
+	dataSourcePrometheus   = "prom"
+
+	metricsNameQueryLoadProfilesFailed = "npd_load_aware_query_failed"
+)
+
+// dataSource is implemented by prometheus api and custom metric datasource,
+// and it's replaced in unit tests
+type dataSource interface {
+	QueryRange(ctx context.Context, query string, r promapiv1.Range, opts ...promapiv1.Option) (model.Value, promapiv1.Warnings, error)
+}
+
+// LoadAwareMetricsPlugin queries usage of nodes and pods from datasource periodically,
+// and aggregates them over time windows as load profiles in npd.
+type LoadAwareMetricsPlugin struct {
+	ctx  context.Context
+	conf *controller.LoadAwareMetricsPluginConfig
+
+	nodeDataSource dataSource
+	podDataSource  dataSource
+	nodeQueries    map[string]string
+	podQueries     map[string]string
+	aggregators    []v1alpha1.Aggregator
+	podLister      corelisters.PodLister
+	syncedFunc     []cache.InformerSynced
+	updater        metricsplugin.MetricsUpdater
+	metricsEmitter metrics.MetricEmitter
+}
+
+var _ metricsplugin.MetricsPlugin = &LoadAwareMetricsPlugin{}
+
+func NewLoadAwareMetricsPlugin(ctx context.Context, conf *controller.NPDConfig, _ interface{},
+	controlCtx *katalystbase.GenericContext, updater metricsplugin.MetricsUpdater,
+) (metricsplugin.MetricsPlugin, error) {
+	pluginConf := conf.LoadAwareMetricsPluginConfig
+	if pluginConf == nil {
+		return nil, fmt.Errorf("load aware metrics plugin config is nil")
+	}
+	if len(pluginConf.Windows) == 0 || pluginConf.Step <= 0 || pluginConf.SyncPeriod <= 0 {
+		return nil, fmt.Errorf("invalid windows %v, step %v or sync period %v",
+			pluginConf.Windows, pluginConf.Step, pluginConf.SyncPeriod)
+	}
+
+	aggregators := make([]v1alpha1.Aggregator, 0, len(pluginConf.Aggregators))
+	for _, aggregator := range pluginConf.Aggregators {
+		if _, ok := aggregateFuncs[v1alpha1.Aggregator(aggregator)]; !ok {
+			return nil, fmt.Errorf("unsupported aggregator %v", aggregator)
+		}
+		aggregators = append(aggregators, v1alpha1.Aggregator(aggregator))
+	}
+
+	podInformer := controlCtx.KubeInformerFactory.Core().V1().Pods()
+	p := &LoadAwareMetricsPlugin{
+		ctx:            ctx,
+		conf:           pluginConf,
+		aggregators:    aggregators,
+		podLister:      podInformer.Lister(),
+		syncedFunc:     []cache.InformerSynced{podInformer.Informer().HasSynced},
+		updater:        updater,
+		metricsEmitter: controlCtx.EmitterPool.GetDefaultMetricsEmitter().WithTags(PluginName),
+	}
+
+	switch pluginConf.DataSource {
+	case dataSourceCustomMetric:
+		p.nodeDataSource = newCustomMetricDataSource(controlCtx.Client.CustomClient, false, pluginConf)
+		p.podDataSource = newCustomMetricDataSource(controlCtx.Client.CustomClient, true, pluginConf)
+		p.nodeQueries = pluginConf.NodeCustomMetrics
+		p.podQueries = pluginConf.PodCustomMetrics
+	case dataSourcePrometheus:
+		promClient, err := datasourceprometheus.NewPrometheusClient(&pluginConf.DataSourcePromConfig)
+		if err != nil {
+			return nil, err
+		}
+		p.nodeDataSource = promapiv1.NewAPI(promClient)
+		p.podDataSource = p.nodeDataSource
+		p.nodeQueries = pluginConf.NodeMetricQueries
+		p.podQueries = pluginConf.PodMetricQueries
+	default:
+		return nil, fmt.Errorf("unsupported datasource %v", pluginConf.DataSource)
+	}
+	return p, nil
+}
+
+func (p *LoadAwareMetricsPlugin) Run() {
+	if !cache.WaitForCacheSync(p.ctx.Done(), p.syncedFunc...) {
+		klog.Errorf("[npd-load-aware] unable to sync caches")
+		return
+	}
+
+	go wait.Until(p.sync, p.conf.SyncPeriod, p.ctx.Done())
+}
+
+func (p *LoadAwareMetricsPlugin) Name() string {
+	return PluginName
+}
+
+func (p *LoadAwareMetricsPlugin) GetSupportedNodeMetricsScope() []string {
+	return []string{p.conf.NodeMetricsScope}
+}
+
+func (p *LoadAwareMetricsPlugin) GetSupportedPodMetricsScope() []string {
+	return []string{p.conf.PodMetricsScope}
+}
+
+func (p *LoadAwareMetricsPlugin) sync() {
+	now := time.Now()
+	p.syncNodeMetrics(now)
+	p.syncPodMetrics(now)
+}
+
+func (p *LoadAwareMetricsPlugin) syncNodeMetrics(now time.Time) {
+	nodeMetrics := make(map[string][]v1alpha1.MetricValue)
+	for _, metricName := range sortedKeys(p.nodeQueries) {
+		matrix, err := p.queryRange(p.nodeDataSource, p.nodeQueries[metricName], now)
+		if err != nil {
+			klog.Errorf("[npd-load-aware] query node metric %v failed: %v", metricName, err)
+			continue
+		}
+
+		for _, stream := range matrix {
+			nodeName := string(stream.Metric[model.LabelName(p.conf.NodeLabel)])
+			if nodeName == "" {
+				continue
+			}
+			nodeMetrics[nodeName] = append(nodeMetrics[nodeName], p.aggregate(metricName, stream.Values, now)...)
+		}
+	}
+
+	for nodeName, metricValues := range nodeMetrics {
+		p.updater.UpdateNodeMetrics(nodeName, []v1alpha1.ScopedNodeMetrics{
+			{Scope: p.conf.NodeMetricsScope, Metrics: metricValues},
+		})
+	}
+}
+
+// syncPodMetrics groups pod load profiles by the nodes that pods are running on,
+// and pods not found in cache are skipped. since pod metrics are matched by
+// namespace and name, samples before the creation of pods are ignored, otherwise
+// recreated pods (e.g. pods of statefulset) would inherit profiles of the old ones.
+func (p *LoadAwareMetricsPlugin) syncPodMetrics(now time.Time) {
+	podMetrics := make(map[types.NamespacedName][]v1alpha1.MetricValue)
+	podNodes := make(map[types.NamespacedName]string)
+	for _, metricName := range sortedKeys(p.podQueries) {
+		matrix, err := p.queryRange(p.podDataSource, p.podQueries[metricName], now)
+		if err != nil {
+			klog.Errorf("[npd-load-aware] query pod metric %v failed: %v", metricName, err)
+			continue
+		}
+
+		for _, stream := range matrix {
+			key := types.NamespacedName{
+				Namespace: string(stream.Metric[model.LabelName(p.conf.NamespaceLabel)]),
+				Name:      string(stream.Metric[model.LabelName(p.conf.PodLabel)]),
+			}
+			if key.Namespace == "" || key.Name == "" {
+				continue
+			}
+
+			pod, err := p.podLister.Pods(key.Namespace).Get(key.Name)
+			if err != nil || pod.Spec.NodeName == "" {
+				klog.V(6).Infof("[npd-load-aware] skip pod %v not found or not scheduled", key)
+				continue
+			}
+			samples := filterSamplesSince(stream.Values, pod.CreationTimestamp.Time)
+			podMetrics[key] = append(podMetrics[key], p.aggregate(metricName, samples, now)...)
+			podNodes[key] = pod.Spec.NodeName
+		}
+	}
+
+	nodePodMetrics := make(map[string][]v1alpha1.PodMetric)
+	for key, metricValues := range podMetrics {
+		nodeName := podNodes[key]
+		nodePodMetrics[nodeName] = append(nodePodMetrics[nodeName], v1alpha1.PodMetric{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+			Metrics:   metricValues,
+		})
+	}
+
+	for nodeName, metricsOfPods := range nodePodMetrics {
+		sort.Slice(metricsOfPods, func(i, j int) bool {
+			if metricsOfPods[i].Namespace != metricsOfPods[j].Namespace {
+				return metricsOfPods[i].Namespace < metricsOfPods[j].Namespace
+			}
+			return metricsOfPods[i].Name < metricsOfPods[j].Name
+		})
+		p.updater.UpdatePodMetrics(nodeName, []v1alpha1.ScopedPodMetrics{
+			{Scope: p.conf.PodMetricsScope, PodMetrics: metricsOfPods},
+		})
+	}
+}
+
+// queryRange queries samples in the longest window, so that all windows can be aggregated with one query
+func (p *LoadAwareMetricsPlugin) queryRange(ds dataSource, query string, now time.Time) (model.Matrix, error) {
+	var maxWindow time.Duration
+	for _, window := range p.conf.Windows {
+		if window > maxWindow {
+			maxWindow = window
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(p.ctx, p.conf.SyncPeriod)
+	defer cancel()
+
+	result, _, err := ds.QueryRange(ctx, query, promapiv1.Range{
+		Start: now.Add(-maxWindow),
+		End:   now,
+		Step:  p.conf.Step,
+	})
+	if err != nil {
+		_ = p.metricsEmitter.StoreInt64(metricsNameQueryLoadProfilesFailed, 1, metrics.MetricTypeNameCount)
+		return nil, err
+	}
+
+	matrix, ok := result.(model.Matrix)
+	if !ok {
+		_ = p.metricsEmitter.StoreInt64(metricsNameQueryLoadProfilesFailed, 1, metrics.MetricTypeNameCount)
+		return nil, fmt.Errorf("unexpected result type %v", result.Type())
+	}
+	return matrix, nil
+}
+
+// aggregate calculates load profiles of each window and aggregator, and the timestamp
+// of profiles is the time of the latest sample, so that consumers can tell stale ones.
+func (p *LoadAwareMetricsPlugin) aggregate(metricName string, samples []model.SamplePair, now time.Time) []v1alpha1.MetricValue {
+	metricValues := make([]v1alpha1.MetricValue, 0, len(p.conf.Windows)*len(p.aggregators))
+	for _, window := range p.conf.Windows {
+		start := now.Add(-window)
+		values := make([]float64, 0, len(samples))
+		var timestamp time.Time
+		for _, sample := range samples {
+			t := sample.Timestamp.Time()
+			// samples are in (now-window, now], which avoids counting one more step
+			if !t.After(start) || math.IsNaN(float64(sample.Value)) {
+				continue
+			}
+			values = append(values, float64(sample.Value))
+			if t.After(timestamp) {
+				timestamp = t
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		for _, aggregator := range p.aggregators {
+			aggregator := aggregator
+			value := aggregateFuncs[aggregator](values)
+			metricValues = append(metricValues, v1alpha1.MetricValue{
+				MetricName: metricName,
+				Timestamp:  metav1.NewTime(timestamp),
+				Aggregator: &aggregator,
+				Window:     &metav1.Duration{Duration: window},
+				Value:      *resource.NewMilliQuantity(int64(math.Round(value*1000)), resource.DecimalSI),
+			})
+		}
+	}
+	return metricValues
+}
+
+// filterSamplesSince returns samples not earlier than the given time
+func filterSamplesSince(samples []model.SamplePair, since time.Time) []model.SamplePair {
+	filtered := make([]model.SamplePair, 0, len(samples))
+	for _, sample := range samples {
+		if !sample.Timestamp.Time().Before(since) {
+			filtered = append(filtered, sample)
+		}
+	}
+	return filtered
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,282 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	promapiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	metricsplugin "github.com/kubewharf/katalyst-core/pkg/controller/npd/metrics-plugin"
+)
+
+type fakeDataSource struct {
+	results map[string]model.Matrix
+}
+
+func (f *fakeDataSource) QueryRange(_ context.Context, query string, r promapiv1.Range,
+	_ ...promapiv1.Option,
+) (model.Value, promapiv1.Warnings, error) {
+	matrix, ok := f.results[query]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown query %v", query)
+	}
+
+	// only samples in the range are returned
+	result := model.Matrix{}
+	for _, stream := range matrix {
+		filtered := &model.SampleStream{Metric: stream.Metric}
+		for _, sample := range stream.Values {
+			if t := sample.Timestamp.Time(); !t.Before(r.Start) && !t.After(r.End) {
+				filtered.Values = append(filtered.Values, sample)
+			}
+		}
+		result = append(result, filtered)
+	}
+	return result, nil, nil
+}
+
+// makeSamples makes one sample per minute, and the last one is the latest
+func makeSamples(now time.Time, values ...float64) []model.SamplePair {
+	samples := make([]model.SamplePair, 0, len(values))
+	for i, value := range values {
+		t := now.Add(-time.Duration(len(values)-1-i) * time.Minute)
+		samples = append(samples, model.SamplePair{Timestamp: model.TimeFromUnixNano(t.UnixNano()), Value: model.SampleValue(value)})
+	}
+	return samples
+}
+
+// makeMetricValue makes metric value in milli unit
+func makeMetricValue(name string, aggregator v1alpha1.Aggregator, window time.Duration, timestamp time.Time, value int64) v1alpha1.MetricValue {
+	return v1alpha1.MetricValue{
+		MetricName: name,
+		Timestamp:  metav1.NewTime(timestamp),
+		Aggregator: &aggregator,
+		Window:     &metav1.Duration{Duration: window},
+		Value:      *resource.NewMilliQuantity(value, resource.DecimalSI),
+	}
+}
+
+func TestLoadAwareMetricsPlugin(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Now().Truncate(time.Second)
+	pods := []runtime.Object{
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-1"},
+			Spec:       v1.PodSpec{NodeName: "node-1"},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "default",
+				Name:              "recreated",
+				CreationTimestamp: metav1.NewTime(now.Add(-time.Minute)),
+			},
+			Spec: v1.PodSpec{NodeName: "node-1"},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pending"},
+		},
+	}
+	controlCtx, err := katalystbase.GenerateFakeGenericContext(pods)
+	require.NoError(t, err)
+
+	conf := controller.NewNPDConfig()
+	conf.LoadAwareMetricsPluginConfig = &controller.LoadAwareMetricsPluginConfig{
+		DataSource:        dataSourcePrometheus,
+		SyncPeriod:        time.Minute,
+		NodeMetricsScope:  consts.NPDScopeLoadAware,
+		PodMetricsScope:   consts.NPDScopeLoadAware,
+		Windows:           []time.Duration{3 * time.Minute, 5 * time.Minute},
+		Step:              time.Minute,
+		Aggregators:       []string{"avg", "max", "p95"},
+		NodeMetricQueries: map[string]string{consts.NPDMetricNameCPUUsage: "node_cpu"},
+		PodMetricQueries:  map[string]string{consts.NPDMetricNameMemoryUsage: "pod_memory"},
+		NodeLabel:         "node",
+		NamespaceLabel:    "namespace",
+		PodLabel:          "pod",
+	}
+
+	manager := metricsplugin.NewMetricsManager()
+	plugin, err := NewLoadAwareMetricsPlugin(ctx, conf, nil, controlCtx, manager)
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.NPDScopeLoadAware}, plugin.GetSupportedNodeMetricsScope())
+	assert.Equal(t, []string{consts.NPDScopeLoadAware}, plugin.GetSupportedPodMetricsScope())
+
+	p := plugin.(*LoadAwareMetricsPlugin)
+	p.nodeDataSource = &fakeDataSource{
+		results: map[string]model.Matrix{
+			"node_cpu": {
+				{Metric: model.Metric{"node": "node-1"}, Values: makeSamples(now, 10, 8, 1, 2, 3)},
+				{Metric: model.Metric{"instance": "unknown"}, Values: makeSamples(now, 1)},
+			},
+			"pod_memory": {
+				{Metric: model.Metric{"namespace": "default", "pod": "pod-1"}, Values: makeSamples(now, 1024, 2048)},
+				{Metric: model.Metric{"namespace": "default", "pod": "pending"}, Values: makeSamples(now, 1024)},
+				{Metric: model.Metric{"namespace": "default", "pod": "recreated"}, Values: makeSamples(now, 4096, 4096, 512, 1024)},
+			},
+		},
+	}
+	p.podDataSource = p.nodeDataSource
+
+	controlCtx.StartInformer(ctx)
+	require.True(t, cache.WaitForCacheSync(ctx.Done(), p.syncedFunc...))
+	p.syncNodeMetrics(now)
+	p.syncPodMetrics(now)
+
+	status := manager.GetNodeProfileStatus("node-1")
+	require.NotNil(t, status)
+	assert.Equal(t, []v1alpha1.ScopedNodeMetrics{
+		{
+			Scope: consts.NPDScopeLoadAware,
+			Metrics: []v1alpha1.MetricValue{
+				makeMetricValue(consts.NPDMetricNameCPUUsage, v1alpha1.AggregatorAvg, 3*time.Minute, now, 2000),
+				makeMetricValue(consts.NPDMetricNameCPUUsage, v1alpha1.AggregatorMax, 3*time.Minute, now, 3000),
+				makeMetricValue(consts.NPDMetricNameCPUUsage, v1alpha1.AggregatorP95, 3*time.Minute, now, 3000),
+				makeMetricValue(consts.NPDMetricNameCPUUsage, v1alpha1.AggregatorAvg, 5*time.Minute, now, 4800),
+				makeMetricValue(consts.NPDMetricNameCPUUsage, v1alpha1.AggregatorMax, 5*time.Minute, now, 10000),
+				makeMetricValue(consts.NPDMetricNameCPUUsage, v1alpha1.AggregatorP95, 5*time.Minute, now, 10000),
+			},
+		},
+	}, status.NodeMetrics)
+	assert.Equal(t, []v1alpha1.ScopedPodMetrics{
+		{
+			Scope: consts.NPDScopeLoadAware,
+			PodMetrics: []v1alpha1.PodMetric{
+				{
+					Namespace: "default",
+					Name:      "pod-1",
+					Metrics: []v1alpha1.MetricValue{
+						makeMetricValue(consts.NPDMetricNameMemoryUsage, v1alpha1.AggregatorAvg, 3*time.Minute, now, 1536000),
+						makeMetricValue(consts.NPDMetricNameMemoryUsage, v1alpha1.AggregatorMax, 3*time.Minute, now, 2048000),
+						makeMetricValue(consts.NPDMetricNameMemoryUsage, v1alpha1.AggregatorP95, 3*time.Minute, now, 2048000),
+						makeMetricValue(consts.NPDMetricNameMemoryUsage, v1alpha1.AggregatorAvg, 5*time.Minute, now, 1536000),
+						makeMetricValue(consts.NPDMetricNameMemoryUsage, v1alpha1.AggregatorMax, 5*time.Minute, now, 2048000),
+						makeMetricValue(consts.NPDMetricNameMemoryUsage, v1alpha1.AggregatorP95, 5*time.Minute, now, 2048000),
+					},
+				},
+				{
+					// samples before the pod is recreated are ignored
+					Namespace: "default",
+					Name:      "recreated",
+					Metrics: []v1alpha1.MetricValue{
+						makeMetricValue(consts.NPDMetricNameMemoryUsage, v1alpha1.AggregatorAvg, 3*time.Minute, now, 768000),
+						makeMetricValue(consts.NPDMetricNameMemoryUsage, v1alpha1.AggregatorMax, 3*time.Minute, now, 1024000),
+						makeMetricValue(consts.NPDMetricNameMemoryUsage, v1alpha1.AggregatorP95, 3*time.Minute, now, 1024000),
+						makeMetricValue(consts.NPDMetricNameMemoryUsage, v1alpha1.AggregatorAvg, 5*time.Minute, now, 768000),
+						makeMetricValue(consts.NPDMetricNameMemoryUsage, v1alpha1.AggregatorMax, 5*time.Minute, now, 1024000),
+						makeMetricValue(consts.NPDMetricNameMemoryUsage, v1alpha1.AggregatorP95, 5*time.Minute, now, 1024000),
+					},
+				},
+			},
+		},
+	}, status.PodMetrics)
+
+	// pending pods and series without node label are skipped
+	assert.Nil(t, manager.GetNodeProfileStatus(""))
+}
+
+func TestNewLoadAwareMetricsPlugin(t *testing.T) {
+	t.Parallel()
+
+	controlCtx, err := katalystbase.GenerateFakeGenericContext()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		conf    *controller.LoadAwareMetricsPluginConfig
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			conf: &controller.LoadAwareMetricsPluginConfig{
+				DataSource: dataSourcePrometheus, SyncPeriod: time.Minute,
+				Windows: []time.Duration{time.Hour}, Step: time.Minute, Aggregators: []string{"p99"},
+			},
+		},
+		{
+			name: "custom metric datasource",
+			conf: &controller.LoadAwareMetricsPluginConfig{
+				DataSource: dataSourceCustomMetric, SyncPeriod: time.Minute,
+				Windows: []time.Duration{time.Hour}, Step: time.Minute, Aggregators: []string{"avg"},
+			},
+		},
+		{
+			name: "unsupported aggregator",
+			conf: &controller.LoadAwareMetricsPluginConfig{
+				DataSource: dataSourcePrometheus, SyncPeriod: time.Minute,
+				Windows: []time.Duration{time.Hour}, Step: time.Minute, Aggregators: []string{"p50"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported datasource",
+			conf: &controller.LoadAwareMetricsPluginConfig{
+				DataSource: "unknown", SyncPeriod: time.Minute,
+				Windows: []time.Duration{time.Hour}, Step: time.Minute,
+			},
+			wantErr: true,
+		},
+		{
+			name: "no windows",
+			conf: &controller.LoadAwareMetricsPluginConfig{
+				DataSource: dataSourcePrometheus, SyncPeriod: time.Minute, Step: time.Minute,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := controller.NewNPDConfig()
+			conf.LoadAwareMetricsPluginConfig = tt.conf
+			_, err := NewLoadAwareMetricsPlugin(context.TODO(), conf, nil, controlCtx, metricsplugin.NewMetricsManager())
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	t.Parallel()
+
+	values := []float64{5, 1, 4, 2, 3, 6, 7, 8, 9, 10}
+	assert.Equal(t, float64(10), percentile(0.95)(values))
+	assert.Equal(t, float64(9), percentile(0.90)(values))
+	assert.Equal(t, float64(1), percentile(0.01)(values))
+	// values are not modified
+	assert.Equal(t, float64(5), values[0])
+}
@@ -43,11 +43,14 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/config/controller"
 	"github.com/kubewharf/katalyst-core/pkg/config/generic"
 	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/datasource"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/datasource/custommetric"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/datasource/localfile"
 	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/datasource/prometheus"
 	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/oom"
 	processormanager "github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/processor/manager"
 	recommendermanager "github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/recommender/manager"
 	conditionstypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/conditions"
+	datasourcetypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/datasource"
 	errortypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/error"
 	processortypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/processor"
 	recommendationtypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/recommendation"
@@ -77,6 +80,8 @@ type ResourceRecommendController struct {
 	client     dynamic.Interface
 	restMapper *restmapper.DeferredDiscoveryRESTMapper
 
+	dataProxy *datasource.Proxy
+
 	OOMRecorder        *oom.PodOOMRecorder
 	ProcessorManager   *processormanager.Manager
 	RecommenderManager *recommendermanager.Manager
@@ -139,6 +144,7 @@ func NewResourceRecommendController(ctx context.Context,
 	dataProxy := initDataSources(recConf)
 	klog.Infof("[resource-recommend] successfully init data proxy %v", *dataProxy)
 
+	recController.dataProxy = dataProxy
 	recController.ProcessorManager = processormanager.NewManager(dataProxy, recController.recLister)
 	recController.OOMRecorder = OOMRecorder
 	recController.RecommenderManager = recommendermanager.NewManager(*recController.ProcessorManager, recController.OOMRecorder)
@@ -348,6 +354,9 @@ func (rrc *ResourceRecommendController) doRecommend(ctx context.Context, namespa
 	}()
 
 	validationError := recommendation.SetConfig(ctx, rrc.client, resourceRecommend, rrc.restMapper)
+	if validationError == nil {
+		validationError = rrc.validateDatasource(recommendation.Datasource)
+	}
 	if validationError != nil {
 		klog.ErrorS(validationError, "failed to get Recommendation", "resourceRecommend", namespacedName)
 		recommendation.Conditions.Set(*conditionstypes.ConvertCustomErrorToCondition(*validationError))
@@ -382,6 +391,7 @@ func (rrc *ResourceRecommendController) RegisterTasks(recommendation recommendat
 			processConfig := processortypes.NewProcessConfig(recommendation.NamespacedName,
 				recommendation.Config.TargetRef, container.ContainerName,
 				containerConfig.ControlledResource, "")
+			processConfig.Datasource = recommendation.Datasource
 			if err := processor.Register(processConfig); err != nil {
 				return errortypes.DataProcessRegisteredFailedError(err.Error())
 			}
@@ -390,6 +400,23 @@ func (rrc *ResourceRecommendController) RegisterTasks(recommendation recommendat
 	return nil
 }
 
+// validateDatasource checks that the datasource specified by ResourceRecommend is registered,
+// and the default datasource must be registered if it's not specified
+func (rrc *ResourceRecommendController) validateDatasource(name string) *errortypes.CustomError {
+	if rrc.dataProxy == nil || rrc.dataProxy.HasDatasource(datasourcetypes.DatasourceType(name)) {
+		return nil
+	}
+
+	var registered []string
+	for _, registeredName := range rrc.dataProxy.ListDatasources() {
+		registered = append(registered, string(registeredName))
+	}
+	if name == "" {
+		name = string(datasource.DefaultDatasource)
+	}
+	return errortypes.DatasourceUnsupportedError(name, registered)
+}
+
 // CancelTasks Cancel all process task
 func (rrc *ResourceRecommendController) CancelTasks(namespacedName k8stypes.NamespacedName) *errortypes.CustomError {
 	processor := rrc.ProcessorManager.GetProcessor(v1alpha1.AlgorithmPercentile)
@@ -418,7 +445,21 @@ func initDataSources(opts *controller.ResourceRecommenderConfig) *datasource.Pro
 	dataProxy := datasource.NewProxy()
 	for _, datasourceProvider := range opts.DataSource {
 		switch datasourceProvider {
-		case string(datasource.PrometheusDatasource):
+		case string(datasourcetypes.CustomMetricDatasource):
+			customMetricProvider, err := custommetric.NewCustomMetric(&opts.DataSourceCustomMetricConfig)
+			if err != nil {
+				klog.Exitf("unable to create datasource provider %v, err: %v", datasourceProvider, err)
+				panic(err)
+			}
+			dataProxy.RegisterDatasource(datasourcetypes.CustomMetricDatasource, customMetricProvider)
+		case string(datasourcetypes.LocalFileDatasource):
+			localFileProvider, err := localfile.NewLocalFile(&opts.DataSourceLocalFileConfig)
+			if err != nil {
+				klog.Exitf("unable to create datasource provider %v, err: %v", datasourceProvider, err)
+				panic(err)
+			}
+			dataProxy.RegisterDatasource(datasourcetypes.LocalFileDatasource, localFileProvider)
+		case string(datasourcetypes.PrometheusDatasource):
 			fallthrough
 		default:
 			// default is prom
@@ -427,7 +468,7 @@ func initDataSources(opts *controller.ResourceRecommenderConfig) *datasource.Pro
 				klog.Exitf("unable to create datasource provider %v, err: %v", prometheusProvider, err)
 				panic(err)
 			}
-			dataProxy.RegisterDatasource(datasource.PrometheusDatasource, prometheusProvider)
+			dataProxy.RegisterDatasource(datasourcetypes.PrometheusDatasource, prometheusProvider)
 		}
 	}
 	return dataProxy
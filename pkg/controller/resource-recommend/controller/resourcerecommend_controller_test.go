@@ -53,7 +53,7 @@ func (m *MockDatasource) GetPromClient() promapiv1.API {
 func Test_initDataSources(t *testing.T) {
 	proxy := datasource.NewProxy()
 	mockDatasource := MockDatasource{}
-	proxy.RegisterDatasource(datasource.PrometheusDatasource, &mockDatasource)
+	proxy.RegisterDatasource(datasourcetypes.PrometheusDatasource, &mockDatasource)
 	type args struct {
 		opts *controller.ResourceRecommenderConfig
 	}
@@ -66,7 +66,7 @@ func Test_initDataSources(t *testing.T) {
 			name: "return_Datasource",
 			args: args{
 				opts: &controller.ResourceRecommenderConfig{
-					DataSource: []string{string(datasource.PrometheusDatasource)},
+					DataSource: []string{string(datasourcetypes.PrometheusDatasource)},
 				},
 			},
 			want: proxy,
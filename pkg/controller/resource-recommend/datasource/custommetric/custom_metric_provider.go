@@ -0,0 +1,256 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package custommetric
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/datasource"
+	"github.com/kubewharf/katalyst-core/pkg/custom-metric/store/data/types"
+	"github.com/kubewharf/katalyst-core/pkg/custom-metric/store/local"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	datasourcetypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/datasource"
+)
+
+const (
+	// metricSelectorKeyContainer is the label key of container name in pod metrics
+	metricSelectorKeyContainer = "container"
+
+	workloadSuffixRuleForDeployment = `[a-z0-9]+-[a-z0-9]{5}$`
+)
+
+var podGroupResource = schema.GroupResource{Resource: string(v1.ResourcePods)}
+
+// MetricGetter is the subset of store.MetricStore that custom metric datasource relies on
+type MetricGetter interface {
+	GetMetric(ctx context.Context, namespace, metricName, objName string, gr *schema.GroupResource,
+		objSelector, metricSelector labels.Selector, latest bool) ([]types.Metric, error)
+}
+
+type customMetric struct {
+	getter MetricGetter
+	config *controller.CustomMetricDatasourceConfig
+}
+
+// NewCustomMetric returns a datasource which queries history usage from katalyst custom metric store
+func NewCustomMetric(config *controller.CustomMetricDatasourceConfig) (datasource.Datasource, error) {
+	if config == nil || config.Address == "" {
+		return nil, fmt.Errorf("custom metric store address is empty")
+	}
+
+	getter, err := newStoreClient(config.Address, config.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return NewCustomMetricWithGetter(getter, config), nil
+}
+
+// NewCustomMetricWithGetter returns a custom metric datasource with the given MetricGetter,
+// e.g. a MetricStore running in the same process
+func NewCustomMetricWithGetter(getter MetricGetter, config *controller.CustomMetricDatasourceConfig) datasource.Datasource {
+	return &customMetric{getter: getter, config: config}
+}
+
+func (c *customMetric) ConvertMetricToQuery(metric datasourcetypes.Metric) (*datasourcetypes.Query, error) {
+	var metricName string
+	switch metric.Resource {
+	case v1.ResourceCPU:
+		metricName = c.config.CPUMetricName
+	case v1.ResourceMemory:
+		metricName = c.config.MemoryMetricName
+	default:
+		return nil, fmt.Errorf("query for resource type %v is not supported", metric.Resource)
+	}
+
+	metricSelector := fmt.Sprintf("%s=%s", metricSelectorKeyContainer, metric.ContainerName)
+	// selectors of metric are in the format of promql, i.e. key="value"
+	if metric.Selectors != "" {
+		metricSelector += "," + strings.ReplaceAll(metric.Selectors, `"`, "")
+	}
+
+	return &datasourcetypes.Query{
+		CustomMetric: &datasourcetypes.CustomMetricQuery{
+			Namespace:         metric.Namespace,
+			MetricName:        metricName,
+			MetricSelector:    metricSelector,
+			ObjectNamePattern: convertWorkloadNameToPods(metric.WorkloadName, metric.Kind),
+		},
+	}, nil
+}
+
+// QueryTimeSeries returns samples of all matched pods in one time series, and labels of pods are merged
+// as well, which is the same as prometheus datasource; samples of each pod are aligned by step so that
+// the weights of samples in histograms are consistent with prometheus range queries.
+func (c *customMetric) QueryTimeSeries(query *datasourcetypes.Query, start time.Time, end time.Time, step time.Duration) (*datasourcetypes.TimeSeries, error) {
+	if query == nil || query.CustomMetric == nil {
+		return nil, fmt.Errorf("custom metric query is empty")
+	}
+	klog.InfoS("QueryTimeSeries", "query", general.StructToString(query), "start", start, "end", end)
+
+	metricSelector, err := labels.Parse(query.CustomMetric.MetricSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metric selector %v: %v", query.CustomMetric.MetricSelector, err)
+	}
+	objectNameRegexp, err := regexp.Compile(query.CustomMetric.ObjectNamePattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object name pattern %v: %v", query.CustomMetric.ObjectNamePattern, err)
+	}
+
+	timeoutCtx, cancelFunc := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancelFunc()
+	metricList, err := c.getter.GetMetric(timeoutCtx, query.CustomMetric.Namespace, query.CustomMetric.MetricName, "",
+		&podGroupResource, nil, metricSelector, false)
+	if err != nil {
+		klog.ErrorS(err, "query", query, "start", start, "end", end)
+		return nil, err
+	}
+
+	results := datasourcetypes.NewTimeSeries()
+	for _, metric := range metricList {
+		if metric == nil || !objectNameRegexp.MatchString(metric.GetObjectName()) {
+			continue
+		}
+
+		for key, val := range metric.GetLabels() {
+			results.AppendLabel(key, val)
+		}
+		for _, sample := range alignSamplesByStep(metric.GetItemList(), start, end, step) {
+			results.AppendSample(sample.Timestamp, sample.Value)
+		}
+	}
+	return results, nil
+}
+
+// alignSamplesByStep keeps the latest sample within each step of (start+(n-1)*step, start+n*step],
+// and the sample is aligned to the end of its step, just like prometheus evaluates series at each
+// step of range queries; raw samples are returned if step isn't positive.
+func alignSamplesByStep(items []types.Item, start, end time.Time, step time.Duration) []datasourcetypes.Sample {
+	var samples []datasourcetypes.Sample
+	latest := make(map[int64]types.Item)
+	for _, item := range items {
+		timestamp := time.UnixMilli(item.GetTimestamp())
+		if timestamp.Before(start) || timestamp.After(end) {
+			continue
+		}
+
+		if step <= 0 {
+			quantity := item.GetQuantity()
+			samples = append(samples, datasourcetypes.Sample{Value: quantity.AsApproximateFloat64(), Timestamp: timestamp.Unix()})
+			continue
+		}
+
+		n := int64((timestamp.Sub(start) + step - 1) / step)
+		if last, ok := latest[n]; !ok || last.GetTimestamp() < item.GetTimestamp() {
+			latest[n] = item
+		}
+	}
+
+	for n, item := range latest {
+		quantity := item.GetQuantity()
+		samples = append(samples, datasourcetypes.Sample{
+			Value:     quantity.AsApproximateFloat64(),
+			Timestamp: start.Add(time.Duration(n) * step).Unix(),
+		})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Timestamp < samples[j].Timestamp })
+	return samples
+}
+
+func convertWorkloadNameToPods(workloadName string, workloadKind string) string {
+	switch workloadKind {
+	case string(datasourcetypes.WorkloadDeployment):
+		return fmt.Sprintf("^%s-%s", regexp.QuoteMeta(workloadName), workloadSuffixRuleForDeployment)
+	}
+	return fmt.Sprintf("^%s-%s", regexp.QuoteMeta(workloadName), `.*`)
+}
+
+// storeClient gets metrics from the serving api of katalyst custom metric store
+type storeClient struct {
+	address *url.URL
+	client  *http.Client
+}
+
+func newStoreClient(address string, timeout time.Duration) (*storeClient, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid custom metric store address %v: %v", address, err)
+	}
+	return &storeClient{
+		address: u,
+		client:  &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (s *storeClient) GetMetric(ctx context.Context, namespace, metricName, objName string, gr *schema.GroupResource,
+	objSelector, metricSelector labels.Selector, latest bool,
+) ([]types.Metric, error) {
+	u := *s.address
+	u.Path = strings.TrimSuffix(u.Path, "/") + local.ServingGetPath
+
+	values := u.Query()
+	if len(namespace) > 0 {
+		values.Set(local.StoreGETParamNamespace, namespace)
+	}
+	if len(metricName) > 0 {
+		values.Set(local.StoreGETParamMetricName, metricName)
+	}
+	if metricSelector != nil && metricSelector.String() != "" {
+		values.Set(local.StoreGETParamMetricSelector, metricSelector.String())
+	}
+	if gr != nil {
+		values.Set(local.StoreGETParamObjectGR, gr.String())
+	}
+	if len(objName) > 0 {
+		values.Set(local.StoreGETParamObjectName, objName)
+	}
+	if objSelector != nil && objSelector.String() != "" {
+		values.Set(local.StoreGETParamMObjectSelector, objSelector.String())
+	}
+	if latest {
+		values.Set(local.StoreGETParamLatest, fmt.Sprintf("%v", latest))
+	}
+	u.RawQuery = values.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get metric from %v failed with status code %v", u.String(), resp.StatusCode)
+	}
+	return types.DecodeMetricList(resp.Body, metricName)
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package custommetric
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/custom-metric/store/data/types"
+	"github.com/kubewharf/katalyst-core/pkg/custom-metric/store/local"
+	datasourcetypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/datasource"
+)
+
+type fakeGetter struct {
+	metrics        []types.Metric
+	metricSelector string
+}
+
+func (f *fakeGetter) GetMetric(_ context.Context, _, _, _ string, _ *schema.GroupResource,
+	_, metricSelector labels.Selector, _ bool,
+) ([]types.Metric, error) {
+	f.metricSelector = metricSelector.String()
+	return f.metrics, nil
+}
+
+func newSeriesMetric(podName string, items ...*types.SeriesItem) *types.SeriesMetric {
+	m := types.NewSeriesMetric()
+	m.ObjectName = podName
+	m.Labels = map[string]string{"container": "c1"}
+	m.Values = items
+	return m
+}
+
+func newTestConfig() *controller.CustomMetricDatasourceConfig {
+	return &controller.CustomMetricDatasourceConfig{
+		Timeout:          time.Second,
+		CPUMetricName:    "pod_cpu_usage",
+		MemoryMetricName: "pod_memory_usage",
+	}
+}
+
+func TestCustomMetric_ConvertMetricToQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		metric  datasourcetypes.Metric
+		want    *datasourcetypes.CustomMetricQuery
+		wantErr bool
+	}{
+		{
+			name: "cpu of deployment",
+			metric: datasourcetypes.Metric{
+				Namespace:     "default",
+				Kind:          "Deployment",
+				WorkloadName:  "app",
+				ContainerName: "c1",
+				Resource:      v1.ResourceCPU,
+			},
+			want: &datasourcetypes.CustomMetricQuery{
+				Namespace:         "default",
+				MetricName:        "pod_cpu_usage",
+				MetricSelector:    "container=c1",
+				ObjectNamePattern: "^app-[a-z0-9]+-[a-z0-9]{5}$",
+			},
+		},
+		{
+			name: "memory with selectors",
+			metric: datasourcetypes.Metric{
+				Namespace:     "default",
+				Kind:          "StatefulSet",
+				WorkloadName:  "app",
+				ContainerName: "c1",
+				Resource:      v1.ResourceMemory,
+				Selectors:     `cluster="c"`,
+			},
+			want: &datasourcetypes.CustomMetricQuery{
+				Namespace:         "default",
+				MetricName:        "pod_memory_usage",
+				MetricSelector:    "container=c1,cluster=c",
+				ObjectNamePattern: "^app-.*",
+			},
+		},
+		{
+			name:    "unsupported resource",
+			metric:  datasourcetypes.Metric{Resource: v1.ResourceStorage},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := NewCustomMetricWithGetter(&fakeGetter{}, newTestConfig())
+			query, err := c.ConvertMetricToQuery(tt.metric)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, query.CustomMetric)
+		})
+	}
+}
+
+func TestCustomMetric_QueryTimeSeries(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(10000, 0)
+	getter := &fakeGetter{
+		metrics: []types.Metric{
+			newSeriesMetric("app-7d9f8b6c4d-abcde",
+				types.NewInternalItem(1, now.Add(-2*time.Hour).UnixMilli()),
+				types.NewInternalItem(2, now.Add(-time.Minute).UnixMilli()),
+				types.NewInternalItem(6, now.Add(-40*time.Second).UnixMilli()),
+				types.NewInternalItem(5, now.Add(-50*time.Second).UnixMilli()),
+			),
+			newSeriesMetric("app-7d9f8b6c4d-fghij",
+				types.NewInternalItem(3, now.Add(-30*time.Second).UnixMilli()),
+			),
+			newSeriesMetric("other-7d9f8b6c4d-abcde",
+				types.NewInternalItem(4, now.Add(-time.Minute).UnixMilli()),
+			),
+		},
+	}
+	c := NewCustomMetricWithGetter(getter, newTestConfig())
+
+	query, err := c.ConvertMetricToQuery(datasourcetypes.Metric{
+		Namespace:     "default",
+		Kind:          "Deployment",
+		WorkloadName:  "app",
+		ContainerName: "c1",
+		Resource:      v1.ResourceCPU,
+	})
+	require.NoError(t, err)
+
+	ts, err := c.QueryTimeSeries(query, now.Add(-time.Hour), now, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "container=c1", getter.metricSelector)
+	// samples are aligned to the end of each step, and only the latest one within a step is kept
+	assert.ElementsMatch(t, []datasourcetypes.Sample{
+		{Value: 2, Timestamp: now.Add(-time.Minute).Unix()},
+		{Value: 6, Timestamp: now.Unix()},
+		{Value: 3, Timestamp: now.Unix()},
+	}, ts.Samples)
+
+	ts, err = c.QueryTimeSeries(query, now.Add(-time.Hour), now, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 4, len(ts.Samples))
+
+	_, err = c.QueryTimeSeries(&datasourcetypes.Query{}, now.Add(-time.Hour), now, time.Minute)
+	assert.Error(t, err)
+}
+
+func TestStoreClient_GetMetric(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != local.ServingGetPath || r.URL.Query().Get(local.StoreGETParamMetricName) != "pod_cpu_usage" ||
+			r.URL.Query().Get(local.StoreGETParamObjectGR) != "pods" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		bytes, _ := json.Marshal([]types.Metric{newSeriesMetric("app-7d9f8b6c4d-abcde", types.NewInternalItem(1, 1000))})
+		_, _ = w.Write(bytes)
+	}))
+	defer server.Close()
+
+	client, err := newStoreClient(server.URL, time.Second)
+	require.NoError(t, err)
+
+	metricList, err := client.GetMetric(context.TODO(), "default", "pod_cpu_usage", "", &podGroupResource, nil, nil, false)
+	require.NoError(t, err)
+	require.Len(t, metricList, 1)
+	assert.Equal(t, "app-7d9f8b6c4d-abcde", metricList[0].GetObjectName())
+	assert.Equal(t, 1, metricList[0].Len())
+
+	_, err = client.GetMetric(context.TODO(), "default", "pod_memory_usage", "", &podGroupResource, nil, nil, false)
+	assert.Error(t, err)
+
+	_, err = NewCustomMetric(&controller.CustomMetricDatasourceConfig{})
+	assert.Error(t, err)
+}
@@ -23,11 +23,9 @@ import (
 	datasourcetypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/datasource"
 )
 
-type DatasourceType string
-
-const (
-	PrometheusDatasource DatasourceType = "Prometheus"
-)
+// DefaultDatasource is preferred when datasource is not specified for ResourceRecommend,
+// and the first registered datasource is used instead if it's not registered
+const DefaultDatasource = datasourcetypes.PrometheusDatasource
 
 type Datasource interface {
 	QueryTimeSeries(query *datasourcetypes.Query, start time.Time, end time.Time, step time.Duration) (*datasourcetypes.TimeSeries, error)
@@ -35,27 +33,58 @@ type Datasource interface {
 }
 
 type Proxy struct {
-	datasourceMap map[DatasourceType]Datasource
+	datasourceMap map[datasourcetypes.DatasourceType]Datasource
+	// registered keeps datasource names in the order they are registered
+	registered []datasourcetypes.DatasourceType
 }
 
 func NewProxy() *Proxy {
 	return &Proxy{
-		datasourceMap: make(map[DatasourceType]Datasource),
+		datasourceMap: make(map[datasourcetypes.DatasourceType]Datasource),
 	}
 }
 
-func (p *Proxy) RegisterDatasource(name DatasourceType, datasource Datasource) {
+func (p *Proxy) RegisterDatasource(name datasourcetypes.DatasourceType, datasource Datasource) {
+	if _, ok := p.datasourceMap[name]; !ok {
+		p.registered = append(p.registered, name)
+	}
 	p.datasourceMap[name] = datasource
 }
 
-func (p *Proxy) getDatasource(name DatasourceType) (Datasource, error) {
+// HasDatasource returns whether the datasource is registered, and the empty name
+// stands for the default datasource
+func (p *Proxy) HasDatasource(name datasourcetypes.DatasourceType) bool {
+	if name == "" {
+		name = p.getDefaultDatasourceName()
+	}
+	_, ok := p.datasourceMap[name]
+	return ok
+}
+
+// ListDatasources returns names of registered datasources in the order they are registered
+func (p *Proxy) ListDatasources() []datasourcetypes.DatasourceType {
+	return append([]datasourcetypes.DatasourceType{}, p.registered...)
+}
+
+// getDefaultDatasourceName returns DefaultDatasource if it's registered, otherwise the first registered one
+func (p *Proxy) getDefaultDatasourceName() datasourcetypes.DatasourceType {
+	if _, ok := p.datasourceMap[DefaultDatasource]; ok || len(p.registered) == 0 {
+		return DefaultDatasource
+	}
+	return p.registered[0]
+}
+
+func (p *Proxy) getDatasource(name datasourcetypes.DatasourceType) (Datasource, error) {
+	if name == "" {
+		name = p.getDefaultDatasourceName()
+	}
 	if datasource, ok := p.datasourceMap[name]; ok {
 		return datasource, nil
 	}
 	return nil, errors.New("datasource not found")
 }
 
-func (p *Proxy) QueryTimeSeries(DatasourceName DatasourceType, metric datasourcetypes.Metric, start time.Time, end time.Time, step time.Duration) (*datasourcetypes.TimeSeries, error) {
+func (p *Proxy) QueryTimeSeries(DatasourceName datasourcetypes.DatasourceType, metric datasourcetypes.Metric, start time.Time, end time.Time, step time.Duration) (*datasourcetypes.TimeSeries, error) {
 	datasource, err := p.getDatasource(DatasourceName)
 	if err != nil {
 		return nil, err
@@ -48,15 +48,15 @@ func TestProxy_QueryTimeSeries(t *testing.T) {
 
 	// Create Proxy with mock datasource
 	proxy := &Proxy{
-		datasourceMap: map[DatasourceType]Datasource{
-			DatasourceType("mock"): mockDatasource,
+		datasourceMap: map[datasourcetypes.DatasourceType]Datasource{
+			datasourcetypes.DatasourceType("mock"): mockDatasource,
 		},
 	}
 
 	// Define test cases
 	testCases := []struct {
 		name        string
-		datasource  DatasourceType
+		datasource  datasourcetypes.DatasourceType
 		metric      datasourcetypes.Metric
 		start       time.Time
 		end         time.Time
@@ -107,3 +107,21 @@ func TestProxy_QueryTimeSeries(t *testing.T) {
 		})
 	}
 }
+
+func TestProxy_DefaultDatasource(t *testing.T) {
+	t.Parallel()
+
+	proxy := NewProxy()
+	assert.False(t, proxy.HasDatasource(""))
+
+	proxy.RegisterDatasource(datasourcetypes.CustomMetricDatasource, &MockDatasource{})
+	proxy.RegisterDatasource(datasourcetypes.LocalFileDatasource, &MockDatasource{})
+	assert.Equal(t, []datasourcetypes.DatasourceType{datasourcetypes.CustomMetricDatasource, datasourcetypes.LocalFileDatasource}, proxy.ListDatasources())
+	assert.True(t, proxy.HasDatasource(""))
+	assert.False(t, proxy.HasDatasource(datasourcetypes.PrometheusDatasource))
+	// the first registered datasource is the default one if prometheus is not registered
+	assert.Equal(t, datasourcetypes.CustomMetricDatasource, proxy.getDefaultDatasourceName())
+
+	proxy.RegisterDatasource(datasourcetypes.PrometheusDatasource, &MockDatasource{})
+	assert.Equal(t, datasourcetypes.PrometheusDatasource, proxy.getDefaultDatasourceName())
+}
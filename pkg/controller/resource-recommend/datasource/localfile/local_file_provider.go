@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package localfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/datasource"
+	datasourcetypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/datasource"
+)
+
+// Snapshot is the content of local snapshot file, which is usually dumped from
+// other datasources, and is used to replay recommendations offline.
+type Snapshot struct {
+	Series []SnapshotSeries `json:"series"`
+}
+
+type SnapshotSeries struct {
+	Namespace     string            `json:"namespace"`
+	Kind          string            `json:"kind"`
+	WorkloadName  string            `json:"workloadName"`
+	ContainerName string            `json:"containerName"`
+	Resource      v1.ResourceName   `json:"resource"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	// Samples are sorted by timestamp in seconds
+	Samples []datasourcetypes.Sample `json:"samples"`
+}
+
+type localFile struct {
+	path string
+
+	mutex    sync.Mutex
+	modTime  time.Time
+	snapshot *Snapshot
+}
+
+// NewLocalFile returns a datasource which queries history usage from local json snapshot file,
+// and the snapshot will be reloaded if it's modified.
+func NewLocalFile(config *controller.LocalFileDatasourceConfig) (datasource.Datasource, error) {
+	if config == nil || config.Path == "" {
+		return nil, fmt.Errorf("local file path is empty")
+	}
+
+	l := &localFile{path: config.Path}
+	if _, err := l.getSnapshot(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *localFile) ConvertMetricToQuery(metric datasourcetypes.Metric) (*datasourcetypes.Query, error) {
+	if metric.Resource != v1.ResourceCPU && metric.Resource != v1.ResourceMemory {
+		return nil, fmt.Errorf("query for resource type %v is not supported", metric.Resource)
+	}
+
+	return &datasourcetypes.Query{
+		LocalFile: &datasourcetypes.LocalFileQuery{
+			Namespace:     metric.Namespace,
+			Kind:          metric.Kind,
+			WorkloadName:  metric.WorkloadName,
+			ContainerName: metric.ContainerName,
+			Resource:      metric.Resource,
+		},
+	}, nil
+}
+
+func (l *localFile) QueryTimeSeries(query *datasourcetypes.Query, start time.Time, end time.Time, _ time.Duration) (*datasourcetypes.TimeSeries, error) {
+	if query == nil || query.LocalFile == nil {
+		return nil, fmt.Errorf("local file query is empty")
+	}
+
+	snapshot, err := l.getSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	results := datasourcetypes.NewTimeSeries()
+	for _, series := range snapshot.Series {
+		if series.Namespace != query.LocalFile.Namespace || series.Kind != query.LocalFile.Kind ||
+			series.WorkloadName != query.LocalFile.WorkloadName || series.ContainerName != query.LocalFile.ContainerName ||
+			series.Resource != query.LocalFile.Resource {
+			continue
+		}
+
+		for key, val := range series.Labels {
+			results.AppendLabel(key, val)
+		}
+		for _, sample := range series.Samples {
+			if sample.Timestamp < start.Unix() || sample.Timestamp > end.Unix() {
+				continue
+			}
+			results.AppendSample(sample.Timestamp, sample.Value)
+		}
+	}
+	return results, nil
+}
+
+// getSnapshot returns the cached snapshot, and reloads it if the file has been modified
+func (l *localFile) getSnapshot() (*Snapshot, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	fileInfo, err := os.Stat(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("stat local file %v failed: %v", l.path, err)
+	}
+
+	if l.snapshot != nil && fileInfo.ModTime().Equal(l.modTime) {
+		return l.snapshot, nil
+	}
+
+	content, err := os.ReadFile(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("read local file %v failed: %v", l.path, err)
+	}
+
+	snapshot := &Snapshot{}
+	if err := json.Unmarshal(content, snapshot); err != nil {
+		return nil, fmt.Errorf("unmarshal local file %v failed: %v", l.path, err)
+	}
+
+	klog.InfoS("local file datasource snapshot loaded", "path", l.path, "series", len(snapshot.Series))
+	l.snapshot, l.modTime = snapshot, fileInfo.ModTime()
+	return l.snapshot, nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package localfile
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	datasourcetypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/datasource"
+)
+
+func writeSnapshot(t *testing.T, path string, snapshot *Snapshot) {
+	bytes, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, bytes, 0o644))
+}
+
+func TestLocalFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	writeSnapshot(t, path, &Snapshot{
+		Series: []SnapshotSeries{
+			{
+				Namespace:     "default",
+				Kind:          "Deployment",
+				WorkloadName:  "app",
+				ContainerName: "c1",
+				Resource:      v1.ResourceCPU,
+				Labels:        map[string]string{"cluster": "c"},
+				Samples: []datasourcetypes.Sample{
+					{Value: 1, Timestamp: 100},
+					{Value: 2, Timestamp: 200},
+					{Value: 3, Timestamp: 300},
+				},
+			},
+			{
+				Namespace:     "default",
+				Kind:          "Deployment",
+				WorkloadName:  "app",
+				ContainerName: "c1",
+				Resource:      v1.ResourceMemory,
+				Samples:       []datasourcetypes.Sample{{Value: 1024, Timestamp: 200}},
+			},
+		},
+	})
+
+	_, err := NewLocalFile(&controller.LocalFileDatasourceConfig{Path: filepath.Join(t.TempDir(), "not-exist")})
+	assert.Error(t, err)
+
+	l, err := NewLocalFile(&controller.LocalFileDatasourceConfig{Path: path})
+	require.NoError(t, err)
+
+	metric := datasourcetypes.Metric{
+		Namespace:     "default",
+		Kind:          "Deployment",
+		WorkloadName:  "app",
+		ContainerName: "c1",
+		Resource:      v1.ResourceCPU,
+	}
+	query, err := l.ConvertMetricToQuery(metric)
+	require.NoError(t, err)
+
+	ts, err := l.QueryTimeSeries(query, time.Unix(150, 0), time.Unix(300, 0), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, []datasourcetypes.Sample{{Value: 2, Timestamp: 200}, {Value: 3, Timestamp: 300}}, ts.Samples)
+	assert.Equal(t, map[string]string{"cluster": "c"}, ts.Labels)
+
+	// snapshot is reloaded after the file is modified
+	writeSnapshot(t, path, &Snapshot{})
+	modTime := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+	ts, err = l.QueryTimeSeries(query, time.Unix(150, 0), time.Unix(300, 0), time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, ts.Samples)
+
+	_, err = l.ConvertMetricToQuery(datasourcetypes.Metric{Resource: v1.ResourceStorage})
+	assert.Error(t, err)
+}
@@ -160,7 +160,7 @@ func TestProcessor_processTask1(t *testing.T) {
 	aggregateTasks2.Store(taskID, mockTask)
 
 	proxy := datasource.NewProxy()
-	proxy.RegisterDatasource(datasource.PrometheusDatasource, &MockDatasourceForProcessTasks{})
+	proxy.RegisterDatasource(datasourcetypes.PrometheusDatasource, &MockDatasourceForProcessTasks{})
 
 	type testFunc func()
 	type runFunc func()
@@ -242,7 +242,7 @@ func TestProcessor_ProcessTasks(t *testing.T) {
 	q := workqueue.NewNamedRateLimitingQueue(DefaultQueueRateLimiter, ProcessorName)
 	aggregateTasks := &sync.Map{}
 	proxy := datasource.NewProxy()
-	proxy.RegisterDatasource(datasource.PrometheusDatasource, &MockDatasource1ForProcessTasks{})
+	proxy.RegisterDatasource(datasourcetypes.PrometheusDatasource, &MockDatasource1ForProcessTasks{})
 
 	taskIDList := make([]processortypes.TaskID, 0)
 	for i := 0; i < DefaultConcurrentTaskNum+1; i++ {
@@ -124,7 +124,7 @@ func (t *HistogramTask) Run(ctx context.Context, datasourceProxy *datasource.Pro
 	}
 	ctx = log.SetKeysAndValues(ctx, "runSectionBegin", runSectionBegin.String(), "runSectionEnd", runSectionEnd.String())
 
-	timeSeries, err := datasourceProxy.QueryTimeSeries(datasource.PrometheusDatasource, t.metric, runSectionBegin, runSectionEnd, time.Minute)
+	timeSeries, err := datasourceProxy.QueryTimeSeries(datasourcetypes.DatasourceType(t.metric.Datasource), t.metric, runSectionBegin, runSectionEnd, time.Minute)
 	if err != nil {
 		log.ErrorS(ctx, err, "task handler error, query samples failed")
 		return 0, err
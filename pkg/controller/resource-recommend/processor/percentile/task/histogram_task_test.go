@@ -241,7 +241,7 @@ func TestHistogramTask_Run(t1 *testing.T) {
 				ctx: context.Background(),
 				datasourceProxy: func() *datasource.Proxy {
 					proxy := datasource.NewProxy()
-					proxy.RegisterDatasource(datasource.PrometheusDatasource, &mockDatasourceEmptyQuery{})
+					proxy.RegisterDatasource(datasourcetypes.PrometheusDatasource, &mockDatasourceEmptyQuery{})
 					return proxy
 				},
 			},
@@ -258,7 +258,7 @@ func TestHistogramTask_Run(t1 *testing.T) {
 				ctx: context.Background(),
 				datasourceProxy: func() *datasource.Proxy {
 					proxy := datasource.NewProxy()
-					proxy.RegisterDatasource(datasource.PrometheusDatasource, &mockDatasourcePanic{})
+					proxy.RegisterDatasource(datasourcetypes.PrometheusDatasource, &mockDatasourcePanic{})
 					return proxy
 				},
 			},
@@ -275,7 +275,7 @@ func TestHistogramTask_Run(t1 *testing.T) {
 				ctx: context.Background(),
 				datasourceProxy: func() *datasource.Proxy {
 					proxy := datasource.NewProxy()
-					proxy.RegisterDatasource(datasource.PrometheusDatasource, &mockDatasource{})
+					proxy.RegisterDatasource(datasourcetypes.PrometheusDatasource, &mockDatasource{})
 					return proxy
 				},
 			},
@@ -297,7 +297,7 @@ func TestHistogramTask_Run(t1 *testing.T) {
 				ctx: context.Background(),
 				datasourceProxy: func() *datasource.Proxy {
 					proxy := datasource.NewProxy()
-					proxy.RegisterDatasource(datasource.PrometheusDatasource, &mockDatasource{})
+					proxy.RegisterDatasource(datasourcetypes.PrometheusDatasource, &mockDatasource{})
 					return proxy
 				},
 			},
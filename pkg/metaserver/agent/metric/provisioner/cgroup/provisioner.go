@@ -18,40 +18,518 @@ package cgroup
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/procfs"
+	"k8s.io/apimachinery/pkg/util/errors"
+	clocks "k8s.io/utils/clock"
 
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/global"
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
 	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric/types"
 	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/pod"
 	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
+	cgroupmgr "github.com/kubewharf/katalyst-core/pkg/util/cgroup/manager"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
 	"github.com/kubewharf/katalyst-core/pkg/util/machine"
 	utilmetric "github.com/kubewharf/katalyst-core/pkg/util/metric"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+	procfsmgr "github.com/kubewharf/katalyst-core/pkg/util/procfs/manager"
+)
+
+const (
+	metricsNameCgroupGetSystemStatusFailed    = "cgroup_provisioner_get_system_status_failed"
+	metricsNameCgroupGetContainerStatusFailed = "cgroup_provisioner_get_container_status_failed"
+
+	cgroupProvisionerHealthCheckName = "cgroup_provisioner_sample"
+	cgroupProvisionTolerationTime    = 15 * time.Second
+
+	// foreignMetricFreshness is the time window in which metrics written by other
+	// provisioners (i.e. malachite) are regarded as valid; the cgroup provisioner
+	// works as a fallback, so it won't overwrite those fresh metrics.
+	foreignMetricFreshness = 30 * time.Second
 )
 
+// cgroupSubsystems are the subsystems needed to collect metrics in cgroupv1
+var cgroupSubsystems = map[string]struct{}{
+	"cpu":                     {},
+	"cpuacct":                 {},
+	common.CgroupSubsysMemory: {},
+	common.CgroupSubsysBlkIO:  {},
+	"pids":                    {},
+}
+
+// containerSample is the raw data sampled from cgroup, and
+// it is kept in memory to calculate rates between two samples.
+type containerSample struct {
+	sampleTime time.Time
+	cpu        common.CPUMetrics
+	memory     common.MemoryMetrics
+	io         common.IOMetrics
+}
+
+// systemSample is the raw data sampled from /proc
+type systemSample struct {
+	sampleTime time.Time
+	cpu        procfs.CPUStat
+}
+
 // NewCGroupMetricsProvisioner returns the default implementation of CGroup.
 func NewCGroupMetricsProvisioner(baseConf *global.BaseConfiguration, _ *metaserver.MetricConfiguration,
-	emitter metrics.MetricEmitter, _ pod.PodFetcher, metricStore *utilmetric.MetricStore, _ *machine.KatalystMachineInfo,
+	emitter metrics.MetricEmitter, fetcher pod.PodFetcher, metricStore *utilmetric.MetricStore, machineInfo *machine.KatalystMachineInfo,
 ) types.MetricsProvisioner {
 	return &CGroupMetricsProvisioner{
-		metricStore: metricStore,
-		emitter:     emitter,
-		baseConf:    baseConf,
+		metricStore:      metricStore,
+		emitter:          emitter,
+		baseConf:         baseConf,
+		podFetcher:       fetcher,
+		machineInfo:      machineInfo,
+		cgroupManager:    cgroupmgr.GetManager(),
+		procfsManager:    procfsmgr.GetProcFSManager(),
+		clock:            clocks.RealClock{},
+		containerSamples: make(map[string]map[string]*containerSample),
+		nodeUpdateTimes:  make(map[string]time.Time),
+
+		getContainerRelativeCgroupPath: common.GetContainerRelativeCgroupPath,
 	}
 }
 
+// CGroupMetricsProvisioner collects metrics from cgroup and procfs directly, and it
+// works as the fallback when malachite is not deployed or doesn't work properly.
 type CGroupMetricsProvisioner struct {
-	baseConf    *global.BaseConfiguration
-	metricStore *utilmetric.MetricStore
-	emitter     metrics.MetricEmitter
+	baseConf      *global.BaseConfiguration
+	metricStore   *utilmetric.MetricStore
+	emitter       metrics.MetricEmitter
+	podFetcher    pod.PodFetcher
+	machineInfo   *machine.KatalystMachineInfo
+	cgroupManager cgroupmgr.Manager
+	procfsManager procfsmgr.ProcFSManager
+	clock         clocks.Clock
+	startOnce     sync.Once
+
+	// containerSamples and systemSample are the previous samples, and
+	// containerSamples is keyed by pod uid and container name.
+	containerSamples map[string]map[string]*containerSample
+	systemSample     *systemSample
+
+	// nodeUpdateTimes records the time when node metrics are updated by this provisioner
+	// at last, and it is keyed by metric name.
+	nodeUpdateTimes map[string]time.Time
+
+	getContainerRelativeCgroupPath func(podUID, containerID string) (string, error)
 }
 
 func (m *CGroupMetricsProvisioner) Run(ctx context.Context) {
+	m.startOnce.Do(func() {
+		general.RegisterHeartbeatCheck(cgroupProvisionerHealthCheckName, cgroupProvisionTolerationTime,
+			general.HealthzCheckStateNotReady, cgroupProvisionTolerationTime)
+	})
 	m.sample(ctx)
 }
 
 func (m *CGroupMetricsProvisioner) sample(ctx context.Context) {
-	// todo, we should add sample logic here by cgroupManager
+	errList := make([]error, 0)
+
+	if err := m.updateSystemStats(); err != nil {
+		errList = append(errList, err)
+	}
+
+	if err := m.updatePodsCgroupData(ctx); err != nil {
+		errList = append(errList, err)
+	}
+
+	_ = general.UpdateHealthzStateByError(cgroupProvisionerHealthCheckName, errors.NewAggregate(errList))
+}
+
+// updateSystemStats collects node level metrics from /proc
+func (m *CGroupMetricsProvisioner) updateSystemStats() error {
+	now := m.clock.Now()
+	errList := make([]error, 0)
+
+	if !m.isForeignNodeMetric(consts.MetricCPUTotalSystem, now) {
+		if err := m.processSystemComputeData(now); err != nil {
+			errList = append(errList, err)
+			_ = m.emitter.StoreInt64(metricsNameCgroupGetSystemStatusFailed, 1, metrics.MetricTypeNameCount,
+				metrics.MetricTag{Key: "kind", Val: "compute"})
+		}
+	}
+
+	if !m.isForeignNodeMetric(consts.MetricMemTotalSystem, now) {
+		if err := m.processSystemMemoryData(now); err != nil {
+			errList = append(errList, err)
+			_ = m.emitter.StoreInt64(metricsNameCgroupGetSystemStatusFailed, 1, metrics.MetricTypeNameCount,
+				metrics.MetricTag{Key: "kind", Val: "memory"})
+		}
+	}
+
+	return errors.NewAggregate(errList)
+}
+
+func (m *CGroupMetricsProvisioner) processSystemComputeData(now time.Time) error {
+	stat, err := m.procfsManager.GetProcStat()
+	if err != nil {
+		return fmt.Errorf("get proc stat failed: %v", err)
+	}
+
+	cpuNum := float64(len(stat.CPU))
+	m.metricStore.SetNodeMetric(consts.MetricCPUTotalSystem, utilmetric.MetricData{Value: cpuNum, Time: &now})
+	m.nodeUpdateTimes[consts.MetricCPUTotalSystem] = now
+	m.metricStore.SetNodeMetric(consts.MetricProcsRunningSystem,
+		utilmetric.MetricData{Value: float64(stat.ProcessesRunning), Time: &now})
+
+	if prev := m.systemSample; prev != nil {
+		busyDelta := cpuBusyTime(stat.CPUTotal) - cpuBusyTime(prev.cpu)
+		totalDelta := cpuTotalTime(stat.CPUTotal) - cpuTotalTime(prev.cpu)
+		if totalDelta > 0 && busyDelta >= 0 {
+			ratio := busyDelta / totalDelta
+			m.metricStore.SetNodeMetric(consts.MetricCPUUsageSystem,
+				utilmetric.MetricData{Value: ratio * cpuNum, Time: &now})
+			m.metricStore.SetNodeMetric(consts.MetricCPUUsageRatioSystem,
+				utilmetric.MetricData{Value: ratio, Time: &now})
+		}
+	}
+	m.systemSample = &systemSample{sampleTime: now, cpu: stat.CPUTotal}
+
+	load, err := m.procfsManager.GetLoadAvg()
+	if err != nil {
+		return fmt.Errorf("get load avg failed: %v", err)
+	}
+	m.metricStore.SetNodeMetric(consts.MetricLoad1MinSystem, utilmetric.MetricData{Value: load.Load1, Time: &now})
+	m.metricStore.SetNodeMetric(consts.MetricLoad5MinSystem, utilmetric.MetricData{Value: load.Load5, Time: &now})
+	m.metricStore.SetNodeMetric(consts.MetricLoad15MinSystem, utilmetric.MetricData{Value: load.Load15, Time: &now})
+	return nil
+}
+
+func (m *CGroupMetricsProvisioner) processSystemMemoryData(now time.Time) error {
+	meminfo, err := m.procfsManager.GetMeminfo()
+	if err != nil {
+		return fmt.Errorf("get meminfo failed: %v", err)
+	}
+
+	// values in meminfo are in KiB
+	setMemMetric := func(metricName string, value *uint64) {
+		if value == nil {
+			return
+		}
+		m.metricStore.SetNodeMetric(metricName, utilmetric.MetricData{Value: float64(*value << 10), Time: &now})
+		m.nodeUpdateTimes[metricName] = now
+	}
+
+	setMemMetric(consts.MetricMemTotalSystem, meminfo.MemTotal)
+	setMemMetric(consts.MetricMemFreeSystem, meminfo.MemFree)
+	setMemMetric(consts.MetricMemAvailableSystem, meminfo.MemAvailable)
+	setMemMetric(consts.MetricMemShmemSystem, meminfo.Shmem)
+	setMemMetric(consts.MetricMemBufferSystem, meminfo.Buffers)
+	setMemMetric(consts.MetricMemPageCacheSystem, meminfo.Cached)
+	setMemMetric(consts.MetricMemActiveAnonSystem, meminfo.ActiveAnon)
+	setMemMetric(consts.MetricMemInactiveAnonSystem, meminfo.InactiveAnon)
+	setMemMetric(consts.MetricMemActiveFileSystem, meminfo.ActiveFile)
+	setMemMetric(consts.MetricMemInactiveFileSystem, meminfo.InactiveFile)
+	setMemMetric(consts.MetricMemDirtySystem, meminfo.Dirty)
+	setMemMetric(consts.MetricMemWritebackSystem, meminfo.Writeback)
+	setMemMetric(consts.MetricMemSwapTotalSystem, meminfo.SwapTotal)
+	setMemMetric(consts.MetricMemSwapFreeSystem, meminfo.SwapFree)
+	setMemMetric(consts.MetricMemSlabReclaimableSystem, meminfo.SReclaimable)
+
+	if meminfo.MemTotal != nil && meminfo.MemFree != nil {
+		used := *meminfo.MemTotal - *meminfo.MemFree
+		for _, v := range []*uint64{meminfo.Buffers, meminfo.Cached} {
+			if v != nil && used >= *v {
+				used -= *v
+			}
+		}
+		setMemMetric(consts.MetricMemUsedSystem, &used)
+	}
+	return nil
+}
+
+// updatePodsCgroupData collects container level metrics from cgroup
+func (m *CGroupMetricsProvisioner) updatePodsCgroupData(ctx context.Context) error {
+	pods, err := m.podFetcher.GetPodList(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("get pod list failed: %v", err)
+	}
+
+	now := m.clock.Now()
+	errList := make([]error, 0)
+	podUIDSet := make(map[string]bool)
+	for _, p := range pods {
+		if p == nil {
+			continue
+		}
+
+		podUID := string(p.UID)
+		podUIDSet[podUID] = true
+		for _, containerStatus := range p.Status.ContainerStatuses {
+			if containerStatus.ContainerID == "" {
+				continue
+			}
+
+			containerName := containerStatus.Name
+			if m.isForeignContainerMetric(podUID, containerName, now) {
+				general.InfofV(6, "[cgroup] metrics of %s/%s are provided by other provisioners, skip", podUID, containerName)
+				continue
+			}
+
+			containerID := native.TrimContainerIDPrefix(containerStatus.ContainerID)
+			if err := m.processContainerData(podUID, containerName, containerID, now); err != nil {
+				errList = append(errList, fmt.Errorf("pod %s container %s: %v", podUID, containerName, err))
+				_ = m.emitter.StoreInt64(metricsNameCgroupGetContainerStatusFailed, 1, metrics.MetricTypeNameCount)
+			}
+		}
+	}
+
+	for podUID := range m.containerSamples {
+		if !podUIDSet[podUID] {
+			delete(m.containerSamples, podUID)
+		}
+	}
+	m.metricStore.GCPodsMetric(podUIDSet)
+
+	return errors.NewAggregate(errList)
+}
+
+func (m *CGroupMetricsProvisioner) processContainerData(podUID, containerName, containerID string, now time.Time) error {
+	relCgroupPath, err := m.getContainerRelativeCgroupPath(podUID, containerID)
+	if err != nil {
+		return err
+	}
+
+	cgMetrics, err := m.cgroupManager.GetMetrics(relCgroupPath, cgroupSubsystems)
+	if err != nil {
+		return fmt.Errorf("get cgroup metrics failed: %v", err)
+	}
+
+	cur := &containerSample{sampleTime: now}
+	if cgMetrics.CPU != nil {
+		cur.cpu = *cgMetrics.CPU
+	}
+	if cgMetrics.Memory != nil {
+		cur.memory = *cgMetrics.Memory
+	}
+	if cgMetrics.IO != nil {
+		cur.io = *cgMetrics.IO
+	}
+
+	if m.containerSamples[podUID] == nil {
+		m.containerSamples[podUID] = make(map[string]*containerSample)
+	}
+	prev := m.containerSamples[podUID][containerName]
+	m.containerSamples[podUID][containerName] = cur
+
+	m.processContainerCPUData(podUID, containerName, relCgroupPath, prev, cur)
+	m.processContainerMemoryData(podUID, containerName, relCgroupPath, prev, cur)
+	m.processContainerBlkIOData(podUID, containerName, relCgroupPath, prev, cur)
+	return nil
+}
+
+func (m *CGroupMetricsProvisioner) processContainerCPUData(podUID, containerName, relCgroupPath string,
+	prev, cur *containerSample,
+) {
+	updateTime := cur.sampleTime
+	absCgroupPath := common.GetAbsCgroupPath(common.CgroupSubsysCPU, relCgroupPath)
+
+	var limit float64
+	cpuStats, err := m.cgroupManager.GetCPU(absCgroupPath)
+	if err != nil {
+		general.Warningf("[cgroup] get cpu stats of %s failed: %v", absCgroupPath, err)
+	} else if cpuStats != nil {
+		quota := float64(cpuStats.CpuQuota)
+		if cpuStats.CpuQuota > 0 && cpuStats.CpuPeriod > 0 {
+			limit = float64(cpuStats.CpuQuota) / float64(cpuStats.CpuPeriod)
+			m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricCPULimitContainer,
+				utilmetric.MetricData{Value: limit, Time: &updateTime})
+		} else {
+			quota = -1
+		}
+		m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricCPUQuotaContainer,
+			utilmetric.MetricData{Value: quota, Time: &updateTime})
+		m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricCPUPeriodContainer,
+			utilmetric.MetricData{Value: float64(cpuStats.CpuPeriod), Time: &updateTime})
+	}
+
+	m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricCPUNrThrottledContainer,
+		utilmetric.MetricData{Value: float64(cur.cpu.NrThrottled), Time: &updateTime})
+	m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricCPUNrPeriodContainer,
+		utilmetric.MetricData{Value: float64(cur.cpu.NrPeriods), Time: &updateTime})
+	// keep the same unit (us) with malachite
+	m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricCPUThrottledTimeContainer,
+		utilmetric.MetricData{Value: float64(cur.cpu.ThrottledTime / 1000), Time: &updateTime})
+
+	if prev != nil {
+		interval := cur.sampleTime.Sub(prev.sampleTime).Seconds()
+
+		// cpu usage is in nanoseconds, so the rate of it represents the actual cores
+		usage := rate(prev.cpu.UsageTotal, cur.cpu.UsageTotal, interval) / float64(time.Second)
+		m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricCPUUsageContainer,
+			utilmetric.MetricData{Value: usage, Time: &updateTime})
+		m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricCPUUsageUserContainer,
+			utilmetric.MetricData{Value: rate(prev.cpu.UsageUser, cur.cpu.UsageUser, interval) / float64(time.Second), Time: &updateTime})
+		m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricCPUUsageSysContainer,
+			utilmetric.MetricData{Value: rate(prev.cpu.UsageKernel, cur.cpu.UsageKernel, interval) / float64(time.Second), Time: &updateTime})
+		if limit > 0 {
+			m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricCPUUsageRatioContainer,
+				utilmetric.MetricData{Value: usage / limit, Time: &updateTime})
+		}
+
+		m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricCPUNrThrottledRateContainer,
+			utilmetric.MetricData{Value: rate(prev.cpu.NrThrottled, cur.cpu.NrThrottled, interval), Time: &updateTime})
+		m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricCPUNrPeriodRateContainer,
+			utilmetric.MetricData{Value: rate(prev.cpu.NrPeriods, cur.cpu.NrPeriods, interval), Time: &updateTime})
+		m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricCPUThrottledTimeRateContainer,
+			utilmetric.MetricData{Value: rate(prev.cpu.ThrottledTime/1000, cur.cpu.ThrottledTime/1000, interval), Time: &updateTime})
+	}
+
+	if pressure, err := m.cgroupManager.GetPressure(absCgroupPath, common.PressureResourceCPU); err == nil && pressure != nil {
+		m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricCPUPsiAvg10Container,
+			utilmetric.MetricData{Value: pressure.Some.Avg10, Time: &updateTime})
+		m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricCPUPsiAvg60Container,
+			utilmetric.MetricData{Value: pressure.Some.Avg60, Time: &updateTime})
+	}
+
+	m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricCPUUpdateTimeContainer,
+		utilmetric.MetricData{Value: float64(updateTime.Unix()), Time: &updateTime})
+}
+
+func (m *CGroupMetricsProvisioner) processContainerMemoryData(podUID, containerName, relCgroupPath string,
+	prev, cur *containerSample,
+) {
+	updateTime := cur.sampleTime
+	absCgroupPath := common.GetAbsCgroupPath(common.CgroupSubsysMemory, relCgroupPath)
+
+	memStats, err := m.cgroupManager.GetMemory(absCgroupPath)
+	if err != nil {
+		general.Warningf("[cgroup] get memory stats of %s failed: %v", absCgroupPath, err)
+	} else if memStats != nil {
+		m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricMemLimitContainer,
+			utilmetric.MetricData{Value: float64(memStats.Limit), Time: &updateTime})
+	}
+
+	mem := cur.memory
+	for metricName, value := range map[string]uint64{
+		consts.MetricMemUsageContainer:        mem.UsageUsage,
+		consts.MetricMemUsageKernContainer:    mem.KernelUsage,
+		consts.MetricMemRssContainer:          mem.RSS,
+		consts.MetricMemCacheContainer:        mem.Cache,
+		consts.MetricMemDirtyContainer:        mem.Dirty,
+		consts.MetricMemWritebackContainer:    mem.WriteBack,
+		consts.MetricMemSwapContainer:         mem.SwapUsage,
+		consts.MetricMemMappedContainer:       mem.Mapped,
+		consts.MetricMemActiveAnonContainer:   mem.ActiveAnon,
+		consts.MetricMemInactiveAnonContainer: mem.InactiveAnon,
+		consts.MetricMemActiveFileContainer:   mem.ActiveFile,
+		consts.MetricMemInactiveFileContainer: mem.InactiveFile,
+		consts.MetricMemPgfaultContainer:      mem.Pgfault,
+		consts.MetricMemPgmajfaultContainer:   mem.Pgmajfault,
+	} {
+		m.metricStore.SetContainerMetric(podUID, containerName, metricName,
+			utilmetric.MetricData{Value: float64(value), Time: &updateTime})
+	}
+
+	if prev != nil {
+		interval := cur.sampleTime.Sub(prev.sampleTime).Seconds()
+		m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricMemPgfaultRateContainer,
+			utilmetric.MetricData{Value: rate(prev.memory.Pgfault, mem.Pgfault, interval), Time: &updateTime})
+		m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricMemPgmajfaultRateContainer,
+			utilmetric.MetricData{Value: rate(prev.memory.Pgmajfault, mem.Pgmajfault, interval), Time: &updateTime})
+	}
+
+	if pressure, err := m.cgroupManager.GetPressure(absCgroupPath, common.PressureResourceMemory); err == nil && pressure != nil {
+		m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricMemPsiAvg60Container,
+			utilmetric.MetricData{Value: pressure.Some.Avg60, Time: &updateTime})
+	}
+
+	// per-numa memory is parsed from memory.numa_stat
+	if numaMemory, err := m.cgroupManager.GetNumaMemory(absCgroupPath); err == nil {
+		for numaID, stat := range numaMemory {
+			if stat == nil {
+				continue
+			}
+			m.metricStore.SetContainerNumaMetric(podUID, containerName, numaID, consts.MetricsMemTotalPerNumaContainer,
+				utilmetric.MetricData{Value: float64(stat.Anon + stat.File), Time: &updateTime})
+			m.metricStore.SetContainerNumaMetric(podUID, containerName, numaID, consts.MetricsMemAnonPerNumaContainer,
+				utilmetric.MetricData{Value: float64(stat.Anon), Time: &updateTime})
+			m.metricStore.SetContainerNumaMetric(podUID, containerName, numaID, consts.MetricsMemFilePerNumaContainer,
+				utilmetric.MetricData{Value: float64(stat.File), Time: &updateTime})
+		}
+	}
+
+	m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricMemUpdateTimeContainer,
+		utilmetric.MetricData{Value: float64(updateTime.Unix()), Time: &updateTime})
+}
+
+func (m *CGroupMetricsProvisioner) processContainerBlkIOData(podUID, containerName, relCgroupPath string,
+	prev, cur *containerSample,
+) {
+	updateTime := cur.sampleTime
+
+	if prev != nil {
+		interval := cur.sampleTime.Sub(prev.sampleTime).Seconds()
+		m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricBlkioReadIopsContainer,
+			utilmetric.MetricData{Value: rate(prev.io.ReadIOs, cur.io.ReadIOs, interval), Time: &updateTime})
+		m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricBlkioWriteIopsContainer,
+			utilmetric.MetricData{Value: rate(prev.io.WriteIOs, cur.io.WriteIOs, interval), Time: &updateTime})
+		m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricBlkioReadBpsContainer,
+			utilmetric.MetricData{Value: rate(prev.io.ReadBytes, cur.io.ReadBytes, interval), Time: &updateTime})
+		m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricBlkioWriteBpsContainer,
+			utilmetric.MetricData{Value: rate(prev.io.WriteBytes, cur.io.WriteBytes, interval), Time: &updateTime})
+	}
+
+	absCgroupPath := common.GetAbsCgroupPath(common.CgroupSubsysIO, relCgroupPath)
+	if pressure, err := m.cgroupManager.GetPressure(absCgroupPath, common.PressureResourceIO); err == nil && pressure != nil {
+		m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricBlkioPsiAvg10Container,
+			utilmetric.MetricData{Value: pressure.Some.Avg10, Time: &updateTime})
+		m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricBlkioPsiAvg60Container,
+			utilmetric.MetricData{Value: pressure.Some.Avg60, Time: &updateTime})
+	}
+
+	m.metricStore.SetContainerMetric(podUID, containerName, consts.MetricBlkioUpdateTimeContainer,
+		utilmetric.MetricData{Value: float64(updateTime.Unix()), Time: &updateTime})
+}
+
+// isForeignContainerMetric returns true if the container metrics are fresh and
+// written by other provisioners, and we should not overwrite them in this case.
+func (m *CGroupMetricsProvisioner) isForeignContainerMetric(podUID, containerName string, now time.Time) bool {
+	var lastUpdateTime time.Time
+	if sample, ok := m.containerSamples[podUID][containerName]; ok {
+		lastUpdateTime = sample.sampleTime
+	}
+
+	data, err := m.metricStore.GetContainerMetric(podUID, containerName, consts.MetricCPUUpdateTimeContainer)
+	return isForeignMetric(data, err, lastUpdateTime, now)
+}
+
+// isForeignNodeMetric returns true if the node metrics are fresh and written by other provisioners
+func (m *CGroupMetricsProvisioner) isForeignNodeMetric(metricName string, now time.Time) bool {
+	data, err := m.metricStore.GetNodeMetric(metricName)
+	return isForeignMetric(data, err, m.nodeUpdateTimes[metricName], now)
+}
+
+// isForeignMetric is the freshness check shared by node and container metrics, the metric is
+// regarded as written by others if it's fresh and not updated at lastUpdateTime by this provisioner.
+func isForeignMetric(data utilmetric.MetricData, err error, lastUpdateTime, now time.Time) bool {
+	if err != nil || data.Time == nil || now.Sub(*data.Time) > foreignMetricFreshness {
+		return false
+	}
+	return !data.Time.Equal(lastUpdateTime)
+}
+
+// rate returns the increasing rate per second of the given counter
+func rate(previous, current uint64, intervalInSec float64) float64 {
+	if intervalInSec <= 0 || current < previous {
+		return 0
+	}
+	return float64(current-previous) / intervalInSec
+}
+
+func cpuBusyTime(stat procfs.CPUStat) float64 {
+	return cpuTotalTime(stat) - stat.Idle - stat.Iowait
+}
 
-	// todo, we should also make sure that the self-collected metrics
-	//  should be checked firstly (whether malachite missed)
+func cpuTotalTime(stat procfs.CPUStat) float64 {
+	return stat.User + stat.Nice + stat.System + stat.Idle + stat.Iowait + stat.IRQ + stat.SoftIRQ + stat.Steal
 }
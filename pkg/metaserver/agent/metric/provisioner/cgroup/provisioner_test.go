@@ -0,0 +1,223 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cgroup
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/procfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/global"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/pod"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
+	cgroupmgr "github.com/kubewharf/katalyst-core/pkg/util/cgroup/manager"
+	utilmetric "github.com/kubewharf/katalyst-core/pkg/util/metric"
+	procfsmgr "github.com/kubewharf/katalyst-core/pkg/util/procfs/manager"
+)
+
+type fakeCgroupManager struct {
+	cgroupmgr.FakeCgroupManager
+	metrics *common.CgroupMetrics
+}
+
+func (f *fakeCgroupManager) GetMetrics(_ string, _ map[string]struct{}) (*common.CgroupMetrics, error) {
+	return f.metrics, nil
+}
+
+func (f *fakeCgroupManager) GetCPU(_ string) (*common.CPUStats, error) {
+	return &common.CPUStats{CpuQuota: 200000, CpuPeriod: 100000}, nil
+}
+
+func (f *fakeCgroupManager) GetMemory(_ string) (*common.MemoryStats, error) {
+	return &common.MemoryStats{Limit: 4 << 30}, nil
+}
+
+func (f *fakeCgroupManager) GetNumaMemory(_ string) (map[int]*common.MemoryNumaMetrics, error) {
+	return map[int]*common.MemoryNumaMetrics{0: {Anon: 30, File: 70}, 1: {Anon: 10}}, nil
+}
+
+func (f *fakeCgroupManager) GetPressure(_ string, _ common.PressureResource) (*common.PressureStats, error) {
+	return &common.PressureStats{Some: common.PressureAvg{Avg10: 1.5, Avg60: 0.5}}, nil
+}
+
+type fakeProcFSManager struct {
+	procfsmgr.ProcFSManager
+	stat procfs.Stat
+}
+
+func (f *fakeProcFSManager) GetProcStat() (procfs.Stat, error) {
+	return f.stat, nil
+}
+
+func (f *fakeProcFSManager) GetMeminfo() (procfs.Meminfo, error) {
+	total, free, buffers, cached := uint64(1024), uint64(256), uint64(64), uint64(128)
+	return procfs.Meminfo{MemTotal: &total, MemFree: &free, Buffers: &buffers, Cached: &cached}, nil
+}
+
+func (f *fakeProcFSManager) GetLoadAvg() (*procfs.LoadAvg, error) {
+	return &procfs.LoadAvg{Load1: 1, Load5: 2, Load15: 3}, nil
+}
+
+func TestCGroupMetricsProvisioner_Sample(t *testing.T) {
+	t.Parallel()
+
+	store := utilmetric.NewMetricStore()
+	podFetcher := &pod.PodFetcherStub{PodList: []*v1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{UID: types.UID("pod-1"), Name: "pod-1", Namespace: "default"},
+			Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{
+				{Name: "c1", ContainerID: "containerd://c1-id"},
+				{Name: "c2"},
+			}},
+		},
+	}}
+
+	cgManager := &fakeCgroupManager{metrics: &common.CgroupMetrics{
+		CPU:    &common.CPUMetrics{UsageTotal: 10 * uint64(time.Second), NrPeriods: 10},
+		Memory: &common.MemoryMetrics{RSS: 100, Cache: 200, Pgfault: 10},
+		IO:     &common.IOMetrics{ReadBytes: 1000, WriteIOs: 10},
+	}}
+	procfsManager := &fakeProcFSManager{stat: procfs.Stat{
+		CPU:      map[int64]procfs.CPUStat{0: {}, 1: {}},
+		CPUTotal: procfs.CPUStat{User: 10, Idle: 10},
+	}}
+
+	p := NewCGroupMetricsProvisioner(&global.BaseConfiguration{}, &metaserver.MetricConfiguration{},
+		metrics.DummyMetrics{}, podFetcher, store, nil).(*CGroupMetricsProvisioner)
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	p.cgroupManager = cgManager
+	p.procfsManager = procfsManager
+	p.clock = fakeClock
+	p.getContainerRelativeCgroupPath = func(podUID, containerID string) (string, error) {
+		return "/kubepods/pod" + podUID + "/" + containerID, nil
+	}
+
+	p.Run(context.Background())
+
+	data, err := store.GetContainerMetric("pod-1", "c1", consts.MetricMemRssContainer)
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), data.Value)
+	data, err = store.GetContainerMetric("pod-1", "c1", consts.MetricCPULimitContainer)
+	require.NoError(t, err)
+	assert.Equal(t, float64(2), data.Value)
+	data, err = store.GetContainerMetric("pod-1", "c1", consts.MetricBlkioPsiAvg10Container)
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, data.Value)
+	data, err = store.GetContainerNumaMetric("pod-1", "c1", 0, consts.MetricsMemTotalPerNumaContainer)
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), data.Value)
+	data, err = store.GetContainerNumaMetric("pod-1", "c1", 1, consts.MetricsMemAnonPerNumaContainer)
+	require.NoError(t, err)
+	assert.Equal(t, float64(10), data.Value)
+	// rates are not available for the first sample
+	_, err = store.GetContainerMetric("pod-1", "c1", consts.MetricCPUUsageContainer)
+	assert.Error(t, err)
+	// containers without id are skipped
+	_, err = store.GetContainerMetric("pod-1", "c2", consts.MetricMemRssContainer)
+	assert.Error(t, err)
+
+	data, err = store.GetNodeMetric(consts.MetricMemUsedSystem)
+	require.NoError(t, err)
+	assert.Equal(t, float64(576<<10), data.Value)
+
+	fakeClock.Step(10 * time.Second)
+	cgManager.metrics = &common.CgroupMetrics{
+		CPU:    &common.CPUMetrics{UsageTotal: 20 * uint64(time.Second), NrPeriods: 20},
+		Memory: &common.MemoryMetrics{RSS: 100, Cache: 200, Pgfault: 110},
+		IO:     &common.IOMetrics{ReadBytes: 11000, WriteIOs: 110},
+	}
+	procfsManager.stat.CPUTotal = procfs.CPUStat{User: 40, Idle: 20}
+
+	p.Run(context.Background())
+
+	for metricName, expected := range map[string]float64{
+		consts.MetricCPUUsageContainer:           1,
+		consts.MetricCPUUsageRatioContainer:      0.5,
+		consts.MetricCPUNrPeriodRateContainer:    1,
+		consts.MetricMemPgfaultRateContainer:     10,
+		consts.MetricBlkioReadBpsContainer:       1000,
+		consts.MetricBlkioWriteIopsContainer:     10,
+		consts.MetricCPUNrThrottledRateContainer: 0,
+	} {
+		data, err = store.GetContainerMetric("pod-1", "c1", metricName)
+		require.NoError(t, err, metricName)
+		assert.Equal(t, expected, data.Value, metricName)
+	}
+
+	data, err = store.GetNodeMetric(consts.MetricCPUUsageRatioSystem)
+	require.NoError(t, err)
+	assert.Equal(t, 0.75, data.Value)
+
+	// metrics written by other provisioners won't be overwritten
+	fakeClock.Step(time.Second)
+	now := fakeClock.Now()
+	store.SetContainerMetric("pod-1", "c1", consts.MetricCPUUpdateTimeContainer,
+		utilmetric.MetricData{Value: float64(now.Unix()), Time: &now})
+	store.SetContainerMetric("pod-1", "c1", consts.MetricMemRssContainer,
+		utilmetric.MetricData{Value: 1, Time: &now})
+	p.Run(context.Background())
+	data, err = store.GetContainerMetric("pod-1", "c1", consts.MetricMemRssContainer)
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), data.Value)
+
+	// node metrics are checked separately, so memory metrics are still updated
+	// when only compute metrics are provided by other provisioners
+	fakeClock.Step(time.Second)
+	foreignTime := fakeClock.Now()
+	store.SetNodeMetric(consts.MetricCPUTotalSystem, utilmetric.MetricData{Value: 64, Time: &foreignTime})
+	for i := 0; i < 2; i++ {
+		p.Run(context.Background())
+		fakeClock.Step(time.Second)
+	}
+	data, err = store.GetNodeMetric(consts.MetricCPUTotalSystem)
+	require.NoError(t, err)
+	assert.Equal(t, float64(64), data.Value)
+	data, err = store.GetNodeMetric(consts.MetricMemUsedSystem)
+	require.NoError(t, err)
+	assert.Equal(t, foreignTime.Add(time.Second), *data.Time)
+
+	// metrics of deleted pods should be cleared
+	podFetcher.PodList = nil
+	p.Run(context.Background())
+	_, err = store.GetContainerMetric("pod-1", "c1", consts.MetricMemRssContainer)
+	assert.Error(t, err)
+	assert.Empty(t, p.containerSamples)
+
+	// failures of containers are returned
+	podFetcher.PodList = []*v1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{UID: types.UID("pod-2"), Name: "pod-2", Namespace: "default"},
+		Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{
+			{Name: "c1", ContainerID: "containerd://c1-id"},
+		}},
+	}}
+	p.getContainerRelativeCgroupPath = func(podUID, containerID string) (string, error) {
+		return "", fmt.Errorf("cgroup path of %s not found", containerID)
+	}
+	assert.Error(t, p.updatePodsCgroupData(context.Background()))
+}
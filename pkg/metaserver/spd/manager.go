@@ -57,6 +57,9 @@ type ServiceProfilingManager interface {
 	// ServiceSystemPerformanceTarget returns the system performance target for the given pod
 	ServiceSystemPerformanceTarget(ctx context.Context, podMeta metav1.ObjectMeta) (IndicatorTarget, error)
 
+	// ServiceBusinessIndicators returns the business indicator targets and their current values for the given pod
+	ServiceBusinessIndicators(ctx context.Context, podMeta metav1.ObjectMeta) (IndicatorTarget, map[string]float64, error)
+
 	// ServiceBaseline returns whether this pod is baseline
 	ServiceBaseline(ctx context.Context, podMeta metav1.ObjectMeta) (bool, error)
 
@@ -79,6 +82,9 @@ type DummyPodServiceProfile struct {
 	PerformanceLevel PerformanceLevel
 	Score            float64
 	AggregatedMetric []resource.Quantity
+
+	BusinessIndicatorTarget IndicatorTarget
+	BusinessIndicatorValue  map[string]float64
 }
 
 type DummyServiceProfilingManager struct {
@@ -127,6 +133,14 @@ func (d *DummyServiceProfilingManager) ServiceSystemPerformanceTarget(_ context.
 	return IndicatorTarget{}, nil
 }
 
+func (d *DummyServiceProfilingManager) ServiceBusinessIndicators(_ context.Context, podMeta metav1.ObjectMeta) (IndicatorTarget, map[string]float64, error) {
+	profile, ok := d.podProfiles[podMeta.UID]
+	if !ok {
+		return IndicatorTarget{}, map[string]float64{}, nil
+	}
+	return profile.BusinessIndicatorTarget, profile.BusinessIndicatorValue, nil
+}
+
 func (d *DummyServiceProfilingManager) Run(_ context.Context) {}
 
 var _ ServiceProfilingManager = &DummyServiceProfilingManager{}
@@ -298,6 +312,26 @@ func (m *serviceProfilingManager) ServiceSystemPerformanceTarget(ctx context.Con
 	return util.GetServiceSystemIndicatorTarget(spd)
 }
 
+// ServiceBusinessIndicators gets the service business indicator targets and current values by spd
+func (m *serviceProfilingManager) ServiceBusinessIndicators(ctx context.Context, podMeta metav1.ObjectMeta) (IndicatorTarget, map[string]float64, error) {
+	spd, err := m.fetcher.GetSPD(ctx, podMeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	indicatorTarget, err := util.GetServiceBusinessIndicatorTarget(spd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	indicatorValue, err := util.GetServiceBusinessIndicatorValue(spd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return indicatorTarget, indicatorValue, nil
+}
+
 func (m *serviceProfilingManager) Run(ctx context.Context) {
 	m.fetcher.Run(ctx)
 }
@@ -432,6 +432,91 @@ func Test_serviceProfilingManager_ServiceSystemPerformanceTarget(t *testing.T) {
 	}
 }
 
+func Test_serviceProfilingManager_ServiceBusinessIndicators(t *testing.T) {
+	t.Parallel()
+
+	spd := &workloadapis.ServiceProfileDescriptor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "spd-1",
+			Namespace: "default",
+			Annotations: map[string]string{
+				pkgconsts.ServiceProfileDescriptorAnnotationKeyConfigHash: "3c7e3ff3f218",
+			},
+		},
+		Spec: workloadapis.ServiceProfileDescriptorSpec{
+			BusinessIndicator: []workloadapis.ServiceBusinessIndicatorSpec{
+				{
+					Name: workloadapis.ServiceBusinessIndicatorNameRPCLatency,
+					Indicators: []workloadapis.Indicator{
+						{
+							IndicatorLevel: workloadapis.IndicatorLevelUpperBound,
+							Value:          100,
+						},
+					},
+				},
+			},
+		},
+		Status: workloadapis.ServiceProfileDescriptorStatus{
+			BusinessStatus: []workloadapis.ServiceBusinessIndicatorStatus{
+				{
+					Name:    workloadapis.ServiceBusinessIndicatorNameRPCLatency,
+					Current: pointer.Float32(40),
+				},
+			},
+		},
+	}
+	cncObj := &v1alpha1.CustomNodeConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-1",
+		},
+		Status: v1alpha1.CustomNodeConfigStatus{
+			ServiceProfileConfigList: []v1alpha1.TargetConfig{
+				{
+					ConfigName:      "spd-1",
+					ConfigNamespace: "default",
+					Hash:            "3c7e3ff3f218",
+				},
+			},
+		},
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod-1",
+			Namespace: "default",
+			Annotations: map[string]string{
+				consts.PodAnnotationSPDNameKey: "spd-1",
+			},
+		},
+	}
+
+	dir, err := ioutil.TempDir("", "checkpoint-Test_serviceProfilingManager_ServiceBusinessIndicators")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	conf := generateTestConfiguration(t, "node-1", dir)
+	genericCtx, err := katalyst_base.GenerateFakeGenericContext(nil, []runtime.Object{spd, cncObj})
+	require.NoError(t, err)
+
+	cncFetcher := cnc.NewCachedCNCFetcher(conf.BaseConfiguration, conf.CNCConfiguration, genericCtx.Client.InternalClient.ConfigV1alpha1().CustomNodeConfigs())
+	s, err := NewSPDFetcher(genericCtx.Client, metrics.DummyMetrics{}, cncFetcher, conf)
+	require.NoError(t, err)
+	require.NotNil(t, s)
+
+	m := NewServiceProfilingManager(s)
+
+	// first get spd add spd key to cache
+	_, _ = s.GetSPD(context.Background(), pod.ObjectMeta)
+	go m.Run(context.Background())
+	time.Sleep(1 * time.Second)
+
+	target, value, err := m.ServiceBusinessIndicators(context.Background(), pod.ObjectMeta)
+	require.NoError(t, err)
+	require.Contains(t, target, string(workloadapis.ServiceBusinessIndicatorNameRPCLatency))
+	require.NotNil(t, target[string(workloadapis.ServiceBusinessIndicatorNameRPCLatency)].UpperBound)
+	require.Equal(t, 100.0, *target[string(workloadapis.ServiceBusinessIndicatorNameRPCLatency)].UpperBound)
+	require.Equal(t, map[string]float64{string(workloadapis.ServiceBusinessIndicatorNameRPCLatency): 40}, value)
+}
+
 func Test_serviceProfilingManager_ServiceExtendedIndicator(t *testing.T) {
 	t.Parallel()
 
@@ -31,6 +31,8 @@ type extendedCache struct {
 	// This mutex guards all fields within this extendedCache struct.
 	mu    sync.RWMutex
 	nodes map[string]*NodeInfo
+	// gangs are pod groups being reserved, keyed by namespace/name of the pod group
+	gangs map[string]*gangInfo
 }
 
 var cache *extendedCache
@@ -38,6 +40,7 @@ var cache *extendedCache
 func init() {
 	cache = &extendedCache{
 		nodes: make(map[string]*NodeInfo),
+		gangs: make(map[string]*gangInfo),
 	}
 }
 
@@ -120,6 +123,10 @@ func (cache *extendedCache) ReserveNodeResource(nodeName string, pod *v1.Pod) {
 	cache.mu.Lock()
 	defer cache.mu.Unlock()
 
+	cache.reserveNodeResource(nodeName, pod)
+}
+
+func (cache *extendedCache) reserveNodeResource(nodeName string, pod *v1.Pod) {
 	nodeInfo, ok := cache.nodes[nodeName]
 	if !ok {
 		nodeInfo = NewNodeInfo()
@@ -133,6 +140,10 @@ func (cache *extendedCache) UnreserveNodeResource(nodeName string, pod *v1.Pod)
 	cache.mu.Lock()
 	defer cache.mu.Unlock()
 
+	cache.unreserveNodeResource(nodeName, pod)
+}
+
+func (cache *extendedCache) unreserveNodeResource(nodeName string, pod *v1.Pod) {
 	nodeInfo, ok := cache.nodes[nodeName]
 	if !ok {
 		klog.Warningf("UnreserveNodeResource fail, node %v not exist in extendedCache", nodeName)
@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// gangMember is a pod of a pod group whose resources have been reserved on the node
+type gangMember struct {
+	nodeName string
+	pod      *v1.Pod
+}
+
+// gangInfo tracks the reserved members of a pod group, and all of them are rolled back
+// together if the pod group fails to be committed.
+type gangInfo struct {
+	minMember int
+	// members are keyed by namespace/name of pods, which is consistent with assumed pods
+	members map[string]*gangMember
+}
+
+// ReserveGangPodResource reserves resources of the pod on the node as a member of the pod group,
+// and minMember is the number of members to be reserved before the pod group can be committed.
+func (cache *extendedCache) ReserveGangPodResource(gang string, minMember int, nodeName string, pod *v1.Pod) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	g, ok := cache.gangs[gang]
+	if !ok {
+		g = &gangInfo{members: make(map[string]*gangMember)}
+		cache.gangs[gang] = g
+	}
+	// the latest min member is used, since members may have been bound since the pod group is created
+	g.minMember = minMember
+
+	key := pod.Namespace + "/" + pod.Name
+	if member, ok := g.members[key]; ok {
+		cache.unreserveNodeResource(member.nodeName, member.pod)
+	}
+	cache.reserveNodeResource(nodeName, pod)
+	g.members[key] = &gangMember{nodeName: nodeName, pod: pod}
+}
+
+// CommitGang returns true if enough members of the pod group have been reserved, and the pod
+// group is forgotten after committed, so that its reservations won't be rolled back anymore.
+func (cache *extendedCache) CommitGang(gang string) bool {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	g, ok := cache.gangs[gang]
+	if !ok || len(g.members) < g.minMember {
+		return false
+	}
+
+	delete(cache.gangs, gang)
+	return true
+}
+
+// UnreserveGangPodResource unreserves resources of the pod, and if the pod is a member of the
+// pod group not committed yet, reservations of all its other members are rolled back as well
+// and returned, so that the pod group is never partially placed.
+func (cache *extendedCache) UnreserveGangPodResource(gang string, nodeName string, pod *v1.Pod) []*v1.Pod {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	key := pod.Namespace + "/" + pod.Name
+	g, ok := cache.gangs[gang]
+	if !ok {
+		cache.unreserveNodeResource(nodeName, pod)
+		return nil
+	}
+	// the pod may have been rolled back with a previous attempt of the pod group, and its stale
+	// unreserve must not roll back members reserved after that
+	if _, ok := g.members[key]; !ok {
+		cache.unreserveNodeResource(nodeName, pod)
+		return nil
+	}
+	delete(cache.gangs, gang)
+
+	rollback := make([]*v1.Pod, 0, len(g.members))
+	for memberKey, member := range g.members {
+		cache.unreserveNodeResource(member.nodeName, member.pod)
+		if memberKey != key {
+			rollback = append(rollback, member.pod)
+		}
+	}
+	klog.V(4).InfoS("Rollback pod group reservation", "podGroup", gang, "pod", klog.KObj(pod), "rollback", len(rollback))
+	return rollback
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+)
+
+const (
+	defaultNodeMetricsExpiredSeconds = 180
+	defaultAvgWindow                 = 15 * time.Minute
+	defaultPercentileWindow          = time.Hour
+	defaultPercentileAggregator      = v1alpha1.AggregatorP95
+)
+
+var (
+	defaultUsageThresholds = map[v1.ResourceName]int64{
+		v1.ResourceCPU:    80,
+		v1.ResourceMemory: 90,
+	}
+	defaultResourceWeights = map[v1.ResourceName]int64{
+		v1.ResourceCPU:    1,
+		v1.ResourceMemory: 1,
+	}
+	defaultEstimatedScalingFactors = map[v1.ResourceName]int64{
+		v1.ResourceCPU:    85,
+		v1.ResourceMemory: 70,
+	}
+)
+
+// LoadAwareArgs holds arguments used to configure LoadAware plugin, and all
+// thresholds and scaling factors are in percent.
+type LoadAwareArgs struct {
+	// NodeMetricsExpiredSeconds is the max age of load profiles in npd, and nodes
+	// with stale profiles are neither filtered nor preferred by their load.
+	NodeMetricsExpiredSeconds *int64 `json:"nodeMetricsExpiredSeconds,omitempty"`
+
+	// UsageThresholds filter nodes whose avg usage in AvgWindow exceeds the percentage of allocatable.
+	UsageThresholds map[v1.ResourceName]int64 `json:"usageThresholds,omitempty"`
+	// PercentileUsageThresholds filter nodes whose percentile usage in PercentileWindow exceeds the
+	// percentage of allocatable, and it's disabled by default.
+	PercentileUsageThresholds map[v1.ResourceName]int64 `json:"percentileUsageThresholds,omitempty"`
+
+	AvgWindow            *metav1.Duration    `json:"avgWindow,omitempty"`
+	PercentileWindow     *metav1.Duration    `json:"percentileWindow,omitempty"`
+	PercentileAggregator v1alpha1.Aggregator `json:"percentileAggregator,omitempty"`
+
+	// ResourceWeights are weights of resources when scoring nodes by their avg usage.
+	ResourceWeights map[v1.ResourceName]int64 `json:"resourceWeights,omitempty"`
+	// EstimatedScalingFactors estimate usage of pods not reported in npd yet by their requests.
+	EstimatedScalingFactors map[v1.ResourceName]int64 `json:"estimatedScalingFactors,omitempty"`
+}
+
+// SetDefaultLoadAwareArgs fills up unset fields with default values
+func SetDefaultLoadAwareArgs(args *LoadAwareArgs) {
+	if args.NodeMetricsExpiredSeconds == nil {
+		seconds := int64(defaultNodeMetricsExpiredSeconds)
+		args.NodeMetricsExpiredSeconds = &seconds
+	}
+	if args.UsageThresholds == nil {
+		args.UsageThresholds = defaultUsageThresholds
+	}
+	if args.AvgWindow == nil {
+		args.AvgWindow = &metav1.Duration{Duration: defaultAvgWindow}
+	}
+	if args.PercentileWindow == nil {
+		args.PercentileWindow = &metav1.Duration{Duration: defaultPercentileWindow}
+	}
+	if args.PercentileAggregator == "" {
+		args.PercentileAggregator = defaultPercentileAggregator
+	}
+	if args.ResourceWeights == nil {
+		args.ResourceWeights = defaultResourceWeights
+	}
+	if args.EstimatedScalingFactors == nil {
+		args.EstimatedScalingFactors = defaultEstimatedScalingFactors
+	}
+}
+
+// ValidateLoadAwareArgs validates args after defaulting
+func ValidateLoadAwareArgs(args *LoadAwareArgs) error {
+	if *args.NodeMetricsExpiredSeconds <= 0 {
+		return fmt.Errorf("nodeMetricsExpiredSeconds should be positive, got %v", *args.NodeMetricsExpiredSeconds)
+	}
+	if args.AvgWindow.Duration <= 0 || args.PercentileWindow.Duration <= 0 {
+		return fmt.Errorf("windows should be positive, got avg %v and percentile %v", args.AvgWindow, args.PercentileWindow)
+	}
+
+	for name, thresholds := range map[string]map[v1.ResourceName]int64{
+		"usageThresholds":           args.UsageThresholds,
+		"percentileUsageThresholds": args.PercentileUsageThresholds,
+		"estimatedScalingFactors":   args.EstimatedScalingFactors,
+	} {
+		for resourceName, value := range thresholds {
+			if _, ok := usageMetricNames[resourceName]; !ok {
+				return fmt.Errorf("%v: unsupported resource %v", name, resourceName)
+			}
+			if value < 0 || value > 100 {
+				return fmt.Errorf("%v: %v of %v should be in [0, 100]", name, value, resourceName)
+			}
+		}
+	}
+
+	for resourceName, weight := range args.ResourceWeights {
+		if _, ok := usageMetricNames[resourceName]; !ok {
+			return fmt.Errorf("resourceWeights: unsupported resource %v", resourceName)
+		}
+		if weight <= 0 {
+			return fmt.Errorf("resourceWeights: weight %v of %v should be positive", weight, resourceName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/util"
+)
+
+var cache *loadAwareCache
+
+func init() {
+	cache = NewCache()
+}
+
+// UsageKey identifies a load profile of node, e.g. avg cpu usage in last 15m
+type UsageKey struct {
+	MetricName string
+	Aggregator v1alpha1.Aggregator
+	Window     time.Duration
+}
+
+// NodeLoad is a snapshot of node load profiles in npd, and pods assumed or bound to
+// the node recently, whose usage isn't fully reflected in the profiles yet.
+type NodeLoad struct {
+	Usages      map[UsageKey]float64
+	UpdateTime  time.Time
+	AssumedPods []*v1.Pod
+}
+
+type assumedPod struct {
+	pod        *v1.Pod
+	assumeTime time.Time
+	// ttl is the duration after which the usage of pod is fully reflected in load profiles
+	ttl time.Duration
+}
+
+type nodeCache struct {
+	usages      map[UsageKey]float64
+	updateTime  time.Time
+	assumedPods map[types.UID]*assumedPod
+}
+
+func newNodeCache() *nodeCache {
+	return &nodeCache{
+		usages:      make(map[UsageKey]float64),
+		assumedPods: make(map[types.UID]*assumedPod),
+	}
+}
+
+// loadAwareCache stores node load profiles from npd and pods assumed by scheduler
+type loadAwareCache struct {
+	sync.RWMutex
+	nodeCaches map[string]*nodeCache
+}
+
+func NewCache() *loadAwareCache {
+	return &loadAwareCache{
+		nodeCaches: make(map[string]*nodeCache),
+	}
+}
+
+func GetCache() *loadAwareCache {
+	return cache
+}
+
+// AddOrUpdateNPD refreshes load profiles of the node, and forgets assumed pods whose
+// usage is fully reflected in the profiles, i.e. the profiles are updated after ttl since
+// it's assumed. Pods reported in npd are still kept before that, since the aggregated
+// profiles only cover part of their usage until the whole window has passed.
+func (c *loadAwareCache) AddOrUpdateNPD(npd *v1alpha1.NodeProfileDescriptor) {
+	usages := make(map[UsageKey]float64)
+	var updateTime time.Time
+	for _, metric := range util.ExtractNPDScopedNodeMetrics(&npd.Status, consts.NPDScopeLoadAware) {
+		if metric.Aggregator == nil || metric.Window == nil {
+			continue
+		}
+
+		key := UsageKey{MetricName: metric.MetricName, Aggregator: *metric.Aggregator, Window: metric.Window.Duration}
+		usages[key] = float64(metric.Value.MilliValue()) / 1000
+		if metric.Timestamp.Time.After(updateTime) {
+			updateTime = metric.Timestamp.Time
+		}
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	n, ok := c.nodeCaches[npd.Name]
+	if !ok {
+		n = newNodeCache()
+		c.nodeCaches[npd.Name] = n
+	}
+	n.usages = usages
+	n.updateTime = updateTime
+
+	for uid, p := range n.assumedPods {
+		if !updateTime.Before(p.assumeTime.Add(p.ttl)) {
+			delete(n.assumedPods, uid)
+		}
+	}
+}
+
+func (c *loadAwareCache) RemoveNPD(npd *v1alpha1.NodeProfileDescriptor) {
+	c.Lock()
+	defer c.Unlock()
+
+	n, ok := c.nodeCaches[npd.Name]
+	if !ok {
+		return
+	}
+
+	// keep assumed pods, since npd may be recreated later
+	n.usages = make(map[UsageKey]float64)
+	n.updateTime = time.Time{}
+
+	if len(n.assumedPods) == 0 {
+		delete(c.nodeCaches, npd.Name)
+	}
+}
+
+// AssumePod records the pod as running on the node since assumeTime, and its usage
+// is estimated until ttl has passed
+func (c *loadAwareCache) AssumePod(pod *v1.Pod, nodeName string, assumeTime time.Time, ttl time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+
+	n, ok := c.nodeCaches[nodeName]
+	if !ok {
+		n = newNodeCache()
+		c.nodeCaches[nodeName] = n
+	}
+	n.assumedPods[pod.UID] = &assumedPod{pod: pod, assumeTime: assumeTime, ttl: ttl}
+}
+
+// ForgetPod removes the pod from assumed pods of the node, e.g. when it's unreserved or deleted
+func (c *loadAwareCache) ForgetPod(pod *v1.Pod, nodeName string) {
+	c.Lock()
+	defer c.Unlock()
+
+	n, ok := c.nodeCaches[nodeName]
+	if !ok {
+		return
+	}
+	delete(n.assumedPods, pod.UID)
+
+	if len(n.assumedPods) == 0 && n.updateTime.IsZero() {
+		delete(c.nodeCaches, nodeName)
+	}
+}
+
+// GetNodeLoad returns load snapshot of the node, and false if there are neither
+// load profiles nor assumed pods of it.
+func (c *loadAwareCache) GetNodeLoad(nodeName string) (*NodeLoad, bool) {
+	c.RLock()
+	defer c.RUnlock()
+
+	n, ok := c.nodeCaches[nodeName]
+	if !ok {
+		return nil, false
+	}
+
+	load := &NodeLoad{
+		Usages:      make(map[UsageKey]float64, len(n.usages)),
+		UpdateTime:  n.updateTime,
+		AssumedPods: make([]*v1.Pod, 0, len(n.assumedPods)),
+	}
+	for key, value := range n.usages {
+		load.Usages[key] = value
+	}
+	for _, p := range n.assumedPods {
+		load.AssumedPods = append(load.AssumedPods, p.pod)
+	}
+	return load, true
+}
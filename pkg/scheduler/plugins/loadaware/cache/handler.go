@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/informers"
+	clientgocache "k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-api/pkg/client/informers/externalversions"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/eventhandlers"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+const (
+	LoadAwarePodHandler = "LoadAwarePodHandler"
+	LoadAwareNPDHandler = "LoadAwareNPDHandler"
+)
+
+// RegisterPodHandler register handler to scheduler event handlers
+func RegisterPodHandler() {
+	eventhandlers.RegisterEventHandler(LoadAwarePodHandler, func(informerFactory informers.SharedInformerFactory, _ externalversions.SharedInformerFactory) {
+		podInformer := informerFactory.Core().V1().Pods()
+		podInformer.Informer().AddEventHandler(
+			clientgocache.FilteringResourceEventHandler{
+				FilterFunc: func(obj interface{}) bool {
+					switch t := obj.(type) {
+					case *v1.Pod:
+						return native.IsAssignedPod(t)
+					case clientgocache.DeletedFinalStateUnknown:
+						if _, ok := t.Obj.(*v1.Pod); ok {
+							// The carried object may be stale, so we don't use it to check if
+							// it's assigned or not. Attempting to cleanup anyways.
+							return true
+						}
+						utilruntime.HandleError(fmt.Errorf("unable to convert object %T to *v1.Pod", obj))
+						return false
+					default:
+						utilruntime.HandleError(fmt.Errorf("unable to handle object: %T", obj))
+						return false
+					}
+				},
+				Handler: clientgocache.ResourceEventHandlerFuncs{
+					DeleteFunc: deletePod,
+				},
+			},
+		)
+	})
+}
+
+// RegisterNPDHandler register handler to scheduler event handlers
+func RegisterNPDHandler() {
+	eventhandlers.RegisterEventHandler(LoadAwareNPDHandler, func(_ informers.SharedInformerFactory, internalInformerFactory externalversions.SharedInformerFactory) {
+		npdInformer := internalInformerFactory.Node().V1alpha1().NodeProfileDescriptors()
+		npdInformer.Informer().AddEventHandler(
+			clientgocache.ResourceEventHandlerFuncs{
+				AddFunc:    addNPD,
+				UpdateFunc: updateNPD,
+				DeleteFunc: deleteNPD,
+			})
+	})
+}
+
+func deletePod(obj interface{}) {
+	var pod *v1.Pod
+	switch t := obj.(type) {
+	case *v1.Pod:
+		pod = t
+	case clientgocache.DeletedFinalStateUnknown:
+		var ok bool
+		pod, ok = t.Obj.(*v1.Pod)
+		if !ok {
+			klog.ErrorS(nil, "Cannot convert to *v1.Pod", "obj", t.Obj)
+			return
+		}
+	default:
+		klog.ErrorS(nil, "Cannot convert to *v1.Pod", "obj", t)
+		return
+	}
+	klog.V(6).InfoS("Delete event for scheduled pod", "pod", klog.KObj(pod))
+
+	GetCache().ForgetPod(pod, pod.Spec.NodeName)
+}
+
+func addNPD(obj interface{}) {
+	npd, ok := obj.(*v1alpha1.NodeProfileDescriptor)
+	if !ok {
+		klog.Errorf("cannot convert obj to NPD: %v", obj)
+		return
+	}
+
+	GetCache().AddOrUpdateNPD(npd)
+}
+
+func updateNPD(_, newObj interface{}) {
+	newNPD, ok := newObj.(*v1alpha1.NodeProfileDescriptor)
+	if !ok {
+		klog.Errorf("cannot convert obj to NPD: %v", newObj)
+		return
+	}
+
+	GetCache().AddOrUpdateNPD(newNPD)
+}
+
+func deleteNPD(obj interface{}) {
+	var npd *v1alpha1.NodeProfileDescriptor
+	switch t := obj.(type) {
+	case *v1alpha1.NodeProfileDescriptor:
+		npd = t
+	case clientgocache.DeletedFinalStateUnknown:
+		var ok bool
+		npd, ok = t.Obj.(*v1alpha1.NodeProfileDescriptor)
+		if !ok {
+			klog.ErrorS(nil, "Cannot convert to *v1alpha1.NodeProfileDescriptor", "obj", t.Obj)
+			return
+		}
+	default:
+		klog.ErrorS(nil, "Cannot convert to *v1alpha1.NodeProfileDescriptor", "obj", t)
+		return
+	}
+
+	GetCache().RemoveNPD(npd)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/plugins/loadaware/cache"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+const (
+	ErrReasonUsageExceedThreshold = "node(s) %s usage exceed threshold"
+)
+
+// Filter rejects nodes whose avg or percentile usage exceeds thresholds, and DaemonSet pods
+// are never rejected since they must run on every node regardless of its load
+func (p *LoadAware) Filter(_ context.Context, _ *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	if native.CheckDaemonPod(pod) {
+		return nil
+	}
+
+	node := nodeInfo.Node()
+	if node == nil {
+		return framework.NewStatus(framework.Error, "node not found")
+	}
+
+	load := p.getNodeLoad(node.Name)
+	if load == nil {
+		return nil
+	}
+
+	if status := p.filterByThresholds(node, load, p.args.UsageThresholds,
+		v1alpha1.AggregatorAvg, p.args.AvgWindow.Duration); !status.IsSuccess() {
+		return status
+	}
+	return p.filterByThresholds(node, load, p.args.PercentileUsageThresholds,
+		p.args.PercentileAggregator, p.args.PercentileWindow.Duration)
+}
+
+func (p *LoadAware) filterByThresholds(node *v1.Node, load *cache.NodeLoad, thresholds map[v1.ResourceName]int64,
+	aggregator v1alpha1.Aggregator, window time.Duration,
+) *framework.Status {
+	for resourceName, threshold := range thresholds {
+		if threshold <= 0 {
+			continue
+		}
+
+		allocatable := getAllocatable(node, resourceName)
+		if allocatable <= 0 {
+			continue
+		}
+
+		usage, ok := p.getUsage(load, resourceName, aggregator, window)
+		if !ok {
+			continue
+		}
+
+		if usage*100 > allocatable*float64(threshold) {
+			klog.V(4).InfoS("Node usage exceeds threshold", "node", node.Name, "resource", resourceName,
+				"aggregator", aggregator, "window", window, "usage", usage, "allocatable", allocatable, "threshold", threshold)
+			return framework.NewStatus(framework.Unschedulable, fmt.Sprintf(ErrReasonUsageExceedThreshold, resourceName))
+		}
+	}
+	return nil
+}
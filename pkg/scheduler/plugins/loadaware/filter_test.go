@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+)
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	node := makeTestNode("node")
+
+	tests := []struct {
+		name        string
+		args        *LoadAwareArgs
+		npd         *v1alpha1.NodeProfileDescriptor
+		assumedPods []*v1.Pod
+		daemon      bool
+		want        *framework.Status
+	}{
+		{
+			name: "no npd",
+			args: &LoadAwareArgs{},
+			want: nil,
+		},
+		{
+			name: "usage below thresholds",
+			args: &LoadAwareArgs{},
+			npd:  makeTestNPD("node", now, "7", "80Gi", "10", "100Gi"),
+			want: nil,
+		},
+		{
+			name: "cpu usage exceeds threshold",
+			args: &LoadAwareArgs{},
+			npd:  makeTestNPD("node", now, "9", "80Gi", "10", "100Gi"),
+			want: framework.NewStatus(framework.Unschedulable, "node(s) cpu usage exceed threshold"),
+		},
+		{
+			name:   "daemonset pod is not filtered",
+			args:   &LoadAwareArgs{},
+			npd:    makeTestNPD("node", now, "9", "80Gi", "10", "100Gi"),
+			daemon: true,
+			want:   nil,
+		},
+		{
+			name: "usage exceeds threshold with assumed pods",
+			args: &LoadAwareArgs{},
+			npd:  makeTestNPD("node", now, "7", "80Gi", "10", "100Gi"),
+			// 2 * 85% = 1.7 cores are estimated
+			assumedPods: []*v1.Pod{makeTestPod("pod", "2", "1Gi")},
+			want:        framework.NewStatus(framework.Unschedulable, "node(s) cpu usage exceed threshold"),
+		},
+		{
+			name: "expired npd is ignored",
+			args: &LoadAwareArgs{},
+			npd:  makeTestNPD("node", now.Add(-time.Hour), "9", "80Gi", "10", "100Gi"),
+			want: nil,
+		},
+		{
+			name: "percentile memory usage exceeds threshold",
+			args: &LoadAwareArgs{
+				PercentileUsageThresholds: map[v1.ResourceName]int64{v1.ResourceMemory: 95},
+			},
+			npd:  makeTestNPD("node", now, "7", "80Gi", "10", "96Gi"),
+			want: framework.NewStatus(framework.Unschedulable, "node(s) memory usage exceed threshold"),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var npds []*v1alpha1.NodeProfileDescriptor
+			if tt.npd != nil {
+				npds = append(npds, tt.npd)
+			}
+			p := newTestPlugin(t, tt.args, []*v1.Node{node}, npds, now)
+			for _, pod := range tt.assumedPods {
+				p.cache.AssumePod(pod, node.Name, now, time.Hour)
+			}
+
+			pod := makeTestPod("incoming", "1", "1Gi")
+			if tt.daemon {
+				pod.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "ds"}}
+			}
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(node)
+			status := p.Filter(context.TODO(), framework.NewCycleState(), pod, nodeInfo)
+			assert.Equal(t, tt.want, status)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/plugins/loadaware/cache"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/util"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+const (
+	// Name is the name of the plugin used in the plugin registry and configurations.
+	Name = "LoadAware"
+
+	// default requests of pods without requests when estimating their usage
+	defaultMilliCPURequest = 250
+	defaultMemoryRequest   = 200 * 1024 * 1024
+)
+
+// usageMetricNames maps resources to their usage metrics in npd
+var usageMetricNames = map[v1.ResourceName]string{
+	v1.ResourceCPU:    consts.NPDMetricNameCPUUsage,
+	v1.ResourceMemory: consts.NPDMetricNameMemoryUsage,
+}
+
+var (
+	_ framework.FilterPlugin      = &LoadAware{}
+	_ framework.ScorePlugin       = &LoadAware{}
+	_ framework.ReservePlugin     = &LoadAware{}
+	_ framework.EnqueueExtensions = &LoadAware{}
+)
+
+// LoadAware filters and scores nodes by their actual load in npd rather than requests,
+// so that hot nodes stop receiving pods even if their requests are inflated.
+type LoadAware struct {
+	handle framework.Handle
+	args   *LoadAwareArgs
+	cache  loadCache
+
+	// nowFunc is replaced in unit tests
+	nowFunc func() time.Time
+}
+
+// loadCache is implemented by cache.GetCache(), and it's replaced in unit tests
+type loadCache interface {
+	GetNodeLoad(nodeName string) (*cache.NodeLoad, bool)
+	AssumePod(pod *v1.Pod, nodeName string, assumeTime time.Time, ttl time.Duration)
+	ForgetPod(pod *v1.Pod, nodeName string)
+}
+
+func (p *LoadAware) Name() string {
+	return Name
+}
+
+func New(args runtime.Object, h framework.Handle) (framework.Plugin, error) {
+	klog.Info("Creating new LoadAware plugin")
+	loadAwareArgs := &LoadAwareArgs{}
+	if err := frameworkruntime.DecodeInto(args, loadAwareArgs); err != nil {
+		return nil, fmt.Errorf("failed to decode LoadAwareArgs: %v", err)
+	}
+	SetDefaultLoadAwareArgs(loadAwareArgs)
+	if err := ValidateLoadAwareArgs(loadAwareArgs); err != nil {
+		return nil, err
+	}
+	klog.Infof("args: %+v", loadAwareArgs)
+
+	cache.RegisterPodHandler()
+	cache.RegisterNPDHandler()
+
+	return &LoadAware{
+		handle:  h,
+		args:    loadAwareArgs,
+		cache:   cache.GetCache(),
+		nowFunc: time.Now,
+	}, nil
+}
+
+// EventsToRegister returns the possible events that may make a Pod
+// failed by this plugin schedulable.
+func (p *LoadAware) EventsToRegister() []framework.ClusterEvent {
+	npdGVK := fmt.Sprintf("nodeprofiledescriptors.v1alpha1.%v", v1alpha1.GroupName)
+	return []framework.ClusterEvent{
+		{Resource: framework.Pod, ActionType: framework.Delete},
+		{Resource: framework.Node, ActionType: framework.Add | framework.UpdateNodeAllocatable},
+		{Resource: framework.GVK(npdGVK), ActionType: framework.Add | framework.Update},
+	}
+}
+
+// getNodeLoad returns nil if the node has no valid load profiles, i.e. they're
+// absent or expired, and such nodes are neither filtered nor preferred.
+func (p *LoadAware) getNodeLoad(nodeName string) *cache.NodeLoad {
+	load, ok := p.cache.GetNodeLoad(nodeName)
+	if !ok || load.UpdateTime.IsZero() {
+		klog.V(6).InfoS("Node load profiles not found", "node", nodeName)
+		return nil
+	}
+
+	expired := time.Duration(*p.args.NodeMetricsExpiredSeconds) * time.Second
+	if p.nowFunc().Sub(load.UpdateTime) > expired {
+		klog.V(4).InfoS("Node load profiles expired", "node", nodeName, "updateTime", load.UpdateTime)
+		return nil
+	}
+	return load
+}
+
+// getUsage returns the usage of resource in load profiles plus the estimated usage of
+// pods not reported yet, and false if the profile is absent.
+func (p *LoadAware) getUsage(load *cache.NodeLoad, resourceName v1.ResourceName,
+	aggregator v1alpha1.Aggregator, window time.Duration,
+) (float64, bool) {
+	usage, ok := load.Usages[cache.UsageKey{
+		MetricName: usageMetricNames[resourceName],
+		Aggregator: aggregator,
+		Window:     window,
+	}]
+	if !ok {
+		return 0, false
+	}
+
+	for _, pod := range load.AssumedPods {
+		usage += p.estimatePodUsage(pod, resourceName)
+	}
+	return usage, true
+}
+
+// estimatePodUsage estimates usage of the pod by its requests, including reclaimed resources
+func (p *LoadAware) estimatePodUsage(pod *v1.Pod, resourceName v1.ResourceName) float64 {
+	requests := util.GetPodEffectiveRequest(pod)
+	factor := float64(p.args.EstimatedScalingFactors[resourceName]) / 100
+
+	switch resourceName {
+	case v1.ResourceCPU:
+		quantity := native.CPUQuantityGetter()(requests)
+		milliCPU := quantity.MilliValue()
+		if milliCPU == 0 {
+			milliCPU = defaultMilliCPURequest
+		}
+		return float64(milliCPU) / 1000 * factor
+	case v1.ResourceMemory:
+		quantity := native.MemoryQuantityGetter()(requests)
+		memory := quantity.Value()
+		if memory == 0 {
+			memory = defaultMemoryRequest
+		}
+		return float64(memory) * factor
+	default:
+		return 0
+	}
+}
+
+// getAllocatable returns allocatable of resource in the same unit as usage, i.e. cores or bytes
+func getAllocatable(node *v1.Node, resourceName v1.ResourceName) float64 {
+	switch resourceName {
+	case v1.ResourceCPU:
+		return float64(node.Status.Allocatable.Cpu().MilliValue()) / 1000
+	case v1.ResourceMemory:
+		return float64(node.Status.Allocatable.Memory().Value())
+	default:
+		return 0
+	}
+}
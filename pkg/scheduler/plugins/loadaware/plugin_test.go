@@ -0,0 +1,177 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/plugins/loadaware/cache"
+)
+
+var _ framework.SharedLister = &testSharedLister{}
+
+type testSharedLister struct {
+	nodeInfos   []*framework.NodeInfo
+	nodeInfoMap map[string]*framework.NodeInfo
+}
+
+func newTestSharedLister(nodes []*v1.Node) *testSharedLister {
+	l := &testSharedLister{nodeInfoMap: make(map[string]*framework.NodeInfo)}
+	for _, node := range nodes {
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		l.nodeInfos = append(l.nodeInfos, nodeInfo)
+		l.nodeInfoMap[node.Name] = nodeInfo
+	}
+	return l
+}
+
+func (f *testSharedLister) NodeInfos() framework.NodeInfoLister {
+	return f
+}
+
+func (f *testSharedLister) List() ([]*framework.NodeInfo, error) {
+	return f.nodeInfos, nil
+}
+
+func (f *testSharedLister) HavePodsWithAffinityList() ([]*framework.NodeInfo, error) {
+	return nil, nil
+}
+
+func (f *testSharedLister) HavePodsWithRequiredAntiAffinityList() ([]*framework.NodeInfo, error) {
+	return nil, nil
+}
+
+func (f *testSharedLister) Get(nodeName string) (*framework.NodeInfo, error) {
+	return f.nodeInfoMap[nodeName], nil
+}
+
+func makeTestNode(name string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("10"),
+				v1.ResourceMemory: resource.MustParse("100Gi"),
+			},
+		},
+	}
+}
+
+func makeTestPod(name string, cpu, memory string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name)},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceCPU:    resource.MustParse(cpu),
+							v1.ResourceMemory: resource.MustParse(memory),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// makeTestNPD makes npd with avg and p95 usage of cpu (in cores) and memory (in Gi)
+func makeTestNPD(name string, timestamp time.Time, avgCPU, avgMemory, p95CPU, p95Memory string) *v1alpha1.NodeProfileDescriptor {
+	avg, p95 := v1alpha1.AggregatorAvg, v1alpha1.AggregatorP95
+	avgWindow, p95Window := &metav1.Duration{Duration: defaultAvgWindow}, &metav1.Duration{Duration: defaultPercentileWindow}
+	metric := func(name string, aggregator *v1alpha1.Aggregator, window *metav1.Duration, value string) v1alpha1.MetricValue {
+		return v1alpha1.MetricValue{
+			MetricName: name,
+			Timestamp:  metav1.NewTime(timestamp),
+			Aggregator: aggregator,
+			Window:     window,
+			Value:      resource.MustParse(value),
+		}
+	}
+
+	return &v1alpha1.NodeProfileDescriptor{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1alpha1.NodeProfileDescriptorStatus{
+			NodeMetrics: []v1alpha1.ScopedNodeMetrics{
+				{
+					Scope: consts.NPDScopeLoadAware,
+					Metrics: []v1alpha1.MetricValue{
+						metric(consts.NPDMetricNameCPUUsage, &avg, avgWindow, avgCPU),
+						metric(consts.NPDMetricNameMemoryUsage, &avg, avgWindow, avgMemory),
+						metric(consts.NPDMetricNameCPUUsage, &p95, p95Window, p95CPU),
+						metric(consts.NPDMetricNameMemoryUsage, &p95, p95Window, p95Memory),
+					},
+				},
+			},
+		},
+	}
+}
+
+func newTestPlugin(t *testing.T, args *LoadAwareArgs, nodes []*v1.Node, npds []*v1alpha1.NodeProfileDescriptor,
+	now time.Time,
+) *LoadAware {
+	SetDefaultLoadAwareArgs(args)
+	require.NoError(t, ValidateLoadAwareArgs(args))
+
+	f, err := runtime.NewFramework(nil, nil, runtime.WithSnapshotSharedLister(newTestSharedLister(nodes)))
+	require.NoError(t, err)
+
+	c := cache.NewCache()
+	for _, npd := range npds {
+		c.AddOrUpdateNPD(npd)
+	}
+	return &LoadAware{
+		handle:  f,
+		args:    args,
+		cache:   c,
+		nowFunc: func() time.Time { return now },
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	p, err := New(nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Name, p.Name())
+	assert.Equal(t, defaultPercentileAggregator, p.(*LoadAware).args.PercentileAggregator)
+
+	p, err = New(&k8sruntime.Unknown{Raw: []byte(`{"usageThresholds":{"cpu":60},"avgWindow":"5m"}`)}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[v1.ResourceName]int64{v1.ResourceCPU: 60}, p.(*LoadAware).args.UsageThresholds)
+	assert.Equal(t, 5*time.Minute, p.(*LoadAware).args.AvgWindow.Duration)
+
+	_, err = New(&k8sruntime.Unknown{Raw: []byte(`{"usageThresholds":{"cpu":120}}`)}, nil)
+	assert.Error(t, err)
+
+	_, err = New(&k8sruntime.Unknown{Raw: []byte(`{"resourceWeights":{"nvidia.com/gpu":1}}`)}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,35 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// Reserve assumes the pod on the node, so that its usage is estimated until the avg window
+// has passed, even if it's already reported in the load profiles of the node.
+func (p *LoadAware) Reserve(_ context.Context, _ *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
+	p.cache.AssumePod(pod, nodeName, p.nowFunc(), p.args.AvgWindow.Duration)
+	return nil
+}
+
+func (p *LoadAware) Unreserve(_ context.Context, _ *framework.CycleState, pod *v1.Pod, nodeName string) {
+	p.cache.ForgetPod(pod, nodeName)
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+)
+
+func TestReserve(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	node := makeTestNode("node")
+	p := newTestPlugin(t, &LoadAwareArgs{}, []*v1.Node{node},
+		[]*v1alpha1.NodeProfileDescriptor{makeTestNPD("node", now, "1", "10Gi", "2", "20Gi")}, now)
+	pod := makeTestPod("pod", "2", "10Gi")
+
+	status := p.Reserve(context.TODO(), framework.NewCycleState(), pod, node.Name)
+	assert.True(t, status.IsSuccess())
+	load, ok := p.cache.GetNodeLoad(node.Name)
+	assert.True(t, ok)
+	assert.Equal(t, []*v1.Pod{pod}, load.AssumedPods)
+
+	updater := p.cache.(interface {
+		AddOrUpdateNPD(*v1alpha1.NodeProfileDescriptor)
+	})
+
+	// assumed pods are kept before the avg window has passed
+	updater.AddOrUpdateNPD(makeTestNPD("node", now.Add(time.Minute), "1", "10Gi", "2", "20Gi"))
+	load, _ = p.cache.GetNodeLoad(node.Name)
+	assert.Equal(t, []*v1.Pod{pod}, load.AssumedPods)
+
+	// assumed pods are still kept after they're reported in pod metrics of npd
+	npd := makeTestNPD("node", now.Add(2*time.Minute), "1", "10Gi", "2", "20Gi")
+	npd.Status.PodMetrics = []v1alpha1.ScopedPodMetrics{
+		{
+			Scope: consts.NPDScopeLoadAware,
+			PodMetrics: []v1alpha1.PodMetric{
+				{Namespace: pod.Namespace, Name: pod.Name, Metrics: npd.Status.NodeMetrics[0].Metrics},
+			},
+		},
+	}
+	updater.AddOrUpdateNPD(npd)
+	load, _ = p.cache.GetNodeLoad(node.Name)
+	assert.Equal(t, []*v1.Pod{pod}, load.AssumedPods)
+
+	// assumed pods are forgotten once the avg window has passed
+	updater.AddOrUpdateNPD(makeTestNPD("node", now.Add(defaultAvgWindow), "1", "10Gi", "2", "20Gi"))
+	load, _ = p.cache.GetNodeLoad(node.Name)
+	assert.Empty(t, load.AssumedPods)
+
+	p.Reserve(context.TODO(), framework.NewCycleState(), pod, node.Name)
+	p.Unreserve(context.TODO(), framework.NewCycleState(), pod, node.Name)
+	load, _ = p.cache.GetNodeLoad(node.Name)
+	assert.Empty(t, load.AssumedPods)
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+)
+
+// neutralScore is given to nodes whose load is unknown
+const neutralScore = framework.MaxNodeScore / 2
+
+// Score prefers nodes with less avg usage, including the estimated usage of the incoming pod,
+// and nodes without valid load profiles get a neutral score, neither preferred nor avoided.
+func (p *LoadAware) Score(_ context.Context, _ *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
+	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return 0, framework.AsStatus(err)
+	}
+	node := nodeInfo.Node()
+	if node == nil {
+		return 0, framework.NewStatus(framework.Error, "node not found")
+	}
+
+	load := p.getNodeLoad(nodeName)
+	if load == nil {
+		return neutralScore, nil
+	}
+
+	var score, weightSum int64
+	for resourceName, weight := range p.args.ResourceWeights {
+		allocatable := getAllocatable(node, resourceName)
+		if allocatable <= 0 {
+			continue
+		}
+
+		usage, ok := p.getUsage(load, resourceName, v1alpha1.AggregatorAvg, p.args.AvgWindow.Duration)
+		if !ok {
+			continue
+		}
+		usage += p.estimatePodUsage(pod, resourceName)
+
+		score += leastUsedScore(usage, allocatable) * weight
+		weightSum += weight
+	}
+
+	if weightSum == 0 {
+		return neutralScore, nil
+	}
+	return score / weightSum, nil
+}
+
+func (p *LoadAware) ScoreExtensions() framework.ScoreExtensions {
+	return nil
+}
+
+// leastUsedScore favors nodes with fewer usage, and it's 0 if usage exceeds allocatable
+func leastUsedScore(usage, allocatable float64) int64 {
+	if usage >= allocatable {
+		return 0
+	}
+	return int64((allocatable - usage) * float64(framework.MaxNodeScore) / allocatable)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+)
+
+func TestScore(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	nodes := []*v1.Node{makeTestNode("idle"), makeTestNode("busy"), makeTestNode("expired"), makeTestNode("unknown")}
+	npds := []*v1alpha1.NodeProfileDescriptor{
+		makeTestNPD("idle", now, "1", "10Gi", "2", "20Gi"),
+		makeTestNPD("busy", now, "5", "50Gi", "8", "80Gi"),
+		makeTestNPD("expired", now.Add(-time.Hour), "1", "10Gi", "2", "20Gi"),
+	}
+	// 2 * 85% = 1.7 cores and 10Gi * 70% = 7Gi are estimated for the incoming pod
+	pod := makeTestPod("incoming", "2", "10Gi")
+
+	tests := []struct {
+		name        string
+		args        *LoadAwareArgs
+		assumedPods map[string][]*v1.Pod
+		want        map[string]int64
+	}{
+		{
+			name: "default weights",
+			args: &LoadAwareArgs{},
+			// idle: cpu (10-2.7)*100/10 = 73, memory (100-17)*100/100 = 83
+			// busy: cpu (10-6.7)*100/10 = 33, memory (100-57)*100/100 = 43
+			want: map[string]int64{"idle": 78, "busy": 38, "expired": 50, "unknown": 50},
+		},
+		{
+			name: "cpu only",
+			args: &LoadAwareArgs{
+				ResourceWeights: map[v1.ResourceName]int64{v1.ResourceCPU: 1},
+			},
+			want: map[string]int64{"idle": 73, "busy": 33, "expired": 50, "unknown": 50},
+		},
+		{
+			name: "with assumed pods",
+			args: &LoadAwareArgs{
+				ResourceWeights:         map[v1.ResourceName]int64{v1.ResourceCPU: 1},
+				EstimatedScalingFactors: map[v1.ResourceName]int64{v1.ResourceCPU: 50},
+			},
+			assumedPods: map[string][]*v1.Pod{
+				"idle": {makeTestPod("pod-1", "2", "10Gi"), makeTestPod("pod-2", "2", "10Gi")},
+			},
+			// idle: cpu (10-1-2*1-1)*100/10 = 60, busy: cpu (10-5-1)*100/10 = 40
+			want: map[string]int64{"idle": 60, "busy": 40, "expired": 50, "unknown": 50},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := newTestPlugin(t, tt.args, nodes, npds, now)
+			for nodeName, pods := range tt.assumedPods {
+				for _, assumed := range pods {
+					p.cache.AssumePod(assumed, nodeName, now, time.Hour)
+				}
+			}
+
+			got := make(map[string]int64)
+			for _, node := range nodes {
+				score, status := p.Score(context.TODO(), framework.NewCycleState(), pod, node.Name)
+				assert.True(t, status.IsSuccess())
+				got[node.Name] = score
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
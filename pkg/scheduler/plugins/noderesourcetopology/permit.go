@@ -0,0 +1,55 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/cache"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/util"
+)
+
+// Permit makes members of a pod group wait until enough members are reserved, and then
+// all the waiting members are allowed together.
+func (tm *TopologyMatch) Permit(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) (*framework.Status, time.Duration) {
+	if !tm.topologyMatchSupport(pod) {
+		return framework.NewStatus(framework.Success, ""), 0
+	}
+
+	gang, _, ok := util.GetPodGroup(pod)
+	if !ok {
+		return framework.NewStatus(framework.Success, ""), 0
+	}
+
+	if !cache.GetCache().CommitGang(gang) {
+		klog.V(4).InfoS("Pod group waits for more members", "podGroup", gang, "pod", klog.KObj(pod))
+		return framework.NewStatus(framework.Wait, ""), tm.gangWaitingTimeout
+	}
+
+	tm.handle.IterateOverWaitingPods(func(waitingPod framework.WaitingPod) {
+		if g, _, ok := util.GetPodGroup(waitingPod.GetPod()); ok && g == gang {
+			waitingPod.Allow(TopologyMatchName)
+		}
+	})
+	klog.V(4).InfoS("Pod group is committed", "podGroup", gang, "pod", klog.KObj(pod))
+	return framework.NewStatus(framework.Success, ""), 0
+}
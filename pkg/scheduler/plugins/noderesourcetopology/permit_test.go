@@ -0,0 +1,159 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+
+	"github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	katalystconsts "github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/cache"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/util"
+)
+
+func makeTestGangPod(name, group, minMember, nodeName string) *v1.Pod {
+	pod := makePodByResourceList(&v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("4"),
+		v1.ResourceMemory: resource.MustParse("8Gi"),
+	}, map[string]string{
+		consts.PodAnnotationQoSLevelKey:                  consts.PodAnnotationQoSLevelDedicatedCores,
+		consts.PodAnnotationMemoryEnhancementKey:         `{"numa_binding":"true"}`,
+		katalystconsts.PodAnnotationPodGroupMinMemberKey: minMember,
+	})
+	pod.Name = name
+	pod.Namespace = "default"
+	pod.UID = types.UID(name)
+	pod.Labels = map[string]string{katalystconsts.PodGroupLabelKey: group}
+	pod.Spec.NodeName = nodeName
+	return pod
+}
+
+func assertAssumedPods(t *testing.T, nodeName string, want ...string) {
+	nodeInfo, err := cache.GetCache().GetNodeInfo(nodeName)
+	assert.NoError(t, err)
+
+	got := make([]string, 0)
+	for key := range nodeInfo.AssumedPodResources {
+		got = append(got, key)
+	}
+	assert.ElementsMatch(t, want, got)
+}
+
+func TestPermit(t *testing.T) {
+	util.SetQoSConfig(generic.NewQoSConfiguration())
+	nodeNames := []string{"node-gang-0", "node-gang-1"}
+	for _, nodeName := range nodeNames {
+		cnr, _ := makeTestReserveNode()
+		cnr.Name = nodeName
+		cache.GetCache().AddOrUpdateCNR(cnr)
+	}
+
+	// a member of the running group has been bound
+	bound := makeTestGangPod("running-0", "running", "3", nodeNames[0])
+	f, err := runtime.NewFramework(nil, nil,
+		runtime.WithSnapshotSharedLister(newTestSharedLister([]*v1.Pod{bound}, nil)))
+	assert.NoError(t, err)
+	p, err := MakeTestTm(MakeTestArgs(config.MostAllocated, []string{"cpu", "memory"}, "dynamic"), f)
+	assert.NoError(t, err)
+	tm := p.(*TopologyMatch)
+
+	t.Run("pod group is committed after enough members are reserved", func(t *testing.T) {
+		pod0 := makeTestGangPod("commit-0", "commit", "2", "")
+		pod1 := makeTestGangPod("commit-1", "commit", "2", "")
+
+		assert.True(t, tm.Reserve(context.TODO(), nil, pod0, nodeNames[0]).IsSuccess())
+		status, timeout := tm.Permit(context.TODO(), nil, pod0, nodeNames[0])
+		assert.Equal(t, framework.Wait, status.Code())
+		assert.Equal(t, util.DefaultGangWaitingTimeout, timeout)
+
+		assert.True(t, tm.Reserve(context.TODO(), nil, pod1, nodeNames[1]).IsSuccess())
+		status, _ = tm.Permit(context.TODO(), nil, pod1, nodeNames[1])
+		assert.True(t, status.IsSuccess())
+		assertAssumedPods(t, nodeNames[0], "default/commit-0")
+		assertAssumedPods(t, nodeNames[1], "default/commit-1")
+
+		// committed members are not rolled back if others fail to be bound
+		tm.Unreserve(context.TODO(), nil, pod1, nodeNames[1])
+		assertAssumedPods(t, nodeNames[0], "default/commit-0")
+		assertAssumedPods(t, nodeNames[1])
+
+		tm.Unreserve(context.TODO(), nil, pod0, nodeNames[0])
+		assertAssumedPods(t, nodeNames[0])
+	})
+
+	t.Run("pod group is rolled back if any member is unreserved before committed", func(t *testing.T) {
+		pod0 := makeTestGangPod("rollback-0", "rollback", "3", "")
+		pod1 := makeTestGangPod("rollback-1", "rollback", "3", "")
+
+		for i, pod := range []*v1.Pod{pod0, pod1} {
+			assert.True(t, tm.Reserve(context.TODO(), nil, pod, nodeNames[i]).IsSuccess())
+			status, _ := tm.Permit(context.TODO(), nil, pod, nodeNames[i])
+			assert.Equal(t, framework.Wait, status.Code())
+		}
+		assertAssumedPods(t, nodeNames[0], "default/rollback-0")
+		assertAssumedPods(t, nodeNames[1], "default/rollback-1")
+
+		// e.g. pod1 is rejected after waiting timeout
+		tm.Unreserve(context.TODO(), nil, pod1, nodeNames[1])
+		assertAssumedPods(t, nodeNames[0])
+		assertAssumedPods(t, nodeNames[1])
+
+		// the pod group starts over after rollback
+		assert.True(t, tm.Reserve(context.TODO(), nil, pod0, nodeNames[0]).IsSuccess())
+		status, _ := tm.Permit(context.TODO(), nil, pod0, nodeNames[0])
+		assert.Equal(t, framework.Wait, status.Code())
+
+		// stale unreserve of the rolled back member doesn't roll back the new attempt
+		tm.Unreserve(context.TODO(), nil, pod1, nodeNames[1])
+		assertAssumedPods(t, nodeNames[0], "default/rollback-0")
+
+		tm.Unreserve(context.TODO(), nil, pod0, nodeNames[0])
+		assertAssumedPods(t, nodeNames[0])
+	})
+
+	t.Run("bound members are counted for recreated members", func(t *testing.T) {
+		pod1 := makeTestGangPod("running-1", "running", "3", "")
+		pod2 := makeTestGangPod("running-2", "running", "3", "")
+
+		assert.True(t, tm.Reserve(context.TODO(), nil, pod1, nodeNames[0]).IsSuccess())
+		status, _ := tm.Permit(context.TODO(), nil, pod1, nodeNames[0])
+		assert.Equal(t, framework.Wait, status.Code())
+
+		assert.True(t, tm.Reserve(context.TODO(), nil, pod2, nodeNames[1]).IsSuccess())
+		status, _ = tm.Permit(context.TODO(), nil, pod2, nodeNames[1])
+		assert.True(t, status.IsSuccess())
+
+		tm.Unreserve(context.TODO(), nil, pod1, nodeNames[0])
+		tm.Unreserve(context.TODO(), nil, pod2, nodeNames[1])
+	})
+
+	t.Run("pods without pod group are permitted directly", func(t *testing.T) {
+		pod := makeTestGangPod("single", "single", "1", "")
+		status, _ := tm.Permit(context.TODO(), nil, pod, nodeNames[0])
+		assert.True(t, status.IsSuccess())
+	})
+}
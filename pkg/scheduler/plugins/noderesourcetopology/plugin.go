@@ -19,12 +19,15 @@ package noderesourcetopology
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	policylisters "k8s.io/client-go/listers/policy/v1"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
 	"k8s.io/kubernetes/pkg/scheduler/apis/config"
@@ -68,12 +71,20 @@ type TopologyMatch struct {
 	alignedResources    sets.String
 	resourcePolicy      consts.ResourcePluginPolicyName
 	sharedLister        framework.SharedLister
+	podLister           corelisters.PodLister
+	pdbLister           policylisters.PodDisruptionBudgetLister
+	handle              framework.Handle
+	// gangWaitingTimeout is the max duration for members of a pod group to wait for the
+	// others, and reservations of the pod group are rolled back in Unreserve after timeout.
+	gangWaitingTimeout time.Duration
 }
 
 var (
 	_ framework.FilterPlugin      = &TopologyMatch{}
 	_ framework.ScorePlugin       = &TopologyMatch{}
 	_ framework.ReservePlugin     = &TopologyMatch{}
+	_ framework.PostFilterPlugin  = &TopologyMatch{}
+	_ framework.PermitPlugin      = &TopologyMatch{}
 	_ framework.EnqueueExtensions = &TopologyMatch{}
 )
 
@@ -109,6 +120,17 @@ func New(args runtime.Object, h framework.Handle) (framework.Plugin, error) {
 	eventhandlers.RegisterCommonPodHandler()
 	eventhandlers.RegisterCommonCNRHandler()
 
+	// listers are used by the preemption evaluator in PostFilter to get the latest preemptor
+	// and avoid preempting pods protected by PodDisruptionBudgets
+	var (
+		podLister corelisters.PodLister
+		pdbLister policylisters.PodDisruptionBudgetLister
+	)
+	if informerFactory := h.SharedInformerFactory(); informerFactory != nil {
+		podLister = informerFactory.Core().V1().Pods().Lister()
+		pdbLister = informerFactory.Policy().V1().PodDisruptionBudgets().Lister()
+	}
+
 	return &TopologyMatch{
 		scoreStrategyType:   tcfg.ScoringStrategy.Type,
 		alignedResources:    alignedResources,
@@ -116,6 +138,10 @@ func New(args runtime.Object, h framework.Handle) (framework.Plugin, error) {
 		scoreStrategyFunc:   strategy,
 		resourcePolicy:      tcfg.ResourcePluginPolicy,
 		sharedLister:        h.SnapshotSharedLister(),
+		podLister:           podLister,
+		pdbLister:           pdbLister,
+		handle:              h,
+		gangWaitingTimeout:  util.GetGangWaitingTimeout(),
 	}, nil
 }
 
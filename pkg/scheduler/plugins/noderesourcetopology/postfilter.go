@@ -0,0 +1,316 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/preemption"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/cache"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/util"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+const (
+	ErrReasonNoReclaimedVictims = "no reclaimed pods to preempt on any NUMA node"
+	ErrReasonNotFailedByPlugin  = "preemption is not helpful for nodes not failed by " + TopologyMatchName
+)
+
+// reclaimedPreemption implements preemption.Interface, so that victims are evicted through
+// the framework preemption evaluator the same as the default preemption.
+type reclaimedPreemption struct {
+	tm                    *TopologyMatch
+	filteredNodeStatusMap framework.NodeToStatusMap
+}
+
+var _ preemption.Interface = &reclaimedPreemption{}
+
+// PostFilter preempts reclaimed_cores pods for NUMA-bound pods failed by this plugin. Unlike
+// the default preemption, it only evicts reclaimed pods with lower priority on a single NUMA
+// node without violating PodDisruptionBudgets, and the NUMA node with the fewest victims is selected.
+func (tm *TopologyMatch) PostFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod,
+	filteredNodeStatusMap framework.NodeToStatusMap,
+) (*framework.PostFilterResult, *framework.Status) {
+	if !tm.topologyMatchSupport(pod) || util.IsReclaimedPod(pod) || tm.podLister == nil {
+		return nil, framework.NewStatus(framework.Unschedulable)
+	}
+
+	pe := preemption.Evaluator{
+		PluginName: TopologyMatchName,
+		Handler:    tm.handle,
+		PodLister:  tm.podLister,
+		PdbLister:  tm.pdbLister,
+		State:      state,
+		Interface: &reclaimedPreemption{
+			tm:                    tm,
+			filteredNodeStatusMap: filteredNodeStatusMap,
+		},
+	}
+	return pe.Preempt(ctx, pod, filteredNodeStatusMap)
+}
+
+// GetOffsetAndNumCandidates dry runs all nodes, since only nodes failed by this plugin
+// with reclaimed pods on them may become candidates.
+func (p *reclaimedPreemption) GetOffsetAndNumCandidates(numNodes int32) (int32, int32) {
+	return 0, numNodes
+}
+
+func (p *reclaimedPreemption) CandidatesToVictimsMap(candidates []preemption.Candidate) map[string]*extenderv1.Victims {
+	m := make(map[string]*extenderv1.Victims)
+	for _, c := range candidates {
+		m[c.Name()] = c.Victims()
+	}
+	return m
+}
+
+// PodEligibleToPreemptOthers returns false if the pod never preempts, or if victims
+// preempted for it before are still terminating on its nominated node.
+func (p *reclaimedPreemption) PodEligibleToPreemptOthers(pod *v1.Pod, nominatedNodeStatus *framework.Status) (bool, string) {
+	if pod.Spec.PreemptionPolicy != nil && *pod.Spec.PreemptionPolicy == v1.PreemptNever {
+		return false, "not eligible due to preemptionPolicy=Never."
+	}
+
+	nomNodeName := pod.Status.NominatedNodeName
+	if len(nomNodeName) == 0 || nominatedNodeStatus.Code() == framework.UnschedulableAndUnresolvable {
+		return true, ""
+	}
+	if nodeInfo, _ := p.tm.sharedLister.NodeInfos().Get(nomNodeName); nodeInfo != nil {
+		podPriority := corev1helpers.PodPriority(pod)
+		for _, podInfo := range nodeInfo.Pods {
+			if podInfo.Pod.DeletionTimestamp != nil && util.IsReclaimedPod(podInfo.Pod) &&
+				corev1helpers.PodPriority(podInfo.Pod) < podPriority {
+				return false, "not eligible due to a terminating reclaimed pod on the nominated node."
+			}
+		}
+	}
+	return true, ""
+}
+
+// SelectVictimsOnNode returns reclaimed pods to be evicted on the NUMA node with the fewest victims,
+// and nodes failed by other plugins are skipped since evicting reclaimed pods doesn't help them.
+func (p *reclaimedPreemption) SelectVictimsOnNode(_ context.Context, _ *framework.CycleState, pod *v1.Pod,
+	nodeInfo *framework.NodeInfo, pdbs []*policyv1.PodDisruptionBudget,
+) ([]*v1.Pod, int, *framework.Status) {
+	nodeName := nodeInfo.Node().Name
+	status := p.filteredNodeStatusMap[nodeName]
+	if status.Code() != framework.Unschedulable || status.FailedPlugin() != TopologyMatchName {
+		return nil, 0, framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrReasonNotFailedByPlugin)
+	}
+
+	victims, numaID := p.tm.selectVictimsOnNode(pod, nodeInfo, pdbs)
+	if len(victims) == 0 {
+		return nil, 0, framework.NewStatus(framework.Unschedulable, ErrReasonNoReclaimedVictims)
+	}
+
+	// victims never violate PodDisruptionBudgets, and they are ordered by decreasing
+	// priority as expected by the evaluator when picking the node
+	sort.SliceStable(victims, func(i, j int) bool {
+		return corev1helpers.PodPriority(victims[i]) > corev1helpers.PodPriority(victims[j])
+	})
+	klog.V(4).InfoS("[TopologyMatch] select reclaimed victims", "preemptor", klog.KObj(pod), "node", nodeName,
+		"numa", numaID, "victims", len(victims))
+	return victims, 0, nil
+}
+
+// selectVictimsOnNode returns victims on the NUMA node that needs the fewest reclaimed pods to be
+// evicted for the pod to fit, and no victims if the pod can't fit on any single NUMA node by evicting them.
+func (tm *TopologyMatch) selectVictimsOnNode(pod *v1.Pod, nodeInfo *framework.NodeInfo,
+	pdbs []*policyv1.PodDisruptionBudget,
+) ([]*v1.Pod, int) {
+	nodeName := nodeInfo.Node().Name
+	reclaimedPods := make(map[string]*v1.Pod)
+	for _, podInfo := range nodeInfo.Pods {
+		if util.IsReclaimedPod(podInfo.Pod) && podInfo.Pod.DeletionTimestamp == nil &&
+			corev1helpers.PodPriority(podInfo.Pod) < corev1helpers.PodPriority(pod) {
+			reclaimedPods[native.GenerateNamespaceNameKey(podInfo.Pod.Namespace, podInfo.Pod.Name)] = podInfo.Pod
+		}
+	}
+	if len(reclaimedPods) == 0 {
+		return nil, -1
+	}
+
+	var filter func(consumer string) bool
+	if tm.resourcePolicy == consts.ResourcePluginPolicyNameDynamic {
+		filter = tm.numaBindingPodsFilter(nodeInfo)
+	}
+	resourceTopology := cache.GetCache().GetNodeResourceTopology(nodeName, filter)
+	if resourceTopology == nil {
+		return nil, -1
+	}
+	handler := tm.filterHandler(pod, resourceTopology)
+	if handler == nil {
+		return nil, -1
+	}
+
+	var (
+		bestVictims []*v1.Pod
+		bestNUMAID  = -1
+	)
+	for _, numaZone := range getNUMAZones(resourceTopology.TopologyZone) {
+		numaID, err := getID(numaZone.Name)
+		if err != nil {
+			continue
+		}
+
+		// evict reclaimed pods with larger requests first to get the minimal set of victims
+		victimAllocations := make([]*v1alpha1.Allocation, 0)
+		for _, alloc := range numaZone.Allocations {
+			if getConsumerPod(alloc.Consumer, reclaimedPods) != nil {
+				victimAllocations = append(victimAllocations, alloc)
+			}
+		}
+		sort.SliceStable(victimAllocations, func(i, j int) bool {
+			return allocationCPU(victimAllocations[i]).Cmp(*allocationCPU(victimAllocations[j])) > 0
+		})
+
+		originalAllocations := numaZone.Allocations
+		disruptionsAllowed := getDisruptionsAllowed(pdbs)
+		victims := make([]*v1.Pod, 0)
+		for _, victimAllocation := range victimAllocations {
+			victim := getConsumerPod(victimAllocation.Consumer, reclaimedPods)
+			if !consumeDisruptionBudget(victim, pdbs, disruptionsAllowed) {
+				klog.V(5).InfoS("[TopologyMatch] skip victim protected by PodDisruptionBudget", "pod", klog.KObj(victim))
+				continue
+			}
+			numaZone.Allocations = removeAllocation(numaZone.Allocations, victimAllocation)
+			victims = append(victims, victim)
+
+			if handler(pod, resourceTopology.TopologyZone, nodeInfo).IsSuccess() {
+				if bestVictims == nil || len(victims) < len(bestVictims) {
+					bestVictims, bestNUMAID = victims, numaID
+				}
+				break
+			}
+		}
+		numaZone.Allocations = originalAllocations
+	}
+	return bestVictims, bestNUMAID
+}
+
+// numaBindingPodsFilter returns the filter of allocations accounted when selecting victims in
+// dynamic policy. Filter only accounts dedicated pods, while QRM also keeps numaBinding shared
+// and reclaimed pods on their NUMA, so they must be accounted to know which of them to preempt.
+func (tm *TopologyMatch) numaBindingPodsFilter(nodeInfo *framework.NodeInfo) func(consumer string) bool {
+	accountedPods := make(map[string]struct{})
+	for _, podInfo := range nodeInfo.Pods {
+		if util.IsDedicatedPod(podInfo.Pod) ||
+			((util.IsSharedPod(podInfo.Pod) || util.IsReclaimedPod(podInfo.Pod)) && util.IsNumaBinding(podInfo.Pod)) {
+			key := native.GenerateNamespaceNameKey(podInfo.Pod.Namespace, podInfo.Pod.Name)
+			accountedPods[key] = struct{}{}
+		}
+	}
+
+	return func(consumer string) bool {
+		namespace, name, _, err := native.ParseNamespaceNameUIDKey(consumer)
+		if err != nil {
+			klog.Errorf("ParseNamespaceNameUIDKey consumer %v fail: %v", consumer, err)
+			return false
+		}
+
+		_, ok := accountedPods[native.GenerateNamespaceNameKey(namespace, name)]
+		return ok
+	}
+}
+
+func getDisruptionsAllowed(pdbs []*policyv1.PodDisruptionBudget) map[string]int32 {
+	disruptionsAllowed := make(map[string]int32, len(pdbs))
+	for _, pdb := range pdbs {
+		disruptionsAllowed[native.GenerateNamespaceNameKey(pdb.Namespace, pdb.Name)] = pdb.Status.DisruptionsAllowed
+	}
+	return disruptionsAllowed
+}
+
+// consumeDisruptionBudget returns false if evicting the pod violates any PodDisruptionBudget
+// selecting it, otherwise the disruptions allowed by these budgets are consumed.
+func consumeDisruptionBudget(pod *v1.Pod, pdbs []*policyv1.PodDisruptionBudget, disruptionsAllowed map[string]int32) bool {
+	matched := make([]string, 0)
+	for _, pdb := range pdbs {
+		if pdb.Namespace != pod.Namespace {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		// an empty selector selects nothing in PodDisruptionBudget
+		if err != nil || selector.Empty() || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+
+		key := native.GenerateNamespaceNameKey(pdb.Namespace, pdb.Name)
+		if disruptionsAllowed[key] <= 0 {
+			return false
+		}
+		matched = append(matched, key)
+	}
+
+	for _, key := range matched {
+		disruptionsAllowed[key]--
+	}
+	return true
+}
+
+// getNUMAZones returns NUMA zones of all sockets, and they can be modified in place
+func getNUMAZones(zones []*v1alpha1.TopologyZone) []*v1alpha1.TopologyZone {
+	numaZones := make([]*v1alpha1.TopologyZone, 0)
+	for _, topologyZone := range zones {
+		if topologyZone.Type != v1alpha1.TopologyTypeSocket {
+			continue
+		}
+		for _, child := range topologyZone.Children {
+			if child.Type == v1alpha1.TopologyTypeNuma {
+				numaZones = append(numaZones, child)
+			}
+		}
+	}
+	return numaZones
+}
+
+func getConsumerPod(consumer string, pods map[string]*v1.Pod) *v1.Pod {
+	namespace, name, _, err := native.ParseNamespaceNameUIDKey(consumer)
+	if err != nil {
+		return nil
+	}
+	return pods[native.GenerateNamespaceNameKey(namespace, name)]
+}
+
+func allocationCPU(alloc *v1alpha1.Allocation) *resource.Quantity {
+	if alloc.Requests == nil {
+		return resource.NewQuantity(0, resource.DecimalSI)
+	}
+	return alloc.Requests.Cpu()
+}
+
+func removeAllocation(allocations []*v1alpha1.Allocation, target *v1alpha1.Allocation) []*v1alpha1.Allocation {
+	result := make([]*v1alpha1.Allocation, 0, len(allocations))
+	for _, alloc := range allocations {
+		if alloc != target {
+			result = append(result, alloc)
+		}
+	}
+	return result
+}
@@ -0,0 +1,334 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/cache"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/util"
+)
+
+// testPodNominator is a PodNominator without any nominated pods
+type testPodNominator struct{}
+
+func (n *testPodNominator) AddNominatedPod(*framework.PodInfo, *framework.NominatingInfo) {}
+func (n *testPodNominator) DeleteNominatedPodIfExists(*v1.Pod)                            {}
+func (n *testPodNominator) UpdateNominatedPod(*v1.Pod, *framework.PodInfo)                {}
+func (n *testPodNominator) NominatedPodsForNode(string) []*framework.PodInfo              { return nil }
+
+func makePreemptionPod(nodeName, name, cpu string, qosLevel string) *v1.Pod {
+	resources := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse(cpu),
+		v1.ResourceMemory: resource.MustParse("1Gi"),
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        name,
+			UID:         "uid",
+			Annotations: map[string]string{consts.PodAnnotationQoSLevelKey: qosLevel},
+		},
+		Spec: v1.PodSpec{
+			NodeName: nodeName,
+			Containers: []v1.Container{
+				{Name: "c", Resources: v1.ResourceRequirements{Requests: resources, Limits: resources}},
+			},
+		},
+	}
+}
+
+func withPriority(pod *v1.Pod, priority int32) *v1.Pod {
+	pod.Spec.Priority = &priority
+	return pod
+}
+
+func withNUMABinding(pod *v1.Pod) *v1.Pod {
+	pod.Annotations[consts.PodAnnotationMemoryEnhancementKey] = `{"numa_binding":"true"}`
+	return pod
+}
+
+func withLabels(pod *v1.Pod, labels map[string]string) *v1.Pod {
+	pod.Labels = labels
+	return pod
+}
+
+func makePreemptionNUMAZone(numaID string, pods ...*v1.Pod) *v1alpha1.TopologyZone {
+	capacity := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("4"),
+		v1.ResourceMemory: resource.MustParse("8Gi"),
+	}
+	allocatable := capacity.DeepCopy()
+	zone := &v1alpha1.TopologyZone{
+		Name: numaID,
+		Type: v1alpha1.TopologyTypeNuma,
+		Resources: v1alpha1.Resources{
+			Capacity:    &capacity,
+			Allocatable: &allocatable,
+		},
+	}
+	for _, pod := range pods {
+		requests := pod.Spec.Containers[0].Resources.Requests.DeepCopy()
+		zone.Allocations = append(zone.Allocations, &v1alpha1.Allocation{
+			Consumer: fmt.Sprintf("%s/%s/%s", pod.Namespace, pod.Name, pod.UID),
+			Requests: &requests,
+		})
+	}
+	return zone
+}
+
+func makePreemptionCNR(nodeName string, numaZones ...*v1alpha1.TopologyZone) *v1alpha1.CustomNodeResource {
+	return &v1alpha1.CustomNodeResource{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+		Status: v1alpha1.CustomNodeResourceStatus{
+			TopologyPolicy: v1alpha1.TopologyPolicySingleNUMANodeContainerLevel,
+			TopologyZone: []*v1alpha1.TopologyZone{
+				{Name: "0", Type: v1alpha1.TopologyTypeSocket, Children: numaZones},
+			},
+		},
+	}
+}
+
+type postFilterTestCase struct {
+	name            string
+	pod             *v1.Pod
+	statusMap       framework.NodeToStatusMap
+	pdbs            []*policyv1.PodDisruptionBudget
+	wantNominated   string
+	wantCode        framework.Code
+	wantEvictedPods []string
+}
+
+func runPostFilterTest(t *testing.T, tt postFilterTestCase, policy consts.ResourcePluginPolicyName, pods []*v1.Pod, nodeNames []string) {
+	nodes := make([]*v1.Node, 0, len(nodeNames))
+	for _, nodeName := range nodeNames {
+		nodes = append(nodes, &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}})
+	}
+	objects := make([]runtime.Object, 0, len(pods)+len(tt.pdbs)+1)
+	// the preemptor is got from the pod lister by the preemption evaluator
+	objects = append(objects, tt.pod)
+	for _, pod := range pods {
+		objects = append(objects, pod)
+	}
+	for _, pdb := range tt.pdbs {
+		objects = append(objects, pdb)
+	}
+	client := fake.NewSimpleClientset(objects...)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+
+	f, err := frameworkruntime.NewFramework(nil, nil,
+		frameworkruntime.WithSnapshotSharedLister(newTestSharedLister(pods, nodes)),
+		frameworkruntime.WithClientSet(client),
+		frameworkruntime.WithInformerFactory(informerFactory),
+		frameworkruntime.WithEventRecorder(&events.FakeRecorder{}),
+		frameworkruntime.WithPodNominator(&testPodNominator{}))
+	require.NoError(t, err)
+	tm, err := MakeTestTm(MakeTestArgs(config.MostAllocated, []string{"cpu", "memory"}, policy), f)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	informerFactory.Start(ctx.Done())
+	informerFactory.WaitForCacheSync(ctx.Done())
+
+	result, status := tm.(*TopologyMatch).PostFilter(ctx, framework.NewCycleState(), tt.pod, tt.statusMap)
+	assert.Equal(t, tt.wantCode, status.Code())
+	if tt.wantNominated != "" {
+		require.NotNil(t, result)
+		assert.Equal(t, tt.wantNominated, result.NominatingInfo.NominatedNodeName)
+	} else if result != nil {
+		assert.Empty(t, result.NominatingInfo.NominatedNodeName)
+	}
+
+	evicted := make([]string, 0)
+	for _, pod := range pods {
+		_, err := client.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			evicted = append(evicted, pod.Name)
+		}
+	}
+	assert.ElementsMatch(t, tt.wantEvictedPods, evicted)
+}
+
+func TestPostFilter(t *testing.T) {
+	util.SetQoSConfig(generic.NewQoSConfiguration())
+
+	// numa0 needs two reclaimed pods to be evicted, while numa1 needs only one
+	preemptNode := "node-preemption-reclaimed"
+	r1 := withLabels(makePreemptionPod(preemptNode, "r1", "2", consts.PodAnnotationQoSLevelReclaimedCores), map[string]string{"app": "r1"})
+	r2 := makePreemptionPod(preemptNode, "r2", "1", consts.PodAnnotationQoSLevelReclaimedCores)
+	s1 := makePreemptionPod(preemptNode, "s1", "1", consts.PodAnnotationQoSLevelSharedCores)
+	r3 := withLabels(makePreemptionPod(preemptNode, "r3", "3", consts.PodAnnotationQoSLevelReclaimedCores), map[string]string{"app": "r3"})
+	s2 := makePreemptionPod(preemptNode, "s2", "1", consts.PodAnnotationQoSLevelSharedCores)
+
+	// no reclaimed pods to evict
+	sharedNode := "node-preemption-shared"
+	s3 := makePreemptionPod(sharedNode, "s3", "4", consts.PodAnnotationQoSLevelSharedCores)
+	s4 := makePreemptionPod(sharedNode, "s4", "4", consts.PodAnnotationQoSLevelSharedCores)
+
+	c := cache.GetCache()
+	c.AddOrUpdateCNR(makePreemptionCNR(preemptNode, makePreemptionNUMAZone("0", r1, r2, s1), makePreemptionNUMAZone("1", r3, s2)))
+	c.AddOrUpdateCNR(makePreemptionCNR(sharedNode, makePreemptionNUMAZone("0", s3), makePreemptionNUMAZone("1", s4)))
+
+	failedByTopology := framework.NewStatus(framework.Unschedulable, "cannot align container").WithFailedPlugin(TopologyMatchName)
+	failedByOthers := framework.NewStatus(framework.Unschedulable, "insufficient cpu").WithFailedPlugin("NodeResourcesFit")
+	makePDB := func(app string, disruptionsAllowed int32) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pdb-" + app},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+			},
+			Status: policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: disruptionsAllowed},
+		}
+	}
+
+	tests := []postFilterTestCase{
+		{
+			name: "preempt reclaimed pods on the numa with fewest victims",
+			pod:  withPriority(makePreemptionPod("", "preemptor", "3", consts.PodAnnotationQoSLevelSharedCores), 100),
+			statusMap: framework.NodeToStatusMap{
+				preemptNode: failedByTopology,
+				sharedNode:  failedByTopology,
+			},
+			wantNominated:   preemptNode,
+			wantCode:        framework.Success,
+			wantEvictedPods: []string{"r3"},
+		},
+		{
+			name: "reclaimed pods protected by pdb are not preempted",
+			pod:  withPriority(makePreemptionPod("", "preemptor", "3", consts.PodAnnotationQoSLevelSharedCores), 100),
+			statusMap: framework.NodeToStatusMap{
+				preemptNode: failedByTopology,
+			},
+			pdbs:            []*policyv1.PodDisruptionBudget{makePDB("r1", 1), makePDB("r3", 0)},
+			wantNominated:   preemptNode,
+			wantCode:        framework.Success,
+			wantEvictedPods: []string{"r1", "r2"},
+		},
+		{
+			name: "all reclaimed pods protected by pdb",
+			pod:  withPriority(makePreemptionPod("", "preemptor", "3", consts.PodAnnotationQoSLevelSharedCores), 100),
+			statusMap: framework.NodeToStatusMap{
+				preemptNode: failedByTopology,
+			},
+			pdbs:     []*policyv1.PodDisruptionBudget{makePDB("r1", 0), makePDB("r3", 0)},
+			wantCode: framework.Unschedulable,
+		},
+		{
+			name: "reclaimed pods with the same priority are not preempted",
+			pod:  makePreemptionPod("", "preemptor", "3", consts.PodAnnotationQoSLevelSharedCores),
+			statusMap: framework.NodeToStatusMap{
+				preemptNode: failedByTopology,
+			},
+			wantCode: framework.Unschedulable,
+		},
+		{
+			name: "nodes failed by other plugins are skipped",
+			pod:  withPriority(makePreemptionPod("", "preemptor", "3", consts.PodAnnotationQoSLevelSharedCores), 100),
+			statusMap: framework.NodeToStatusMap{
+				preemptNode: failedByOthers,
+			},
+			wantCode: framework.Unschedulable,
+		},
+		{
+			name: "no reclaimed pods to preempt",
+			pod:  withPriority(makePreemptionPod("", "preemptor", "3", consts.PodAnnotationQoSLevelSharedCores), 100),
+			statusMap: framework.NodeToStatusMap{
+				sharedNode: failedByTopology,
+			},
+			wantCode: framework.Unschedulable,
+		},
+		{
+			name: "reclaimed pods can't preempt",
+			pod:  withPriority(makePreemptionPod("", "preemptor", "3", consts.PodAnnotationQoSLevelReclaimedCores), 100),
+			statusMap: framework.NodeToStatusMap{
+				preemptNode: failedByTopology,
+			},
+			wantCode: framework.Unschedulable,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			runPostFilterTest(t, tt, consts.ResourcePluginPolicyNameNative,
+				[]*v1.Pod{r1, r2, s1, r3, s2, s3, s4}, []string{preemptNode, sharedNode})
+		})
+	}
+}
+
+func TestPostFilterDynamicPolicy(t *testing.T) {
+	util.SetQoSConfig(generic.NewQoSConfiguration())
+
+	// numa0 needs one numaBinding reclaimed pod to be evicted, while numa1 needs two, and
+	// reclaimed pods without numaBinding are not accounted since they shrink in the reclaim pool
+	nodeName := "node-preemption-dynamic"
+	d1 := withNUMABinding(makePreemptionPod(nodeName, "d1", "2", consts.PodAnnotationQoSLevelDedicatedCores))
+	rnb1 := withNUMABinding(makePreemptionPod(nodeName, "rnb1", "2", consts.PodAnnotationQoSLevelReclaimedCores))
+	snb1 := withNUMABinding(makePreemptionPod(nodeName, "snb1", "2", consts.PodAnnotationQoSLevelSharedCores))
+	rnb2 := withNUMABinding(makePreemptionPod(nodeName, "rnb2", "1", consts.PodAnnotationQoSLevelReclaimedCores))
+	rnb3 := withNUMABinding(makePreemptionPod(nodeName, "rnb3", "1", consts.PodAnnotationQoSLevelReclaimedCores))
+	r4 := makePreemptionPod(nodeName, "r4", "1", consts.PodAnnotationQoSLevelReclaimedCores)
+	pods := []*v1.Pod{d1, rnb1, snb1, rnb2, rnb3, r4}
+
+	cache.GetCache().AddOrUpdateCNR(makePreemptionCNR(nodeName,
+		makePreemptionNUMAZone("0", d1, rnb1), makePreemptionNUMAZone("1", snb1, rnb2, rnb3, r4)))
+
+	failedByTopology := framework.NewStatus(framework.Unschedulable, "cannot align container").WithFailedPlugin(TopologyMatchName)
+
+	tests := []postFilterTestCase{
+		{
+			name:            "dedicated pod preempts numaBinding reclaimed pods",
+			pod:             withPriority(withNUMABinding(makePreemptionPod("", "preemptor", "2", consts.PodAnnotationQoSLevelDedicatedCores)), 100),
+			statusMap:       framework.NodeToStatusMap{nodeName: failedByTopology},
+			wantNominated:   nodeName,
+			wantCode:        framework.Success,
+			wantEvictedPods: []string{"rnb1"},
+		},
+		{
+			name:      "shared pod is not supported",
+			pod:       withPriority(withNUMABinding(makePreemptionPod("", "preemptor", "2", consts.PodAnnotationQoSLevelSharedCores)), 100),
+			statusMap: framework.NodeToStatusMap{nodeName: failedByTopology},
+			wantCode:  framework.Unschedulable,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			runPostFilterTest(t, tt, consts.ResourcePluginPolicyNameDynamic, pods, []string{nodeName})
+		})
+	}
+}
@@ -18,6 +18,7 @@ package noderesourcetopology
 
 import (
 	"context"
+	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -57,14 +58,56 @@ func (tm *TopologyMatch) Reserve(ctx context.Context, state *framework.CycleStat
 				adjustExclusivePodRequest(podCopy, *numaCapacity, tm.alignedResources)
 			}
 		}
-		cache.GetCache().ReserveNodeResource(nodeName, podCopy)
+		if gang, minMember, ok := util.GetPodGroup(pod); ok {
+			cache.GetCache().ReserveGangPodResource(gang, tm.getRequiredGangMembers(gang, minMember, pod), nodeName, podCopy)
+		} else {
+			cache.GetCache().ReserveNodeResource(nodeName, podCopy)
+		}
 	}
 	return framework.NewStatus(framework.Success, "")
 }
 
 func (tm *TopologyMatch) Unreserve(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) {
 	if tm.topologyMatchSupport(pod) {
-		cache.GetCache().UnreserveNodeResource(nodeName, pod)
+		if gang, _, ok := util.GetPodGroup(pod); ok {
+			rollback := cache.GetCache().UnreserveGangPodResource(gang, nodeName, pod)
+			tm.rejectWaitingPods(rollback, fmt.Sprintf("pod group %v is rolled back since pod %v is unreserved", gang, pod.Name))
+		} else {
+			cache.GetCache().UnreserveNodeResource(nodeName, pod)
+		}
+	}
+}
+
+// getRequiredGangMembers returns the number of members to be reserved before the pod group
+// can be committed, and members which have already been bound are excluded, so that pods
+// recreated for a running pod group won't wait for the whole pod group.
+func (tm *TopologyMatch) getRequiredGangMembers(gang string, minMember int, pod *corev1.Pod) int {
+	nodeInfos, err := tm.sharedLister.NodeInfos().List()
+	if err != nil {
+		klog.Errorf("[TopologyMatch] list node infos fail: %v", err)
+		return minMember
+	}
+
+	bound := 0
+	for _, nodeInfo := range nodeInfos {
+		for _, podInfo := range nodeInfo.Pods {
+			p := podInfo.Pod
+			if p.UID == pod.UID || tm.handle.GetWaitingPod(p.UID) != nil {
+				continue
+			}
+			if g, _, ok := util.GetPodGroup(p); ok && g == gang {
+				bound++
+			}
+		}
+	}
+	return minMember - bound
+}
+
+func (tm *TopologyMatch) rejectWaitingPods(pods []*corev1.Pod, msg string) {
+	for _, pod := range pods {
+		if waitingPod := tm.handle.GetWaitingPod(pod.UID); waitingPod != nil {
+			waitingPod.Reject(TopologyMatchName, msg)
+		}
 	}
 }
 
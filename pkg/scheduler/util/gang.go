@@ -0,0 +1,62 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strconv"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+)
+
+// DefaultGangWaitingTimeout is the default max duration for members of a pod group to wait for the others
+const DefaultGangWaitingTimeout = 60 * time.Second
+
+var gangWaitingTimeout = DefaultGangWaitingTimeout
+
+// SetGangWaitingTimeout sets the max duration for members of a pod group to wait for the others,
+// and it should be called before plugins are created.
+func SetGangWaitingTimeout(timeout time.Duration) {
+	gangWaitingTimeout = timeout
+}
+
+// GetGangWaitingTimeout returns the max duration for members of a pod group to wait for the others
+func GetGangWaitingTimeout() time.Duration {
+	return gangWaitingTimeout
+}
+
+// GetPodGroup returns the key (namespace/name) and the min member of the pod group
+// that the pod belongs to, and false if the pod doesn't belong to any pod group, or
+// the min member is not larger than 1 since there is nothing to be waited for.
+func GetPodGroup(pod *v1.Pod) (string, int, bool) {
+	if pod == nil {
+		return "", 0, false
+	}
+
+	name, ok := pod.Labels[consts.PodGroupLabelKey]
+	if !ok || name == "" {
+		return "", 0, false
+	}
+
+	minMember, err := strconv.Atoi(pod.Annotations[consts.PodAnnotationPodGroupMinMemberKey])
+	if err != nil || minMember <= 1 {
+		return "", 0, false
+	}
+	return pod.Namespace + "/" + name, minMember, true
+}
@@ -43,6 +43,11 @@ func IsReclaimedPod(pod *v1.Pod) bool {
 	return ok
 }
 
+func IsSharedPod(pod *v1.Pod) bool {
+	ok, _ := qosConfig.CheckSharedQoSForPod(pod)
+	return ok
+}
+
 func IsDedicatedPod(pod *v1.Pod) bool {
 	ok, _ := qosConfig.CheckDedicatedQoSForPod(pod)
 	return ok
@@ -237,6 +237,12 @@ func IsContainerCgroupExist(podUID, containerID string) (bool, error) {
 	return general.IsPathExists(containerAbsCGPath), nil
 }
 
+// IsPodCgroupExist returns true if the pod-level cgroup exists in any kubernetes cgroup path
+func IsPodCgroupExist(podUID string) bool {
+	_, err := GetPodAbsCgroupPath("", podUID)
+	return err == nil
+}
+
 func IsContainerCgroupFileExist(subsys, podUID, containerId, cgroupFileName string) (bool, error) {
 	absCgroupPath, err := GetContainerAbsCgroupPath(subsys, podUID, containerId)
 	if err != nil {
@@ -18,6 +18,7 @@ package common
 
 import (
 	"fmt"
+	"strconv"
 )
 
 const (
@@ -34,8 +35,12 @@ const (
 	CgroupSubsysMemory = "memory"
 	CgroupSubsysCPU    = "cpu"
 	CgroupSubsysIO     = "io"
+	// CgroupSubsysBlkIO is the blkio sub-system in cgroupv1
+	CgroupSubsysBlkIO = "blkio"
 	// CgroupSubsysNetCls is the net_cls sub-system
 	CgroupSubsysNetCls = "net_cls"
+	// CgroupSubsysHugeTLB is the hugetlb sub-system
+	CgroupSubsysHugeTLB = "hugetlb"
 
 	PodCgroupPathPrefix        = "pod"
 	CgroupFsRootPath           = "/kubepods"
@@ -76,6 +81,9 @@ type MemoryData struct {
 	WmarkRatio int32
 	// SwapMaxInBytes < 0 means disable cgroup-level swap
 	SwapMaxInBytes int64
+	// HighInBytes for memory.high (only supported by cgroupv2)
+	// memory allocation will be throttled and reclaimed above it, and HighInBytes < 0 means max.
+	HighInBytes int64
 }
 
 type PressureType int
@@ -85,6 +93,15 @@ const (
 	FULL
 )
 
+// PressureResource is the resource name of pressure stall information, i.e. cpu.pressure
+type PressureResource string
+
+const (
+	PressureResourceCPU    PressureResource = "cpu"
+	PressureResourceMemory PressureResource = "memory"
+	PressureResourceIO     PressureResource = "io"
+)
+
 // MemoryPressure get cgroup memory pressure
 type MemoryPressure struct {
 	Avg10  uint64
@@ -175,6 +192,31 @@ func (iocmd *IOCostModelData) String() string {
 		iocmd.CtrlMode, iocmd.Model, iocmd.ReadBPS, iocmd.ReadSeqIOPS, iocmd.ReadRandIOPS, iocmd.WriteBPS, iocmd.WriteSeqIOPS, iocmd.WriteRandIOPS)
 }
 
+// IOThrottleData is the io.max data in cgroupv2 (or blkio.throttle.* in cgroupv1),
+// zero value of each field means unlimited
+type IOThrottleData struct {
+	ReadBps   uint64 `json:"read_bps"`   // read bytes per second
+	WriteBps  uint64 `json:"write_bps"`  // write bytes per second
+	ReadIOPS  uint64 `json:"read_iops"`  // read io per second
+	WriteIOPS uint64 `json:"write_iops"` // write io per second
+}
+
+func (iotd *IOThrottleData) String() string {
+	if iotd == nil {
+		return ""
+	}
+
+	formatLimit := func(limit uint64) string {
+		if limit == 0 {
+			return "max"
+		}
+		return strconv.FormatUint(limit, 10)
+	}
+
+	return fmt.Sprintf("rbps=%s wbps=%s riops=%s wiops=%s",
+		formatLimit(iotd.ReadBps), formatLimit(iotd.WriteBps), formatLimit(iotd.ReadIOPS), formatLimit(iotd.WriteIOPS))
+}
+
 // MemoryStats get cgroup memory data
 type MemoryStats struct {
 	Limit uint64
@@ -206,6 +248,16 @@ type MemoryMetrics struct {
 	// memory.memsw.usage_in_bytes Reports the total size in
 	// bytes of the memory and swap space used by tasks in the cgroup.
 	MemSWUsage uint64
+	// SwapUsage reports the size in bytes of swap space used by tasks in the cgroup.
+	SwapUsage uint64
+
+	Mapped       uint64
+	ActiveAnon   uint64
+	InactiveAnon uint64
+	ActiveFile   uint64
+	InactiveFile uint64
+	Pgfault      uint64
+	Pgmajfault   uint64
 }
 
 // MemoryNumaMetrics get per-numa level memory cgroup metrics
@@ -225,6 +277,19 @@ type CPUMetrics struct {
 	UsageTotal  uint64
 	UsageKernel uint64
 	UsageUser   uint64
+
+	NrPeriods   uint64
+	NrThrottled uint64
+	// ThrottledTime is the total throttled time in nanoseconds
+	ThrottledTime uint64
+}
+
+// IOMetrics get io cgroup metrics, accumulated among all devices
+type IOMetrics struct {
+	ReadBytes  uint64
+	WriteBytes uint64
+	ReadIOs    uint64
+	WriteIOs   uint64
 }
 
 // CgroupMetrics cgroup metrics
@@ -232,6 +297,22 @@ type CgroupMetrics struct {
 	CPU    *CPUMetrics
 	Memory *MemoryMetrics
 	Pid    *PidMetrics
+	IO     *IOMetrics
+}
+
+// PressureAvg is the average percentage of time in which tasks are stalled
+// in the last 10s/60s/300s, and Total is the absolute stall time in microseconds
+type PressureAvg struct {
+	Avg10  float64
+	Avg60  float64
+	Avg300 float64
+	Total  uint64
+}
+
+// PressureStats get cgroup pressure (psi) data of a given resource
+type PressureStats struct {
+	Some PressureAvg
+	Full PressureAvg
 }
 
 type CgroupResources struct {
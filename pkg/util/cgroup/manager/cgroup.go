@@ -177,6 +177,53 @@ func ApplyIOWeightWithAbsolutePath(absCgroupPath string, devID string, weight ui
 	return GetManager().ApplyIOWeight(absCgroupPath, devID, weight)
 }
 
+func ApplyIOThrottleWithAbsolutePath(absCgroupPath string, devID string, data *common.IOThrottleData) error {
+	if data == nil {
+		return fmt.Errorf("ApplyIOThrottleWithAbsolutePath with nil cgroup data")
+	}
+
+	return GetManager().ApplyIOThrottle(absCgroupPath, devID, data)
+}
+
+// ApplyIOThrottleForContainer applies the io throttle config of a block device for a container.
+func ApplyIOThrottleForContainer(podUID, containerId string, devID string, data *common.IOThrottleData) error {
+	if data == nil {
+		return fmt.Errorf("ApplyIOThrottleForContainer with nil cgroup data")
+	}
+
+	subsys := common.CgroupSubsysIO
+	if !common.CheckCgroup2UnifiedMode() {
+		subsys = common.CgroupSubsysBlkIO
+	}
+
+	ioAbsCGPath, err := common.GetContainerAbsCgroupPath(subsys, podUID, containerId)
+	if err != nil {
+		return fmt.Errorf("GetContainerAbsCgroupPath failed with error: %v", err)
+	}
+
+	return ApplyIOThrottleWithAbsolutePath(ioAbsCGPath, devID, data)
+}
+
+// ApplyIOThrottleForPod applies the io throttle config of a block device for a pod,
+// so that it's shared by all containers of the pod.
+func ApplyIOThrottleForPod(podUID string, devID string, data *common.IOThrottleData) error {
+	if data == nil {
+		return fmt.Errorf("ApplyIOThrottleForPod with nil cgroup data")
+	}
+
+	subsys := common.CgroupSubsysIO
+	if !common.CheckCgroup2UnifiedMode() {
+		subsys = common.CgroupSubsysBlkIO
+	}
+
+	ioAbsCGPath, err := common.GetPodAbsCgroupPath(subsys, podUID)
+	if err != nil {
+		return fmt.Errorf("GetPodAbsCgroupPath failed with error: %v", err)
+	}
+
+	return ApplyIOThrottleWithAbsolutePath(ioAbsCGPath, devID, data)
+}
+
 func ApplyUnifiedDataWithAbsolutePath(absCgroupPath, cgroupFileName, data string) error {
 	return GetManager().ApplyUnifiedData(absCgroupPath, cgroupFileName, data)
 }
@@ -204,6 +251,10 @@ func GetMemoryPressureWithAbsolutePath(absCgroupPath string, t common.PressureTy
 	return GetManager().GetMemoryPressure(absCgroupPath, t)
 }
 
+func GetPressureWithAbsolutePath(absCgroupPath string, resource common.PressureResource) (*common.PressureStats, error) {
+	return GetManager().GetPressure(absCgroupPath, resource)
+}
+
 func GetIOCostQoSWithRelativePath(relCgroupPath string) (map[string]*common.IOCostQoSData, error) {
 	absCgroupPath := common.GetAbsCgroupPath(common.CgroupSubsysIO, relCgroupPath)
 	return GetIOCostQoSWithAbsolutePath(absCgroupPath)
@@ -427,6 +478,24 @@ func SetExtraCGMemLimitWithTimeoutAndAbsCGPath(timeoutSecs int, absCgroupPath st
 	return nil
 }
 
+// SetMemoryHighWithAbsolutePath sets memory.high to throttle memory allocation of the cgroup,
+// and negative nbytes means removing the throttling
+func SetMemoryHighWithAbsolutePath(ctx context.Context, absCgroupPath string, nbytes int64) error {
+	if !common.CheckCgroup2UnifiedMode() {
+		general.InfofV(6, "[SetMemoryHighWithAbsolutePath] is not supported on cgroupv1")
+		return nil
+	} else if nbytes == 0 {
+		return fmt.Errorf("invalid memory high nbytes: %d", nbytes)
+	}
+
+	err := GetManager().ApplyMemory(absCgroupPath, &common.MemoryData{HighInBytes: nbytes})
+	_ = asyncworker.EmitAsyncedMetrics(ctx, metrics.ConvertMapToTags(map[string]string{
+		"absCgroupPath": absCgroupPath,
+		"succeeded":     fmt.Sprintf("%v", err == nil),
+	})...)
+	return err
+}
+
 func SetSwapMaxWithAbsolutePathToParentCgroupRecursive(absCgroupPath string) error {
 	if !common.CheckCgroup2UnifiedMode() {
 		general.Infof("[SetSwapMaxWithAbsolutePathToParentCgroupRecursive] is not supported on cgroupv1")
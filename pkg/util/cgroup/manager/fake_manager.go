@@ -48,6 +48,10 @@ func (f *FakeCgroupManager) ApplyIOWeight(absCgroupPath string, devID string, we
 	return nil
 }
 
+func (f *FakeCgroupManager) ApplyIOThrottle(absCgroupPath string, devID string, data *common.IOThrottleData) error {
+	return nil
+}
+
 func (f *FakeCgroupManager) ApplyUnifiedData(absCgroupPath, cgroupFileName, data string) error {
 	return nil
 }
@@ -64,6 +68,10 @@ func (m *FakeCgroupManager) GetMemoryPressure(absCgroupPath string, t common.Pre
 	return nil, nil
 }
 
+func (m *FakeCgroupManager) GetPressure(absCgroupPath string, resource common.PressureResource) (*common.PressureStats, error) {
+	return nil, nil
+}
+
 func (f *FakeCgroupManager) GetCPU(absCgroupPath string) (*common.CPUStats, error) {
 	return nil, nil
 }
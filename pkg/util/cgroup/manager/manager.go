@@ -41,11 +41,13 @@ type Manager interface {
 	ApplyIOCostQoS(absCgroupPath string, devID string, data *common.IOCostQoSData) error
 	ApplyIOCostModel(absCgroupPath string, devID string, data *common.IOCostModelData) error
 	ApplyIOWeight(absCgroupPath string, devID string, weight uint64) error
+	ApplyIOThrottle(absCgroupPath string, devID string, data *common.IOThrottleData) error
 	ApplyUnifiedData(absCgroupPath, cgroupFileName, data string) error
 
 	GetMemory(absCgroupPath string) (*common.MemoryStats, error)
 	GetNumaMemory(absCgroupPath string) (map[int]*common.MemoryNumaMetrics, error)
 	GetMemoryPressure(absCgroupPath string, t common.PressureType) (*common.MemoryPressure, error)
+	GetPressure(absCgroupPath string, resource common.PressureResource) (*common.PressureStats, error)
 	GetCPU(absCgroupPath string) (*common.CPUStats, error)
 	GetCPUSet(absCgroupPath string) (*common.CPUSetStats, error)
 	GetIOCostQoS(absCgroupPath string) (map[string]*common.IOCostQoSData, error)
@@ -22,6 +22,7 @@ package v1
 import (
 	"errors"
 	"fmt"
+	"os"
 	"path"
 	"path/filepath"
 	"strconv"
@@ -29,6 +30,7 @@ import (
 	"syscall"
 
 	"github.com/containerd/cgroups"
+	statsv1 "github.com/containerd/cgroups/stats/v1"
 	libcgroups "github.com/opencontainers/runc/libcontainer/cgroups"
 	"github.com/opencontainers/runc/libcontainer/cgroups/fscommon"
 	"k8s.io/klog/v2"
@@ -214,6 +216,72 @@ func (m *manager) ApplyIOWeight(absCgroupPath string, devID string, weight uint6
 	return errors.New("cgroups v1 does not support io.weight")
 }
 
+func (m *manager) ApplyIOThrottle(absCgroupPath string, devID string, data *common.IOThrottleData) error {
+	if data == nil {
+		return fmt.Errorf("ApplyIOThrottle got nil data")
+	}
+
+	// in cgroupv1, writing zero to blkio.throttle.* removes the limit of the device
+	throttleFiles := []struct {
+		file  string
+		limit uint64
+	}{
+		{file: "blkio.throttle.read_bps_device", limit: data.ReadBps},
+		{file: "blkio.throttle.write_bps_device", limit: data.WriteBps},
+		{file: "blkio.throttle.read_iops_device", limit: data.ReadIOPS},
+		{file: "blkio.throttle.write_iops_device", limit: data.WriteIOPS},
+	}
+
+	for _, tf := range throttleFiles {
+		curLimit, found, err := m.getDeviceThrottle(absCgroupPath, tf.file, devID)
+		if err != nil {
+			return fmt.Errorf("try getDeviceThrottle before ApplyIOThrottle failed with error: %v", err)
+		}
+
+		// devices without any limit don't show up in blkio.throttle.*
+		if (found && curLimit == tf.limit) || (!found && tf.limit == 0) {
+			klog.V(5).Infof("[CgroupV1] %s: %d in cgroupPath: %s for device: %s isn't changed, not to apply it",
+				tf.file, tf.limit, absCgroupPath, devID)
+			continue
+		}
+
+		dataContent := fmt.Sprintf("%s %d", devID, tf.limit)
+		if err, applied, oldData := common.InstrumentedWriteFileIfChange(absCgroupPath, tf.file, dataContent); err != nil {
+			return err
+		} else if applied {
+			klog.Infof("[CgroupV1] apply %s for device: %s successfully, cgroupPath: %s, data: %v, old data: %v\n",
+				tf.file, devID, absCgroupPath, dataContent, oldData)
+		}
+	}
+
+	return nil
+}
+
+// getDeviceThrottle returns the limit of the given device in the blkio.throttle.* file,
+// since the file lists limits of all devices and can't be compared as a whole.
+func (m *manager) getDeviceThrottle(absCgroupPath, file, devID string) (uint64, bool, error) {
+	throttleFile := path.Join(absCgroupPath, file)
+	contents, err := os.ReadFile(throttleFile)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to ReadFile %s, err %v", throttleFile, err)
+	}
+
+	for _, line := range strings.Split(string(contents), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != devID {
+			continue
+		}
+
+		limit, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to parse %s in %s, err %v", line, throttleFile, err)
+		}
+		return limit, true, nil
+	}
+
+	return 0, false, nil
+}
+
 func (m *manager) ApplyUnifiedData(absCgroupPath, cgroupFileName, data string) error {
 	if err, applied, oldData := common.InstrumentedWriteFileIfChange(absCgroupPath, cgroupFileName, data); err != nil {
 		return err
@@ -353,6 +421,10 @@ func (m *manager) GetDeviceIOWeight(absCgroupPath string, devID string) (uint64,
 	return 0, false, errors.New("cgroups v1 does not support io.weight")
 }
 
+func (m *manager) GetPressure(absCgroupPath string, resource common.PressureResource) (*common.PressureStats, error) {
+	return nil, errors.New("cgroups v1 does not support pressure")
+}
+
 func (m *manager) GetIOStat(absCgroupPath string) (map[string]map[string]string, error) {
 	return nil, errors.New("cgroups v1 does not support io.stat")
 }
@@ -381,6 +453,7 @@ func (m *manager) GetMetrics(relCgroupPath string, subsystemMap map[string]struc
 		Memory: &common.MemoryMetrics{},
 		CPU:    &common.CPUMetrics{},
 		Pid:    &common.PidMetrics{},
+		IO:     &common.IOMetrics{},
 	}
 	for subsys := range subsystems {
 		switch subsys {
@@ -395,6 +468,18 @@ func (m *manager) GetMetrics(relCgroupPath string, subsystemMap map[string]struc
 				cm.Memory.UsageUsage = stats.Memory.Usage.Usage
 				cm.Memory.KernelUsage = stats.Memory.Kernel.Usage
 				cm.Memory.MemSWUsage = stats.Memory.Swap.Usage
+				// memsw accounts both memory and swap in cgroup v1
+				if cm.Memory.MemSWUsage > cm.Memory.UsageUsage {
+					cm.Memory.SwapUsage = cm.Memory.MemSWUsage - cm.Memory.UsageUsage
+				}
+
+				cm.Memory.Mapped = stats.Memory.TotalMappedFile
+				cm.Memory.ActiveAnon = stats.Memory.TotalActiveAnon
+				cm.Memory.InactiveAnon = stats.Memory.TotalInactiveAnon
+				cm.Memory.ActiveFile = stats.Memory.TotalActiveFile
+				cm.Memory.InactiveFile = stats.Memory.TotalInactiveFile
+				cm.Memory.Pgfault = stats.Memory.TotalPgFault
+				cm.Memory.Pgmajfault = stats.Memory.TotalPgMajFault
 			}
 		case cgroups.Cpu:
 			if stats.CPU == nil {
@@ -403,6 +488,18 @@ func (m *manager) GetMetrics(relCgroupPath string, subsystemMap map[string]struc
 				cm.CPU.UsageTotal = stats.CPU.Usage.Total
 				cm.CPU.UsageKernel = stats.CPU.Usage.Kernel
 				cm.CPU.UsageUser = stats.CPU.Usage.User
+				if stats.CPU.Throttling != nil {
+					cm.CPU.NrPeriods = stats.CPU.Throttling.Periods
+					cm.CPU.NrThrottled = stats.CPU.Throttling.ThrottledPeriods
+					cm.CPU.ThrottledTime = stats.CPU.Throttling.ThrottledTime
+				}
+			}
+		case cgroups.Blkio:
+			if stats.Blkio == nil {
+				klog.Infof("[cgroupv1] get cgroup stats blkio nil, cgroupPath: %v\n", relCgroupPath)
+			} else {
+				cm.IO.ReadBytes, cm.IO.WriteBytes = sumBlkIOEntries(stats.Blkio.IoServiceBytesRecursive)
+				cm.IO.ReadIOs, cm.IO.WriteIOs = sumBlkIOEntries(stats.Blkio.IoServicedRecursive)
 			}
 		case cgroups.Pids:
 			if stats.Pids == nil {
@@ -437,6 +534,23 @@ func (m *manager) GetTasks(absCgroupPath string) ([]string, error) {
 	return tasks, nil
 }
 
+// sumBlkIOEntries accumulates the read and write values among all devices
+func sumBlkIOEntries(entries []*statsv1.BlkIOEntry) (read, write uint64) {
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			read += entry.Value
+		case "write":
+			write += entry.Value
+		}
+	}
+	return read, write
+}
+
 func newHierarchy(enabled map[cgroups.Name]struct{}) cgroups.Hierarchy {
 	return func() ([]cgroups.Subsystem, error) {
 		ss, err := cgroups.V1()
@@ -20,9 +20,13 @@ limitations under the License.
 package v1
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
 )
 
@@ -97,3 +101,29 @@ func Test_manager_ApplyMemory(t *testing.T) {
 		})
 	}
 }
+
+func Test_manager_ApplyIOThrottle(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"blkio.throttle.read_bps_device":   "8:16 100\n8:0 1000\n",
+		"blkio.throttle.write_bps_device":  "8:0 2000\n",
+		"blkio.throttle.read_iops_device":  "8:16 100\n",
+		"blkio.throttle.write_iops_device": "",
+	}
+	for file, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644))
+	}
+
+	// limits of the device are not changed, so that no file is rewritten
+	m := NewManager()
+	assert.NoError(t, m.ApplyIOThrottle(dir, "8:0", &common.IOThrottleData{ReadBps: 1000, WriteBps: 2000}))
+	for file, content := range files {
+		got, err := os.ReadFile(filepath.Join(dir, file))
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(got))
+	}
+
+	assert.Error(t, m.ApplyIOThrottle(filepath.Join(dir, "not-exist"), "8:0", &common.IOThrottleData{}))
+}
@@ -60,6 +60,10 @@ func (m *unsupportedManager) ApplyIOWeight(absCgroupPath string, devID string, w
 	return fmt.Errorf("unsupported manager v1")
 }
 
+func (m *unsupportedManager) ApplyIOThrottle(absCgroupPath string, devID string, data *common.IOThrottleData) error {
+	return fmt.Errorf("unsupported manager v1")
+}
+
 func (m *unsupportedManager) ApplyUnifiedData(absCgroupPath, cgroupFileName, data string) error {
 	return fmt.Errorf("unsupported manager v1")
 }
@@ -76,6 +80,10 @@ func (m *unsupportedManager) GetMemoryPressure(absCgroupPath string, t common.Pr
 	return nil, fmt.Errorf("unsupported manager v1")
 }
 
+func (m *unsupportedManager) GetPressure(_ string, _ common.PressureResource) (*common.PressureStats, error) {
+	return nil, fmt.Errorf("unsupported manager v1")
+}
+
 func (m *unsupportedManager) GetCPU(_ string) (*common.CPUStats, error) {
 	return nil, fmt.Errorf("unsupported manager v1")
 }
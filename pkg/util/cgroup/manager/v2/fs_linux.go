@@ -94,6 +94,19 @@ func (m *manager) ApplyMemory(absCgroupPath string, data *common.MemoryData) err
 			klog.Infof("[CgroupV2] apply memory swap max successfully, cgroupPath: %s, data: %v, old data: %v\n", absCgroupPath, swapMax, oldData)
 		}
 	}
+
+	if data.HighInBytes != 0 {
+		// Do Not change memory high setting if HighInBytes equals to 0
+		high := "max"
+		if data.HighInBytes > 0 {
+			high = numToStr(data.HighInBytes)
+		}
+		if err, applied, oldData := common.InstrumentedWriteFileIfChange(absCgroupPath, "memory.high", high); err != nil {
+			return err
+		} else if applied {
+			klog.Infof("[CgroupV2] apply memory high successfully, cgroupPath: %s, data: %v, old data: %v\n", absCgroupPath, high, oldData)
+		}
+	}
 	return nil
 }
 
@@ -257,6 +270,54 @@ func (m *manager) ApplyIOWeight(absCgroupPath string, devID string, weight uint6
 	return nil
 }
 
+func (m *manager) ApplyIOThrottle(absCgroupPath string, devID string, data *common.IOThrottleData) error {
+	if data == nil {
+		return fmt.Errorf("ApplyIOThrottle got nil data")
+	}
+
+	dataContent := data.String()
+	curData, found, err := m.getDeviceIOMax(absCgroupPath, devID)
+	if err != nil {
+		return fmt.Errorf("try getDeviceIOMax before ApplyIOThrottle failed with error: %v", err)
+	}
+
+	if found && curData == dataContent {
+		klog.V(5).Infof("[CgroupV2] io.max: %s in cgroupPath: %s for device: %s isn't changed, not to apply it",
+			curData, absCgroupPath, devID)
+		return nil
+	}
+
+	if err, applied, oldData := common.InstrumentedWriteFileIfChange(absCgroupPath, "io.max", fmt.Sprintf("%s %s", devID, dataContent)); err != nil {
+		return err
+	} else if applied {
+		klog.Infof("[CgroupV2] apply io.max for device: %s successfully,"+
+			"cgroupPath: %s, added data: %s, old data: %s\n", devID, absCgroupPath, dataContent, oldData)
+	}
+
+	return nil
+}
+
+// getDeviceIOMax returns the io.max limits of the given device in the same format as
+// common.IOThrottleData.String; devices without any limit don't show up in io.max.
+func (m *manager) getDeviceIOMax(absCgroupPath string, devID string) (string, bool, error) {
+	ioMaxFile := path.Join(absCgroupPath, "io.max")
+	contents, err := ioutil.ReadFile(ioMaxFile)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to ReadFile %s, err %v", ioMaxFile, err)
+	}
+
+	for _, line := range strings.Split(string(contents), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != devID {
+			continue
+		}
+
+		return strings.Join(fields[1:], " "), true, nil
+	}
+
+	return "", false, nil
+}
+
 func (m *manager) ApplyUnifiedData(absCgroupPath, cgroupFileName, data string) error {
 	if err, applied, oldData := common.InstrumentedWriteFileIfChange(absCgroupPath, cgroupFileName, data); err != nil {
 		return err
@@ -587,6 +648,64 @@ func (m *manager) GetDeviceIOWeight(absCgroupPath string, devID string) (uint64,
 	return weight, true, err
 }
 
+func (m *manager) GetPressure(absCgroupPath string, resource common.PressureResource) (*common.PressureStats, error) {
+	pressureFile := filepath.Join(absCgroupPath, fmt.Sprintf("%s.pressure", resource))
+	contents, err := ioutil.ReadFile(pressureFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ReadFile %s, err %v", pressureFile, err)
+	}
+
+	return parsePressureStats(string(contents))
+}
+
+// parsePressureStats parses the content of pressure file like:
+// some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+// full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+// and the "full" line doesn't exist for cpu.pressure on some kernels.
+func parsePressureStats(content string) (*common.PressureStats, error) {
+	stats := &common.PressureStats{}
+	for _, line := range strings.Split(strings.TrimSpace(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var avg *common.PressureAvg
+		switch fields[0] {
+		case pressureTypeToString(common.SOME):
+			avg = &stats.Some
+		case pressureTypeToString(common.FULL):
+			avg = &stats.Full
+		default:
+			return nil, fmt.Errorf("invalid pressure line %s", line)
+		}
+
+		for _, field := range fields[1:] {
+			kv := strings.Split(field, "=")
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid pressure field %s in line %s", field, line)
+			}
+
+			var err error
+			switch kv[0] {
+			case "avg10":
+				avg.Avg10, err = strconv.ParseFloat(kv[1], 64)
+			case "avg60":
+				avg.Avg60, err = strconv.ParseFloat(kv[1], 64)
+			case "avg300":
+				avg.Avg300, err = strconv.ParseFloat(kv[1], 64)
+			case "total":
+				avg.Total, err = strconv.ParseUint(kv[1], 10, 64)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid pressure field %s in line %s: %v", field, line, err)
+			}
+		}
+	}
+
+	return stats, nil
+}
+
 func (m *manager) GetIOStat(absCgroupPath string) (map[string]map[string]string, error) {
 	ioStatFile := path.Join(absCgroupPath, "io.stat")
 	contents, err := ioutil.ReadFile(ioStatFile)
@@ -644,6 +763,7 @@ func (m *manager) GetMetrics(relCgroupPath string, _ map[string]struct{}) (*comm
 		Memory: &common.MemoryMetrics{},
 		CPU:    &common.CPUMetrics{},
 		Pid:    &common.PidMetrics{},
+		IO:     &common.IOMetrics{},
 	}
 	if stats.Memory == nil {
 		klog.Infof("[cgroupv2] get cgroup stats memory nil, cgroupPath: %v\n", relCgroupPath)
@@ -655,7 +775,16 @@ func (m *manager) GetMetrics(relCgroupPath string, _ map[string]struct{}) (*comm
 
 		cm.Memory.KernelUsage = stats.Memory.KernelStack + stats.Memory.Slab + stats.Memory.Sock
 		cm.Memory.UsageUsage = stats.Memory.Usage
-		cm.Memory.MemSWUsage = stats.Memory.SwapUsage
+		cm.Memory.MemSWUsage = stats.Memory.Usage + stats.Memory.SwapUsage
+		cm.Memory.SwapUsage = stats.Memory.SwapUsage
+
+		cm.Memory.Mapped = stats.Memory.FileMapped
+		cm.Memory.ActiveAnon = stats.Memory.ActiveAnon
+		cm.Memory.InactiveAnon = stats.Memory.InactiveAnon
+		cm.Memory.ActiveFile = stats.Memory.ActiveFile
+		cm.Memory.InactiveFile = stats.Memory.InactiveFile
+		cm.Memory.Pgfault = stats.Memory.Pgfault
+		cm.Memory.Pgmajfault = stats.Memory.Pgmajfault
 	}
 
 	if stats.CPU == nil {
@@ -664,6 +793,21 @@ func (m *manager) GetMetrics(relCgroupPath string, _ map[string]struct{}) (*comm
 		cm.CPU.UsageTotal = stats.CPU.UsageUsec * 1000
 		cm.CPU.UsageUser = stats.CPU.UserUsec * 1000
 		cm.CPU.UsageKernel = stats.CPU.SystemUsec * 1000
+
+		cm.CPU.NrPeriods = stats.CPU.NrPeriods
+		cm.CPU.NrThrottled = stats.CPU.NrThrottled
+		cm.CPU.ThrottledTime = stats.CPU.ThrottledUsec * 1000
+	}
+
+	if stats.Io == nil {
+		klog.Infof("[cgroupv2] get cgroup stats io nil, cgroupPath: %v\n", relCgroupPath)
+	} else {
+		for _, entry := range stats.Io.Usage {
+			cm.IO.ReadBytes += entry.Rbytes
+			cm.IO.WriteBytes += entry.Wbytes
+			cm.IO.ReadIOs += entry.Rios
+			cm.IO.WriteIOs += entry.Wios
+		}
 	}
 
 	if stats.Pids == nil {
@@ -20,6 +20,8 @@ limitations under the License.
 package v2
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -96,6 +98,17 @@ func Test_manager_ApplyMemory(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "test apply memory with HighInBytes",
+			m:    NewManager(),
+			args: args{
+				absCgroupPath: "test-fake-path",
+				data: &common.MemoryData{
+					HighInBytes: 4234,
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -360,6 +373,65 @@ func Test_manager_ApplyIOWeight(t *testing.T) {
 	}
 }
 
+func Test_manager_ApplyIOThrottle(t *testing.T) {
+	t.Parallel()
+
+	cgroupPath := t.TempDir()
+	err := os.WriteFile(filepath.Join(cgroupPath, "io.max"), []byte("8:0 rbps=1048576 wbps=max riops=max wiops=1000\n"), 0o644)
+	if err != nil {
+		t.Fatalf("failed to prepare io.max: %v", err)
+	}
+
+	type args struct {
+		absCgroupPath string
+		devID         string
+		data          *common.IOThrottleData
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "test apply io throttle with nil data",
+			args: args{
+				absCgroupPath: cgroupPath,
+				devID:         "8:0",
+			},
+			wantErr: true,
+		},
+		{
+			name: "test apply io throttle with fake path",
+			args: args{
+				absCgroupPath: "test-fake-path",
+				devID:         "8:0",
+				data:          &common.IOThrottleData{ReadBps: 1048576},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test apply unchanged io throttle",
+			args: args{
+				absCgroupPath: cgroupPath,
+				devID:         "8:0",
+				data:          &common.IOThrottleData{ReadBps: 1048576, WriteIOPS: 1000},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := &manager{}
+			if err := m.ApplyIOThrottle(tt.args.absCgroupPath, tt.args.devID, tt.args.data); (err != nil) != tt.wantErr {
+				t.Errorf("manager.ApplyIOThrottle() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func Test_manager_ApplyUnifiedData(t *testing.T) {
 	t.Parallel()
 
@@ -949,3 +1021,54 @@ func Test_parseDeviceIOCostModel(t *testing.T) {
 		})
 	}
 }
+
+func Test_parsePressureStats(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		want    *common.PressureStats
+		wantErr bool
+	}{
+		{
+			name:    "some and full",
+			content: "some avg10=1.50 avg60=0.25 avg300=0.00 total=12345\nfull avg10=0.50 avg60=0.10 avg300=0.00 total=345\n",
+			want: &common.PressureStats{
+				Some: common.PressureAvg{Avg10: 1.5, Avg60: 0.25, Total: 12345},
+				Full: common.PressureAvg{Avg10: 0.5, Avg60: 0.1, Total: 345},
+			},
+		},
+		{
+			name:    "some only",
+			content: "some avg10=3.00 avg60=2.00 avg300=1.00 total=1",
+			want: &common.PressureStats{
+				Some: common.PressureAvg{Avg10: 3, Avg60: 2, Avg300: 1, Total: 1},
+			},
+		},
+		{
+			name:    "invalid line",
+			content: "none avg10=3.00",
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			content: "some avg10=abc",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parsePressureStats(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parsePressureStats() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePressureStats() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
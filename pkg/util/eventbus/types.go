@@ -72,3 +72,57 @@ type SyscallLog struct {
 	Time     time.Time
 	KeyValue map[string]string
 }
+
+const (
+	QRMAllocationActionAllocate   = "Allocate"
+	QRMAllocationActionReallocate = "Reallocate"
+	// QRMAllocationActionAdvisor means the allocation is adjusted by advice from sysadvisor,
+	// e.g. resizing cpu pools or updating memory control knobs
+	QRMAllocationActionAdvisor = "Advisor"
+)
+
+// QRMAllocationEvent describes an allocation decision made by qrm plugins
+type QRMAllocationEvent struct {
+	BaseEventImpl
+	Cost          time.Duration
+	PluginName    string
+	ResourceName  string
+	Action        string
+	PodUID        string
+	PodNamespace  string
+	PodName       string
+	ContainerName string
+	// PoolName is set for decisions on a shared pool rather than a specific container
+	PoolName string
+	// CgroupPath is set for decisions on a cgroup rather than a specific container
+	CgroupPath string
+	QoSLevel   string
+	Request    string
+	Result     string
+	OldResult  string
+	Reason     string
+	Error      string
+}
+
+const (
+	EvictionPhaseCandidate = "Candidate"
+	EvictionPhaseKilled    = "Killed"
+	EvictionPhaseKillFail  = "KillFailed"
+	// EvictionPhaseShadow means the pod would have been evicted if the plugin wasn't in shadow run mode
+	EvictionPhaseShadow = "Shadow"
+)
+
+// EvictionEvent describes a decision or a kill result made by eviction manager
+type EvictionEvent struct {
+	BaseEventImpl
+	Phase              string
+	PluginName         string
+	KillerName         string
+	Scope              string
+	PodUID             string
+	PodNamespace       string
+	PodName            string
+	Reason             string
+	GracePeriodSeconds int64
+	Error              string
+}
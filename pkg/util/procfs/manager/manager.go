@@ -33,6 +33,8 @@ var (
 type ProcFSManager interface {
 	GetCPUInfo() ([]procfs.CPUInfo, error)
 	GetProcStat() (procfs.Stat, error)
+	GetMeminfo() (procfs.Meminfo, error)
+	GetLoadAvg() (*procfs.LoadAvg, error)
 	GetPidComm(pid int) (string, error)
 	GetPidCmdline(pid int) ([]string, error)
 	GetPidCgroups(pid int) ([]procfs.Cgroup, error)
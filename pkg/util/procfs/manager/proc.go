@@ -39,6 +39,16 @@ func GetProcStat() (procfs.Stat, error) {
 	return GetProcFSManager().GetProcStat()
 }
 
+// GetMeminfo returns the Meminfo of the host.
+func GetMeminfo() (procfs.Meminfo, error) {
+	return GetProcFSManager().GetMeminfo()
+}
+
+// GetLoadAvg returns the LoadAvg of the host.
+func GetLoadAvg() (*procfs.LoadAvg, error) {
+	return GetProcFSManager().GetLoadAvg()
+}
+
 // GetPidComm returns the comm of the given pid.
 func GetPidComm(pid int) (string, error) {
 	return GetProcFSManager().GetPidComm(pid)
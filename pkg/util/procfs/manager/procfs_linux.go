@@ -58,6 +58,16 @@ func (m *manager) GetProcStat() (procfs.Stat, error) {
 	return m.procfs.Stat()
 }
 
+// GetMeminfo returns the meminfo of the host.
+func (m *manager) GetMeminfo() (procfs.Meminfo, error) {
+	return m.procfs.Meminfo()
+}
+
+// GetLoadAvg returns the loadavg of the host.
+func (m *manager) GetLoadAvg() (*procfs.LoadAvg, error) {
+	return m.procfs.LoadAvg()
+}
+
 // GetPidComm returns the comm of the given pid.
 func (m *manager) GetPidComm(pid int) (string, error) {
 	proc, err := m.procfs.Proc(pid)
@@ -37,6 +37,15 @@ type TimeSeries struct {
 	Samples []Sample
 }
 
+// DatasourceType is the name of datasource that history usage is queried from
+type DatasourceType string
+
+const (
+	PrometheusDatasource   DatasourceType = "Prometheus"
+	CustomMetricDatasource DatasourceType = "CustomMetric"
+	LocalFileDatasource    DatasourceType = "LocalFile"
+)
+
 // WorkloadKind is k8s resource kind
 type WorkloadKind string
 
@@ -56,6 +65,9 @@ type Metric struct {
 	ContainerName string
 	Resource      v1.ResourceName
 	Selectors     string
+	// Datasource is the name of datasource to query this metric from,
+	// and the default datasource will be used if it's empty
+	Datasource string
 }
 
 func (m *Metric) SetSelector(selectors map[string]string) {
@@ -77,12 +89,32 @@ func (m *Metric) SetSelector(selectors map[string]string) {
 
 type Query struct {
 	// to be extended when new datasource is added
-	Prometheus *PrometheusQuery
+	Prometheus   *PrometheusQuery
+	CustomMetric *CustomMetricQuery
+	LocalFile    *LocalFileQuery
 }
 type PrometheusQuery struct {
 	Query string
 }
 
+// CustomMetricQuery is the query for katalyst custom metric store
+type CustomMetricQuery struct {
+	Namespace      string
+	MetricName     string
+	MetricSelector string
+	// ObjectNamePattern is the regular expression to match names of the pods
+	ObjectNamePattern string
+}
+
+// LocalFileQuery is the query for time series in local snapshot files
+type LocalFileQuery struct {
+	Namespace     string
+	Kind          string
+	WorkloadName  string
+	ContainerName string
+	Resource      v1.ResourceName
+}
+
 func NewTimeSeries() *TimeSeries {
 	return &TimeSeries{
 		Labels:  make(map[string]string),
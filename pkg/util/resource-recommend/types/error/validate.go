@@ -26,6 +26,7 @@ const (
 	WorkloadNameIsEmpty              Code = "WorkloadNameIsEmpty"
 	WorkloadsUnsupported             Code = "WorkloadsUnsupported"
 	AlgorithmUnsupported             Code = "AlgorithmUnsupported"
+	DatasourceUnsupported            Code = "DatasourceUnsupported"
 	WorkloadMatchError               Code = "WorkloadMatchError"
 	WorkloadNotFound                 Code = "WorkloadNotFound"
 	ContainerPoliciesNotFound        Code = "ContainerPoliciesNotFound"
@@ -43,6 +44,7 @@ const (
 	WorkloadNameIsEmptyMessage              = "spec.targetRef.name cannot be empty"
 	WorkloadsUnsupportedMessage             = "spec.targetRef.kind %s is currently unsupported, only support in %s"
 	AlgorithmUnsupportedMessage             = "spec.resourcePolicy.algorithmPolicy.algorithm %s is currently unsupported, only support in %s"
+	DatasourceUnsupportedMessage            = "datasource %s is currently unsupported, only support in %s"
 	WorkloadNotFoundMessage                 = "workload not found"
 	WorkloadMatchedErrorMessage             = "workload matched err"
 	ContainerPoliciesNotFoundMessage        = "spec.containerPolicies cannot be empty"
@@ -80,6 +82,14 @@ func AlgorithmUnsupportedError(algorithm string, supportedAlgorithm []string) *C
 	}
 }
 
+func DatasourceUnsupportedError(datasource string, supportedDatasources []string) *CustomError {
+	return &CustomError{
+		Phase:   Validated,
+		Code:    DatasourceUnsupported,
+		Message: fmt.Sprintf(DatasourceUnsupportedMessage, datasource, supportedDatasources),
+	}
+}
+
 func ResourceNameUnsupportedError(msg string, arg ...any) *CustomError {
 	return &CustomError{
 		Phase:   Validated,
@@ -83,11 +83,16 @@ func (pc *ProcessConfig) Validate() error {
 }
 
 func (pc *ProcessConfig) GenerateTaskID() TaskID {
-	return TaskID(pc.ResourceRecommendNamespacedName.String() + "-" +
+	taskID := pc.ResourceRecommendNamespacedName.String() + "-" +
 		pc.Kind + "-" +
 		pc.APIVersion + "-" +
 		pc.WorkloadName + "-" +
 		pc.ContainerName + "-" +
 		string(pc.Resource) + "-" +
-		string(pc.Config))
+		string(pc.Config)
+	// keep task id unchanged for the default datasource
+	if pc.Datasource != "" {
+		taskID += "-" + pc.Datasource
+	}
+	return TaskID(taskID)
 }
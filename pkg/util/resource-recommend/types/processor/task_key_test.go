@@ -34,6 +34,7 @@ func TestProcessConfig_GenerateTaskID(t *testing.T) {
 		containerName      string
 		controlledResource v1.ResourceName
 		taskConfig         TaskConfigStr
+		datasource         string
 	}
 	tests := []struct {
 		name   string
@@ -58,10 +59,30 @@ func TestProcessConfig_GenerateTaskID(t *testing.T) {
 			},
 			want: "default/recommendation1-Deployment-app/v1-demo-c1-cpu-",
 		},
+		{
+			name: "with datasource",
+			fields: fields{
+				namespacedName: types.NamespacedName{
+					Name:      "recommendation1",
+					Namespace: "default",
+				},
+				targetRef: v1alpha1.CrossVersionObjectReference{
+					Kind:       "Deployment",
+					Name:       "demo",
+					APIVersion: "app/v1",
+				},
+				containerName:      "c1",
+				controlledResource: "cpu",
+				taskConfig:         "",
+				datasource:         "CustomMetric",
+			},
+			want: "default/recommendation1-Deployment-app/v1-demo-c1-cpu--CustomMetric",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			pc := NewProcessConfig(tt.fields.namespacedName, tt.fields.targetRef, tt.fields.containerName, tt.fields.controlledResource, tt.fields.taskConfig)
+			pc.Datasource = tt.fields.datasource
 			if got := pc.GenerateTaskID(); got != tt.want {
 				t.Errorf("Validate() error, got: %s, want: %s", got, tt.want)
 			}
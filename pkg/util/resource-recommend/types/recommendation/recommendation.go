@@ -28,6 +28,7 @@ import (
 
 	"github.com/kubewharf/katalyst-api/pkg/apis/recommendation/v1alpha1"
 	conditionstypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/conditions"
+	datasourcetypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/datasource"
 	errortypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/error"
 )
 
@@ -64,6 +65,18 @@ const (
 	ContainerPolicySelectAllFlag = "*"
 )
 
+const (
+	// DatasourceAnnotationKey is the annotation of ResourceRecommend to specify which datasource
+	// the history usage is queried from, and the default datasource will be used if it's not set
+	DatasourceAnnotationKey = "recommendation.katalyst.kubewharf.io/datasource"
+)
+
+var DatasourceTypes = []string{
+	string(datasourcetypes.PrometheusDatasource),
+	string(datasourcetypes.CustomMetricDatasource),
+	string(datasourcetypes.LocalFileDatasource),
+}
+
 type Recommendation struct {
 	types.NamespacedName
 	ObservedGeneration int64
@@ -77,6 +90,7 @@ type Config struct {
 	TargetRef       v1alpha1.CrossVersionObjectReference
 	Containers      []Container
 	AlgorithmPolicy v1alpha1.AlgorithmPolicy
+	Datasource      string
 }
 
 type Container struct {
@@ -114,6 +128,13 @@ func (r *Recommendation) SetConfig(ctx context.Context, client dynamic.Interface
 		return customErr
 	}
 
+	datasource, customErr := ValidateAndExtractDatasource(resourceRecommend.Annotations)
+	if customErr != nil {
+		klog.Errorf("annotation %s validate error, "+
+			"reason: %s, msg: %s", DatasourceAnnotationKey, customErr.Code, customErr.Message)
+		return customErr
+	}
+
 	containers, customErr := ValidateAndExtractContainers(ctx, client, resourceRecommend.Namespace,
 		targetRef, resourceRecommend.Spec.ResourcePolicy.ContainerPolicies, mapper)
 	if customErr != nil {
@@ -126,6 +147,7 @@ func (r *Recommendation) SetConfig(ctx context.Context, client dynamic.Interface
 		TargetRef:       targetRef,
 		AlgorithmPolicy: algorithmPolicy,
 		Containers:      containers,
+		Datasource:      datasource,
 	}
 	return nil
 }
@@ -66,6 +66,19 @@ func ValidateAndExtractAlgorithmPolicy(algorithmPolicyReq v1alpha1.AlgorithmPoli
 	return algorithmPolicy, nil
 }
 
+// ValidateAndExtractDatasource returns the datasource specified by annotation, and empty
+// value means the default datasource
+func ValidateAndExtractDatasource(annotations map[string]string) (string, *errortypes.CustomError) {
+	datasource := annotations[DatasourceAnnotationKey]
+	if datasource == "" {
+		return "", nil
+	}
+	if ok := general.SliceContains(DatasourceTypes, datasource); !ok {
+		return "", errortypes.DatasourceUnsupportedError(datasource, DatasourceTypes)
+	}
+	return datasource, nil
+}
+
 func ValidateAndExtractContainers(ctx context.Context, client dynamic.Interface, namespace string,
 	targetRef v1alpha1.CrossVersionObjectReference,
 	containerPolicies []v1alpha1.ContainerResourcePolicy,
@@ -113,6 +113,43 @@ func TestValidateAndExtractAlgorithmPolicy(t *testing.T) {
 	}
 }
 
+func TestValidateAndExtractDatasource(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        string
+		wantErr     *errortypes.CustomError
+	}{
+		{
+			name:        "datasource not specified",
+			annotations: nil,
+			want:        "",
+		},
+		{
+			name:        "supported datasource",
+			annotations: map[string]string{DatasourceAnnotationKey: "CustomMetric"},
+			want:        "CustomMetric",
+		},
+		{
+			name:        "unsupported datasource",
+			annotations: map[string]string{DatasourceAnnotationKey: "prom"},
+			want:        "",
+			wantErr:     errortypes.DatasourceUnsupportedError("prom", DatasourceTypes),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotErr := ValidateAndExtractDatasource(tt.annotations)
+			if got != tt.want {
+				t.Errorf("ValidateAndExtractDatasource() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotErr, tt.wantErr) {
+				t.Errorf("ValidateAndExtractDatasource() gotErr = %v, want %v", gotErr, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidateAndExtractContainers(t *testing.T) {
 	type args struct {
 		ctx               context.Context
@@ -0,0 +1,202 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	DefaultSysFSRoot = "/sys"
+
+	// zswap parameters are exposed as module parameters, while its
+	// statistics are only exposed in debugfs
+	zswapParametersDir = "module/zswap/parameters"
+	zswapStatsDir      = "kernel/debug/zswap"
+
+	zramDevicePattern = "block/zram*"
+
+	// zram accounts backing device statistics in 4K units
+	zramBackingDeviceUnit = 4096
+)
+
+// CompressedSwapStats is the aggregated statistics of all compressed swap
+// pools (zswap and zram devices) on the node.
+type CompressedSwapStats struct {
+	// Utilization is the max utilization among all compressed swap pools, in [0, 1]
+	Utilization float64
+	// OriginalBytes is the uncompressed size of data stored in compressed swap pools
+	OriginalBytes float64
+	// CompressedBytes is the compressed size of data stored in compressed swap pools
+	CompressedBytes float64
+	// WrittenBackBytes is the accumulated size of data written back to backing devices
+	WrittenBackBytes float64
+}
+
+// CompressionRatio returns the ratio of original size to compressed size,
+// and 0 means it's unknown since nothing has been stored yet.
+func (s *CompressedSwapStats) CompressionRatio() float64 {
+	if s.CompressedBytes <= 0 {
+		return 0
+	}
+	return s.OriginalBytes / s.CompressedBytes
+}
+
+// GetCompressedSwapStats reads statistics of zswap and zram devices under sysFSRoot,
+// and memTotal is used to calculate the size limit of zswap pool. It returns nil
+// if neither zswap nor zram is enabled on the node.
+func GetCompressedSwapStats(sysFSRoot string, memTotal float64) (*CompressedSwapStats, error) {
+	stats := &CompressedSwapStats{}
+
+	zswapEnabled, err := readZswapStats(sysFSRoot, memTotal, stats)
+	if err != nil {
+		return nil, fmt.Errorf("read zswap stats failed: %v", err)
+	}
+
+	zramEnabled, err := readZramStats(sysFSRoot, stats)
+	if err != nil {
+		return nil, fmt.Errorf("read zram stats failed: %v", err)
+	}
+
+	if !zswapEnabled && !zramEnabled {
+		return nil, nil
+	}
+	return stats, nil
+}
+
+func readZswapStats(sysFSRoot string, memTotal float64, stats *CompressedSwapStats) (bool, error) {
+	enabled, err := os.ReadFile(filepath.Join(sysFSRoot, zswapParametersDir, "enabled"))
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	} else if strings.TrimSpace(string(enabled)) != "Y" {
+		return false, nil
+	}
+
+	maxPoolPercent, err := general.ReadInt64FromFile(filepath.Join(sysFSRoot, zswapParametersDir, "max_pool_percent"))
+	if err != nil {
+		return false, err
+	}
+
+	// debugfs may be not mounted or not accessible, and then zswap is regarded as
+	// having no statistics instead of failing the zram ones
+	statsDir := filepath.Join(sysFSRoot, zswapStatsDir)
+	if _, err := os.Stat(statsDir); os.IsNotExist(err) || os.IsPermission(err) {
+		general.InfofV(4, "zswap is enabled but its statistics are unavailable: %v", err)
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	poolTotalSize, err := general.ReadInt64FromFile(filepath.Join(statsDir, "pool_total_size"))
+	if err != nil {
+		return false, err
+	}
+	storedPages, err := general.ReadInt64FromFile(filepath.Join(statsDir, "stored_pages"))
+	if err != nil {
+		return false, err
+	}
+	writtenBackPages, err := general.ReadInt64FromFile(filepath.Join(statsDir, "written_back_pages"))
+	if err != nil {
+		return false, err
+	}
+
+	pageSize := float64(os.Getpagesize())
+	if poolLimit := memTotal * float64(maxPoolPercent) / 100; poolLimit > 0 {
+		stats.Utilization = general.MaxFloat64(stats.Utilization, float64(poolTotalSize)/poolLimit)
+	}
+	stats.OriginalBytes += float64(storedPages) * pageSize
+	stats.CompressedBytes += float64(poolTotalSize)
+	stats.WrittenBackBytes += float64(writtenBackPages) * pageSize
+	return true, nil
+}
+
+// readZramStats reads mm_stat and bd_stat of all initialized zram devices, and the
+// utilization of a device is calculated by its memory limit if it's set, otherwise
+// by its disk size.
+func readZramStats(sysFSRoot string, stats *CompressedSwapStats) (bool, error) {
+	devices, err := filepath.Glob(filepath.Join(sysFSRoot, zramDevicePattern))
+	if err != nil {
+		return false, err
+	}
+
+	enabled := false
+	for _, device := range devices {
+		diskSize, err := general.ReadInt64FromFile(filepath.Join(device, "disksize"))
+		if err != nil {
+			return false, err
+		} else if diskSize == 0 {
+			// the device is not initialized
+			continue
+		}
+
+		// mm_stat: orig_data_size compr_data_size mem_used_total mem_limit ...
+		mmStat, err := readUint64Fields(filepath.Join(device, "mm_stat"), 4)
+		if err != nil {
+			return false, err
+		}
+		origDataSize, comprDataSize, memUsedTotal, memLimit := mmStat[0], mmStat[1], mmStat[2], mmStat[3]
+
+		utilization := float64(origDataSize) / float64(diskSize)
+		if memLimit > 0 {
+			utilization = general.MaxFloat64(utilization, float64(memUsedTotal)/float64(memLimit))
+		}
+		stats.Utilization = general.MaxFloat64(stats.Utilization, utilization)
+		stats.OriginalBytes += float64(origDataSize)
+		stats.CompressedBytes += float64(comprDataSize)
+
+		// bd_stat: bd_count bd_reads bd_writes, and it only exists if writeback is supported
+		bdStat, err := readUint64Fields(filepath.Join(device, "bd_stat"), 3)
+		if err == nil {
+			stats.WrittenBackBytes += float64(bdStat[2] * zramBackingDeviceUnit)
+		} else if !os.IsNotExist(err) {
+			return false, err
+		}
+		enabled = true
+	}
+	return enabled, nil
+}
+
+// readUint64Fields parses the first n space-separated fields of the file as uint64
+func readUint64Fields(file string, n int) ([]uint64, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Fields(string(b))
+	if len(fields) < n {
+		return nil, fmt.Errorf("%s has %d fields, less than %d", file, len(fields), n)
+	}
+
+	values := make([]uint64, 0, n)
+	for _, field := range fields[:n] {
+		value, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s in %s: %v", field, file, err)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
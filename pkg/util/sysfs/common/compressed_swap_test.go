@@ -0,0 +1,145 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSysFSFiles(t *testing.T, root string, files map[string]string) {
+	for file, content := range files {
+		path := filepath.Join(root, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+}
+
+func TestGetCompressedSwapStats(t *testing.T) {
+	t.Parallel()
+
+	pageSize := float64(os.Getpagesize())
+	zswapFiles := map[string]string{
+		"module/zswap/parameters/enabled":          "Y\n",
+		"module/zswap/parameters/max_pool_percent": "20\n",
+		"kernel/debug/zswap/pool_total_size":       "100\n",
+		"kernel/debug/zswap/stored_pages":          "1\n",
+		"kernel/debug/zswap/written_back_pages":    "2\n",
+	}
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		memTotal float64
+		want     *CompressedSwapStats
+		wantErr  bool
+	}{
+		{
+			name:  "no compressed swap",
+			files: map[string]string{"module/zswap/parameters/enabled": "N\n"},
+			want:  nil,
+		},
+		{
+			name:     "zswap",
+			files:    zswapFiles,
+			memTotal: 1000,
+			want: &CompressedSwapStats{
+				Utilization:      0.5,
+				OriginalBytes:    pageSize,
+				CompressedBytes:  100,
+				WrittenBackBytes: 2 * pageSize,
+			},
+		},
+		{
+			name: "zswap without debugfs falls back to zram",
+			files: map[string]string{
+				"module/zswap/parameters/enabled":          "Y\n",
+				"module/zswap/parameters/max_pool_percent": "20\n",
+				"block/zram0/disksize":                     "1000\n",
+				"block/zram0/mm_stat":                      "200 50 60 100 60 0 0 0\n",
+			},
+			memTotal: 1000,
+			want: &CompressedSwapStats{
+				Utilization:     0.6,
+				OriginalBytes:   200,
+				CompressedBytes: 50,
+			},
+		},
+		{
+			name: "zswap without debugfs",
+			files: map[string]string{
+				"module/zswap/parameters/enabled":          "Y\n",
+				"module/zswap/parameters/max_pool_percent": "20\n",
+			},
+			memTotal: 1000,
+			want:     nil,
+		},
+		{
+			name: "zram with and without memory limit",
+			files: map[string]string{
+				"block/zram0/disksize": "1000\n",
+				"block/zram0/mm_stat":  "200 50 60 100 60 0 0 0\n",
+				"block/zram0/bd_stat":  "1 2 3\n",
+				"block/zram1/disksize": "1000\n",
+				"block/zram1/mm_stat":  "800 300 320 0 320 0 0 0\n",
+				"block/zram2/disksize": "0\n",
+			},
+			want: &CompressedSwapStats{
+				Utilization:      0.8,
+				OriginalBytes:    1000,
+				CompressedBytes:  350,
+				WrittenBackBytes: 3 * zramBackingDeviceUnit,
+			},
+		},
+		{
+			name: "invalid zram mm_stat",
+			files: map[string]string{
+				"block/zram0/disksize": "1000\n",
+				"block/zram0/mm_stat":  "200 50\n",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			root := t.TempDir()
+			writeSysFSFiles(t, root, tt.files)
+
+			got, err := GetCompressedSwapStats(root, tt.memTotal)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompressedSwapStats_CompressionRatio(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, float64(0), (&CompressedSwapStats{OriginalBytes: 100}).CompressionRatio())
+	assert.Equal(t, float64(4), (&CompressedSwapStats{OriginalBytes: 100, CompressedBytes: 25}).CompressionRatio())
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	kubewebhook "github.com/slok/kubewebhook/pkg/webhook"
+	whcontext "github.com/slok/kubewebhook/pkg/webhook/context"
+	"github.com/slok/kubewebhook/pkg/webhook/validating"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	webhookconsts "github.com/kubewharf/katalyst-core/cmd/katalyst-webhook/app/webhook"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	webhookconfig "github.com/kubewharf/katalyst-core/pkg/config/webhook"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	podWebhookName = "pod-validating"
+)
+
+// WebhookPod is the implementation of Kubernetes Webhook
+// any implementation should at least implement the interface of mutating.Mutator of validating.Validator
+type WebhookPod struct {
+	ctx    context.Context
+	dryRun bool
+
+	validators    []WebhookPodValidator
+	metricEmitter metrics.MetricEmitter
+}
+
+// WebhookPodValidator validates the pod, and oldPod is nil unless the pod is being updated
+type WebhookPodValidator interface {
+	ValidatePod(pod, oldPod *core.Pod) (valid bool, message string, err error)
+}
+
+func NewWebhookPod(ctx context.Context, _ *katalystbase.GenericContext,
+	genericConf *generic.GenericConfiguration, _ *webhookconfig.GenericWebhookConfiguration,
+	_ *webhookconfig.WebhooksConfiguration, metricsEmitter metrics.MetricEmitter,
+) (kubewebhook.Webhook, webhookconsts.GenericStartFunc, error) {
+	wp := &WebhookPod{
+		ctx:    ctx,
+		dryRun: genericConf.DryRun,
+	}
+
+	wp.metricEmitter = metricsEmitter
+	if metricsEmitter == nil {
+		wp.metricEmitter = metrics.DummyMetrics{}
+	}
+
+	wp.validators = []WebhookPodValidator{
+		NewWebhookPodQoSValidator(genericConf.QoSConfiguration),
+	}
+
+	cfg := validating.WebhookConfig{
+		Name: "podValidator",
+		Obj:  &core.Pod{},
+	}
+
+	webhook, err := validating.NewWebhook(cfg, wp, nil, nil, nil)
+	if err != nil {
+		return nil, wp.Run, err
+	}
+	return webhook, wp.Run, nil
+}
+
+func (wp *WebhookPod) Run() bool {
+	klog.Infof("%s webhook is ready", podWebhookName)
+	return true
+}
+
+func (wp *WebhookPod) Validate(ctx context.Context, obj metav1.Object) (bool, validating.ValidatorResult, error) {
+	klog.V(5).Info("notice an obj to be validated")
+	pod, ok := obj.(*core.Pod)
+	if !ok {
+		err := fmt.Errorf("failed to convert obj to pod: %v", obj)
+		klog.Error(err.Error())
+		return false, validating.ValidatorResult{}, err
+	}
+	if pod == nil {
+		err := fmt.Errorf("pod can't be nil")
+		klog.Error(err.Error())
+		return false, validating.ValidatorResult{}, err
+	}
+
+	oldPod, err := getOldPod(ctx)
+	if err != nil {
+		klog.Error(err.Error())
+		return false, validating.ValidatorResult{}, err
+	}
+
+	klog.V(5).Infof("begin to validate pod %s", pod.Name)
+
+	for _, validator := range wp.validators {
+		succeed, msg, err := validator.ValidatePod(pod, oldPod)
+		if err != nil {
+			klog.Errorf("an err occurred when validating pod %s", pod.Name)
+			_ = wp.metricEmitter.StoreInt64("pod_validating_webhook_error", 1, metrics.MetricTypeNameCount,
+				metrics.MetricTag{Key: "namespace", Val: pod.Namespace})
+			return false, validating.ValidatorResult{}, err
+		} else if !succeed {
+			klog.Infof("pod %s didn't pass the webhook: %s", pod.Name, msg)
+			_ = wp.metricEmitter.StoreInt64("pod_validating_webhook_fail", 1, metrics.MetricTypeNameCount,
+				metrics.MetricTag{Key: "namespace", Val: pod.Namespace})
+			if !wp.dryRun {
+				return false, validating.ValidatorResult{Valid: false, Message: msg}, nil
+			}
+		}
+	}
+
+	_ = wp.metricEmitter.StoreInt64("pod_validating_webhook_succeed", 1, metrics.MetricTypeNameCount,
+		metrics.MetricTag{Key: "namespace", Val: pod.Namespace})
+	klog.V(4).Infof("pod %s passed the validation webhook", pod.Name)
+	return false, validating.ValidatorResult{Valid: true, Message: "validation succeed"}, nil
+}
+
+// getOldPod returns the pod before updated, and nil for other operations
+func getOldPod(ctx context.Context) (*core.Pod, error) {
+	ar := whcontext.GetAdmissionRequest(ctx)
+	if ar == nil || ar.Operation != admissionv1beta1.Update || len(ar.OldObject.Raw) == 0 {
+		return nil, nil
+	}
+
+	oldPod := &core.Pod{}
+	if err := json.Unmarshal(ar.OldObject.Raw, oldPod); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal old pod: %v", err)
+	}
+	return oldPod, nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	whcontext "github.com/slok/kubewebhook/pkg/webhook/context"
+	"github.com/stretchr/testify/assert"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	webhookconfig "github.com/kubewharf/katalyst-core/pkg/config/webhook"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+func getPodJSON(pod *v1.Pod) []byte {
+	bs, _ := json.Marshal(pod)
+	return bs
+}
+
+func TestValidatePod(t *testing.T) {
+	t.Parallel()
+
+	invalidPod := makeQoSPod("1", "node1", map[string]string{
+		apiconsts.PodAnnotationQoSLevelKey: "unknown_cores",
+	})
+	sharedPod := makeQoSPod("1", "node1", map[string]string{
+		apiconsts.PodAnnotationQoSLevelKey: apiconsts.PodAnnotationQoSLevelSharedCores,
+	})
+	dedicatedPod := makeQoSPod("1", "node1", map[string]string{
+		apiconsts.PodAnnotationQoSLevelKey: apiconsts.PodAnnotationQoSLevelDedicatedCores,
+	})
+
+	for _, tc := range []struct {
+		name    string
+		dryRun  bool
+		request *admissionv1beta1.AdmissionRequest
+		allowed bool
+	}{
+		{
+			name: "create valid pod",
+			request: &admissionv1beta1.AdmissionRequest{
+				UID:       "test",
+				Operation: admissionv1beta1.Create,
+				Object:    runtime.RawExtension{Raw: getPodJSON(sharedPod)},
+			},
+			allowed: true,
+		},
+		{
+			name: "create invalid pod",
+			request: &admissionv1beta1.AdmissionRequest{
+				UID:       "test",
+				Operation: admissionv1beta1.Create,
+				Object:    runtime.RawExtension{Raw: getPodJSON(invalidPod)},
+			},
+			allowed: false,
+		},
+		{
+			name:   "create invalid pod in dry run mode",
+			dryRun: true,
+			request: &admissionv1beta1.AdmissionRequest{
+				UID:       "test",
+				Operation: admissionv1beta1.Create,
+				Object:    runtime.RawExtension{Raw: getPodJSON(invalidPod)},
+			},
+			allowed: true,
+		},
+		{
+			name: "update qos level of running pod",
+			request: &admissionv1beta1.AdmissionRequest{
+				UID:       "test",
+				Operation: admissionv1beta1.Update,
+				Object:    runtime.RawExtension{Raw: getPodJSON(dedicatedPod)},
+				OldObject: runtime.RawExtension{Raw: getPodJSON(sharedPod)},
+			},
+			allowed: false,
+		},
+		{
+			name: "update running pod without changing qos annotations",
+			request: &admissionv1beta1.AdmissionRequest{
+				UID:       "test",
+				Operation: admissionv1beta1.Update,
+				Object:    runtime.RawExtension{Raw: getPodJSON(invalidPod)},
+				OldObject: runtime.RawExtension{Raw: getPodJSON(invalidPod)},
+			},
+			allowed: true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			genericConf := &generic.GenericConfiguration{
+				DryRun:           tc.dryRun,
+				QoSConfiguration: generic.NewQoSConfiguration(),
+			}
+			webhookGenericConf := webhookconfig.NewGenericWebhookConfiguration()
+
+			controlCtx, err := katalystbase.GenerateFakeGenericContext()
+			assert.NoError(t, err)
+
+			wh, run, err := NewWebhookPod(context.TODO(), controlCtx, genericConf, webhookGenericConf, nil, metrics.DummyMetrics{})
+			assert.NoError(t, err)
+			assert.True(t, run())
+
+			ctx := whcontext.SetAdmissionRequest(context.TODO(), tc.request)
+			gotResponse := wh.Review(ctx, &admissionv1beta1.AdmissionReview{Request: tc.request})
+			assert.Equal(t, tc.allowed, gotResponse.Allowed)
+			assert.Equal(t, tc.request.UID, gotResponse.UID)
+		})
+	}
+}
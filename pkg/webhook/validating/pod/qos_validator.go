@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"fmt"
+	"strconv"
+
+	core "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+	qosutil "github.com/kubewharf/katalyst-core/pkg/util/qos"
+)
+
+var validCPUBurstPolicies = sets.NewString(
+	apiconsts.PodAnnotationCPUEnhancementCPUBurstPolicyDefault,
+	apiconsts.PodAnnotationCPUEnhancementCPUBurstPolicyClosed,
+	apiconsts.PodAnnotationCPUEnhancementCPUBurstPolicyStatic,
+	apiconsts.PodAnnotationCPUEnhancementCPUBurstPolicyDynamic,
+)
+
+// WebhookPodQoSValidator validate:
+// 1. if qos level and enhancements can be parsed, by both default and expanded annotation keys
+// 2. if enhancements are compatible with qos level and resource requests
+// 3. if qos annotations of pods already assigned to nodes are not changed
+type WebhookPodQoSValidator struct {
+	qosConf *generic.QoSConfiguration
+}
+
+func NewWebhookPodQoSValidator(qosConf *generic.QoSConfiguration) *WebhookPodQoSValidator {
+	return &WebhookPodQoSValidator{
+		qosConf: qosConf,
+	}
+}
+
+func (qv *WebhookPodQoSValidator) ValidatePod(pod, oldPod *core.Pod) (valid bool, message string, err error) {
+	if pod == nil {
+		err := fmt.Errorf("pod is nil")
+		return false, err.Error(), err
+	}
+
+	if oldPod != nil {
+		// pods created before are not validated unless their qos annotations are changed
+		if apiequality.Semantic.DeepEqual(qv.qosConf.FilterQoSAndEnhancementMap(pod.Annotations),
+			qv.qosConf.FilterQoSAndEnhancementMap(oldPod.Annotations)) {
+			return true, "", nil
+		}
+
+		if native.IsAssignedPod(oldPod) {
+			return false, "qos annotations can't be changed after pod is assigned to node", nil
+		}
+	}
+
+	qosLevel, err := qv.qosConf.GetQoSLevelForPod(pod)
+	if err != nil {
+		return false, fmt.Sprintf("invalid qos level: %v", err), nil
+	}
+
+	if err := qv.qosConf.CheckQoSEnhancementsForPod(pod); err != nil {
+		return false, fmt.Sprintf("invalid qos enhancement: %v", err), nil
+	}
+
+	if msg, ok := qv.validateMemoryEnhancement(pod, qosLevel); !ok {
+		return false, msg, nil
+	}
+
+	if msg, ok := qv.validateCPUEnhancement(pod); !ok {
+		return false, msg, nil
+	}
+
+	return true, "", nil
+}
+
+func (qv *WebhookPodQoSValidator) validateMemoryEnhancement(pod *core.Pod, qosLevel string) (string, bool) {
+	memoryEnhancement := qosutil.ParseMemoryEnhancement(qv.qosConf, pod)
+	for _, key := range []string{
+		apiconsts.PodAnnotationMemoryEnhancementNumaBinding,
+		apiconsts.PodAnnotationMemoryEnhancementNumaExclusive,
+	} {
+		if value, ok := memoryEnhancement[key]; ok && value != "true" && value != "false" {
+			return fmt.Sprintf("invalid memory enhancement %s: %s", key, value), false
+		}
+	}
+
+	numaBinding := qosutil.AnnotationsIndicateNUMABinding(memoryEnhancement)
+	numaExclusive := memoryEnhancement[apiconsts.PodAnnotationMemoryEnhancementNumaExclusive] ==
+		apiconsts.PodAnnotationMemoryEnhancementNumaExclusiveEnable
+	if numaExclusive && (!numaBinding || qosLevel != apiconsts.PodAnnotationQoSLevelDedicatedCores) {
+		return "numa exclusive is only supported for dedicated_cores with numa binding", false
+	}
+
+	// cpus are allocated exclusively in whole for dedicated_cores with numa binding
+	if numaBinding && qosLevel == apiconsts.PodAnnotationQoSLevelDedicatedCores {
+		for _, container := range pod.Spec.Containers {
+			request := native.CPUQuantityGetter()(container.Resources.Requests)
+			if request.MilliValue()%1000 != 0 {
+				return fmt.Sprintf("container %s requests non-integer cpu %s for dedicated_cores with numa binding",
+					container.Name, request.String()), false
+			}
+		}
+	}
+
+	if _, invalid := qosutil.GetRSSOverUseEvictThreshold(qv.qosConf, pod); invalid {
+		return "invalid memory enhancement " + apiconsts.PodAnnotationMemoryEnhancementRssOverUseThreshold, false
+	}
+
+	if _, invalid := qosutil.GetOOMPriority(qv.qosConf, pod); invalid {
+		return "invalid memory enhancement " + apiconsts.PodAnnotationMemoryEnhancementOOMPriority, false
+	}
+	return "", true
+}
+
+func (qv *WebhookPodQoSValidator) validateCPUEnhancement(pod *core.Pod) (string, bool) {
+	cpuEnhancement := qv.qosConf.GetQoSEnhancementKVs(pod, map[string]string{}, apiconsts.PodAnnotationCPUEnhancementKey)
+	if policy, ok := cpuEnhancement[apiconsts.PodAnnotationCPUEnhancementCPUBurstPolicy]; ok && !validCPUBurstPolicies.Has(policy) {
+		return fmt.Sprintf("invalid cpu enhancement %s: %s", apiconsts.PodAnnotationCPUEnhancementCPUBurstPolicy, policy), false
+	}
+
+	if _, _, err := qosutil.GetPodCPUBurstPercentFromCPUEnhancement(qv.qosConf, pod); err != nil {
+		return fmt.Sprintf("invalid cpu enhancement %s: %v", apiconsts.PodAnnotationCPUEnhancementCPUBurstPercent, err), false
+	}
+
+	if rate, ok := cpuEnhancement[apiconsts.PodAnnotationCPUEnhancementSuppressionToleranceRate]; ok {
+		if _, err := strconv.ParseFloat(rate, 64); err != nil {
+			return fmt.Sprintf("invalid cpu enhancement %s: %s", apiconsts.PodAnnotationCPUEnhancementSuppressionToleranceRate, rate), false
+		}
+	}
+	return "", true
+}
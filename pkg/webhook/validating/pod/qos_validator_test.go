@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+func makeQoSPod(cpu string, nodeName string, annotations map[string]string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "pod1",
+			Annotations: annotations,
+		},
+		Spec: v1.PodSpec{
+			NodeName: nodeName,
+			Containers: []v1.Container{
+				{
+					Name: "c1",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+						Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestWebhookPodQoSValidator_ValidatePod(t *testing.T) {
+	t.Parallel()
+
+	dedicatedNUMABinding := map[string]string{
+		apiconsts.PodAnnotationQoSLevelKey:          apiconsts.PodAnnotationQoSLevelDedicatedCores,
+		apiconsts.PodAnnotationMemoryEnhancementKey: `{"numa_binding":"true","numa_exclusive":"true"}`,
+	}
+
+	for _, tc := range []struct {
+		name   string
+		pod    *v1.Pod
+		oldPod *v1.Pod
+		valid  bool
+	}{
+		{
+			name:  "pod without qos annotations",
+			pod:   makeQoSPod("1.5", "", nil),
+			valid: true,
+		},
+		{
+			name:  "dedicated_cores with numa binding",
+			pod:   makeQoSPod("2", "", dedicatedNUMABinding),
+			valid: true,
+		},
+		{
+			name: "unknown qos level",
+			pod: makeQoSPod("1", "", map[string]string{
+				apiconsts.PodAnnotationQoSLevelKey: "unknown_cores",
+			}),
+			valid: false,
+		},
+		{
+			name: "qos level by expanded key",
+			pod: makeQoSPod("1", "", map[string]string{
+				"legacy-qos": "dedicated",
+			}),
+			valid: true,
+		},
+		{
+			name: "invalid memory enhancement json",
+			pod: makeQoSPod("1", "", map[string]string{
+				apiconsts.PodAnnotationMemoryEnhancementKey: `{"numa_binding":true}`,
+			}),
+			valid: false,
+		},
+		{
+			name: "invalid cpu enhancement json by expanded key",
+			pod: makeQoSPod("1", "", map[string]string{
+				"legacy-cpu-enhancement": `{"cpu_burst_policy":`,
+			}),
+			valid: false,
+		},
+		{
+			name: "invalid numa binding value",
+			pod: makeQoSPod("1", "", map[string]string{
+				apiconsts.PodAnnotationMemoryEnhancementKey: `{"numa_binding":"yes"}`,
+			}),
+			valid: false,
+		},
+		{
+			name: "numa exclusive for shared_cores",
+			pod: makeQoSPod("1", "", map[string]string{
+				apiconsts.PodAnnotationMemoryEnhancementKey: `{"numa_binding":"true","numa_exclusive":"true"}`,
+			}),
+			valid: false,
+		},
+		{
+			name: "numa exclusive without numa binding",
+			pod: makeQoSPod("1", "", map[string]string{
+				apiconsts.PodAnnotationQoSLevelKey:          apiconsts.PodAnnotationQoSLevelDedicatedCores,
+				apiconsts.PodAnnotationMemoryEnhancementKey: `{"numa_exclusive":"true"}`,
+			}),
+			valid: false,
+		},
+		{
+			name:  "non-integer cpu for dedicated_cores with numa binding",
+			pod:   makeQoSPod("1.5", "", dedicatedNUMABinding),
+			valid: false,
+		},
+		{
+			name: "non-integer cpu for shared_cores with numa binding",
+			pod: makeQoSPod("1.5", "", map[string]string{
+				apiconsts.PodAnnotationMemoryEnhancementKey: `{"numa_binding":"true"}`,
+			}),
+			valid: true,
+		},
+		{
+			name: "invalid oom priority",
+			pod: makeQoSPod("1", "", map[string]string{
+				apiconsts.PodAnnotationMemoryEnhancementKey: `{"oom_priority":"high"}`,
+			}),
+			valid: false,
+		},
+		{
+			name: "invalid cpu burst policy",
+			pod: makeQoSPod("1", "", map[string]string{
+				apiconsts.PodAnnotationCPUEnhancementKey: `{"cpu_burst_policy":"unknown"}`,
+			}),
+			valid: false,
+		},
+		{
+			name:   "qos annotations are changed for assigned pod",
+			pod:    makeQoSPod("2", "node1", dedicatedNUMABinding),
+			oldPod: makeQoSPod("2", "node1", nil),
+			valid:  false,
+		},
+		{
+			name:   "qos annotations are changed for pending pod",
+			pod:    makeQoSPod("2", "", dedicatedNUMABinding),
+			oldPod: makeQoSPod("2", "", nil),
+			valid:  true,
+		},
+		{
+			name: "qos annotations are not changed for assigned pod",
+			pod: makeQoSPod("1.5", "node1", general.MergeMap(dedicatedNUMABinding, map[string]string{
+				"other": "value",
+			})),
+			oldPod: makeQoSPod("1.5", "node1", dedicatedNUMABinding),
+			valid:  true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			qosConf := generic.NewQoSConfiguration()
+			qosConf.SetExpandQoSLevelSelector(apiconsts.PodAnnotationQoSLevelDedicatedCores, map[string]string{
+				"legacy-qos": "dedicated",
+			})
+			qosConf.SetExpandQoSEnhancementKey(map[string]string{
+				apiconsts.PodAnnotationCPUEnhancementKey: "legacy-cpu-enhancement",
+			})
+
+			valid, msg, err := NewWebhookPodQoSValidator(qosConf).ValidatePod(tc.pod, tc.oldPod)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.valid, valid, msg)
+		})
+	}
+}